@@ -1,23 +1,63 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/captcha"
 	"github.com/anxuanzi/bua/dom"
+	"github.com/anxuanzi/bua/inbox"
+	"github.com/anxuanzi/bua/ocr"
+	"github.com/anxuanzi/bua/ratelimit"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 )
 
 // BrowserToolkit holds browser context for tool execution.
 type BrowserToolkit struct {
-	browser    *browser.Browser
+	browser    browser.Interface
 	elementMap *dom.ElementMap
+	shownMap   *dom.ElementMap // element map of the page state the model saw last
 	maxWidth   int
-}
+
+	captchaSolver   captcha.Solver
+	ocr             ocr.Engine
+	onHumanTakeover func(ctx context.Context, reason string) error
+	solvedCaptchas  map[captcha.Challenge]bool
+	totpSecrets     map[string]string
+	inbox           inbox.Inbox
+	runStarted      time.Time
+	logins          []Login
+	extraSecrets    map[string]string
+	sessionDir      string
+	pendingLogin    *Login
+	downloads       browser.DownloadOptions
+	downloadPaths   []string
+	assertions      []Assertion
+	sections        []Section
+	findings        *findingStore
+	rateLimit       *ratelimit.Limiter
+	fullElements    bool
+	maxElements     int    // elements listed by get_page_state, as in page states
+	goal            string // task of the run, to rank elements by
+	selectorTools   bool
+	allowJSEval     bool
+	jsEvalOrigins   []string
+	allowCDP        bool
+	visited         map[string]bool // normalized URLs the run has been on
+}
+
+// humanTakeoverTimeout bounds how long request_human_takeover waits for a person.
+const humanTakeoverTimeout = 10 * time.Minute
 
 // NewBrowserToolkit creates a new browser toolkit.
-func NewBrowserToolkit(b *browser.Browser, maxWidth int) *BrowserToolkit {
+func NewBrowserToolkit(b browser.Interface, maxWidth int) *BrowserToolkit {
 	return &BrowserToolkit{
 		browser:  b,
 		maxWidth: maxWidth,
@@ -31,6 +71,7 @@ func (t *BrowserToolkit) RefreshElementMap() error {
 		return err
 	}
 	t.elementMap = em
+	t.visit(em.PageURL)
 	return nil
 }
 
@@ -49,9 +90,10 @@ type NavigateArgs struct {
 
 // NavigateResult is the output for the navigate tool.
 type NavigateResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	URL     string `json:"url,omitempty"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	URL       string    `json:"url,omitempty"`
 }
 
 // ClickArgs is the input for the click tool.
@@ -62,8 +104,10 @@ type ClickArgs struct {
 
 // ClickResult is the output for the click tool.
 type ClickResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Redirect  *Redirect `json:"redirect,omitempty"` // set if the click left the site
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // TypeTextArgs is the input for the type_text tool.
@@ -75,8 +119,9 @@ type TypeTextArgs struct {
 
 // TypeTextResult is the output for the type_text tool.
 type TypeTextResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // ClearAndTypeArgs is the input for the clear_and_type tool.
@@ -88,8 +133,9 @@ type ClearAndTypeArgs struct {
 
 // ClearAndTypeResult is the output for the clear_and_type tool.
 type ClearAndTypeResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // ScrollArgs is the input for the scroll tool.
@@ -125,8 +171,9 @@ type GoBackArgs struct {
 
 // GoBackResult is the output for the go_back tool.
 type GoBackResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // GoForwardArgs is the input for the go_forward tool.
@@ -136,8 +183,9 @@ type GoForwardArgs struct {
 
 // GoForwardResult is the output for the go_forward tool.
 type GoForwardResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // HoverArgs is the input for the hover tool.
@@ -148,8 +196,9 @@ type HoverArgs struct {
 
 // HoverResult is the output for the hover tool.
 type HoverResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // DoubleClickArgs is the input for the double_click tool.
@@ -160,8 +209,10 @@ type DoubleClickArgs struct {
 
 // DoubleClickResult is the output for the double_click tool.
 type DoubleClickResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Redirect  *Redirect `json:"redirect,omitempty"` // set if the click left the site
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // FocusArgs is the input for the focus tool.
@@ -172,8 +223,9 @@ type FocusArgs struct {
 
 // FocusResult is the output for the focus tool.
 type FocusResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // ReloadArgs is the input for the reload tool.
@@ -183,8 +235,9 @@ type ReloadArgs struct {
 
 // ReloadResult is the output for the reload tool.
 type ReloadResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // ScrollToElementArgs is the input for the scroll_to_element tool.
@@ -195,8 +248,23 @@ type ScrollToElementArgs struct {
 
 // ScrollToElementResult is the output for the scroll_to_element tool.
 type ScrollToElementResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// GetElementDetailsArgs is the input for the get_element_details tool.
+type GetElementDetailsArgs struct {
+	ElementIndex int    `json:"element_index" jsonschema:"The index of the element to describe"`
+	Reasoning    string `json:"reasoning,omitempty" jsonschema:"What needs to be told apart"`
+}
+
+// GetElementDetailsResult is the output for the get_element_details tool.
+type GetElementDetailsResult struct {
+	Success   bool                    `json:"success"`
+	Message   string                  `json:"message"`
+	Details   *browser.ElementDetails `json:"details,omitempty"`
+	ErrorCode ErrorCode               `json:"error_code,omitempty"`
 }
 
 // ExtractContentArgs is the input for the extract_content tool.
@@ -211,6 +279,20 @@ type ExtractContentResult struct {
 	Content string `json:"content,omitempty"`
 }
 
+// ReadTextArgs is the input for the read_text tool.
+type ReadTextArgs struct {
+	ElementIndex *int   `json:"element_index,omitempty" jsonschema:"The index of the element to read, e.g. a chart, canvas or image button; omit to read the whole viewport"`
+	Reasoning    string `json:"reasoning,omitempty" jsonschema:"What text is needed"`
+}
+
+// ReadTextResult is the output for the read_text tool.
+type ReadTextResult struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Text      string    `json:"text,omitempty"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
 // ScreenshotArgs is the input for the screenshot tool.
 type ScreenshotArgs struct {
 	FullPage  bool   `json:"full_page,omitempty" jsonschema:"Whether to capture the full page or just the viewport"`
@@ -224,17 +306,41 @@ type ScreenshotResult struct {
 	Size    int    `json:"size,omitempty"`
 }
 
-// EvaluateJSArgs is the input for the evaluate_js tool.
-type EvaluateJSArgs struct {
-	Script    string `json:"script" jsonschema:"The JavaScript code to execute"`
-	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why running this JavaScript"`
+// EvalJSArgs is the input for the eval_js tool.
+type EvalJSArgs struct {
+	Script    string `json:"script" jsonschema:"A JavaScript expression, e.g. document.title, or statements ending in a return statement. Promises are awaited"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why no other tool can do this"`
+}
+
+// EvalJSResult is the output for the eval_js tool.
+type EvalJSResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Result  string `json:"result,omitempty"` // JSON
+}
+
+// EvaluateJSArgs is the input for the former evaluate_js tool.
+//
+// Deprecated: Use EvalJSArgs.
+type EvaluateJSArgs = EvalJSArgs
+
+// EvaluateJSResult is the output for the former evaluate_js tool.
+//
+// Deprecated: Use EvalJSResult.
+type EvaluateJSResult = EvalJSResult
+
+// CDPCallArgs is the input for the cdp_call tool.
+type CDPCallArgs struct {
+	Method    string `json:"method" jsonschema:"The DevTools protocol command, e.g. Page.printToPDF or Emulation.setCPUThrottlingRate"`
+	Params    string `json:"params,omitempty" jsonschema:"The command's parameters as a JSON object, e.g. {\"rate\": 4}"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why no other tool can do this"`
 }
 
-// EvaluateJSResult is the output for the evaluate_js tool.
-type EvaluateJSResult struct {
+// CDPCallResult is the output for the cdp_call tool.
+type CDPCallResult struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
-	Result  string `json:"result,omitempty"`
+	Result  string `json:"result,omitempty"` // JSON
 }
 
 // WaitArgs is the input for the wait tool.
@@ -252,6 +358,8 @@ type WaitResult struct {
 // NewTabArgs is the input for the new_tab tool.
 type NewTabArgs struct {
 	URL       string `json:"url,omitempty" jsonschema:"Optional URL to open in the new tab"`
+	Isolated  bool   `json:"isolated,omitempty" jsonschema:"Open the tab in its own browser context with separate cookies and storage, e.g. to log into a second account of the same site"`
+	Label     string `json:"label,omitempty" jsonschema:"Short name for what the tab is for, e.g. 'GitHub stats'; list_tabs shows it and switch_tab and close_tab accept it instead of the ID"`
 	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why opening a new tab"`
 }
 
@@ -264,7 +372,7 @@ type NewTabResult struct {
 
 // SwitchTabArgs is the input for the switch_tab tool.
 type SwitchTabArgs struct {
-	TabID     string `json:"tab_id" jsonschema:"The ID of the tab to switch to"`
+	TabID     string `json:"tab_id" jsonschema:"The ID or label of the tab to switch to"`
 	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why switching to this tab"`
 }
 
@@ -276,7 +384,7 @@ type SwitchTabResult struct {
 
 // CloseTabArgs is the input for the close_tab tool.
 type CloseTabArgs struct {
-	TabID     string `json:"tab_id" jsonschema:"The ID of the tab to close"`
+	TabID     string `json:"tab_id" jsonschema:"The ID or label of the tab to close"`
 	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why closing this tab"`
 }
 
@@ -291,10 +399,12 @@ type ListTabsArgs struct{}
 
 // ADKTabInfo represents information about a browser tab.
 type ADKTabInfo struct {
-	ID     string `json:"id"`
-	URL    string `json:"url"`
-	Title  string `json:"title"`
-	Active bool   `json:"active"`
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Active   bool   `json:"active"`
+	Isolated bool   `json:"isolated,omitempty"`
+	Label    string `json:"label,omitempty"`
 }
 
 // ListTabsResult is the output for the list_tabs tool.
@@ -304,8 +414,34 @@ type ListTabsResult struct {
 	Tabs    []ADKTabInfo `json:"tabs"`
 }
 
+// ListFramesArgs is the input for the list_frames tool (no args needed).
+type ListFramesArgs struct{}
+
+// ListFramesResult is the output for the list_frames tool.
+type ListFramesResult struct {
+	Success   bool                `json:"success"`
+	Message   string              `json:"message"`
+	Frames    []browser.FrameInfo `json:"frames"`
+	ErrorCode ErrorCode           `json:"error_code,omitempty"`
+}
+
+// SwitchFrameArgs is the input for the switch_frame tool.
+type SwitchFrameArgs struct {
+	Path      string `json:"path" jsonschema:"Path of the frame from list_frames, e.g. 0 or 1.0, or main to return to the page"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why switching to this frame"`
+}
+
+// SwitchFrameResult is the output for the switch_frame tool.
+type SwitchFrameResult struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
 // GetPageStateArgs is the input for the get_page_state tool (no args needed).
-type GetPageStateArgs struct{}
+type GetPageStateArgs struct {
+	FullPage bool `json:"full_page,omitempty" jsonschema:"List the elements of the whole page, not just the viewport, to plan scrolls. Elements marked [offscreen] must be scrolled to before use"`
+}
 
 // GetPageStateResult is the output for the get_page_state tool.
 type GetPageStateResult struct {
@@ -331,6 +467,203 @@ type DoneResult struct {
 	Data    any    `json:"data,omitempty"`
 }
 
+// SolveCaptchaArgs is the input for the solve_captcha tool.
+type SolveCaptchaArgs struct {
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why a CAPTCHA needs solving"`
+}
+
+// SolveCaptchaResult is the output for the solve_captcha tool.
+type SolveCaptchaResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// RequestHumanTakeoverArgs is the input for the request_human_takeover tool.
+type RequestHumanTakeoverArgs struct {
+	Reason string `json:"reason" jsonschema:"What the person needs to do in the browser, e.g. solve the CAPTCHA"`
+}
+
+// RequestHumanTakeoverResult is the output for the request_human_takeover tool.
+type RequestHumanTakeoverResult struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// GetTOTPArgs is the input for the get_totp tool.
+type GetTOTPArgs struct {
+	Name      string `json:"name" jsonschema:"Name of the stored authenticator secret, e.g. the site or account"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why a 2FA code is needed"`
+}
+
+// GetTOTPResult is the output for the get_totp tool.
+type GetTOTPResult struct {
+	Success          bool     `json:"success"`
+	Message          string   `json:"message"`
+	Code             string   `json:"code,omitempty"`
+	ValidForSeconds  int      `json:"valid_for_seconds,omitempty"`
+	AvailableSecrets []string `json:"available_secrets,omitempty"`
+}
+
+// GetEmailCodeArgs is the input for the get_email_code tool.
+type GetEmailCodeArgs struct {
+	From            string `json:"from,omitempty" jsonschema:"Only consider emails whose sender contains this text"`
+	SubjectContains string `json:"subject_contains,omitempty" jsonschema:"Only consider emails whose subject contains this text"`
+	TimeoutSeconds  int    `json:"timeout_seconds,omitzero" jsonschema:"How long to wait for the email (default 60, max 300)"`
+	Reasoning       string `json:"reasoning,omitempty" jsonschema:"Why an emailed code is needed"`
+}
+
+// GetEmailCodeResult is the output for the get_email_code tool.
+type GetEmailCodeResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	From    string `json:"from,omitempty"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// LoginArgs is the input for the login tool.
+type LoginArgs struct {
+	Name      string `json:"name" jsonschema:"Name of the configured login, e.g. the site"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why signing in is needed"`
+}
+
+// LoginResult is the output for the login tool.
+type LoginResult struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// QuerySelectorArgs is the input for the query_selector tool.
+type QuerySelectorArgs struct {
+	Selector  string `json:"selector" jsonschema:"CSS selector, or XPath expression starting with / or ( (prefix css= or xpath= to be explicit)"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why querying this selector"`
+}
+
+// QuerySelectorResult is the output for the query_selector tool.
+type QuerySelectorResult struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+	Matches   []string  `json:"matches,omitempty"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// ClickSelectorArgs is the input for the click_selector tool.
+type ClickSelectorArgs struct {
+	Selector  string `json:"selector" jsonschema:"CSS selector, or XPath expression starting with / or ( (prefix css= or xpath= to be explicit)"`
+	Reasoning string `json:"reasoning,omitempty" jsonschema:"Why clicking this element"`
+}
+
+// ClickSelectorResult is the output for the click_selector tool.
+type ClickSelectorResult struct {
+	Success   bool      `json:"success"`
+	Message   string    `json:"message"`
+	Redirect  *Redirect `json:"redirect,omitempty"` // set if the click left the site
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// DownloadFileArgs is the input for the download_file tool.
+type DownloadFileArgs struct {
+	ElementIndex *int   `json:"element_index,omitempty" jsonschema:"Index of the link or media element to download"`
+	URL          string `json:"url,omitempty" jsonschema:"URL to download, if not downloading an element"`
+	Reasoning    string `json:"reasoning,omitempty" jsonschema:"Why downloading this file"`
+}
+
+// DownloadFileResult is the output for the download_file tool.
+type DownloadFileResult struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+}
+
+// Assertion types for the assert tool.
+const (
+	AssertElementExists = "element_exists"
+	AssertTextContains  = "text_contains"
+	AssertURLMatches    = "url_matches"
+	AssertTitleContains = "title_contains"
+	AssertCountAtLeast  = "count_at_least"
+)
+
+// Assertion is a pass/fail check recorded by the assert tool.
+type Assertion struct {
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Expected    string    `json:"expected"`
+	Actual      string    `json:"actual"`
+	Passed      bool      `json:"passed"`
+	URL         string    `json:"url"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// AssertArgs is the input for the assert tool.
+type AssertArgs struct {
+	Type        string `json:"type" jsonschema:"Check to run: element_exists, text_contains, url_matches, title_contains or count_at_least"`
+	Description string `json:"description" jsonschema:"What is being verified, in plain words, e.g. 'cart shows 2 items'"`
+	Selector    string `json:"selector,omitempty" jsonschema:"CSS selector for element_exists and count_at_least; optionally limits text_contains to matching elements"`
+	Text        string `json:"text,omitempty" jsonschema:"Text expected for text_contains and title_contains (case-insensitive); also limits element_exists and count_at_least to elements containing it"`
+	Pattern     string `json:"pattern,omitempty" jsonschema:"Regular expression the current URL must match, for url_matches"`
+	Count       int    `json:"count,omitzero" jsonschema:"Minimum number of matching elements, for count_at_least"`
+}
+
+// AssertResult is the output for the assert tool.
+type AssertResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Passed  bool   `json:"passed"`
+	Actual  string `json:"actual,omitempty"`
+}
+
+// Section statuses for the report_section tool.
+const (
+	SectionComplete = "complete"
+	SectionPartial  = "partial"
+	SectionFailed   = "failed"
+)
+
+// Section is the result of one objective of a task, filed by the
+// report_section tool.
+type Section struct {
+	Objective string    `json:"objective"`
+	Status    string    `json:"status"`
+	Summary   string    `json:"summary,omitempty"`
+	Data      any       `json:"data,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReportSectionArgs is the input for the report_section tool.
+type ReportSectionArgs struct {
+	Objective string `json:"objective" jsonschema:"The objective this result is for, named as in the task, e.g. '1. Star count of the repository'. Reporting the same objective again replaces its result"`
+	Status    string `json:"status" jsonschema:"complete, partial (some of it found) or failed"`
+	Summary   string `json:"summary,omitempty" jsonschema:"What was found, or why it could not be"`
+	Data      any    `json:"data,omitempty" jsonschema:"The objective's data"`
+}
+
+// ReportSectionResult is the output for the report_section tool.
+type ReportSectionResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// SaveFindingArgs is the input for the save_finding tool.
+type SaveFindingArgs struct {
+	Data map[string]any `json:"data" jsonschema:"The entity's fields, e.g. {\"username\": \"...\", \"followers\": 1200}. Use the same field names for every entity of a kind"`
+}
+
+// SaveFindingResult is the output for the save_finding tool.
+type SaveFindingResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
 // ---- Tool Functions ----
 
 // CreateNavigateTool creates the navigate function tool.
@@ -341,11 +674,20 @@ func (t *BrowserToolkit) CreateNavigateTool() (tool.Tool, error) {
 			Description: "Navigate the browser to a specified URL",
 		},
 		func(ctx tool.Context, args NavigateArgs) (NavigateResult, error) {
-			if err := t.browser.Navigate(nil, args.URL); err != nil {
+			if err := t.waitRateLimit(ctx, args.URL); err != nil {
 				return NavigateResult{Success: false, Message: fmt.Sprintf("Navigation failed: %v", err)}, nil
 			}
+			revisit := t.visitedBefore(args.URL) && visitKey(t.browser.GetURL()) != visitKey(args.URL)
+			if err := t.browser.Navigate(nil, args.URL); err != nil {
+				return NavigateResult{Success: false, Message: fmt.Sprintf("Navigation failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			t.visit(args.URL)
 			t.RefreshElementMap()
-			return NavigateResult{Success: true, Message: fmt.Sprintf("Navigated to %s", args.URL), URL: args.URL}, nil
+			message := fmt.Sprintf("Navigated to %s", args.URL)
+			if revisit {
+				message += revisitNote
+			}
+			return NavigateResult{Success: true, Message: message, URL: args.URL}, nil
 		},
 	)
 }
@@ -359,13 +701,23 @@ func (t *BrowserToolkit) CreateClickTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args ClickArgs) (ClickResult, error) {
 			if t.elementMap == nil {
-				return ClickResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return ClickResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+			}
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return ClickResult{Success: false, Message: fmt.Sprintf("Click failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
-			if err := t.browser.Click(nil, args.ElementIndex, t.elementMap); err != nil {
-				return ClickResult{Success: false, Message: fmt.Sprintf("Click failed: %v", err)}, nil
+			before := t.markPage()
+			if err := t.browser.Click(nil, index, t.elementMap); err != nil {
+				return ClickResult{Success: false, Message: fmt.Sprintf("Click failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
-			return ClickResult{Success: true, Message: fmt.Sprintf("Clicked element [%d]", args.ElementIndex)}, nil
+			message := fmt.Sprintf("Clicked element [%d]", args.ElementIndex)
+			redirect := t.redirectSince(before)
+			if redirect != nil {
+				message += redirect.note()
+			}
+			return ClickResult{Success: true, Message: message, Redirect: redirect}, nil
 		},
 	)
 }
@@ -379,10 +731,14 @@ func (t *BrowserToolkit) CreateTypeTextTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args TypeTextArgs) (TypeTextResult, error) {
 			if t.elementMap == nil {
-				return TypeTextResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return TypeTextResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+			}
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return TypeTextResult{Success: false, Message: fmt.Sprintf("Type failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
-			if err := t.browser.TypeText(nil, args.ElementIndex, args.Text, t.elementMap); err != nil {
-				return TypeTextResult{Success: false, Message: fmt.Sprintf("Type failed: %v", err)}, nil
+			if err := t.browser.TypeText(nil, index, t.expandSecrets(args.Text), t.elementMap); err != nil {
+				return TypeTextResult{Success: false, Message: fmt.Sprintf("Type failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			return TypeTextResult{Success: true, Message: fmt.Sprintf("Typed text into element [%d]", args.ElementIndex)}, nil
 		},
@@ -398,10 +754,14 @@ func (t *BrowserToolkit) CreateClearAndTypeTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args ClearAndTypeArgs) (ClearAndTypeResult, error) {
 			if t.elementMap == nil {
-				return ClearAndTypeResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return ClearAndTypeResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
 			}
-			if err := t.browser.ClearAndType(nil, args.ElementIndex, args.Text, t.elementMap); err != nil {
-				return ClearAndTypeResult{Success: false, Message: fmt.Sprintf("Clear and type failed: %v", err)}, nil
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return ClearAndTypeResult{Success: false, Message: fmt.Sprintf("Clear and type failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			if err := t.browser.ClearAndType(nil, index, t.expandSecrets(args.Text), t.elementMap); err != nil {
+				return ClearAndTypeResult{Success: false, Message: fmt.Sprintf("Clear and type failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			return ClearAndTypeResult{Success: true, Message: fmt.Sprintf("Cleared and typed into element [%d]", args.ElementIndex)}, nil
 		},
@@ -420,7 +780,15 @@ func (t *BrowserToolkit) CreateScrollTool() (tool.Tool, error) {
 			if amount == 0 {
 				amount = 300
 			}
-			if err := t.browser.Scroll(nil, args.Direction, amount, args.ElementIndex, t.elementMap); err != nil {
+			elementIndex := args.ElementIndex
+			if elementIndex != nil {
+				index, err := t.resolveIndex(*elementIndex)
+				if err != nil {
+					return ScrollResult{Success: false, Message: fmt.Sprintf("Scroll failed: %v", err)}, nil
+				}
+				elementIndex = &index
+			}
+			if err := t.browser.Scroll(nil, args.Direction, amount, elementIndex, t.elementMap); err != nil {
 				return ScrollResult{Success: false, Message: fmt.Sprintf("Scroll failed: %v", err)}, nil
 			}
 			t.RefreshElementMap()
@@ -455,7 +823,7 @@ func (t *BrowserToolkit) CreateGoBackTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args GoBackArgs) (GoBackResult, error) {
 			if err := t.browser.GoBack(nil); err != nil {
-				return GoBackResult{Success: false, Message: fmt.Sprintf("Go back failed: %v", err)}, nil
+				return GoBackResult{Success: false, Message: fmt.Sprintf("Go back failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
 			return GoBackResult{Success: true, Message: "Navigated back"}, nil
@@ -472,7 +840,7 @@ func (t *BrowserToolkit) CreateGoForwardTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args GoForwardArgs) (GoForwardResult, error) {
 			if err := t.browser.GoForward(nil); err != nil {
-				return GoForwardResult{Success: false, Message: fmt.Sprintf("Go forward failed: %v", err)}, nil
+				return GoForwardResult{Success: false, Message: fmt.Sprintf("Go forward failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
 			return GoForwardResult{Success: true, Message: "Navigated forward"}, nil
@@ -489,10 +857,14 @@ func (t *BrowserToolkit) CreateHoverTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args HoverArgs) (HoverResult, error) {
 			if t.elementMap == nil {
-				return HoverResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return HoverResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
 			}
-			if err := t.browser.Hover(nil, args.ElementIndex, t.elementMap); err != nil {
-				return HoverResult{Success: false, Message: fmt.Sprintf("Hover failed: %v", err)}, nil
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return HoverResult{Success: false, Message: fmt.Sprintf("Hover failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			if err := t.browser.Hover(nil, index, t.elementMap); err != nil {
+				return HoverResult{Success: false, Message: fmt.Sprintf("Hover failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
 			return HoverResult{Success: true, Message: fmt.Sprintf("Hovered over element [%d]", args.ElementIndex)}, nil
@@ -509,13 +881,23 @@ func (t *BrowserToolkit) CreateDoubleClickTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args DoubleClickArgs) (DoubleClickResult, error) {
 			if t.elementMap == nil {
-				return DoubleClickResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return DoubleClickResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+			}
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return DoubleClickResult{Success: false, Message: fmt.Sprintf("Double-click failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
-			if err := t.browser.DoubleClick(nil, args.ElementIndex, t.elementMap); err != nil {
-				return DoubleClickResult{Success: false, Message: fmt.Sprintf("Double-click failed: %v", err)}, nil
+			before := t.markPage()
+			if err := t.browser.DoubleClick(nil, index, t.elementMap); err != nil {
+				return DoubleClickResult{Success: false, Message: fmt.Sprintf("Double-click failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
-			return DoubleClickResult{Success: true, Message: fmt.Sprintf("Double-clicked element [%d]", args.ElementIndex)}, nil
+			message := fmt.Sprintf("Double-clicked element [%d]", args.ElementIndex)
+			redirect := t.redirectSince(before)
+			if redirect != nil {
+				message += redirect.note()
+			}
+			return DoubleClickResult{Success: true, Message: message, Redirect: redirect}, nil
 		},
 	)
 }
@@ -529,10 +911,14 @@ func (t *BrowserToolkit) CreateFocusTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args FocusArgs) (FocusResult, error) {
 			if t.elementMap == nil {
-				return FocusResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return FocusResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+			}
+			index, err := t.resolveVisibleIndex(args.ElementIndex)
+			if err != nil {
+				return FocusResult{Success: false, Message: fmt.Sprintf("Focus failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
-			if err := t.browser.Focus(nil, args.ElementIndex, t.elementMap); err != nil {
-				return FocusResult{Success: false, Message: fmt.Sprintf("Focus failed: %v", err)}, nil
+			if err := t.browser.Focus(nil, index, t.elementMap); err != nil {
+				return FocusResult{Success: false, Message: fmt.Sprintf("Focus failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			return FocusResult{Success: true, Message: fmt.Sprintf("Focused element [%d]", args.ElementIndex)}, nil
 		},
@@ -548,7 +934,7 @@ func (t *BrowserToolkit) CreateReloadTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args ReloadArgs) (ReloadResult, error) {
 			if err := t.browser.Reload(nil); err != nil {
-				return ReloadResult{Success: false, Message: fmt.Sprintf("Reload failed: %v", err)}, nil
+				return ReloadResult{Success: false, Message: fmt.Sprintf("Reload failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
 			return ReloadResult{Success: true, Message: "Page reloaded"}, nil
@@ -565,10 +951,14 @@ func (t *BrowserToolkit) CreateScrollToElementTool() (tool.Tool, error) {
 		},
 		func(ctx tool.Context, args ScrollToElementArgs) (ScrollToElementResult, error) {
 			if t.elementMap == nil {
-				return ScrollToElementResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				return ScrollToElementResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
 			}
-			if err := t.browser.ScrollToElement(nil, args.ElementIndex, t.elementMap); err != nil {
-				return ScrollToElementResult{Success: false, Message: fmt.Sprintf("Scroll to element failed: %v", err)}, nil
+			index, err := t.resolveIndex(args.ElementIndex)
+			if err != nil {
+				return ScrollToElementResult{Success: false, Message: fmt.Sprintf("Scroll to element failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			if err := t.browser.ScrollToElement(nil, index, t.elementMap); err != nil {
+				return ScrollToElementResult{Success: false, Message: fmt.Sprintf("Scroll to element failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
 			}
 			t.RefreshElementMap()
 			return ScrollToElementResult{Success: true, Message: fmt.Sprintf("Scrolled to element [%d]", args.ElementIndex)}, nil
@@ -576,6 +966,30 @@ func (t *BrowserToolkit) CreateScrollToElementTool() (tool.Tool, error) {
 	)
 }
 
+// CreateGetElementDetailsTool creates the get_element_details function tool.
+func (t *BrowserToolkit) CreateGetElementDetailsTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "get_element_details",
+			Description: "Get an element's attributes, ARIA state, computed visibility (including what covers it) and inner HTML, to tell similar elements apart",
+		},
+		func(ctx tool.Context, args GetElementDetailsArgs) (GetElementDetailsResult, error) {
+			if t.elementMap == nil {
+				return GetElementDetailsResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+			}
+			index, err := t.resolveIndex(args.ElementIndex)
+			if err != nil {
+				return GetElementDetailsResult{Success: false, Message: fmt.Sprintf("Get element details failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			details, err := t.browser.ElementDetails(ctx, index, t.elementMap)
+			if err != nil {
+				return GetElementDetailsResult{Success: false, Message: fmt.Sprintf("Get element details failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			return GetElementDetailsResult{Success: true, Message: fmt.Sprintf("Details of element [%d]", args.ElementIndex), Details: details}, nil
+		},
+	)
+}
+
 // CreateExtractContentTool creates the extract_content function tool.
 func (t *BrowserToolkit) CreateExtractContentTool() (tool.Tool, error) {
 	return functiontool.New(
@@ -588,11 +1002,71 @@ func (t *BrowserToolkit) CreateExtractContentTool() (tool.Tool, error) {
 			if err != nil {
 				return ExtractContentResult{Success: false, Message: fmt.Sprintf("Extract content failed: %v", err)}, nil
 			}
+			// Pages drawn on canvases or made of images have little text in
+			// the DOM; read what is on screen instead
+			message := "Content extracted"
+			if t.ocr != nil && len(strings.TrimSpace(content)) < minDOMContent {
+				if text, err := t.readText(ctx, nil); err == nil && text != "" {
+					content = strings.TrimSpace(content + "\n\n[Text read from the screenshot]\n" + text)
+					message = "Content extracted, with text read from the screenshot"
+				}
+			}
 			// Truncate if too long
 			if len(content) > 10000 {
 				content = content[:10000] + "... (truncated)"
 			}
-			return ExtractContentResult{Success: true, Message: "Content extracted", Content: content}, nil
+			return ExtractContentResult{Success: true, Message: message, Content: content}, nil
+		},
+	)
+}
+
+// minDOMContent is the length of page text below which extract_content also
+// reads the screenshot, when OCR is configured.
+const minDOMContent = 200
+
+// readText reads the text in a screenshot of the element at index, or of the
+// viewport if index is nil.
+func (t *BrowserToolkit) readText(ctx context.Context, index *int) (string, error) {
+	var data []byte
+	var err error
+	if index != nil {
+		data, err = t.browser.ScreenshotElement(nil, *index, t.elementMap)
+	} else {
+		data, err = t.browser.ScreenshotFullQuality(nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	return t.ocr.Recognize(ctx, data)
+}
+
+// CreateReadTextTool creates the read_text function tool.
+func (t *BrowserToolkit) CreateReadTextTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "read_text",
+			Description: "Read text drawn on the page rather than written in it, e.g. in charts, canvas-based apps and image buttons, with OCR on a screenshot of one element or of the viewport",
+		},
+		func(ctx tool.Context, args ReadTextArgs) (ReadTextResult, error) {
+			index := args.ElementIndex
+			if index != nil {
+				if t.elementMap == nil {
+					return ReadTextResult{Success: false, Message: "No elements available. Call get_page_state first.", ErrorCode: ErrorCodeElementNotFound}, nil
+				}
+				current, err := t.resolveIndex(*index)
+				if err != nil {
+					return ReadTextResult{Success: false, Message: fmt.Sprintf("Read text failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+				}
+				index = &current
+			}
+			text, err := t.readText(ctx, index)
+			if err != nil {
+				return ReadTextResult{Success: false, Message: fmt.Sprintf("Read text failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			if text == "" {
+				return ReadTextResult{Success: true, Message: "No text found"}, nil
+			}
+			return ReadTextResult{Success: true, Message: "Text read", Text: text}, nil
 		},
 	)
 }
@@ -615,19 +1089,60 @@ func (t *BrowserToolkit) CreateScreenshotTool() (tool.Tool, error) {
 	)
 }
 
-// CreateEvaluateJSTool creates the evaluate_js function tool.
+// CreateEvalJSTool creates the eval_js function tool.
+func (t *BrowserToolkit) CreateEvalJSTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "eval_js",
+			Description: "Run JavaScript on the page and return its result as JSON. A last resort for what no other tool can do",
+		},
+		func(ctx tool.Context, args EvalJSArgs) (EvalJSResult, error) {
+			if err := t.checkJSEvalOrigin(); err != nil {
+				return EvalJSResult{Success: false, Message: fmt.Sprintf("JS evaluation refused: %v", err)}, nil
+			}
+			result, err := t.evalJS(args.Script)
+			if err != nil {
+				return EvalJSResult{Success: false, Message: fmt.Sprintf("JS evaluation failed: %v", err)}, nil
+			}
+			return EvalJSResult{Success: true, Message: "JavaScript executed", Result: result}, nil
+		},
+	)
+}
+
+// CreateEvaluateJSTool creates the eval_js tool, which replaced evaluate_js.
+// The agent registers it only with AgentConfig.AllowJSEval.
+//
+// Deprecated: Use CreateEvalJSTool.
 func (t *BrowserToolkit) CreateEvaluateJSTool() (tool.Tool, error) {
+	return t.CreateEvalJSTool()
+}
+
+// CreateCDPCallTool creates the cdp_call function tool.
+func (t *BrowserToolkit) CreateCDPCallTool() (tool.Tool, error) {
 	return functiontool.New(
 		functiontool.Config{
-			Name:        "evaluate_js",
-			Description: "Execute JavaScript code on the page and return the result",
+			Name:        "cdp_call",
+			Description: "Send a raw Chrome DevTools protocol command and return its JSON result. A last resort for browser features no other tool has",
 		},
-		func(ctx tool.Context, args EvaluateJSArgs) (EvaluateJSResult, error) {
-			result, err := t.browser.EvaluateJS(nil, args.Script)
+		func(ctx tool.Context, args CDPCallArgs) (CDPCallResult, error) {
+			var params any
+			if strings.TrimSpace(args.Params) != "" {
+				raw := json.RawMessage(args.Params)
+				if !json.Valid(raw) {
+					return CDPCallResult{Success: false, Message: "CDP call failed: params is not valid JSON"}, nil
+				}
+				params = raw
+			}
+			res, err := t.browser.CallCDP(ctx, args.Method, params)
 			if err != nil {
-				return EvaluateJSResult{Success: false, Message: fmt.Sprintf("JS evaluation failed: %v", err)}, nil
+				return CDPCallResult{Success: false, Message: fmt.Sprintf("CDP call failed: %v", err)}, nil
+			}
+			result := string(res)
+			if len(result) > maxEvalResult {
+				result = truncate(result, maxEvalResult) + "... (truncated)"
 			}
-			return EvaluateJSResult{Success: true, Message: "JavaScript executed", Result: result}, nil
+			t.RefreshElementMap()
+			return CDPCallResult{Success: true, Message: fmt.Sprintf("Called %s", args.Method), Result: result}, nil
 		},
 	)
 }
@@ -660,28 +1175,82 @@ func (t *BrowserToolkit) CreateNewTabTool() (tool.Tool, error) {
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "new_tab",
-			Description: "Open a new browser tab, optionally navigating to a URL",
+			Description: "Open a new browser tab, optionally navigating to a URL. Give it a label saying what it is for when working with several tabs. Set isolated to give the tab its own cookies and storage",
 		},
 		func(ctx tool.Context, args NewTabArgs) (NewTabResult, error) {
-			tabID, err := t.browser.NewTab(nil, args.URL)
+			// Isolated tabs may open the same page on purpose, e.g. as another account
+			if args.URL != "" && !args.Isolated {
+				if tab, ok := t.openTabFor(args.URL); ok {
+					return NewTabResult{Success: false, Message: duplicateTabMessage(tab), TabID: tab.ID}, nil
+				}
+			}
+			revisit := args.URL != "" && t.visitedBefore(args.URL)
+			if err := t.waitRateLimit(ctx, args.URL); err != nil {
+				return NewTabResult{Success: false, Message: fmt.Sprintf("New tab failed: %v", err)}, nil
+			}
+			var tabID string
+			var err error
+			if args.Isolated {
+				tabID, err = t.browser.NewIsolatedTab(nil, args.URL)
+			} else {
+				tabID, err = t.browser.NewTab(nil, args.URL)
+			}
 			if err != nil {
 				return NewTabResult{Success: false, Message: fmt.Sprintf("New tab failed: %v", err)}, nil
 			}
 			t.RefreshElementMap()
-			return NewTabResult{Success: true, Message: fmt.Sprintf("Opened new tab: %s", tabID), TabID: tabID}, nil
+
+			name := tabID
+			if label := strings.TrimSpace(args.Label); label != "" {
+				if err := t.browser.LabelTab(tabID, label); err == nil {
+					name = fmt.Sprintf("%s (%q)", tabID, label)
+				}
+			}
+			message := fmt.Sprintf("Opened new tab: %s", name)
+			if args.Isolated {
+				message = fmt.Sprintf("Opened new isolated tab: %s", name)
+			}
+			if revisit {
+				message += revisitNote
+			}
+			return NewTabResult{Success: true, Message: message, TabID: tabID}, nil
 		},
 	)
 }
 
+// resolveTab returns the ID of the tab ref names: ref itself if it is a tab
+// ID, else that of the only tab labeled ref, ignoring case. Refs that match
+// neither are returned as is, for the caller to report.
+func (t *BrowserToolkit) resolveTab(ref string) string {
+	tabs := t.browser.ListTabs()
+	var match string
+	for _, tab := range tabs {
+		if tab.ID == ref {
+			return ref
+		}
+		if tab.Label != "" && strings.EqualFold(tab.Label, strings.TrimSpace(ref)) {
+			if match != "" {
+				return ref // ambiguous
+			}
+			match = tab.ID
+		}
+	}
+	if match != "" {
+		return match
+	}
+	return ref
+}
+
 // CreateSwitchTabTool creates the switch_tab function tool.
 func (t *BrowserToolkit) CreateSwitchTabTool() (tool.Tool, error) {
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "switch_tab",
-			Description: "Switch to a different browser tab by its ID",
+			Description: "Switch to a different browser tab by its ID or label",
 		},
 		func(ctx tool.Context, args SwitchTabArgs) (SwitchTabResult, error) {
-			if err := t.browser.SwitchTab(args.TabID); err != nil {
+			tabID := t.resolveTab(args.TabID)
+			if err := t.browser.SwitchTab(tabID); err != nil {
 				return SwitchTabResult{Success: false, Message: fmt.Sprintf("Switch tab failed: %v", err)}, nil
 			}
 			t.RefreshElementMap()
@@ -695,10 +1264,10 @@ func (t *BrowserToolkit) CreateCloseTabTool() (tool.Tool, error) {
 	return functiontool.New(
 		functiontool.Config{
 			Name:        "close_tab",
-			Description: "Close a browser tab by its ID",
+			Description: "Close a browser tab by its ID or label",
 		},
 		func(ctx tool.Context, args CloseTabArgs) (CloseTabResult, error) {
-			if err := t.browser.CloseTab(args.TabID); err != nil {
+			if err := t.browser.CloseTab(t.resolveTab(args.TabID)); err != nil {
 				return CloseTabResult{Success: false, Message: fmt.Sprintf("Close tab failed: %v", err)}, nil
 			}
 			t.RefreshElementMap()
@@ -719,10 +1288,12 @@ func (t *BrowserToolkit) CreateListTabsTool() (tool.Tool, error) {
 			tabInfos := make([]ADKTabInfo, len(tabs))
 			for i, tab := range tabs {
 				tabInfos[i] = ADKTabInfo{
-					ID:     tab.ID,
-					URL:    tab.URL,
-					Title:  tab.Title,
-					Active: tab.Active,
+					ID:       tab.ID,
+					URL:      tab.URL,
+					Title:    tab.Title,
+					Active:   tab.Active,
+					Isolated: tab.Isolated,
+					Label:    tab.Label,
 				}
 			}
 			return ListTabsResult{Success: true, Message: fmt.Sprintf("Found %d tabs", len(tabs)), Tabs: tabInfos}, nil
@@ -730,6 +1301,43 @@ func (t *BrowserToolkit) CreateListTabsTool() (tool.Tool, error) {
 	)
 }
 
+// CreateListFramesTool creates the list_frames function tool.
+func (t *BrowserToolkit) CreateListFramesTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "list_frames",
+			Description: "List the iframes of the page, including nested ones, with their paths for switch_frame",
+		},
+		func(ctx tool.Context, args ListFramesArgs) (ListFramesResult, error) {
+			frames, err := t.browser.ListFrames(ctx)
+			if err != nil {
+				return ListFramesResult{Success: false, Message: fmt.Sprintf("List frames failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			return ListFramesResult{Success: true, Message: fmt.Sprintf("Found %d frames", len(frames)), Frames: frames}, nil
+		},
+	)
+}
+
+// CreateSwitchFrameTool creates the switch_frame function tool.
+func (t *BrowserToolkit) CreateSwitchFrameTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "switch_frame",
+			Description: "Read and act on the elements inside an iframe (e.g. an embedded payment form) until switching back with path main",
+		},
+		func(ctx tool.Context, args SwitchFrameArgs) (SwitchFrameResult, error) {
+			if err := t.browser.SwitchFrame(ctx, args.Path); err != nil {
+				return SwitchFrameResult{Success: false, Message: fmt.Sprintf("Switch frame failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			t.RefreshElementMap()
+			if args.Path == "" || args.Path == "main" {
+				return SwitchFrameResult{Success: true, Message: "Switched back to the page"}, nil
+			}
+			return SwitchFrameResult{Success: true, Message: fmt.Sprintf("Switched to frame %s; page states now list its elements", args.Path)}, nil
+		},
+	)
+}
+
 // CreateGetPageStateTool creates the get_page_state function tool.
 func (t *BrowserToolkit) CreateGetPageStateTool() (tool.Tool, error) {
 	return functiontool.New(
@@ -738,11 +1346,23 @@ func (t *BrowserToolkit) CreateGetPageStateTool() (tool.Tool, error) {
 			Description: "Get the current page state including URL, title, and interactive elements",
 		},
 		func(ctx tool.Context, args GetPageStateArgs) (GetPageStateResult, error) {
-			if err := t.RefreshElementMap(); err != nil {
+			refresh := t.RefreshElementMap
+			if args.FullPage {
+				refresh = t.refreshFullElementMap
+			}
+			if err := refresh(); err != nil {
 				return GetPageStateResult{Success: false, Message: fmt.Sprintf("Failed to get page state: %v", err)}, nil
 			}
 
-			elementsText := t.elementMap.ToTokenStringLimited(100)
+			maxElements := t.maxElements
+			if maxElements <= 0 {
+				maxElements = 100
+			}
+			elementsText := t.elementMap.ToTokenStringFor(maxElements, t.goal)
+			if diff, ok := t.elementMap.ToDiffString(t.shownMap, maxElements, t.goal); ok && !t.fullElements {
+				elementsText = diff
+			}
+			t.MarkShown()
 
 			return GetPageStateResult{
 				Success:  true,
@@ -773,9 +1393,559 @@ func (t *BrowserToolkit) CreateDoneTool() (tool.Tool, error) {
 	)
 }
 
+// SolveCaptcha detects a CAPTCHA on the active page and, if a solver is
+// configured, solves it and injects the token. It returns the challenge found
+// (nil if the page has none) and whether it was solved by this call; each
+// challenge is only sent to the solver once.
+func (t *BrowserToolkit) SolveCaptcha(ctx context.Context) (*captcha.Challenge, bool, error) {
+	page := t.browser.ActivePage()
+	if page == nil {
+		return nil, false, fmt.Errorf("no active page")
+	}
+
+	challenge, err := captcha.Detect(page)
+	if err != nil || challenge == nil {
+		return nil, false, err
+	}
+	if t.captchaSolver == nil {
+		return challenge, false, fmt.Errorf("no CAPTCHA solver configured")
+	}
+	if t.solvedCaptchas[*challenge] {
+		return challenge, false, nil
+	}
+
+	token, err := t.captchaSolver.Solve(ctx, *challenge)
+	if err != nil {
+		return challenge, false, fmt.Errorf("failed to solve %s: %w", challenge.Kind, err)
+	}
+	if err := captcha.Inject(page, *challenge, token); err != nil {
+		return challenge, false, err
+	}
+
+	if t.solvedCaptchas == nil {
+		t.solvedCaptchas = make(map[captcha.Challenge]bool)
+	}
+	t.solvedCaptchas[*challenge] = true
+	return challenge, true, nil
+}
+
+// CreateSolveCaptchaTool creates the solve_captcha function tool.
+func (t *BrowserToolkit) CreateSolveCaptchaTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "solve_captcha",
+			Description: "Solve a reCAPTCHA, hCaptcha or Turnstile challenge on the current page and fill in its token",
+		},
+		func(ctx tool.Context, args SolveCaptchaArgs) (SolveCaptchaResult, error) {
+			challenge, _, err := t.SolveCaptcha(ctx)
+			if challenge == nil && err == nil {
+				return SolveCaptchaResult{Success: false, Message: "No CAPTCHA found on the page"}, nil
+			}
+			if err != nil {
+				msg := fmt.Sprintf("CAPTCHA not solved: %v", err)
+				if t.onHumanTakeover != nil {
+					msg += ". Use request_human_takeover to ask a person to solve it"
+				}
+				result := SolveCaptchaResult{Success: false, Message: msg}
+				if challenge != nil {
+					result.Kind = string(challenge.Kind)
+				}
+				return result, nil
+			}
+			t.browser.WaitStable(nil)
+			t.RefreshElementMap()
+			return SolveCaptchaResult{
+				Success: true,
+				Message: fmt.Sprintf("Solved %s and filled in the token; submit the form if the page did not continue", challenge.Kind),
+				Kind:    string(challenge.Kind),
+			}, nil
+		},
+	)
+}
+
+// CreateRequestHumanTakeoverTool creates the request_human_takeover function tool.
+func (t *BrowserToolkit) CreateRequestHumanTakeoverTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "request_human_takeover",
+			Description: "Ask a person to complete a step in the browser that you cannot, such as a CAPTCHA, and wait until they are done",
+		},
+		func(ctx tool.Context, args RequestHumanTakeoverArgs) (RequestHumanTakeoverResult, error) {
+			if t.onHumanTakeover == nil {
+				return RequestHumanTakeoverResult{Success: false, Message: "No person is available to take over; finish with done and explain what blocked you", ErrorCode: ErrorCodeHumanTakeover}, nil
+			}
+
+			takeoverCtx, cancel := context.WithTimeout(ctx, humanTakeoverTimeout)
+			defer cancel()
+
+			if err := t.onHumanTakeover(takeoverCtx, args.Reason); err != nil {
+				if takeoverCtx.Err() == context.DeadlineExceeded {
+					return RequestHumanTakeoverResult{Success: false, Message: "Timed out waiting for a person to take over", ErrorCode: ErrorCodeHumanTakeover}, nil
+				}
+				return RequestHumanTakeoverResult{Success: false, Message: fmt.Sprintf("Human takeover failed: %v", err), ErrorCode: ErrorCodeHumanTakeover}, nil
+			}
+			t.RefreshElementMap()
+			return RequestHumanTakeoverResult{Success: true, Message: "The person has finished; check the page state before continuing"}, nil
+		},
+	)
+}
+
+// minTOTPValidity is the least time a returned code must stay valid, leaving
+// room to type and submit it before it expires.
+const minTOTPValidity = 5 * time.Second
+
+// CreateGetTOTPTool creates the get_totp function tool.
+func (t *BrowserToolkit) CreateGetTOTPTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "get_totp",
+			Description: "Get the current 6-digit authenticator (2FA) code for a stored secret",
+		},
+		func(ctx tool.Context, args GetTOTPArgs) (GetTOTPResult, error) {
+			secret, ok := t.totpSecrets[args.Name]
+			if !ok {
+				names := make([]string, 0, len(t.totpSecrets))
+				for name := range t.totpSecrets {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				return GetTOTPResult{Success: false, Message: fmt.Sprintf("No authenticator secret named %q", args.Name), AvailableSecrets: names}, nil
+			}
+
+			params, err := parseTOTPSecret(secret)
+			if err != nil {
+				return GetTOTPResult{Success: false, Message: fmt.Sprintf("Authenticator secret %q is invalid: %v", args.Name, err)}, nil
+			}
+
+			// Don't hand out a code that expires before it can be submitted
+			now := time.Now()
+			if left := params.remaining(now); left < minTOTPValidity {
+				select {
+				case <-ctx.Done():
+					return GetTOTPResult{Success: false, Message: "Cancelled while waiting for the next code"}, nil
+				case <-time.After(left):
+				}
+				now = time.Now()
+			}
+
+			return GetTOTPResult{
+				Success:         true,
+				Message:         "Generated authenticator code",
+				Code:            params.code(now),
+				ValidForSeconds: int(params.remaining(now) / time.Second),
+			}, nil
+		},
+	)
+}
+
+// emailClockSkew widens the search window for emails sent just before the run
+// started, or stamped by a server whose clock is slightly behind.
+const emailClockSkew = time.Minute
+
+// CreateGetEmailCodeTool creates the get_email_code function tool.
+func (t *BrowserToolkit) CreateGetEmailCodeTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "get_email_code",
+			Description: "Wait for an email with a one-time code (sent during this task) and return the code",
+		},
+		func(ctx tool.Context, args GetEmailCodeArgs) (GetEmailCodeResult, error) {
+			timeout := time.Duration(args.TimeoutSeconds) * time.Second
+			if timeout <= 0 {
+				timeout = 60 * time.Second
+			}
+			if timeout > 5*time.Minute {
+				timeout = 5 * time.Minute
+			}
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			since := t.runStarted.Add(-emailClockSkew)
+			filter := inbox.Filter{From: args.From, Subject: args.SubjectContains}
+			msg, code, err := inbox.WaitForCode(waitCtx, t.inbox, since, filter, 3*time.Second)
+			if err != nil {
+				if waitCtx.Err() == context.DeadlineExceeded {
+					return GetEmailCodeResult{Success: false, Message: fmt.Sprintf("No email with a code arrived within %s", timeout)}, nil
+				}
+				return GetEmailCodeResult{Success: false, Message: fmt.Sprintf("Failed to read email: %v", err)}, nil
+			}
+			return GetEmailCodeResult{
+				Success: true,
+				Message: "Found code in email",
+				Code:    code,
+				From:    msg.From,
+				Subject: msg.Subject,
+			}, nil
+		},
+	)
+}
+
+// CreateLoginTool creates the login function tool.
+func (t *BrowserToolkit) CreateLoginTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "login",
+			Description: "Open the sign-in page of a configured site and get instructions for signing in with its stored credentials",
+		},
+		func(ctx tool.Context, args LoginArgs) (LoginResult, error) {
+			l := t.findLogin(args.Name)
+			if l == nil {
+				names := make([]string, len(t.logins))
+				for i, l := range t.logins {
+					names[i] = l.Name
+				}
+				return LoginResult{Success: false, Message: fmt.Sprintf("No login named %q; configured: %s", args.Name, strings.Join(names, ", "))}, nil
+			}
+
+			if l.LoginURL != "" {
+				if err := t.browser.Navigate(nil, l.LoginURL); err != nil {
+					return LoginResult{Success: false, Message: fmt.Sprintf("Failed to open sign-in page: %v", err)}, nil
+				}
+			}
+			t.pendingLogin = l
+			t.RefreshElementMap()
+			return LoginResult{Success: true, Message: fmt.Sprintf("Opened sign-in page for %s", l.Name), Instructions: loginInstructions(l)}, nil
+		},
+	)
+}
+
+// CreateQuerySelectorTool creates the query_selector function tool.
+func (t *BrowserToolkit) CreateQuerySelectorTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "query_selector",
+			Description: "Find the elements matching a CSS selector or XPath expression, with their tag, text and visibility",
+		},
+		func(ctx tool.Context, args QuerySelectorArgs) (QuerySelectorResult, error) {
+			matches, total, err := t.browser.QuerySelector(ctx, args.Selector)
+			if err != nil {
+				return QuerySelectorResult{Success: false, Message: fmt.Sprintf("Query failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			lines := make([]string, len(matches))
+			for i, m := range matches {
+				line := fmt.Sprintf("%s %q (%.0f,%.0f)", m.TagName, m.Text, m.BoundingBox.X, m.BoundingBox.Y)
+				if !m.Visible {
+					line += " [hidden]"
+				}
+				lines[i] = line
+			}
+			return QuerySelectorResult{Success: true, Message: fmt.Sprintf("%d elements match %s", total, args.Selector), Count: total, Matches: lines}, nil
+		},
+	)
+}
+
+// CreateClickSelectorTool creates the click_selector function tool.
+func (t *BrowserToolkit) CreateClickSelectorTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "click_selector",
+			Description: "Click the first visible element matching a CSS selector or XPath expression, without an element index",
+		},
+		func(ctx tool.Context, args ClickSelectorArgs) (ClickSelectorResult, error) {
+			before := t.markPage()
+			if err := t.browser.ClickSelector(ctx, args.Selector); err != nil {
+				return ClickSelectorResult{Success: false, Message: fmt.Sprintf("Click failed: %v", err), ErrorCode: toolErrorCode(err)}, nil
+			}
+			t.RefreshElementMap()
+			message := fmt.Sprintf("Clicked %s", args.Selector)
+			redirect := t.redirectSince(before)
+			if redirect != nil {
+				message += redirect.note()
+			}
+			return ClickSelectorResult{Success: true, Message: message, Redirect: redirect}, nil
+		},
+	)
+}
+
+// CheckLogin tracks sign-ins across turns. It saves the session once a
+// pending sign-in has left the login page, and returns a note for the model
+// when the current site looks signed out, or "" otherwise.
+func (t *BrowserToolkit) CheckLogin() string {
+	if len(t.logins) == 0 {
+		return ""
+	}
+
+	loggedOut := t.LoggedOut()
+	if t.pendingLogin != nil {
+		if loggedOut == nil && t.loginForURL(t.browser.GetURL()) == t.pendingLogin {
+			t.saveSession(t.pendingLogin)
+			t.pendingLogin = nil
+		}
+		return ""
+	}
+	if loggedOut != nil {
+		return loginRequiredNote(loggedOut)
+	}
+	return ""
+}
+
+// CreateDownloadFileTool creates the download_file function tool.
+func (t *BrowserToolkit) CreateDownloadFileTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "download_file",
+			Description: "Download a file from a link element or URL using the browser's session, and report its path, size and SHA-256",
+		},
+		func(ctx tool.Context, args DownloadFileArgs) (DownloadFileResult, error) {
+			var result *browser.DownloadResult
+			var err error
+			switch {
+			case args.ElementIndex != nil:
+				if t.elementMap == nil {
+					return DownloadFileResult{Success: false, Message: "No elements available. Call get_page_state first."}, nil
+				}
+				var index int
+				if index, err = t.resolveIndex(*args.ElementIndex); err == nil {
+					result, err = t.browser.DownloadFile(ctx, index, t.elementMap, t.downloads)
+				}
+			case args.URL != "":
+				result, err = t.browser.DownloadResource(ctx, args.URL, t.downloads)
+			default:
+				return DownloadFileResult{Success: false, Message: "Provide element_index or url"}, nil
+			}
+			if err != nil {
+				return DownloadFileResult{Success: false, Message: fmt.Sprintf("Download failed: %v", err)}, nil
+			}
+			t.downloadPaths = append(t.downloadPaths, result.Path)
+			return DownloadFileResult{
+				Success:  true,
+				Message:  fmt.Sprintf("Downloaded %s (%d bytes)", result.Filename, result.Size),
+				Path:     result.Path,
+				Filename: result.Filename,
+				Size:     result.Size,
+				SHA256:   result.SHA256,
+			}, nil
+		},
+	)
+}
+
+// CreateAssertTool creates the assert function tool.
+func (t *BrowserToolkit) CreateAssertTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "assert",
+			Description: "Check a condition on the current page (element exists, text contains, URL matches, title contains, or at least N elements) and record whether it passed",
+		},
+		func(ctx tool.Context, args AssertArgs) (AssertResult, error) {
+			expected, actual, passed, err := t.evaluateAssertion(ctx, args)
+			if err != nil {
+				return AssertResult{Success: false, Message: err.Error()}, nil
+			}
+
+			t.assertions = append(t.assertions, Assertion{
+				Type:        args.Type,
+				Description: args.Description,
+				Expected:    expected,
+				Actual:      actual,
+				Passed:      passed,
+				URL:         t.browser.GetURL(),
+				Timestamp:   time.Now(),
+			})
+
+			status := "passed"
+			if !passed {
+				status = "FAILED"
+			}
+			return AssertResult{
+				Success: true,
+				Message: fmt.Sprintf("Assertion %s: %s (expected %s, got %s)", status, args.Description, expected, actual),
+				Passed:  passed,
+				Actual:  actual,
+			}, nil
+		},
+	)
+}
+
+// CreateReportSectionTool creates the report_section function tool.
+func (t *BrowserToolkit) CreateReportSectionTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "report_section",
+			Description: "File the result of one objective of a task with several, as soon as you have it, so it is kept even if the rest of the task fails",
+		},
+		func(ctx tool.Context, args ReportSectionArgs) (ReportSectionResult, error) {
+			objective := strings.TrimSpace(args.Objective)
+			if objective == "" {
+				return ReportSectionResult{Success: false, Message: "Provide the objective"}, nil
+			}
+			switch args.Status {
+			case SectionComplete, SectionPartial, SectionFailed:
+			default:
+				return ReportSectionResult{Success: false, Message: fmt.Sprintf("Unknown status %q: use complete, partial or failed", args.Status)}, nil
+			}
+
+			t.sections = addSection(t.sections, Section{
+				Objective: objective,
+				Status:    args.Status,
+				Summary:   args.Summary,
+				Data:      args.Data,
+				URL:       t.browser.GetURL(),
+				Timestamp: time.Now(),
+			})
+			return ReportSectionResult{
+				Success: true,
+				Message: fmt.Sprintf("Filed %q as %s (%d objectives filed)", objective, args.Status, len(t.sections)),
+			}, nil
+		},
+	)
+}
+
+// CreateSaveFindingTool creates the save_finding function tool.
+func (t *BrowserToolkit) CreateSaveFindingTool() (tool.Tool, error) {
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "save_finding",
+			Description: "Save one entity found on the page (a profile, post, product, listing) as structured fields, for tasks that collect many. Saving the same entity again merges it with the earlier save",
+		},
+		func(ctx tool.Context, args SaveFindingArgs) (SaveFindingResult, error) {
+			if len(args.Data) == 0 {
+				return SaveFindingResult{Success: false, Message: "Provide the entity's fields in data"}, nil
+			}
+			if t.findings == nil {
+				t.findings = newFindingStore(nil, "")
+			}
+			i, dup := t.findings.add(Finding{Data: args.Data, URL: t.browser.GetURL(), Timestamp: time.Now()})
+			count := len(t.findings.findings)
+			if dup {
+				return SaveFindingResult{
+					Success: true,
+					Message: fmt.Sprintf("Already saved as finding %d; merged (%d findings)", i+1, count),
+					Count:   count,
+				}, nil
+			}
+			return SaveFindingResult{
+				Success: true,
+				Message: fmt.Sprintf("Saved finding %d", count),
+				Count:   count,
+			}, nil
+		},
+	)
+}
+
+// addSection adds s to sections, replacing the section of the same objective
+// if there is one.
+func addSection(sections []Section, s Section) []Section {
+	for i, existing := range sections {
+		if strings.EqualFold(existing.Objective, s.Objective) {
+			sections[i] = s
+			return sections
+		}
+	}
+	return append(sections, s)
+}
+
+// evaluateAssertion runs an assertion against the page and describes what
+// was expected and found. err reports invalid arguments, not failed checks.
+func (t *BrowserToolkit) evaluateAssertion(ctx context.Context, args AssertArgs) (expected, actual string, passed bool, err error) {
+	switch args.Type {
+	case AssertElementExists:
+		if args.Selector == "" {
+			return "", "", false, fmt.Errorf("selector is required for %s", args.Type)
+		}
+		n, err := t.browser.CountElements(ctx, args.Selector, args.Text)
+		if err != nil {
+			return "", "", false, err
+		}
+		return fmt.Sprintf("visible %s", args.Selector), fmt.Sprintf("%d matching", n), n > 0, nil
+
+	case AssertCountAtLeast:
+		if args.Selector == "" {
+			return "", "", false, fmt.Errorf("selector is required for %s", args.Type)
+		}
+		n, err := t.browser.CountElements(ctx, args.Selector, args.Text)
+		if err != nil {
+			return "", "", false, err
+		}
+		return fmt.Sprintf(">= %d %s", args.Count, args.Selector), fmt.Sprintf("%d", n), n >= args.Count, nil
+
+	case AssertTextContains:
+		if args.Text == "" {
+			return "", "", false, fmt.Errorf("text is required for %s", args.Type)
+		}
+		selector := args.Selector
+		if selector == "" {
+			selector = "body"
+		}
+		n, err := t.browser.CountElements(ctx, selector, args.Text)
+		if err != nil {
+			return "", "", false, err
+		}
+		actual := "text found"
+		if n == 0 {
+			actual = "text not found"
+		}
+		return fmt.Sprintf("%q in %s", args.Text, selector), actual, n > 0, nil
+
+	case AssertURLMatches:
+		if args.Pattern == "" {
+			return "", "", false, fmt.Errorf("pattern is required for %s", args.Type)
+		}
+		re, err := regexp.Compile(args.Pattern)
+		if err != nil {
+			return "", "", false, fmt.Errorf("invalid pattern: %w", err)
+		}
+		url := t.browser.GetURL()
+		return fmt.Sprintf("URL matching %s", args.Pattern), url, re.MatchString(url), nil
+
+	case AssertTitleContains:
+		if args.Text == "" {
+			return "", "", false, fmt.Errorf("text is required for %s", args.Type)
+		}
+		title := t.browser.GetTitle()
+		return fmt.Sprintf("title containing %q", args.Text), title, strings.Contains(strings.ToLower(title), strings.ToLower(args.Text)), nil
+
+	default:
+		return "", "", false, fmt.Errorf("unknown assertion type %q", args.Type)
+	}
+}
+
+// DownloadNote reports downloads the page started since the last call, or "".
+func (t *BrowserToolkit) DownloadNote() string {
+	finished, failed, pending := t.browser.TakeDownloads()
+	if len(finished) == 0 && len(failed) == 0 && pending == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n<downloads>\n")
+	for _, d := range finished {
+		t.downloadPaths = append(t.downloadPaths, d.Path)
+		sb.WriteString(fmt.Sprintf("<saved path=%q size=\"%d\" sha256=\"%s\"/>\n", d.Path, d.Size, d.SHA256))
+	}
+	for _, f := range failed {
+		sb.WriteString(fmt.Sprintf("<failed>%s</failed>\n", f))
+	}
+	if pending > 0 {
+		sb.WriteString(fmt.Sprintf("<in_progress>%d</in_progress>\n", pending))
+	}
+	sb.WriteString("</downloads>")
+	return sb.String()
+}
+
+// ClosedTabsNote reports the tabs closed since the last call to stay within
+// the tab limit, or "".
+func (t *BrowserToolkit) ClosedTabsNote() string {
+	closed := t.browser.TakeClosedTabs()
+	if len(closed) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n<closed_tabs reason=\"tab limit reached; the least recently used tabs were closed\">\n")
+	for _, tab := range closed {
+		if tab.Label != "" {
+			sb.WriteString(fmt.Sprintf("<tab id=%q label=%q url=%q/>\n", tab.ID, tab.Label, tab.URL))
+		} else {
+			sb.WriteString(fmt.Sprintf("<tab id=%q url=%q/>\n", tab.ID, tab.URL))
+		}
+	}
+	sb.WriteString("</closed_tabs>")
+	return sb.String()
+}
+
 // CreateAllTools creates all browser automation tools.
 func (t *BrowserToolkit) CreateAllTools() ([]tool.Tool, error) {
-	tools := make([]tool.Tool, 0, 23)
+	tools := make([]tool.Tool, 0, 30)
 
 	navigateTool, err := t.CreateNavigateTool()
 	if err != nil {
@@ -855,6 +2025,12 @@ func (t *BrowserToolkit) CreateAllTools() ([]tool.Tool, error) {
 	}
 	tools = append(tools, scrollToElementTool)
 
+	getElementDetailsTool, err := t.CreateGetElementDetailsTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create get_element_details tool: %w", err)
+	}
+	tools = append(tools, getElementDetailsTool)
+
 	extractContentTool, err := t.CreateExtractContentTool()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create extract_content tool: %w", err)
@@ -867,12 +2043,6 @@ func (t *BrowserToolkit) CreateAllTools() ([]tool.Tool, error) {
 	}
 	tools = append(tools, screenshotTool)
 
-	evaluateJSTool, err := t.CreateEvaluateJSTool()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create evaluate_js tool: %w", err)
-	}
-	tools = append(tools, evaluateJSTool)
-
 	waitTool, err := t.CreateWaitTool()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create wait tool: %w", err)
@@ -903,12 +2073,122 @@ func (t *BrowserToolkit) CreateAllTools() ([]tool.Tool, error) {
 	}
 	tools = append(tools, listTabsTool)
 
+	listFramesTool, err := t.CreateListFramesTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list_frames tool: %w", err)
+	}
+	tools = append(tools, listFramesTool)
+
+	switchFrameTool, err := t.CreateSwitchFrameTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create switch_frame tool: %w", err)
+	}
+	tools = append(tools, switchFrameTool)
+
 	getPageStateTool, err := t.CreateGetPageStateTool()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create get_page_state tool: %w", err)
 	}
 	tools = append(tools, getPageStateTool)
 
+	downloadFileTool, err := t.CreateDownloadFileTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download_file tool: %w", err)
+	}
+	tools = append(tools, downloadFileTool)
+
+	assertTool, err := t.CreateAssertTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create assert tool: %w", err)
+	}
+	tools = append(tools, assertTool)
+
+	reportSectionTool, err := t.CreateReportSectionTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report_section tool: %w", err)
+	}
+	tools = append(tools, reportSectionTool)
+
+	saveFindingTool, err := t.CreateSaveFindingTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create save_finding tool: %w", err)
+	}
+	tools = append(tools, saveFindingTool)
+
+	solveCaptchaTool, err := t.CreateSolveCaptchaTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create solve_captcha tool: %w", err)
+	}
+	tools = append(tools, solveCaptchaTool)
+
+	requestHumanTakeoverTool, err := t.CreateRequestHumanTakeoverTool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request_human_takeover tool: %w", err)
+	}
+	tools = append(tools, requestHumanTakeoverTool)
+
+	if len(t.totpSecrets) > 0 {
+		getTOTPTool, err := t.CreateGetTOTPTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create get_totp tool: %w", err)
+		}
+		tools = append(tools, getTOTPTool)
+	}
+
+	if t.inbox != nil {
+		getEmailCodeTool, err := t.CreateGetEmailCodeTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create get_email_code tool: %w", err)
+		}
+		tools = append(tools, getEmailCodeTool)
+	}
+
+	if len(t.logins) > 0 {
+		loginTool, err := t.CreateLoginTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create login tool: %w", err)
+		}
+		tools = append(tools, loginTool)
+	}
+
+	if t.ocr != nil {
+		readTextTool, err := t.CreateReadTextTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create read_text tool: %w", err)
+		}
+		tools = append(tools, readTextTool)
+	}
+
+	if t.allowJSEval {
+		evalJSTool, err := t.CreateEvalJSTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create eval_js tool: %w", err)
+		}
+		tools = append(tools, evalJSTool)
+	}
+
+	if t.allowCDP {
+		cdpCallTool, err := t.CreateCDPCallTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cdp_call tool: %w", err)
+		}
+		tools = append(tools, cdpCallTool)
+	}
+
+	if t.selectorTools {
+		querySelectorTool, err := t.CreateQuerySelectorTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create query_selector tool: %w", err)
+		}
+		tools = append(tools, querySelectorTool)
+
+		clickSelectorTool, err := t.CreateClickSelectorTool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create click_selector tool: %w", err)
+		}
+		tools = append(tools, clickSelectorTool)
+	}
+
 	doneTool, err := t.CreateDoneTool()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create done tool: %w", err)
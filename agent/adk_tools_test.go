@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/dom"
+)
+
+// runTool runs the tool named name of tk's tools with args, in a tool
+// context of which only the context.Context methods work.
+func runTool(t *testing.T, tk *BrowserToolkit, name string, args map[string]any) map[string]any {
+	t.Helper()
+	tools, err := tk.CreateAllTools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tl := range tools {
+		if tl.Name() != name {
+			continue
+		}
+		ft, ok := tl.(functionTool)
+		if !ok {
+			t.Fatalf("tool %s cannot be run", name)
+		}
+		result, err := ft.Run(toolContext{ctx: context.Background()}, args)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("no tool %s", name)
+	return nil
+}
+
+func TestToolkitDrivesFake(t *testing.T) {
+	fake := browser.NewFake(map[string]*browser.FakePage{
+		"https://shop.example/": {
+			Title: "Shop",
+			Elements: []*dom.Element{
+				{Index: 0, TagName: "a", Text: "Widgets", IsVisible: true, IsEnabled: true},
+			},
+			Links: map[int]string{0: "https://shop.example/widgets"},
+		},
+		"https://shop.example/widgets": {Title: "Widgets", Content: "Blue widget $5"},
+	})
+	tk := NewBrowserToolkit(fake, 1280)
+
+	if r := runTool(t, tk, "navigate", map[string]any{"url": "https://shop.example/"}); r["success"] != true {
+		t.Fatalf("navigate = %v", r)
+	}
+	if r := runTool(t, tk, "click", map[string]any{"element_index": 0}); r["success"] != true {
+		t.Fatalf("click = %v", r)
+	}
+	if got := fake.GetURL(); got != "https://shop.example/widgets" {
+		t.Errorf("URL after click = %s", got)
+	}
+
+	for range 2 {
+		r := runTool(t, tk, "save_finding", map[string]any{"data": map[string]any{"name": "Blue widget", "price": 5}})
+		if r["success"] != true {
+			t.Fatalf("save_finding = %v", r)
+		}
+	}
+	findings := tk.findings.list()
+	if len(findings) != 1 {
+		t.Fatalf("saved %d findings, want the repeat merged into 1", len(findings))
+	}
+	if findings[0].URL != "https://shop.example/widgets" {
+		t.Errorf("finding URL = %s", findings[0].URL)
+	}
+
+	want := []string{"navigate https://shop.example/", "click 0"}
+	if got := fake.Actions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions() = %q, want %q", got, want)
+	}
+}
+
+func TestToolkitClickWithoutElements(t *testing.T) {
+	fake := browser.NewFake(nil)
+	tk := NewBrowserToolkit(fake, 1280)
+
+	r := runTool(t, tk, "click", map[string]any{"element_index": 3})
+	if r["success"] != false || r["error_code"] != string(ErrorCodeElementNotFound) {
+		t.Errorf("click = %v, want an element_not_found failure", r)
+	}
+	if got := fake.Actions(); len(got) != 0 {
+		t.Errorf("Actions() = %q, want none", got)
+	}
+}
@@ -3,12 +3,25 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/captcha"
+	"github.com/anxuanzi/bua/consent"
+	"github.com/anxuanzi/bua/dom"
+	"github.com/anxuanzi/bua/inbox"
+	"github.com/anxuanzi/bua/logging"
+	"github.com/anxuanzi/bua/ocr"
+	"github.com/anxuanzi/bua/ratelimit"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model/gemini"
@@ -22,34 +35,125 @@ type BrowserAgent struct {
 	agent           agent.Agent
 	runner          *runner.Runner
 	sessionService  session.Service
-	browser         *browser.Browser
+	browser         browser.Interface
 	toolkit         *BrowserToolkit
 	messageManager  *MessageManager
 	maxSteps        int
 	maxFailures     int
-	debug           bool
+	logger          *slog.Logger
 	steps           []Step
 	screenshotDir   string
 	screenshotPaths []string
+	screenshots     *screenshotWriter
 	useVision       bool
 	maxWidth        int
 	showAnnotations bool // Enable element annotations on screenshots
+	onStep          func(Step)
+	hooks           Hooks
+	progress        progressTracker
+	siteRules       []SiteRule
+
+	// examples are all the agent's examples; runExamples those of the current run.
+	examples    []Example
+	runExamples []Example
+
+	// consent dismisses cookie banners; consentAsked holds the pages whose
+	// banner the planner model was already asked about.
+	consent      *consent.Config
+	consentAsked map[string]bool
+
+	// ignoreBlocks turns off block-page detection; loginWall is the last
+	// login wall the current run met.
+	ignoreBlocks bool
+	loginWall    *BlockedError
+
+	// architecture is ArchitectureSingle or ArchitecturePlanExecute.
+	architecture string
+
+	// verifyDone has a critic check successful done calls before they stand.
+	verifyDone bool
+
+	// planner talks to the planner model directly, for planning, verification,
+	// critique and consent banners. Nil unless one of those is enabled.
+	planner      *genai.Client
+	plannerModel string
+
+	// failureCaptured tracks whether the current failure streak already produced a screenshot.
+	failureCaptured bool
+
+	// tracer traces runs and counts the tokens they use.
+	tracer *runTracer
+
+	// model, pricing and maxCost price the tokens of a run. runUsage is the
+	// tracer's usage when the current run started.
+	model    string
+	pricing  Pricing
+	maxCost  float64
+	runUsage TokenUsage
+
+	// maxTokens is the current run's RunOptions.MaxTokens.
+	maxTokens int
+
+	// summaryFormat is the current run's RunOptions.SummaryFormat.
+	summaryFormat SummaryFormat
+
+	// normalize is the current run's RunOptions.Normalize.
+	normalize bool
+
+	// findingKeys and findingMerge are the current run's
+	// RunOptions.FindingKeys and FindingMerge.
+	findingKeys  []string
+	findingMerge MergeStrategy
+
+	// strict fails runs that did not end with done(success=true) and data
+	// matching schema, the current run's RunOptions.Schema.
+	strict bool
+	schema *dataSchema
+
+	// forceDone leaves the agent a last turn to call done when a run's step
+	// or token budget is nearly used up, instead of ending it.
+	forceDone bool
+
+	// toolFilter applies the run's enabled and disabled tools.
+	toolFilter *toolFilter
+
+	// generation applies the agent's and the run's generation settings.
+	generation *generation
+
+	// cache holds the system prompt and tools in cached content. Nil unless
+	// ContextCaching is enabled.
+	cache *contextCache
 }
 
+// failureScreenshotThreshold is the number of consecutive failed actions that
+// triggers an automatic diagnostic screenshot.
+const failureScreenshotThreshold = 3
+
+// maxStepResultLen caps the tool result stored on each Step.
+const maxStepResultLen = 1000
+
 // Step represents a single step in the agent's execution.
 type Step struct {
-	Number         int       `json:"number"`
-	Action         string    `json:"action"`
-	Target         string    `json:"target,omitempty"`
-	Thinking       string    `json:"thinking,omitempty"`
-	Evaluation     string    `json:"evaluation,omitempty"`
-	Memory         string    `json:"memory,omitempty"`
-	NextGoal       string    `json:"next_goal,omitempty"`
-	Result         string    `json:"result,omitempty"`
-	Success        bool      `json:"success"`
-	Timestamp      time.Time `json:"timestamp"`
-	DurationMs     int64     `json:"duration_ms"`
-	ScreenshotPath string    `json:"screenshot_path,omitempty"`
+	Number              int       `json:"number"`
+	Action              string    `json:"action"`
+	Target              string    `json:"target,omitempty"`
+	Thinking            string    `json:"thinking,omitempty"`
+	Evaluation          string    `json:"evaluation,omitempty"`
+	Memory              string    `json:"memory,omitempty"`
+	NextGoal            string    `json:"next_goal,omitempty"`
+	Result              string    `json:"result,omitempty"`
+	Error               string    `json:"error,omitempty"`
+	ErrorCode           ErrorCode `json:"error_code,omitempty"`
+	Success             bool      `json:"success"`
+	URL                 string    `json:"url,omitempty"`
+	Title               string    `json:"title,omitempty"`
+	Timestamp           time.Time `json:"timestamp"`
+	DurationMs          int64     `json:"duration_ms"`
+	ScreenshotPath      string    `json:"screenshot_path,omitempty"`       // What the model saw before acting
+	AfterScreenshotPath string    `json:"after_screenshot_path,omitempty"` // Page state after the action
+	// Usage is the tokens of the model call that chose this action. A call
+	// that chose several actions is counted on the first of them only.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // AgentConfig configures the browser agent.
@@ -65,6 +169,150 @@ type AgentConfig struct {
 	Debug           bool
 	ScreenshotDir   string // Directory to save screenshots (empty = no saving)
 	ShowAnnotations bool   // Enable element annotations on screenshots
+
+	// OnStep is called after each tool call completes with the finished step.
+	OnStep func(Step)
+
+	// Hooks are further callbacks into each run.
+	Hooks Hooks
+
+	// ToolMiddleware wraps every tool's handler, the first outermost.
+	ToolMiddleware []ToolMiddleware
+
+	// Retry retries model and tool calls that fail transiently.
+	Retry RetryPolicy
+
+	// ContextCaching keeps the system prompt and tool declarations in Gemini
+	// cached content, billed at cached-token prices. Unavailable with more
+	// than one key in Keys, as caches belong to a project.
+	ContextCaching bool
+
+	// CaptchaSolver solves CAPTCHAs detected on the page. Nil leaves them to
+	// request_human_takeover.
+	CaptchaSolver captcha.Solver
+
+	// OCR reads text from screenshots for the read_text tool and for
+	// extract_content on pages with little text. Nil leaves both out.
+	OCR ocr.Engine
+
+	// Consent dismisses cookie-consent banners after each action (nil = off).
+	Consent *consent.Config
+
+	// RateLimit paces the navigate and new_tab tools per host (nil = no
+	// limit).
+	RateLimit *ratelimit.Limiter
+
+	// IgnoreBlocks keeps runs going on bot challenges, block pages and login
+	// walls instead of ending them with ErrorCodeBlocked.
+	IgnoreBlocks bool
+
+	// FullElementLists sends the full element list in every page state. By
+	// default, a page state of the page the model saw last lists only the
+	// elements that changed, when that is shorter.
+	FullElementLists bool
+
+	// SelectorTools adds the query_selector and click_selector tools, which
+	// act on a CSS selector or XPath expression instead of an element index.
+	SelectorTools bool
+
+	// AllowJSEval adds the eval_js tool, which runs JavaScript on the page.
+	AllowJSEval bool
+
+	// JSEvalOrigins limits eval_js to pages on these domains and their
+	// subdomains, checked against the selected frame's URL if there is
+	// one. Empty means any page.
+	JSEvalOrigins []string
+
+	// DangerouslyAllowCDP adds the cdp_call tool, which sends the model's
+	// raw DevTools protocol commands to the browser.
+	DangerouslyAllowCDP bool
+
+	// OnHumanTakeover is called by request_human_takeover and blocks until a
+	// person has finished in the browser. Nil means no one is available.
+	OnHumanTakeover func(ctx context.Context, reason string) error
+
+	// TOTPSecrets maps names to authenticator secrets for the get_totp tool.
+	// Secrets never reach the model; only the generated codes do.
+	TOTPSecrets map[string]string
+
+	// Inbox receives emailed one-time codes for the get_email_code tool.
+	Inbox inbox.Inbox
+
+	// Logins are sites the agent can sign in to with the login tool. A signed
+	// out page on one of them prompts the agent to sign in again.
+	Logins []Login
+
+	// SessionDir stores cookies after each sign-in (empty = don't save).
+	SessionDir string
+
+	// Secrets are values for <secret>name</secret> placeholders in typed text.
+	Secrets map[string]string
+
+	// Architecture is ArchitectureSingle (default) or ArchitecturePlanExecute.
+	Architecture string
+
+	// PlannerModel plans and verifies in ArchitecturePlanExecute, and
+	// critiques answers with VerifyDone. Default: "gemini-2.5-flash".
+	PlannerModel string
+
+	// VerifyDone has a critic check the data of every successful done call
+	// against the page, sending the agent back to work if it is wrong or
+	// incomplete.
+	VerifyDone bool
+
+	// Generation sets the model's temperature, sampling and thinking.
+	Generation GenerationConfig
+
+	// Language is the locale the agent writes in and expects pages in, e.g.
+	// "de-DE" (empty = no preference).
+	Language string
+
+	// SystemPromptOverride replaces the built-in system prompt (empty = keep it).
+	SystemPromptOverride string
+
+	// SystemPromptExtra is appended to the system prompt, e.g. company
+	// policies or output conventions.
+	SystemPromptExtra string
+
+	// SiteRules add instructions while the agent is on matching sites.
+	SiteRules []SiteRule
+
+	// Examples are worked examples, shown to runs of their category.
+	Examples []Example
+
+	// Pricing adds or replaces entries of DefaultPricing, for Result.EstimatedCost.
+	Pricing Pricing
+
+	// MaxCost aborts a run once its estimated cost exceeds it, in US dollars
+	// (0 = no limit).
+	MaxCost float64
+
+	// StrictSuccess makes a run succeed only if the agent called done with
+	// success=true and, with RunOptions.Schema, data matching the schema.
+	StrictSuccess bool
+
+	// DisableFinalTurn ends runs as soon as MaxSteps or RunOptions.MaxTokens
+	// is reached. By default, the agent gets one last turn in which it may
+	// only call done, to hand in what it has gathered.
+	DisableFinalTurn bool
+
+	// Downloads configures the download_file tool.
+	Downloads browser.DownloadOptions
+
+	// HTTPClient sends model requests, e.g. to record or replay them
+	// (nil = default client).
+	HTTPClient *http.Client
+
+	// Keys spreads model requests over several API keys, overriding APIKey.
+	Keys *KeyPool
+
+	// TracerProvider receives spans for runs, model calls and tool calls
+	// (nil = the global OpenTelemetry provider).
+	TracerProvider trace.TracerProvider
+
+	// Logger receives the agent's logs (nil = console output, verbose with
+	// Debug).
+	Logger *slog.Logger
 }
 
 // Result represents the outcome of an agent run.
@@ -72,16 +320,41 @@ type Result struct {
 	Success         bool          `json:"success"`
 	Data            any           `json:"data,omitempty"`
 	Error           string        `json:"error,omitempty"`
+	ErrorCode       ErrorCode     `json:"error_code,omitempty"`
 	Steps           []Step        `json:"steps"`
 	Duration        time.Duration `json:"duration"`
-	TokensUsed      int           `json:"tokens_used,omitempty"`
+	TokensUsed      int           `json:"tokens_used,omitempty"`   // Usage.TotalTokens
+	CachedTokens    int           `json:"cached_tokens,omitempty"` // Usage.CachedTokens
+	Usage           TokenUsage    `json:"usage"`
+	EstimatedCost   float64       `json:"estimated_cost,omitempty"` // US dollars, per Pricing
 	ScreenshotPaths []string      `json:"screenshot_paths,omitempty"`
+	Blocked         *BlockedError `json:"blocked,omitempty"` // Set with ErrorCodeBlocked
+
+	// PartialResult is set if the run ended without done, e.g. at its step
+	// limit, and Data holds a *PartialData with what it found so far.
+	PartialResult bool `json:"partial_result,omitempty"`
+
+	// CompletionReason says how the run ended.
+	CompletionReason CompletionReason `json:"completion_reason"`
+
+	// Tabs is the state of every open tab at the end, with RunOptions.CaptureTabs.
+	Tabs []TabState `json:"tabs,omitempty"`
+
+	// Summary is the agent's account of the run from its done call, in
+	// RunOptions.SummaryFormat.
+	Summary string `json:"summary,omitempty"`
 }
 
 // NewBrowserAgent creates a new browser agent using ADK.
-func NewBrowserAgent(ctx context.Context, cfg AgentConfig, b *browser.Browser) (*BrowserAgent, error) {
-	// Get API key from config or environment
+func NewBrowserAgent(ctx context.Context, cfg AgentConfig, b browser.Interface) (*BrowserAgent, error) {
+	// Get API key from config, key pool or environment
 	apiKey := cfg.APIKey
+	httpClient := cfg.HTTPClient
+	if cfg.Keys != nil && cfg.Keys.Len() > 0 {
+		// The pool sets the key of every request; the client just needs one
+		httpClient = cfg.Keys.Client(httpClient)
+		apiKey = cfg.Keys.keys[0].key
+	}
 	if apiKey == "" {
 		apiKey = os.Getenv("GOOGLE_API_KEY")
 	}
@@ -130,33 +403,112 @@ func NewBrowserAgent(ctx context.Context, cfg AgentConfig, b *browser.Browser) (
 
 	// Create Gemini model using ADK
 	model, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
-		APIKey: apiKey,
+		APIKey:     apiKey,
+		HTTPClient: httpClient,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini model: %w", err)
 	}
+	logger := logging.Or(cfg.Logger, cfg.Debug)
+	retry := cfg.Retry.withDefaults()
+	llm := &retryModel{LLM: model, policy: retry, log: logging.Component(logger, "Agent")}
+
+	var cache *contextCache
+	if cfg.ContextCaching {
+		if cfg.Keys != nil && cfg.Keys.Len() > 1 {
+			logging.Component(logger, "Agent").Warn("Context caching is unavailable with several API keys")
+		} else {
+			client, err := genai.NewClient(ctx, &genai.ClientConfig{
+				APIKey:     apiKey,
+				Backend:    genai.BackendGeminiAPI,
+				HTTPClient: httpClient,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create cache client: %w", err)
+			}
+			cache = newContextCache(client, modelName, logging.Component(logger, "Cache"))
+		}
+	}
 
 	// Create browser toolkit with tools
 	toolkit := NewBrowserToolkit(b, maxWidth)
+	toolkit.captchaSolver = cfg.CaptchaSolver
+	toolkit.ocr = cfg.OCR
+	toolkit.onHumanTakeover = cfg.OnHumanTakeover
+	toolkit.totpSecrets = cfg.TOTPSecrets
+	toolkit.inbox = cfg.Inbox
+	toolkit.logins = cfg.Logins
+	toolkit.extraSecrets = cfg.Secrets
+	toolkit.sessionDir = cfg.SessionDir
+	toolkit.downloads = cfg.Downloads
+	toolkit.rateLimit = cfg.RateLimit
+	toolkit.fullElements = cfg.FullElementLists
+	toolkit.maxElements = maxElements
+	toolkit.selectorTools = cfg.SelectorTools
+	toolkit.allowJSEval = cfg.AllowJSEval
+	toolkit.jsEvalOrigins = cfg.JSEvalOrigins
+	toolkit.allowCDP = cfg.DangerouslyAllowCDP
+	for _, l := range cfg.Logins {
+		if l.TOTPSecret == "" {
+			continue
+		}
+		if _, ok := toolkit.totpSecrets[l.Name]; ok {
+			continue
+		}
+		// Copy before adding so the caller's map is left alone
+		secrets := make(map[string]string, len(toolkit.totpSecrets)+1)
+		for name, secret := range toolkit.totpSecrets {
+			secrets[name] = secret
+		}
+		secrets[l.Name] = l.TOTPSecret
+		toolkit.totpSecrets = secrets
+	}
 	tools, err := toolkit.CreateAllTools()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser tools: %w", err)
 	}
+	middleware := cfg.ToolMiddleware
+	if retry.MaxAttempts > 1 {
+		// Innermost, so other middleware sees a retried call once
+		middleware = append(slices.Clip(middleware), retryMiddleware(retry, logging.Component(logger, "Agent")))
+	}
+	tools, err = wrapTools(tools, middleware)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create message manager
 	messageManager := NewMessageManager(MessageManagerConfig{
 		MaxHistoryItems: maxHistoryItems,
 		MaxElements:     maxElements,
 		UseVision:       !cfg.TextOnly,
+		SystemPrompt:    buildSystemPrompt(cfg.SystemPromptOverride, cfg.SystemPromptExtra, cfg.Language),
+
+		FullElementLists: cfg.FullElementLists,
 	})
 
-	// Create LLM agent using ADK
+	// Create LLM agent using ADK, traced through its callbacks
+	tracer := newRunTracer(cfg.TracerProvider, b, modelName)
+	filter := newToolFilter(tools)
+	if err := checkExamples(cfg.Examples, filter.names); err != nil {
+		return nil, err
+	}
+	gen := &generation{base: cfg.Generation, run: cfg.Generation}
+	beforeModel := []llmagent.BeforeModelCallback{filter.beforeModel, gen.beforeModel}
+	if cache != nil {
+		beforeModel = append(beforeModel, cache.beforeModel)
+	}
+	beforeModel = append(beforeModel, tracer.beforeModel)
 	llmAgent, err := llmagent.New(llmagent.Config{
-		Name:        "browser_agent",
-		Model:       model,
-		Description: "An expert web browser automation agent that helps users accomplish tasks by interacting with web pages.",
-		Instruction: messageManager.GetSystemPrompt(),
-		Tools:       tools,
+		Name:                 "browser_agent",
+		Model:                llm,
+		Description:          "An expert web browser automation agent that helps users accomplish tasks by interacting with web pages.",
+		Instruction:          messageManager.GetSystemPrompt(),
+		Tools:                tools,
+		BeforeModelCallbacks: beforeModel,
+		AfterModelCallbacks:  []llmagent.AfterModelCallback{tracer.afterModel},
+		BeforeToolCallbacks:  []llmagent.BeforeToolCallback{tracer.beforeTool, filter.beforeTool, cfg.Hooks.beforeTool},
+		AfterToolCallbacks:   []llmagent.AfterToolCallback{tracer.afterTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LLM agent: %w", err)
@@ -175,6 +527,38 @@ func NewBrowserAgent(ctx context.Context, cfg AgentConfig, b *browser.Browser) (
 		return nil, fmt.Errorf("failed to create runner: %w", err)
 	}
 
+	// Plan-execute mode talks to the planner model directly
+	architecture := cfg.Architecture
+	if architecture == "" {
+		architecture = ArchitectureSingle
+	}
+	if architecture != ArchitectureSingle && architecture != ArchitecturePlanExecute {
+		return nil, fmt.Errorf("unknown architecture %q", architecture)
+	}
+	var planner *genai.Client
+	plannerModel := cfg.PlannerModel
+	if architecture == ArchitecturePlanExecute || cfg.VerifyDone || (cfg.Consent != nil && cfg.Consent.AskModel) {
+		planner, err = genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:     apiKey,
+			Backend:    genai.BackendGeminiAPI,
+			HTTPClient: httpClient,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create planner client: %w", err)
+		}
+		if plannerModel == "" {
+			plannerModel = defaultPlannerModel
+		}
+	}
+
+	pricing := cfg.Pricing.withDefaults()
+	if _, ok := pricing.Price(modelName); !ok {
+		if cfg.MaxCost > 0 {
+			return nil, fmt.Errorf("no price for model %q: add it to Pricing to use MaxCost", modelName)
+		}
+		logger.Debug("No price for model, costs are not estimated", "model", modelName)
+	}
+
 	// Create screenshot directory if specified
 	screenshotDir := cfg.ScreenshotDir
 	if screenshotDir != "" {
@@ -192,30 +576,132 @@ func NewBrowserAgent(ctx context.Context, cfg AgentConfig, b *browser.Browser) (
 		messageManager:  messageManager,
 		maxSteps:        maxSteps,
 		maxFailures:     maxFailures,
-		debug:           cfg.Debug,
+		logger:          logger,
 		steps:           make([]Step, 0),
 		screenshotDir:   screenshotDir,
 		screenshotPaths: make([]string, 0),
+		screenshots:     newScreenshotWriter(b, logging.Component(logger, "Screenshot")),
 		useVision:       !cfg.TextOnly,
 		maxWidth:        maxWidth,
 		showAnnotations: cfg.ShowAnnotations,
+		onStep:          cfg.OnStep,
+		hooks:           cfg.Hooks,
+		siteRules:       cfg.SiteRules,
+		examples:        cfg.Examples,
+		consent:         cfg.Consent,
+		consentAsked:    make(map[string]bool),
+		ignoreBlocks:    cfg.IgnoreBlocks,
+		architecture:    architecture,
+		verifyDone:      cfg.VerifyDone,
+		planner:         planner,
+		plannerModel:    plannerModel,
+		tracer:          tracer,
+		model:           modelName,
+		pricing:         pricing,
+		maxCost:         cfg.MaxCost,
+		forceDone:       !cfg.DisableFinalTurn,
+		strict:          cfg.StrictSuccess,
+		toolFilter:      filter,
+		generation:      gen,
+		cache:           cache,
 	}, nil
 }
 
 // Run executes a task and returns the result.
 func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
+	return a.RunWithOptions(ctx, task, RunOptions{})
+}
+
+// RunWithOptions executes a task with per-run options and returns the result.
+func (a *BrowserAgent) RunWithOptions(ctx context.Context, task string, opts RunOptions) (*Result, error) {
+	examples, err := examplesFor(a.examples, opts.ExampleCategory)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.toolFilter.set(opts); err != nil {
+		return nil, err
+	}
+	schema, err := newDataSchema(opts.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSummaryFormat(opts.SummaryFormat); err != nil {
+		return nil, err
+	}
+	if err := checkMergeStrategy(opts.FindingMerge); err != nil {
+		return nil, err
+	}
+	a.schema = schema
+	a.summaryFormat = opts.SummaryFormat
+	a.normalize = opts.Normalize
+	a.findingKeys, a.findingMerge = opts.FindingKeys, opts.FindingMerge
+	a.runExamples = examples
+	a.maxTokens = opts.MaxTokens
+	a.generation.set(opts.Generation)
+
+	ctx, end := a.tracer.startRun(ctx, task, a.architecture)
+	a.runUsage = a.tracer.tokenUsage()
+	a.progress.reset(task, a.maxSteps)
+
+	var result *Result
+	if a.architecture == ArchitecturePlanExecute {
+		result, err = a.runPlanExecute(ctx, task)
+	} else {
+		result, err = a.runLoop(ctx, task)
+	}
+	if result != nil && opts.CaptureTabs {
+		result.Tabs = a.captureTabs(context.WithoutCancel(ctx)) // also after a timeout
+		result.ScreenshotPaths = a.screenshotPaths
+	}
+	// Screenshots are written in the background; they must be on disk before
+	// the caller sees their paths
+	a.screenshots.flush()
+	if result != nil {
+		result.EstimatedCost, _ = a.pricing.Cost(a.model, result.Usage)
+		result.Summary = FormatSummary(result.Summary, opts.SummaryFormat)
+	}
+	end(result, err)
+	if err == nil {
+		a.hooks.reportProgress(a.progress.finish())
+	}
+
+	switch {
+	case err != nil && a.hooks.OnError != nil:
+		a.hooks.OnError(err)
+	case err == nil && a.hooks.OnDone != nil:
+		a.hooks.OnDone(result)
+	}
+	return result, err
+}
+
+// runLoop runs the task in a single agent loop until done or the step limit.
+func (a *BrowserAgent) runLoop(ctx context.Context, task string) (*Result, error) {
 	startTime := time.Now()
+	startUsage := a.tracer.tokenUsage()
+	a.toolkit.runStarted = startTime
+	a.toolkit.pendingLogin = nil
+	a.toolkit.downloadPaths = nil
+	a.toolkit.assertions = nil
+	a.toolkit.sections = nil
+	a.toolkit.findings = newFindingStore(a.findingKeys, a.findingMerge)
+	a.toolkit.visited = nil
+	a.toolkit.goal = task
 	a.steps = make([]Step, 0)
 	a.screenshotPaths = make([]string, 0)
+	a.failureCaptured = false
+	a.loginWall = nil
 	a.messageManager.Clear()
 	a.messageManager.SetTask(task)
 
 	// Get initial page state
+	a.dismissConsent(ctx)
+	blocked, blockNote := a.checkBlock(ctx)
+	if blocked != nil {
+		return blockedResult(blocked).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+	}
 	if err := a.toolkit.RefreshElementMap(); err != nil {
 		// Continue even if initial state fails - page might be blank
-		if a.debug {
-			fmt.Printf("[Debug] Initial page state: %v\n", err)
-		}
+		a.log("Agent").Debug("Initial page state unavailable", "err", err)
 	}
 
 	// Generate a unique session ID for this task
@@ -234,6 +720,15 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 
 	// Build the initial task message with page state
 	taskMessage := a.messageManager.BuildInitialTaskMessage(task, a.toolkit.GetElementMap())
+	a.toolkit.MarkShown()
+	taskMessage += a.toolkit.CheckLogin()
+	taskMessage += blockNote
+	taskMessage += siteRulesNote(a.siteRules, a.browser.GetURL())
+	taskMessage += examplesNote(a.runExamples)
+	if a.schema != nil {
+		taskMessage += a.schema.note()
+	}
+	taskMessage += summaryFormatNote(a.summaryFormat)
 
 	// Filter sensitive data
 	taskMessage = a.messageManager.FilterSensitiveData(taskMessage)
@@ -243,6 +738,7 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 	if a.useVision {
 		screenshotData, _, err := a.captureAndSaveScreenshot(ctx, 0)
 		if err == nil && len(screenshotData) > 0 {
+			a.hooks.screenshot(1, screenshotData)
 			userContent = a.createMultimodalContent(taskMessage, screenshotData)
 		} else {
 			userContent = genai.NewContentFromText(taskMessage, "user")
@@ -260,26 +756,59 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 	var lastActionResult string
 	var lastActionSuccess bool
 	var lastScreenshotData []byte // Reuse screenshot for continuation message
-
-	for toolCallNum < a.maxSteps && !taskComplete {
+	var doneSummary string        // Summary from the last done call, for the critic
+	criticRejections := 0
+	schemaRejections := 0
+	var loops loopDetector // Repeated actions, to break them
+	loopWarnings := 0
+	var partial partialCollector         // What was found, in case done is never called
+	finalTurn := false                   // a budget is spent and only done is left
+	var finalCode ErrorCode              // the budget that is spent
+	stepByCallID := make(map[string]int) // Function call ID -> index into a.steps
+
+	// The final turn may take one step past the limit
+	for (toolCallNum < a.maxSteps || finalTurn) && !taskComplete {
 		turnNum++
 
-		if a.debug {
-			fmt.Printf("[Turn %d] Starting...\n", turnNum)
+		a.log("Agent").Debug("Starting turn", "turn", turnNum)
+		if a.hooks.OnStepStart != nil {
+			a.hooks.OnStepStart(toolCallNum + 1)
+		}
+
+		// Capture a diagnostic screenshot once per streak of repeated tool errors
+		consecutiveFailures := a.messageManager.GetHistory().GetConsecutiveFailures()
+		if consecutiveFailures == 0 {
+			a.failureCaptured = false
+		} else if consecutiveFailures >= failureScreenshotThreshold && !a.failureCaptured {
+			a.captureFailureScreenshot(ctx, "repeated_errors")
+			a.failureCaptured = true
 		}
 
 		// Check for too many consecutive failures
-		if a.messageManager.GetHistory().GetConsecutiveFailures() >= a.maxFailures {
-			if a.debug {
-				fmt.Printf("[Turn %d] Too many consecutive failures (%d), forcing completion\n", turnNum, a.maxFailures)
-			}
-			return &Result{
+		if consecutiveFailures >= a.maxFailures {
+			a.log("Agent").Warn("Too many consecutive failures, forcing completion", "turn", turnNum, "failures", a.maxFailures)
+			a.captureFailureScreenshot(ctx, "aborted")
+			return partial.finish(&Result{
 				Success:         false,
 				Error:           fmt.Sprintf("Task aborted after %d consecutive failures", a.maxFailures),
+				ErrorCode:       ErrorCodeRepeatedFailures,
 				Steps:           a.steps,
 				Duration:        time.Since(startTime),
 				ScreenshotPaths: a.screenshotPaths,
-			}, nil
+			}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
+
+		// Solve CAPTCHAs before the model sees the page, so it never has to ask
+		if a.toolkit.captchaSolver != nil {
+			challenge, solved, err := a.toolkit.SolveCaptcha(ctx)
+			if err != nil && challenge != nil {
+				a.log("Captcha").Warn("Not solved", "kind", challenge.Kind, "url", challenge.PageURL, "err", err)
+			}
+			if solved {
+				a.log("Captcha").Debug("Solved", "kind", challenge.Kind, "url", challenge.PageURL)
+				a.browser.WaitStable(nil)
+				a.toolkit.RefreshElementMap()
+			}
 		}
 
 		// Capture screenshot at START of each turn (before action execution)
@@ -287,15 +816,29 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 		// The screenshot path is saved with the Step to record what the model saw
 		var turnScreenshotPath string
 		if a.useVision {
-			_, path, err := a.captureAndSaveScreenshot(ctx, turnNum)
-			if err == nil {
-				turnScreenshotPath = path
-			}
+			turnScreenshotPath = a.recordScreenshot(ctx, turnNum)
 		}
 
 		// Run the agent for one turn using iter.Seq2 pattern
+		var refused string
+		overBudget := false
+		outOfTokens := false
 		for event, err := range a.runner.Run(ctx, userID, sessionID, userContent, agent.RunConfig{}) {
 			if err != nil {
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					a.log("Agent").Warn("Run timed out", "turn", turnNum)
+					return partial.finish(&Result{
+						Success:         false,
+						Error:           "Timed out before the task was finished",
+						ErrorCode:       ErrorCodeTimeout,
+						Steps:           a.steps,
+						Duration:        time.Since(startTime),
+						ScreenshotPaths: a.screenshotPaths,
+					}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+				}
+				if coded := modelError(err); coded != nil {
+					err = fmt.Errorf("%w: %w", coded, err)
+				}
 				return nil, fmt.Errorf("agent error at turn %d: %w", turnNum, err)
 			}
 
@@ -303,6 +846,15 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 				continue
 			}
 
+			if reason := refusal(event.FinishReason, event.ErrorCode); reason != "" {
+				refused = reason
+				break
+			}
+
+			// The usage of this model call goes on the first step it chose
+			callUsage := usageFrom(event.UsageMetadata)
+			usageRecorded := event.UsageMetadata == nil
+
 			// Check for function calls (tool usage)
 			if event.Content != nil {
 				for _, part := range event.Content.Parts {
@@ -313,9 +865,7 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 						toolArgs, _ := json.Marshal(part.FunctionCall.Args)
 						callStart := time.Now()
 
-						if a.debug {
-							fmt.Printf("[Step %d] Tool call: %s\n", toolCallNum, toolName)
-						}
+						a.log("Agent").Debug("Tool call", "step", toolCallNum, "tool", toolName)
 
 						lastActionName = toolName
 						lastActionSuccess = true // Will be updated by response
@@ -328,9 +878,18 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 							Timestamp:      callStart,
 							DurationMs:     0, // Will be updated
 							Success:        true,
+							URL:            a.browser.GetURL(),
+							Title:          a.browser.GetTitle(),
 							ScreenshotPath: turnScreenshotPath,
 						}
+						if !usageRecorded {
+							step.Usage = &callUsage
+							usageRecorded = true
+						}
 						a.steps = append(a.steps, step)
+						if part.FunctionCall.ID != "" {
+							stepByCallID[part.FunctionCall.ID] = len(a.steps) - 1
+						}
 
 						// Add to history
 						historyItem := HistoryItem{
@@ -348,9 +907,13 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 							taskComplete = true
 							var doneArgs DoneArgs
 							if err := json.Unmarshal(toolArgs, &doneArgs); err == nil {
+								if !doneArgs.Success {
+									a.captureFailureScreenshot(ctx, "task_failed")
+								}
 								lastResult = &Result{
 									Success:         doneArgs.Success,
-									Data:            doneArgs.Data,
+									Data:            a.normalizeData(a.schema, doneArgs.Data),
+									Summary:         doneArgs.Summary,
 									Steps:           a.steps,
 									Duration:        time.Since(startTime),
 									ScreenshotPaths: a.screenshotPaths,
@@ -358,19 +921,31 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 								if !doneArgs.Success {
 									lastResult.Error = doneArgs.Summary
 								}
+								doneSummary = doneArgs.Summary
 							}
 						}
 					}
 
 					// Check for function responses (tool results)
 					if part.FunctionResponse != nil {
-						if a.debug {
-							fmt.Printf("[Step %d] Tool response: %s\n", toolCallNum, part.FunctionResponse.Name)
+						a.log("Agent").Debug("Tool response", "step", toolCallNum, "tool", part.FunctionResponse.Name)
+
+						// Find the step this response belongs to
+						var step *Step
+						if idx, ok := stepByCallID[part.FunctionResponse.ID]; ok {
+							step = &a.steps[idx]
+						} else if len(a.steps) > 0 {
+							step = &a.steps[len(a.steps)-1]
+						}
+						if step != nil {
+							step.DurationMs = time.Since(step.Timestamp).Milliseconds()
+							loops.record(step.Action, step.Target, a.browser.GetURL())
 						}
 
 						// Extract result for history
 						resp := part.FunctionResponse.Response
 						if resp != nil {
+							partial.toolResult(part.FunctionResponse.Name, a.browser.GetURL(), resp)
 							resultBytes, _ := json.Marshal(resp)
 							lastActionResult = string(resultBytes)
 
@@ -380,56 +955,253 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 									lastActionSuccess = successBool
 								}
 							}
+
+							if step != nil {
+								step.Result = lastActionResult
+								if len(step.Result) > maxStepResultLen {
+									step.Result = truncate(step.Result, maxStepResultLen) + "..."
+								}
+								step.Success = lastActionSuccess
+								if msg, ok := resp["message"].(string); ok && !lastActionSuccess {
+									step.Error = msg
+								}
+								if code, ok := resp["error_code"].(string); ok && !lastActionSuccess {
+									step.ErrorCode = ErrorCode(code)
+								}
+							}
 						}
 
 						// Capture screenshot after tool execution for continuation message
 						// Uses captureScreenshotAfterAction which waits for page stability
 						// This ensures the screenshot shows the result of the action
 						if a.useVision {
-							data, _, err := a.captureScreenshotAfterAction(ctx, toolCallNum)
+							data, path, err := a.captureScreenshotAfterAction(ctx, toolCallNum)
 							if err == nil && len(data) > 0 {
 								lastScreenshotData = data // Store for continuation message
+								a.hooks.screenshot(toolCallNum+1, data)
 							}
+							if step != nil && path != "" {
+								step.AfterScreenshotPath = path
+							}
+						}
+
+						if a.onStep != nil && step != nil {
+							a.onStep(*step)
+						}
+						if a.hooks.OnToolResult != nil && step != nil {
+							a.hooks.OnToolResult(*step)
+						}
+						if step != nil {
+							a.hooks.reportProgress(a.progress.step(*step))
 						}
 					}
 
 					// Check for text content (agent reasoning)
-					if part.Text != "" && a.debug {
+					if part.Text != "" && !part.Thought {
+						partial.text(part.Text)
+					}
+					if part.Text != "" {
 						// Only show first 200 chars of reasoning
 						text := part.Text
 						if len(text) > 200 {
 							text = text[:200] + "..."
 						}
-						fmt.Printf("[Turn %d] Agent: %s\n", turnNum, text)
+						a.log("Agent").Debug("Reasoning", "turn", turnNum, "text", text)
 					}
 				}
 			}
 
+			// Stop before the next model call once the run has cost too much,
+			// letting tool calls the model already made run first
+			if !callsTools(event.Content) {
+				if a.overBudget() {
+					overBudget = true
+					break
+				}
+				if !finalTurn && !taskComplete && a.overTokenBudget() {
+					outOfTokens = true
+					break
+				}
+			}
+
 			// Check if this is the final response for this turn
 			if event.IsFinalResponse() {
 				break
 			}
 		}
 
+		if refused != "" {
+			a.log("Agent").Warn("Model refused to continue", "turn", turnNum, "reason", refused)
+			return partial.finish(&Result{
+				Success:         false,
+				Error:           fmt.Sprintf("Model refused to continue (%s)", refused),
+				ErrorCode:       ErrorCodeModelRefusal,
+				Steps:           a.steps,
+				Duration:        time.Since(startTime),
+				ScreenshotPaths: a.screenshotPaths,
+			}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
+
+		if overBudget {
+			a.log("Agent").Warn("Cost budget exhausted", "turn", turnNum, "max_cost", a.maxCost)
+			return partial.finish(&Result{
+				Success:         false,
+				Error:           fmt.Sprintf("Cost budget ($%.4f) exhausted", a.maxCost),
+				ErrorCode:       ErrorCodeCostBudget,
+				Steps:           a.steps,
+				Duration:        time.Since(startTime),
+				ScreenshotPaths: a.screenshotPaths,
+			}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
+
+		// The agent used its last turn without calling done
+		if finalTurn && !taskComplete {
+			a.log("Agent").Warn("Budget exhausted without a final answer", "turn", turnNum, "code", finalCode)
+			return partial.finish(&Result{
+				Success:         false,
+				Error:           a.budgetError(finalCode),
+				ErrorCode:       finalCode,
+				Steps:           a.steps,
+				Duration:        time.Since(startTime),
+				ScreenshotPaths: a.screenshotPaths,
+			}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
+
+		// Send data that does not match the run's schema back to be fixed
+		var schemaNote string
+		if taskComplete && lastResult != nil && lastResult.Success && a.schema != nil {
+			if err := a.schema.validate(lastResult.Data); err != nil {
+				switch {
+				case !finalTurn && schemaRejections < maxSchemaRejections:
+					schemaRejections++
+					a.log("Agent").Debug("Done data does not match the schema", "err", err)
+					if len(a.steps) > 0 {
+						done := &a.steps[len(a.steps)-1]
+						done.Success = false
+						done.Error = "Data does not match the result schema: " + err.Error()
+					}
+					taskComplete = false
+					lastResult = nil
+					lastActionSuccess = false
+					schemaNote = a.schema.feedback(err)
+				case a.strict:
+					lastResult.Success = false
+					lastResult.Error = "Data does not match the result schema: " + err.Error()
+				}
+			}
+		}
+
+		// Have the critic check a successful answer before accepting it
+		var criticNote string
+		if taskComplete && !finalTurn && a.verifyDone && lastResult != nil && lastResult.Success && criticRejections < maxCriticRejections {
+			c, err := a.critique(ctx, task, lastResult, doneSummary)
+			switch {
+			case err != nil:
+				a.log("Critic").Warn("Check failed, accepting answer", "err", err)
+			case !c.Accept:
+				criticRejections++
+				a.log("Critic").Debug("Rejected answer", "issues", strings.Join(c.Issues, "; "))
+				if len(a.steps) > 0 {
+					done := &a.steps[len(a.steps)-1]
+					done.Success = false
+					done.Error = "Rejected by verifier: " + strings.Join(c.Issues, "; ")
+				}
+				taskComplete = false
+				lastResult = nil
+				lastActionSuccess = false
+				criticNote = criticFeedback(c)
+			}
+		}
+
 		// If task is complete, break out of the loop
 		if taskComplete {
 			break
 		}
 
+		// Point out loops, and give up on an agent that keeps looping
+		var loopNote string
+		if loop := loops.detect(); loop != "" {
+			loopWarnings++
+			if loopWarnings > maxLoopWarnings {
+				a.log("Agent").Warn("Stuck in a loop, aborting", "turn", turnNum, "loop", loop)
+				return partial.finish(&Result{
+					Success:         false,
+					Error:           fmt.Sprintf("Task aborted: the agent kept repeating itself (%s)", loop),
+					ErrorCode:       ErrorCodeLoopDetected,
+					Steps:           a.steps,
+					Duration:        time.Since(startTime),
+					ScreenshotPaths: a.screenshotPaths,
+				}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+			}
+			a.log("Agent").Debug("Loop detected", "turn", turnNum, "loop", loop)
+			loopNote = loopFeedback(loop)
+		}
+
 		// Refresh page state for next iteration
+		a.dismissConsent(ctx)
+		blocked, blockNote := a.checkBlock(ctx)
+		if blocked != nil {
+			result := partial.finish(blockedResult(blocked))
+			result.Steps = a.steps
+			result.Duration = time.Since(startTime)
+			result.ScreenshotPaths = a.screenshotPaths
+			return result.setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
 		if err := a.toolkit.RefreshElementMap(); err != nil {
-			if a.debug {
-				fmt.Printf("[Turn %d] Failed to refresh page state: %v\n", turnNum, err)
-			}
+			a.log("Agent").Warn("Failed to refresh page state", "turn", turnNum, "err", err)
 		}
 
 		// Build continuation message with history and updated page state
 		continuationMsg := a.messageManager.BuildContinuationMessage(
 			a.toolkit.GetElementMap(),
+			a.toolkit.ShownElementMap(),
 			lastActionName,
 			lastActionResult,
 			lastActionSuccess,
 		)
+		a.toolkit.MarkShown()
+		continuationMsg += a.toolkit.CheckLogin()
+		continuationMsg += blockNote
+		continuationMsg += siteRulesNote(a.siteRules, a.browser.GetURL())
+		continuationMsg += a.toolkit.DownloadNote()
+		continuationMsg += a.toolkit.ClosedTabsNote()
+		continuationMsg += criticNote
+		continuationMsg += loopNote
+		continuationMsg += schemaNote
+
+		// Out of tokens or down to the last step: leave the agent one turn
+		// to hand in what it has
+		if !finalTurn {
+			switch {
+			case outOfTokens || a.overTokenBudget():
+				finalCode = ErrorCodeTokenBudget
+			case toolCallNum >= a.maxSteps-1:
+				finalCode = ErrorCodeStepBudget
+			}
+		}
+		switch {
+		case finalTurn || finalCode == "":
+			// Already asked, or nothing is spent
+		case a.forceDone:
+			a.log("Agent").Warn("Budget nearly exhausted, asking for a final answer", "turn", turnNum, "code", finalCode)
+			finalTurn = true
+			defer a.toolFilter.only("done")()
+			if finalCode == ErrorCodeTokenBudget {
+				continuationMsg += tokenBudgetNote
+			} else {
+				continuationMsg += stepBudgetNote
+			}
+		case finalCode == ErrorCodeTokenBudget:
+			a.log("Agent").Warn("Token budget exhausted", "turn", turnNum, "max_tokens", a.maxTokens)
+			return partial.finish(&Result{
+				Success:         false,
+				Error:           a.budgetError(finalCode),
+				ErrorCode:       finalCode,
+				Steps:           a.steps,
+				Duration:        time.Since(startTime),
+				ScreenshotPaths: a.screenshotPaths,
+			}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+		}
 
 		// Filter sensitive data
 		continuationMsg = a.messageManager.FilterSensitiveData(continuationMsg)
@@ -445,17 +1217,46 @@ func (a *BrowserAgent) Run(ctx context.Context, task string) (*Result, error) {
 
 	// Return result
 	if lastResult != nil {
+		// Steps and screenshots may have been updated by the final tool response
+		lastResult.Steps = a.steps
+		lastResult.ScreenshotPaths = a.screenshotPaths
+		lastResult.Duration = time.Since(startTime)
+		lastResult.setUsage(a.tracer.tokenUsage().sub(startUsage))
+		lastResult.CompletionReason = CompletionDone
+		if !lastResult.Success {
+			lastResult.ErrorCode = failureCode(a.steps)
+			if finalTurn {
+				lastResult.ErrorCode = finalCode
+			}
+			if a.loginWall != nil && lastResult.ErrorCode == ErrorCodeTaskFailed {
+				lastResult.ErrorCode = ErrorCodeBlocked
+				lastResult.Blocked = a.loginWall
+			}
+			switch {
+			case finalTurn:
+				lastResult.CompletionReason = CompletionBudget
+			case lastResult.ErrorCode == ErrorCodeHumanTakeover:
+				lastResult.CompletionReason = CompletionTakeover
+			}
+		}
 		return lastResult, nil
 	}
 
 	// Max steps reached without completion
-	return &Result{
+	a.captureFailureScreenshot(ctx, "max_steps")
+	return partial.finish(&Result{
 		Success:         false,
-		Error:           fmt.Sprintf("Max steps (%d) reached without completion", a.maxSteps),
+		Error:           a.budgetError(ErrorCodeStepBudget),
+		ErrorCode:       ErrorCodeStepBudget,
 		Steps:           a.steps,
 		Duration:        time.Since(startTime),
 		ScreenshotPaths: a.screenshotPaths,
-	}, nil
+	}).setUsage(a.tracer.tokenUsage().sub(startUsage)), nil
+}
+
+// log returns the agent's logger tagged with a component.
+func (a *BrowserAgent) log(component string) *slog.Logger {
+	return logging.Component(a.logger, component)
 }
 
 // GetSteps returns all executed steps.
@@ -470,7 +1271,10 @@ func (a *BrowserAgent) GetHistory() *AgentHistory {
 
 // Close cleans up the agent resources.
 func (a *BrowserAgent) Close() error {
-	// Clean up any resources if needed
+	if a.cache != nil {
+		a.cache.close(context.Background())
+	}
+	a.screenshots.close()
 	return nil
 }
 
@@ -486,9 +1290,7 @@ func (a *BrowserAgent) captureAndSaveScreenshot(ctx context.Context, stepNum int
 		// Get element map for annotations
 		elementMap, mapErr := a.browser.GetElementMap(ctx)
 		if mapErr != nil {
-			if a.debug {
-				fmt.Printf("[Screenshot] Step %d: Failed to get element map for annotations: %v\n", stepNum, mapErr)
-			}
+			a.log("Screenshot").Warn("Failed to get element map for annotations", "step", stepNum, "err", mapErr)
 			// Fall back to regular screenshot
 			data, err = a.browser.ScreenshotSafe(ctx, false)
 		} else {
@@ -506,9 +1308,7 @@ func (a *BrowserAgent) captureAndSaveScreenshot(ctx context.Context, stepNum int
 
 	// If no screenshot data (blank page), return empty without error
 	if len(data) == 0 {
-		if a.debug {
-			fmt.Printf("[Screenshot] Step %d: Skipped (page is blank or empty)\n", stepNum)
-		}
+		a.log("Screenshot").Debug("Skipped blank page", "step", stepNum)
 		return nil, "", nil
 	}
 
@@ -517,24 +1317,72 @@ func (a *BrowserAgent) captureAndSaveScreenshot(ctx context.Context, stepNum int
 	if a.screenshotDir != "" {
 		filename := fmt.Sprintf("step_%03d_%d.jpg", stepNum, time.Now().UnixMilli())
 		savedPath = filepath.Join(a.screenshotDir, filename)
-		if err := os.WriteFile(savedPath, data, 0644); err != nil {
-			return data, "", fmt.Errorf("failed to save screenshot: %w", err)
-		}
+		a.screenshots.write(screenshotJob{path: savedPath, data: data})
 		a.screenshotPaths = append(a.screenshotPaths, savedPath)
 
-		if a.debug {
-			fmt.Printf("[Screenshot] Step %d: Saved to %s%s\n", stepNum, savedPath, func() string {
-				if a.showAnnotations {
-					return " (with annotations)"
-				}
-				return ""
-			}())
-		}
+		a.log("Screenshot").Debug("Saving", "step", stepNum, "path", savedPath, "annotated", a.showAnnotations)
 	}
 
 	return data, savedPath, nil
 }
 
+// recordScreenshot captures a screenshot for the run's record only, as the
+// model is not shown it: annotating and saving it happen in the background.
+// It returns the path the screenshot is saved to, or "" if it is not.
+func (a *BrowserAgent) recordScreenshot(ctx context.Context, stepNum int) string {
+	if a.screenshotDir == "" {
+		return ""
+	}
+
+	var elementMap *dom.ElementMap
+	if a.showAnnotations {
+		em, err := a.browser.GetElementMap(ctx)
+		if err != nil {
+			a.log("Screenshot").Warn("Failed to get element map for annotations", "step", stepNum, "err", err)
+		}
+		elementMap = em
+	}
+	data, err := a.browser.ScreenshotSafe(ctx, false)
+	if err != nil || len(data) == 0 {
+		a.log("Screenshot").Debug("Skipped", "step", stepNum, "err", err)
+		return ""
+	}
+
+	filename := fmt.Sprintf("step_%03d_%d.jpg", stepNum, time.Now().UnixMilli())
+	savedPath := filepath.Join(a.screenshotDir, filename)
+	a.screenshots.write(screenshotJob{path: savedPath, data: data, elementMap: elementMap})
+	a.screenshotPaths = append(a.screenshotPaths, savedPath)
+
+	a.log("Screenshot").Debug("Saving", "step", stepNum, "path", savedPath, "annotated", elementMap != nil)
+	return savedPath
+}
+
+// captureFailureScreenshot saves a full-quality screenshot of the current page when a task
+// fails, regardless of vision settings, and records its path in the run's screenshot paths.
+// Falls back to the system temp directory when no screenshot directory is configured.
+// Errors are non-fatal: the failure result is more important than the diagnostic image.
+func (a *BrowserAgent) captureFailureScreenshot(ctx context.Context, reason string) string {
+	data, err := a.browser.ScreenshotFullQuality(ctx)
+	if err != nil || len(data) == 0 {
+		a.log("Screenshot").Warn("Failure capture failed", "reason", reason, "err", err)
+		return ""
+	}
+
+	dir := a.screenshotDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	filename := fmt.Sprintf("failure_%s_%d.png", reason, time.Now().UnixMilli())
+	savedPath := filepath.Join(dir, filename)
+	a.screenshots.write(screenshotJob{path: savedPath, data: data})
+	a.screenshotPaths = append(a.screenshotPaths, savedPath)
+
+	a.log("Screenshot").Debug("Saving failure", "reason", reason, "path", savedPath)
+
+	return savedPath
+}
+
 // captureScreenshotAfterAction captures a screenshot after an action has completed.
 // Uses enhanced waiting for page stability after the action.
 func (a *BrowserAgent) captureScreenshotAfterAction(ctx context.Context, stepNum int) ([]byte, string, error) {
@@ -546,9 +1394,7 @@ func (a *BrowserAgent) captureScreenshotAfterAction(ctx context.Context, stepNum
 		// Get element map for annotations
 		elementMap, mapErr := a.browser.GetElementMap(ctx)
 		if mapErr != nil {
-			if a.debug {
-				fmt.Printf("[Screenshot] Step %d: Failed to get element map for annotations: %v\n", stepNum, mapErr)
-			}
+			a.log("Screenshot").Warn("Failed to get element map for annotations", "step", stepNum, "err", mapErr)
 			// Fall back to regular screenshot
 			data, err = a.browser.ScreenshotAfterAction(ctx)
 		} else {
@@ -561,9 +1407,7 @@ func (a *BrowserAgent) captureScreenshotAfterAction(ctx context.Context, stepNum
 
 	if err != nil {
 		// Non-fatal for blank page errors
-		if a.debug {
-			fmt.Printf("[Screenshot] Step %d: After-action capture failed: %v\n", stepNum, err)
-		}
+		a.log("Screenshot").Debug("After-action capture failed", "step", stepNum, "err", err)
 		return nil, "", nil
 	}
 
@@ -576,19 +1420,10 @@ func (a *BrowserAgent) captureScreenshotAfterAction(ctx context.Context, stepNum
 	if a.screenshotDir != "" {
 		filename := fmt.Sprintf("step_%03d_after_%d.jpg", stepNum, time.Now().UnixMilli())
 		savedPath = filepath.Join(a.screenshotDir, filename)
-		if err := os.WriteFile(savedPath, data, 0644); err != nil {
-			return data, "", fmt.Errorf("failed to save screenshot: %w", err)
-		}
+		a.screenshots.write(screenshotJob{path: savedPath, data: data})
 		a.screenshotPaths = append(a.screenshotPaths, savedPath)
 
-		if a.debug {
-			fmt.Printf("[Screenshot] Step %d: After-action saved to %s%s\n", stepNum, savedPath, func() string {
-				if a.showAnnotations {
-					return " (with annotations)"
-				}
-				return ""
-			}())
-		}
+		a.log("Screenshot").Debug("Saving after-action", "step", stepNum, "path", savedPath, "annotated", a.showAnnotations)
 	}
 
 	return data, savedPath, nil
@@ -599,6 +1434,36 @@ func (a *BrowserAgent) GetScreenshotPaths() []string {
 	return a.screenshotPaths
 }
 
+// GetDownloadPaths returns the paths of files downloaded during this run.
+func (a *BrowserAgent) GetDownloadPaths() []string {
+	return a.toolkit.downloadPaths
+}
+
+// GetAssertions returns the assertions recorded by the assert tool during this run.
+func (a *BrowserAgent) GetAssertions() []Assertion {
+	return a.toolkit.assertions
+}
+
+// GetFindings returns the entities saved with the save_finding tool during
+// this run, one per entity.
+func (a *BrowserAgent) GetFindings() []Finding {
+	return a.toolkit.findings.list()
+}
+
+// normalizeData cleans data as schema types it, if the run asked for it.
+func (a *BrowserAgent) normalizeData(schema *dataSchema, data any) any {
+	if !a.normalize || schema == nil || data == nil {
+		return data
+	}
+	return schema.normalize(data)
+}
+
+// GetSections returns the objective results filed by the report_section tool
+// during this run.
+func (a *BrowserAgent) GetSections() []Section {
+	return a.toolkit.sections
+}
+
 // createMultimodalContent creates a genai.Content with both text and image.
 func (a *BrowserAgent) createMultimodalContent(text string, imageData []byte) *genai.Content {
 	parts := []*genai.Part{
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anxuanzi/bua/block"
+)
+
+// challengeWait is how long a bot challenge that may clear by itself gets
+// before it counts as a block.
+const challengeWait = 15 * time.Second
+
+// loginWallNote tells the agent it met a sign-in form it has no login for.
+const loginWallNote = "\n\n<login_wall>\nThis page asks you to sign in, and no login is configured for this site. Unless the task gives you credentials, do not guess or create an account: if the task cannot be done without signing in, call done with success=false and say that sign-in is required.\n</login_wall>"
+
+// BlockedError is the failure of a run that a site turned away, with what
+// blocked it. It matches ErrBlocked with errors.Is.
+type BlockedError struct {
+	// Kind is what blocked the run, e.g. block.KindCloudflare.
+	Kind block.Kind `json:"kind"`
+
+	// URL is the blocked page.
+	URL string `json:"url"`
+
+	// Status is the HTTP status of the page (0 if unknown).
+	Status int `json:"status,omitempty"`
+
+	// Evidence is what gave the block away.
+	Evidence string `json:"evidence"`
+
+	// ScreenshotPath is a screenshot of the block page, if one was saved.
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+}
+
+// Error implements error.
+func (e *BlockedError) Error() string {
+	if e.Kind == block.KindLoginWall {
+		return fmt.Sprintf("sign-in required at %s", e.URL)
+	}
+	return fmt.Sprintf("blocked by %s at %s (%s)", e.Kind, e.URL, e.Evidence)
+}
+
+// Is reports whether target is an *Error with ErrorCodeBlocked, like ErrBlocked.
+func (e *BlockedError) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == ErrorCodeBlocked
+}
+
+// checkBlock looks for a block page before the model sees the page. It
+// returns the block if the run should end there. A login wall does not end
+// the run; it is kept in a.loginWall, and the agent gets a note about it
+// once per page.
+func (a *BrowserAgent) checkBlock(ctx context.Context) (*BlockedError, string) {
+	blocked := a.detectBlock(ctx)
+	if blocked == nil {
+		return nil, ""
+	}
+	if blocked.Kind != block.KindLoginWall {
+		a.log("Block").Warn("Run blocked", "kind", blocked.Kind, "url", blocked.URL, "evidence", blocked.Evidence)
+		blocked.ScreenshotPath = a.captureFailureScreenshot(ctx, "blocked")
+		return blocked, ""
+	}
+	if a.loginWall != nil && a.loginWall.URL == blocked.URL {
+		return nil, ""
+	}
+	blocked.ScreenshotPath = a.captureFailureScreenshot(ctx, "login_wall")
+	a.loginWall = blocked
+	return nil, loginWallNote
+}
+
+// detectBlock returns the block on the current page, or nil if there is none
+// or blocks are ignored. Challenges that may clear by themselves are given
+// challengeWait first, and login walls of sites with a configured login are
+// left to the login tool.
+func (a *BrowserAgent) detectBlock(ctx context.Context) *BlockedError {
+	if a.ignoreBlocks {
+		return nil
+	}
+	page := a.browser.ActivePage()
+	if page == nil {
+		return nil
+	}
+
+	b, err := block.Detect(page)
+	deadline := time.Now().Add(challengeWait)
+	for err == nil && b != nil && b.Challenge && time.Now().Before(deadline) {
+		a.log("Block").Debug("Waiting for challenge", "kind", b.Kind, "url", b.URL)
+		if a.toolkit.captchaSolver != nil {
+			a.toolkit.SolveCaptcha(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+		b, err = block.Detect(page)
+	}
+	if err != nil {
+		a.log("Block").Debug("Detection failed", "url", a.browser.GetURL(), "err", err)
+		return nil
+	}
+	if b == nil {
+		return nil
+	}
+	if b.Kind == block.KindLoginWall && a.toolkit.loginForURL(b.URL) != nil {
+		return nil
+	}
+	a.log("Block").Debug("Block page detected", "kind", b.Kind, "url", b.URL, "status", b.Status, "evidence", b.Evidence)
+	return &BlockedError{Kind: b.Kind, URL: b.URL, Status: b.Status, Evidence: b.Evidence}
+}
+
+// blockedResult is the result of a run that ended on a block page.
+func blockedResult(blocked *BlockedError) *Result {
+	return &Result{
+		Success:   false,
+		Error:     blocked.Error(),
+		ErrorCode: ErrorCodeBlocked,
+		Blocked:   blocked,
+
+		CompletionReason: CompletionError,
+	}
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// tokenBudgetNote asks the model to wrap up once RunOptions.MaxTokens is used.
+const tokenBudgetNote = "\n\n<token_budget_exhausted>\nThe token budget for this task is used up. Take no further actions: call done now. Set success to true only if the task is already complete; otherwise set it to false and put everything gathered so far in data.\n</token_budget_exhausted>"
+
+// stepBudgetNote asks the model to wrap up with the last step of MaxSteps.
+const stepBudgetNote = "\n\n<step_budget_exhausted>\nOnly one step is left for this task. Take no further actions: call done now. Set success to true only if the task is already complete; otherwise set it to false and put everything gathered so far in data.\n</step_budget_exhausted>"
+
+// overTokenBudget reports whether the current run has used RunOptions.MaxTokens.
+func (a *BrowserAgent) overTokenBudget() bool {
+	if a.maxTokens <= 0 {
+		return false
+	}
+	return a.tracer.tokenUsage().sub(a.runUsage).TotalTokens >= a.maxTokens
+}
+
+// budgetError describes the budget a run ran out of, for Result.Error.
+func (a *BrowserAgent) budgetError(code ErrorCode) string {
+	if code == ErrorCodeStepBudget {
+		return fmt.Sprintf("Max steps (%d) reached without completion", a.maxSteps)
+	}
+	return fmt.Sprintf("Token budget (%d) exhausted", a.maxTokens)
+}
+
+// callsTools reports whether content holds function calls, whose responses
+// are still to come.
+func callsTools(content *genai.Content) bool {
+	if content == nil {
+		return false
+	}
+	for _, part := range content.Parts {
+		if part.FunctionCall != nil {
+			return true
+		}
+	}
+	return false
+}
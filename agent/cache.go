@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// contextCacheTTL is how long a cached system prompt and tool schema live.
+// Runs within it reuse the cache; after it, a new one is created.
+const contextCacheTTL = time.Hour
+
+// contextCacheMargin renews a cache this long before it expires, so a model
+// call never refers to one that has just gone.
+const contextCacheMargin = time.Minute
+
+// contextCache moves the static part of every model request — the system
+// prompt and tool declarations — into Gemini cached content, so it is billed
+// at cached-token prices. A cache is kept per distinct prompt and tool set,
+// as per-run tool limits change the declarations.
+type contextCache struct {
+	client *genai.Client
+	model  string
+	log    *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry // by hash of the cached content
+}
+
+type cacheEntry struct {
+	name    string
+	expires time.Time
+	// failed is set when the content could not be cached, e.g. because it is
+	// below the model's minimum size; such requests are sent uncached.
+	failed bool
+}
+
+func newContextCache(client *genai.Client, modelName string, log *slog.Logger) *contextCache {
+	return &contextCache{client: client, model: modelName, log: log, entries: make(map[string]*cacheEntry)}
+}
+
+// beforeModel replaces the request's system prompt and tools with a
+// reference to their cached content.
+func (c *contextCache) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	cfg := req.Config
+	if cfg == nil || cfg.CachedContent != "" || (cfg.SystemInstruction == nil && len(cfg.Tools) == 0) {
+		return nil, nil
+	}
+	key, err := cacheKey(cfg)
+	if err != nil {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[key]
+	if entry == nil || (!entry.failed && time.Until(entry.expires) < contextCacheMargin) {
+		entry = c.create(ctx, cfg)
+		c.entries[key] = entry
+	}
+	if entry.failed {
+		return nil, nil
+	}
+
+	cfg.CachedContent = entry.name
+	cfg.SystemInstruction = nil
+	cfg.Tools = nil
+	cfg.ToolConfig = nil
+	return nil, nil
+}
+
+// create caches the static part of cfg.
+func (c *contextCache) create(ctx context.Context, cfg *genai.GenerateContentConfig) *cacheEntry {
+	cached, err := c.client.Caches.Create(ctx, c.model, &genai.CreateCachedContentConfig{
+		DisplayName:       "bua-agent",
+		TTL:               contextCacheTTL,
+		SystemInstruction: cfg.SystemInstruction,
+		Tools:             cfg.Tools,
+		ToolConfig:        cfg.ToolConfig,
+	})
+	if err != nil {
+		c.log.Debug("Not caching system prompt", "err", err)
+		return &cacheEntry{failed: true}
+	}
+
+	expires := cached.ExpireTime
+	if expires.IsZero() {
+		expires = time.Now().Add(contextCacheTTL)
+	}
+	c.log.Debug("Cached system prompt", "name", cached.Name, "expires", expires)
+	return &cacheEntry{name: cached.Name, expires: expires}
+}
+
+// close deletes the caches that have not expired yet.
+func (c *contextCache) close(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if !entry.failed && time.Now().Before(entry.expires) {
+			if _, err := c.client.Caches.Delete(ctx, entry.name, nil); err != nil {
+				c.log.Debug("Failed to delete cache", "name", entry.name, "err", err)
+			}
+		}
+		delete(c.entries, key)
+	}
+}
+
+// cacheKey identifies the cacheable part of cfg.
+func cacheKey(cfg *genai.GenerateContentConfig) (string, error) {
+	data, err := json.Marshal(struct {
+		SystemInstruction *genai.Content    `json:"s"`
+		Tools             []*genai.Tool     `json:"t"`
+		ToolConfig        *genai.ToolConfig `json:"c"`
+	}{cfg.SystemInstruction, cfg.Tools, cfg.ToolConfig})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod"
+
+	"github.com/anxuanzi/bua/consent"
+)
+
+// consentPrompt asks the planner model which button of a banner to click.
+const consentPrompt = `A web page shows this banner:
+%s
+
+Its buttons:
+%s
+Which button %s? Reply with JSON {"button": <index>}. Reply {"button": -1} if this is not a cookie or privacy consent banner, or if no button does that.`
+
+// dismissConsent answers a cookie-consent banner on the current page, so
+// the model never sees it.
+func (a *BrowserAgent) dismissConsent(ctx context.Context) {
+	cfg := a.consent
+	if cfg == nil {
+		return
+	}
+	page := a.browser.ActivePage()
+	if page == nil {
+		return
+	}
+
+	rules := append(slices.Clip(cfg.Rules), consent.DefaultRules...)
+	d, err := consent.Dismiss(page, rules, cfg.Choice)
+	if err == nil && d == nil && cfg.AskModel && a.planner != nil {
+		d, err = a.askConsent(ctx, page, cfg.Choice)
+	}
+	if err != nil {
+		a.log("Consent").Debug("Banner not dismissed", "url", a.browser.GetURL(), "err", err)
+		return
+	}
+	if d != nil {
+		a.log("Consent").Debug("Dismissed banner", "rule", d.Rule, "choice", d.Choice, "button", d.Button, "url", a.browser.GetURL())
+		a.browser.WaitStable(nil)
+	}
+}
+
+// askConsent has the planner model pick the button of a banner no rule
+// recognizes. It asks once per page.
+func (a *BrowserAgent) askConsent(ctx context.Context, page *rod.Page, choice consent.Choice) (*consent.Dismissal, error) {
+	pageURL := a.browser.GetURL()
+	if a.consentAsked[pageURL] {
+		return nil, nil
+	}
+	banner, err := consent.Find(page)
+	if err != nil || banner == nil {
+		return nil, err
+	}
+	a.consentAsked[pageURL] = true
+
+	var buttons strings.Builder
+	for i, b := range banner.Buttons {
+		fmt.Fprintf(&buttons, "%d: %s\n", i, b)
+	}
+	want := "rejects all optional cookies (or, if there is none, accepts them so the banner closes)"
+	if choice == consent.ChoiceAccept {
+		want = "accepts all cookies"
+	}
+	var reply struct {
+		Button int `json:"button"`
+	}
+	if err := a.generateJSON(ctx, fmt.Sprintf(consentPrompt, banner.Text, buttons.String(), want), &reply); err != nil {
+		return nil, err
+	}
+	if reply.Button < 0 || reply.Button >= len(banner.Buttons) {
+		return nil, nil
+	}
+	if err := consent.Click(page, reply.Button); err != nil {
+		return nil, err
+	}
+	if choice == "" {
+		choice = consent.ChoiceReject
+	}
+	return &consent.Dismissal{Choice: choice, Button: banner.Buttons[reply.Button]}, nil
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"maps"
+	"strings"
+)
+
+// ModelPrice is what a model costs, in US dollars per million tokens.
+type ModelPrice struct {
+	// Input is the price of prompt tokens.
+	Input float64 `json:"input"`
+
+	// Output is the price of answer and thinking tokens.
+	Output float64 `json:"output"`
+
+	// CachedInput is the price of prompt tokens served from a cache
+	// (0 = the Input price).
+	CachedInput float64 `json:"cached_input,omitempty"`
+}
+
+// Pricing maps model names to their prices. A model without an entry of its
+// own uses the entry with the longest name it starts with, so
+// "gemini-2.5-flash-preview-09-2025" is priced as "gemini-2.5-flash".
+type Pricing map[string]ModelPrice
+
+// DefaultPricing are the Gemini API's standard paid-tier prices for prompts
+// up to 200k tokens. Update or extend it when prices change, or override
+// single models per agent.
+var DefaultPricing = Pricing{
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40, CachedInput: 0.025},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50, CachedInput: 0.03},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40, CachedInput: 0.01},
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00, CachedInput: 0.125},
+	"gemini-3-flash":        {Input: 0.50, Output: 3.00, CachedInput: 0.05},
+	"gemini-3-pro":          {Input: 2.00, Output: 12.00, CachedInput: 0.20},
+}
+
+// Price returns the price of model, and false if the table has none.
+func (p Pricing) Price(model string) (ModelPrice, bool) {
+	model = strings.TrimPrefix(model, "models/")
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	var best string
+	for name := range p {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return p[best], true
+}
+
+// Cost returns what usage costs in US dollars, and false if the table has
+// no price for model.
+func (p Pricing) Cost(model string, usage TokenUsage) (float64, bool) {
+	price, ok := p.Price(model)
+	if !ok {
+		return 0, false
+	}
+	cachedRate := price.CachedInput
+	if cachedRate == 0 {
+		cachedRate = price.Input
+	}
+	cost := float64(usage.InputTokens-usage.CachedTokens)*price.Input +
+		float64(usage.CachedTokens)*cachedRate +
+		float64(usage.OutputTokens+usage.ThinkingTokens)*price.Output
+	return cost / 1e6, true
+}
+
+// overBudget reports whether the current run has cost more than MaxCost.
+func (a *BrowserAgent) overBudget() bool {
+	if a.maxCost <= 0 {
+		return false
+	}
+	cost, _ := a.pricing.Cost(a.model, a.tracer.tokenUsage().sub(a.runUsage))
+	return cost > a.maxCost
+}
+
+// withDefaults returns DefaultPricing with p's entries added or replaced.
+func (p Pricing) withDefaults() Pricing {
+	merged := maps.Clone(DefaultPricing)
+	maps.Copy(merged, p)
+	return merged
+}
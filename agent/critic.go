@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxCriticRejections bounds how often the critic can send the agent back
+// to work in one run; after that the agent's answer stands.
+const maxCriticRejections = 2
+
+// maxCriticPageChars caps the page text shown to the critic.
+const maxCriticPageChars = 12000
+
+// criticPrompt asks the critic to check the agent's answer against the page.
+const criticPrompt = `You check the final answer of a browser automation agent before it is returned to the user.
+
+Task: %s
+
+The agent reported success with this summary:
+%s
+
+and this data:
+%s
+
+The browser is now on %s (%s). Its visible text:
+<page_text>
+%s
+</page_text>
+
+Reject the answer only if it is clearly wrong or incomplete: a field the task asks for is missing or empty, a value contradicts the page, or the task was not actually carried out. Accept answers whose values come from pages the agent visited earlier, unless the current page contradicts them.
+
+Respond with JSON only: {"accept": true|false, "issues": ["..."]}`
+
+// critique is the critic's judgment of a done call.
+type critique struct {
+	Accept bool     `json:"accept"`
+	Issues []string `json:"issues"`
+}
+
+// critique checks a successful done call against the current page.
+func (a *BrowserAgent) critique(ctx context.Context, task string, done *Result, summary string) (*critique, error) {
+	pageText, err := a.browser.ExtractContent(ctx)
+	if err != nil {
+		pageText = "(unavailable)"
+	}
+	if len(pageText) > maxCriticPageChars {
+		pageText = pageText[:maxCriticPageChars] + "..."
+	}
+
+	data, _ := json.MarshalIndent(done.Data, "", "  ")
+	prompt := fmt.Sprintf(criticPrompt, task, summary, data, a.browser.GetURL(), a.browser.GetTitle(), pageText)
+
+	var c critique
+	if err := a.generateJSON(ctx, prompt, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// criticFeedback tells the agent why its answer was rejected.
+func criticFeedback(c *critique) string {
+	var sb strings.Builder
+	sb.WriteString("\n\n<verification_failed>\nYour answer was checked against the page and rejected:\n")
+	for _, issue := range c.Issues {
+		sb.WriteString("- " + issue + "\n")
+	}
+	sb.WriteString("Fix these problems, using get_page_state or extract_content if needed, then call done again with the corrected data.\n</verification_failed>")
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/dom"
+)
+
+// MarkShown records the current element map as the one the model was shown.
+// Element indices in the model's next tool calls refer to it.
+func (t *BrowserToolkit) MarkShown() {
+	t.shownMap = t.elementMap
+}
+
+// refreshFullElementMap updates the cached element map with the elements of
+// the whole page.
+func (t *BrowserToolkit) refreshFullElementMap() error {
+	em, err := t.browser.GetFullElementMap(nil)
+	if err != nil {
+		return err
+	}
+	t.elementMap = em
+	return nil
+}
+
+// ShownElementMap returns the element map of the page state the model saw
+// last, or nil if it has seen none.
+func (t *BrowserToolkit) ShownElementMap() *dom.ElementMap {
+	return t.shownMap
+}
+
+// resolveIndex maps an element index from the page state the model was shown
+// to the index the same element has in the current element map. The map is
+// refreshed after every action, so without this a second action in a turn,
+// or any action after the DOM changed, could hit whatever element took the
+// index. Elements are matched by their stable ID; one that is gone is an
+// error rather than a guess.
+func (t *BrowserToolkit) resolveIndex(index int) (int, error) {
+	if t.shownMap == nil || t.shownMap == t.elementMap || t.elementMap == nil {
+		return index, nil
+	}
+	if _, ok := t.shownMap.Get(index); !ok {
+		return index, nil
+	}
+	current, ok := t.elementMap.Remap(t.shownMap)[index]
+	if !ok {
+		return 0, fmt.Errorf("%w: element [%d] is no longer on the page; check the page state again", browser.ErrElementNotFound, index)
+	}
+	return current, nil
+}
+
+// resolveVisibleIndex is resolveIndex for actions aimed at where the
+// element is on screen, which would miss an element outside the viewport
+// (listed by get_page_state with full_page).
+func (t *BrowserToolkit) resolveVisibleIndex(index int) (int, error) {
+	current, err := t.resolveIndex(index)
+	if err != nil {
+		return 0, err
+	}
+	if el, ok := t.elementMap.Get(current); ok && !el.IsVisible && t.elementMap.ViewportHeight > 0 {
+		return 0, fmt.Errorf("element [%d] is outside the viewport; call scroll_to_element first", index)
+	}
+	return current, nil
+}
@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// ErrorCode classifies why a run or a tool call failed.
+type ErrorCode string
+
+const (
+	// ErrorCodeRateLimited means the model API refused requests for exceeding its rate limit or quota.
+	ErrorCodeRateLimited ErrorCode = "rate_limited"
+
+	// ErrorCodeModelUnavailable means the model API failed with a server or network error.
+	ErrorCodeModelUnavailable ErrorCode = "model_unavailable"
+
+	// ErrorCodeElementNotFound means an element index was not on the page.
+	ErrorCodeElementNotFound ErrorCode = "element_not_found"
+
+	// ErrorCodeNavigationTimeout means a page did not load in time.
+	ErrorCodeNavigationTimeout ErrorCode = "navigation_timeout"
+
+	// ErrorCodeStepBudget means the run reached its step limit.
+	ErrorCodeStepBudget ErrorCode = "step_budget"
+
+	// ErrorCodeCostBudget means the run's estimated cost exceeded its limit.
+	ErrorCodeCostBudget ErrorCode = "cost_budget"
+
+	// ErrorCodeTokenBudget means the run used up RunOptions.MaxTokens.
+	ErrorCodeTokenBudget ErrorCode = "token_budget"
+
+	// ErrorCodeTimeout means the run's context deadline passed before it finished.
+	ErrorCodeTimeout ErrorCode = "timeout"
+
+	// ErrorCodeRepeatedFailures means the run was aborted after too many failed actions in a row.
+	ErrorCodeRepeatedFailures ErrorCode = "repeated_failures"
+
+	// ErrorCodeLoopDetected means the run was aborted because the agent kept
+	// repeating the same actions after being told to change its approach.
+	ErrorCodeLoopDetected ErrorCode = "loop_detected"
+
+	// ErrorCodeHumanTakeover means a person was needed but unavailable, or did not finish in time.
+	ErrorCodeHumanTakeover ErrorCode = "human_takeover"
+
+	// ErrorCodeModelRefusal means the model declined to continue, e.g. for safety reasons.
+	ErrorCodeModelRefusal ErrorCode = "model_refusal"
+
+	// ErrorCodeBlocked means a site turned the agent away with a bot
+	// challenge, block page or login wall; Result.Blocked says which.
+	ErrorCodeBlocked ErrorCode = "blocked"
+
+	// ErrorCodeTaskFailed means the agent finished but reported that the task failed.
+	ErrorCodeTaskFailed ErrorCode = "task_failed"
+)
+
+// Error is an error with a code. Errors match each other with errors.Is when
+// their codes are equal, so a Result's error can be checked against the
+// sentinels below.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Sentinel errors for each code.
+var (
+	ErrRateLimited       = &Error{Code: ErrorCodeRateLimited, Message: "rate limited by the model API"}
+	ErrModelUnavailable  = &Error{Code: ErrorCodeModelUnavailable, Message: "model API unavailable"}
+	ErrElementNotFound   = &Error{Code: ErrorCodeElementNotFound, Message: "element not found"}
+	ErrNavigationTimeout = &Error{Code: ErrorCodeNavigationTimeout, Message: "navigation timed out"}
+	ErrStepBudget        = &Error{Code: ErrorCodeStepBudget, Message: "step budget exhausted"}
+	ErrCostBudget        = &Error{Code: ErrorCodeCostBudget, Message: "cost budget exhausted"}
+	ErrTokenBudget       = &Error{Code: ErrorCodeTokenBudget, Message: "token budget exhausted"}
+	ErrTimeout           = &Error{Code: ErrorCodeTimeout, Message: "run timed out"}
+	ErrRepeatedFailures  = &Error{Code: ErrorCodeRepeatedFailures, Message: "too many consecutive failures"}
+	ErrLoopDetected      = &Error{Code: ErrorCodeLoopDetected, Message: "agent stuck in a loop"}
+	ErrHumanTakeover     = &Error{Code: ErrorCodeHumanTakeover, Message: "human takeover failed"}
+	ErrModelRefusal      = &Error{Code: ErrorCodeModelRefusal, Message: "model refused to continue"}
+	ErrBlocked           = &Error{Code: ErrorCodeBlocked, Message: "blocked by the site"}
+	ErrTaskFailed        = &Error{Code: ErrorCodeTaskFailed, Message: "task failed"}
+)
+
+// Err returns the result's failure as an *Error, or as a *BlockedError if a
+// site blocked the run, or nil if it succeeded.
+func (r *Result) Err() error {
+	if r.Success {
+		return nil
+	}
+	if r.Blocked != nil {
+		return r.Blocked
+	}
+	return &Error{Code: r.ErrorCode, Message: r.Error}
+}
+
+// failureCode returns the code of a task the agent reported as failed: that
+// of the last failed step, if it has one, as it usually explains why.
+func failureCode(steps []Step) ErrorCode {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if !steps[i].Success && steps[i].ErrorCode != "" {
+			return steps[i].ErrorCode
+		}
+	}
+	return ErrorCodeTaskFailed
+}
+
+// toolErrorCode classifies the error of a failed browser action.
+func toolErrorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, browser.ErrElementNotFound):
+		return ErrorCodeElementNotFound
+	case errors.Is(err, browser.ErrNavigationTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeNavigationTimeout
+	}
+	return ""
+}
+
+// modelError classifies a failed model call, returning the matching sentinel
+// or nil if the failure is not one bua recognizes.
+func modelError(err error) *Error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return ErrRateLimited
+		case apiErr.Code >= http.StatusInternalServerError:
+			return ErrModelUnavailable
+		}
+		return nil
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrModelUnavailable
+	}
+	return nil
+}
+
+// refusalReasons are the finish and block reasons of a model that declined to answer.
+var refusalReasons = []string{
+	string(genai.FinishReasonSafety),
+	string(genai.FinishReasonProhibitedContent),
+	string(genai.FinishReasonBlocklist),
+	string(genai.FinishReasonSPII),
+	string(genai.FinishReasonRecitation),
+	string(genai.FinishReasonImageSafety),
+	string(genai.FinishReasonImageProhibitedContent),
+}
+
+// refusal returns why the model declined to answer, or "" if it did not.
+func refusal(finishReason genai.FinishReason, errorCode string) string {
+	if slices.Contains(refusalReasons, string(finishReason)) {
+		return string(finishReason)
+	}
+	if slices.Contains(refusalReasons, errorCode) {
+		return errorCode
+	}
+	return ""
+}
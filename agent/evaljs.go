@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxEvalResult caps the JSON eval_js returns.
+const maxEvalResult = 10000
+
+// evalJSWrapper runs a function body and returns its awaited result as JSON.
+// DOM nodes are serialized as the start of their markup, and values JSON
+// cannot represent, such as cyclic objects, as strings.
+const evalJSWrapper = `(async () => {
+	const value = await (async () => { %s
+	})();
+	try {
+		return JSON.stringify(value === undefined ? null : value, (key, v) =>
+			v instanceof Node ? (v.outerHTML || v.textContent || '').slice(0, 300) : v);
+	} catch (e) {
+		return JSON.stringify(String(value));
+	}
+})`
+
+// evalJSParseCheck compiles a script as an expression without running it:
+// the function is created but never called, so only a SyntaxError of the
+// script itself can fail it. It needs no eval, so pages whose Content
+// Security Policy forbids eval allow it.
+const evalJSParseCheck = `(() => {
+	const check = async () => { %s };
+	return "ok";
+})`
+
+// evalJS runs script on the page once, as an expression if it parses as one
+// and else as statements, and returns the result as JSON, cut after
+// maxEvalResult bytes.
+func (t *BrowserToolkit) evalJS(script string) (string, error) {
+	script = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(script), ";"))
+	body := "return (" + script + "\n);"
+	if _, err := t.browser.EvaluateJS(nil, fmt.Sprintf(evalJSParseCheck, body)); err != nil {
+		if !strings.Contains(err.Error(), "SyntaxError") {
+			return "", err
+		}
+		// Not an expression; run it as a function body
+		body = script + ";"
+	}
+	result, err := t.browser.EvaluateJS(nil, fmt.Sprintf(evalJSWrapper, body))
+	if err != nil {
+		return "", err
+	}
+	if len(result) > maxEvalResult {
+		result = truncate(result, maxEvalResult) + "... (truncated)"
+	}
+	return result, nil
+}
+
+// checkJSEvalOrigin returns an error unless the document eval_js runs in,
+// the selected frame's or else the page's, is on a domain eval_js may run on.
+func (t *BrowserToolkit) checkJSEvalOrigin() error {
+	if len(t.jsEvalOrigins) == 0 {
+		return nil
+	}
+	docURL := t.browser.DocumentURL()
+	if u, err := url.Parse(docURL); err == nil {
+		for _, domain := range t.jsEvalOrigins {
+			if inDomain(u.Hostname(), domain) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is not on a domain JavaScript may run on (%s)", docURL, strings.Join(t.jsEvalOrigins, ", "))
+}
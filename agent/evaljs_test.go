@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// evalJSToolkit returns a toolkit on a Fake page at https://a.example/.
+func evalJSToolkit(t *testing.T, page *browser.FakePage) (*BrowserToolkit, *browser.Fake) {
+	t.Helper()
+	fake := browser.NewFake(map[string]*browser.FakePage{"https://a.example/": page})
+	if err := fake.Navigate(nil, "https://a.example/"); err != nil {
+		t.Fatal(err)
+	}
+	return NewBrowserToolkit(fake, 1280), fake
+}
+
+// evaluations counts the EvaluateJS calls in fake's action log.
+func evaluations(fake *browser.Fake) int {
+	n := 0
+	for _, action := range fake.Actions() {
+		if strings.HasPrefix(action, "evaluate ") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEvalJSExpression(t *testing.T) {
+	body := "return (document.title\n);"
+	tk, fake := evalJSToolkit(t, &browser.FakePage{
+		Scripts: map[string]string{
+			fmt.Sprintf(evalJSParseCheck, body): "ok",
+			fmt.Sprintf(evalJSWrapper, body):    `"Example"`,
+		},
+	})
+
+	got, err := tk.evalJS("  document.title; ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `"Example"` {
+		t.Errorf("evalJS() = %q, want %q", got, `"Example"`)
+	}
+	// One parse check, then the script runs once
+	if n := evaluations(fake); n != 2 {
+		t.Errorf("EvaluateJS called %d times, want 2", n)
+	}
+}
+
+func TestEvalJSStatements(t *testing.T) {
+	script := "const n = 2; return n * 2"
+	tk, fake := evalJSToolkit(t, &browser.FakePage{
+		Scripts: map[string]string{
+			fmt.Sprintf(evalJSWrapper, script+";"): "4",
+		},
+		Errors: map[string]string{
+			fmt.Sprintf(evalJSParseCheck, "return ("+script+"\n);"): "SyntaxError: Unexpected token 'const'",
+		},
+	})
+
+	got, err := tk.evalJS(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "4" {
+		t.Errorf("evalJS() = %q, want 4", got)
+	}
+	if n := evaluations(fake); n != 2 {
+		t.Errorf("EvaluateJS called %d times, want 2", n)
+	}
+}
+
+func TestEvalJSParseCheckError(t *testing.T) {
+	// Errors other than a SyntaxError are the page's, not the script's
+	tk, fake := evalJSToolkit(t, &browser.FakePage{
+		Errors: map[string]string{
+			fmt.Sprintf(evalJSParseCheck, "return (1\n);"): "Execution context was destroyed",
+		},
+	})
+
+	if _, err := tk.evalJS("1"); err == nil || !strings.Contains(err.Error(), "context was destroyed") {
+		t.Errorf("evalJS() error = %v, want the page's error", err)
+	}
+	if n := evaluations(fake); n != 1 {
+		t.Errorf("EvaluateJS called %d times, want 1", n)
+	}
+}
+
+func TestEvalJSTruncates(t *testing.T) {
+	body := "return (big\n);"
+	tk, _ := evalJSToolkit(t, &browser.FakePage{
+		Scripts: map[string]string{
+			fmt.Sprintf(evalJSParseCheck, body): "ok",
+			fmt.Sprintf(evalJSWrapper, body):    strings.Repeat("x", maxEvalResult+50),
+		},
+	})
+
+	got, err := tk.evalJS("big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Repeat("x", maxEvalResult) + "... (truncated)"; got != want {
+		t.Errorf("evalJS() returned %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestEvalJSTruncatesOnRuneBoundary(t *testing.T) {
+	body := "return (names\n);"
+	tk, _ := evalJSToolkit(t, &browser.FakePage{
+		Scripts: map[string]string{
+			fmt.Sprintf(evalJSParseCheck, body): "ok",
+			// The first é straddles the cut
+			fmt.Sprintf(evalJSWrapper, body): strings.Repeat("x", maxEvalResult-1) + "ééé",
+		},
+	})
+
+	got, err := tk.evalJS("names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Repeat("x", maxEvalResult-1) + "... (truncated)"; got != want {
+		t.Errorf("evalJS() = ...%q, want ...%q", got[len(got)-20:], want[len(want)-20:])
+	}
+}
+
+func TestCheckJSEvalOrigin(t *testing.T) {
+	tk, _ := evalJSToolkit(t, &browser.FakePage{})
+
+	if err := tk.checkJSEvalOrigin(); err != nil {
+		t.Errorf("checkJSEvalOrigin() without origins = %v", err)
+	}
+	tk.jsEvalOrigins = []string{"b.example", "xa.example"}
+	if err := tk.checkJSEvalOrigin(); err == nil {
+		t.Error("checkJSEvalOrigin() allowed a.example for b.example and xa.example")
+	}
+	tk.jsEvalOrigins = []string{"b.example", "example"}
+	if err := tk.checkJSEvalOrigin(); err != nil {
+		t.Errorf("checkJSEvalOrigin() = %v, want a.example allowed as a subdomain", err)
+	}
+}
+
+func TestCheckJSEvalOriginFrame(t *testing.T) {
+	tk, fake := evalJSToolkit(t, &browser.FakePage{
+		Frames: []browser.FrameInfo{
+			{Path: "0", URL: "https://ads.example.net/slot"},
+			{Path: "1", URL: "https://pay.a.example/checkout"},
+		},
+	})
+	tk.jsEvalOrigins = []string{"a.example"}
+
+	// The page is allowed, but scripts would run in the frame
+	if err := fake.SwitchFrame(nil, "0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.checkJSEvalOrigin(); err == nil || !strings.Contains(err.Error(), "ads.example.net") {
+		t.Errorf("checkJSEvalOrigin() in a frame of another site = %v, want refused", err)
+	}
+
+	if err := fake.SwitchFrame(nil, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.checkJSEvalOrigin(); err != nil {
+		t.Errorf("checkJSEvalOrigin() in a frame of a subdomain = %v", err)
+	}
+
+	if err := fake.SwitchFrame(nil, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.checkJSEvalOrigin(); err != nil {
+		t.Errorf("checkJSEvalOrigin() back on the page = %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Example is a worked example of a recurring flow, shown to the agent at the
+// start of runs of its category so it follows a known-good approach.
+type Example struct {
+	// Category is the kind of task the example is for, e.g. "search". Runs
+	// pick examples with RunOptions.ExampleCategory.
+	Category string
+
+	// Task describes what the example accomplishes, e.g. "Accept the cookie
+	// banner, then search the site".
+	Task string
+
+	// Steps are the page states the agent met and the tool calls that
+	// handled them, in order.
+	Steps []ExampleStep
+}
+
+// ExampleStep is one page state of an Example and the right tool call for it.
+type ExampleStep struct {
+	// PageState is what the agent saw, abbreviated to what matters, e.g.
+	// "[0]<button>Accept all</button> [1]<input placeholder=Search>".
+	PageState string
+
+	// Tool is the tool to call, e.g. "click".
+	Tool string
+
+	// Args are the tool's arguments, e.g. {"element_index": 0}.
+	Args map[string]any
+
+	// Note explains the choice, e.g. "The banner covers the page until accepted".
+	Note string
+}
+
+// checkExamples rejects examples that call tools the agent does not have.
+func checkExamples(examples []Example, tools map[string]bool) error {
+	for _, ex := range examples {
+		if ex.Category == "" {
+			return fmt.Errorf("example %q has no category", ex.Task)
+		}
+		for i, s := range ex.Steps {
+			if !tools[s.Tool] {
+				return fmt.Errorf("example %q step %d: unknown tool %q", ex.Task, i+1, s.Tool)
+			}
+		}
+	}
+	return nil
+}
+
+// examplesFor returns the examples of category, or an error if there are none.
+func examplesFor(examples []Example, category string) ([]Example, error) {
+	if category == "" {
+		return nil, nil
+	}
+	var selected []Example
+	for _, ex := range examples {
+		if ex.Category == category {
+			selected = append(selected, ex)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no examples for category %q", category)
+	}
+	return selected, nil
+}
+
+// examplesNote renders examples for the model, or "" if there are none.
+func examplesNote(examples []Example) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n<examples>\nThese worked examples show how similar tasks were done. Follow the same approach where it fits; element indexes on your page will differ, so always read them from the current page state.\n")
+	for _, ex := range examples {
+		fmt.Fprintf(&sb, "<example>\nTask: %s\n", ex.Task)
+		for i, s := range ex.Steps {
+			args, _ := json.Marshal(s.Args)
+			fmt.Fprintf(&sb, "Step %d:\n", i+1)
+			if s.PageState != "" {
+				fmt.Fprintf(&sb, "  Page: %s\n", s.PageState)
+			}
+			fmt.Fprintf(&sb, "  Call: %s %s\n", s.Tool, args)
+			if s.Note != "" {
+				fmt.Fprintf(&sb, "  Why: %s\n", s.Note)
+			}
+		}
+		sb.WriteString("</example>\n")
+	}
+	sb.WriteString("</examples>")
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// MergeStrategy says how a finding saved again for the same entity is
+// merged into the one saved before; see RunOptions.FindingMerge.
+type MergeStrategy string
+
+const (
+	// MergeFields keeps the first save and adds the fields of later ones,
+	// whose non-empty values win. It is the default.
+	MergeFields MergeStrategy = "merge-fields"
+
+	// MergeFirst keeps the first save and drops later ones.
+	MergeFirst MergeStrategy = "first"
+
+	// MergeLast replaces a finding with its latest save.
+	MergeLast MergeStrategy = "last"
+)
+
+// Finding is an entity the agent saved with the save_finding tool, e.g. a
+// profile, post or product.
+type Finding struct {
+	Data      map[string]any `json:"data"`
+	URL       string         `json:"url,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+
+	// Saves counts how often the entity was saved; past 1, the saves were
+	// merged into this finding.
+	Saves int `json:"saves"`
+}
+
+// checkMergeStrategy returns an error for an unknown strategy.
+func checkMergeStrategy(m MergeStrategy) error {
+	switch m {
+	case "", MergeFields, MergeFirst, MergeLast:
+		return nil
+	}
+	return fmt.Errorf("unknown finding merge strategy %q", m)
+}
+
+// findingStore holds a run's findings, one per entity.
+type findingStore struct {
+	keys     []string
+	merge    MergeStrategy
+	findings []Finding
+	index    map[string]int // entity key -> index into findings
+}
+
+// newFindingStore returns a store that identifies entities by keys and
+// merges their saves with merge.
+func newFindingStore(keys []string, merge MergeStrategy) *findingStore {
+	if merge == "" {
+		merge = MergeFields
+	}
+	return &findingStore{keys: keys, merge: merge, index: make(map[string]int)}
+}
+
+// add saves r, merging it into the finding of the same entity if there is
+// one. It returns the finding's index and whether it was a duplicate.
+func (s *findingStore) add(r Finding) (int, bool) {
+	if r.Saves == 0 {
+		r.Saves = 1
+	}
+	key, ok := s.key(r)
+	if i, dup := s.index[key]; ok && dup {
+		s.findings[i] = mergeFindings(s.findings[i], r, s.merge)
+		return i, true
+	}
+	s.findings = append(s.findings, r)
+	if ok {
+		s.index[key] = len(s.findings) - 1
+	}
+	return len(s.findings) - 1, false
+}
+
+// list returns the findings, in the order first saved.
+func (s *findingStore) list() []Finding {
+	if s == nil {
+		return nil
+	}
+	return s.findings
+}
+
+// key identifies the entity of r: the values of the store's keys, or all of
+// r's data without keys. A "url" key falls back to the page r was saved on.
+// It reports false if r lacks a key, so it cannot be matched.
+func (s *findingStore) key(r Finding) (string, bool) {
+	if len(s.keys) == 0 {
+		b, err := json.Marshal(r.Data) // sorts keys
+		return string(b), err == nil
+	}
+	parts := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		v := r.Data[k]
+		if v == nil && strings.EqualFold(k, "url") {
+			v = r.URL
+		}
+		switch val := v.(type) {
+		case nil:
+			return "", false
+		case string:
+			if val = strings.ToLower(visitKey(val)); val == "" {
+				return "", false
+			}
+			parts[i] = val
+		default:
+			b, _ := json.Marshal(val)
+			parts[i] = string(b)
+		}
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// mergeFindings merges a later save of the same entity into old.
+func mergeFindings(old, later Finding, merge MergeStrategy) Finding {
+	saves := old.Saves + later.Saves
+	switch merge {
+	case MergeFirst:
+		old.Saves = saves
+		return old
+	case MergeLast:
+		later.Saves = saves
+		return later
+	}
+	data := maps.Clone(old.Data)
+	if data == nil {
+		data = make(map[string]any, len(later.Data))
+	}
+	for k, v := range later.Data {
+		if !emptyValue(v) || data[k] == nil {
+			data[k] = v
+		}
+	}
+	old.Data, old.Saves = data, saves
+	return old
+}
+
+// emptyValue reports whether v holds nothing worth keeping.
+func emptyValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package agent
+
+import "testing"
+
+func TestFindingStoreKeys(t *testing.T) {
+	s := newFindingStore([]string{"username"}, "")
+
+	s.add(Finding{Data: map[string]any{"username": "Ada", "followers": 10}})
+	s.add(Finding{Data: map[string]any{"username": "bob"}})
+	i, dup := s.add(Finding{Data: map[string]any{"username": "ada", "bio": "math"}})
+	if !dup || i != 0 {
+		t.Fatalf("add() = %d, %v; want 0, true (keys compare case-insensitively)", i, dup)
+	}
+
+	// A save without the key can't be matched, so it is kept as is
+	if _, dup := s.add(Finding{Data: map[string]any{"followers": 3}}); dup {
+		t.Error("finding without its key was merged")
+	}
+
+	got := s.list()
+	if len(got) != 3 {
+		t.Fatalf("list() has %d findings, want 3", len(got))
+	}
+	ada := got[0]
+	if ada.Saves != 2 || ada.Data["bio"] != "math" || ada.Data["followers"] != 10 || ada.Data["username"] != "ada" {
+		t.Errorf("merged finding = %+v", ada)
+	}
+}
+
+func TestFindingStoreURLKey(t *testing.T) {
+	s := newFindingStore([]string{"url"}, MergeLast)
+
+	s.add(Finding{Data: map[string]any{"title": "old"}, URL: "https://Example.com/p/1/#top"})
+	i, dup := s.add(Finding{Data: map[string]any{"title": "new", "url": "https://example.com/p/1"}})
+	if !dup || i != 0 {
+		t.Fatalf("add() = %d, %v; want the page URL to match the url field", i, dup)
+	}
+	if got := s.list()[0]; got.Data["title"] != "new" || got.Saves != 2 {
+		t.Errorf("MergeLast kept %+v", got)
+	}
+}
+
+func TestFindingStoreWithoutKeys(t *testing.T) {
+	s := newFindingStore(nil, MergeFirst)
+
+	s.add(Finding{Data: map[string]any{"a": 1, "b": 2}})
+	if _, dup := s.add(Finding{Data: map[string]any{"b": 2, "a": 1}}); !dup {
+		t.Error("identical data was not merged")
+	}
+	if _, dup := s.add(Finding{Data: map[string]any{"a": 1}}); dup {
+		t.Error("different data was merged")
+	}
+	if got := s.list(); len(got) != 2 || got[0].Saves != 2 {
+		t.Errorf("list() = %+v", got)
+	}
+}
+
+func TestMergeFindingsFields(t *testing.T) {
+	old := Finding{Data: map[string]any{"name": "Ada", "bio": "math", "tags": []any{"x"}}, Saves: 1}
+	later := Finding{Data: map[string]any{"name": "Ada L.", "bio": " ", "tags": []any{}, "city": "London"}, Saves: 1}
+
+	got := mergeFindings(old, later, MergeFields)
+	want := map[string]any{"name": "Ada L.", "bio": "math", "city": "London"}
+	for k, v := range want {
+		if got.Data[k] != v {
+			t.Errorf("%s = %v, want %v", k, got.Data[k], v)
+		}
+	}
+	if tags, _ := got.Data["tags"].([]any); len(tags) != 1 {
+		t.Errorf("tags = %v, want the non-empty earlier value", got.Data["tags"])
+	}
+	if old.Data["name"] != "Ada" {
+		t.Error("merge modified the earlier finding's data")
+	}
+	if got.Saves != 2 {
+		t.Errorf("Saves = %d, want 2", got.Saves)
+	}
+}
+
+func TestCheckMergeStrategy(t *testing.T) {
+	for _, m := range []MergeStrategy{"", MergeFields, MergeFirst, MergeLast} {
+		if err := checkMergeStrategy(m); err != nil {
+			t.Errorf("checkMergeStrategy(%q) = %v", m, err)
+		}
+	}
+	if checkMergeStrategy("newest") == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// ThinkingLevel is how much a Gemini 3 model thinks before answering.
+type ThinkingLevel string
+
+const (
+	ThinkingLevelMinimal ThinkingLevel = "MINIMAL"
+	ThinkingLevelLow     ThinkingLevel = "LOW"
+	ThinkingLevelMedium  ThinkingLevel = "MEDIUM"
+	ThinkingLevelHigh    ThinkingLevel = "HIGH"
+)
+
+// GenerationConfig tunes how the agent's model generates its answers. Unset
+// fields keep the model's defaults.
+type GenerationConfig struct {
+	// Temperature controls randomness, from 0 (most deterministic) to 2
+	// (nil = model default).
+	Temperature *float32
+
+	// TopP samples from the most likely tokens up to this probability mass
+	// (nil = model default).
+	TopP *float32
+
+	// MaxOutputTokens caps the length of each answer (0 = model default).
+	MaxOutputTokens int32
+
+	// ThinkingBudget caps the thinking tokens of each answer on Gemini 2.5
+	// models: 0 turns thinking off, -1 lets the model decide (nil = model
+	// default).
+	ThinkingBudget *int32
+
+	// ThinkingLevel sets how much Gemini 3 models think ("" = model default).
+	ThinkingLevel ThinkingLevel
+}
+
+// merge returns g with the fields set in over replacing its own.
+func (g GenerationConfig) merge(over GenerationConfig) GenerationConfig {
+	if over.Temperature != nil {
+		g.Temperature = over.Temperature
+	}
+	if over.TopP != nil {
+		g.TopP = over.TopP
+	}
+	if over.MaxOutputTokens != 0 {
+		g.MaxOutputTokens = over.MaxOutputTokens
+	}
+	if over.ThinkingBudget != nil {
+		g.ThinkingBudget = over.ThinkingBudget
+	}
+	if over.ThinkingLevel != "" {
+		g.ThinkingLevel = over.ThinkingLevel
+	}
+	return g
+}
+
+// apply copies the fields set in g to cfg.
+func (g GenerationConfig) apply(cfg *genai.GenerateContentConfig) {
+	if g.Temperature != nil {
+		cfg.Temperature = g.Temperature
+	}
+	if g.TopP != nil {
+		cfg.TopP = g.TopP
+	}
+	if g.MaxOutputTokens != 0 {
+		cfg.MaxOutputTokens = g.MaxOutputTokens
+	}
+	if g.ThinkingBudget != nil || g.ThinkingLevel != "" {
+		thinking := &genai.ThinkingConfig{ThinkingBudget: g.ThinkingBudget, ThinkingLevel: genai.ThinkingLevel(g.ThinkingLevel)}
+		if cfg.ThinkingConfig != nil {
+			thinking.IncludeThoughts = cfg.ThinkingConfig.IncludeThoughts
+		}
+		cfg.ThinkingConfig = thinking
+	}
+}
+
+// generation applies the agent's GenerationConfig, and a run's on top of it,
+// to every model request.
+type generation struct {
+	base GenerationConfig
+
+	mu  sync.Mutex
+	run GenerationConfig
+}
+
+// set applies a run's generation settings.
+func (g *generation) set(run GenerationConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.run = g.base.merge(run)
+}
+
+// beforeModel sets the request's generation parameters.
+func (g *generation) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	g.run.apply(req.Config)
+	return nil, nil
+}
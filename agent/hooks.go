@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/adk/tool"
+)
+
+// Hooks are callbacks into a run, for hosts that persist progress, update a
+// UI or enforce their own policies. All are optional and run on the agent's
+// goroutine, so they should return quickly.
+type Hooks struct {
+	// OnStepStart is called before the model picks its next action, with the
+	// number the resulting Step will have.
+	OnStepStart func(step int)
+
+	// OnToolCall is called before a tool runs. Returning an error blocks the
+	// call; the model is told it failed with the error's message.
+	OnToolCall func(ctx context.Context, name string, args map[string]any) error
+
+	// OnToolResult is called with each finished step.
+	OnToolResult func(Step)
+
+	// OnScreenshot is called with every JPEG screenshot shown to the model,
+	// and the number of the step the model is choosing with it.
+	OnScreenshot func(step int, data []byte)
+
+	// OnProgress is called with an estimate of how far the run is, after
+	// each step and when a subtask of a plan starts.
+	OnProgress func(Progress)
+
+	// OnDone is called with the result of a run that finished.
+	OnDone func(*Result)
+
+	// OnError is called when a run fails with an error instead.
+	OnError func(error)
+}
+
+// beforeTool runs OnToolCall, answering for the tool when it blocks the call.
+func (h Hooks) beforeTool(ctx tool.Context, tl tool.Tool, args map[string]any) (map[string]any, error) {
+	if h.OnToolCall == nil {
+		return nil, nil
+	}
+	if err := h.OnToolCall(ctx, tl.Name(), args); err != nil {
+		return map[string]any{
+			"success": false,
+			"message": fmt.Sprintf("%s blocked: %v", tl.Name(), err),
+		}, nil
+	}
+	return nil, nil
+}
+
+// reportProgress passes p to OnProgress.
+func (h Hooks) reportProgress(p Progress) {
+	if h.OnProgress != nil {
+		h.OnProgress(p)
+	}
+}
+
+// screenshot passes a captured screenshot to OnScreenshot.
+func (h Hooks) screenshot(step int, data []byte) {
+	if h.OnScreenshot != nil && len(data) > 0 {
+		h.OnScreenshot(step, data)
+	}
+}
@@ -0,0 +1,217 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultKeyCooldown is how long a rate-limited key rests when the API does
+// not say when to retry.
+const defaultKeyCooldown = time.Minute
+
+// maxErrorBody caps how much of a 429 response is read for its retry delay.
+const maxErrorBody = 64 << 10
+
+// KeyUsage reports how one API key of a KeyPool has been used.
+type KeyUsage struct {
+	// Key identifies the key by its last four characters, e.g. "…x9Qc".
+	Key string `json:"key"`
+
+	// Requests is the number of model requests sent with the key.
+	Requests int `json:"requests"`
+
+	// RateLimited is the number of those the API refused with 429.
+	RateLimited int `json:"rate_limited"`
+
+	// CoolingUntil is when the key is used again after a 429 (zero if it is available).
+	CoolingUntil time.Time `json:"cooling_until,omitempty"`
+}
+
+// KeyPool spreads model requests over several API keys. Keys take turns;
+// a key the API rate limits rests until the API says to retry, and the
+// request is sent again with the next available key. Only when every key
+// is resting does the 429 reach the caller.
+type KeyPool struct {
+	mu   sync.Mutex
+	keys []*poolKey
+	next int
+}
+
+type poolKey struct {
+	key          string
+	requests     int
+	rateLimited  int
+	coolingUntil time.Time
+}
+
+// NewKeyPool returns a pool of the given keys. Empty keys are skipped.
+func NewKeyPool(keys []string) *KeyPool {
+	p := &KeyPool{}
+	for _, k := range keys {
+		if k != "" {
+			p.keys = append(p.keys, &poolKey{key: k})
+		}
+	}
+	return p
+}
+
+// Len returns the number of keys in the pool.
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Usage returns the usage of every key, in the order they were given.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]KeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		usage[i] = KeyUsage{Key: maskKey(k.key), Requests: k.requests, RateLimited: k.rateLimited}
+		if k.coolingUntil.After(now) {
+			usage[i].CoolingUntil = k.coolingUntil
+		}
+	}
+	return usage
+}
+
+// Client returns an HTTP client that sends each request with a key from the
+// pool, through base (nil = default client).
+func (p *KeyPool) Client(base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	c := *base
+	c.Transport = &keyTransport{pool: p, base: base.Transport}
+	return &c
+}
+
+// pick returns the next key to use: the next available one in turn, or the
+// one that rests the shortest if all are resting. exclude holds keys that
+// were already tried for the request.
+func (p *KeyPool) pick(exclude map[*poolKey]bool) *poolKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var soonest *poolKey
+	for i := range p.keys {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if exclude[k] {
+			continue
+		}
+		if !k.coolingUntil.After(now) {
+			p.next = (p.next + i + 1) % len(p.keys)
+			k.requests++
+			return k
+		}
+		if soonest == nil || k.coolingUntil.Before(soonest.coolingUntil) {
+			soonest = k
+		}
+	}
+	if soonest != nil {
+		soonest.requests++
+	}
+	return soonest
+}
+
+// rest records a 429 for k, resting it for cooldown.
+func (p *KeyPool) rest(k *poolKey, cooldown time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k.rateLimited++
+	k.coolingUntil = time.Now().Add(cooldown)
+}
+
+// keyTransport sets the API key of each request from the pool.
+type keyTransport struct {
+	pool *KeyPool
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	tried := make(map[*poolKey]bool)
+	for {
+		k := t.pool.pick(tried)
+		if k == nil {
+			return base.RoundTrip(req)
+		}
+		tried[k] = true
+
+		r := req.Clone(req.Context())
+		r.Header.Set("x-goog-api-key", k.key)
+		if len(tried) > 1 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := base.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		cooldown := retryDelay(resp)
+		t.pool.rest(k, cooldown)
+		if len(tried) == t.pool.Len() || req.GetBody == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+}
+
+// retryDelay reads when a 429 response says to retry, from its Retry-After
+// header or the RetryInfo in its body, leaving the body readable.
+func retryDelay(resp *http.Response) time.Duration {
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return defaultKeyCooldown
+	}
+
+	var body struct {
+		Error struct {
+			Details []struct {
+				RetryDelay string `json:"retryDelay"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		for _, d := range body.Error.Details {
+			if delay, err := time.ParseDuration(d.RetryDelay); err == nil && delay > 0 {
+				return delay
+			}
+		}
+	}
+	return defaultKeyCooldown
+}
+
+// maskKey identifies a key by its last four characters.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "…"
+	}
+	return "…" + key[len(key)-4:]
+}
@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// Login describes how to sign in to a site. Credentials are only ever typed
+// into the page; the model sees <secret>name_username</secret> style
+// placeholders instead.
+type Login struct {
+	// Name identifies the login in prompts and placeholders, e.g. "github".
+	Name string
+
+	// Domain is the site the login applies to; subdomains match too.
+	Domain string
+
+	// LoginURL is the sign-in page.
+	LoginURL string
+
+	Username string
+	Password string
+
+	// TOTPSecret is the authenticator secret for sites with 2FA (optional).
+	TOTPSecret string
+
+	// Steps are extra instructions for the sign-in flow, e.g. "Click 'Use
+	// password instead' before entering the password" (optional).
+	Steps string
+
+	// LoggedOutPattern is a regular expression matched against the URL of a
+	// page that means the session has ended. Default: the LoginURL path, or a
+	// visible password field on the site.
+	LoggedOutPattern string
+}
+
+// secretPlaceholder matches <secret>name</secret> in typed text.
+var secretPlaceholder = regexp.MustCompile(`<secret>([A-Za-z0-9_.-]+)</secret>`)
+
+// secrets returns the placeholder values for all configured logins and
+// plain secrets.
+func (t *BrowserToolkit) secrets() map[string]string {
+	values := make(map[string]string, 2*len(t.logins)+len(t.extraSecrets))
+	for name, v := range t.extraSecrets {
+		values[name] = v
+	}
+	for _, l := range t.logins {
+		values[l.Name+"_username"] = l.Username
+		values[l.Name+"_password"] = l.Password
+	}
+	return values
+}
+
+// expandSecrets replaces secret placeholders with their values.
+func (t *BrowserToolkit) expandSecrets(text string) string {
+	if len(t.logins) == 0 && len(t.extraSecrets) == 0 {
+		return text
+	}
+	values := t.secrets()
+	return secretPlaceholder.ReplaceAllStringFunc(text, func(m string) string {
+		name := secretPlaceholder.FindStringSubmatch(m)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// findLogin returns the login with the given name.
+func (t *BrowserToolkit) findLogin(name string) *Login {
+	for i := range t.logins {
+		if strings.EqualFold(t.logins[i].Name, name) {
+			return &t.logins[i]
+		}
+	}
+	return nil
+}
+
+// loginForURL returns the login whose domain covers pageURL.
+func (t *BrowserToolkit) loginForURL(pageURL string) *Login {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	for i := range t.logins {
+		if inDomain(u.Hostname(), t.logins[i].Domain) {
+			return &t.logins[i]
+		}
+	}
+	return nil
+}
+
+// inDomain reports whether host is domain or one of its subdomains.
+func inDomain(host, domain string) bool {
+	host = strings.ToLower(strings.TrimPrefix(host, "."))
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+// passwordFieldJS reports whether the page shows a password input.
+const passwordFieldJS = `() => Array.from(document.querySelectorAll('input[type=password]')).some(el => el.offsetParent !== null)`
+
+// LoggedOut returns the login for the current site if the page looks signed
+// out, or nil.
+func (t *BrowserToolkit) LoggedOut() *Login {
+	pageURL := t.browser.GetURL()
+	l := t.loginForURL(pageURL)
+	if l == nil {
+		return nil
+	}
+
+	if l.LoggedOutPattern != "" {
+		re, err := regexp.Compile(l.LoggedOutPattern)
+		if err == nil && re.MatchString(pageURL) {
+			return l
+		}
+		return nil
+	}
+
+	if login, err := url.Parse(l.LoginURL); err == nil && login.Path != "" && login.Path != "/" {
+		if current, err := url.Parse(pageURL); err == nil && strings.HasPrefix(current.Path, login.Path) {
+			return l
+		}
+	}
+
+	page := t.browser.ActivePage()
+	if page == nil {
+		return nil
+	}
+	res, err := page.Eval(passwordFieldJS)
+	if err != nil || !res.Value.Bool() {
+		return nil
+	}
+	return l
+}
+
+// loginInstructions is the playbook returned by the login tool.
+func loginInstructions(l *Login) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Sign in to %s. Type <secret>%s_username</secret> into the username or email field and <secret>%s_password</secret> into the password field exactly as written; they are replaced with the real values when typed.", l.Name, l.Name, l.Name))
+	if l.TOTPSecret != "" {
+		sb.WriteString(fmt.Sprintf(" If asked for a two-factor code, call get_totp with name=%q.", l.Name))
+	}
+	if l.Steps != "" {
+		sb.WriteString(" ")
+		sb.WriteString(l.Steps)
+	}
+	sb.WriteString(" After signing in, go back to the page you need and continue the task.")
+	return sb.String()
+}
+
+// loginRequiredNote tells the model the session ended during the task.
+func loginRequiredNote(l *Login) string {
+	return fmt.Sprintf("\n\n<login_required>You are signed out of %s. Call login with name=%q, then continue the task.</login_required>", l.Name, l.Name)
+}
+
+// saveSession stores the cookies for a login's domain, so a fresh browser can
+// skip the sign-in.
+func (t *BrowserToolkit) saveSession(l *Login) error {
+	if t.sessionDir == "" {
+		return nil
+	}
+
+	cookies, err := t.browser.GetCookies(nil)
+	if err != nil {
+		return err
+	}
+	return writeSession(t.sessionDir, l, cookies)
+}
+
+// SaveSessions stores the cookies of each login's domain that the browser
+// has, as the login tool does after signing in, so sessions refreshed since
+// survive the browser.
+func SaveSessions(b browser.Interface, sessionDir string, logins []Login) error {
+	if sessionDir == "" || len(logins) == 0 {
+		return nil
+	}
+
+	cookies, err := b.GetCookies(nil)
+	if err != nil {
+		return err
+	}
+	for i := range logins {
+		if !slices.ContainsFunc(cookies, func(c browser.Cookie) bool { return inDomain(c.Domain, logins[i].Domain) }) {
+			continue
+		}
+		if err := writeSession(sessionDir, &logins[i], cookies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSession stores the cookies of l's domain among cookies.
+func writeSession(sessionDir string, l *Login, cookies []browser.Cookie) error {
+	var kept []browser.Cookie
+	for _, c := range cookies {
+		if inDomain(c.Domain, l.Domain) {
+			kept = append(kept, c)
+		}
+	}
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(sessionDir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionDir, l.Name+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// RestoreSessions loads saved session cookies for logins whose domain has no
+// cookies in the browser yet.
+func RestoreSessions(b browser.Interface, sessionDir string, logins []Login) error {
+	if sessionDir == "" || len(logins) == 0 {
+		return nil
+	}
+
+	current, err := b.GetCookies(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range logins {
+		present := false
+		for _, c := range current {
+			if inDomain(c.Domain, l.Domain) {
+				present = true
+				break
+			}
+		}
+		if present {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(sessionDir, l.Name+".json"))
+		if err != nil {
+			continue
+		}
+		var cookies []browser.Cookie
+		if err := json.Unmarshal(data, &cookies); err != nil {
+			return fmt.Errorf("failed to parse saved session for %s: %w", l.Name, err)
+		}
+		if err := b.SetCookies(nil, cookies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
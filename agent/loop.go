@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// loopRepeats is how many identical tool calls in a row count as a loop.
+	loopRepeats = 3
+
+	// loopOscillations is how many times the run must go back and forth
+	// between two pages to count as a loop.
+	loopOscillations = 2
+
+	// maxLoopWarnings is how often the agent is told it is looping before
+	// the run is aborted with ErrorCodeLoopDetected.
+	maxLoopWarnings = 2
+)
+
+// loopExempt are tools that are legitimately called several times in a row
+// with the same arguments, e.g. to scroll down a long page.
+var loopExempt = map[string]bool{
+	"scroll": true,
+	"wait":   true,
+	"done":   true,
+}
+
+// loopCall is a tool call, as the loop detector compares them.
+type loopCall struct {
+	action string // tool name and arguments, without reasoning
+	url    string // page after the call
+}
+
+// loopDetector watches a run's tool calls for the agent repeating itself:
+// the same call several times in a row, or going back and forth between two
+// pages.
+type loopDetector struct {
+	calls []loopCall
+}
+
+// record adds a tool call with its JSON arguments, and the URL of the page
+// after it.
+func (d *loopDetector) record(tool, args, url string) {
+	d.calls = append(d.calls, loopCall{action: tool + " " + loopArgs(args), url: visitKey(url)})
+	if max := 2 * (loopOscillations + 1); len(d.calls) > max {
+		d.calls = d.calls[len(d.calls)-max:]
+	}
+}
+
+// detect describes the loop the recent calls form, or returns "" if they
+// form none. A detected loop is forgotten, so the agent gets to break it.
+func (d *loopDetector) detect() string {
+	loop := d.repeated()
+	if loop == "" {
+		loop = d.oscillating()
+	}
+	if loop != "" {
+		d.calls = nil
+	}
+	return loop
+}
+
+// repeated reports the same tool call made loopRepeats times in a row.
+func (d *loopDetector) repeated() string {
+	n := len(d.calls)
+	if n < loopRepeats {
+		return ""
+	}
+	last := d.calls[n-1]
+	if loopExempt[toolOf(last.action)] {
+		return ""
+	}
+	for _, c := range d.calls[n-loopRepeats:] {
+		if c.action != last.action || c.url != last.url {
+			return ""
+		}
+	}
+	return fmt.Sprintf("the same call %s %d times in a row", last.action, loopRepeats)
+}
+
+// oscillating reports the run going back and forth between two pages.
+func (d *loopDetector) oscillating() string {
+	n := len(d.calls)
+	span := 2 * loopOscillations
+	if n < span+1 {
+		return ""
+	}
+	urls := make([]string, 0, span+1)
+	for _, c := range d.calls[n-span-1:] {
+		urls = append(urls, c.url)
+	}
+	a, b := urls[0], urls[1]
+	if a == b || a == "" || b == "" {
+		return ""
+	}
+	for i, u := range urls {
+		if (i%2 == 0 && u != a) || (i%2 == 1 && u != b) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("going back and forth between %s and %s", a, b)
+}
+
+// loopArgs returns the JSON arguments of a tool call without its reasoning,
+// which the model words differently each time.
+func loopArgs(args string) string {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(args), &m); err != nil {
+		return args
+	}
+	delete(m, "reasoning")
+	b, err := json.Marshal(m) // sorts keys
+	if err != nil {
+		return args
+	}
+	return string(b)
+}
+
+// toolOf returns the tool name of a loopCall action.
+func toolOf(action string) string {
+	for i := range action {
+		if action[i] == ' ' {
+			return action[:i]
+		}
+	}
+	return action
+}
+
+// loopFeedback tells the model it is looping.
+func loopFeedback(loop string) string {
+	return fmt.Sprintf("\n\n<loop_detected>\nYou are repeating yourself: %s. Repeating it again will not change the outcome. Try a different approach, e.g. another element, page or search, or call done with what you have and explain what blocked you.\n</loop_detected>", loop)
+}
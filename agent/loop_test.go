@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoopDetectorRepeats(t *testing.T) {
+	var d loopDetector
+	for i := range loopRepeats {
+		// Reasoning is worded differently each time but doesn't count
+		d.record("click", `{"index": 4, "reasoning": "try `+strings.Repeat("again ", i)+`"}`, "https://a.example/")
+		loop := d.detect()
+		if i < loopRepeats-1 && loop != "" {
+			t.Fatalf("call %d: detected %q early", i+1, loop)
+		}
+		if i == loopRepeats-1 && !strings.Contains(loop, "click") {
+			t.Fatalf("call %d: detect() = %q, want the repeated click", i+1, loop)
+		}
+	}
+
+	// A detected loop is forgotten
+	d.record("click", `{"index": 4}`, "https://a.example/")
+	if loop := d.detect(); loop != "" {
+		t.Errorf("detect() after a reported loop = %q", loop)
+	}
+}
+
+func TestLoopDetectorIgnores(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls [][3]string
+	}{
+		{"exempt tool", [][3]string{
+			{"scroll", `{"direction": "down"}`, "https://a.example"},
+			{"scroll", `{"direction": "down"}`, "https://a.example"},
+			{"scroll", `{"direction": "down"}`, "https://a.example"},
+		}},
+		{"different arguments", [][3]string{
+			{"click", `{"index": 1}`, "https://a.example"},
+			{"click", `{"index": 2}`, "https://a.example"},
+			{"click", `{"index": 3}`, "https://a.example"},
+		}},
+		{"different pages", [][3]string{
+			{"click", `{"index": 1}`, "https://a.example/1"},
+			{"click", `{"index": 1}`, "https://a.example/2"},
+			{"click", `{"index": 1}`, "https://a.example/3"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d loopDetector
+			for _, c := range tt.calls {
+				d.record(c[0], c[1], c[2])
+				if loop := d.detect(); loop != "" {
+					t.Fatalf("detect() = %q, want no loop", loop)
+				}
+			}
+		})
+	}
+}
+
+func TestLoopDetectorOscillation(t *testing.T) {
+	var d loopDetector
+	urls := []string{"https://a.example/list", "https://a.example/item#x", "https://a.example/list/", "https://a.example/item", "https://A.example/list"}
+	var loop string
+	for i, u := range urls {
+		d.record("navigate", `{"step": `+string(rune('0'+i))+`}`, u)
+		loop = d.detect()
+		if i < len(urls)-1 && loop != "" {
+			t.Fatalf("call %d: detected %q early", i+1, loop)
+		}
+	}
+	if !strings.Contains(loop, "back and forth") || !strings.Contains(loop, "https://a.example/item") {
+		t.Errorf("detect() = %q, want back and forth between the list and the item", loop)
+	}
+}
+
+func TestLoopArgs(t *testing.T) {
+	if got, want := loopArgs(`{"reasoning": "x", "b": 1, "a": 2}`), `{"a":2,"b":1}`; got != want {
+		t.Errorf("loopArgs() = %s, want %s", got, want)
+	}
+	if got := loopArgs("not json"); got != "not json" {
+		t.Errorf("loopArgs(invalid) = %s, want it unchanged", got)
+	}
+}
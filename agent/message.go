@@ -15,6 +15,7 @@ type MessageManager struct {
 	sensitiveFilter *SensitiveDataFilter
 	maxElements     int
 	useVision       bool
+	fullElements    bool
 }
 
 // MessageManagerConfig configures the message manager.
@@ -22,6 +23,11 @@ type MessageManagerConfig struct {
 	MaxHistoryItems int
 	MaxElements     int
 	UseVision       bool
+	SystemPrompt    string // empty = SystemPrompt()
+
+	// FullElementLists sends the full element list in every page state,
+	// instead of the changes since the last one when the page is the same.
+	FullElementLists bool
 }
 
 // NewMessageManager creates a new message manager.
@@ -36,12 +42,18 @@ func NewMessageManager(cfg MessageManagerConfig) *MessageManager {
 		maxElements = 100
 	}
 
+	systemPrompt := cfg.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = SystemPrompt()
+	}
+
 	return &MessageManager{
-		systemPrompt:    SystemPrompt(),
+		systemPrompt:    systemPrompt,
 		history:         NewAgentHistory(maxHistory),
 		sensitiveFilter: NewSensitiveDataFilter(),
 		maxElements:     maxElements,
 		useVision:       cfg.UseVision,
+		fullElements:    cfg.FullElementLists,
 	}
 }
 
@@ -65,8 +77,22 @@ func (m *MessageManager) GetHistory() *AgentHistory {
 	return m.history
 }
 
-// BuildStateMessage builds the current state message for the LLM.
-func (m *MessageManager) BuildStateMessage(elementMap *dom.ElementMap, lastActionResult string, screenshotIncluded bool) string {
+// elementsText serializes elementMap for the LLM: as the changes since shown,
+// the element map of the last page state the LLM saw, if that is shorter,
+// or else in full.
+func (m *MessageManager) elementsText(elementMap, shown *dom.ElementMap) string {
+	if !m.fullElements {
+		if diff, ok := elementMap.ToDiffString(shown, m.maxElements, m.history.taskDescription); ok {
+			return diff
+		}
+	}
+	return elementMap.ToTokenStringFor(m.maxElements, m.history.taskDescription)
+}
+
+// BuildStateMessage builds the current state message for the LLM. shown is
+// the element map of the last page state the LLM saw (nil if none), so that
+// only the changes since can be sent.
+func (m *MessageManager) BuildStateMessage(elementMap, shown *dom.ElementMap, lastActionResult string, screenshotIncluded bool) string {
 	var sb strings.Builder
 
 	// Add current page state
@@ -74,7 +100,7 @@ func (m *MessageManager) BuildStateMessage(elementMap *dom.ElementMap, lastActio
 		pageState := BuildPageStatePrompt(
 			elementMap.PageURL,
 			elementMap.PageTitle,
-			elementMap.ToTokenStringLimited(m.maxElements),
+			m.elementsText(elementMap, shown),
 			screenshotIncluded,
 		)
 		sb.WriteString(pageState)
@@ -119,7 +145,7 @@ func (m *MessageManager) BuildInitialTaskMessage(task string, elementMap *dom.El
 		pageState := BuildPageStatePrompt(
 			elementMap.PageURL,
 			elementMap.PageTitle,
-			elementMap.ToTokenStringLimited(m.maxElements),
+			elementMap.ToTokenStringFor(m.maxElements, m.history.taskDescription),
 			false,
 		)
 		sb.WriteString(pageState)
@@ -129,14 +155,15 @@ func (m *MessageManager) BuildInitialTaskMessage(task string, elementMap *dom.El
 }
 
 // BuildContinuationMessage builds a message for continuing after an action.
-func (m *MessageManager) BuildContinuationMessage(elementMap *dom.ElementMap, actionName, actionResult string, success bool) string {
+// shown is as for BuildStateMessage.
+func (m *MessageManager) BuildContinuationMessage(elementMap, shown *dom.ElementMap, actionName, actionResult string, success bool) string {
 	var sb strings.Builder
 
 	// Update the last history item with results
 	m.history.UpdateLastItem(actionResult, success)
 
 	// Build state message
-	sb.WriteString(m.BuildStateMessage(elementMap, actionResult, false))
+	sb.WriteString(m.BuildStateMessage(elementMap, shown, actionResult, false))
 	sb.WriteString("\n\n")
 
 	// Add continuation instruction
@@ -154,7 +181,7 @@ func (m *MessageManager) BuildErrorRecoveryMessage(elementMap *dom.ElementMap, e
 		pageState := BuildPageStatePrompt(
 			elementMap.PageURL,
 			elementMap.PageTitle,
-			elementMap.ToTokenStringLimited(m.maxElements),
+			elementMap.ToTokenStringFor(m.maxElements, m.history.taskDescription),
 			false,
 		)
 		sb.WriteString(pageState)
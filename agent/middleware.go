@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+)
+
+// ToolCall is a tool invocation as chosen by the model.
+type ToolCall struct {
+	// Name is the tool's name, e.g. "click".
+	Name string
+
+	// Args are the arguments the model passed, as decoded from JSON.
+	Args map[string]any
+}
+
+// ToolHandler runs a tool call and returns the result the model sees.
+type ToolHandler func(ctx context.Context, call ToolCall) (map[string]any, error)
+
+// ToolMiddleware wraps the handler of every tool, like HTTP middleware: it can
+// inspect or rewrite the call, skip next and answer itself, or inspect and
+// rewrite the result. An error fails the call with the error's message.
+type ToolMiddleware func(next ToolHandler) ToolHandler
+
+// functionTool is the method set ADK runs tools through.
+type functionTool interface {
+	tool.Tool
+	Declaration() *genai.FunctionDeclaration
+	Run(ctx tool.Context, args any) (map[string]any, error)
+	ProcessRequest(ctx tool.Context, req *model.LLMRequest) error
+}
+
+// wrapTools applies middleware to every tool. The first middleware is the
+// outermost.
+func wrapTools(tools []tool.Tool, middleware []ToolMiddleware) ([]tool.Tool, error) {
+	if len(middleware) == 0 {
+		return tools, nil
+	}
+
+	wrapped := make([]tool.Tool, len(tools))
+	for i, t := range tools {
+		ft, ok := t.(functionTool)
+		if !ok {
+			return nil, fmt.Errorf("tool %q cannot be wrapped by middleware", t.Name())
+		}
+		mt := &middlewareTool{functionTool: ft}
+		mt.handler = mt.run
+		for j := len(middleware) - 1; j >= 0; j-- {
+			mt.handler = middleware[j](mt.handler)
+		}
+		wrapped[i] = mt
+	}
+	return wrapped, nil
+}
+
+// middlewareTool runs a tool through its middleware chain.
+type middlewareTool struct {
+	functionTool
+	handler ToolHandler
+}
+
+// ProcessRequest declares the tool to the model, registering the wrapper to
+// run its calls.
+func (t *middlewareTool) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
+	if err := t.functionTool.ProcessRequest(ctx, req); err != nil {
+		return err
+	}
+	req.Tools[t.Name()] = t
+	return nil
+}
+
+// Run implements the tool through the middleware chain.
+func (t *middlewareTool) Run(ctx tool.Context, args any) (map[string]any, error) {
+	m, ok := args.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected args type, got: %T", args)
+	}
+
+	result, err := t.handler(context.WithValue(ctx, toolContextKey{}, ctx), ToolCall{Name: t.Name(), Args: m})
+	if err != nil {
+		return map[string]any{"success": false, "message": err.Error()}, nil
+	}
+	return result, nil
+}
+
+// run is the end of the chain: it runs the tool itself, under the context
+// the middleware passed on.
+func (t *middlewareTool) run(ctx context.Context, call ToolCall) (map[string]any, error) {
+	tc, ok := ctx.Value(toolContextKey{}).(tool.Context)
+	if !ok {
+		return nil, fmt.Errorf("%s called without its tool context", call.Name)
+	}
+	return t.functionTool.Run(toolContext{Context: tc, ctx: ctx}, call.Args)
+}
+
+// toolContextKey holds the ADK tool context of a call in the context passed
+// through the middleware chain.
+type toolContextKey struct{}
+
+// toolContext is an ADK tool context whose cancellation and values come from
+// the context the middleware passed on.
+type toolContext struct {
+	tool.Context
+	ctx context.Context
+}
+
+func (c toolContext) Deadline() (time.Time, bool) { return c.ctx.Deadline() }
+func (c toolContext) Done() <-chan struct{}       { return c.ctx.Done() }
+func (c toolContext) Err() error                  { return c.ctx.Err() }
+func (c toolContext) Value(key any) any           { return c.ctx.Value(key) }
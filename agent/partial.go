@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// maxReads caps the page reads kept for a partial result; the latest
+	// are kept.
+	maxReads = 10
+
+	// maxReadLen caps the length of one page read.
+	maxReadLen = 4000
+)
+
+// readFields maps the tools that read the page to the field of their
+// result that holds what they read.
+var readFields = map[string]string{
+	"extract_content": "content",
+	"read_text":       "text",
+	"eval_js":         "result",
+}
+
+// PartialData is the Data of a Result with PartialResult set: what a run
+// that ended without calling done had found so far.
+type PartialData struct {
+	// Output is the model's last text, parsed if it is JSON.
+	Output any `json:"output,omitempty"`
+
+	// Reads are the latest results of tools that read the page, such as
+	// extract_content, oldest first.
+	Reads []PageRead `json:"reads,omitempty"`
+}
+
+// PageRead is what one tool call read from a page.
+type PageRead struct {
+	Tool    string `json:"tool"`
+	URL     string `json:"url,omitempty"`
+	Content string `json:"content"`
+}
+
+// partialCollector gathers what a run finds, for its result if the run
+// ends without done.
+type partialCollector struct {
+	output string
+	reads  []PageRead
+}
+
+// text records text the model wrote.
+func (p *partialCollector) text(s string) {
+	if s = strings.TrimSpace(s); s != "" {
+		p.output = s
+	}
+}
+
+// toolResult records the result of a tool call on the page at url, if the
+// tool reads the page and succeeded.
+func (p *partialCollector) toolResult(tool, url string, resp map[string]any) {
+	field, ok := readFields[tool]
+	if !ok {
+		return
+	}
+	if success, _ := resp["success"].(bool); !success {
+		return
+	}
+	content, _ := resp[field].(string)
+	if content = strings.TrimSpace(content); content == "" {
+		return
+	}
+	if len(content) > maxReadLen {
+		content = truncate(content, maxReadLen) + "..."
+	}
+	p.reads = append(p.reads, PageRead{Tool: tool, URL: url, Content: content})
+	if len(p.reads) > maxReads {
+		p.reads = p.reads[len(p.reads)-maxReads:]
+	}
+}
+
+// finish completes the result r of a run that ended without done: it sets
+// the CompletionReason for r's ErrorCode and, unless nothing was collected,
+// puts what was into r's Data and marks r partial. It returns r.
+func (p *partialCollector) finish(r *Result) *Result {
+	r.CompletionReason = completionReason(r.ErrorCode)
+	if p.output == "" && len(p.reads) == 0 {
+		return r
+	}
+	r.Data = &PartialData{Output: parseOutput(p.output), Reads: p.reads}
+	r.PartialResult = true
+	return r
+}
+
+// parseOutput returns s parsed as JSON, also inside a Markdown code fence,
+// or s itself if it is not JSON.
+func parseOutput(s string) any {
+	if s == "" {
+		return nil
+	}
+	raw := strings.TrimSpace(s)
+	if strings.HasPrefix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```json")
+		raw = strings.TrimPrefix(raw, "```")
+		raw = strings.TrimSuffix(strings.TrimSpace(raw), "```")
+	}
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err == nil {
+		return v
+	}
+	return s
+}
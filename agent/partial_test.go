@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPartialCollector(t *testing.T) {
+	var p partialCollector
+	p.text("  ")
+	p.toolResult("click", "https://a.example", map[string]any{"success": true, "content": "ignored"})
+	p.toolResult("extract_content", "https://a.example", map[string]any{"success": false, "content": "failed"})
+
+	r := p.finish(&Result{ErrorCode: ErrorCodeStepBudget})
+	if r.PartialResult || r.Data != nil {
+		t.Fatalf("nothing collected, got partial=%v data=%v", r.PartialResult, r.Data)
+	}
+
+	p.text("```json\n{\"price\": 3}\n```")
+	p.toolResult("read_text", "https://a.example", map[string]any{"success": true, "text": "Price: 3"})
+	r = p.finish(&Result{ErrorCode: ErrorCodeStepBudget})
+	data, ok := r.Data.(*PartialData)
+	if !r.PartialResult || !ok {
+		t.Fatalf("got partial=%v data=%T", r.PartialResult, r.Data)
+	}
+	if out, _ := data.Output.(map[string]any); out["price"] != 3.0 {
+		t.Errorf("Output = %v, want parsed JSON", data.Output)
+	}
+	if len(data.Reads) != 1 || data.Reads[0].Tool != "read_text" || data.Reads[0].Content != "Price: 3" {
+		t.Errorf("Reads = %+v", data.Reads)
+	}
+}
+
+func TestPartialCollectorKeepsLatestReads(t *testing.T) {
+	var p partialCollector
+	for i := range maxReads + 3 {
+		p.toolResult("eval_js", "", map[string]any{"success": true, "result": strings.Repeat("x", i+1)})
+	}
+	if len(p.reads) != maxReads {
+		t.Fatalf("kept %d reads, want %d", len(p.reads), maxReads)
+	}
+	if got := len(p.reads[0].Content); got != 4 {
+		t.Errorf("oldest kept read has length %d, want 4", got)
+	}
+}
+
+func TestPartialCollectorTruncatesOnRuneBoundary(t *testing.T) {
+	var p partialCollector
+	p.toolResult("extract_content", "", map[string]any{"success": true, "content": strings.Repeat("日", maxReadLen)})
+
+	content := p.reads[0].Content
+	if !utf8.ValidString(content) {
+		t.Fatal("truncated content is not valid UTF-8")
+	}
+	if len(content) > maxReadLen+len("...") {
+		t.Errorf("content is %d bytes, want at most %d", len(content), maxReadLen+len("..."))
+	}
+}
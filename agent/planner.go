@@ -0,0 +1,321 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Architectures select how a task is carried out.
+const (
+	// ArchitectureSingle runs the whole task in one agent loop.
+	ArchitectureSingle = "single"
+
+	// ArchitecturePlanExecute has a planner model split the task into
+	// subtasks, runs each in its own agent loop, and has the planner verify
+	// the outcome at the end. It helps on long tasks that span several sites.
+	ArchitecturePlanExecute = "plan-execute"
+)
+
+// defaultPlannerModel is the cheap model used for planning and verification.
+const defaultPlannerModel = "gemini-2.5-flash"
+
+// maxReplans bounds how often a failed subtask sends the run back to the planner.
+const maxReplans = 2
+
+// planPrompt asks the planner to decompose a task.
+const planPrompt = `You plan tasks for a browser automation agent. The agent controls a real web browser: it can navigate, click, type, scroll, switch tabs, and extract data, and it finishes each instruction by reporting data.
+
+Split the task below into 1-8 sequential subtasks. Each subtask must be a self-contained instruction the agent can complete on its own, naming the site or page when known and the data it must report for later subtasks. Do not split steps that belong on the same page.
+
+Task: %s
+%s
+Respond with JSON only: {"subtasks": [{"goal": "...", "done_when": "..."}]}`
+
+// verifyPrompt asks the planner to judge the combined outcome.
+const verifyPrompt = `You verify the work of a browser automation agent.
+
+Task: %s
+
+Subtasks and what the agent reported:
+%s
+Current page: %s (%s)
+
+Was the task fully accomplished? If it was, combine the reported data into the final answer the task asks for.
+
+Respond with JSON only: {"success": true|false, "reason": "...", "data": <final answer or null>}`
+
+// subtask is one step of a plan.
+type subtask struct {
+	Goal     string `json:"goal"`
+	DoneWhen string `json:"done_when"`
+}
+
+// subtaskOutcome records how a subtask went, for the executor and verifier.
+type subtaskOutcome struct {
+	subtask
+	Success bool
+	Data    any
+	Error   string
+}
+
+// verdict is the verifier's judgment.
+type verdict struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason"`
+	Data    any    `json:"data"`
+}
+
+// runPlanExecute plans the task, executes each subtask in turn, replanning
+// after failures, and verifies the combined result.
+func (a *BrowserAgent) runPlanExecute(ctx context.Context, task string) (*Result, error) {
+	startTime := time.Now()
+
+	plan, err := a.plan(ctx, task, nil)
+	if err != nil {
+		a.log("Planner").Warn("Planning failed, running task directly", "err", err)
+		return a.runLoop(ctx, task)
+	}
+
+	// The schema is for the task's data, not that of each subtask
+	schema := a.schema
+	a.schema = nil
+	defer func() { a.schema = schema }()
+
+	var (
+		outcomes    []subtaskOutcome
+		steps       []Step
+		screenshots []string
+		downloads   []string
+		assertions  []Assertion
+		sections    []Section
+		findings    = newFindingStore(a.findingKeys, a.findingMerge)
+		usage       TokenUsage
+		replans     int
+		lastCode    ErrorCode
+		lastReason  CompletionReason
+		lastPartial bool
+		lastSummary string
+		blocked     *BlockedError
+	)
+
+	for i := 0; i < len(plan); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if a.overTokenBudget() && len(outcomes) > 0 {
+			// The last subtask handed in its answer; there is nothing left for the rest
+			lastCode = ErrorCodeTokenBudget
+			break
+		}
+		st := plan[i]
+		a.log("Planner").Debug("Subtask", "number", len(outcomes)+1, "of", len(outcomes)+len(plan)-i, "goal", st.Goal)
+		a.hooks.reportProgress(a.progress.startSubtask(st.Goal, len(outcomes)+1, len(outcomes)+len(plan)-i))
+
+		res, err := a.runLoop(ctx, executorPrompt(task, outcomes, st))
+		if err != nil {
+			return nil, err
+		}
+		a.progress.finishSubtask(len(res.Steps))
+
+		// Collect steps, renumbered to continue across subtasks
+		for _, s := range res.Steps {
+			s.Number = len(steps) + 1
+			steps = append(steps, s)
+		}
+		screenshots = append(screenshots, res.ScreenshotPaths...)
+		downloads = append(downloads, a.toolkit.downloadPaths...)
+		assertions = append(assertions, a.toolkit.assertions...)
+		for _, s := range a.toolkit.sections {
+			sections = addSection(sections, s)
+		}
+		for _, r := range a.toolkit.findings.list() {
+			findings.add(r)
+		}
+		usage = usage.add(res.Usage)
+		lastCode = res.ErrorCode
+		lastReason, lastPartial = res.CompletionReason, res.PartialResult
+		lastSummary = res.Summary
+		blocked = res.Blocked
+
+		outcome := subtaskOutcome{subtask: st, Success: res.Success, Data: res.Data, Error: res.Error}
+		outcomes = append(outcomes, outcome)
+		if res.Success {
+			continue
+		}
+
+		if replans >= maxReplans || stopsPlan(res.ErrorCode) {
+			break
+		}
+		replans++
+		a.log("Planner").Debug("Subtask failed, replanning", "err", res.Error)
+		next, err := a.plan(ctx, task, outcomes)
+		if err != nil {
+			break
+		}
+		plan, i = next, -1
+	}
+
+	// Restore the run's combined record for GetSteps and friends
+	a.steps = steps
+	a.screenshotPaths = screenshots
+	a.toolkit.downloadPaths = downloads
+	a.toolkit.assertions = assertions
+	a.toolkit.sections = sections
+	a.toolkit.findings = findings
+
+	result := (&Result{
+		Steps:            steps,
+		ScreenshotPaths:  screenshots,
+		CompletionReason: lastReason,
+		Summary:          lastSummary,
+	}).setUsage(usage)
+
+	if stopsPlan(lastCode) {
+		// The budget or time is spent or the site blocked us; don't pay for a verdict on unfinished work
+		last := outcomes[len(outcomes)-1]
+		result.Data, result.Error, result.ErrorCode = last.Data, last.Error, lastCode
+		result.Blocked, result.PartialResult = blocked, lastPartial
+		if result.Error == "" {
+			result.Error = "Budget exhausted before the plan was finished"
+		}
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	v, err := a.verify(ctx, task, outcomes)
+	if err != nil {
+		// Without a verdict, fall back to the last subtask's outcome
+		last := outcomes[len(outcomes)-1]
+		result.Success, result.Data, result.Error = last.Success, last.Data, last.Error
+		a.log("Planner").Warn("Verification failed", "err", err)
+	} else {
+		result.Success, result.Data = v.Success, v.Data
+		if !v.Success {
+			result.Error = v.Reason
+		}
+		if result.Data == nil && len(outcomes) > 0 {
+			result.Data = outcomes[len(outcomes)-1].Data
+		}
+		result.Data = a.normalizeData(schema, result.Data)
+		a.log("Planner").Debug("Verified", "success", v.Success, "reason", v.Reason)
+	}
+
+	if a.strict && result.Success {
+		if last := outcomes[len(outcomes)-1]; !last.Success {
+			result.Success, result.Error = false, "The last subtask did not finish successfully"
+		} else if schema != nil {
+			if err := schema.validate(result.Data); err != nil {
+				result.Success, result.Error = false, "Data does not match the result schema: "+err.Error()
+			}
+		}
+	}
+
+	if !result.Success {
+		// The verifier judges the whole task; the last subtask says how it went wrong
+		result.ErrorCode = lastCode
+		if result.ErrorCode == "" {
+			result.ErrorCode = ErrorCodeTaskFailed
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// stopsPlan reports whether a subtask that failed with code leaves nothing
+// for replanning to fix.
+func stopsPlan(code ErrorCode) bool {
+	switch code {
+	case ErrorCodeCostBudget, ErrorCodeTokenBudget, ErrorCodeTimeout, ErrorCodeBlocked:
+		return true
+	}
+	return false
+}
+
+// plan asks the planner for the remaining subtasks, given what has run so far.
+func (a *BrowserAgent) plan(ctx context.Context, task string, outcomes []subtaskOutcome) ([]subtask, error) {
+	progress := ""
+	if len(outcomes) > 0 {
+		progress = "\nProgress so far (plan only the remaining work, and a different approach for what failed):\n" + describeOutcomes(outcomes)
+	}
+
+	var p struct {
+		Subtasks []subtask `json:"subtasks"`
+	}
+	if err := a.generateJSON(ctx, fmt.Sprintf(planPrompt, task, progress), &p); err != nil {
+		return nil, err
+	}
+	if len(p.Subtasks) == 0 {
+		return nil, fmt.Errorf("planner returned no subtasks")
+	}
+	return p.Subtasks, nil
+}
+
+// verify asks the planner whether the outcomes accomplish the task.
+func (a *BrowserAgent) verify(ctx context.Context, task string, outcomes []subtaskOutcome) (*verdict, error) {
+	prompt := fmt.Sprintf(verifyPrompt, task, describeOutcomes(outcomes), a.browser.GetURL(), a.browser.GetTitle())
+	var v verdict
+	if err := a.generateJSON(ctx, prompt, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// generateJSON sends a single prompt to the planner model and decodes its JSON reply.
+func (a *BrowserAgent) generateJSON(ctx context.Context, prompt string, out any) error {
+	resp, err := a.planner.Models.GenerateContent(ctx, a.plannerModel,
+		genai.Text(prompt),
+		&genai.GenerateContentConfig{ResponseMIMEType: "application/json"},
+	)
+	if err != nil {
+		return fmt.Errorf("planner request failed: %w", err)
+	}
+
+	text := strings.TrimSpace(resp.Text())
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```json"), "```")
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return fmt.Errorf("failed to decode planner response: %w", err)
+	}
+	return nil
+}
+
+// executorPrompt builds the task message for one subtask.
+func executorPrompt(task string, outcomes []subtaskOutcome, st subtask) string {
+	var sb strings.Builder
+	sb.WriteString("You are carrying out one step of a larger task.\n\n")
+	sb.WriteString("Overall task: " + task + "\n\n")
+	if len(outcomes) > 0 {
+		sb.WriteString("Completed so far:\n" + describeOutcomes(outcomes) + "\n")
+	}
+	sb.WriteString("Your step: " + st.Goal + "\n")
+	if st.DoneWhen != "" {
+		sb.WriteString("Done when: " + st.DoneWhen + "\n")
+	}
+	sb.WriteString("\nDo only this step, then call done with the data later steps need.")
+	return sb.String()
+}
+
+// describeOutcomes lists subtasks with their status and reported data.
+func describeOutcomes(outcomes []subtaskOutcome) string {
+	var sb strings.Builder
+	for i, o := range outcomes {
+		status := "succeeded"
+		if !o.Success {
+			status = "failed: " + o.Error
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s — %s\n", i+1, o.Goal, status))
+		if o.Data != nil {
+			data, _ := json.Marshal(o.Data)
+			if len(data) > 2000 {
+				data = append(data[:2000], "..."...)
+			}
+			sb.WriteString(fmt.Sprintf("   Reported: %s\n", data))
+		}
+	}
+	return sb.String()
+}
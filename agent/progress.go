@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// defaultStepEstimate is how many steps a task is assumed to take until
+	// it takes more.
+	defaultStepEstimate = 10
+
+	// defaultSubtaskSteps is how many steps a subtask is assumed to take
+	// until one has finished.
+	defaultSubtaskSteps = 5
+
+	// recentActions is how many narrated actions Progress keeps.
+	recentActions = 5
+
+	// maxNarrationLen bounds a narrated action.
+	maxNarrationLen = 100
+)
+
+// Progress is a rough account of how far a run is, for UIs that show more
+// than a spinner. The estimates are heuristics that firm up as the run goes:
+// in plan-execute mode they follow the plan, otherwise the steps taken.
+type Progress struct {
+	// Step is the number of steps taken so far.
+	Step int `json:"step"`
+
+	// EstimatedSteps is the likely total number of steps; never less than
+	// Step.
+	EstimatedSteps int `json:"estimated_steps"`
+
+	// Percent estimates how much of the run is done, from 0 to 100. It
+	// reaches 100 only once the run is over.
+	Percent int `json:"percent"`
+
+	// Goal is the subtask being worked on in plan-execute mode, and the task
+	// otherwise.
+	Goal string `json:"goal"`
+
+	// Subtask and Subtasks number the current subtask of the plan; both are
+	// zero outside plan-execute mode.
+	Subtask  int `json:"subtask,omitempty"`
+	Subtasks int `json:"subtasks,omitempty"`
+
+	// Action narrates the latest step, in the model's words where it gave
+	// its reasoning.
+	Action string `json:"action,omitempty"`
+
+	// Recent narrates the latest few steps, oldest first.
+	Recent []string `json:"recent,omitempty"`
+}
+
+// String formats p for a status line, e.g. "Step 7/~15: extracting star
+// count".
+func (p Progress) String() string {
+	s := fmt.Sprintf("Step %d/~%d", p.Step, p.EstimatedSteps)
+	if p.Action != "" {
+		s += ": " + p.Action
+	}
+	return s
+}
+
+// progressTracker estimates the Progress of a run.
+type progressTracker struct {
+	maxSteps int
+	goal     string
+	recent   []string
+
+	// Plan-execute mode
+	subtask  int
+	subtasks int
+	base     int // steps of the finished subtasks
+	finished int // finished subtasks
+	current  int // steps of the current subtask
+}
+
+// reset starts tracking a run of task.
+func (p *progressTracker) reset(task string, maxSteps int) {
+	*p = progressTracker{maxSteps: maxSteps, goal: task}
+}
+
+// startSubtask notes that subtask n of total, with goal, starts.
+func (p *progressTracker) startSubtask(goal string, n, total int) Progress {
+	p.goal, p.subtask, p.subtasks, p.current = goal, n, total, 0
+	return p.progress()
+}
+
+// finishSubtask notes that the current subtask finished after steps steps.
+func (p *progressTracker) finishSubtask(steps int) {
+	p.base += steps
+	p.finished++
+	p.current = 0
+}
+
+// step notes a finished step of the current run or subtask.
+func (p *progressTracker) step(s Step) Progress {
+	p.current = s.Number
+	p.recent = append(p.recent, Narrate(s.Action, s.Target))
+	if len(p.recent) > recentActions {
+		p.recent = p.recent[len(p.recent)-recentActions:]
+	}
+	return p.progress()
+}
+
+// finish returns the Progress of a run that is over.
+func (p *progressTracker) finish() Progress {
+	pr := p.progress()
+	pr.EstimatedSteps, pr.Percent = pr.Step, 100
+	return pr
+}
+
+// progress estimates where the run stands.
+func (p *progressTracker) progress() Progress {
+	pr := Progress{
+		Step:     p.base + p.current,
+		Goal:     p.goal,
+		Subtask:  p.subtask,
+		Subtasks: p.subtasks,
+		Recent:   append([]string(nil), p.recent...),
+	}
+	if n := len(p.recent); n > 0 {
+		pr.Action = p.recent[n-1]
+	}
+
+	if p.subtasks > 0 {
+		// The remaining subtasks take as long as the finished ones did
+		per := defaultSubtaskSteps
+		if p.finished > 0 {
+			per = (p.base + p.finished - 1) / p.finished
+		}
+		pr.EstimatedSteps = p.base + max(per, p.current+1) + (p.subtasks-p.subtask)*per
+	} else {
+		// Past the default, assume a quarter more than taken so far
+		est := defaultStepEstimate
+		if p.maxSteps > 0 {
+			est = min(est, p.maxSteps)
+		}
+		if p.current >= est {
+			est = p.current + (p.current+3)/4
+		}
+		if p.maxSteps > 0 {
+			est = min(est, max(p.maxSteps, p.current+1))
+		}
+		pr.EstimatedSteps = est
+	}
+	pr.EstimatedSteps = max(pr.EstimatedSteps, pr.Step)
+	if pr.EstimatedSteps > 0 {
+		pr.Percent = min(pr.Step*100/pr.EstimatedSteps, 99)
+	}
+	return pr
+}
+
+// Narrate describes a tool call in a few words, given its name and JSON
+// arguments: the model's reasoning for it, or else the tool.
+func Narrate(action, args string) string {
+	var parsed struct {
+		Reasoning string `json:"reasoning"`
+	}
+	_ = json.Unmarshal([]byte(args), &parsed)
+	text := strings.TrimSpace(parsed.Reasoning)
+	if text == "" {
+		text = strings.ReplaceAll(action, "_", " ")
+	}
+	if len(text) > maxNarrationLen {
+		text = strings.TrimSpace(truncate(text, maxNarrationLen)) + "..."
+	}
+	return text
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
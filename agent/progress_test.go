@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNarrate(t *testing.T) {
+	tests := []struct {
+		name, action, args, want string
+	}{
+		{"reasoning", "click", `{"reasoning": " Open the cart "}`, "Open the cart"},
+		{"no reasoning", "type_text", `{"index": 3}`, "type text"},
+		{"invalid args", "scroll", `not json`, "scroll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Narrate(tt.action, tt.args); got != tt.want {
+				t.Errorf("Narrate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNarrateTruncatesOnRuneBoundary(t *testing.T) {
+	// 2-byte characters put the byte limit in the middle of one
+	reasoning := strings.Repeat("é", maxNarrationLen)
+	got := Narrate("click", `{"reasoning": "`+reasoning+`"}`)
+
+	if !utf8.ValidString(got) {
+		t.Fatalf("Narrate() = %q, not valid UTF-8", got)
+	}
+	if !strings.HasSuffix(got, "...") || len(got) > maxNarrationLen+len("...") {
+		t.Errorf("Narrate() = %q (%d bytes), want at most %d bytes and ...", got, len(got), maxNarrationLen)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is 2 bytes
+		{"日本語", 4, "日"},   // 3 bytes each
+		{"日本語", 6, "日本"},
+		{"日本語", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	var p progressTracker
+	p.reset("Find the price", 100)
+
+	pr := p.step(Step{Number: 1, Action: "navigate"})
+	if pr.Step != 1 || pr.EstimatedSteps != defaultStepEstimate || pr.Percent != 10 || pr.Goal != "Find the price" {
+		t.Errorf("after step 1: %+v", pr)
+	}
+	if pr.Action != "navigate" || len(pr.Recent) != 1 {
+		t.Errorf("Action = %q, Recent = %v", pr.Action, pr.Recent)
+	}
+
+	// Past the default estimate, it grows with the steps taken
+	for n := 2; n <= 12; n++ {
+		pr = p.step(Step{Number: n, Action: "scroll"})
+	}
+	if pr.Step != 12 || pr.EstimatedSteps != 15 || pr.Percent >= 100 {
+		t.Errorf("after step 12: %+v", pr)
+	}
+	if len(pr.Recent) != recentActions {
+		t.Errorf("Recent has %d actions, want %d", len(pr.Recent), recentActions)
+	}
+
+	pr = p.finish()
+	if pr.Percent != 100 || pr.EstimatedSteps != pr.Step {
+		t.Errorf("finish() = %+v", pr)
+	}
+}
+
+func TestProgressTrackerMaxSteps(t *testing.T) {
+	var p progressTracker
+	p.reset("task", 4)
+
+	pr := p.step(Step{Number: 1, Action: "click"})
+	if pr.EstimatedSteps != 4 {
+		t.Errorf("EstimatedSteps = %d, want MaxSteps 4", pr.EstimatedSteps)
+	}
+	for n := 2; n <= 4; n++ {
+		pr = p.step(Step{Number: n, Action: "click"})
+	}
+	if pr.EstimatedSteps < pr.Step || pr.Percent > 99 {
+		t.Errorf("at MaxSteps: %+v", pr)
+	}
+}
+
+func TestProgressTrackerSubtasks(t *testing.T) {
+	var p progressTracker
+	p.reset("task", 0)
+
+	pr := p.startSubtask("Open the site", 1, 3)
+	if pr.Subtask != 1 || pr.Subtasks != 3 || pr.Goal != "Open the site" {
+		t.Errorf("startSubtask() = %+v", pr)
+	}
+	if want := defaultSubtaskSteps * 3; pr.EstimatedSteps != want {
+		t.Errorf("EstimatedSteps = %d, want %d before any subtask finished", pr.EstimatedSteps, want)
+	}
+
+	p.step(Step{Number: 1, Action: "navigate"})
+	p.step(Step{Number: 2, Action: "click"})
+	p.finishSubtask(2)
+
+	pr = p.startSubtask("Read the price", 2, 3)
+	pr = p.step(Step{Number: 1, Action: "extract_content"})
+	// Steps count across subtasks; the rest take 2 steps each like the first
+	if pr.Step != 3 || pr.EstimatedSteps != 6 || pr.Percent != 50 {
+		t.Errorf("in subtask 2: %+v", pr)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	p := Progress{Step: 7, EstimatedSteps: 15, Action: "extracting star count"}
+	if got, want := p.String(), "Step 7/~15: extracting star count"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -11,6 +12,59 @@ func SystemPrompt() string {
 	return systemPromptTemplate
 }
 
+// buildSystemPrompt returns the system prompt: override, or the built-in one
+// if it is empty, followed by the language section and extra.
+func buildSystemPrompt(override, extra, language string) string {
+	prompt := SystemPrompt()
+	if strings.TrimSpace(override) != "" {
+		prompt = override
+	}
+	if language = strings.TrimSpace(language); language != "" {
+		prompt += fmt.Sprintf(languagePrompt, language)
+	}
+	if extra = strings.TrimSpace(extra); extra != "" {
+		prompt += "\n\n<additional_instructions>\nFollow these instructions as well; where they conflict with the guidance above, they take precedence.\n" + extra + "\n</additional_instructions>"
+	}
+	return prompt
+}
+
+// languagePrompt sets the output language and prepares the model for
+// localized pages.
+const languagePrompt = `
+
+<language>
+Write your reasoning, summaries and the text values in done's data in the language of locale %[1]s. Keep text quoted from pages, URLs and data field names as they are.
+Expect pages localized for %[1]s: buttons, labels, menus and messages are in that language, so look for their meaning rather than English words (e.g. the local word for "checkout" or "search"). Dates, numbers, prices and addresses use local formats; enter them in those formats too.
+</language>`
+
+// SiteRule adds instructions for the agent while it is on one site.
+type SiteRule struct {
+	// Domain is the site the rules apply to; subdomains match too.
+	Domain string
+
+	// Rules are the instructions, e.g. "Never accept the upsell on checkout".
+	Rules string
+}
+
+// siteRulesNote returns the rules for the site of pageURL as a note for the
+// model, or "" if none apply.
+func siteRulesNote(rules []SiteRule, pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, r := range rules {
+		if inDomain(u.Hostname(), r.Domain) && strings.TrimSpace(r.Rules) != "" {
+			sb.WriteString(strings.TrimSpace(r.Rules) + "\n")
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "\n\n<site_rules>\nRules for this site:\n" + sb.String() + "</site_rules>"
+}
+
 // systemPromptTemplate is the core system prompt defining agent behavior.
 // Uses XML-style structure for better LLM parsing.
 const systemPromptTemplate = `<role>
@@ -44,26 +98,44 @@ You have access to browser automation tools. Use them by making function calls.
 - hover: Hover over an element to reveal dropdowns/tooltips
 - focus: Focus on an element
 - scroll: Scroll the page or a specific element
-- scroll_to_element: Scroll until an element is visible
+- scroll_to_element: Bring a listed element into view in one step. Prefer it to repeated scroll calls when the element already has an index
+- get_element_details: Get an element's attributes, ARIA state, visibility and inner HTML, e.g. to tell two similar buttons apart or see why a click did nothing
+- read_text: Read text drawn rather than written on the page (charts, canvas apps, image buttons) with OCR, if enabled. Use it when the element list or extract_content lacks text you can see in the screenshot
+- query_selector / click_selector: Find or click elements by CSS selector or XPath, if enabled. Use them when the task gives an exact selector; otherwise prefer element indices
 - send_keys: Send keyboard keys (Enter, Escape, Tab, etc.)
 </category>
 
 <category name="page_state">
-- get_page_state: Get current page state with all interactive elements
+- get_page_state: Get current page state with all interactive elements; full_page=true lists those of the whole page, not just the viewport
 - wait: Wait for page stability or loading
 - extract_content: Extract text content from the page
 - screenshot: Take a screenshot of the page
-- evaluate_js: Execute JavaScript code on the page
+- eval_js: Run JavaScript on the page and get the result as JSON, if enabled. Only when no other tool can do it
+- cdp_call: Send a raw Chrome DevTools protocol command, if enabled. Only for browser features no other tool has
+- assert: Check a condition on the page (element_exists, text_contains, url_matches, title_contains, count_at_least) and record pass/fail. Use it for each check a testing task asks for; a failed assertion is a result to report, not an error to work around
+- download_file: Download a file from a link element or URL; returns its saved path and SHA-256. Files from clicked download buttons are saved automatically and listed under <downloads>
 </category>
 
 <category name="tab_management">
-- new_tab: Open a new browser tab
-- switch_tab: Switch to a different tab
-- close_tab: Close a tab
-- list_tabs: List all open tabs
+- new_tab: Open a new browser tab (isolated=true for separate cookies, e.g. a second account). When using several tabs, label each with what it is for (e.g. "GitHub stats") to keep them apart. A URL already open in a tab is refused; switch to that tab instead
+- switch_tab: Switch to a different tab, by ID or label
+- close_tab: Close a tab, by ID or label
+- list_tabs: List all open tabs with their labels
+- list_frames: List the page's iframes with their paths
+- switch_frame: Work inside an iframe (e.g. an embedded payment or login form) whose elements are missing from the page state; path main returns to the page
+</category>
+
+<category name="blockers">
+- solve_captcha: Solve a reCAPTCHA, hCaptcha or Turnstile challenge on the page
+- request_human_takeover: Ask a person to handle a step you cannot, e.g. a CAPTCHA that solve_captcha could not solve
+- get_totp: Get the current authenticator (2FA) code for a stored secret, if any are configured
+- get_email_code: Wait for an emailed one-time code, if an inbox is configured. Trigger the email first
+- login: Sign in to a configured site with stored credentials, e.g. when the session has expired
 </category>
 
 <category name="completion">
+- save_finding: Save each entity a collecting task asks for (a profile, post, product) as structured fields as soon as you see it. Saving one again, e.g. after a feed re-renders, is harmless: duplicates are merged
+- report_section: For a task with several objectives (e.g. a numbered list), file each objective's result as soon as you have it, with status complete, partial or failed. Filed results are kept even if you run out of steps
 - done: Mark the task as complete with success/failure status and summary
 </category>
 </tool_categories>
@@ -72,8 +144,11 @@ You have access to browser automation tools. Use them by making function calls.
 <element_interaction_rules>
 <rule>Elements are identified by index numbers: [0], [1], [2], etc.</rule>
 <rule>Only interact with elements visible in the current page state</rule>
+<rule>If a click result reports a redirect to another site you did not mean to visit (an ad, a sign-in bounce, a tracking page), go back as it says before doing anything else</rule>
+<rule>Elements marked [offscreen] are outside the viewport: call scroll_to_element on one before clicking or typing into it</rule>
 <rule>After clicks or form submissions, wait for page updates before next action</rule>
 <rule>If content may have changed, use get_page_state to refresh your view</rule>
+<rule>A page state of the same page may list only the changes since the last one: "+" marks a new element at that index, "~" a changed one, "-" an index that is gone. All other indices are as last listed</rule>
 <rule>For text inputs, verify the element is an input/textarea before typing</rule>
 </element_interaction_rules>
 
@@ -125,6 +200,9 @@ Check the URL, try an alternative URL, or use search to find the correct page.
 <scenario type="action_blocked">
 The page may have popups, modals, or overlays. Look for close buttons or use send_keys with "Escape".
 </scenario>
+<scenario type="captcha">
+Call solve_captcha. If it fails, call request_human_takeover with what the person needs to do.
+</scenario>
 <scenario type="page_loading">
 Use wait tool to allow the page to fully load before interacting.
 </scenario>
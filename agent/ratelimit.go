@@ -0,0 +1,18 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+)
+
+// waitRateLimit holds a navigation to url until its host's rate limit
+// allows it.
+func (t *BrowserToolkit) waitRateLimit(ctx context.Context, url string) error {
+	if t.rateLimit == nil || url == "" {
+		return nil
+	}
+	if err := t.rateLimit.Wait(ctx, url); err != nil {
+		return fmt.Errorf("rate limit wait interrupted: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Redirect reports that an action took the browser to another site, e.g. an
+// ad, a single sign-on bounce or a tracking interstitial.
+type Redirect struct {
+	From string `json:"from_url"`
+	To   string `json:"to_url"`
+
+	// NewTab is set if the other site opened in a new tab, which is now
+	// the active one.
+	NewTab bool `json:"new_tab,omitempty"`
+}
+
+// pageMark is where the browser was before an action.
+type pageMark struct {
+	url string
+	tab string
+}
+
+// markPage records the active tab and its URL, to compare with after an
+// action.
+func (t *BrowserToolkit) markPage() pageMark {
+	return pageMark{url: t.browser.GetURL(), tab: t.activeTab()}
+}
+
+// activeTab returns the ID of the active tab, or "" if there is none.
+func (t *BrowserToolkit) activeTab() string {
+	for _, tab := range t.browser.ListTabs() {
+		if tab.Active {
+			return tab.ID
+		}
+	}
+	return ""
+}
+
+// redirectSince returns the Redirect if the browser left the site of before,
+// or nil if it is still on it. Pages without a site, such as about:blank,
+// are not counted either way.
+func (t *BrowserToolkit) redirectSince(before pageMark) *Redirect {
+	after := t.browser.GetURL()
+	from, to := siteOf(before.url), siteOf(after)
+	if from == "" || to == "" || from == to {
+		return nil
+	}
+	return &Redirect{From: before.url, To: after, NewTab: t.activeTab() != before.tab}
+}
+
+// note returns a sentence on the redirect for a tool result message,
+// saying how to get back.
+func (r *Redirect) note() string {
+	back := "call go_back to return"
+	if r.NewTab {
+		back = "it opened in a new tab; call close_tab to return"
+	}
+	return fmt.Sprintf(". This left %s for %s; if that was not intended (an ad, a sign-in bounce or a tracking page), %s",
+		siteOf(r.From), siteOf(r.To), back)
+}
+
+// siteOf returns the registrable domain of rawURL, e.g. "example.co.uk" for
+// "https://shop.example.co.uk/cart", or "" for URLs without a host.
+func siteOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	site, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// IP addresses and single-label hosts such as localhost
+		return host
+	}
+	return site
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"slices"
+	"time"
+
+	"google.golang.org/adk/model"
+)
+
+// RetryPolicy retries the transient failures of a single step — a model call
+// or a tool call — so they don't fail or restart the whole task.
+type RetryPolicy struct {
+	// MaxAttempts is the number of tries per call, including the first.
+	// 1 disables retries. Default: 3.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. Default: 1s.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Default: 30s.
+	MaxBackoff time.Duration
+
+	// Multiplier grows the wait after each retry. Default: 2.
+	Multiplier float64
+
+	// RetryOn are the failures that are retried. Default: rate_limited,
+	// model_unavailable and navigation_timeout.
+	RetryOn []ErrorCode
+}
+
+// defaultRetryOn are the failures retried when RetryPolicy.RetryOn is empty.
+var defaultRetryOn = []ErrorCode{ErrorCodeRateLimited, ErrorCodeModelUnavailable, ErrorCodeNavigationTimeout}
+
+// withDefaults returns the policy with zero fields set to their defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if len(p.RetryOn) == 0 {
+		p.RetryOn = defaultRetryOn
+	}
+	return p
+}
+
+// retries reports whether a failure with code is retried.
+func (p RetryPolicy) retries(code ErrorCode) bool {
+	return code != "" && slices.Contains(p.RetryOn, code)
+}
+
+// backoff returns the wait before retry number n (1-based).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < n; i++ {
+		d *= p.Multiplier
+	}
+	return min(time.Duration(d), p.MaxBackoff)
+}
+
+// wait sleeps before retry number n, returning false if ctx ends first.
+func (p RetryPolicy) wait(ctx context.Context, n int) bool {
+	timer := time.NewTimer(p.backoff(n))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retryModel retries model calls that fail before producing a response.
+type retryModel struct {
+	model.LLM
+	policy RetryPolicy
+	log    *slog.Logger
+}
+
+// GenerateContent implements model.LLM.
+func (m *retryModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for attempt := 1; ; attempt++ {
+			started := false
+			var failed error
+			for resp, err := range m.LLM.GenerateContent(ctx, req, stream) {
+				if err != nil && !started {
+					failed = err
+					break
+				}
+				started = true
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if failed == nil {
+				return
+			}
+
+			coded := modelError(failed)
+			if coded == nil || !m.policy.retries(coded.Code) || attempt >= m.policy.MaxAttempts {
+				yield(nil, failed)
+				return
+			}
+			m.log.Warn("Model call failed, retrying", "code", coded.Code, "attempt", attempt, "delay", m.policy.backoff(attempt), "err", failed)
+			if !m.policy.wait(ctx, attempt) {
+				yield(nil, failed)
+				return
+			}
+		}
+	}
+}
+
+// retryMiddleware reruns tool calls that fail with a retryable code.
+func retryMiddleware(policy RetryPolicy, log *slog.Logger) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call ToolCall) (map[string]any, error) {
+			for attempt := 1; ; attempt++ {
+				result, err := next(ctx, call)
+				if err != nil || attempt >= policy.MaxAttempts {
+					return result, err
+				}
+				code, _ := result["error_code"].(string)
+				if success, _ := result["success"].(bool); success || !policy.retries(ErrorCode(code)) {
+					return result, nil
+				}
+				log.Warn("Tool call failed, retrying", "tool", call.Name, "code", code, "attempt", attempt, "delay", policy.backoff(attempt))
+				if !policy.wait(ctx, attempt) {
+					return result, nil
+				}
+			}
+		}
+	}
+}
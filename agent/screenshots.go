@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/dom"
+)
+
+const (
+	// screenshotWorkers is how many screenshots are annotated and written
+	// at once.
+	screenshotWorkers = 2
+
+	// screenshotQueueSize bounds the screenshots waiting to be written;
+	// once it is full, taking a screenshot waits for the queue.
+	screenshotQueueSize = 16
+)
+
+// screenshotJob is a screenshot to write to path, annotated first if
+// elementMap is set.
+type screenshotJob struct {
+	path       string
+	data       []byte
+	elementMap *dom.ElementMap
+}
+
+// screenshotWriter annotates and writes screenshots in the background, so
+// disk I/O and image encoding stay off the agent loop.
+type screenshotWriter struct {
+	browser browser.Interface
+	logger  *slog.Logger
+
+	start   sync.Once
+	stop    sync.Once
+	jobs    chan screenshotJob
+	pending sync.WaitGroup
+}
+
+// newScreenshotWriter returns a writer that annotates with b.
+func newScreenshotWriter(b browser.Interface, logger *slog.Logger) *screenshotWriter {
+	return &screenshotWriter{browser: b, logger: logger, jobs: make(chan screenshotJob, screenshotQueueSize)}
+}
+
+// write queues a screenshot, starting the workers on first use.
+func (w *screenshotWriter) write(job screenshotJob) {
+	w.start.Do(func() {
+		for range screenshotWorkers {
+			go w.work()
+		}
+	})
+	w.pending.Add(1)
+	w.jobs <- job
+}
+
+// work writes queued screenshots until the writer is closed.
+func (w *screenshotWriter) work() {
+	for job := range w.jobs {
+		w.save(job)
+		w.pending.Done()
+	}
+}
+
+// save annotates and writes one screenshot. Failures are logged: the
+// screenshot is a record of the run, not something the run needs.
+func (w *screenshotWriter) save(job screenshotJob) {
+	data := job.data
+	if job.elementMap != nil {
+		annotated, err := w.browser.AnnotateScreenshot(data, job.elementMap)
+		if err != nil {
+			w.logger.Warn("Failed to annotate screenshot", "path", job.path, "err", err)
+		} else {
+			data = annotated
+		}
+	}
+	if err := os.WriteFile(job.path, data, 0644); err != nil {
+		w.logger.Warn("Failed to save screenshot", "path", job.path, "err", err)
+	}
+}
+
+// flush waits until every queued screenshot is written.
+func (w *screenshotWriter) flush() {
+	w.pending.Wait()
+}
+
+// close flushes the writer and stops its workers. It must not be used
+// afterwards.
+func (w *screenshotWriter) close() {
+	w.flush()
+	w.stop.Do(func() { close(w.jobs) })
+}
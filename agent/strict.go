@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/anxuanzi/bua/normalize"
+)
+
+// CompletionReason says how a run ended.
+type CompletionReason string
+
+const (
+	// CompletionDone means the agent called done, successfully or not.
+	CompletionDone CompletionReason = "done"
+
+	// CompletionBudget means the run used up its steps, tokens or cost.
+	CompletionBudget CompletionReason = "budget"
+
+	// CompletionTimeout means the run's context deadline passed.
+	CompletionTimeout CompletionReason = "timeout"
+
+	// CompletionError means the run was aborted: by repeated failures, a
+	// model refusal or a site blocking it.
+	CompletionError CompletionReason = "error"
+
+	// CompletionTakeover means the agent gave up after a person was needed
+	// but unavailable, or did not finish in time.
+	CompletionTakeover CompletionReason = "takeover"
+)
+
+// completionReason returns the reason for a run that ended with code
+// without a done call.
+func completionReason(code ErrorCode) CompletionReason {
+	switch code {
+	case ErrorCodeStepBudget, ErrorCodeTokenBudget, ErrorCodeCostBudget:
+		return CompletionBudget
+	case ErrorCodeTimeout:
+		return CompletionTimeout
+	case ErrorCodeHumanTakeover:
+		return CompletionTakeover
+	}
+	return CompletionError
+}
+
+// maxSchemaRejections bounds how often done data that does not match
+// RunOptions.Schema sends the agent back to work in one run.
+const maxSchemaRejections = 2
+
+// dataSchema is a run's RunOptions.Schema, ready to validate with.
+type dataSchema struct {
+	raw      map[string]any
+	resolved *jsonschema.Resolved
+	text     string // indented JSON, for the model
+}
+
+// newDataSchema resolves schema, returning nil for an empty one.
+func newDataSchema(schema map[string]any) (*dataSchema, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+	raw, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("invalid result schema: %w", err)
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid result schema: %w", err)
+	}
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result schema: %w", err)
+	}
+	return &dataSchema{raw: schema, resolved: resolved, text: string(raw)}, nil
+}
+
+// normalize cleans the values of data the schema types as numbers, dates or
+// amounts.
+func (s *dataSchema) normalize(data any) any {
+	// Round-trip through JSON, so data holds the types normalize walks
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return data
+	}
+	return normalize.Data(v, s.raw, time.Now())
+}
+
+// validate checks done data against the schema.
+func (s *dataSchema) validate(data any) error {
+	// Round-trip through JSON, so data holds only the types the validator knows
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	return s.resolved.Validate(v)
+}
+
+// note tells the model what its data must look like, for the task message.
+func (s *dataSchema) note() string {
+	return "\n\n<result_schema>\nWhen finished, call done with data that matches this JSON Schema:\n" + s.text + "\n</result_schema>"
+}
+
+// feedback tells the model its done data did not match, and why.
+func (s *dataSchema) feedback(err error) string {
+	return fmt.Sprintf("\n\n<schema_mismatch>\nThe data of your done call does not match the result schema: %v\nCall done again with data that matches it.\n</schema_mismatch>", err)
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SummaryFormat is the format of a run's summary; see
+// RunOptions.SummaryFormat.
+type SummaryFormat string
+
+const (
+	// SummaryMarkdown is Markdown: a short paragraph and bullet points.
+	SummaryMarkdown SummaryFormat = "markdown"
+
+	// SummaryPlain is plain text, without Markdown.
+	SummaryPlain SummaryFormat = "plain"
+
+	// SummaryJSON is a JSON object. Summaries the model did not write as
+	// JSON become {"summary": "..."}.
+	SummaryJSON SummaryFormat = "json"
+)
+
+// summaryFormatNote tells the model how to write done's summary.
+func summaryFormatNote(format SummaryFormat) string {
+	var how string
+	switch format {
+	case SummaryMarkdown:
+		how = "in Markdown: one short paragraph on the outcome, then a bullet point per finding"
+	case SummaryPlain:
+		how = "as plain text, without Markdown, headings or bullet symbols"
+	case SummaryJSON:
+		how = `as a single JSON object, e.g. {"outcome": "...", "findings": ["..."]}, with nothing around it`
+	default:
+		return ""
+	}
+	return fmt.Sprintf("\n\n<summary_format>\nWrite the summary of your done call %s.\n</summary_format>", how)
+}
+
+// checkSummaryFormat returns an error for an unknown format.
+func checkSummaryFormat(format SummaryFormat) error {
+	switch format {
+	case "", SummaryMarkdown, SummaryPlain, SummaryJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown summary format %q", format)
+}
+
+var (
+	mdFence     = regexp.MustCompile("(?m)^[ \\t]*```[a-zA-Z]*[ \\t]*$\\n?")
+	mdHeading   = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	mdQuote     = regexp.MustCompile(`(?m)^>\s?`)
+	mdBullet    = regexp.MustCompile(`(?m)^(\s*)[*+]\s+`)
+	mdLink      = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdBold      = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	mdItalic    = regexp.MustCompile(`\*(\S[^*\n]*?)\*`)
+	mdCode      = regexp.MustCompile("`([^`\n]+)`")
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+	jsonSummary = regexp.MustCompile("(?s)^```(?:json)?\\s*(.*?)\\s*```$")
+)
+
+// FormatSummary normalizes a summary written by the model to format, so
+// callers can rely on it whatever the model did: plain text loses its
+// Markdown, and JSON is unwrapped from code fences or, if it is not JSON,
+// wrapped in an object. An empty format leaves the summary as it is.
+func FormatSummary(summary string, format SummaryFormat) string {
+	summary = strings.TrimSpace(summary)
+	switch format {
+	case SummaryPlain:
+		return stripMarkdown(summary)
+	case SummaryJSON:
+		if m := jsonSummary.FindStringSubmatch(summary); m != nil {
+			summary = m[1]
+		}
+		if json.Valid([]byte(summary)) && strings.HasPrefix(summary, "{") {
+			return summary
+		}
+		b, _ := json.Marshal(map[string]string{"summary": stripMarkdown(summary)})
+		return string(b)
+	}
+	return summary
+}
+
+// stripMarkdown turns Markdown into plain text.
+func stripMarkdown(s string) string {
+	s = mdFence.ReplaceAllString(s, "")
+	s = mdHeading.ReplaceAllString(s, "")
+	s = mdQuote.ReplaceAllString(s, "")
+	s = mdBullet.ReplaceAllString(s, "$1- ")
+	s = mdLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdLink.FindStringSubmatch(m)
+		if sub[1] == "" || sub[1] == sub[2] {
+			return sub[2]
+		}
+		return sub[1] + " (" + sub[2] + ")"
+	})
+	s = mdBold.ReplaceAllString(s, "$2")
+	s = mdItalic.ReplaceAllString(s, "$1")
+	s = mdCode.ReplaceAllString(s, "$1")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// TabState is the state of one tab at the end of a run, for comparing what
+// several tabs show.
+type TabState struct {
+	TabID  string `json:"tab_id"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Active bool   `json:"active"`
+
+	// Elements lists the tab's interactive elements as the model sees them.
+	Elements string `json:"elements,omitempty"`
+
+	// ScreenshotPath is where the tab's screenshot was saved, if a
+	// screenshot directory is configured.
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+
+	// Error says what could not be captured, if anything.
+	Error string `json:"error,omitempty"`
+}
+
+// captureTabs captures the state of every open tab, for RunOptions.CaptureTabs.
+func (a *BrowserAgent) captureTabs(ctx context.Context) []TabState {
+	snapshots, err := a.browser.CaptureAllTabs(ctx)
+	if err != nil {
+		a.log("Agent").Warn("Failed to capture tabs", "err", err)
+		return nil
+	}
+
+	states := make([]TabState, len(snapshots))
+	for i, s := range snapshots {
+		states[i] = TabState{TabID: s.TabID, URL: s.URL, Title: s.Title, Active: s.Active, Error: s.Error}
+		if s.ElementMap != nil {
+			states[i].Elements = s.ElementMap.ToTokenStringLimited(a.messageManager.maxElements)
+		}
+		if a.screenshotDir != "" && len(s.Screenshot) > 0 {
+			path := filepath.Join(a.screenshotDir, fmt.Sprintf("tab_%s_%d.jpg", s.TabID, time.Now().UnixMilli()))
+			a.screenshots.write(screenshotJob{path: path, data: s.Screenshot})
+			a.screenshotPaths = append(a.screenshotPaths, path)
+			states[i].ScreenshotPath = path
+		}
+	}
+	return states
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// RunOptions adjust a single run.
+type RunOptions struct {
+	// EnabledTools limits the run to these tools (empty = all tools). done is
+	// always enabled.
+	EnabledTools []string
+
+	// DisabledTools removes these tools from the run.
+	DisabledTools []string
+
+	// MaxTokens ends the run once it has used this many tokens (0 = no
+	// limit). The agent gets one last turn, with only done available, to
+	// return what it has so far.
+	MaxTokens int
+
+	// Generation overrides the agent's generation settings for the run.
+	// Unset fields keep the agent's.
+	Generation GenerationConfig
+
+	// ExampleCategory shows the agent the examples of this category ("" =
+	// none). It is an error if the agent has none.
+	ExampleCategory string
+
+	// Schema is a JSON Schema the data of the agent's done call must match.
+	// The agent is shown it, and sent back to work when its data does not
+	// match; with AgentConfig.StrictSuccess, data that still does not match
+	// fails the run.
+	Schema map[string]any
+
+	// CaptureTabs captures every open tab when the run ends, into
+	// Result.Tabs, e.g. to compare prices the agent opened on several sites.
+	CaptureTabs bool
+
+	// SummaryFormat asks the agent to write done's summary as Markdown,
+	// plain text or JSON, and normalizes Result.Summary to it ("" = as the
+	// agent writes it).
+	SummaryFormat SummaryFormat
+
+	// Normalize cleans the done data where Schema types it, before it is
+	// validated: "45.2k" in a number field becomes 45200, "3 hours ago" in a
+	// date-time field an RFC 3339 timestamp, and "$12.99" in an object with
+	// amount and currency properties {"amount": 12.99, "currency": "USD"}.
+	// See package normalize.
+	Normalize bool
+
+	// FindingKeys are the fields of a save_finding entity that identify it,
+	// e.g. "username" or "url", so saving it again, as re-scrolled feeds
+	// do, merges into one Result finding. Without keys, only identical
+	// findings are merged.
+	FindingKeys []string
+
+	// FindingMerge says how the saves of one entity are merged ("" =
+	// MergeFields).
+	FindingMerge MergeStrategy
+}
+
+// toolFilter hides disabled tools from the model and refuses to run them.
+type toolFilter struct {
+	// names are all the agent's tools
+	names map[string]bool
+
+	mu       sync.Mutex
+	disabled map[string]bool
+}
+
+func newToolFilter(tools []tool.Tool) *toolFilter {
+	names := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		names[t.Name()] = true
+	}
+	return &toolFilter{names: names}
+}
+
+// set applies a run's options, rejecting unknown tool names.
+func (f *toolFilter) set(opts RunOptions) error {
+	for _, name := range slices.Concat(opts.EnabledTools, opts.DisabledTools) {
+		if !f.names[name] {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+	}
+	if slices.Contains(opts.DisabledTools, "done") {
+		return fmt.Errorf("the done tool cannot be disabled")
+	}
+
+	disabled := make(map[string]bool)
+	if len(opts.EnabledTools) > 0 {
+		for name := range f.names {
+			if name != "done" && !slices.Contains(opts.EnabledTools, name) {
+				disabled[name] = true
+			}
+		}
+	}
+	for _, name := range opts.DisabledTools {
+		disabled[name] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.disabled = disabled
+	return nil
+}
+
+// only disables every tool but the named ones, until restore is called.
+func (f *toolFilter) only(names ...string) (restore func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	saved := f.disabled
+	restore = func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.disabled = saved
+	}
+	f.disabled = make(map[string]bool)
+	for name := range f.names {
+		if !slices.Contains(names, name) {
+			f.disabled[name] = true
+		}
+	}
+	return restore
+}
+
+// beforeModel removes the declarations of disabled tools from the request.
+// The tools stay registered, so a call the model makes anyway is refused by
+// beforeTool rather than failing the run.
+func (f *toolFilter) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.disabled) == 0 || req.Config == nil {
+		return nil, nil
+	}
+	for _, t := range req.Config.Tools {
+		if t == nil || t.FunctionDeclarations == nil {
+			continue
+		}
+		decls := t.FunctionDeclarations[:0:0]
+		for _, d := range t.FunctionDeclarations {
+			if !f.disabled[d.Name] {
+				decls = append(decls, d)
+			}
+		}
+		t.FunctionDeclarations = decls
+	}
+	return nil, nil
+}
+
+// beforeTool refuses to run disabled tools.
+func (f *toolFilter) beforeTool(ctx tool.Context, tl tool.Tool, args map[string]any) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.disabled[tl.Name()] {
+		return map[string]any{
+			"success": false,
+			"message": fmt.Sprintf("%s is disabled for this task", tl.Name()),
+		}, nil
+	}
+	return nil, nil
+}
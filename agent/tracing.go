@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// tracerName is the instrumentation scope of the agent's spans.
+const tracerName = "github.com/anxuanzi/bua/agent"
+
+// traceContextSetter is implemented by browsers that trace their
+// operations, such as *browser.Browser. Tool spans are made their parents.
+type traceContextSetter interface {
+	SetTraceContext(ctx context.Context)
+}
+
+// runTracer traces runs, model calls and tool calls, and counts the tokens
+// the model reports along the way.
+type runTracer struct {
+	tracer  trace.Tracer
+	browser browser.Interface
+	model   string
+
+	mu        sync.Mutex
+	runCtx    context.Context
+	modelSpan trace.Span
+	toolSpan  trace.Span
+	usage     TokenUsage
+}
+
+func newRunTracer(tp trace.TracerProvider, b browser.Interface, modelName string) *runTracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &runTracer{tracer: tp.Tracer(tracerName), browser: b, model: modelName}
+}
+
+// startRun starts the span of a run. end finishes it with the outcome.
+func (t *runTracer) startRun(ctx context.Context, task, architecture string) (context.Context, func(*Result, error)) {
+	ctx, span := t.tracer.Start(ctx, "bua.run", trace.WithAttributes(
+		attribute.String("gen_ai.request.model", t.model),
+		attribute.String("bua.architecture", architecture),
+		attribute.Int("bua.task_length", len(task)),
+	))
+	t.mu.Lock()
+	t.runCtx = ctx
+	t.mu.Unlock()
+	t.setBrowserContext(ctx)
+
+	return ctx, func(result *Result, err error) {
+		t.setBrowserContext(nil)
+		if span.IsRecording() {
+			span.SetAttributes(attribute.String("url.full", t.browser.GetURL()))
+		}
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil:
+			span.SetAttributes(
+				attribute.Bool("bua.success", result.Success),
+				attribute.Int("bua.steps", len(result.Steps)),
+				attribute.Int("bua.tokens", result.TokensUsed),
+			)
+			if !result.Success {
+				span.SetStatus(codes.Error, result.Error)
+			}
+		}
+		span.End()
+	}
+}
+
+// tokenUsage returns the tokens the model has reported so far.
+func (t *runTracer) tokenUsage() TokenUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.usage
+}
+
+// beforeModel starts the span of a model call.
+func (t *runTracer) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	_, span := t.tracer.Start(ctx, "bua.model", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("gen_ai.request.model", t.model)))
+
+	t.mu.Lock()
+	t.modelSpan = span
+	t.mu.Unlock()
+	return nil, nil
+}
+
+// afterModel ends the span of a model call with its token usage.
+func (t *runTracer) afterModel(ctx agent.CallbackContext, resp *model.LLMResponse, respErr error) (*model.LLMResponse, error) {
+	t.mu.Lock()
+	span := t.modelSpan
+	t.modelSpan = nil
+	if resp != nil {
+		t.usage = t.usage.add(usageFrom(resp.UsageMetadata))
+	}
+	t.mu.Unlock()
+
+	if span == nil {
+		return nil, nil
+	}
+	if resp != nil && resp.UsageMetadata != nil {
+		usage := usageFrom(resp.UsageMetadata)
+		span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", usage.InputTokens),
+			attribute.Int("gen_ai.usage.output_tokens", usage.OutputTokens),
+			attribute.Int("gen_ai.usage.thinking_tokens", usage.ThinkingTokens),
+			attribute.Int("bua.tokens", usage.TotalTokens),
+			attribute.Int("gen_ai.usage.cached_tokens", usage.CachedTokens),
+		)
+	}
+	if respErr != nil {
+		span.RecordError(respErr)
+		span.SetStatus(codes.Error, respErr.Error())
+	}
+	span.End()
+	return nil, nil
+}
+
+// beforeTool starts the span of a tool call and makes it the parent of the
+// browser operations the tool performs.
+func (t *runTracer) beforeTool(ctx tool.Context, tl tool.Tool, args map[string]any) (map[string]any, error) {
+	spanCtx, span := t.tracer.Start(ctx, "bua.tool "+tl.Name(),
+		trace.WithAttributes(attribute.String("bua.tool", tl.Name())))
+	if index, ok := args["element_index"].(float64); ok {
+		span.SetAttributes(attribute.Int("bua.element_index", int(index)))
+	}
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("url.full", t.browser.GetURL()))
+	}
+
+	t.mu.Lock()
+	t.toolSpan = span
+	t.mu.Unlock()
+	t.setBrowserContext(spanCtx)
+	return nil, nil
+}
+
+// afterTool ends the span of a tool call with its outcome.
+func (t *runTracer) afterTool(ctx tool.Context, tl tool.Tool, args, result map[string]any, err error) (map[string]any, error) {
+	t.mu.Lock()
+	span := t.toolSpan
+	t.toolSpan = nil
+	runCtx := t.runCtx
+	t.mu.Unlock()
+	t.setBrowserContext(runCtx)
+
+	if span == nil {
+		return nil, nil
+	}
+	switch success, ok := result["success"].(bool); {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case ok:
+		span.SetAttributes(attribute.Bool("bua.success", success))
+		if !success {
+			msg, _ := result["message"].(string)
+			span.SetStatus(codes.Error, msg)
+		}
+	}
+	span.End()
+	return nil, nil
+}
+
+// setBrowserContext sets the parent of the browser's operation spans.
+func (t *runTracer) setBrowserContext(ctx context.Context) {
+	if s, ok := t.browser.(traceContextSetter); ok {
+		s.SetTraceContext(ctx)
+	}
+}
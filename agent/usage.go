@@ -0,0 +1,67 @@
+package agent
+
+import "google.golang.org/genai"
+
+// TokenUsage counts the tokens of model calls, as reported by the API in the
+// usage metadata of each response.
+type TokenUsage struct {
+	// InputTokens are the prompt tokens, including those served from a cache.
+	InputTokens int `json:"input_tokens"`
+
+	// OutputTokens are the tokens of the model's answers.
+	OutputTokens int `json:"output_tokens"`
+
+	// ThinkingTokens are the tokens the model spent reasoning, for models
+	// that think. They are billed as output but not part of OutputTokens.
+	ThinkingTokens int `json:"thinking_tokens,omitempty"`
+
+	// CachedTokens are the input tokens served from a cache.
+	CachedTokens int `json:"cached_tokens,omitempty"`
+
+	// TotalTokens is the total the API reports for the calls.
+	TotalTokens int `json:"total_tokens"`
+}
+
+// usageFrom reads the token counts of one model response.
+func usageFrom(m *genai.GenerateContentResponseUsageMetadata) TokenUsage {
+	if m == nil {
+		return TokenUsage{}
+	}
+	return TokenUsage{
+		InputTokens:    int(m.PromptTokenCount + m.ToolUsePromptTokenCount),
+		OutputTokens:   int(m.CandidatesTokenCount),
+		ThinkingTokens: int(m.ThoughtsTokenCount),
+		CachedTokens:   int(m.CachedContentTokenCount),
+		TotalTokens:    int(m.TotalTokenCount),
+	}
+}
+
+// add returns the sum of u and v.
+func (u TokenUsage) add(v TokenUsage) TokenUsage {
+	return TokenUsage{
+		InputTokens:    u.InputTokens + v.InputTokens,
+		OutputTokens:   u.OutputTokens + v.OutputTokens,
+		ThinkingTokens: u.ThinkingTokens + v.ThinkingTokens,
+		CachedTokens:   u.CachedTokens + v.CachedTokens,
+		TotalTokens:    u.TotalTokens + v.TotalTokens,
+	}
+}
+
+// sub returns the usage in u since v, an earlier reading of the same counter.
+func (u TokenUsage) sub(v TokenUsage) TokenUsage {
+	return TokenUsage{
+		InputTokens:    u.InputTokens - v.InputTokens,
+		OutputTokens:   u.OutputTokens - v.OutputTokens,
+		ThinkingTokens: u.ThinkingTokens - v.ThinkingTokens,
+		CachedTokens:   u.CachedTokens - v.CachedTokens,
+		TotalTokens:    u.TotalTokens - v.TotalTokens,
+	}
+}
+
+// setUsage records the tokens a run used, keeping the summary fields in step.
+func (r *Result) setUsage(u TokenUsage) *Result {
+	r.Usage = u
+	r.TokensUsed = u.TotalTokens
+	r.CachedTokens = u.CachedTokens
+	return r
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// visitKey normalizes a URL for comparing visits: without its fragment or a
+// trailing slash, with scheme and host in lower case. Non-URLs are
+// returned trimmed.
+func visitKey(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Fragment, u.RawFragment = "", ""
+	u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// visit records that the run was on rawURL.
+func (t *BrowserToolkit) visit(rawURL string) {
+	if rawURL == "" || rawURL == "about:blank" {
+		return
+	}
+	if t.visited == nil {
+		t.visited = make(map[string]bool)
+	}
+	t.visited[visitKey(rawURL)] = true
+}
+
+// visitedBefore reports whether the run was already on rawURL.
+func (t *BrowserToolkit) visitedBefore(rawURL string) bool {
+	return t.visited[visitKey(rawURL)]
+}
+
+// openTabFor returns the tab that has rawURL open, if any.
+func (t *BrowserToolkit) openTabFor(rawURL string) (browser.TabInfo, bool) {
+	key := visitKey(rawURL)
+	for _, tab := range t.browser.ListTabs() {
+		if visitKey(tab.URL) == key {
+			return tab, true
+		}
+	}
+	return browser.TabInfo{}, false
+}
+
+// duplicateTabMessage refuses to open a second tab for the URL tab has open.
+func duplicateTabMessage(tab browser.TabInfo) string {
+	name := tab.ID
+	if tab.Label != "" {
+		name = fmt.Sprintf("%s (%q)", tab.ID, tab.Label)
+	}
+	if tab.Active {
+		return fmt.Sprintf("New tab refused: %s is already open in the current tab %s. Work with it instead of opening it again", tab.URL, name)
+	}
+	return fmt.Sprintf("New tab refused: %s is already open in tab %s. Call switch_tab to go back to it instead of opening it again", tab.URL, name)
+}
+
+// revisitNote warns about going back to a page visited earlier in the run,
+// for a tool result message.
+const revisitNote = ". You already visited this page earlier in this task; if you keep returning to the same pages, change your approach instead of repeating it"
@@ -0,0 +1,127 @@
+// Package artifact uploads files produced by a run — screenshots, session
+// recordings and downloads — to durable storage, so results from headless
+// fleet machines aren't lost with their ephemeral disks.
+//
+// A Sink stores one object and returns its URL. S3 and GCS talk to the
+// respective services; SinkFunc adapts any other implementation.
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Kind identifies what produced an artifact.
+type Kind string
+
+const (
+	// KindScreenshot is a screenshot taken during the run.
+	KindScreenshot Kind = "screenshot"
+
+	// KindRecording is the session recording.
+	KindRecording Kind = "recording"
+
+	// KindDownload is a file the agent downloaded.
+	KindDownload Kind = "download"
+)
+
+// Artifact is a local file and where it was uploaded.
+type Artifact struct {
+	Kind Kind   `json:"kind"`
+	Path string `json:"path"`
+	Key  string `json:"key"`
+	URL  string `json:"url,omitempty"`
+
+	// Error is set if the upload failed; Path is still valid.
+	Error string `json:"error,omitempty"`
+}
+
+// Sink stores objects and returns a URL for each.
+type Sink interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+
+// Put calls f.
+func (f SinkFunc) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	return f(ctx, key, body, size, contentType)
+}
+
+// UploadFile stores the file at path under key and returns its URL.
+func UploadFile(ctx context.Context, sink Sink, key, filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat artifact: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(filePath)))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return sink.Put(ctx, key, f, info.Size(), contentType)
+}
+
+// Upload stores each file under prefix/<kind>/<name> and returns the results.
+// Failed uploads are reported in Artifact.Error rather than stopping the rest.
+func Upload(ctx context.Context, sink Sink, prefix string, files map[Kind][]string) []Artifact {
+	var artifacts []Artifact
+	for _, kind := range []Kind{KindScreenshot, KindRecording, KindDownload} {
+		for _, p := range files[kind] {
+			if p == "" {
+				continue
+			}
+			a := Artifact{
+				Kind: kind,
+				Path: p,
+				Key:  path.Join(prefix, string(kind), filepath.Base(p)),
+			}
+			url, err := UploadFile(ctx, sink, a.Key, p)
+			if err != nil {
+				a.Error = err.Error()
+			} else {
+				a.URL = url
+			}
+			artifacts = append(artifacts, a)
+		}
+	}
+	return artifacts
+}
+
+// escapeKey percent-encodes each segment of an object key, keeping slashes.
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = uriEncode(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// uriEncode percent-encodes everything except RFC 3986 unreserved characters.
+func uriEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
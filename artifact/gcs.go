@@ -0,0 +1,83 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+)
+
+// gcsScope grants object write access.
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// GCS stores artifacts in a Google Cloud Storage bucket.
+type GCS struct {
+	// Bucket is the bucket name (required).
+	Bucket string
+
+	// Token returns an OAuth2 access token. Default: Application Default
+	// Credentials (GOOGLE_APPLICATION_CREDENTIALS, gcloud, or the metadata server).
+	Token func(ctx context.Context) (string, error)
+
+	// HTTPClient is used for uploads. Default: http.DefaultClient.
+	HTTPClient *http.Client
+
+	once  sync.Once
+	creds *auth.Credentials
+	err   error
+}
+
+// Put uploads body to key with the JSON API's media upload and returns the object URL.
+func (g *GCS) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if g.Bucket == "" {
+		return "", errors.New("gcs: bucket required")
+	}
+
+	token, err := g.token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gcs: failed to get access token: %w", err)
+	}
+
+	uploadURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		url.PathEscape(g.Bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	if err != nil {
+		return "", fmt.Errorf("gcs: failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := doUpload(g.HTTPClient, req); err != nil {
+		return "", fmt.Errorf("gcs: %w", err)
+	}
+	return "https://storage.googleapis.com/" + escapeKey(g.Bucket+"/"+key), nil
+}
+
+// token returns an access token from Token or Application Default Credentials.
+func (g *GCS) token(ctx context.Context) (string, error) {
+	if g.Token != nil {
+		return g.Token(ctx)
+	}
+
+	g.once.Do(func() {
+		g.creds, g.err = credentials.DetectDefault(&credentials.DetectOptions{
+			Scopes: []string{gcsScope},
+		})
+	})
+	if g.err != nil {
+		return "", g.err
+	}
+
+	tok, err := g.creds.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return tok.Value, nil
+}
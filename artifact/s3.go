@@ -0,0 +1,167 @@
+package artifact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3 stores artifacts in an Amazon S3 bucket, or any S3-compatible store
+// such as MinIO or Cloudflare R2 via Endpoint.
+type S3 struct {
+	// Bucket is the bucket name (required).
+	Bucket string
+
+	// Region is the bucket's region. Default: $AWS_REGION, then "us-east-1".
+	Region string
+
+	// Endpoint overrides the service URL, e.g. "https://<account>.r2.cloudflarestorage.com".
+	// Objects are then addressed path-style. Default: AWS.
+	Endpoint string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials.
+	// Default: $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// HTTPClient is used for uploads. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Put uploads body to key with a SigV4-signed PUT and returns the object URL.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if s.Bucket == "" {
+		return "", errors.New("s3: bucket required")
+	}
+
+	region := firstNonEmpty(s.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	accessKey := firstNonEmpty(s.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretKey := firstNonEmpty(s.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	sessionToken := s.SessionToken
+	if s.AccessKeyID == "" {
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if accessKey == "" || secretKey == "" {
+		return "", errors.New("s3: credentials required")
+	}
+
+	var host, uriPath string
+	scheme := "https"
+	if s.Endpoint != "" {
+		endpoint := strings.TrimSuffix(s.Endpoint, "/")
+		if i := strings.Index(endpoint, "://"); i >= 0 {
+			scheme, endpoint = endpoint[:i], endpoint[i+3:]
+		}
+		host = endpoint
+		uriPath = "/" + escapeKey(s.Bucket+"/"+key)
+	} else {
+		host = fmt.Sprintf("%s.s3.%s.amazonaws.com", s.Bucket, region)
+		uriPath = "/" + escapeKey(key)
+	}
+	objectURL := scheme + "://" + host + uriPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, body)
+	if err != nil {
+		return "", fmt.Errorf("s3: failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	// The body is streamed, so the payload itself is left out of the signature
+	headers := [][2]string{
+		{"host", host},
+		{"x-amz-content-sha256", "UNSIGNED-PAYLOAD"},
+		{"x-amz-date", amzDate},
+	}
+	if sessionToken != "" {
+		headers = append(headers, [2]string{"x-amz-security-token", sessionToken})
+	}
+
+	var canonicalHeaders strings.Builder
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		canonicalHeaders.WriteString(h[0] + ":" + h[1] + "\n")
+		names[i] = h[0]
+		if h[0] != "host" {
+			req.Header.Set(h[0], h[1])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPut,
+		uriPath,
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), day)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+
+	if err := doUpload(s.HTTPClient, req); err != nil {
+		return "", fmt.Errorf("s3: %w", err)
+	}
+	return objectURL, nil
+}
+
+// doUpload sends req and fails on any non-2xx status, including the
+// service's error message.
+func doUpload(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// hmacSHA256 returns HMAC-SHA256(key, data).
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
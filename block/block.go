@@ -0,0 +1,126 @@
+// Package block recognizes pages that turn automated visitors away: the bot
+// challenges and block pages of Cloudflare, Akamai, DataDome and PerimeterX,
+// generic access-denied and rate-limit pages, and login walls.
+//
+// Detect reads the page's DOM, title and the HTTP status of its document,
+// so it needs no network interception.
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// Kind identifies what blocked a page.
+type Kind string
+
+const (
+	// KindCloudflare is a Cloudflare bot challenge or block page.
+	KindCloudflare Kind = "cloudflare"
+
+	// KindAkamai is an Akamai "Access Denied" page.
+	KindAkamai Kind = "akamai"
+
+	// KindDataDome is a DataDome challenge.
+	KindDataDome Kind = "datadome"
+
+	// KindPerimeterX is a PerimeterX (HUMAN) "press and hold" challenge.
+	KindPerimeterX Kind = "perimeterx"
+
+	// KindRateLimited is a "too many requests" or "unusual traffic" page.
+	KindRateLimited Kind = "rate_limited"
+
+	// KindAccessDenied is any other 401 or 403 page that refuses the visitor.
+	KindAccessDenied Kind = "access_denied"
+
+	// KindLoginWall is a sign-in form standing between the visitor and the page.
+	KindLoginWall Kind = "login_wall"
+)
+
+// Block describes a page that blocked the visitor.
+type Block struct {
+	Kind Kind   `json:"kind"`
+	URL  string `json:"url"`
+
+	// Status is the HTTP status of the page's document (0 if unknown).
+	Status int `json:"status,omitempty"`
+
+	// Evidence is what gave the block away, e.g. `title "Just a moment..."`.
+	Evidence string `json:"evidence"`
+
+	// Challenge is set for interstitials that may clear by themselves after
+	// a few seconds, like Cloudflare's JavaScript challenge.
+	Challenge bool `json:"challenge,omitempty"`
+}
+
+// detectJS looks for block pages, vendor by vendor, then for generic ones.
+const detectJS = `() => {
+	const nav = performance.getEntriesByType('navigation')[0];
+	const status = (nav && nav.responseStatus) || 0;
+	const title = (document.title || '').trim();
+	const text = ((document.body && document.body.innerText) || '').slice(0, 5000);
+	const has = (selector) => !!document.querySelector(selector);
+	const visible = (el) => {
+		const r = el.getBoundingClientRect();
+		const s = getComputedStyle(el);
+		return r.width > 0 && r.height > 0 && s.visibility !== 'hidden' && s.display !== 'none';
+	};
+	const found = (kind, evidence, challenge) => JSON.stringify({
+		kind: kind, url: location.href, status: status, evidence: evidence, challenge: !!challenge
+	});
+
+	if (/^just a moment/i.test(title) || has('#challenge-running, #challenge-form, #cf-challenge-running') ||
+		(status >= 400 && has('script[src*="/cdn-cgi/challenge-platform/"]'))) {
+		return found('cloudflare', 'challenge page "' + title + '"', true);
+	}
+	if (has('#cf-error-details, .cf-error-details') || /attention required! \| cloudflare/i.test(title) ||
+		(status >= 400 && /cloudflare ray id/i.test(text))) {
+		return found('cloudflare', 'block page "' + title + '"', false);
+	}
+	if ((/^access denied$/i.test(title) && /you don't have permission to access/i.test(text)) || /errors\.edgesuite\.net/i.test(text)) {
+		return found('akamai', 'access denied page "' + title + '"', false);
+	}
+	if (has('iframe[src*="captcha-delivery.com"]')) {
+		return found('datadome', 'captcha-delivery.com challenge', false);
+	}
+	if (has('#px-captcha') || (/press (&|and) hold/i.test(text) && has('[id^="px-"]'))) {
+		return found('perimeterx', 'press and hold challenge', false);
+	}
+
+	const short = text.length < 1500;
+	if ((location.pathname.startsWith('/sorry/') && /unusual traffic/i.test(text)) ||
+		((status === 429 || /too many requests/i.test(title)) && short)) {
+		return found('rate_limited', 'HTTP ' + status + ' "' + title + '"', false);
+	}
+	if ((status === 401 || status === 403) && short &&
+		/access denied|forbidden|blocked|not authori[sz]ed|are you a (human|robot)|automated/i.test(title + ' ' + text)) {
+		return found('access_denied', 'HTTP ' + status + ' "' + title + '"', false);
+	}
+
+	const password = [...document.querySelectorAll('input[type="password"]')].some(visible);
+	if (password && (/log-?in|sign-?in|signin|auth|sso/i.test(location.pathname) || /(sign|log) ?in/i.test(title))) {
+		return found('login_wall', 'sign-in form at ' + location.pathname, false);
+	}
+	return '';
+}`
+
+// Detect returns the block on the page, or nil if the page looks normal.
+func Detect(page *rod.Page) (*Block, error) {
+	res, err := page.Eval(detectJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect block page: %w", err)
+	}
+
+	raw := res.Value.Str()
+	if raw == "" {
+		return nil, nil
+	}
+
+	var b Block
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		return nil, fmt.Errorf("failed to parse block page: %w", err)
+	}
+	return &b, nil
+}
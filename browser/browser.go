@@ -3,18 +3,29 @@ package browser
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/anxuanzi/bua/dom"
+	"github.com/anxuanzi/bua/logging"
+	"github.com/anxuanzi/bua/robots"
+	screenshotpkg "github.com/anxuanzi/bua/screenshot"
 )
 
+// TempProfilePattern names the temporary profiles of browsers launched
+// without a ProfileName, in the system temp directory.
+const TempProfilePattern = "bua-browser-*"
+
 // Config holds browser configuration.
 type Config struct {
 	// Headless runs the browser without a visible window.
@@ -44,8 +55,105 @@ type Config struct {
 	// When true, screenshots include bounding boxes and index labels.
 	ShowAnnotations bool
 
+	// Annotations customizes annotated screenshots (nil =
+	// screenshot.DefaultAnnotationConfig()).
+	Annotations *screenshotpkg.AnnotationConfig
+
+	// ElementScope is the part of the page element maps cover (empty =
+	// dom.ScopeViewport).
+	ElementScope dom.Scope
+
+	// NewTabPolicy is what happens when a page opens a new tab (empty =
+	// NewTabFollow).
+	NewTabPolicy NewTabPolicy
+
+	// MaxTabMemoryBytes is the JavaScript heap size past which a tab is
+	// recycled: replaced, between actions, by a fresh one at the same URL in
+	// the same browser context (0 = no limit).
+	MaxTabMemoryBytes int64
+
+	// MaxTabs is how many tabs may be open at once. Opening one more closes
+	// the background tab that was least recently active (0 = no limit).
+	MaxTabs int
+
 	// Stealth configures anti-detection measures.
 	Stealth StealthConfig
+
+	// Humanize configures human-like mouse movement and typing.
+	Humanize HumanizeConfig
+
+	// Permissions are granted or denied per origin on start, so permission
+	// prompts never block the agent.
+	Permissions []PermissionRule
+
+	// Geolocation is the position reported to pages. Nil uses the real position
+	// (if the geolocation permission is granted).
+	Geolocation *Geolocation
+
+	// ColorScheme emulates prefers-color-scheme. Empty keeps the system setting.
+	ColorScheme ColorScheme
+
+	// Locale is the browser's language, e.g. "de-DE": navigator.language,
+	// Accept-Language and Intl formatting. Empty keeps the system locale.
+	Locale string
+
+	// ReducedMotion emulates prefers-reduced-motion: reduce.
+	ReducedMotion bool
+
+	// Robots makes the browser follow robots.txt: disallowed pages fail to
+	// load, and loads on each host are spaced by its crawl delay. Nil
+	// ignores robots.txt.
+	Robots *robots.Policy
+
+	// Device emulates a phone or tablet (size, pixel density, touch, user agent).
+	// Overrides ViewportWidth and ViewportHeight. Nil emulates a desktop.
+	Device *Device
+
+	// BrowserPath is the Chrome/Chromium executable to launch.
+	// Empty lets the launcher find a local install or download one.
+	BrowserPath string
+
+	// BrowserRevision pins the Chromium revision to launch, downloading it on
+	// first use. Ignored when BrowserPath is set.
+	BrowserRevision int
+
+	// BrowserDir is where pinned revisions are downloaded. Default: rod's cache directory.
+	BrowserDir string
+
+	// OnInstallProgress is called while a pinned revision downloads (optional).
+	OnInstallProgress func(InstallProgress)
+
+	// DownloadDir is where downloads started by the page are saved.
+	// Default: ~/.bua/downloads
+	DownloadDir string
+
+	// OnDownloadProgress is called as downloads make progress (optional).
+	OnDownloadProgress func(DownloadProgress)
+
+	// ControlURL attaches to an already-running Chrome instead of launching one.
+	// Accepts a DevTools WebSocket URL, a remote debugging address such as
+	// "http://localhost:9222", or a bare port. Profile and launch flags are ignored.
+	ControlURL string
+
+	// WSEndpoint is a WebSocket endpoint used as-is, for hosted browsers such as
+	// browserless.io or Chrome in Docker (e.g., "wss://host/?token=...").
+	// Takes precedence over ControlURL.
+	WSEndpoint string
+
+	// WrapCDP wraps the DevTools connection, e.g. to record it (optional).
+	WrapCDP func(rod.CDPClient) rod.CDPClient
+
+	// Offline skips launching or attaching to a browser: WrapCDP is called
+	// with nil and must answer every DevTools call itself, as a replay does.
+	Offline bool
+
+	// Logger receives the browser's logs (nil = console output, verbose
+	// with Debug).
+	Logger *slog.Logger
+
+	// TracerProvider receives spans for browser operations and DevTools
+	// calls (nil = the global OpenTelemetry provider).
+	TracerProvider trace.TracerProvider
 }
 
 // DefaultConfig returns a default browser configuration.
@@ -62,10 +170,12 @@ func DefaultConfig() Config {
 
 // TabInfo contains information about an open tab.
 type TabInfo struct {
-	ID     string
-	URL    string
-	Title  string
-	Active bool
+	ID       string
+	URL      string
+	Title    string
+	Active   bool
+	Isolated bool   // Tab has its own browser context (cookies, storage)
+	Label    string // What the tab is for, set with LabelTab
 }
 
 // Browser wraps rod.Browser with enhanced functionality.
@@ -74,41 +184,89 @@ type Browser struct {
 	rod      *rod.Browser
 	launcher *launcher.Launcher
 
+	// disconnect closes the DevTools connection
+	disconnect context.CancelFunc
+
 	// Tab management
 	pages       map[string]*rod.Page
 	activeTabID string
 
+	// Browser contexts owned by isolated tabs, keyed by tab ID
+	tabContexts map[string]*rod.Browser
+
+	// Labels set with LabelTab, keyed by tab ID
+	labels map[string]string
+
+	// When each tab was last active, and the tabs closed for MaxTabs that
+	// TakeClosedTabs has not returned yet
+	lastUsed   map[string]time.Time
+	closedTabs []TabInfo
+
 	// DOM extraction
 	extractor *dom.Extractor
 
+	// Frames selected with SwitchFrame, keyed by tab ID
+	frames map[string]*frameContext
+
 	// Temporary profile path for cleanup
 	tempProfilePath string
 
+	// Live frame streaming (lazily created by SubscribeFrames)
+	screencast *screencast
+
+	// Tab memory watchdog (lazily created)
+	memoryWatch *memoryWatch
+
+	// parent is the browser that owns the process when this is a tab view
+	parent *Browser
+
+	// incognito marks a view that owns its own browser context
+	incognito bool
+
+	// Page-initiated downloads (nil for views, which use the parent's)
+	downloads *downloadWatcher
+
+	// OpenTelemetry spans (shared with views)
+	tracing *tracing
+
+	// logger is the base logger; log tags it with a component
+	logger *slog.Logger
+
 	mu sync.RWMutex
 }
 
 // New creates a new browser instance.
 func New(cfg Config) (*Browser, error) {
 	b := &Browser{
-		config: cfg,
-		pages:  make(map[string]*rod.Page),
+		config:      cfg,
+		pages:       make(map[string]*rod.Page),
+		tabContexts: make(map[string]*rod.Browser),
+		tracing:     newTracing(cfg.TracerProvider),
+		logger:      logging.Or(cfg.Logger, cfg.Debug),
 	}
 
 	// Set default values
-	if cfg.ViewportWidth == 0 {
+	if cfg.Device != nil {
+		b.config.ViewportWidth = cfg.Device.Width
+		b.config.ViewportHeight = cfg.Device.Height
+	}
+	if b.config.ViewportWidth == 0 {
 		b.config.ViewportWidth = 1280
 	}
-	if cfg.ViewportHeight == 0 {
+	if b.config.ViewportHeight == 0 {
 		b.config.ViewportHeight = 720
 	}
 	if cfg.HighlightDuration == 0 {
 		b.config.HighlightDuration = 300 * time.Millisecond
 	}
+	if cfg.Locale != "" && cfg.Stealth.Fingerprint == nil {
+		b.config.Stealth.Locale = acceptLanguage(cfg.Locale)
+	}
 
 	return b, nil
 }
 
-// Start launches the browser.
+// Start launches the browser, or attaches to one when ControlURL or WSEndpoint is set.
 func (b *Browser) Start(ctx context.Context) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -117,9 +275,157 @@ func (b *Browser) Start(ctx context.Context) error {
 		return fmt.Errorf("browser already started")
 	}
 
+	// Connect to browser. The connection lives until Close cancels connCtx.
+	connCtx, disconnect := context.WithCancel(context.Background())
+	client, err := b.connect(ctx, connCtx)
+	if err != nil {
+		disconnect()
+		return err
+	}
+	browser := rod.New().Context(connCtx).Client(client)
+	if err := browser.Connect(); err != nil {
+		disconnect()
+		return fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	b.rod = browser
+	b.disconnect = disconnect
+
+	if err := b.applyPermissionRules(browser); err != nil {
+		return err
+	}
+	b.watchDownloads(browser)
+	b.watchNewTabs(browser)
+	if err := b.enforceRobots(browser); err != nil {
+		return err
+	}
+
+	// Set browser window size to match viewport (ensures consistency)
+	if !b.config.Headless && !b.IsRemote() {
+		// Get the first target to set window bounds
+		windowWidth := b.config.ViewportWidth + 16   // Add chrome border
+		windowHeight := b.config.ViewportHeight + 88 // Add toolbar height
+		boundsErr := proto.BrowserSetWindowBounds{
+			WindowID: 1,
+			Bounds: &proto.BrowserBounds{
+				Width:  &windowWidth,
+				Height: &windowHeight,
+			},
+		}.Call(browser)
+		if boundsErr != nil {
+			b.log("Browser").Warn("Failed to set window bounds", "err", boundsErr)
+		}
+	}
+
+	// Create initial page
+	page, err := b.rod.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return fmt.Errorf("failed to create initial page: %w", err)
+	}
+
+	// Apply stealth mode to page if enabled
+	if b.config.Stealth.EnableStealth {
+		if err := applyStealthMode(page, b.config.Stealth, b.config.Device); err != nil {
+			// Continue anyway - stealth is best-effort
+			b.log("Stealth").Warn("Failed to apply stealth mode", "err", err)
+		} else {
+			b.log("Stealth").Debug("Anti-detection scripts injected")
+		}
+	}
+
+	// Set viewport
+	if err := b.applyEmulation(page); err != nil {
+		return err
+	}
+	if err := b.applyNewTabPolicy(page); err != nil {
+		return err
+	}
+
+	// Register initial tab
+	tabID := generateTabID()
+	b.pages[tabID] = page
+	b.activeTabID = tabID
+
+	// Create extractor
+	b.extractor = dom.NewExtractor(100)
+	b.extractor.SetScope(b.config.ElementScope)
+
+	return nil
+}
+
+// connect opens the DevTools connection: to a running browser, a newly
+// launched one, or none at all when Offline.
+func (b *Browser) connect(ctx, connCtx context.Context) (rod.CDPClient, error) {
+	if b.config.Offline {
+		if b.config.WrapCDP == nil {
+			return nil, fmt.Errorf("offline browser requires WrapCDP")
+		}
+		return &tracingCDP{tracing: b.tracing, inner: b.config.WrapCDP(nil)}, nil
+	}
+
+	// Attach to a running browser or launch a local one
+	var controlURL string
+	var err error
+	if b.IsRemote() {
+		controlURL, err = b.remoteURL()
+	} else {
+		controlURL, err = b.launch(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := cdp.StartWithURL(connCtx, controlURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	if b.config.WrapCDP != nil {
+		return &tracingCDP{tracing: b.tracing, inner: b.config.WrapCDP(client)}, nil
+	}
+	return &tracingCDP{tracing: b.tracing, inner: client}, nil
+}
+
+// log returns the browser's logger tagged with a component.
+func (b *Browser) log(component string) *slog.Logger {
+	return logging.Component(b.logger, component)
+}
+
+// IsRemote reports whether the browser attaches to an existing Chrome
+// (ControlURL or WSEndpoint) instead of launching a local one.
+func (b *Browser) IsRemote() bool {
+	return b.config.ControlURL != "" || b.config.WSEndpoint != ""
+}
+
+// remoteURL returns the DevTools WebSocket URL of the browser to attach to.
+func (b *Browser) remoteURL() (string, error) {
+	if b.config.WSEndpoint != "" {
+		b.log("Browser").Debug("Connecting to remote WebSocket endpoint")
+		return b.config.WSEndpoint, nil
+	}
+
+	// Accepts ws:// URLs as well as http://host:port or a bare port,
+	// which are resolved through the /json/version endpoint
+	url, err := launcher.ResolveURL(b.config.ControlURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve control URL %s: %w", b.config.ControlURL, err)
+	}
+	b.log("Browser").Debug("Attaching to browser", "url", url)
+	return url, nil
+}
+
+// launch starts a local Chrome and returns its DevTools URL.
+func (b *Browser) launch(ctx context.Context) (string, error) {
 	// Configure launcher
 	l := launcher.New()
 
+	bin, err := b.resolveBinary(ctx)
+	if err != nil {
+		return "", err
+	}
+	if bin != "" {
+		l = l.Bin(bin)
+		b.log("Browser").Debug("Using binary", "path", bin)
+	}
+
 	if b.config.Headless {
 		l = l.Headless(true)
 	} else {
@@ -131,14 +437,14 @@ func (b *Browser) Start(ctx context.Context) error {
 		// Use named profile
 		profilePath := filepath.Join(b.config.ProfileDir, b.config.ProfileName)
 		if err := os.MkdirAll(profilePath, 0755); err != nil {
-			return fmt.Errorf("failed to create profile directory: %w", err)
+			return "", fmt.Errorf("failed to create profile directory: %w", err)
 		}
 		l = l.UserDataDir(profilePath)
 	} else {
 		// Use temporary profile
-		tempDir, err := os.MkdirTemp("", "bua-browser-*")
+		tempDir, err := os.MkdirTemp("", TempProfilePattern)
 		if err != nil {
-			return fmt.Errorf("failed to create temp profile: %w", err)
+			return "", fmt.Errorf("failed to create temp profile: %w", err)
 		}
 		b.tempProfilePath = tempDir
 		l = l.UserDataDir(tempDir)
@@ -170,9 +476,13 @@ func (b *Browser) Start(ctx context.Context) error {
 		l = l.Set("disable-background-timer-throttling")
 		l = l.Set("no-sandbox")
 		l = l.Set("ignore-certificate-errors")
-		if b.config.Debug {
-			fmt.Println("[Stealth] Anti-detection launch flags applied")
+		if fp := b.config.Stealth.Fingerprint; fp != nil && len(fp.Languages) > 0 && b.config.Locale == "" {
+			l = l.Set("lang", fp.Languages[0])
 		}
+		b.log("Stealth").Debug("Anti-detection launch flags applied")
+	}
+	if b.config.Locale != "" {
+		l = l.Set("lang", b.config.Locale).Set("accept-lang", acceptLanguage(b.config.Locale))
 	}
 
 	// Set window size to match viewport (prevents responsive layout issues)
@@ -181,69 +491,11 @@ func (b *Browser) Start(ctx context.Context) error {
 	// Launch browser
 	url, err := l.Launch()
 	if err != nil {
-		return fmt.Errorf("failed to launch browser: %w", err)
+		return "", fmt.Errorf("failed to launch browser: %w", err)
 	}
 	b.launcher = l
 
-	// Connect to browser
-	browser := rod.New().ControlURL(url)
-	if err := browser.Connect(); err != nil {
-		return fmt.Errorf("failed to connect to browser: %w", err)
-	}
-	b.rod = browser
-
-	// Set browser window size to match viewport (ensures consistency)
-	if !b.config.Headless {
-		// Get the first target to set window bounds
-		windowWidth := b.config.ViewportWidth + 16   // Add chrome border
-		windowHeight := b.config.ViewportHeight + 88 // Add toolbar height
-		boundsErr := proto.BrowserSetWindowBounds{
-			WindowID: 1,
-			Bounds: &proto.BrowserBounds{
-				Width:  &windowWidth,
-				Height: &windowHeight,
-			},
-		}.Call(browser)
-		if boundsErr != nil && b.config.Debug {
-			fmt.Printf("[Browser] Warning: failed to set window bounds: %v\n", boundsErr)
-		}
-	}
-
-	// Create initial page
-	page, err := b.rod.Page(proto.TargetCreateTarget{URL: "about:blank"})
-	if err != nil {
-		return fmt.Errorf("failed to create initial page: %w", err)
-	}
-
-	// Apply stealth mode to page if enabled
-	if b.config.Stealth.EnableStealth {
-		if err := applyStealthMode(page, b.config.Stealth); err != nil {
-			if b.config.Debug {
-				fmt.Printf("[Stealth] Warning: failed to apply stealth mode: %v\n", err)
-			}
-			// Continue anyway - stealth is best-effort
-		} else if b.config.Debug {
-			fmt.Println("[Stealth] Anti-detection scripts injected")
-		}
-	}
-
-	// Set viewport
-	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:  b.config.ViewportWidth,
-		Height: b.config.ViewportHeight,
-	}); err != nil {
-		return fmt.Errorf("failed to set viewport: %w", err)
-	}
-
-	// Register initial tab
-	tabID := generateTabID()
-	b.pages[tabID] = page
-	b.activeTabID = tabID
-
-	// Create extractor
-	b.extractor = dom.NewExtractor(100)
-
-	return nil
+	return url, nil
 }
 
 // Close shuts down the browser and cleans up resources.
@@ -253,6 +505,35 @@ func (b *Browser) Close() error {
 
 	var errs []error
 
+	// Stop streaming before pages go away
+	if b.screencast != nil {
+		b.screencast.mu.Lock()
+		b.screencast.stopLocked()
+		b.screencast.mu.Unlock()
+	}
+
+	// Views only own their pages (and incognito context); the process belongs to the parent
+	if b.parent != nil {
+		for _, page := range b.pages {
+			if err := page.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		b.pages = make(map[string]*rod.Page)
+		errs = append(errs, b.disposeTabContexts()...)
+		if b.incognito && b.rod != nil {
+			// Disposes the context along with its cookies and storage
+			if err := b.rod.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		b.rod = nil
+		if len(errs) > 0 {
+			return fmt.Errorf("errors during close: %v", errs)
+		}
+		return nil
+	}
+
 	// Close all pages
 	for _, page := range b.pages {
 		if err := page.Close(); err != nil {
@@ -260,14 +541,21 @@ func (b *Browser) Close() error {
 		}
 	}
 	b.pages = make(map[string]*rod.Page)
+	errs = append(errs, b.disposeTabContexts()...)
 
-	// Close browser
+	// Close browser. A remote browser is left running; we only drop our connection.
 	if b.rod != nil {
-		if err := b.rod.Close(); err != nil {
-			errs = append(errs, err)
+		if !b.IsRemote() {
+			if err := b.rod.Close(); err != nil {
+				errs = append(errs, err)
+			}
 		}
 		b.rod = nil
 	}
+	if b.disconnect != nil {
+		b.disconnect()
+		b.disconnect = nil
+	}
 
 	// Clean up temporary profile
 	if b.tempProfilePath != "" {
@@ -283,6 +571,33 @@ func (b *Browser) Close() error {
 	return nil
 }
 
+// disposeTabContexts disposes the browser contexts of isolated tabs. Must be called with b.mu held.
+func (b *Browser) disposeTabContexts() []error {
+	var errs []error
+	for id, tabCtx := range b.tabContexts {
+		if err := tabCtx.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(b.tabContexts, id)
+	}
+	return errs
+}
+
+// ProfilePath returns the user data directory of the launched browser: the
+// named profile's, or the temporary one. It is "" for remote browsers.
+func (b *Browser) ProfilePath() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	switch {
+	case b.config.WSEndpoint != "" || b.config.ControlURL != "":
+		return ""
+	case b.config.ProfileName != "":
+		return filepath.Join(b.config.ProfileDir, b.config.ProfileName)
+	}
+	return b.tempProfilePath
+}
+
 // ActivePage returns the currently active page.
 func (b *Browser) ActivePage() *rod.Page {
 	b.mu.RLock()
@@ -328,11 +643,14 @@ func (b *Browser) ListTabs() []TabInfo {
 		if err != nil {
 			continue
 		}
+		_, isolated := b.tabContexts[id]
 		tabs = append(tabs, TabInfo{
-			ID:     id,
-			URL:    info.URL,
-			Title:  info.Title,
-			Active: id == b.activeTabID,
+			ID:       id,
+			URL:      info.URL,
+			Title:    info.Title,
+			Active:   id == b.activeTabID,
+			Isolated: isolated,
+			Label:    b.labels[id],
 		})
 	}
 	return tabs
@@ -340,6 +658,30 @@ func (b *Browser) ListTabs() []TabInfo {
 
 // NewTab creates a new tab and optionally navigates to a URL.
 func (b *Browser) NewTab(ctx context.Context, url string) (string, error) {
+	_, end := b.startSpan(ctx, "browser.new_tab", attribute.String("url.full", url))
+	defer end()
+
+	return b.openTab(ctx, url, false)
+}
+
+// NewIsolatedTab creates a new tab in its own browser context, so it shares no
+// cookies or storage with other tabs. This lets one agent be logged into two
+// accounts of the same site at once. The context is disposed with the tab.
+func (b *Browser) NewIsolatedTab(ctx context.Context, url string) (string, error) {
+	_, end := b.startSpan(ctx, "browser.new_tab", attribute.String("url.full", url))
+	defer end()
+
+	return b.openTab(ctx, url, true)
+}
+
+// openTab creates a tab, optionally in a fresh browser context, and activates it.
+func (b *Browser) openTab(ctx context.Context, url string, isolated bool) (string, error) {
+	if url != "" {
+		if err := b.checkRobots(ctx, url); err != nil {
+			return "", err
+		}
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -352,37 +694,67 @@ func (b *Browser) NewTab(ctx context.Context, url string) (string, error) {
 		targetURL = url
 	}
 
-	page, err := b.rod.Page(proto.TargetCreateTarget{URL: targetURL})
+	owner := b.rod
+	if isolated {
+		incognito, err := b.rod.Incognito()
+		if err != nil {
+			return "", fmt.Errorf("failed to create browser context: %w", err)
+		}
+		owner = incognito
+		if err := b.applyPermissionRules(owner); err != nil {
+			_ = owner.Close()
+			return "", err
+		}
+		_ = b.applyDownloadBehavior(owner)
+	}
+
+	page, err := owner.Page(proto.TargetCreateTarget{URL: targetURL})
 	if err != nil {
+		if isolated {
+			_ = owner.Close()
+		}
 		return "", fmt.Errorf("failed to create new tab: %w", err)
 	}
 
+	if err := b.setupTab(page, url); err != nil {
+		if isolated {
+			_ = owner.Close()
+		}
+		return "", err
+	}
+
+	tabID := generateTabID()
+	b.pages[tabID] = page
+	if isolated {
+		b.tabContexts[tabID] = owner
+	}
+	b.activate(tabID)
+
+	return tabID, nil
+}
+
+// setupTab applies stealth mode and the viewport to a freshly created tab,
+// then waits for the initial navigation to settle.
+func (b *Browser) setupTab(page *rod.Page, url string) error {
 	// Apply stealth mode to new tab if enabled
 	if b.config.Stealth.EnableStealth {
-		if err := applyStealthMode(page, b.config.Stealth); err != nil {
-			if b.config.Debug {
-				fmt.Printf("[Stealth] Warning: failed to apply stealth mode to new tab: %v\n", err)
-			}
+		if err := applyStealthMode(page, b.config.Stealth, b.config.Device); err != nil {
+			b.log("Stealth").Warn("Failed to apply stealth mode to new tab", "err", err)
 		}
 	}
 
 	// Set viewport
-	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:  b.config.ViewportWidth,
-		Height: b.config.ViewportHeight,
-	}); err != nil {
-		return "", fmt.Errorf("failed to set viewport: %w", err)
+	if err := b.applyEmulation(page); err != nil {
+		return err
+	}
+	if err := b.applyNewTabPolicy(page); err != nil {
+		return err
 	}
 
 	if url != "" {
-		_ = page.WaitStable(500 * time.Millisecond)
+		b.settle(page, settleLoad)
 	}
-
-	tabID := generateTabID()
-	b.pages[tabID] = page
-	b.activeTabID = tabID
-
-	return tabID, nil
+	return nil
 }
 
 // SwitchTab switches to a tab by ID.
@@ -400,7 +772,27 @@ func (b *Browser) SwitchTab(tabID string) error {
 		return fmt.Errorf("failed to activate tab: %w", err)
 	}
 
-	b.activeTabID = tabID
+	b.activate(tabID)
+	return nil
+}
+
+// LabelTab names what a tab is for, e.g. "GitHub stats", so it can be told
+// apart from the others in ListTabs. An empty label removes it.
+func (b *Browser) LabelTab(tabID, label string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.pages[tabID]; !ok {
+		return fmt.Errorf("tab not found: %s", tabID)
+	}
+	if label == "" {
+		delete(b.labels, tabID)
+		return nil
+	}
+	if b.labels == nil {
+		b.labels = make(map[string]string)
+	}
+	b.labels[tabID] = label
 	return nil
 }
 
@@ -409,8 +801,7 @@ func (b *Browser) CloseTab(tabID string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	page, ok := b.pages[tabID]
-	if !ok {
+	if _, ok := b.pages[tabID]; !ok {
 		return fmt.Errorf("tab not found: %s", tabID)
 	}
 
@@ -419,46 +810,115 @@ func (b *Browser) CloseTab(tabID string) error {
 		return fmt.Errorf("cannot close the last tab")
 	}
 
-	if err := page.Close(); err != nil {
-		return fmt.Errorf("failed to close tab: %w", err)
+	if err := b.closeTab(tabID); err != nil {
+		return err
 	}
 
-	delete(b.pages, tabID)
-
-	// Switch to another tab if we closed the active one
+	// Switch to the most recently used tab if we closed the active one
 	if b.activeTabID == tabID {
+		next := ""
 		for id := range b.pages {
-			b.activeTabID = id
-			break
+			if next == "" || b.lastUsed[id].After(b.lastUsed[next]) {
+				next = id
+			}
 		}
+		b.activeTabID = next
+		b.followActiveTab()
 	}
 
 	return nil
 }
 
+// closeTab closes a tab and forgets it. Must be called with b.mu held.
+func (b *Browser) closeTab(tabID string) error {
+	page := b.pages[tabID]
+	if err := page.Close(); err != nil {
+		return fmt.Errorf("failed to close tab: %w", err)
+	}
+
+	delete(b.pages, tabID)
+	delete(b.labels, tabID)
+	delete(b.lastUsed, tabID)
+	b.extractor.Invalidate(page)
+
+	// Dispose the tab's own browser context, if any
+	if tabCtx, ok := b.tabContexts[tabID]; ok {
+		if err := tabCtx.Close(); err != nil {
+			b.log("Browser").Warn("Failed to dispose context of tab", "tab", tabID, "err", err)
+		}
+		delete(b.tabContexts, tabID)
+	}
+	return nil
+}
+
 // GetElementMap extracts interactive elements from the current page.
 func (b *Browser) GetElementMap(ctx context.Context) (*dom.ElementMap, error) {
+	_, end := b.startSpan(ctx, "browser.get_element_map")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return nil, fmt.Errorf("no active page")
 	}
 
+	b.checkMemory(ctx)
+	page = b.ActivePage()
+
+	b.settle(page, settleRead)
+	if fc, dx, dy := b.activeFrame(); fc != nil {
+		em, err := b.extractor.Extract(ctx, fc.page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame %s: %w", fc.path, err)
+		}
+		return em.Translated(dx, dy), nil
+	}
 	return b.extractor.Extract(ctx, page)
 }
 
+// GetFullElementMap extracts interactive elements from the whole current
+// page, whatever the configured ElementScope. Elements outside the viewport
+// are marked not visible.
+func (b *Browser) GetFullElementMap(ctx context.Context) (*dom.ElementMap, error) {
+	_, end := b.startSpan(ctx, "browser.get_element_map")
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+
+	b.checkMemory(ctx)
+	page = b.ActivePage()
+
+	b.settle(page, settleRead)
+	if fc, dx, dy := b.activeFrame(); fc != nil {
+		em, err := b.extractor.ExtractScope(ctx, fc.page, dom.ScopeDocument)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame %s: %w", fc.path, err)
+		}
+		return em.Translated(dx, dy), nil
+	}
+	return b.extractor.ExtractScope(ctx, page, dom.ScopeDocument)
+}
+
 // SetMaxElements sets the maximum number of elements to extract.
 func (b *Browser) SetMaxElements(max int) {
 	b.extractor = dom.NewExtractor(max)
+	b.extractor.SetScope(b.config.ElementScope)
 }
 
-// WaitStable waits for the page to become stable.
+// WaitStable waits for the page to load and settle: no pending requests
+// and no DOM changes for half a second, or 10 seconds at most.
 func (b *Browser) WaitStable(ctx context.Context) error {
+	_, end := b.startSpan(ctx, "browser.wait_stable")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
 	}
-	_ = ctx // Context available for future use
-	return page.WaitStable(500 * time.Millisecond)
+	b.settle(page, settleLoad)
+	return nil
 }
 
 // generateTabID creates a unique 4-character tab ID.
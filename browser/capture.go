@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/anxuanzi/bua/dom"
+	screenshotpkg "github.com/anxuanzi/bua/screenshot"
+)
+
+// tabCaptureTimeout bounds the capture of one tab by CaptureAllTabs.
+const tabCaptureTimeout = 15 * time.Second
+
+// TabSnapshot is the state of one tab at a point in time, as captured by
+// CaptureAllTabs.
+type TabSnapshot struct {
+	TabID  string `json:"tab_id"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Active bool   `json:"active"`
+
+	// Screenshot is a JPEG of the tab's viewport.
+	Screenshot []byte `json:"-"`
+
+	// ElementMap holds the tab's interactive elements, in the configured
+	// ElementScope.
+	ElementMap *dom.ElementMap `json:"-"`
+
+	// Error says what could not be captured, if anything.
+	Error string `json:"error,omitempty"`
+}
+
+// CaptureAllTabs takes a screenshot and the element map of every open tab
+// at once, sorted by tab ID, e.g. to compare the same product on several
+// sites. A tab that fails to capture has its Error set. In a visible
+// browser Chrome may not repaint background tabs, so their screenshots can
+// lag behind.
+func (b *Browser) CaptureAllTabs(ctx context.Context) ([]TabSnapshot, error) {
+	_, end := b.startSpan(ctx, "browser.capture_all_tabs")
+	defer end()
+
+	b.mu.RLock()
+	snapshots := make([]TabSnapshot, 0, len(b.pages))
+	pages := make([]*rod.Page, 0, len(b.pages))
+	for id, page := range b.pages {
+		snapshots = append(snapshots, TabSnapshot{TabID: id, Active: id == b.activeTabID})
+		pages = append(pages, page)
+	}
+	b.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range snapshots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.captureTab(ctx, pages[i], &snapshots[i])
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].TabID < snapshots[j].TabID })
+	return snapshots, nil
+}
+
+// captureTab fills in s from page.
+func (b *Browser) captureTab(ctx context.Context, page *rod.Page, s *TabSnapshot) {
+	ctx, cancel := context.WithTimeout(ctx, tabCaptureTimeout)
+	defer cancel()
+	page = page.Context(ctx)
+
+	info, err := page.Info()
+	if err != nil {
+		s.Error = "failed to get tab info: " + err.Error()
+		return
+	}
+	s.URL, s.Title = info.URL, info.Title
+
+	em, err := b.extractor.Extract(ctx, page)
+	if err != nil {
+		s.Error = err.Error()
+		return
+	}
+	s.ElementMap = em
+
+	opts := screenshotpkg.LLMOptions()
+	opts.MaxWidth = b.config.ViewportWidth
+	opts.WaitForIdle = false
+	opts.ValidateContent = false
+	b.clearHighlights(page)
+	data, err := screenshotpkg.Capture(ctx, page, opts)
+	if err != nil {
+		s.Error = err.Error()
+		return
+	}
+	s.Screenshot = data
+}
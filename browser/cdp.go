@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// browserDomains are the DevTools protocol domains whose commands go to the
+// browser rather than to a page.
+var browserDomains = []string{"Browser.", "Target.", "SystemInfo.", "Storage."}
+
+// CallCDP sends a raw DevTools protocol command, e.g. "Page.printToPDF", and
+// returns its raw result. Commands of the Browser, Target, SystemInfo and
+// Storage domains go to the browser, others to the active page. params is
+// marshaled to JSON; nil sends no parameters.
+func (b *Browser) CallCDP(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	ctx, end := b.startSpan(ctx, "browser.cdp_call", attribute.String("bua.cdp_method", method))
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+	if params == nil {
+		params = struct{}{}
+	}
+
+	sessionID := string(page.SessionID)
+	for _, domain := range browserDomains {
+		if strings.HasPrefix(method, domain) {
+			sessionID = ""
+			break
+		}
+	}
+	res, err := page.Call(ctx, sessionID, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("cdp call %s failed: %w", method, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Cookie is a browser cookie.
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitzero"` // Zero for session cookies
+	HTTPOnly bool      `json:"http_only,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	SameSite string    `json:"same_site,omitempty"` // "Strict", "Lax", or "None"
+}
+
+// GetCookies returns all cookies in the browser.
+func (b *Browser) GetCookies(ctx context.Context) ([]Cookie, error) {
+	b.mu.RLock()
+	r := b.rod
+	b.mu.RUnlock()
+
+	if r == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+	_ = ctx // Context available for future use
+
+	raw, err := r.GetCookies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	cookies := make([]Cookie, len(raw))
+	for i, c := range raw {
+		cookies[i] = Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: string(c.SameSite),
+		}
+		if !c.Session && c.Expires > 0 {
+			cookies[i].Expires = c.Expires.Time()
+		}
+	}
+	return cookies, nil
+}
+
+// SetCookies adds or replaces cookies in the browser.
+func (b *Browser) SetCookies(ctx context.Context, cookies []Cookie) error {
+	b.mu.RLock()
+	r := b.rod
+	b.mu.RUnlock()
+
+	if r == nil {
+		return fmt.Errorf("browser not started")
+	}
+	_ = ctx // Context available for future use
+
+	params := make([]*proto.NetworkCookieParam, len(cookies))
+	for i, c := range cookies {
+		params[i] = &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: proto.NetworkCookieSameSite(c.SameSite),
+		}
+		if !c.Expires.IsZero() {
+			params[i].Expires = proto.TimeSinceEpoch(c.Expires.Unix())
+		}
+	}
+
+	if err := r.SetCookies(params); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+// ClearCookies removes all cookies from the browser.
+func (b *Browser) ClearCookies(ctx context.Context) error {
+	b.mu.RLock()
+	r := b.rod
+	b.mu.RUnlock()
+
+	if r == nil {
+		return fmt.Errorf("browser not started")
+	}
+	_ = ctx // Context available for future use
+
+	// rod clears all cookies when given nil
+	if err := r.SetCookies(nil); err != nil {
+		return fmt.Errorf("failed to clear cookies: %w", err)
+	}
+	return nil
+}
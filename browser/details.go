@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// maxDetailsHTML caps the inner HTML ElementDetails returns.
+const maxDetailsHTML = 1500
+
+// detailsJS describes the element with a stable ID, or else the element at a
+// point, or returns "" if there is none.
+const detailsJS = `(id, x, y, maxHTML) => {
+	const el = (id && document.querySelector('[data-bua-id="' + CSS.escape(id) + '"]')) ||
+		document.elementFromPoint(x, y);
+	if (!el) return '';
+
+	const attributes = {};
+	const aria = {};
+	for (const attr of el.attributes) {
+		if (attr.name === 'data-bua-id') continue;
+		if (attr.name === 'role' || attr.name.startsWith('aria-')) aria[attr.name] = attr.value;
+		else attributes[attr.name] = attr.value.length > 200 ? attr.value.slice(0, 200) + '...' : attr.value;
+	}
+	if ('checked' in el && (el.type === 'checkbox' || el.type === 'radio')) aria['checked'] = String(el.checked);
+	if ('disabled' in el) aria['disabled'] = String(!!el.disabled);
+	if (el.tagName === 'SELECT') aria['selected'] = Array.from(el.selectedOptions).map(o => o.text).join(', ');
+
+	const style = getComputedStyle(el);
+	const r = el.getBoundingClientRect();
+	const cx = r.x + r.width / 2, cy = r.y + r.height / 2;
+	const inViewport = r.bottom > 0 && r.top < innerHeight && r.right > 0 && r.left < innerWidth;
+	let coveredBy = '';
+	if (inViewport) {
+		const top = document.elementFromPoint(cx, cy);
+		if (top && top !== el && !el.contains(top) && !top.contains(el)) {
+			coveredBy = top.tagName.toLowerCase() + (top.id ? '#' + top.id : '') +
+				(typeof top.className === 'string' && top.className.trim() ? '.' + top.className.trim().split(/\s+/).slice(0, 2).join('.') : '');
+		}
+	}
+
+	let html = el.innerHTML.replace(/\s+/g, ' ').trim();
+	if (html.length > maxHTML) html = html.slice(0, maxHTML) + '...';
+	const text = (el.innerText || el.value || '').trim().replace(/\s+/g, ' ');
+
+	return JSON.stringify({
+		tagName: el.tagName.toLowerCase(),
+		text: text.length > 300 ? text.slice(0, 300) + '...' : text,
+		attributes: attributes,
+		aria: aria,
+		visibility: {
+			display: style.display,
+			visibility: style.visibility,
+			opacity: parseFloat(style.opacity),
+			pointerEvents: style.pointerEvents,
+			inViewport: inViewport,
+			coveredBy: coveredBy
+		},
+		boundingBox: { x: r.x, y: r.y, width: r.width, height: r.height },
+		innerHTML: html
+	});
+}`
+
+// ElementDetails is everything ElementDetails reports about an element.
+type ElementDetails struct {
+	TagName string `json:"tagName"`
+	Text    string `json:"text,omitempty"`
+
+	// Attributes are the element's attributes other than role and aria-*.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// ARIA holds role and aria-* attributes, and the checked, disabled and
+	// selected state of form controls.
+	ARIA map[string]string `json:"aria,omitempty"`
+
+	Visibility ElementVisibility `json:"visibility"`
+
+	BoundingBox dom.BoundingBox `json:"boundingBox"`
+
+	// InnerHTML is the element's markup, whitespace-collapsed and cut
+	// after 1500 characters.
+	InnerHTML string `json:"innerHTML,omitempty"`
+}
+
+// ElementVisibility is the computed visibility of an element.
+type ElementVisibility struct {
+	Display       string  `json:"display"`
+	Visibility    string  `json:"visibility"`
+	Opacity       float64 `json:"opacity"`
+	PointerEvents string  `json:"pointerEvents"`
+	InViewport    bool    `json:"inViewport"`
+
+	// CoveredBy describes the element on top of this one's center, e.g. a
+	// modal overlay, if another element covers it.
+	CoveredBy string `json:"coveredBy,omitempty"`
+}
+
+// ElementDetails returns the attributes, ARIA state, computed visibility
+// and inner HTML of an element of elementMap.
+func (b *Browser) ElementDetails(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) (*ElementDetails, error) {
+	_, end := b.startSpan(ctx, "browser.element_details", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+
+	element, ok := elementMap.Get(elementIndex)
+	if !ok {
+		return nil, fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
+	}
+
+	doc, dx, dy := b.documentPage()
+	x, y := element.BoundingBox.Center()
+	result, err := doc.Eval(detailsJS, element.StableID, x-dx, y-dy, maxDetailsHTML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read element details: %w", err)
+	}
+	raw := result.Value.Str()
+	if raw == "" {
+		return nil, fmt.Errorf("%w: index %d is no longer on the page", ErrElementNotFound, elementIndex)
+	}
+
+	var details ElementDetails
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		return nil, fmt.Errorf("failed to parse element details: %w", err)
+	}
+	return &details, nil
+}
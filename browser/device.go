@@ -0,0 +1,175 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Device describes a device to emulate: viewport, pixel density, touch input
+// and user agent. Setting only the viewport size isn't enough for sites that
+// serve mobile layouts based on the user agent or touch support.
+type Device struct {
+	Name              string
+	Width             int
+	Height            int
+	DeviceScaleFactor float64
+	Mobile            bool
+	Touch             bool
+	UserAgent         string
+	Platform          string // navigator.platform reported with the user agent
+}
+
+// Device presets.
+var (
+	// DeviceIPhone15 emulates an iPhone 15 running Safari.
+	DeviceIPhone15 = Device{
+		Name:              "iPhone 15",
+		Width:             393,
+		Height:            852,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		Touch:             true,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Platform:          "iPhone",
+	}
+
+	// DeviceIPhone15ProMax emulates an iPhone 15 Pro Max running Safari.
+	DeviceIPhone15ProMax = Device{
+		Name:              "iPhone 15 Pro Max",
+		Width:             430,
+		Height:            932,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		Touch:             true,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Platform:          "iPhone",
+	}
+
+	// DevicePixel8 emulates a Google Pixel 8 running Chrome.
+	DevicePixel8 = Device{
+		Name:              "Pixel 8",
+		Width:             412,
+		Height:            915,
+		DeviceScaleFactor: 2.625,
+		Mobile:            true,
+		Touch:             true,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		Platform:          "Linux armv81",
+	}
+
+	// DeviceGalaxyS24 emulates a Samsung Galaxy S24 running Chrome.
+	DeviceGalaxyS24 = Device{
+		Name:              "Galaxy S24",
+		Width:             360,
+		Height:            780,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		Touch:             true,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 14; SM-S921B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+		Platform:          "Linux armv81",
+	}
+
+	// DeviceIPadPro emulates a 12.9" iPad Pro running Safari.
+	DeviceIPadPro = Device{
+		Name:              "iPad Pro",
+		Width:             1024,
+		Height:            1366,
+		DeviceScaleFactor: 2,
+		Mobile:            true,
+		Touch:             true,
+		UserAgent:         "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Platform:          "iPad",
+	}
+)
+
+// Devices lists the built-in device presets.
+var Devices = []Device{
+	DeviceIPhone15,
+	DeviceIPhone15ProMax,
+	DevicePixel8,
+	DeviceGalaxyS24,
+	DeviceIPadPro,
+}
+
+// DeviceByName returns the built-in preset with the given name (case-insensitive).
+func DeviceByName(name string) (Device, error) {
+	for _, d := range Devices {
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("unknown device: %s", name)
+}
+
+// applyEmulation applies the configured viewport or device, media features and
+// geolocation to a page.
+func (b *Browser) applyEmulation(page *rod.Page) error {
+	if err := b.applyDevice(page); err != nil {
+		return err
+	}
+	if b.config.ColorScheme != ColorSchemeDefault || b.config.ReducedMotion {
+		if err := b.applyMedia(page); err != nil {
+			return err
+		}
+	}
+	if err := b.applyLocale(page); err != nil {
+		return fmt.Errorf("failed to set locale: %w", err)
+	}
+	return b.applyGeolocation(page)
+}
+
+// stealthFingerprint reports whether pages get a stealth fingerprint.
+func (b *Browser) stealthFingerprint() bool {
+	return b.config.Stealth.EnableStealth && b.config.Stealth.Fingerprint != nil
+}
+
+// applyDevice sets the viewport on a page, emulating the configured device
+// (pixel density, mobile flag, touch, user agent) if there is one.
+func (b *Browser) applyDevice(page *rod.Page) error {
+	d := b.config.Device
+	if d == nil {
+		if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:  b.config.ViewportWidth,
+			Height: b.config.ViewportHeight,
+		}); err != nil {
+			return fmt.Errorf("failed to set viewport: %w", err)
+		}
+		return nil
+	}
+
+	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             d.Width,
+		Height:            d.Height,
+		DeviceScaleFactor: d.DeviceScaleFactor,
+		Mobile:            d.Mobile,
+	}); err != nil {
+		return fmt.Errorf("failed to set device metrics: %w", err)
+	}
+
+	touch := proto.EmulationSetTouchEmulationEnabled{Enabled: d.Touch}
+	if d.Touch {
+		maxTouchPoints := 5
+		touch.MaxTouchPoints = &maxTouchPoints
+	}
+	if err := touch.Call(page); err != nil {
+		return fmt.Errorf("failed to set touch emulation: %w", err)
+	}
+
+	// A stealth fingerprint has already set the device's user agent, with
+	// client hints to match; overriding it here would drop them
+	if d.UserAgent != "" && !b.stealthFingerprint() {
+		if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+			UserAgent:      d.UserAgent,
+			AcceptLanguage: b.deviceAcceptLanguage(),
+			Platform:       d.Platform,
+		}); err != nil {
+			return fmt.Errorf("failed to set user agent: %w", err)
+		}
+	}
+
+	b.log("Browser").Debug("Emulating device", "device", d.Name, "width", d.Width, "height", d.Height, "scale", d.DeviceScaleFactor)
+	return nil
+}
@@ -0,0 +1,441 @@
+package browser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// ErrDownloadTooLarge is returned when a download exceeds DownloadOptions.MaxBytes.
+var ErrDownloadTooLarge = errors.New("download exceeds size limit")
+
+// DownloadProgress reports the state of a download.
+type DownloadProgress struct {
+	URL        string
+	Path       string
+	Downloaded int64 // Bytes on disk, including any resumed part
+	Total      int64 // Expected size, or -1 if the server didn't say
+	Done       bool
+}
+
+// DownloadOptions configures a download.
+type DownloadOptions struct {
+	// Dir is where files are saved. Default: the current directory.
+	Dir string
+
+	// Filename overrides the name taken from Content-Disposition or the URL.
+	Filename string
+
+	// MaxBytes aborts downloads larger than this. Default: 0 (no limit).
+	MaxBytes int64
+
+	// OnProgress is called as data arrives (optional).
+	OnProgress func(DownloadProgress)
+}
+
+// DownloadResult describes a completed download.
+type DownloadResult struct {
+	Path        string `json:"path"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type,omitempty"`
+	Resumed     bool   `json:"resumed,omitempty"` // Continued from an earlier partial download
+}
+
+// partSuffix marks an incomplete download that a later call can resume;
+// validatorSuffix the file holding the ETag or Last-Modified it started with.
+const (
+	partSuffix      = ".part"
+	validatorSuffix = ".part-validator"
+)
+
+// DownloadFile downloads the resource an element links to (href or src).
+func (b *Browser) DownloadFile(ctx context.Context, elementIndex int, elementMap *dom.ElementMap, opts DownloadOptions) (*DownloadResult, error) {
+	_, end := b.startSpan(ctx, "browser.download", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
+	element, ok := elementMap.Get(elementIndex)
+	if !ok {
+		return nil, fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
+	}
+
+	link := element.Href
+	if link == "" {
+		page := b.ActivePage()
+		if page == nil {
+			return nil, fmt.Errorf("no active page")
+		}
+		// Images, media and embeds carry the URL in src
+		res, err := page.Eval(`(x, y) => {
+			const el = document.elementFromPoint(x, y);
+			const target = el && el.closest('a[href], [src]');
+			return target ? (target.href || target.src || '') : '';
+		}`, element.BoundingBox.X+element.BoundingBox.Width/2, element.BoundingBox.Y+element.BoundingBox.Height/2)
+		if err == nil {
+			link = res.Value.Str()
+		}
+	}
+	if link == "" {
+		return nil, fmt.Errorf("element %d has no link to download", elementIndex)
+	}
+
+	return b.DownloadResource(ctx, link, opts)
+}
+
+// DownloadResource downloads a URL with the browser's cookies and user agent,
+// so files behind a login work. An interrupted download leaves a ".part" file,
+// named after a hash of the URL, that the next call for the same URL resumes
+// with a Range request. If the file changed on the server meanwhile, as its
+// ETag or Last-Modified tell, or the server answers with another range, the
+// download starts over.
+func (b *Browser) DownloadResource(ctx context.Context, rawURL string, opts DownloadOptions) (*DownloadResult, error) {
+	_, end := b.startSpan(ctx, "browser.download", attribute.String("url.full", rawURL))
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Resolve relative URLs against the current page
+	base, _ := url.Parse(b.GetURL())
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid download URL: %w", err)
+	}
+	if base != nil {
+		target = base.ResolveReference(target)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported download URL scheme %q", target.Scheme)
+	}
+
+	header := http.Header{}
+	var cookies []*http.Cookie
+	if res, err := (proto.NetworkGetCookies{Urls: []string{target.String()}}).Call(page); err == nil {
+		for _, c := range res.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+	if ua, err := page.Eval(`() => navigator.userAgent`); err == nil {
+		header.Set("User-Agent", ua.Value.Str())
+	}
+	if b.GetURL() != "" {
+		header.Set("Referer", b.GetURL())
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	// Resume a partial download of the same URL if there is one
+	name := opts.Filename
+	if name == "" {
+		name = filenameFromURL(target)
+	}
+	partPath := partFilePath(dir, name, target)
+	validatorPath := strings.TrimSuffix(partPath, partSuffix) + validatorSuffix
+	var offset int64
+	var validator string
+	if fi, err := os.Stat(partPath); err == nil {
+		offset = fi.Size()
+		if v, err := os.ReadFile(validatorPath); err == nil {
+			validator = string(v)
+		}
+	}
+
+	var resp *http.Response
+	resumed, complete := false, false
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+		req.Header = header.Clone()
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			if validator != "" {
+				// The server sends the whole file if it changed
+				req.Header.Set("If-Range", validator)
+			}
+		}
+
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("download failed: %w", err)
+		}
+
+		restart := false
+		switch resp.StatusCode {
+		case http.StatusOK:
+			offset = 0
+			if v := responseValidator(resp.Header); v != "" {
+				if err := os.WriteFile(validatorPath, []byte(v), 0644); err != nil {
+					b.log("Download").Warn("Failed to save download validator", "path", validatorPath, "err", err)
+				}
+			} else {
+				os.Remove(validatorPath)
+			}
+		case http.StatusPartialContent:
+			start, _, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			switch {
+			case ok && start == offset:
+				resumed = offset > 0
+			case offset > 0:
+				restart = true // not the range we asked for
+			default:
+				resp.Body.Close()
+				return nil, fmt.Errorf("download failed: server returned an unrequested range %q", resp.Header.Get("Content-Range"))
+			}
+		case http.StatusRequestedRangeNotSatisfiable:
+			// The part file is complete if it is as long as the file
+			_, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			if offset > 0 && ok && size == offset {
+				resumed, complete = true, true
+			} else if offset > 0 {
+				restart = true
+			} else {
+				resp.Body.Close()
+				return nil, fmt.Errorf("download failed: server returned %s", resp.Status)
+			}
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("download failed: server returned %s", resp.Status)
+		}
+		if !restart {
+			break
+		}
+		resp.Body.Close()
+		os.Remove(partPath)
+		os.Remove(validatorPath)
+		offset, validator = 0, ""
+	}
+	defer resp.Body.Close()
+
+	// The server's name wins over the URL's for the finished file, unless the
+	// caller chose one
+	if opts.Filename == "" {
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+			name = params["filename"]
+		}
+	}
+
+	total := int64(-1)
+	switch {
+	case complete:
+		total = offset
+	case resp.ContentLength >= 0:
+		total = offset + resp.ContentLength
+	}
+	if opts.MaxBytes > 0 && total > opts.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrDownloadTooLarge, total, opts.MaxBytes)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 && resp.StatusCode != http.StatusOK {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download file: %w", err)
+	}
+
+	if !complete {
+		progress := &downloadWriter{
+			w:        f,
+			written:  offset,
+			maxBytes: opts.MaxBytes,
+			report: func(n int64) {
+				if opts.OnProgress != nil {
+					opts.OnProgress(DownloadProgress{URL: target.String(), Path: partPath, Downloaded: n, Total: total})
+				}
+			},
+		}
+		if _, err := io.Copy(progress, resp.Body); err != nil {
+			f.Close()
+			if errors.Is(err, ErrDownloadTooLarge) {
+				os.Remove(partPath)
+			}
+			return nil, fmt.Errorf("download interrupted: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write download file: %w", err)
+	}
+
+	// Checksum the complete file, which covers resumed parts too
+	sum, size, err := fileSHA256(partPath)
+	if err != nil {
+		return nil, err
+	}
+
+	finalPath := uniquePath(filepath.Join(dir, sanitizeFilename(name)))
+	if err := os.Rename(partPath, finalPath); err != nil {
+		return nil, fmt.Errorf("failed to finish download: %w", err)
+	}
+	os.Remove(validatorPath)
+
+	if opts.OnProgress != nil {
+		opts.OnProgress(DownloadProgress{URL: target.String(), Path: finalPath, Downloaded: size, Total: size, Done: true})
+	}
+
+	return &DownloadResult{
+		Path:        finalPath,
+		Filename:    filepath.Base(finalPath),
+		Size:        size,
+		SHA256:      sum,
+		ContentType: resp.Header.Get("Content-Type"),
+		Resumed:     resumed,
+	}, nil
+}
+
+// downloadWriter counts bytes, enforces the size limit and reports progress.
+type downloadWriter struct {
+	w        io.Writer
+	written  int64
+	maxBytes int64
+	report   func(int64)
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if d.maxBytes > 0 && d.written+int64(len(p)) > d.maxBytes {
+		return 0, ErrDownloadTooLarge
+	}
+	n, err := d.w.Write(p)
+	d.written += int64(n)
+	d.report(d.written)
+	return n, err
+}
+
+// fileSHA256 returns the hex SHA-256 and size of a file.
+func fileSHA256(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read download file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read download file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// partFilePath returns the part file of a download of u named name. The hash
+// of the URL keeps downloads of different URLs with the same name, such as
+// /a/report.pdf and /b/report.pdf, from resuming each other.
+func partFilePath(dir, name string, u *url.URL) string {
+	sum := sha256.Sum256([]byte(u.String()))
+	return filepath.Join(dir, sanitizeFilename(name)+"."+hex.EncodeToString(sum[:8])+partSuffix)
+}
+
+// responseValidator returns what to send as If-Range to resume the download
+// of a response: its strong ETag, or else its Last-Modified date.
+func responseValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// parseContentRange parses a Content-Range header, "bytes 100-199/1000" or
+// "bytes */1000", into the first byte and the full size (-1 if unknown).
+// start is -1 for "*".
+func parseContentRange(s string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(s), "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, total, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	size = -1
+	if total != "*" {
+		n, err := strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		size = n
+	}
+	if rng == "*" {
+		return -1, size, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// filenameFromURL derives a file name from the last path segment.
+func filenameFromURL(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || name == "" {
+		name = "download"
+	}
+	return sanitizeFilename(name)
+}
+
+// sanitizeFilename strips directories and characters invalid on common filesystems.
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "download"
+	}
+	return name
+}
+
+// uniquePath returns p, or "name (n).ext" if p already exists.
+func uniquePath(p string) string {
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return p
+	}
+	ext := filepath.Ext(p)
+	stem := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		candidate := stem + " (" + strconv.Itoa(i) + ")" + ext
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
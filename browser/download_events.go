@@ -0,0 +1,152 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// downloadWatcher collects downloads the page starts itself, e.g. from a
+// button that responds with Content-Disposition: attachment. Chrome saves
+// them under their GUID; finished files are renamed to the suggested name.
+type downloadWatcher struct {
+	mu       sync.Mutex
+	active   map[string]*proto.BrowserDownloadWillBegin
+	finished []DownloadResult
+	failed   []string
+}
+
+// watchDownloads routes page-initiated downloads into the download directory
+// and tracks them. Best-effort: remote browsers may refuse the behavior.
+func (b *Browser) watchDownloads(r *rod.Browser) {
+	dir := b.downloadDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		b.log("Download").Warn("Failed to create download directory", "err", err)
+		return
+	}
+
+	w := &downloadWatcher{active: make(map[string]*proto.BrowserDownloadWillBegin)}
+	b.downloads = w
+	if err := b.applyDownloadBehavior(r); err != nil {
+		b.log("Download").Warn("Failed to enable downloads", "err", err)
+		return
+	}
+
+	go r.EachEvent(func(e *proto.BrowserDownloadWillBegin) {
+		w.mu.Lock()
+		w.active[e.GUID] = e
+		w.mu.Unlock()
+		b.log("Download").Debug("Started", "file", e.SuggestedFilename)
+	}, func(e *proto.BrowserDownloadProgress) {
+		b.onDownloadProgress(w, dir, e)
+	})()
+}
+
+// applyDownloadBehavior enables download events for a browser context.
+func (b *Browser) applyDownloadBehavior(r *rod.Browser) error {
+	return proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		BrowserContextID: r.BrowserContextID,
+		DownloadPath:     b.downloadDir(),
+		EventsEnabled:    true,
+	}.Call(r)
+}
+
+// onDownloadProgress reports progress and finalizes completed downloads.
+func (b *Browser) onDownloadProgress(w *downloadWatcher, dir string, e *proto.BrowserDownloadProgress) {
+	w.mu.Lock()
+	begin, ok := w.active[e.GUID]
+	w.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	tmpPath := filepath.Join(dir, e.GUID)
+	report := b.config.OnDownloadProgress
+
+	switch e.State {
+	case proto.BrowserDownloadProgressStateInProgress:
+		if report != nil {
+			total := int64(e.TotalBytes)
+			if total == 0 {
+				total = -1
+			}
+			report(DownloadProgress{URL: begin.URL, Path: tmpPath, Downloaded: int64(e.ReceivedBytes), Total: total})
+		}
+
+	case proto.BrowserDownloadProgressStateCompleted:
+		w.mu.Lock()
+		delete(w.active, e.GUID)
+		w.mu.Unlock()
+
+		result, err := finishDownload(tmpPath, dir, begin.SuggestedFilename)
+		w.mu.Lock()
+		if err != nil {
+			w.failed = append(w.failed, fmt.Sprintf("%s: %v", begin.SuggestedFilename, err))
+		} else {
+			w.finished = append(w.finished, *result)
+		}
+		w.mu.Unlock()
+		if err == nil && report != nil {
+			report(DownloadProgress{URL: begin.URL, Path: result.Path, Downloaded: result.Size, Total: result.Size, Done: true})
+		}
+		b.log("Download").Debug("Finished", "file", begin.SuggestedFilename, "err", err)
+
+	case proto.BrowserDownloadProgressStateCanceled:
+		w.mu.Lock()
+		delete(w.active, e.GUID)
+		w.failed = append(w.failed, begin.SuggestedFilename+": canceled")
+		w.mu.Unlock()
+		os.Remove(tmpPath)
+	}
+}
+
+// finishDownload renames a completed GUID-named file and checksums it.
+func finishDownload(tmpPath, dir, suggested string) (*DownloadResult, error) {
+	sum, size, err := fileSHA256(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	finalPath := uniquePath(filepath.Join(dir, sanitizeFilename(suggested)))
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return nil, fmt.Errorf("failed to finish download: %w", err)
+	}
+	return &DownloadResult{
+		Path:     finalPath,
+		Filename: filepath.Base(finalPath),
+		Size:     size,
+		SHA256:   sum,
+	}, nil
+}
+
+// TakeDownloads returns the page-initiated downloads that finished or failed
+// since the last call, and how many are still in progress.
+func (b *Browser) TakeDownloads() (finished []DownloadResult, failed []string, pending int) {
+	root := b
+	if b.parent != nil {
+		root = b.parent
+	}
+	w := root.downloads
+	if w == nil {
+		return nil, nil, 0
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	finished, failed = w.finished, w.failed
+	w.finished, w.failed = nil, nil
+	return finished, failed, len(w.active)
+}
+
+// downloadDir returns where downloads are saved.
+func (b *Browser) downloadDir() string {
+	if b.config.DownloadDir != "" {
+		return b.config.DownloadDir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".bua", "downloads")
+}
@@ -0,0 +1,64 @@
+package browser
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header      string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 0-0/*", 0, -1, true},
+		{"bytes */1000", -1, 1000, true},
+		{"bytes */*", -1, -1, true},
+		{"", 0, 0, false},
+		{"items 0-9/10", 0, 0, false},
+		{"bytes 100-199", 0, 0, false},
+		{"bytes x-199/1000", 0, 0, false},
+		{"bytes 100-199/many", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseContentRange(tt.header)
+		if ok != tt.ok || (ok && (start != tt.start || size != tt.size)) {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v; want %d, %d, %v", tt.header, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
+
+func TestPartFilePath(t *testing.T) {
+	a, _ := url.Parse("https://example.com/a/report.pdf")
+	b, _ := url.Parse("https://example.com/b/report.pdf")
+	if partFilePath("dl", "report.pdf", a) == partFilePath("dl", "report.pdf", b) {
+		t.Error("downloads of different URLs share a part file")
+	}
+	if partFilePath("dl", "report.pdf", a) != partFilePath("dl", "report.pdf", a) {
+		t.Error("downloads of the same URL do not share a part file")
+	}
+}
+
+func TestResponseValidator(t *testing.T) {
+	tests := []struct {
+		etag, lastModified, want string
+	}{
+		{`"abc"`, "Mon, 02 Jan 2006 15:04:05 GMT", `"abc"`},
+		{`W/"abc"`, "Mon, 02 Jan 2006 15:04:05 GMT", "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.etag != "" {
+			h.Set("ETag", tt.etag)
+		}
+		if tt.lastModified != "" {
+			h.Set("Last-Modified", tt.lastModified)
+		}
+		if got := responseValidator(h); got != tt.want {
+			t.Errorf("responseValidator(%q, %q) = %q, want %q", tt.etag, tt.lastModified, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,812 @@
+package browser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// FakePage is a scripted page served by Fake.
+type FakePage struct {
+	Title string
+
+	// Content is the page text returned by ExtractContent, and searched by
+	// CountElements with the "body" selector.
+	Content string
+
+	// Elements are the interactive elements, looked up by their Index.
+	// Typing into an element updates its Value.
+	Elements []*dom.Element
+
+	// Links maps element indices to the URL clicking the element opens.
+	Links map[int]string
+
+	// Scripts maps JavaScript source to the result EvaluateJS returns.
+	Scripts map[string]string
+
+	// Errors maps JavaScript source to the message of the error EvaluateJS
+	// returns instead, e.g. a SyntaxError.
+	Errors map[string]string
+
+	// Frames are the frames ListFrames returns. SwitchFrame accepts their
+	// paths, and DocumentURL then returns the frame's URL, but the elements
+	// served stay the page's.
+	Frames []FrameInfo
+
+	// Screenshot is returned by screenshot calls. Default: nil, which the
+	// agent treats as a blank page and sends no image.
+	Screenshot []byte
+}
+
+// Fake is an in-memory browser for unit tests. It serves scripted pages by
+// URL, follows canned links on click, and logs every action, so prompts,
+// hooks and tool wiring can be tested without launching Chrome:
+//
+//	fake := browser.NewFake(map[string]*browser.FakePage{
+//		"https://example.com": {
+//			Title:    "Example",
+//			Elements: []*dom.Element{{Index: 0, TagName: "a", Text: "More", IsVisible: true, IsEnabled: true}},
+//			Links:    map[int]string{0: "https://example.com/more"},
+//		},
+//		"https://example.com/more": {Title: "More", Content: "Details"},
+//	})
+//	agent, err := agent.NewBrowserAgent(ctx, cfg, fake)
+//
+// Navigating to a URL without a page fails. Fake has no real page, so
+// ActivePage returns nil.
+type Fake struct {
+	// Pages maps URLs to their pages.
+	Pages map[string]*FakePage
+
+	// Files maps URLs to the content DownloadResource saves.
+	Files map[string][]byte
+
+	mu        sync.Mutex
+	tabs      []*fakeTab
+	active    *fakeTab
+	nextTabID int
+	cookies   []Cookie
+	actions   []string
+}
+
+// fakeTab is an open tab with its navigation history.
+type fakeTab struct {
+	id       string
+	history  []string
+	pos      int
+	isolated bool
+	label    string
+	frame    string // path of the selected frame, or ""
+}
+
+// blankURL is where new tabs start.
+const blankURL = "about:blank"
+
+// NewFake returns a Fake serving pages, with one blank tab open.
+func NewFake(pages map[string]*FakePage) *Fake {
+	f := &Fake{Pages: pages}
+	f.active = f.openTab(false)
+	return f
+}
+
+// Actions returns a log of the actions performed, e.g. `click 3` or
+// `type 2 "hello"`, in order.
+func (f *Fake) Actions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.actions...)
+}
+
+// openTab adds a blank tab. Must be called with f.mu held (or before f is shared).
+func (f *Fake) openTab(isolated bool) *fakeTab {
+	f.nextTabID++
+	tab := &fakeTab{id: fmt.Sprintf("tab%d", f.nextTabID), history: []string{blankURL}, isolated: isolated}
+	f.tabs = append(f.tabs, tab)
+	return tab
+}
+
+// log records an action. Must be called with f.mu held.
+func (f *Fake) log(format string, args ...any) {
+	f.actions = append(f.actions, fmt.Sprintf(format, args...))
+}
+
+// page returns the active tab's page, or an empty page for about:blank.
+// Must be called with f.mu held.
+func (f *Fake) page() *FakePage {
+	if p, ok := f.Pages[f.active.url()]; ok {
+		return p
+	}
+	return &FakePage{}
+}
+
+func (t *fakeTab) url() string {
+	return t.history[t.pos]
+}
+
+// visit navigates tab to url. Must be called with f.mu held.
+func (f *Fake) visit(tab *fakeTab, url string) error {
+	if _, ok := f.Pages[url]; !ok && url != blankURL {
+		return fmt.Errorf("navigation failed: no fake page for %s", url)
+	}
+	tab.history = append(tab.history[:tab.pos+1], url)
+	tab.pos++
+	tab.frame = ""
+	return nil
+}
+
+// element looks up an element in the map the caller acted on.
+func (f *Fake) element(elementIndex int, elementMap *dom.ElementMap) (*dom.Element, error) {
+	if elementMap == nil {
+		return nil, fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
+	}
+	el, ok := elementMap.Get(elementIndex)
+	if !ok {
+		return nil, fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
+	}
+	return el, nil
+}
+
+// GetURL returns the active tab's URL.
+func (f *Fake) GetURL() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.active.url()
+}
+
+// GetTitle returns the active page's title.
+func (f *Fake) GetTitle() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.page().Title
+}
+
+// GetElementMap returns the active page's elements.
+func (f *Fake) GetElementMap(ctx context.Context) (*dom.ElementMap, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.page()
+	em := dom.NewElementMap()
+	em.PageURL = f.active.url()
+	em.PageTitle = p.Title
+	for _, el := range p.Elements {
+		em.Add(el)
+	}
+	return em, nil
+}
+
+// GetFullElementMap returns the active page's elements, like GetElementMap.
+func (f *Fake) GetFullElementMap(ctx context.Context) (*dom.ElementMap, error) {
+	return f.GetElementMap(ctx)
+}
+
+// ExtractContent returns the active page's Content.
+func (f *Fake) ExtractContent(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.page().Content, nil
+}
+
+// EvaluateJS returns the scripted result for script.
+func (f *Fake) EvaluateJS(ctx context.Context, script string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("evaluate %q", script)
+	if msg, ok := f.page().Errors[script]; ok {
+		return "", fmt.Errorf("JS evaluation failed: %s", msg)
+	}
+	result, ok := f.page().Scripts[script]
+	if !ok {
+		return "", fmt.Errorf("JS evaluation failed: no scripted result")
+	}
+	return result, nil
+}
+
+// CountElements counts elements whose Selector or TagName is selector and,
+// if text is set, whose Text contains it. The "body" selector matches the
+// page Content.
+func (f *Fake) CountElements(ctx context.Context, selector, text string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.page()
+	needle := strings.ToLower(text)
+	if selector == "body" {
+		if strings.Contains(strings.ToLower(p.Content), needle) {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	n := 0
+	for _, el := range p.Elements {
+		if el.Selector != selector && el.TagName != selector {
+			continue
+		}
+		if needle == "" || strings.Contains(strings.ToLower(el.Text), needle) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// fakeMatches returns the elements whose Selector or TagName is selector.
+func (f *Fake) fakeMatches(selector string) []*dom.Element {
+	var matches []*dom.Element
+	for _, el := range f.page().Elements {
+		if el.Selector == selector || el.TagName == selector {
+			matches = append(matches, el)
+		}
+	}
+	return matches
+}
+
+// QuerySelector returns the elements whose Selector or TagName is selector.
+func (f *Fake) QuerySelector(ctx context.Context, selector string) ([]SelectorMatch, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matches []SelectorMatch
+	for _, el := range f.fakeMatches(selector) {
+		matches = append(matches, SelectorMatch{TagName: el.TagName, Text: el.Text, Visible: el.IsVisible, BoundingBox: el.BoundingBox})
+	}
+	return matches, len(matches), nil
+}
+
+// ClickSelector clicks the first element whose Selector or TagName is
+// selector, following its link if it has one.
+func (f *Fake) ClickSelector(ctx context.Context, selector string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	matches := f.fakeMatches(selector)
+	if len(matches) == 0 {
+		return fmt.Errorf("%w: no visible match for %q", ErrElementNotFound, selector)
+	}
+	f.log("click_selector %q", selector)
+	if url, ok := f.page().Links[matches[0].Index]; ok {
+		return f.visit(f.active, url)
+	}
+	return nil
+}
+
+// ListFrames returns the active page's Frames.
+func (f *Fake) ListFrames(ctx context.Context) ([]FrameInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]FrameInfo(nil), f.page().Frames...), nil
+}
+
+// SwitchFrame logs the switch if path is "main" or one of the active page's
+// Frames.
+func (f *Fake) SwitchFrame(ctx context.Context, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	found := path == "" || path == "main"
+	for _, frame := range f.page().Frames {
+		found = found || frame.Path == path
+	}
+	if !found {
+		return fmt.Errorf("frame %s not found; list_frames shows the frames", path)
+	}
+	f.log("switch_frame %s", path)
+	f.active.frame = path
+	if path == "main" {
+		f.active.frame = ""
+	}
+	return nil
+}
+
+// DocumentURL returns the URL of the selected frame, as listed in the
+// active page's Frames, or else the page's.
+func (f *Fake) DocumentURL() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active.frame != "" {
+		for _, frame := range f.page().Frames {
+			if frame.Path == f.active.frame {
+				return frame.URL
+			}
+		}
+	}
+	return f.active.url()
+}
+
+// CallCDP logs the command and returns an empty result.
+func (f *Fake) CallCDP(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("cdp_call %s", method)
+	return json.RawMessage("{}"), nil
+}
+
+// WaitStable returns immediately; fake pages are always stable.
+func (f *Fake) WaitStable(ctx context.Context) error {
+	return nil
+}
+
+// ActivePage returns nil; Fake has no real page.
+func (f *Fake) ActivePage() *rod.Page {
+	return nil
+}
+
+// Navigate opens url in the active tab.
+func (f *Fake) Navigate(ctx context.Context, url string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("navigate %s", url)
+	return f.visit(f.active, url)
+}
+
+// GoBack moves back in the active tab's history.
+func (f *Fake) GoBack(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("go_back")
+	if f.active.pos == 0 {
+		return fmt.Errorf("no previous page")
+	}
+	f.active.pos--
+	f.active.frame = ""
+	return nil
+}
+
+// GoForward moves forward in the active tab's history.
+func (f *Fake) GoForward(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("go_forward")
+	if f.active.pos == len(f.active.history)-1 {
+		return fmt.Errorf("no next page")
+	}
+	f.active.pos++
+	f.active.frame = ""
+	return nil
+}
+
+// Reload logs a reload; the page stays the same.
+func (f *Fake) Reload(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("reload")
+	return nil
+}
+
+// Click clicks an element, following its link if it has one.
+func (f *Fake) Click(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	if _, err := f.element(elementIndex, elementMap); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("click %d", elementIndex)
+	if url, ok := f.page().Links[elementIndex]; ok {
+		return f.visit(f.active, url)
+	}
+	return nil
+}
+
+// DoubleClick double-clicks an element, following its link if it has one.
+func (f *Fake) DoubleClick(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	if _, err := f.element(elementIndex, elementMap); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("double_click %d", elementIndex)
+	if url, ok := f.page().Links[elementIndex]; ok {
+		return f.visit(f.active, url)
+	}
+	return nil
+}
+
+// TypeText appends text to an element's Value.
+func (f *Fake) TypeText(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error {
+	el, err := f.element(elementIndex, elementMap)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("type %d %q", elementIndex, text)
+	el.Value += text
+	return nil
+}
+
+// ClearAndType replaces an element's Value with text.
+func (f *Fake) ClearAndType(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error {
+	el, err := f.element(elementIndex, elementMap)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("clear_and_type %d %q", elementIndex, text)
+	el.Value = text
+	return nil
+}
+
+// Hover logs a hover over an element.
+func (f *Fake) Hover(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	return f.act("hover", elementIndex, elementMap)
+}
+
+// Focus logs focusing an element.
+func (f *Fake) Focus(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	return f.act("focus", elementIndex, elementMap)
+}
+
+// ScrollToElement logs scrolling an element into view.
+func (f *Fake) ScrollToElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	return f.act("scroll_to_element", elementIndex, elementMap)
+}
+
+// ElementDetails describes an element from its fields: its Href, Type,
+// Placeholder and Value as attributes, and its Role and AriaLabel
+// as ARIA properties.
+func (f *Fake) ElementDetails(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) (*ElementDetails, error) {
+	el, err := f.element(elementIndex, elementMap)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	details := &ElementDetails{
+		TagName:     el.TagName,
+		Text:        el.Text,
+		Attributes:  make(map[string]string),
+		ARIA:        make(map[string]string),
+		Visibility:  ElementVisibility{Display: "block", Visibility: "visible", Opacity: 1, PointerEvents: "auto", InViewport: el.IsVisible},
+		BoundingBox: el.BoundingBox,
+	}
+	for name, value := range map[string]string{"href": el.Href, "type": el.Type, "placeholder": el.Placeholder, "value": el.Value} {
+		if value != "" {
+			details.Attributes[name] = value
+		}
+	}
+	if el.Role != "" {
+		details.ARIA["role"] = el.Role
+	}
+	if el.AriaLabel != "" {
+		details.ARIA["aria-label"] = el.AriaLabel
+	}
+	details.ARIA["disabled"] = fmt.Sprint(!el.IsEnabled)
+	return details, nil
+}
+
+// act logs an action on an element that has no other effect.
+func (f *Fake) act(action string, elementIndex int, elementMap *dom.ElementMap) error {
+	if _, err := f.element(elementIndex, elementMap); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("%s %d", action, elementIndex)
+	return nil
+}
+
+// Scroll logs a scroll of the page or an element.
+func (f *Fake) Scroll(ctx context.Context, direction string, amount float64, elementIndex *int, elementMap *dom.ElementMap) error {
+	if elementIndex != nil {
+		if _, err := f.element(*elementIndex, elementMap); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("scroll %s %g", direction, amount)
+	return nil
+}
+
+// SendKeys logs keys sent to the page.
+func (f *Fake) SendKeys(ctx context.Context, keys string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("send_keys %s", keys)
+	return nil
+}
+
+// Screenshot returns the active page's Screenshot.
+func (f *Fake) Screenshot(ctx context.Context, fullPage bool) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data := f.page().Screenshot
+	if len(data) == 0 {
+		return nil, fmt.Errorf("page has no screenshot")
+	}
+	return data, nil
+}
+
+// ScreenshotFullQuality returns the active page's Screenshot.
+func (f *Fake) ScreenshotFullQuality(ctx context.Context) ([]byte, error) {
+	return f.Screenshot(ctx, false)
+}
+
+// ScreenshotElement returns the active page's Screenshot if the element
+// exists.
+func (f *Fake) ScreenshotElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) ([]byte, error) {
+	if _, err := f.element(elementIndex, elementMap); err != nil {
+		return nil, err
+	}
+	return f.Screenshot(ctx, false)
+}
+
+// ScreenshotSafe returns the active page's Screenshot, or nil if it has none.
+func (f *Fake) ScreenshotSafe(ctx context.Context, fullPage bool) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.page().Screenshot, nil
+}
+
+// ScreenshotSafeWithAnnotations returns the active page's Screenshot as is.
+func (f *Fake) ScreenshotSafeWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error) {
+	return f.ScreenshotSafe(ctx, false)
+}
+
+// ScreenshotAfterAction returns the active page's Screenshot, or nil if it has none.
+func (f *Fake) ScreenshotAfterAction(ctx context.Context) ([]byte, error) {
+	return f.ScreenshotSafe(ctx, false)
+}
+
+// ScreenshotAfterActionWithAnnotations returns the active page's Screenshot as is.
+func (f *Fake) ScreenshotAfterActionWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error) {
+	return f.ScreenshotSafe(ctx, false)
+}
+
+// AnnotateScreenshot returns data as is.
+func (f *Fake) AnnotateScreenshot(data []byte, elementMap *dom.ElementMap) ([]byte, error) {
+	return data, nil
+}
+
+// NewTab opens a tab, navigates it to url if set, and makes it active.
+func (f *Fake) NewTab(ctx context.Context, url string) (string, error) {
+	return f.newTab(url, false)
+}
+
+// NewIsolatedTab opens a tab like NewTab, marked as isolated.
+func (f *Fake) NewIsolatedTab(ctx context.Context, url string) (string, error) {
+	return f.newTab(url, true)
+}
+
+func (f *Fake) newTab(url string, isolated bool) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.log("new_tab %s", url)
+	tab := f.openTab(isolated)
+	if url != "" {
+		if err := f.visit(tab, url); err != nil {
+			f.tabs = f.tabs[:len(f.tabs)-1]
+			return "", err
+		}
+	}
+	f.active = tab
+	return tab.id, nil
+}
+
+// SwitchTab makes a tab active.
+func (f *Fake) SwitchTab(tabID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tab := range f.tabs {
+		if tab.id == tabID {
+			f.log("switch_tab %s", tabID)
+			f.active = tab
+			return nil
+		}
+	}
+	return fmt.Errorf("tab not found: %s", tabID)
+}
+
+// LabelTab sets the label ListTabs reports for a tab.
+func (f *Fake) LabelTab(tabID, label string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tab := range f.tabs {
+		if tab.id == tabID {
+			tab.label = label
+			return nil
+		}
+	}
+	return fmt.Errorf("tab not found: %s", tabID)
+}
+
+// CloseTab closes a tab. The last tab can't be closed.
+func (f *Fake) CloseTab(tabID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, tab := range f.tabs {
+		if tab.id != tabID {
+			continue
+		}
+		if len(f.tabs) <= 1 {
+			return fmt.Errorf("cannot close the last tab")
+		}
+		f.log("close_tab %s", tabID)
+		f.tabs = append(f.tabs[:i], f.tabs[i+1:]...)
+		if f.active == tab {
+			f.active = f.tabs[0]
+		}
+		return nil
+	}
+	return fmt.Errorf("tab not found: %s", tabID)
+}
+
+// ListTabs returns the open tabs in the order they were opened.
+func (f *Fake) ListTabs() []TabInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tabs := make([]TabInfo, 0, len(f.tabs))
+	for _, tab := range f.tabs {
+		title := ""
+		if p, ok := f.Pages[tab.url()]; ok {
+			title = p.Title
+		}
+		tabs = append(tabs, TabInfo{
+			ID:       tab.id,
+			URL:      tab.url(),
+			Title:    title,
+			Active:   tab == f.active,
+			Isolated: tab.isolated,
+			Label:    tab.label,
+		})
+	}
+	return tabs
+}
+
+// CaptureAllTabs returns the Screenshot and Elements of each tab's page.
+func (f *Fake) CaptureAllTabs(ctx context.Context) ([]TabSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	snapshots := make([]TabSnapshot, 0, len(f.tabs))
+	for _, tab := range f.tabs {
+		s := TabSnapshot{TabID: tab.id, URL: tab.url(), Active: tab == f.active, ElementMap: dom.NewElementMap()}
+		s.ElementMap.PageURL = s.URL
+		if p, ok := f.Pages[tab.url()]; ok {
+			s.Title, s.Screenshot = p.Title, p.Screenshot
+			s.ElementMap.PageTitle = p.Title
+			for _, el := range p.Elements {
+				s.ElementMap.Add(el)
+			}
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+// GetCookies returns the cookies set with SetCookies.
+func (f *Fake) GetCookies(ctx context.Context) ([]Cookie, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Cookie(nil), f.cookies...), nil
+}
+
+// SetCookies stores cookies, replacing any with the same name, domain and path.
+func (f *Fake) SetCookies(ctx context.Context, cookies []Cookie) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, c := range cookies {
+		replaced := false
+		for i, existing := range f.cookies {
+			if existing.Name == c.Name && existing.Domain == c.Domain && existing.Path == c.Path {
+				f.cookies[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			f.cookies = append(f.cookies, c)
+		}
+	}
+	return nil
+}
+
+// DownloadFile downloads the URL an element links to (its Href).
+func (f *Fake) DownloadFile(ctx context.Context, elementIndex int, elementMap *dom.ElementMap, opts DownloadOptions) (*DownloadResult, error) {
+	el, err := f.element(elementIndex, elementMap)
+	if err != nil {
+		return nil, err
+	}
+	if el.Href == "" {
+		return nil, fmt.Errorf("element %d has no link to download", elementIndex)
+	}
+	return f.DownloadResource(ctx, el.Href, opts)
+}
+
+// DownloadResource saves the content Files holds for rawURL into opts.Dir.
+func (f *Fake) DownloadResource(ctx context.Context, rawURL string, opts DownloadOptions) (*DownloadResult, error) {
+	f.mu.Lock()
+	f.log("download %s", rawURL)
+	data, ok := f.Files[rawURL]
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("download failed: no fake file for %s", rawURL)
+	}
+	if opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes {
+		return nil, ErrDownloadTooLarge
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	filename := opts.Filename
+	if filename == "" {
+		filename = path.Base(rawURL)
+	}
+	target := filepath.Join(dir, filename)
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save download: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return &DownloadResult{
+		Path:     target,
+		Filename: filename,
+		Size:     int64(len(data)),
+		SHA256:   hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// TakeDownloads reports no page-initiated downloads.
+func (f *Fake) TakeDownloads() (finished []DownloadResult, failed []string, pending int) {
+	return nil, nil, 0
+}
+
+// TakeClosedTabs reports no tabs: the fake has no tab limit.
+func (f *Fake) TakeClosedTabs() []TabInfo {
+	return nil
+}
+
+var _ Interface = (*Fake)(nil)
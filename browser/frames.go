@@ -0,0 +1,280 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+const (
+	// maxFrames caps the frames ListFrames reports.
+	maxFrames = 30
+
+	// maxFrameDepth is how deep ListFrames looks into nested frames.
+	maxFrameDepth = 3
+)
+
+// frameSelector matches the elements that hold frames.
+const frameSelector = "iframe, frame"
+
+// frameInfoJS describes a frame element.
+const frameInfoJS = `function () {
+	const r = this.getBoundingClientRect();
+	const style = getComputedStyle(this);
+	return {
+		name: this.name || this.id || '',
+		title: this.title || this.getAttribute('aria-label') || '',
+		url: this.src || '',
+		visible: r.width > 0 && r.height > 0 && style.visibility !== 'hidden' && style.display !== 'none',
+		boundingBox: { x: r.x, y: r.y, width: r.width, height: r.height }
+	};
+}`
+
+// frameOriginJS returns where a frame element's document starts in the
+// viewport of the document holding it, or null if it is no longer there.
+const frameOriginJS = `function () {
+	if (!this.isConnected) return null;
+	const r = this.getBoundingClientRect();
+	const style = getComputedStyle(this);
+	return {
+		x: r.x + this.clientLeft + parseFloat(style.paddingLeft),
+		y: r.y + this.clientTop + parseFloat(style.paddingTop)
+	};
+}`
+
+// FrameInfo describes a frame of the page, for SwitchFrame.
+type FrameInfo struct {
+	// Path locates the frame: "0" is the first frame of the page, "1.0"
+	// the first frame inside the second.
+	Path        string          `json:"path"`
+	Name        string          `json:"name,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	URL         string          `json:"url"`
+	Visible     bool            `json:"visible"`
+	BoundingBox dom.BoundingBox `json:"boundingBox"`
+
+	// Active is set on the frame element maps are currently read from.
+	Active bool `json:"active,omitempty"`
+}
+
+// frameContext is a frame selected with SwitchFrame.
+type frameContext struct {
+	path string
+
+	// chain holds the frame elements from the page's down to this frame's
+	chain []*rod.Element
+
+	// page is the frame's document
+	page *rod.Page
+}
+
+// origin returns where the frame's document starts in the page's viewport,
+// or false if the frame is gone.
+func (fc *frameContext) origin() (x, y float64, ok bool) {
+	for _, el := range fc.chain {
+		res, err := el.Eval(frameOriginJS)
+		if err != nil || res.Value.Nil() {
+			return 0, 0, false
+		}
+		x += res.Value.Get("x").Num()
+		y += res.Value.Get("y").Num()
+	}
+	return x, y, true
+}
+
+// activeFrame returns the frame selected in the active tab, or nil if there
+// is none. A frame that is gone, e.g. after the page navigated, is dropped.
+func (b *Browser) activeFrame() (*frameContext, float64, float64) {
+	b.mu.RLock()
+	tabID := b.activeTabID
+	fc := b.frames[tabID]
+	b.mu.RUnlock()
+	if fc == nil {
+		return nil, 0, 0
+	}
+
+	x, y, ok := fc.origin()
+	if !ok {
+		b.log("Browser").Debug("Selected frame is gone; back to the page", "frame", fc.path)
+		b.mu.Lock()
+		if b.frames[tabID] == fc {
+			delete(b.frames, tabID)
+		}
+		b.mu.Unlock()
+		return nil, 0, 0
+	}
+	return fc, x, y
+}
+
+// documentPage returns the document of the active tab that elements are
+// read from: the selected frame's, or else the page's. dx and dy are where
+// that document starts in the page's viewport.
+func (b *Browser) documentPage() (page *rod.Page, dx, dy float64) {
+	if fc, x, y := b.activeFrame(); fc != nil {
+		return fc.page, x, y
+	}
+	return b.ActivePage(), 0, 0
+}
+
+// DocumentURL returns the URL of the document EvaluateJS runs in: the
+// selected frame's, or else the page's. It returns "" if the frame's URL
+// cannot be read.
+func (b *Browser) DocumentURL() string {
+	fc, _, _ := b.activeFrame()
+	if fc == nil {
+		return b.GetURL()
+	}
+	// A frame's document may run in its page's target, whose info is the
+	// page's, so ask the document itself
+	res, err := fc.page.Eval(`() => location.href`)
+	if err != nil {
+		return ""
+	}
+	return res.Value.Str()
+}
+
+// frameDocument returns the document inside a frame element.
+func (b *Browser) frameDocument(el *rod.Element) (*rod.Page, error) {
+	node, err := el.Describe(0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// A frame of another site runs in its own process, as its own target
+	if node.FrameID != "" {
+		if targets, err := (proto.TargetGetTargets{}).Call(b.rod); err == nil {
+			for _, t := range targets.TargetInfos {
+				if t.Type == "iframe" && string(t.TargetID) == string(node.FrameID) {
+					return b.rod.PageFromTarget(t.TargetID)
+				}
+			}
+		}
+	}
+	return el.Frame()
+}
+
+// ListFrames returns the frames of the active tab's page, including frames
+// nested in them, up to 30.
+func (b *Browser) ListFrames(ctx context.Context) ([]FrameInfo, error) {
+	_, end := b.startSpan(ctx, "browser.list_frames")
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+	active := ""
+	if fc, _, _ := b.activeFrame(); fc != nil {
+		active = fc.path
+	}
+
+	var frames []FrameInfo
+	var walk func(doc *rod.Page, prefix string, dx, dy float64, depth int)
+	walk = func(doc *rod.Page, prefix string, dx, dy float64, depth int) {
+		els, err := doc.Elements(frameSelector)
+		if err != nil {
+			return
+		}
+		for i, el := range els {
+			if len(frames) >= maxFrames {
+				return
+			}
+			res, err := el.Eval(frameInfoJS)
+			if err != nil {
+				continue
+			}
+			info := FrameInfo{
+				Path:    prefix + strconv.Itoa(i),
+				Name:    res.Value.Get("name").Str(),
+				Title:   res.Value.Get("title").Str(),
+				URL:     res.Value.Get("url").Str(),
+				Visible: res.Value.Get("visible").Bool(),
+				BoundingBox: dom.BoundingBox{
+					X:      res.Value.Get("boundingBox.x").Num() + dx,
+					Y:      res.Value.Get("boundingBox.y").Num() + dy,
+					Width:  res.Value.Get("boundingBox.width").Num(),
+					Height: res.Value.Get("boundingBox.height").Num(),
+				},
+			}
+			info.Active = info.Path == active
+			frames = append(frames, info)
+
+			if depth+1 >= maxFrameDepth {
+				continue
+			}
+			inner, err := b.frameDocument(el)
+			if err != nil {
+				continue
+			}
+			origin, err := el.Eval(frameOriginJS)
+			if err != nil || origin.Value.Nil() {
+				continue
+			}
+			walk(inner, info.Path+".", dx+origin.Value.Get("x").Num(), dy+origin.Value.Get("y").Num(), depth+1)
+		}
+	}
+	walk(page, "", 0, 0, 0)
+	return frames, nil
+}
+
+// SwitchFrame makes element maps, content extraction and selector lookups
+// of the active tab read the frame at path (see FrameInfo.Path), until the
+// frame goes away or SwitchFrame is called with "" or "main" to return to
+// the page. Actions on the frame's elements work as on the page's.
+func (b *Browser) SwitchFrame(ctx context.Context, path string) error {
+	_, end := b.startSpan(ctx, "browser.switch_frame", attribute.String("bua.frame", path))
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return fmt.Errorf("no active page")
+	}
+
+	b.mu.RLock()
+	tabID := b.activeTabID
+	b.mu.RUnlock()
+
+	path = strings.TrimSpace(path)
+	if path == "" || path == "main" {
+		b.mu.Lock()
+		delete(b.frames, tabID)
+		b.mu.Unlock()
+		return nil
+	}
+
+	fc := &frameContext{path: path, page: page}
+	for _, part := range strings.Split(path, ".") {
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 {
+			return fmt.Errorf("invalid frame path %q", path)
+		}
+		els, err := fc.page.Elements(frameSelector)
+		if err != nil {
+			return fmt.Errorf("failed to list frames: %w", err)
+		}
+		if i >= len(els) {
+			return fmt.Errorf("frame %s not found; list_frames shows the frames", path)
+		}
+		doc, err := b.frameDocument(els[i])
+		if err != nil {
+			return fmt.Errorf("failed to enter frame %s: %w", path, err)
+		}
+		fc.chain = append(fc.chain, els[i])
+		fc.page = doc
+	}
+
+	b.mu.Lock()
+	if b.frames == nil {
+		b.frames = make(map[string]*frameContext)
+	}
+	b.frames[tabID] = fc
+	b.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// highlightColors tell concurrent highlights apart, e.g. the source and
+// target of a drag.
+var highlightColors = []string{"255,107,107", "77,150,255", "81,207,102", "255,183,3"}
+
+// highlightJS draws one overlay per box and registers it in the page's
+// window.__buaHighlights, so clearHighlightsJS removes exactly the overlays
+// bua drew. Overlays hang off the document element rather than the body,
+// which single-page apps often replace on route changes.
+const highlightJS = `(boxes, colors, ms) => {
+	const registry = window.__buaHighlights || (window.__buaHighlights = new Set());
+	boxes.forEach((box, i) => {
+		const color = colors[i % colors.length];
+		const overlay = document.createElement('div');
+		overlay.className = 'bua-highlight';
+		overlay.style.cssText = 'position:fixed;pointer-events:none;z-index:2147483647;box-sizing:border-box;' +
+			'border:3px solid rgb(' + color + ');background:rgba(' + color + ',0.2);' +
+			'left:' + box.x + 'px;top:' + box.y + 'px;width:' + box.width + 'px;height:' + box.height + 'px;' +
+			'transition:opacity 0.2s;';
+		document.documentElement.appendChild(overlay);
+		registry.add(overlay);
+		setTimeout(() => {
+			overlay.style.opacity = '0';
+			setTimeout(() => {
+				overlay.remove();
+				registry.delete(overlay);
+			}, 200);
+		}, ms);
+	});
+}`
+
+// clearHighlightsJS removes the overlays highlightJS drew and nothing else.
+const clearHighlightsJS = `() => {
+	const registry = window.__buaHighlights;
+	if (!registry) return;
+	for (const overlay of registry) overlay.remove();
+	registry.clear();
+}`
+
+// highlightBox is an element's position, as highlightJS takes it.
+type highlightBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Highlight shows the elements at indices together, each in its own color,
+// for HighlightDuration. Use it for actions that involve several elements,
+// e.g. the source and target of a drag.
+func (b *Browser) Highlight(ctx context.Context, elementMap *dom.ElementMap, indices ...int) error {
+	elements := make([]*dom.Element, 0, len(indices))
+	for _, i := range indices {
+		element, ok := elementMap.Get(i)
+		if !ok {
+			return fmt.Errorf("%w: index %d", ErrElementNotFound, i)
+		}
+		elements = append(elements, element)
+	}
+	b.highlightElements(ctx, elements...)
+	return nil
+}
+
+// highlightElements shows a visual highlight on elements and waits while it
+// is shown.
+func (b *Browser) highlightElements(ctx context.Context, elements ...*dom.Element) {
+	page := b.ActivePage()
+	if page == nil || len(elements) == 0 {
+		return
+	}
+
+	boxes := make([]highlightBox, len(elements))
+	for i, e := range elements {
+		boxes[i] = highlightBox{X: e.BoundingBox.X, Y: e.BoundingBox.Y, Width: e.BoundingBox.Width, Height: e.BoundingBox.Height}
+	}
+	visible := b.config.HighlightDuration - 200*time.Millisecond
+	if _, err := page.Eval(highlightJS, boxes, highlightColors, max(visible, 0).Milliseconds()); err != nil {
+		b.log("Highlight").Debug("Failed to draw highlight", "err", err)
+		return
+	}
+	time.Sleep(b.config.HighlightDuration)
+}
+
+// clearHighlights removes any highlight still shown on page, so it does not
+// end up in a screenshot.
+func (b *Browser) clearHighlights(page *rod.Page) {
+	if !b.config.ShowHighlight {
+		return
+	}
+	_, _ = page.Eval(clearHighlightsJS)
+}
@@ -0,0 +1,266 @@
+package browser
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// HumanizeConfig configures human-like input: curved mouse paths with
+// variable speed, per-character typing with occasional corrected typos, and
+// random micro-pauses. It makes actions slower but much harder to tell apart
+// from a person on sites with strict bot detection.
+type HumanizeConfig struct {
+	// Enabled turns humanized input on.
+	Enabled bool
+
+	// MouseSpeed is the average cursor speed in pixels per second. Default: 1000.
+	MouseSpeed float64
+
+	// TypingDelay is the average delay between keystrokes. Default: 90ms.
+	TypingDelay time.Duration
+
+	// TypoRate is the chance per character of typing a wrong key and
+	// correcting it with Backspace. Default: 0.02; negative for no typos.
+	TypoRate float64
+
+	// PauseChance is the chance of a short hesitation before an action or
+	// between keystrokes. Default: 0.05; negative for no pauses.
+	PauseChance float64
+}
+
+// DefaultHumanizeConfig returns enabled humanization with sensible defaults.
+func DefaultHumanizeConfig() HumanizeConfig {
+	return HumanizeConfig{
+		Enabled:     true,
+		MouseSpeed:  1000,
+		TypingDelay: 90 * time.Millisecond,
+		TypoRate:    0.02,
+		PauseChance: 0.05,
+	}
+}
+
+// withDefaults fills zero values with defaults and turns negative rates off.
+func (h HumanizeConfig) withDefaults() HumanizeConfig {
+	d := DefaultHumanizeConfig()
+	if h.MouseSpeed <= 0 {
+		h.MouseSpeed = d.MouseSpeed
+	}
+	if h.TypingDelay <= 0 {
+		h.TypingDelay = d.TypingDelay
+	}
+	switch {
+	case h.TypoRate == 0:
+		h.TypoRate = d.TypoRate
+	case h.TypoRate < 0:
+		h.TypoRate = 0
+	}
+	switch {
+	case h.PauseChance == 0:
+		h.PauseChance = d.PauseChance
+	case h.PauseChance < 0:
+		h.PauseChance = 0
+	}
+	return h
+}
+
+// moveMouse moves the cursor to target. With humanization it follows a
+// bezier curve at variable speed, sometimes overshooting and correcting;
+// otherwise it moves in a straight line of the given number of steps.
+// Humanized moves stop early if ctx is done.
+func (b *Browser) moveMouse(ctx context.Context, page *rod.Page, target proto.Point, steps int) error {
+	if !b.config.Humanize.Enabled {
+		if steps <= 1 {
+			return page.Mouse.MoveTo(target)
+		}
+		if err := page.Mouse.MoveLinear(target, steps); err != nil {
+			return page.Mouse.MoveTo(target)
+		}
+		return nil
+	}
+
+	h := b.config.Humanize.withDefaults()
+	if err := maybePause(ctx, h); err != nil {
+		return err
+	}
+
+	start := page.Mouse.Position()
+	dist := math.Hypot(target.X-start.X, target.Y-start.Y)
+
+	// Overshoot long moves now and then, like a hand that stops late
+	if dist > 200 && rand.Float64() < 0.2 {
+		over := proto.Point{
+			X: target.X + (target.X-start.X)/dist*(5+rand.Float64()*15),
+			Y: target.Y + (target.Y-start.Y)/dist*(5+rand.Float64()*15),
+		}
+		if err := b.moveAlongCurve(ctx, page, start, over, h); err != nil {
+			return err
+		}
+		start = over
+	}
+
+	return b.moveAlongCurve(ctx, page, start, target, h)
+}
+
+// moveAlongCurve moves the cursor along a cubic bezier curve with ease-in-out timing.
+func (b *Browser) moveAlongCurve(ctx context.Context, page *rod.Page, from, to proto.Point, h HumanizeConfig) error {
+	dist := math.Hypot(to.X-from.X, to.Y-from.Y)
+	if dist < 1 {
+		return page.Mouse.MoveTo(to)
+	}
+
+	// Control points offset perpendicular to the straight path
+	nx, ny := -(to.Y-from.Y)/dist, (to.X-from.X)/dist
+	spread := math.Min(dist*0.3, 120)
+	c1 := proto.Point{
+		X: from.X + (to.X-from.X)*0.3 + nx*spread*(rand.Float64()*2-1),
+		Y: from.Y + (to.Y-from.Y)*0.3 + ny*spread*(rand.Float64()*2-1),
+	}
+	c2 := proto.Point{
+		X: from.X + (to.X-from.X)*0.7 + nx*spread*(rand.Float64()*2-1),
+		Y: from.Y + (to.Y-from.Y)*0.7 + ny*spread*(rand.Float64()*2-1),
+	}
+
+	steps := int(math.Max(8, math.Min(dist/12, 60)))
+	duration := time.Duration(dist/h.MouseSpeed*float64(time.Second)) * time.Duration(80+rand.Intn(50)) / 100
+	stepDelay := duration / time.Duration(steps)
+
+	for i := 1; i <= steps; i++ {
+		t := easeInOut(float64(i) / float64(steps))
+		p := bezier(from, c1, c2, to, t)
+		if i == steps {
+			p = to
+		}
+		if err := page.Mouse.MoveTo(p); err != nil {
+			return err
+		}
+		if err := sleep(ctx, stepDelay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeText types text into the focused element. With humanization each
+// character is typed with a variable delay, occasional typos are corrected
+// with Backspace, and word boundaries get slightly longer pauses. It stops
+// early if ctx is done.
+func (b *Browser) typeText(ctx context.Context, page *rod.Page, text string) error {
+	h := b.config.Humanize.withDefaults()
+
+	for _, char := range text {
+		if h.TypoRate > 0 && rand.Float64() < h.TypoRate {
+			if typo, ok := neighborKey(char); ok {
+				if err := page.InsertText(string(typo)); err != nil {
+					return err
+				}
+				if err := sleep(ctx, keyDelay(h)*2); err != nil {
+					return err
+				}
+				if err := page.Keyboard.Type(input.Backspace); err != nil {
+					return err
+				}
+				if err := sleep(ctx, keyDelay(h)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := page.InsertText(string(char)); err != nil {
+			return err
+		}
+
+		delay := keyDelay(h)
+		if char == ' ' || char == '.' || char == ',' {
+			delay += delay / 2
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		if err := maybePause(ctx, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maybePause hesitates for a moment with probability PauseChance.
+func maybePause(ctx context.Context, h HumanizeConfig) error {
+	if h.PauseChance > 0 && rand.Float64() < h.PauseChance {
+		return sleep(ctx, time.Duration(200+rand.Intn(600))*time.Millisecond)
+	}
+	return nil
+}
+
+// sleep waits for d, or returns ctx's error if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// keyDelay returns a keystroke delay around the configured average.
+func keyDelay(h HumanizeConfig) time.Duration {
+	// Log-normal-ish spread: mostly near the mean, occasionally much slower
+	factor := math.Exp(rand.NormFloat64() * 0.35)
+	return time.Duration(float64(h.TypingDelay) * factor)
+}
+
+// easeInOut accelerates at the start of a move and decelerates at the end.
+func easeInOut(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// bezier evaluates a cubic bezier curve at t.
+func bezier(p0, p1, p2, p3 proto.Point, t float64) proto.Point {
+	u := 1 - t
+	a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	return proto.Point{
+		X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+		Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+	}
+}
+
+// keyboardRows is a QWERTY layout used to pick plausible typos.
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// neighborKey returns a key next to char on a QWERTY keyboard.
+func neighborKey(char rune) (rune, bool) {
+	lower := char
+	upper := false
+	if char >= 'A' && char <= 'Z' {
+		lower = char + ('a' - 'A')
+		upper = true
+	}
+
+	for _, row := range keyboardRows {
+		for i, k := range row {
+			if k != lower {
+				continue
+			}
+			var candidates []rune
+			if i > 0 {
+				candidates = append(candidates, rune(row[i-1]))
+			}
+			if i < len(row)-1 {
+				candidates = append(candidates, rune(row[i+1]))
+			}
+			typo := candidates[rand.Intn(len(candidates))]
+			if upper {
+				typo -= 'a' - 'A'
+			}
+			return typo, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHumanizeWithDefaults(t *testing.T) {
+	d := DefaultHumanizeConfig()
+
+	h := HumanizeConfig{Enabled: true}.withDefaults()
+	if h.TypoRate != d.TypoRate || h.PauseChance != d.PauseChance {
+		t.Errorf("zero rates = %v/%v, want defaults %v/%v", h.TypoRate, h.PauseChance, d.TypoRate, d.PauseChance)
+	}
+
+	h = HumanizeConfig{Enabled: true, TypoRate: -1, PauseChance: -1}.withDefaults()
+	if h.TypoRate != 0 || h.PauseChance != 0 {
+		t.Errorf("negative rates = %v/%v, want off", h.TypoRate, h.PauseChance)
+	}
+
+	h = HumanizeConfig{Enabled: true, TypoRate: 0.1, PauseChance: 0.2}.withDefaults()
+	if h.TypoRate != 0.1 || h.PauseChance != 0.2 {
+		t.Errorf("set rates = %v/%v, want kept", h.TypoRate, h.PauseChance)
+	}
+}
+
+func TestSleepStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("sleep did not return when ctx was canceled")
+	}
+}
@@ -0,0 +1,180 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/go-rod/rod/lib/launcher"
+
+	"github.com/anxuanzi/bua/logging"
+)
+
+// DefaultRevision is the Chromium revision downloaded when none is pinned.
+const DefaultRevision = launcher.RevisionDefault
+
+// InstallProgress reports the state of a Chromium download.
+type InstallProgress struct {
+	Revision   int    `json:"revision"`
+	URL        string `json:"url"`
+	Downloaded int64  `json:"downloaded"` // Bytes received so far
+	Total      int64  `json:"total"`      // Total bytes, or -1 if unknown
+	Done       bool   `json:"done"`
+}
+
+// InstallOptions configures Install.
+type InstallOptions struct {
+	// Revision is the Chromium revision to install. Default: DefaultRevision.
+	Revision int
+
+	// Dir is the root directory for downloaded browsers.
+	// Default: rod's cache directory (~/.cache/rod/browser on Linux).
+	Dir string
+
+	// OnProgress is called as the download advances (optional).
+	OnProgress func(InstallProgress)
+
+	// HTTPClient is used for the download (optional), e.g. to set a proxy.
+	HTTPClient *http.Client
+
+	// Debug enables verbose logging.
+	Debug bool
+
+	// Logger receives progress logs (nil = console output with Debug).
+	Logger *slog.Logger
+}
+
+// Install returns the path to the pinned Chromium revision, downloading it
+// first if it is missing or broken. Installs are shared across processes
+// through a lock port, so concurrent calls download only once.
+func Install(ctx context.Context, opts InstallOptions) (string, error) {
+	if opts.Revision <= 0 {
+		opts.Revision = DefaultRevision
+	}
+
+	lb := launcher.NewBrowser()
+	lb.Context = ctx
+	lb.Revision = opts.Revision
+	if opts.Dir != "" {
+		lb.RootDir = opts.Dir
+	}
+	if !opts.Debug {
+		lb.Logger = discardLogger{}
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	if opts.OnProgress != nil {
+		wrapped := *client
+		wrapped.Transport = &progressTransport{
+			base:       client.Transport,
+			revision:   opts.Revision,
+			onProgress: opts.OnProgress,
+		}
+		client = &wrapped
+	}
+	lb.HTTPClient = client
+
+	logging.Component(logging.Or(opts.Logger, opts.Debug), "Browser").
+		Debug("Ensuring Chromium", "revision", opts.Revision, "dir", lb.Dir())
+
+	path, err := lb.Get()
+	if err != nil {
+		return "", fmt.Errorf("failed to install Chromium r%d: %w", opts.Revision, err)
+	}
+	return path, nil
+}
+
+// resolveBinary returns the browser executable configured by BrowserPath or
+// BrowserRevision, or "" to let the launcher find one.
+func (b *Browser) resolveBinary(ctx context.Context) (string, error) {
+	if b.config.BrowserPath != "" {
+		if _, err := os.Stat(b.config.BrowserPath); err != nil {
+			return "", fmt.Errorf("browser binary not found at %s: %w", b.config.BrowserPath, err)
+		}
+		return b.config.BrowserPath, nil
+	}
+
+	if b.config.BrowserRevision > 0 {
+		return Install(ctx, InstallOptions{
+			Revision:   b.config.BrowserRevision,
+			Dir:        b.config.BrowserDir,
+			OnProgress: b.config.OnInstallProgress,
+			Debug:      b.config.Debug,
+			Logger:     b.logger,
+		})
+	}
+
+	return "", nil
+}
+
+// progressTransport reports download progress for every response body it reads.
+type progressTransport struct {
+	base       http.RoundTripper
+	revision   int
+	onProgress func(InstallProgress)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *progressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet {
+		return resp, err
+	}
+
+	resp.Body = &progressReader{
+		ReadCloser: resp.Body,
+		progress: InstallProgress{
+			Revision: t.revision,
+			URL:      req.URL.String(),
+			Total:    resp.ContentLength,
+		},
+		onProgress: t.onProgress,
+	}
+	return resp, nil
+}
+
+// progressReader counts bytes as they are read.
+type progressReader struct {
+	io.ReadCloser
+	progress   InstallProgress
+	read       int64
+	lastReport int64
+	onProgress func(InstallProgress)
+}
+
+// progressStep is the minimum number of bytes between progress reports.
+const progressStep = 1 << 20
+
+// Read implements io.Reader.
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+
+	if err == io.EOF {
+		r.progress.Downloaded = r.read
+		r.progress.Done = true
+		r.onProgress(r.progress)
+	} else if r.read-r.lastReport >= progressStep {
+		r.lastReport = r.read
+		r.progress.Downloaded = r.read
+		r.onProgress(r.progress)
+	}
+	return n, err
+}
+
+// discardLogger silences the launcher's download log.
+type discardLogger struct{}
+
+// Println implements utils.Logger.
+func (discardLogger) Println(...interface{}) {}
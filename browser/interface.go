@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-rod/rod"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// Interface is the browser as the agent and its tools use it. *Browser
+// implements it on a real Chrome; Fake implements it in memory for tests.
+type Interface interface {
+	// Page state
+	GetURL() string
+	GetTitle() string
+	GetElementMap(ctx context.Context) (*dom.ElementMap, error)
+	GetFullElementMap(ctx context.Context) (*dom.ElementMap, error)
+	ExtractContent(ctx context.Context) (string, error)
+	EvaluateJS(ctx context.Context, script string) (string, error)
+	CountElements(ctx context.Context, selector, text string) (int, error)
+	WaitStable(ctx context.Context) error
+
+	// ActivePage returns the underlying page, or nil if there is none, as
+	// with Fake. Features that need a real page, such as CAPTCHA solving,
+	// are skipped without one.
+	ActivePage() *rod.Page
+
+	// Navigation
+	Navigate(ctx context.Context, url string) error
+	GoBack(ctx context.Context) error
+	GoForward(ctx context.Context) error
+	Reload(ctx context.Context) error
+
+	// Interaction with elements of an element map
+	Click(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error
+	DoubleClick(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error
+	TypeText(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error
+	ClearAndType(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error
+	Hover(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error
+	Focus(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error
+	Scroll(ctx context.Context, direction string, amount float64, elementIndex *int, elementMap *dom.ElementMap) error
+	ScrollToElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error
+	ElementDetails(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) (*ElementDetails, error)
+	SendKeys(ctx context.Context, keys string) error
+
+	// Interaction by CSS selector or XPath expression
+	QuerySelector(ctx context.Context, selector string) ([]SelectorMatch, int, error)
+	ClickSelector(ctx context.Context, selector string) error
+
+	// Frames
+	ListFrames(ctx context.Context) ([]FrameInfo, error)
+	SwitchFrame(ctx context.Context, path string) error
+
+	// DocumentURL returns the URL of the document scripts run in: the
+	// selected frame's, or else the page's.
+	DocumentURL() string
+
+	// Raw DevTools protocol commands
+	CallCDP(ctx context.Context, method string, params any) (json.RawMessage, error)
+
+	// Screenshots
+	Screenshot(ctx context.Context, fullPage bool) ([]byte, error)
+	ScreenshotFullQuality(ctx context.Context) ([]byte, error)
+	ScreenshotElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) ([]byte, error)
+	ScreenshotSafe(ctx context.Context, fullPage bool) ([]byte, error)
+	ScreenshotSafeWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error)
+	ScreenshotAfterAction(ctx context.Context) ([]byte, error)
+	ScreenshotAfterActionWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error)
+	AnnotateScreenshot(data []byte, elementMap *dom.ElementMap) ([]byte, error)
+
+	// Tabs
+	NewTab(ctx context.Context, url string) (string, error)
+	NewIsolatedTab(ctx context.Context, url string) (string, error)
+	SwitchTab(tabID string) error
+	CloseTab(tabID string) error
+	LabelTab(tabID, label string) error
+	TakeClosedTabs() []TabInfo
+	ListTabs() []TabInfo
+	CaptureAllTabs(ctx context.Context) ([]TabSnapshot, error)
+
+	// Cookies
+	GetCookies(ctx context.Context) ([]Cookie, error)
+	SetCookies(ctx context.Context, cookies []Cookie) error
+
+	// Downloads
+	DownloadFile(ctx context.Context, elementIndex int, elementMap *dom.ElementMap, opts DownloadOptions) (*DownloadResult, error)
+	DownloadResource(ctx context.Context, rawURL string, opts DownloadOptions) (*DownloadResult, error)
+	TakeDownloads() (finished []DownloadResult, failed []string, pending int)
+}
+
+var _ Interface = (*Browser)(nil)
@@ -0,0 +1,56 @@
+package browser
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// acceptLanguage returns the Accept-Language header for a locale, e.g.
+// "de-DE,de;q=0.9" for "de-DE".
+func acceptLanguage(locale string) string {
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		return locale + "," + base + ";q=0.9"
+	}
+	return locale
+}
+
+// applyLocale sets a page's Intl locale and Accept-Language to the
+// configured locale.
+func (b *Browser) applyLocale(page *rod.Page) error {
+	locale := b.config.Locale
+	if locale == "" {
+		return nil
+	}
+
+	// Fails if stealth already set the same override; that's fine
+	if err := (proto.EmulationSetLocaleOverride{Locale: locale}).Call(page); err != nil {
+		b.log("Browser").Debug("Locale override not applied", "locale", locale, "err", err)
+	}
+
+	// Stealth and device emulation send the language with their user agent
+	if b.config.Stealth.EnableStealth || (b.config.Device != nil && b.config.Device.UserAgent != "") {
+		return nil
+	}
+	version, err := proto.BrowserGetVersion{}.Call(page)
+	if err != nil {
+		return err
+	}
+	return page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:      version.UserAgent,
+		AcceptLanguage: acceptLanguage(locale),
+	})
+}
+
+// deviceAcceptLanguage returns the Accept-Language for a device's user agent
+// override, which would otherwise drop the language stealth or Locale set.
+func (b *Browser) deviceAcceptLanguage() string {
+	if fp := b.config.Stealth.Fingerprint; b.config.Stealth.EnableStealth && fp != nil && len(fp.Languages) > 0 {
+		return strings.Join(fp.Languages, ",")
+	}
+	if b.config.Locale != "" {
+		return acceptLanguage(b.config.Locale)
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ColorScheme is the emulated prefers-color-scheme value.
+type ColorScheme string
+
+const (
+	// ColorSchemeDefault leaves the system preference unchanged.
+	ColorSchemeDefault ColorScheme = ""
+
+	// ColorSchemeLight emulates prefers-color-scheme: light.
+	ColorSchemeLight ColorScheme = "light"
+
+	// ColorSchemeDark emulates prefers-color-scheme: dark.
+	ColorSchemeDark ColorScheme = "dark"
+)
+
+// SetColorScheme emulates prefers-color-scheme on all open tabs and tabs opened later.
+func (b *Browser) SetColorScheme(ctx context.Context, scheme ColorScheme) error {
+	_ = ctx // Context available for future use
+
+	b.mu.Lock()
+	b.config.ColorScheme = scheme
+	b.mu.Unlock()
+
+	return b.applyMediaToAll()
+}
+
+// SetReducedMotion emulates prefers-reduced-motion: reduce on all open tabs and
+// tabs opened later, so animated pages settle faster.
+func (b *Browser) SetReducedMotion(ctx context.Context, reduce bool) error {
+	_ = ctx // Context available for future use
+
+	b.mu.Lock()
+	b.config.ReducedMotion = reduce
+	b.mu.Unlock()
+
+	return b.applyMediaToAll()
+}
+
+// applyMediaToAll re-applies media emulation to every open tab.
+func (b *Browser) applyMediaToAll() error {
+	b.mu.RLock()
+	pages := make([]*rod.Page, 0, len(b.pages))
+	for _, page := range b.pages {
+		pages = append(pages, page)
+	}
+	b.mu.RUnlock()
+
+	for _, page := range pages {
+		if err := b.applyMedia(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMedia applies the configured media feature emulation to a page.
+func (b *Browser) applyMedia(page *rod.Page) error {
+	features := []*proto.EmulationMediaFeature{
+		{Name: "prefers-color-scheme", Value: string(b.config.ColorScheme)},
+		{Name: "prefers-reduced-motion", Value: ""},
+	}
+	if b.config.ReducedMotion {
+		features[1].Value = "reduce"
+	}
+
+	if err := (proto.EmulationSetEmulatedMedia{Features: features}).Call(page); err != nil {
+		return fmt.Errorf("failed to set media emulation: %w", err)
+	}
+	return nil
+}
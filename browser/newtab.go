@@ -0,0 +1,134 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// NewTabPolicy decides what happens when a page opens a new tab, as
+// target=_blank links and window.open do.
+type NewTabPolicy string
+
+const (
+	// NewTabFollow lets the tab open and makes it the active tab, so the
+	// agent continues there.
+	NewTabFollow NewTabPolicy = "follow"
+
+	// NewTabSameTab opens such links in the current tab instead.
+	NewTabSameTab NewTabPolicy = "same_tab"
+
+	// NewTabBlock keeps pages from opening tabs at all.
+	NewTabBlock NewTabPolicy = "block"
+)
+
+// newTabPolicyJS makes links, forms and window.open that would open a new
+// tab use the current one (same_tab), or do nothing (block). Targets naming
+// a frame of the page are left alone.
+const newTabPolicyJS = `(() => {
+	const policy = %q;
+	const opensTab = (target) => {
+		if (!target) return false;
+		const t = target.toLowerCase();
+		if (t === '_self' || t === '_parent' || t === '_top') return false;
+		return !document.querySelector('iframe[name="' + CSS.escape(target) + '"], frame[name="' + CSS.escape(target) + '"]');
+	};
+	const redirect = (el, e) => {
+		if (policy === 'same_tab') el.target = '_self';
+		else e.preventDefault();
+	};
+	document.addEventListener('click', (e) => {
+		const link = e.target instanceof Element && e.target.closest('a[target], area[target]');
+		if (link && opensTab(link.target)) redirect(link, e);
+	}, true);
+	document.addEventListener('submit', (e) => {
+		if (e.target instanceof HTMLFormElement && opensTab(e.target.target)) redirect(e.target, e);
+	}, true);
+	const open = window.open;
+	window.open = function (url, target, features) {
+		if (!opensTab(target || '_blank')) return open.apply(this, arguments);
+		if (policy === 'same_tab') {
+			if (url) location.href = new URL(url, location.href).href;
+			return window;
+		}
+		return null;
+	};
+})();`
+
+// applyNewTabPolicy installs the script enforcing the same_tab and block
+// policies in every document of page.
+func (b *Browser) applyNewTabPolicy(page *rod.Page) error {
+	switch b.config.NewTabPolicy {
+	case NewTabSameTab, NewTabBlock:
+		if _, err := page.EvalOnNewDocument(fmt.Sprintf(newTabPolicyJS, b.config.NewTabPolicy)); err != nil {
+			return fmt.Errorf("failed to apply new tab policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// watchNewTabs adopts tabs that this browser's tabs open, per the new tab
+// policy: they become the active tab, or are closed under NewTabBlock. Tabs
+// opened some other way than the policy script covers, e.g. by a
+// middle-click, are adopted under NewTabSameTab too, so they are not lost.
+func (b *Browser) watchNewTabs(r *rod.Browser) {
+	if err := (proto.TargetSetDiscoverTargets{Discover: true}).Call(r); err != nil {
+		b.log("Browser").Warn("Failed to watch for new tabs", "err", err)
+		return
+	}
+	go r.EachEvent(func(e *proto.TargetTargetCreated) {
+		b.onTabOpened(r, e.TargetInfo)
+	})()
+}
+
+// onTabOpened adopts or closes a tab opened by one of b's tabs.
+func (b *Browser) onTabOpened(r *rod.Browser, info *proto.TargetTargetInfo) {
+	if info.Type != proto.TargetTargetInfoTypePage || info.OpenerID == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	opener := ""
+	for id, page := range b.pages {
+		if page.TargetID == info.TargetID {
+			return // already known
+		}
+		if page.TargetID == info.OpenerID {
+			opener = id
+		}
+	}
+	if opener == "" {
+		return
+	}
+
+	page, err := r.PageFromTarget(info.TargetID)
+	if err != nil {
+		b.log("Browser").Warn("Failed to attach to new tab", "url", info.URL, "err", err)
+		return
+	}
+	if b.config.NewTabPolicy == NewTabBlock {
+		b.log("Browser").Debug("Blocked new tab", "url", info.URL)
+		_ = page.Close()
+		return
+	}
+
+	if b.config.Stealth.EnableStealth {
+		if err := applyStealthMode(page, b.config.Stealth, b.config.Device); err != nil {
+			b.log("Stealth").Warn("Failed to apply stealth mode to new tab", "err", err)
+		}
+	}
+	if err := b.applyEmulation(page); err != nil {
+		b.log("Browser").Warn("Failed to set viewport of new tab", "err", err)
+	}
+	if err := b.applyNewTabPolicy(page); err != nil {
+		b.log("Browser").Warn("Failed to apply new tab policy", "err", err)
+	}
+
+	tabID := generateTabID()
+	b.pages[tabID] = page
+	b.activate(tabID)
+	b.log("Browser").Debug("Followed new tab", "tab", tabID, "opener", opener, "url", info.URL)
+}
@@ -2,23 +2,42 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/anxuanzi/bua/dom"
 	screenshotpkg "github.com/anxuanzi/bua/screenshot"
 )
 
+var (
+	// ErrElementNotFound is returned when an element index is not in the element map.
+	ErrElementNotFound = errors.New("element not found")
+
+	// ErrNavigationTimeout is returned when a page does not respond to navigation in time.
+	ErrNavigationTimeout = errors.New("navigation timed out")
+)
+
+// navigationTimeout bounds how long Navigate waits for the page to respond.
+const navigationTimeout = 30 * time.Second
+
 // Navigate navigates the current page to a URL.
 func (b *Browser) Navigate(ctx context.Context, url string) error {
+	_, end := b.startSpan(ctx, "browser.navigate", attribute.String("url.full", url))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
 	}
+	if err := b.checkRobots(ctx, url); err != nil {
+		return err
+	}
 
 	// Add human-like delay before navigation
 	if b.config.Stealth.HumanLikeDelays {
@@ -26,83 +45,89 @@ func (b *Browser) Navigate(ctx context.Context, url string) error {
 	}
 
 	// Navigate to URL
-	if err := page.Navigate(url); err != nil {
+	watch := b.watchSettle(page)
+	defer watch.stop()
+	if err := page.Timeout(navigationTimeout).Navigate(url); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %s", ErrNavigationTimeout, navigationTimeout, url)
+		}
 		return fmt.Errorf("navigation failed: %w", err)
 	}
 
-	// Wait for page to load
-	if err := page.WaitLoad(); err != nil {
-		// Continue even if wait fails - page might be dynamic
-	}
-
-	// Wait for stability
-	_ = ctx // Context available for future use
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	// Wait for the page to load and settle
+	watch.wait(settleLoad)
 
 	return nil
 }
 
 // GoBack navigates back in history.
 func (b *Browser) GoBack(ctx context.Context) error {
+	_, end := b.startSpan(ctx, "browser.go_back")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.NavigateBack(); err != nil {
 		return fmt.Errorf("go back failed: %w", err)
 	}
 
-	_ = ctx
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	watch.wait(settleLoad)
 
 	return nil
 }
 
 // GoForward navigates forward in history.
 func (b *Browser) GoForward(ctx context.Context) error {
+	_, end := b.startSpan(ctx, "browser.go_forward")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.NavigateForward(); err != nil {
 		return fmt.Errorf("go forward failed: %w", err)
 	}
 
-	_ = ctx
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	watch.wait(settleLoad)
 
 	return nil
 }
 
 // Reload reloads the current page.
 func (b *Browser) Reload(ctx context.Context) error {
+	_, end := b.startSpan(ctx, "browser.reload")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Reload(); err != nil {
 		return fmt.Errorf("reload failed: %w", err)
 	}
 
-	_ = ctx
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	watch.wait(settleLoad)
 
 	return nil
 }
 
 // Click clicks on an element by index.
 func (b *Browser) Click(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.click", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -110,12 +135,12 @@ func (b *Browser) Click(ctx context.Context, elementIndex int, elementMap *dom.E
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	// Show highlight if enabled
 	if b.config.ShowHighlight {
-		b.highlightElement(ctx, element)
+		b.highlightElements(ctx, element)
 	}
 
 	// Add human-like delay before click
@@ -131,12 +156,9 @@ func (b *Browser) Click(ctx context.Context, elementIndex int, elementMap *dom.E
 		centerY += offsetY
 	}
 
-	// Move mouse with human-like motion (linear interpolation)
-	if err := page.Mouse.MoveLinear(proto.Point{X: centerX, Y: centerY}, 5); err != nil {
-		// Fallback to direct move if linear fails
-		if err := page.Mouse.MoveTo(proto.Point{X: centerX, Y: centerY}); err != nil {
-			return fmt.Errorf("failed to move mouse: %w", err)
-		}
+	// Move mouse with human-like motion (linear, or a curve when humanized)
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 5); err != nil {
+		return fmt.Errorf("failed to move mouse: %w", err)
 	}
 
 	// Small delay before click (like human reaction time)
@@ -144,16 +166,14 @@ func (b *Browser) Click(ctx context.Context, elementIndex int, elementMap *dom.E
 		humanDelay(20, 50)
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return fmt.Errorf("click failed: %w", err)
 	}
 
-	// Wait for stability after click
-	time.Sleep(100 * time.Millisecond)
-	_ = ctx
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	// Wait for whatever the click started to settle
+	watch.wait(settleClick)
 
 	return nil
 }
@@ -166,26 +186,27 @@ func (b *Browser) ClickAt(ctx context.Context, x, y float64) error {
 	}
 
 	// Move mouse and click
-	if err := page.Mouse.MoveTo(proto.Point{X: x, Y: y}); err != nil {
+	if err := b.moveMouse(ctx, page, proto.Point{X: x, Y: y}, 1); err != nil {
 		return fmt.Errorf("failed to move mouse: %w", err)
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return fmt.Errorf("click failed: %w", err)
 	}
 
-	// Wait for stability after click
-	time.Sleep(100 * time.Millisecond)
-	_ = ctx
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails
-	}
+	// Wait for whatever the click started to settle
+	watch.wait(settleClick)
 
 	return nil
 }
 
 // DoubleClick double-clicks on an element by index.
 func (b *Browser) DoubleClick(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.double_click", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -193,31 +214,36 @@ func (b *Browser) DoubleClick(ctx context.Context, elementIndex int, elementMap
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	// Show highlight if enabled
 	if b.config.ShowHighlight {
-		b.highlightElement(ctx, element)
+		b.highlightElements(ctx, element)
 	}
 
 	centerX, centerY := element.BoundingBox.Center()
 
-	if err := page.Mouse.MoveTo(proto.Point{X: centerX, Y: centerY}); err != nil {
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 1); err != nil {
 		return fmt.Errorf("failed to move mouse: %w", err)
 	}
 
 	// Double click
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 2); err != nil {
 		return fmt.Errorf("double click failed: %w", err)
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	watch.wait(settleClick)
 	return nil
 }
 
 // TypeText types text into an element by index.
 func (b *Browser) TypeText(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.type_text", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -225,12 +251,12 @@ func (b *Browser) TypeText(ctx context.Context, elementIndex int, text string, e
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	// Show highlight if enabled
 	if b.config.ShowHighlight {
-		b.highlightElement(ctx, element)
+		b.highlightElements(ctx, element)
 	}
 
 	// Add human-like delay before typing
@@ -246,11 +272,11 @@ func (b *Browser) TypeText(ctx context.Context, elementIndex int, text string, e
 		centerY += offsetY
 	}
 
-	if err := page.Mouse.MoveLinear(proto.Point{X: centerX, Y: centerY}, 5); err != nil {
-		if err := page.Mouse.MoveTo(proto.Point{X: centerX, Y: centerY}); err != nil {
-			return fmt.Errorf("failed to move mouse: %w", err)
-		}
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 5); err != nil {
+		return fmt.Errorf("failed to move mouse: %w", err)
 	}
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return fmt.Errorf("click to focus failed: %w", err)
 	}
@@ -263,7 +289,11 @@ func (b *Browser) TypeText(ctx context.Context, elementIndex int, text string, e
 	}
 
 	// Type the text - use character-by-character for more human-like behavior
-	if b.config.Stealth.HumanLikeDelays && len(text) < 100 {
+	if b.config.Humanize.Enabled {
+		if err := b.typeText(ctx, page, text); err != nil {
+			return fmt.Errorf("type failed: %w", err)
+		}
+	} else if b.config.Stealth.HumanLikeDelays && len(text) < 100 {
 		// Type character by character with small random delays
 		for _, char := range text {
 			if err := page.InsertText(string(char)); err != nil {
@@ -278,11 +308,16 @@ func (b *Browser) TypeText(ctx context.Context, elementIndex int, text string, e
 		}
 	}
 
+	// Wait for suggestions or validation the input triggered
+	watch.wait(settleInput)
 	return nil
 }
 
 // ClearAndType clears an input and types new text.
 func (b *Browser) ClearAndType(ctx context.Context, elementIndex int, text string, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.clear_and_type", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -290,19 +325,21 @@ func (b *Browser) ClearAndType(ctx context.Context, elementIndex int, text strin
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	// Show highlight if enabled
 	if b.config.ShowHighlight {
-		b.highlightElement(ctx, element)
+		b.highlightElements(ctx, element)
 	}
 
 	// Click to focus
 	centerX, centerY := element.BoundingBox.Center()
-	if err := page.Mouse.MoveTo(proto.Point{X: centerX, Y: centerY}); err != nil {
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 1); err != nil {
 		return fmt.Errorf("failed to move mouse: %w", err)
 	}
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return fmt.Errorf("click to focus failed: %w", err)
 	}
@@ -319,10 +356,15 @@ func (b *Browser) ClearAndType(ctx context.Context, elementIndex int, text strin
 	}
 
 	// Type new text using InsertText for string input
-	if err := page.InsertText(text); err != nil {
+	if b.config.Humanize.Enabled {
+		if err := b.typeText(ctx, page, text); err != nil {
+			return fmt.Errorf("type failed: %w", err)
+		}
+	} else if err := page.InsertText(text); err != nil {
 		return fmt.Errorf("type failed: %w", err)
 	}
 
+	watch.wait(settleInput)
 	return nil
 }
 
@@ -349,6 +391,9 @@ func (b *Browser) clearInput(page *rod.Page) error {
 
 // SendKeys sends keyboard keys to the page.
 func (b *Browser) SendKeys(ctx context.Context, keys string) error {
+	_, end := b.startSpan(ctx, "browser.send_keys")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -373,6 +418,8 @@ func (b *Browser) SendKeys(ctx context.Context, keys string) error {
 		"Space":      input.Space,
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if key, ok := keyMap[keys]; ok {
 		if err := page.Keyboard.Type(key); err != nil {
 			return fmt.Errorf("send keys failed: %w", err)
@@ -384,11 +431,20 @@ func (b *Browser) SendKeys(ctx context.Context, keys string) error {
 		}
 	}
 
+	// Enter may submit a form, like a click
+	if keys == "Enter" {
+		watch.wait(settleClick)
+	} else {
+		watch.wait(settleInput)
+	}
 	return nil
 }
 
 // Scroll scrolls the page or an element.
 func (b *Browser) Scroll(ctx context.Context, direction string, amount float64, elementIndex *int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.scroll", attribute.String("bua.scroll_direction", direction))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -408,16 +464,19 @@ func (b *Browser) Scroll(ctx context.Context, direction string, amount float64,
 		return fmt.Errorf("invalid scroll direction: %s", direction)
 	}
 
+	watch := b.watchSettle(page)
+	defer watch.stop()
+
 	// If element index is specified, scroll within that element
 	if elementIndex != nil && elementMap != nil {
 		element, ok := elementMap.Get(*elementIndex)
 		if !ok {
-			return fmt.Errorf("element not found: index %d", *elementIndex)
+			return fmt.Errorf("%w: index %d", ErrElementNotFound, *elementIndex)
 		}
 
 		// Show highlight if enabled
 		if b.config.ShowHighlight {
-			b.highlightElement(ctx, element)
+			b.highlightElements(ctx, element)
 		}
 
 		// Scroll within element using JavaScript
@@ -442,13 +501,16 @@ func (b *Browser) Scroll(ctx context.Context, direction string, amount float64,
 	}
 
 	// Wait for content to load after scroll
-	time.Sleep(200 * time.Millisecond)
+	watch.wait(settleScroll)
 
 	return nil
 }
 
 // ScrollToElement scrolls an element into view.
 func (b *Browser) ScrollToElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.scroll_to_element", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -456,60 +518,39 @@ func (b *Browser) ScrollToElement(ctx context.Context, elementIndex int, element
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
-	// Use JavaScript to scroll element into view
-	scrollJS := fmt.Sprintf(`() => {
-		const el = document.elementFromPoint(%f, %f);
+	// Use JavaScript to scroll element into view, finding it by its stable
+	// ID, which also works for elements outside the viewport
+	doc, dx, dy := b.documentPage()
+	scrollJS := fmt.Sprintf(`(id) => {
+		const el = (id && document.querySelector('[data-bua-id="' + CSS.escape(id) + '"]')) ||
+			document.elementFromPoint(%f, %f);
 		if (el) {
 			el.scrollIntoView({behavior: 'smooth', block: 'center'});
 			return true;
 		}
 		return false;
-	}`, element.BoundingBox.X+10, element.BoundingBox.Y+10)
+	}`, element.BoundingBox.X-dx+10, element.BoundingBox.Y-dy+10)
 
-	_, err := page.Eval(scrollJS)
+	watch := b.watchSettle(page)
+	defer watch.stop()
+	_, err := doc.Eval(scrollJS, element.StableID)
 	if err != nil {
 		return fmt.Errorf("scroll to element failed: %w", err)
 	}
 
-	time.Sleep(300 * time.Millisecond)
+	// Smooth scrolling counts as change until it stops
+	watch.wait(settleScroll)
 	return nil
 }
 
-// highlightElement shows a visual highlight on an element.
-func (b *Browser) highlightElement(ctx context.Context, element *dom.Element) {
-	page := b.ActivePage()
-	if page == nil {
-		return
-	}
-
-	highlightJS := fmt.Sprintf(`() => {
-		const overlay = document.createElement('div');
-		overlay.id = 'bua-highlight';
-		overlay.style.cssText = 'position:fixed;pointer-events:none;z-index:999999;' +
-			'border:3px solid #ff6b6b;background:rgba(255,107,107,0.2);' +
-			'left:%fpx;top:%fpx;width:%fpx;height:%fpx;transition:opacity 0.2s;';
-		document.body.appendChild(overlay);
-		setTimeout(() => {
-			overlay.style.opacity = '0';
-			setTimeout(() => overlay.remove(), 200);
-		}, %d);
-	}`,
-		element.BoundingBox.X,
-		element.BoundingBox.Y,
-		element.BoundingBox.Width,
-		element.BoundingBox.Height,
-		b.config.HighlightDuration.Milliseconds()-200,
-	)
-
-	page.Eval(highlightJS)
-	time.Sleep(b.config.HighlightDuration)
-}
-
 // Hover moves the mouse to hover over an element.
 func (b *Browser) Hover(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.hover", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -517,21 +558,27 @@ func (b *Browser) Hover(ctx context.Context, elementIndex int, elementMap *dom.E
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	centerX, centerY := element.BoundingBox.Center()
 
-	if err := page.Mouse.MoveLinear(proto.Point{X: centerX, Y: centerY}, 10); err != nil {
+	watch := b.watchSettle(page)
+	defer watch.stop()
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 10); err != nil {
 		return fmt.Errorf("hover failed: %w", err)
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	// Wait for menus or tooltips the hover opened
+	watch.wait(settleInput)
 	return nil
 }
 
 // Focus focuses on an element.
 func (b *Browser) Focus(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) error {
+	_, end := b.startSpan(ctx, "browser.focus", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return fmt.Errorf("no active page")
@@ -539,18 +586,21 @@ func (b *Browser) Focus(ctx context.Context, elementIndex int, elementMap *dom.E
 
 	element, ok := elementMap.Get(elementIndex)
 	if !ok {
-		return fmt.Errorf("element not found: index %d", elementIndex)
+		return fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
 	}
 
 	// Click to focus
 	centerX, centerY := element.BoundingBox.Center()
-	if err := page.Mouse.MoveTo(proto.Point{X: centerX, Y: centerY}); err != nil {
+	if err := b.moveMouse(ctx, page, proto.Point{X: centerX, Y: centerY}, 1); err != nil {
 		return fmt.Errorf("failed to move mouse: %w", err)
 	}
+	watch := b.watchSettle(page)
+	defer watch.stop()
 	if err := page.Mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
 		return fmt.Errorf("click to focus failed: %w", err)
 	}
 
+	watch.wait(settleInput)
 	return nil
 }
 
@@ -566,28 +616,83 @@ func (b *Browser) Screenshot(ctx context.Context, fullPage bool) ([]byte, error)
 	opts := screenshotpkg.LLMOptions()
 	opts.FullPage = fullPage
 
+	b.clearHighlights(page)
 	return screenshotpkg.Capture(ctx, page, opts)
 }
 
+// ScreenshotFullQuality takes a lossless, full-resolution screenshot for diagnostics.
+// Unlike the LLM-oriented captures it ignores preset quality settings and blank-page checks.
+func (b *Browser) ScreenshotFullQuality(ctx context.Context) ([]byte, error) {
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+
+	b.clearHighlights(page)
+	return screenshotpkg.Capture(ctx, page, screenshotpkg.DebugOptions())
+}
+
+// ScreenshotElement takes a lossless PNG screenshot of just one element,
+// scrolling it into view first, e.g. for reading its text with OCR.
+func (b *Browser) ScreenshotElement(ctx context.Context, elementIndex int, elementMap *dom.ElementMap) ([]byte, error) {
+	_, end := b.startSpan(ctx, "browser.screenshot_element", attribute.Int("bua.element_index", elementIndex))
+	defer end()
+
+	page := b.ActivePage()
+	if page == nil {
+		return nil, fmt.Errorf("no active page")
+	}
+
+	element, ok := elementMap.Get(elementIndex)
+	if !ok {
+		return nil, fmt.Errorf("%w: index %d", ErrElementNotFound, elementIndex)
+	}
+
+	// Find the element by its stable ID, falling back to the one at its center
+	doc, dx, dy := b.documentPage()
+	x, y := element.BoundingBox.Center()
+	el, err := doc.Sleeper(rod.NotFoundSleeper).ElementByJS(rod.Eval(`(id, x, y) =>
+		(id && document.querySelector('[data-bua-id="' + CSS.escape(id) + '"]')) ||
+		document.elementFromPoint(x, y)`, element.StableID, x-dx, y-dy))
+	if err != nil {
+		return nil, fmt.Errorf("%w: index %d is no longer on the page", ErrElementNotFound, elementIndex)
+	}
+
+	b.clearHighlights(page)
+	data, err := el.Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture element screenshot: %w", err)
+	}
+	return data, nil
+}
+
 // ScreenshotSafe takes a screenshot, returning nil (not error) for blank pages.
 // This is useful for agent loops where blank screenshots should be skipped.
 func (b *Browser) ScreenshotSafe(ctx context.Context, fullPage bool) ([]byte, error) {
+	_, end := b.startSpan(ctx, "browser.screenshot")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return nil, nil // No page, return nil safely
 	}
 
+	b.clearHighlights(page)
 	return screenshotpkg.ForLLMSafe(ctx, page, b.config.ViewportWidth)
 }
 
 // ScreenshotAfterAction captures a screenshot after an action completes.
 // Waits for page stability before capturing.
 func (b *Browser) ScreenshotAfterAction(ctx context.Context) ([]byte, error) {
+	_, end := b.startSpan(ctx, "browser.screenshot")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return nil, fmt.Errorf("no active page")
 	}
 
+	b.clearHighlights(page)
 	return screenshotpkg.CaptureAfterAction(ctx, page, b.config.ViewportWidth)
 }
 
@@ -613,7 +718,10 @@ func (b *Browser) WaitForPageReady(ctx context.Context, timeout time.Duration) e
 
 // ExtractContent extracts text content from the page.
 func (b *Browser) ExtractContent(ctx context.Context) (string, error) {
-	page := b.ActivePage()
+	_, end := b.startSpan(ctx, "browser.extract_content")
+	defer end()
+
+	page, _, _ := b.documentPage()
 	if page == nil {
 		return "", fmt.Errorf("no active page")
 	}
@@ -637,7 +745,10 @@ func (b *Browser) ExtractContent(ctx context.Context) (string, error) {
 
 // EvaluateJS evaluates JavaScript code on the page.
 func (b *Browser) EvaluateJS(ctx context.Context, script string) (string, error) {
-	page := b.ActivePage()
+	_, end := b.startSpan(ctx, "browser.evaluate_js")
+	defer end()
+
+	page, _, _ := b.documentPage()
 	if page == nil {
 		return "", fmt.Errorf("no active page")
 	}
@@ -656,6 +767,28 @@ func (b *Browser) EvaluateJS(ctx context.Context, script string) (string, error)
 	return result.Value.String(), nil
 }
 
+// CountElements returns how many visible elements match selector, counting
+// only those whose text contains text (case-insensitive) if text is set.
+func (b *Browser) CountElements(ctx context.Context, selector, text string) (int, error) {
+	page := b.ActivePage()
+	if page == nil {
+		return 0, fmt.Errorf("no active page")
+	}
+
+	result, err := page.Eval(`(selector, text) => {
+		const needle = text.toLowerCase();
+		return Array.from(document.querySelectorAll(selector)).filter(el => {
+			if (el.getClientRects().length === 0) return false;
+			return !needle || (el.innerText || el.textContent || '').toLowerCase().includes(needle);
+		}).length;
+	}`, selector, text)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count elements: %w", err)
+	}
+
+	return result.Value.Int(), nil
+}
+
 // ElementMapAdapter adapts dom.ElementMap to screenshot.ElementMapInterface.
 type ElementMapAdapter struct {
 	elementMap *dom.ElementMap
@@ -692,11 +825,13 @@ type elementInfoAdapter struct {
 	element dom.ElementInfoInterface
 }
 
-func (a *elementInfoAdapter) GetIndex() int      { return a.element.GetIndex() }
-func (a *elementInfoAdapter) GetTagName() string { return a.element.GetTagName() }
-func (a *elementInfoAdapter) GetRole() string    { return a.element.GetRole() }
-func (a *elementInfoAdapter) GetText() string    { return a.element.GetText() }
-func (a *elementInfoAdapter) GetIsVisible() bool { return a.element.GetIsVisible() }
+func (a *elementInfoAdapter) GetIndex() int          { return a.element.GetIndex() }
+func (a *elementInfoAdapter) GetTagName() string     { return a.element.GetTagName() }
+func (a *elementInfoAdapter) GetRole() string        { return a.element.GetRole() }
+func (a *elementInfoAdapter) GetText() string        { return a.element.GetText() }
+func (a *elementInfoAdapter) GetIsVisible() bool     { return a.element.GetIsVisible() }
+func (a *elementInfoAdapter) GetName() string        { return a.element.GetName() }
+func (a *elementInfoAdapter) GetIsInteractive() bool { return a.element.GetIsInteractive() }
 func (a *elementInfoAdapter) GetBoundingBox() screenshotpkg.BoundingBoxInfo {
 	return a.element.GetBoundingBox()
 }
@@ -710,27 +845,63 @@ func (b *Browser) ScreenshotWithAnnotations(ctx context.Context, elementMap *dom
 	}
 
 	adapter := NewElementMapAdapter(elementMap)
-	return screenshotpkg.ForLLMWithAnnotations(ctx, page, adapter, b.config.ViewportWidth)
+	b.clearHighlights(page)
+	return screenshotpkg.CaptureWithAnnotations(ctx, page, adapter, b.annotatedOptions())
 }
 
 // ScreenshotSafeWithAnnotations takes an annotated screenshot, returning nil for blank pages.
 func (b *Browser) ScreenshotSafeWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error) {
+	_, end := b.startSpan(ctx, "browser.screenshot")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return nil, nil
 	}
 
 	adapter := NewElementMapAdapter(elementMap)
-	return screenshotpkg.ForLLMSafeWithAnnotations(ctx, page, adapter, b.config.ViewportWidth)
+	b.clearHighlights(page)
+	return screenshotpkg.CaptureSafeWithAnnotations(ctx, page, adapter, b.annotatedOptions())
 }
 
 // ScreenshotAfterActionWithAnnotations captures an annotated screenshot after an action.
 func (b *Browser) ScreenshotAfterActionWithAnnotations(ctx context.Context, elementMap *dom.ElementMap) ([]byte, error) {
+	_, end := b.startSpan(ctx, "browser.screenshot")
+	defer end()
+
 	page := b.ActivePage()
 	if page == nil {
 		return nil, fmt.Errorf("no active page")
 	}
 
 	adapter := NewElementMapAdapter(elementMap)
-	return screenshotpkg.CaptureAfterActionWithAnnotations(ctx, page, adapter, b.config.ViewportWidth)
+	opts := b.annotatedOptions()
+	// Use longer stability timeout for post-action captures
+	opts.StabilityTimeout = 1500 * time.Millisecond
+	opts.WaitForIdle = true
+	b.clearHighlights(page)
+	return screenshotpkg.CaptureWithAnnotations(ctx, page, adapter, opts)
+}
+
+// AnnotateScreenshot draws the element annotations of annotated screenshots
+// on a screenshot taken earlier, without touching the page, so it can run in
+// the background.
+func (b *Browser) AnnotateScreenshot(data []byte, elementMap *dom.ElementMap) ([]byte, error) {
+	opts := b.annotatedOptions()
+	if elementMap == nil || elementMap.Len() == 0 {
+		return data, nil
+	}
+	return screenshotpkg.Annotate(data, NewElementMapAdapter(elementMap), *opts.AnnotationConfig)
+}
+
+// annotatedOptions returns the options of annotated screenshots for the LLM.
+func (b *Browser) annotatedOptions() screenshotpkg.AnnotatedOptions {
+	opts := screenshotpkg.DefaultAnnotatedOptions()
+	if b.config.ViewportWidth > 0 {
+		opts.MaxWidth = b.config.ViewportWidth
+	}
+	if b.config.Annotations != nil {
+		opts.AnnotationConfig = b.config.Annotations
+	}
+	return opts
 }
@@ -0,0 +1,181 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Permission is a browser permission name as used by the Permissions API.
+type Permission string
+
+// Common permissions.
+const (
+	PermissionGeolocation    Permission = "geolocation"
+	PermissionNotifications  Permission = "notifications"
+	PermissionCamera         Permission = "camera"
+	PermissionMicrophone     Permission = "microphone"
+	PermissionClipboardRead  Permission = "clipboard-read"
+	PermissionClipboardWrite Permission = "clipboard-write"
+	PermissionMIDI           Permission = "midi"
+	PermissionBackgroundSync Permission = "background-sync"
+	PermissionStorageAccess  Permission = "storage-access"
+)
+
+// PermissionRule grants or denies permissions for an origin.
+type PermissionRule struct {
+	// Origin the rule applies to, e.g. "https://maps.example.com". Empty means all origins.
+	Origin string
+
+	// Grant lists permissions to allow without a prompt.
+	Grant []Permission
+
+	// Deny lists permissions to reject without a prompt.
+	Deny []Permission
+}
+
+// Geolocation is a fixed position reported to pages.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // Meters. Default: 10.
+}
+
+// GrantPermissions allows permissions for an origin ("" for all origins), so
+// the page gets them without showing a prompt that would block the agent.
+func (b *Browser) GrantPermissions(ctx context.Context, origin string, perms ...Permission) error {
+	return b.setPermissions(ctx, origin, proto.BrowserPermissionSettingGranted, perms)
+}
+
+// DenyPermissions rejects permissions for an origin ("" for all origins), as if
+// the user had clicked Block, e.g. to test a site's denied-geolocation flow.
+func (b *Browser) DenyPermissions(ctx context.Context, origin string, perms ...Permission) error {
+	return b.setPermissions(ctx, origin, proto.BrowserPermissionSettingDenied, perms)
+}
+
+// ResetPermissions restores the default prompt behavior for all permissions.
+func (b *Browser) ResetPermissions(ctx context.Context) error {
+	_ = ctx // Context available for future use
+
+	for _, r := range b.permissionTargets() {
+		if err := (proto.BrowserResetPermissions{BrowserContextID: r.BrowserContextID}).Call(r); err != nil {
+			return fmt.Errorf("failed to reset permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetGeolocation overrides the position reported to the active page.
+// Pass nil to clear the override.
+func (b *Browser) SetGeolocation(ctx context.Context, geo *Geolocation) error {
+	page := b.ActivePage()
+	if page == nil {
+		return fmt.Errorf("no active page")
+	}
+	_ = ctx // Context available for future use
+
+	b.mu.Lock()
+	b.config.Geolocation = geo
+	b.mu.Unlock()
+
+	if geo == nil {
+		if err := (proto.EmulationClearGeolocationOverride{}).Call(page); err != nil {
+			return fmt.Errorf("failed to clear geolocation: %w", err)
+		}
+		return nil
+	}
+	return b.applyGeolocation(page)
+}
+
+// setPermissions applies a setting to each permission in the browser's
+// default context and every isolated tab context.
+func (b *Browser) setPermissions(ctx context.Context, origin string, setting proto.BrowserPermissionSetting, perms []Permission) error {
+	_ = ctx // Context available for future use
+
+	targets := b.permissionTargets()
+	if len(targets) == 0 {
+		return fmt.Errorf("browser not started")
+	}
+
+	for _, r := range targets {
+		if err := setPermissionsOn(r, origin, setting, perms); err != nil {
+			return err
+		}
+	}
+
+	scope := origin
+	if scope == "" {
+		scope = "all origins"
+	}
+	b.log("Browser").Debug("Set permissions", "permissions", perms, "setting", setting, "scope", scope)
+	return nil
+}
+
+// setPermissionsOn applies a setting to each permission in one browser context.
+func setPermissionsOn(r *rod.Browser, origin string, setting proto.BrowserPermissionSetting, perms []Permission) error {
+	for _, perm := range perms {
+		err := proto.BrowserSetPermission{
+			Permission:       &proto.BrowserPermissionDescriptor{Name: string(perm)},
+			Setting:          setting,
+			Origin:           origin,
+			BrowserContextID: r.BrowserContextID,
+		}.Call(r)
+		if err != nil {
+			return fmt.Errorf("failed to set %s permission to %s: %w", perm, setting, err)
+		}
+	}
+	return nil
+}
+
+// permissionTargets returns the browser contexts that permission changes apply to.
+func (b *Browser) permissionTargets() []*rod.Browser {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.rod == nil {
+		return nil
+	}
+	targets := []*rod.Browser{b.rod}
+	for _, tabCtx := range b.tabContexts {
+		targets = append(targets, tabCtx)
+	}
+	return targets
+}
+
+// applyPermissionRules applies the configured permission rules to a browser context.
+// It doesn't take b.mu, so it can be used while creating contexts.
+func (b *Browser) applyPermissionRules(r *rod.Browser) error {
+	for _, rule := range b.config.Permissions {
+		if err := setPermissionsOn(r, rule.Origin, proto.BrowserPermissionSettingGranted, rule.Grant); err != nil {
+			return err
+		}
+		if err := setPermissionsOn(r, rule.Origin, proto.BrowserPermissionSettingDenied, rule.Deny); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGeolocation applies the configured geolocation override, if any, to a page.
+func (b *Browser) applyGeolocation(page *rod.Page) error {
+	geo := b.config.Geolocation
+	if geo == nil {
+		return nil
+	}
+
+	accuracy := geo.Accuracy
+	if accuracy <= 0 {
+		accuracy = 10
+	}
+	err := proto.EmulationSetGeolocationOverride{
+		Latitude:  &geo.Latitude,
+		Longitude: &geo.Longitude,
+		Accuracy:  &accuracy,
+	}.Call(page)
+	if err != nil {
+		return fmt.Errorf("failed to set geolocation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package browser
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// recordingFrameInterval is the minimum time between recorded frames (~4 fps).
+const recordingFrameInterval = 250 * time.Millisecond
+
+// Recorder captures the screencast of a browser session into an animated GIF.
+// Frames are buffered as JPEG while recording and encoded when Stop is called.
+type Recorder struct {
+	path        string
+	unsubscribe func()
+	done        chan struct{}
+
+	mu     sync.Mutex
+	frames [][]byte
+	times  []time.Time
+}
+
+// StartRecording begins recording the active tab to an animated GIF at path.
+// The recording follows tab switches. Call Stop to write the file.
+func (b *Browser) StartRecording(path string) (*Recorder, error) {
+	if path == "" {
+		return nil, fmt.Errorf("recording path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	frames, unsubscribe := b.SubscribeFrames()
+	r := &Recorder{
+		path:        path,
+		unsubscribe: unsubscribe,
+		done:        make(chan struct{}),
+	}
+
+	go func() {
+		defer close(r.done)
+		var last time.Time
+		for frame := range frames {
+			if frame.Timestamp.Sub(last) < recordingFrameInterval {
+				continue
+			}
+			last = frame.Timestamp
+			r.mu.Lock()
+			r.frames = append(r.frames, frame.Data)
+			r.times = append(r.times, frame.Timestamp)
+			r.mu.Unlock()
+		}
+	}()
+
+	return r, nil
+}
+
+// Stop ends the recording and writes the GIF file.
+// Returns the path of the written file.
+func (r *Recorder) Stop() (string, error) {
+	r.unsubscribe()
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.frames) == 0 {
+		return "", fmt.Errorf("no frames recorded")
+	}
+
+	anim := &gif.GIF{}
+	for i, data := range r.frames {
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.Point{})
+
+		// GIF delays are in hundredths of a second; hold the last frame for a second
+		delay := 100
+		if i+1 < len(r.times) {
+			delay = int(r.times[i+1].Sub(r.times[i]) / (10 * time.Millisecond))
+			if delay < 1 {
+				delay = 1
+			}
+		}
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+
+		// Frames from different tabs may differ in size; the canvas must fit them all
+		if b := img.Bounds(); b.Dx() > anim.Config.Width || b.Dy() > anim.Config.Height {
+			anim.Config.Width = max(anim.Config.Width, b.Dx())
+			anim.Config.Height = max(anim.Config.Height, b.Dy())
+		}
+	}
+
+	if len(anim.Image) == 0 {
+		return "", fmt.Errorf("no decodable frames recorded")
+	}
+
+	f, err := os.Create(r.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create recording file: %w", err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return "", fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	return r.path, nil
+}
@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrDisallowed is returned when robots.txt disallows a page.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// checkRobots returns ErrDisallowed if robots.txt disallows url. The
+// request interception set up by enforceRobots catches the same page, but
+// checking first gives Navigate a clear error.
+func (b *Browser) checkRobots(ctx context.Context, url string) error {
+	policy := b.config.Robots
+	if policy == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	allowed, err := policy.Allowed(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to check robots.txt: %w", err)
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrDisallowed, url)
+	}
+	return nil
+}
+
+// enforceRobots intercepts the page loads of every tab, failing those that
+// robots.txt disallows and holding the rest until their host's crawl delay
+// has passed. Links clicked and scripts that navigate are caught too.
+func (b *Browser) enforceRobots(r *rod.Browser) error {
+	policy := b.config.Robots
+	if policy == nil {
+		return nil
+	}
+
+	router := r.HijackRequests()
+	err := router.Add("*", proto.NetworkResourceTypeDocument, func(h *rod.Hijack) {
+		ctx := h.Request.Req().Context()
+		url := h.Request.URL().String()
+
+		if allowed, err := policy.Allowed(ctx, url); err != nil || !allowed {
+			b.log("Robots").Debug("Page disallowed", "url", url, "err", err)
+			h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+		if err := policy.Wait(ctx, url); err != nil {
+			h.Response.Fail(proto.NetworkErrorReasonAborted)
+			return
+		}
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable robots.txt checks: %w", err)
+	}
+	go router.Run()
+	return nil
+}
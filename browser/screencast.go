@@ -0,0 +1,160 @@
+package browser
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ScreencastFrame is a single JPEG frame emitted by the browser screencast.
+type ScreencastFrame struct {
+	// Data is the JPEG-encoded frame.
+	Data []byte
+
+	// TabID is the tab the frame was captured from.
+	TabID string
+
+	// Timestamp is when the frame was received.
+	Timestamp time.Time
+}
+
+// screencastMaxWidth caps the frame width requested from Chrome.
+const screencastMaxWidth = 1280
+
+// screencast streams frames from the active tab to any number of subscribers.
+// It follows the active tab: when the tab changes, the stream is restarted on the new page.
+type screencast struct {
+	mu     sync.Mutex
+	subs   map[int]chan ScreencastFrame
+	nextID int
+	cancel context.CancelFunc
+	page   *rod.Page
+	log    *slog.Logger
+}
+
+// SubscribeFrames starts streaming screencast frames from the active tab.
+// The returned function unsubscribes; the screencast stops when the last
+// subscriber leaves. Frames are dropped for subscribers that fall behind.
+func (b *Browser) SubscribeFrames() (<-chan ScreencastFrame, func()) {
+	b.mu.Lock()
+	if b.screencast == nil {
+		b.screencast = &screencast{subs: make(map[int]chan ScreencastFrame), log: b.log("Screencast")}
+	}
+	sc := b.screencast
+	tabID := b.activeTabID
+	page := b.pages[tabID]
+	b.mu.Unlock()
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	id := sc.nextID
+	sc.nextID++
+	ch := make(chan ScreencastFrame, 8)
+	sc.subs[id] = ch
+
+	if len(sc.subs) == 1 && page != nil {
+		sc.startLocked(tabID, page)
+	}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			sc.mu.Lock()
+			defer sc.mu.Unlock()
+			delete(sc.subs, id)
+			close(ch)
+			if len(sc.subs) == 0 {
+				sc.stopLocked()
+			}
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// followActiveTab restarts a running screencast on the newly active tab.
+// Must be called with b.mu held.
+func (b *Browser) followActiveTab() {
+	if b.screencast == nil {
+		return
+	}
+	page := b.pages[b.activeTabID]
+	if page == nil {
+		return
+	}
+
+	sc := b.screencast
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if len(sc.subs) == 0 || sc.page == page {
+		return
+	}
+	sc.stopLocked()
+	sc.startLocked(b.activeTabID, page)
+}
+
+// startLocked begins streaming frames from page. Must be called with sc.mu held.
+func (sc *screencast) startLocked(tabID string, page *rod.Page) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sc.cancel = cancel
+	sc.page = page
+
+	p := page.Context(ctx)
+	wait := p.EachEvent(func(e *proto.PageScreencastFrame) {
+		_ = proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(p)
+		sc.broadcast(ScreencastFrame{
+			Data:      e.Data,
+			TabID:     tabID,
+			Timestamp: time.Now(),
+		})
+	})
+
+	quality := 70
+	maxWidth := screencastMaxWidth
+	if err := (proto.PageStartScreencast{
+		Format:   proto.PageStartScreencastFormatJpeg,
+		Quality:  &quality,
+		MaxWidth: &maxWidth,
+	}).Call(p); err != nil {
+		sc.log.Warn("Failed to start", "tab", tabID, "err", err)
+		cancel()
+		sc.cancel = nil
+		sc.page = nil
+		return
+	}
+
+	go wait()
+
+	sc.log.Debug("Streaming", "tab", tabID)
+}
+
+// stopLocked stops the current stream. Must be called with sc.mu held.
+func (sc *screencast) stopLocked() {
+	if sc.cancel == nil {
+		return
+	}
+	_ = proto.PageStopScreencast{}.Call(sc.page)
+	sc.cancel()
+	sc.cancel = nil
+	sc.page = nil
+}
+
+// broadcast delivers a frame to every subscriber without blocking.
+func (sc *screencast) broadcast(frame ScreencastFrame) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for _, ch := range sc.subs {
+		select {
+		case ch <- frame:
+		default:
+			// Subscriber is behind; drop the frame
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/anxuanzi/bua/dom"
+)
+
+// maxSelectorMatches caps the matches QuerySelector reports.
+const maxSelectorMatches = 20
+
+// findJS defines find(selector), which returns the elements matching a CSS
+// selector or an XPath expression. XPath is recognized by a leading "/",
+// "(" or "./", or an "xpath=" prefix; a "css=" prefix forces CSS.
+const findJS = `const find = (selector) => {
+		if (/^xpath=/.test(selector) || /^(\/|\(|\.\/)/.test(selector)) {
+			const result = document.evaluate(selector.replace(/^xpath=/, ''), document, null,
+				XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			const nodes = [];
+			for (let i = 0; i < result.snapshotLength; i++) {
+				const node = result.snapshotItem(i);
+				if (node.nodeType === Node.ELEMENT_NODE) nodes.push(node);
+			}
+			return nodes;
+		}
+		return Array.from(document.querySelectorAll(selector.replace(/^css=/, '')));
+	};
+	const visible = (el) => {
+		if (el.getClientRects().length === 0) return false;
+		const style = getComputedStyle(el);
+		return style.visibility !== 'hidden' && style.display !== 'none';
+	};`
+
+// querySelectorJS describes the first matches of a selector.
+const querySelectorJS = `(selector, limit) => {
+	` + findJS + `
+	const nodes = find(selector);
+	return JSON.stringify({
+		total: nodes.length,
+		matches: nodes.slice(0, limit).map((el) => {
+			const r = el.getBoundingClientRect();
+			const text = (el.innerText || el.value || el.textContent || '').trim().replace(/\s+/g, ' ');
+			return {
+				tagName: el.tagName.toLowerCase(),
+				text: text.length > 100 ? text.slice(0, 100) + '...' : text,
+				visible: visible(el),
+				boundingBox: { x: r.x, y: r.y, width: r.width, height: r.height }
+			};
+		})
+	});
+}`
+
+// locateSelectorJS scrolls the first visible match of a selector into view
+// and returns its bounding box, or "" if nothing visible matches.
+const locateSelectorJS = `(selector) => {
+	` + findJS + `
+	const el = find(selector).find(visible);
+	if (!el) return '';
+	el.scrollIntoView({ block: 'center', inline: 'center' });
+	const r = el.getBoundingClientRect();
+	return JSON.stringify({ x: r.x, y: r.y, width: r.width, height: r.height });
+}`
+
+// SelectorMatch is an element matched by QuerySelector.
+type SelectorMatch struct {
+	TagName     string          `json:"tagName"`
+	Text        string          `json:"text,omitempty"`
+	Visible     bool            `json:"visible"`
+	BoundingBox dom.BoundingBox `json:"boundingBox"`
+}
+
+// QuerySelector returns the elements matching a CSS selector or XPath
+// expression (see ClickSelector), up to 20, and how many match in all.
+func (b *Browser) QuerySelector(ctx context.Context, selector string) ([]SelectorMatch, int, error) {
+	_, end := b.startSpan(ctx, "browser.query_selector", attribute.String("bua.selector", selector))
+	defer end()
+
+	page, dx, dy := b.documentPage()
+	if page == nil {
+		return nil, 0, fmt.Errorf("no active page")
+	}
+
+	result, err := page.Eval(querySelectorJS, selector, maxSelectorMatches)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query selector %q: %w", selector, err)
+	}
+	var data struct {
+		Total   int             `json:"total"`
+		Matches []SelectorMatch `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(result.Value.Str()), &data); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse selector matches: %w", err)
+	}
+	for i := range data.Matches {
+		data.Matches[i].BoundingBox.X += dx
+		data.Matches[i].BoundingBox.Y += dy
+	}
+	return data.Matches, data.Total, nil
+}
+
+// ClickSelector clicks the first visible element matching selector, after
+// scrolling it into view. selector is a CSS selector, or an XPath
+// expression if it starts with "/", "(" or "./"; the prefixes "css=" and
+// "xpath=" make either explicit.
+func (b *Browser) ClickSelector(ctx context.Context, selector string) error {
+	page, dx, dy := b.documentPage()
+	if page == nil {
+		return fmt.Errorf("no active page")
+	}
+
+	result, err := page.Eval(locateSelectorJS, selector)
+	if err != nil {
+		return fmt.Errorf("failed to query selector %q: %w", selector, err)
+	}
+	raw := result.Value.Str()
+	if raw == "" {
+		return fmt.Errorf("%w: no visible match for %q", ErrElementNotFound, selector)
+	}
+	var box dom.BoundingBox
+	if err := json.Unmarshal([]byte(raw), &box); err != nil {
+		return fmt.Errorf("failed to parse selector match: %w", err)
+	}
+	box.X += dx
+	box.Y += dy
+
+	// Click it like an element of an element map, with highlight and
+	// humanized motion
+	em := dom.NewElementMap()
+	em.Add(&dom.Element{Index: 0, Selector: selector, BoundingBox: box, IsVisible: true, IsEnabled: true})
+	return b.Click(ctx, 0, em)
+}
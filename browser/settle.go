@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// settleKind selects how long an action's effects are waited for.
+type settleKind int
+
+const (
+	// settleLoad follows navigation: the document must finish loading.
+	settleLoad settleKind = iota
+	// settleClick follows clicks and Enter, which may submit forms,
+	// fetch data or navigate.
+	settleClick
+	// settleInput follows typing, hovering and focusing, which at most
+	// open suggestions or menus.
+	settleInput
+	// settleScroll follows scrolling, which may lazy-load content.
+	settleScroll
+	// settleRead precedes reading the page, in case something changed since
+	// the last action.
+	settleRead
+)
+
+// settleHeuristic is how one kind of action settles: the page is settled
+// once nothing changed for quiet, and given up on after timeout.
+type settleHeuristic struct {
+	quiet   time.Duration
+	timeout time.Duration
+	load    bool // document.readyState must be "complete"
+}
+
+var settleHeuristics = map[settleKind]settleHeuristic{
+	settleLoad:   {quiet: 500 * time.Millisecond, timeout: 10 * time.Second, load: true},
+	settleClick:  {quiet: 300 * time.Millisecond, timeout: 5 * time.Second},
+	settleInput:  {quiet: 150 * time.Millisecond, timeout: 2 * time.Second},
+	settleScroll: {quiet: 150 * time.Millisecond, timeout: 1500 * time.Millisecond},
+	settleRead:   {quiet: 100 * time.Millisecond, timeout: 2 * time.Second},
+}
+
+const (
+	// settlePoll is how often the page is checked while settling.
+	settlePoll = 50 * time.Millisecond
+
+	// backgroundRequest is how long a request may be pending before it is
+	// taken for long-polling or streaming and no longer waited for.
+	backgroundRequest = 3 * time.Second
+)
+
+// settleProbeJS returns a string that changes whenever the page does: its
+// document, load state, scroll position, and a count of DOM mutations. Style
+// and data-bua-id attribute changes are not counted, so JavaScript
+// animations do not keep the page from settling.
+const settleProbeJS = `() => {
+	if (!window.__buaSettle) {
+		const s = { mutations: 0 };
+		new MutationObserver((records) => {
+			for (const r of records) {
+				if (r.type === 'attributes' && (r.attributeName === 'style' || r.attributeName === 'data-bua-id')) continue;
+				s.mutations++;
+			}
+		}).observe(document, { childList: true, subtree: true, characterData: true, attributes: true });
+		window.__buaSettle = s;
+	}
+	return [document.readyState, performance.timeOrigin, location.href,
+		Math.round(scrollX), Math.round(scrollY), window.__buaSettle.mutations].join('|');
+}`
+
+// settleWatch follows a page's network requests from before an action until
+// the page has settled after it.
+type settleWatch struct {
+	page   *rod.Page
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	inflight map[proto.NetworkRequestID]time.Time
+	activity time.Time
+}
+
+// watchSettle starts following page's network requests. Call it before an
+// action, so requests the action starts are seen, and wait after it.
+func (b *Browser) watchSettle(page *rod.Page) *settleWatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &settleWatch{
+		page:     page,
+		cancel:   cancel,
+		inflight: make(map[proto.NetworkRequestID]time.Time),
+		activity: time.Now(),
+	}
+	// Leave the Network domain enabled, rather than let EachEvent disable it
+	// again, which would also reset overrides such as extra headers
+	_ = page.EnableDomain(&proto.NetworkEnable{})
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Type == proto.NetworkResourceTypeWebSocket || e.Type == proto.NetworkResourceTypeEventSource {
+				return
+			}
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.inflight[e.RequestID] = time.Now()
+			w.activity = time.Now()
+		},
+		func(e *proto.NetworkLoadingFinished) { w.finish(e.RequestID) },
+		func(e *proto.NetworkLoadingFailed) { w.finish(e.RequestID) },
+	)
+	go wait()
+
+	// Install the mutation counter, so mutations the action causes count
+	_, _ = page.Timeout(time.Second).Eval(settleProbeJS)
+	return w
+}
+
+// stop stops following the page, if wait has not already.
+func (w *settleWatch) stop() {
+	w.cancel()
+}
+
+// settle waits for page to settle from whatever changed it last, without
+// seeing requests already in flight.
+func (b *Browser) settle(page *rod.Page, kind settleKind) {
+	b.watchSettle(page).wait(kind)
+}
+
+// finish records that a request completed or failed.
+func (w *settleWatch) finish(id proto.NetworkRequestID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.inflight, id)
+	w.activity = time.Now()
+}
+
+// networkQuietSince returns when network activity last changed, or false if
+// requests are pending that are still worth waiting for.
+func (w *settleWatch) networkQuietSince(now time.Time) (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, started := range w.inflight {
+		if now.Sub(started) < backgroundRequest {
+			return time.Time{}, false
+		}
+	}
+	return w.activity, true
+}
+
+// wait returns once the page has settled after an action of the given kind:
+// no pending requests, the document loaded as far as kind needs, and no
+// changes for its quiet period. It gives up silently after kind's timeout,
+// as pages that never settle are still worth acting on. The watch stops
+// following the page.
+func (w *settleWatch) wait(kind settleKind) {
+	defer w.stop()
+
+	h := settleHeuristics[kind]
+	deadline := time.Now().Add(h.timeout)
+	var last string
+	changed := time.Now()
+	for time.Now().Before(deadline) {
+		now := time.Now()
+		// The probe fails while the document is being replaced
+		result, err := w.page.Timeout(time.Second).Eval(settleProbeJS)
+		state := ""
+		if err == nil {
+			state = result.Value.Str()
+		}
+		if err != nil || state != last {
+			last = state
+			changed = now
+		}
+
+		networkQuiet, idle := w.networkQuietSince(now)
+		if err == nil && idle && loaded(state, h.load) &&
+			now.Sub(changed) >= h.quiet && now.Sub(networkQuiet) >= h.quiet {
+			return
+		}
+		time.Sleep(settlePoll)
+	}
+}
+
+// loaded reports whether the probed document.readyState is far enough along.
+func loaded(state string, complete bool) bool {
+	if strings.HasPrefix(state, "complete|") {
+		return true
+	}
+	return !complete && strings.HasPrefix(state, "interactive|")
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/anxuanzi/bua/stealth"
 )
 
 // StealthConfig configures anti-detection measures.
@@ -38,6 +40,11 @@ type StealthConfig struct {
 
 	// MaxDelay maximum delay between actions (ms).
 	MaxDelay int
+
+	// Fingerprint enables the full evasion set from the stealth package and
+	// presents this fingerprint. When set, UserAgent, Locale, Timezone and the
+	// WebGL fields are taken from the fingerprint instead.
+	Fingerprint *stealth.Fingerprint
 }
 
 // DefaultStealthConfig returns sensible stealth defaults.
@@ -191,12 +198,21 @@ const stealthJS = `
 })();
 `
 
-// applyStealthMode injects stealth JavaScript into a page.
-func applyStealthMode(page *rod.Page, cfg StealthConfig) error {
+// applyStealthMode injects stealth JavaScript into a page. With a
+// fingerprint and an emulated device, the fingerprint presents the device.
+func applyStealthMode(page *rod.Page, cfg StealthConfig, device *Device) error {
 	if !cfg.EnableStealth {
 		return nil
 	}
 
+	if cfg.Fingerprint != nil {
+		fp := *cfg.Fingerprint
+		if device != nil && device.UserAgent != "" {
+			fp = fp.ForDevice(device.UserAgent, device.Platform, device.Width, device.Height, device.Mobile)
+		}
+		return stealth.Apply(page, fp)
+	}
+
 	// Set user agent if specified
 	if cfg.UserAgent != "" {
 		if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
@@ -0,0 +1,67 @@
+package browser
+
+import "time"
+
+// activate makes tabID the active tab and enforces Config.MaxTabs. Tabs are
+// ranked by when they were last active, so the previous active tab is
+// marked used now. Must be called with b.mu held.
+func (b *Browser) activate(tabID string) {
+	if b.lastUsed == nil {
+		b.lastUsed = make(map[string]time.Time)
+	}
+	now := time.Now()
+	if b.activeTabID != "" {
+		b.lastUsed[b.activeTabID] = now
+	}
+	b.lastUsed[tabID] = now
+	b.activeTabID = tabID
+
+	b.enforceTabLimit()
+	b.followActiveTab()
+}
+
+// enforceTabLimit closes the least recently used background tabs while more
+// than Config.MaxTabs are open, for TakeClosedTabs to report. Must be
+// called with b.mu held.
+func (b *Browser) enforceTabLimit() {
+	if b.config.MaxTabs <= 0 {
+		return
+	}
+	for len(b.pages) > b.config.MaxTabs {
+		oldest := ""
+		for id := range b.pages {
+			if id == b.activeTabID {
+				continue
+			}
+			if oldest == "" || b.lastUsed[id].Before(b.lastUsed[oldest]) {
+				oldest = id
+			}
+		}
+		if oldest == "" {
+			return
+		}
+
+		info := TabInfo{ID: oldest, Label: b.labels[oldest]}
+		if pi, err := b.pages[oldest].Info(); err == nil {
+			info.URL, info.Title = pi.URL, pi.Title
+		}
+		_, info.Isolated = b.tabContexts[oldest]
+		if err := b.closeTab(oldest); err != nil {
+			b.log("Browser").Warn("Failed to close tab over the limit", "tab", oldest, "err", err)
+			return
+		}
+		b.closedTabs = append(b.closedTabs, info)
+		b.log("Browser").Debug("Closed least recently used tab", "tab", oldest, "url", info.URL, "max_tabs", b.config.MaxTabs)
+	}
+}
+
+// TakeClosedTabs returns the tabs closed to stay within Config.MaxTabs since
+// the last call.
+func (b *Browser) TakeClosedTabs() []TabInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	closed := b.closedTabs
+	b.closedTabs = nil
+	return closed
+}
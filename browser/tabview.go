@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// NewTabView opens a new tab in this browser process and returns a Browser
+// pinned to it. The view shares the process, cookies and storage with its
+// parent but has its own tab set, so agents driving different views can run
+// concurrently without switching each other's active tab.
+//
+// Closing the view closes only its tabs; the parent keeps running.
+func (b *Browser) NewTabView(ctx context.Context, url string) (*Browser, error) {
+	b.mu.RLock()
+	rodBrowser := b.rod
+	b.mu.RUnlock()
+
+	if rodBrowser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	return b.newView(ctx, rodBrowser, url, false)
+}
+
+// NewIncognitoView creates a fresh incognito browser context in this browser
+// process and returns a Browser pinned to a tab in it. Cookies, storage and
+// cache start empty and are discarded when the view is closed.
+func (b *Browser) NewIncognitoView(ctx context.Context, url string) (*Browser, error) {
+	b.mu.RLock()
+	rodBrowser := b.rod
+	b.mu.RUnlock()
+
+	if rodBrowser == nil {
+		return nil, fmt.Errorf("browser not started")
+	}
+
+	incognito, err := rodBrowser.Incognito()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create incognito context: %w", err)
+	}
+	if err := b.applyPermissionRules(incognito); err != nil {
+		_ = incognito.Close()
+		return nil, err
+	}
+	_ = b.applyDownloadBehavior(incognito)
+
+	view, err := b.newView(ctx, incognito, url, true)
+	if err != nil {
+		_ = incognito.Close()
+		return nil, err
+	}
+
+	b.log("Browser").Debug("Opened incognito context", "context", incognito.BrowserContextID)
+	return view, nil
+}
+
+// newView opens a tab through rodBrowser and wraps it in a view of b.
+func (b *Browser) newView(ctx context.Context, rodBrowser *rod.Browser, url string, incognito bool) (*Browser, error) {
+	b.mu.RLock()
+	extractor := b.extractor
+	b.mu.RUnlock()
+
+	targetURL := "about:blank"
+	if url != "" {
+		targetURL = url
+	}
+
+	page, err := rodBrowser.Page(proto.TargetCreateTarget{URL: targetURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tab view: %w", err)
+	}
+	_ = ctx // Context available for future use
+
+	if err := b.setupTab(page, url); err != nil {
+		page.Close()
+		return nil, err
+	}
+
+	tabID := generateTabID()
+	view := &Browser{
+		config:      b.config,
+		rod:         rodBrowser,
+		pages:       map[string]*rod.Page{tabID: page},
+		tabContexts: make(map[string]*rod.Browser),
+		activeTabID: tabID,
+		extractor:   extractor,
+		parent:      b,
+		incognito:   incognito,
+		tracing:     b.tracing,
+		logger:      b.logger,
+	}
+	view.watchNewTabs(rodBrowser)
+	return view, nil
+}
+
+// IsIncognito reports whether this browser is a view created by NewIncognitoView.
+func (b *Browser) IsIncognito() bool {
+	return b.incognito
+}
+
+// IsTabView reports whether this browser is a view created by NewTabView.
+func (b *Browser) IsTabView() bool {
+	return b.parent != nil
+}
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the browser's spans.
+const tracerName = "github.com/anxuanzi/bua/browser"
+
+// tracing holds the browser's tracer and the contexts its spans are started
+// in. Views share their parent's, as they share its connection.
+type tracing struct {
+	tracer trace.Tracer
+
+	mu sync.Mutex
+	// parent is set by SetTraceContext
+	parent context.Context
+	// op is the running browser operation, the parent of DevTools calls
+	op context.Context
+}
+
+func newTracing(tp trace.TracerProvider) *tracing {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &tracing{tracer: tp.Tracer(tracerName)}
+}
+
+// SetTraceContext makes the span in ctx the parent of browser operations
+// started without a context, as the agent's tools start them. Nil clears it.
+func (b *Browser) SetTraceContext(ctx context.Context) {
+	b.tracing.mu.Lock()
+	defer b.tracing.mu.Unlock()
+
+	b.tracing.parent = ctx
+}
+
+// startSpan starts the span of a browser operation. DevTools calls made
+// before end is called are traced as its children.
+func (b *Browser) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func()) {
+	t := b.tracing
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ctx == nil {
+		ctx = t.parent
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	prev := t.op
+	t.op = ctx
+
+	return ctx, func() {
+		span.End()
+		t.mu.Lock()
+		t.op = prev
+		t.mu.Unlock()
+	}
+}
+
+// tracingCDP traces every DevTools call as a child of the running browser
+// operation, or else of the trace context. With views driven in parallel, a
+// call is attributed to the most recently started operation.
+type tracingCDP struct {
+	tracing *tracing
+	inner   rod.CDPClient
+}
+
+func (c *tracingCDP) Event() <-chan *cdp.Event {
+	return c.inner.Event()
+}
+
+func (c *tracingCDP) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	parent := ctx
+	c.tracing.mu.Lock()
+	switch {
+	case c.tracing.op != nil:
+		parent = c.tracing.op
+	case c.tracing.parent != nil:
+		parent = c.tracing.parent
+	}
+	c.tracing.mu.Unlock()
+
+	_, span := c.tracing.tracer.Start(parent, "cdp "+method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("cdp.method", method)))
+	defer span.End()
+	if sessionID != "" {
+		span.SetAttributes(attribute.String("cdp.session_id", sessionID))
+	}
+
+	res, err := c.inner.Call(ctx, sessionID, method, params)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return res, err
+}
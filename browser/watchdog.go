@@ -0,0 +1,218 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// memoryCheckInterval is how often tabs are checked against
+// Config.MaxTabMemoryBytes.
+const memoryCheckInterval = 30 * time.Second
+
+// TabMetrics is the resource use of one tab, as reported by the renderer.
+type TabMetrics struct {
+	TabID string `json:"tab_id"`
+	URL   string `json:"url"`
+
+	// JSHeapUsedBytes is the memory the page's JavaScript heap uses; it is
+	// what Config.MaxTabMemoryBytes limits.
+	JSHeapUsedBytes  int64 `json:"js_heap_used_bytes"`
+	JSHeapTotalBytes int64 `json:"js_heap_total_bytes"`
+
+	// Nodes and Documents count the DOM nodes and documents alive in the
+	// renderer; both grow when a page leaks.
+	Nodes     int64 `json:"nodes"`
+	Documents int64 `json:"documents"`
+
+	// CPUTime is the time the renderer spent running the page's tasks.
+	CPUTime time.Duration `json:"cpu_time"`
+
+	// Recycles counts how often the tab was replaced for using too much
+	// memory.
+	Recycles int `json:"recycles"`
+}
+
+// memoryWatch holds the state of the tab memory watchdog.
+type memoryWatch struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	recycles  map[string]int // by tab ID
+}
+
+// TabMetrics returns the resource use of every open tab.
+func (b *Browser) TabMetrics(ctx context.Context) ([]TabMetrics, error) {
+	b.mu.RLock()
+	if b.rod == nil {
+		b.mu.RUnlock()
+		return nil, fmt.Errorf("browser not started")
+	}
+	pages := make(map[string]*rod.Page, len(b.pages))
+	for id, page := range b.pages {
+		pages[id] = page
+	}
+	b.mu.RUnlock()
+
+	metrics := make([]TabMetrics, 0, len(pages))
+	for id, page := range pages {
+		m, err := pageMetrics(page.Context(ctx))
+		if err != nil {
+			continue // the tab closed meanwhile
+		}
+		m.TabID = id
+		m.Recycles = b.memory().recycled(id)
+		metrics = append(metrics, *m)
+	}
+	return metrics, nil
+}
+
+// pageMetrics reads the performance metrics of page.
+func pageMetrics(page *rod.Page) (*TabMetrics, error) {
+	if err := (proto.PerformanceEnable{}).Call(page); err != nil {
+		return nil, fmt.Errorf("failed to enable performance metrics: %w", err)
+	}
+	res, err := proto.PerformanceGetMetrics{}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance metrics: %w", err)
+	}
+
+	m := &TabMetrics{}
+	if info, err := page.Info(); err == nil {
+		m.URL = info.URL
+	}
+	for _, metric := range res.Metrics {
+		switch metric.Name {
+		case "JSHeapUsedSize":
+			m.JSHeapUsedBytes = int64(metric.Value)
+		case "JSHeapTotalSize":
+			m.JSHeapTotalBytes = int64(metric.Value)
+		case "Nodes":
+			m.Nodes = int64(metric.Value)
+		case "Documents":
+			m.Documents = int64(metric.Value)
+		case "TaskDuration":
+			m.CPUTime = time.Duration(metric.Value * float64(time.Second))
+		}
+	}
+	return m, nil
+}
+
+// memory returns the watchdog state, creating it on first use.
+func (b *Browser) memory() *memoryWatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.memoryWatch == nil {
+		b.memoryWatch = &memoryWatch{recycles: make(map[string]int)}
+	}
+	return b.memoryWatch
+}
+
+// recycled returns how often the tab was recycled.
+func (w *memoryWatch) recycled(tabID string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.recycles[tabID]
+}
+
+// due reports whether tabs should be checked again, and if so notes the
+// check.
+func (w *memoryWatch) due() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if time.Since(w.lastCheck) < memoryCheckInterval {
+		return false
+	}
+	w.lastCheck = time.Now()
+	return true
+}
+
+// checkMemory recycles the tabs whose JavaScript heap exceeds
+// Config.MaxTabMemoryBytes. It is called before the page is read, i.e.
+// between actions, and checks at most every memoryCheckInterval.
+func (b *Browser) checkMemory(ctx context.Context) {
+	limit := b.config.MaxTabMemoryBytes
+	if limit <= 0 || !b.memory().due() {
+		return
+	}
+
+	metrics, err := b.TabMetrics(ctx)
+	if err != nil {
+		return
+	}
+	for _, m := range metrics {
+		if m.JSHeapUsedBytes <= limit {
+			continue
+		}
+		if err := b.recycleTab(ctx, m.TabID, m.URL); err != nil {
+			b.log("Watchdog").Warn("Failed to recycle tab", "tab", m.TabID, "err", err)
+			continue
+		}
+		b.log("Watchdog").Info("Recycled tab over memory limit",
+			"tab", m.TabID, "url", m.URL, "heap_bytes", m.JSHeapUsedBytes, "limit_bytes", limit)
+	}
+}
+
+// recycleTab replaces the tab's page with a fresh one in the same browser
+// context, so cookies and storage other than sessionStorage carry over, and
+// reopens url in it. The tab keeps its ID.
+func (b *Browser) recycleTab(ctx context.Context, tabID, url string) error {
+	b.mu.RLock()
+	old := b.pages[tabID]
+	b.mu.RUnlock()
+	if old == nil {
+		return fmt.Errorf("tab not found: %s", tabID)
+	}
+
+	page, err := old.Browser().Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return fmt.Errorf("failed to create new tab: %w", err)
+	}
+	if err := b.setupTab(page, ""); err != nil {
+		_ = page.Close()
+		return err
+	}
+	if url != "" && url != "about:blank" {
+		watch := b.watchSettle(page)
+		defer watch.stop()
+		if err := page.Context(ctx).Timeout(navigationTimeout).Navigate(url); err != nil {
+			_ = page.Close()
+			return fmt.Errorf("navigation failed: %w", err)
+		}
+		watch.wait(settleLoad)
+	}
+
+	b.mu.Lock()
+	if b.pages[tabID] != old {
+		// The tab was closed or recycled meanwhile
+		b.mu.Unlock()
+		_ = page.Close()
+		return nil
+	}
+	b.pages[tabID] = page
+	delete(b.frames, tabID)
+	if b.activeTabID == tabID {
+		if _, err := page.Activate(); err != nil {
+			b.log("Watchdog").Warn("Failed to activate recycled tab", "tab", tabID, "err", err)
+		}
+		b.followActiveTab()
+	}
+	b.mu.Unlock()
+
+	b.extractor.Invalidate(old)
+	if err := old.Close(); err != nil {
+		b.log("Watchdog").Warn("Failed to close old page of recycled tab", "tab", tabID, "err", err)
+	}
+
+	w := b.memory()
+	w.mu.Lock()
+	w.recycles[tabID]++
+	w.mu.Unlock()
+	return nil
+}
@@ -2,21 +2,43 @@ package bua
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/anxuanzi/bua/agent"
+	"github.com/anxuanzi/bua/artifact"
 	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/liveview"
+	"github.com/anxuanzi/bua/logging"
+	"github.com/anxuanzi/bua/stealth"
 )
 
 // Agent is the main interface for browser automation with LLM.
 type Agent struct {
-	config  Config
-	browser *browser.Browser
-	agent   *agent.BrowserAgent
-	started bool
-	mu      sync.RWMutex
+	config   Config
+	browser  *browser.Browser
+	agent    *agent.BrowserAgent
+	liveView *liveview.Server
+	logger   *slog.Logger
+	keys     *agent.KeyPool // nil without Config.APIKeys
+	started  bool
+	mu       sync.RWMutex
+
+	// runSlot holds a token while a task runs, as runs share the browser
+	runSlot chan struct{}
+
+	// cancelRun cancels the running task, if any
+	cancelRun context.CancelFunc
+
+	// runAgent is the running task's agent: a per-run one with
+	// IncognitoPerRun, a.agent otherwise
+	runAgent *agent.BrowserAgent
 }
 
 // New creates a new browser automation agent.
@@ -28,9 +50,32 @@ func New(cfg Config) (*Agent, error) {
 		return nil, err
 	}
 
-	return &Agent{
-		config: cfg,
-	}, nil
+	a := &Agent{
+		config:  cfg,
+		logger:  logging.Or(cfg.Logger, cfg.Debug),
+		runSlot: make(chan struct{}, 1),
+	}
+	if len(cfg.APIKeys) > 0 {
+		a.keys = agent.NewKeyPool(append([]string{cfg.APIKey}, cfg.APIKeys...))
+	}
+	return a, nil
+}
+
+// KeyUsage reports how one of Config.APIKeys has been used.
+type KeyUsage = agent.KeyUsage
+
+// APIKeyUsage returns the requests and rate limits of each key in
+// Config.APIKeys (with Config.APIKey first, if set), or nil without them.
+func (a *Agent) APIKeyUsage() []KeyUsage {
+	if a.keys == nil {
+		return nil
+	}
+	return a.keys.Usage()
+}
+
+// log returns the agent's logger tagged with a component.
+func (a *Agent) log(component string) *slog.Logger {
+	return logging.Component(a.logger, component)
 }
 
 // Start launches the browser and initializes the agent.
@@ -44,14 +89,89 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	// Create browser configuration
 	browserCfg := browser.Config{
-		Headless:          a.config.Headless,
-		ProfileDir:        a.config.ProfileDir,
-		ProfileName:       a.config.ProfileName,
-		ViewportWidth:     a.config.Viewport.Width,
-		ViewportHeight:    a.config.Viewport.Height,
-		ShowHighlight:     a.config.ShowHighlight,
-		HighlightDuration: time.Duration(a.config.HighlightDurationMs) * time.Millisecond,
-		Debug:             a.config.Debug,
+		Headless:           a.config.Headless,
+		ProfileDir:         a.config.ProfileDir,
+		ProfileName:        a.config.ProfileName,
+		ViewportWidth:      a.config.Viewport.Width,
+		ViewportHeight:     a.config.Viewport.Height,
+		ShowHighlight:      a.config.ShowHighlight,
+		Annotations:        a.config.Annotations,
+		ElementScope:       a.config.ElementScope,
+		NewTabPolicy:       a.config.NewTabPolicy,
+		MaxTabMemoryBytes:  a.config.MaxTabMemoryBytes,
+		MaxTabs:            a.config.MaxTabs,
+		HighlightDuration:  time.Duration(a.config.HighlightDurationMs) * time.Millisecond,
+		Debug:              a.config.Debug,
+		Device:             a.config.Device,
+		ColorScheme:        a.config.ColorScheme,
+		ReducedMotion:      a.config.ReducedMotion,
+		Locale:             a.config.Language,
+		Robots:             a.config.Robots,
+		Permissions:        a.config.Permissions,
+		Geolocation:        a.config.Geolocation,
+		BrowserPath:        a.config.BrowserPath,
+		BrowserRevision:    a.config.BrowserRevision,
+		BrowserDir:         a.config.BrowserDir,
+		OnInstallProgress:  a.config.OnBrowserInstall,
+		ControlURL:         a.config.ControlURL,
+		WSEndpoint:         a.config.WSEndpoint,
+		DownloadDir:        a.config.DownloadDir,
+		OnDownloadProgress: a.config.OnDownloadProgress,
+		TracerProvider:     a.config.TracerProvider,
+		Logger:             a.logger,
+	}
+	if a.config.Cassette != nil {
+		browserCfg.WrapCDP = a.config.Cassette.WrapCDP
+		browserCfg.Offline = a.config.Cassette.Replaying()
+	}
+
+	if a.config.Stealth {
+		fp, err := a.fingerprint()
+		if err != nil {
+			return err
+		}
+		if lang := a.config.Language; lang != "" {
+			// Present the same languages the pages are requested in
+			fp.Languages = []string{lang}
+			if base, _, ok := strings.Cut(lang, "-"); ok {
+				fp.Languages = append(fp.Languages, base)
+			}
+		}
+		browserCfg.Stealth = browser.DefaultStealthConfig()
+		browserCfg.Stealth.Fingerprint = &fp
+
+		// A rotated bundle includes the window size that goes with its screen
+		if a.config.FingerprintRotation != nil && a.config.Device == nil && fp.ViewportWidth > 0 {
+			browserCfg.ViewportWidth = fp.ViewportWidth
+			browserCfg.ViewportHeight = fp.ViewportHeight
+		}
+	}
+
+	if a.config.Humanize != nil {
+		browserCfg.Humanize = *a.config.Humanize
+	}
+
+	// Decrypt the profile's session data for the browser
+	if a.sealsProfile() {
+		if err := unsealProfile(a.profilePath(), a.config.ProfileKey); err != nil {
+			return fmt.Errorf("failed to unlock profile: %w", err)
+		}
+		defer func() {
+			if a.started {
+				return
+			}
+			if err := sealProfile(a.profilePath(), a.config.ProfileKey); err != nil {
+				a.log("Profile").Warn("Failed to lock profile; its session data is left unencrypted", "err", err)
+			}
+		}()
+	}
+
+	if a.config.Retention != nil {
+		if res, err := Cleanup(a.config); err != nil {
+			a.log("Cleanup").Warn("Failed to clean up old files", "err", err)
+		} else if len(res.Removed) > 0 {
+			a.log("Cleanup").Info("Removed old files", "count", len(res.Removed), "bytes", res.FreedBytes)
+		}
 	}
 
 	// Create browser
@@ -66,32 +186,204 @@ func (a *Agent) Start(ctx context.Context) error {
 	}
 	a.browser = b
 
+	// Restore saved sign-ins the profile has lost, e.g. after a crash
+	if err := agent.RestoreSessions(b, a.sessionDir(), a.config.Logins); err != nil {
+		a.log("Login").Warn("Failed to restore sessions", "err", err)
+	}
+
+	// Start live view server if configured
+	if a.config.LiveViewAddr != "" {
+		lv := liveview.New(b, a.config.LiveViewAddr)
+		lv.Logger = a.logger
+		if err := lv.Start(); err != nil {
+			b.Close()
+			return fmt.Errorf("failed to start live view: %w", err)
+		}
+		a.liveView = lv
+		a.log("LiveView").Info("Watching", "url", lv.URL())
+	}
+
 	// Create browser agent
+	browserAgent, err := agent.NewBrowserAgent(ctx, a.agentConfig(a.config.ScreenshotDir), b)
+	if err != nil {
+		if a.liveView != nil {
+			a.liveView.Close()
+			a.liveView = nil
+		}
+		b.Close()
+		return fmt.Errorf("failed to create agent: %w", err)
+	}
+	a.agent = browserAgent
+
+	a.started = true
+	return nil
+}
+
+// fingerprint returns the stealth fingerprint for this agent's profile.
+func (a *Agent) fingerprint() (Fingerprint, error) {
+	if a.config.Fingerprint != nil {
+		return *a.config.Fingerprint, nil
+	}
+
+	rotation := a.config.FingerprintRotation
+	if a.config.ProfileName == "" {
+		if rotation != nil {
+			return rotation.Next(), nil
+		}
+		return stealth.Random(), nil
+	}
+
+	profileDir := filepath.Join(a.config.ProfileDir, a.config.ProfileName)
+	var fp Fingerprint
+	var err error
+	if rotation != nil {
+		fp, err = rotation.ForProfile(profileDir)
+	} else {
+		fp, err = stealth.ForProfile(profileDir)
+	}
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to load profile fingerprint: %w", err)
+	}
+	return fp, nil
+}
+
+// downloadOptions returns the download settings from the config.
+func (a *Agent) downloadOptions() browser.DownloadOptions {
+	return browser.DownloadOptions{
+		Dir:        a.config.DownloadDir,
+		MaxBytes:   a.config.MaxDownloadBytes,
+		OnProgress: a.config.OnDownloadProgress,
+	}
+}
+
+// profilePath returns the directory of the named profile.
+func (a *Agent) profilePath() string {
+	return filepath.Join(a.config.ProfileDir, a.config.ProfileName)
+}
+
+// sealsProfile reports whether the profile is encrypted at rest. Temporary
+// profiles are deleted on close and remote browsers keep their own profile.
+func (a *Agent) sealsProfile() bool {
+	return a.config.ProfileKey != "" && a.config.ProfileName != "" &&
+		a.config.ControlURL == "" && a.config.WSEndpoint == ""
+}
+
+// sessionDir returns where sign-in cookies are saved, or "" for temporary profiles.
+func (a *Agent) sessionDir() string {
+	if a.config.ProfileName == "" {
+		return ""
+	}
+	return filepath.Join(a.profilePath(), "bua-sessions")
+}
+
+// agentConfig builds the internal agent configuration, saving screenshots to screenshotDir.
+func (a *Agent) agentConfig(screenshotDir string) agent.AgentConfig {
 	agentCfg := agent.AgentConfig{
 		APIKey:          a.config.APIKey,
 		Model:           a.config.Model,
+		Generation:      a.config.Generation,
+		Language:        a.config.Language,
+		Architecture:    string(a.config.Architecture),
+		PlannerModel:    a.config.PlannerModel,
+		VerifyDone:      a.config.VerifyResults,
 		MaxSteps:        a.config.MaxSteps,
+		MaxCost:         a.config.MaxCost,
+		Pricing:         a.config.Pricing,
 		TextOnly:        a.config.TextOnly,
 		MaxWidth:        a.config.ScreenshotMaxWidth,
 		Debug:           a.config.Debug,
-		ScreenshotDir:   a.config.ScreenshotDir,
+		ScreenshotDir:   screenshotDir,
 		ShowAnnotations: a.config.ShowAnnotations,
-	}
+		CaptchaSolver:   a.config.CaptchaSolver,
+		OCR:             a.config.OCR,
+		Consent:         a.config.Consent,
+		RateLimit:       a.config.RateLimit,
+		IgnoreBlocks:    a.config.IgnoreBlocks,
+		OnHumanTakeover: a.config.OnHumanTakeover,
+		TOTPSecrets:     a.config.TOTPSecrets,
+		Inbox:           a.config.Inbox,
+		Logins:          a.config.Logins,
+		Secrets:         a.config.Secrets,
+		SessionDir:      a.sessionDir(),
+		Downloads:       a.downloadOptions(),
+		TracerProvider:  a.config.TracerProvider,
+		Keys:            a.keys,
+		Logger:          a.logger,
+		Hooks:           a.config.Hooks.agentHooks(),
+		ToolMiddleware:  a.config.ToolMiddleware,
+		Retry:           a.config.Retry,
+		ContextCaching:  a.config.ContextCaching,
 
-	browserAgent, err := agent.NewBrowserAgent(ctx, agentCfg, b)
-	if err != nil {
-		b.Close()
-		return fmt.Errorf("failed to create agent: %w", err)
+		SystemPromptExtra:    a.config.SystemPromptExtra,
+		SystemPromptOverride: a.config.SystemPromptOverride,
+		FullElementLists:     a.config.FullElementLists,
+		SelectorTools:        a.config.SelectorTools,
+		AllowJSEval:          a.config.AllowJSEval,
+		JSEvalOrigins:        a.config.JSEvalOrigins,
+		DangerouslyAllowCDP:  a.config.DangerouslyAllowCDP,
+		SiteRules:            a.config.SiteRules,
+		Examples:             a.config.Examples,
+		DisableFinalTurn:     a.config.DisableFinalTurn,
+		StrictSuccess:        a.config.StrictSuccess,
 	}
-	a.agent = browserAgent
-
-	a.started = true
-	return nil
+	if c := a.config.Cassette; c != nil {
+		agentCfg.HTTPClient = c.HTTPClient()
+		if c.Replaying() && agentCfg.APIKey == "" {
+			// The model client insists on a key; replayed requests never use it
+			agentCfg.APIKey = "replay"
+		}
+	}
+	if a.liveView != nil || a.config.OnStep != nil {
+		lv, onStep := a.liveView, a.config.OnStep
+		agentCfg.OnStep = func(s agent.Step) {
+			step := convertStep(s)
+			if lv != nil {
+				lv.Publish("step", step)
+			}
+			if onStep != nil {
+				onStep(step)
+			}
+		}
+	}
+	return agentCfg
 }
 
+// RunOptions adjust a single run, e.g. limiting a read-only research task to
+// navigate, scroll and get_page_state so it cannot click, type or download.
+type RunOptions = agent.RunOptions
+
 // Run executes a task described in natural language.
 // Returns a Result containing the outcome and execution details.
+//
+// An Agent runs one task at a time, as tasks share its browser: Run returns
+// ErrBusy while another task is running. Use RunQueued to wait instead.
 func (a *Agent) Run(ctx context.Context, task string) (*Result, error) {
+	return a.RunWithOptions(ctx, task, RunOptions{})
+}
+
+// RunWithOptions executes a task like Run, adjusted by opts.
+func (a *Agent) RunWithOptions(ctx context.Context, task string, opts RunOptions) (*Result, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	select {
+	case a.runSlot <- struct{}{}:
+	default:
+		return nil, ErrBusy
+	}
+	defer func() { <-a.runSlot }()
+	return a.runTask(ctx, task, opts)
+}
+
+// RunQueued executes a task like RunWithOptions, but waits for running
+// tasks to finish instead of returning ErrBusy. Queued tasks run in the
+// order they were queued. It returns ctx's error if ctx is done first.
+func (a *Agent) RunQueued(ctx context.Context, task string, opts RunOptions) (*Result, error) {
 	a.mu.RLock()
 	started := a.started
 	a.mu.RUnlock()
@@ -100,40 +392,204 @@ func (a *Agent) Run(ctx context.Context, task string) (*Result, error) {
 		return nil, ErrNotStarted
 	}
 
+	select {
+	case a.runSlot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-a.runSlot }()
+	return a.runTask(ctx, task, opts)
+}
+
+// runTask runs a task holding the run slot, with hooks and the completion
+// webhook.
+func (a *Agent) runTask(ctx context.Context, task string, opts RunOptions) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The agent may have been closed while the task waited its turn
+	a.mu.Lock()
+	if !a.started {
+		a.mu.Unlock()
+		return nil, ErrNotStarted
+	}
+	a.cancelRun = cancel
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.cancelRun = nil
+		a.runAgent = nil
+		a.mu.Unlock()
+	}()
+
+	start := time.Now()
+	result, err := a.run(ctx, task, opts)
+	a.config.Hooks.finish(result, err)
+	if a.config.CompletionWebhook != nil {
+		a.notifyCompletion(ctx, task, result, err, time.Since(start))
+	}
+	return result, err
+}
+
+// run executes a task on the started agent.
+func (a *Agent) run(ctx context.Context, task string, opts RunOptions) (*Result, error) {
+	runBrowser, runAgent := a.browser, a.agent
+
+	// Isolate the run in a throwaway incognito context if enabled
+	if a.config.IncognitoPerRun {
+		url := a.browser.GetURL()
+		if url == "about:blank" {
+			url = ""
+		}
+		view, err := a.browser.NewIncognitoView(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		defer view.Close()
+
+		browserAgent, err := agent.NewBrowserAgent(ctx, a.agentConfig(a.config.ScreenshotDir), view)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create agent: %w", err)
+		}
+		defer browserAgent.Close()
+
+		runBrowser, runAgent = view, browserAgent
+	}
+	a.mu.Lock()
+	a.runAgent = runAgent
+	a.mu.Unlock()
+
+	// Start recording the session if enabled
+	var recorder *browser.Recorder
+	if a.config.RecordVideo {
+		path := filepath.Join(a.config.RecordingDir, fmt.Sprintf("run_%d.gif", time.Now().UnixMilli()))
+		rec, err := runBrowser.StartRecording(path)
+		if err != nil {
+			a.log("Recording").Warn("Failed to start", "err", err)
+		} else {
+			recorder = rec
+		}
+	}
+
+	if a.liveView != nil {
+		// Show the browser the run works in, and the shared one again after
+		if runBrowser != a.browser {
+			a.liveView.SetBrowser(runBrowser)
+			defer a.liveView.SetBrowser(a.browser)
+		}
+		a.liveView.Publish("run_started", map[string]string{"task": task})
+	}
+
 	// Execute the task
-	agentResult, err := a.agent.Run(ctx, task)
+	agentResult, err := runAgent.RunWithOptions(ctx, task, opts)
+
+	// Finish the recording even if the run failed; it is most useful then
+	var recordingPath string
+	if recorder != nil {
+		path, recErr := recorder.Stop()
+		if recErr != nil {
+			a.log("Recording").Warn("Failed to save", "err", recErr)
+		} else {
+			recordingPath = path
+			a.log("Recording").Debug("Saved", "path", path)
+		}
+	}
+
+	if a.liveView != nil {
+		if err != nil {
+			a.liveView.Publish("run_finished", map[string]any{"success": false, "error": err.Error()})
+		} else {
+			a.liveView.Publish("run_finished", map[string]any{"success": agentResult.Success, "error": agentResult.Error, "data": agentResult.Data})
+		}
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert agent result to public Result type
-	result := &Result{
-		Success:         agentResult.Success,
-		Data:            agentResult.Data,
-		Error:           agentResult.Error,
-		Duration:        agentResult.Duration,
-		TokensUsed:      agentResult.TokensUsed,
-		Steps:           make([]Step, len(agentResult.Steps)),
-		ScreenshotPaths: agentResult.ScreenshotPaths,
-	}
-
-	for i, s := range agentResult.Steps {
-		result.Steps[i] = Step{
-			Number:         s.Number,
-			Action:         s.Action,
-			Target:         s.Target,
-			Thinking:       s.Thinking,
-			Evaluation:     s.Evaluation,
-			NextGoal:       s.NextGoal,
-			Memory:         s.Memory,
-			Duration:       time.Duration(s.DurationMs) * time.Millisecond,
-			ScreenshotPath: s.ScreenshotPath,
-		}
+	result := convertResult(agentResult)
+	result.RecordingPath = recordingPath
+	result.DownloadPaths = runAgent.GetDownloadPaths()
+	result.Assertions = runAgent.GetAssertions()
+	result.Sections = runAgent.GetSections()
+	result.Findings = runAgent.GetFindings()
+
+	if a.config.Artifacts != nil {
+		result.Artifacts = a.uploadArtifacts(ctx, result)
 	}
 
 	return result, nil
 }
 
+// uploadArtifacts uploads the files a run produced to Config.Artifacts.
+func (a *Agent) uploadArtifacts(ctx context.Context, result *Result) []Artifact {
+	prefix := path.Join(a.config.ArtifactPrefix, fmt.Sprintf("run_%d", time.Now().UnixMilli()))
+	artifacts := artifact.Upload(ctx, a.config.Artifacts, prefix, map[artifact.Kind][]string{
+		artifact.KindScreenshot: result.ScreenshotPaths,
+		artifact.KindRecording:  {result.RecordingPath},
+		artifact.KindDownload:   result.DownloadPaths,
+	})
+
+	for _, art := range artifacts {
+		if art.Error != "" {
+			a.log("Artifacts").Warn("Failed to upload", "path", art.Path, "err", art.Error)
+		}
+	}
+	return artifacts
+}
+
+// convertResult converts an internal agent result to the public Result type.
+func convertResult(r *agent.Result) *Result {
+	result := &Result{
+		Success:         r.Success,
+		Data:            r.Data,
+		Error:           r.Error,
+		ErrorCode:       r.ErrorCode,
+		Summary:         r.Summary,
+		Duration:        r.Duration,
+		TokensUsed:      r.TokensUsed,
+		CachedTokens:    r.CachedTokens,
+		Usage:           r.Usage,
+		EstimatedCost:   r.EstimatedCost,
+		Blocked:         r.Blocked,
+		PartialResult:   r.PartialResult,
+		Steps:           make([]Step, len(r.Steps)),
+		ScreenshotPaths: r.ScreenshotPaths,
+
+		CompletionReason: r.CompletionReason,
+		Tabs:             r.Tabs,
+	}
+
+	for i, s := range r.Steps {
+		result.Steps[i] = convertStep(s)
+	}
+
+	return result
+}
+
+// convertStep converts an internal agent step to the public Step type.
+func convertStep(s agent.Step) Step {
+	return Step{
+		Number:              s.Number,
+		Action:              s.Action,
+		Target:              s.Target,
+		Thinking:            s.Thinking,
+		Evaluation:          s.Evaluation,
+		NextGoal:            s.NextGoal,
+		Memory:              s.Memory,
+		URL:                 s.URL,
+		Title:               s.Title,
+		Duration:            time.Duration(s.DurationMs) * time.Millisecond,
+		Result:              s.Result,
+		Success:             s.Success,
+		Error:               s.Error,
+		ErrorCode:           s.ErrorCode,
+		ScreenshotPath:      s.ScreenshotPath,
+		AfterScreenshotPath: s.AfterScreenshotPath,
+		Usage:               s.Usage,
+	}
+}
+
 // Navigate opens a URL in the browser.
 // This is a convenience method for direct navigation without a task.
 func (a *Agent) Navigate(ctx context.Context, url string) error {
@@ -148,11 +604,17 @@ func (a *Agent) Navigate(ctx context.Context, url string) error {
 	return a.browser.Navigate(ctx, url)
 }
 
-// Close shuts down the browser and cleans up resources.
+// Close shuts down the browser and cleans up resources. It does not wait
+// for a running task; see CloseGracefully.
 func (a *Agent) Close() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	return a.close()
+}
+
+// close closes the agent. Must be called with a.mu held.
+func (a *Agent) close() error {
 	if !a.started {
 		return nil
 	}
@@ -166,6 +628,13 @@ func (a *Agent) Close() error {
 		a.agent = nil
 	}
 
+	if a.liveView != nil {
+		if err := a.liveView.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		a.liveView = nil
+	}
+
 	if a.browser != nil {
 		if err := a.browser.Close(); err != nil {
 			errs = append(errs, err)
@@ -173,6 +642,18 @@ func (a *Agent) Close() error {
 		a.browser = nil
 	}
 
+	if a.sealsProfile() {
+		if err := sealProfile(a.profilePath(), a.config.ProfileKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if a.config.Cassette != nil {
+		if err := a.config.Cassette.Save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	a.started = false
 
 	if len(errs) > 0 {
@@ -181,6 +662,74 @@ func (a *Agent) Close() error {
 	return nil
 }
 
+// artifactFlushTimeout bounds the upload of an interrupted task's files by
+// CloseGracefully.
+const artifactFlushTimeout = 30 * time.Second
+
+// CloseGracefully shuts the agent down without cutting a task short: it
+// waits for the running task or RunParallel batch to finish, or cancels it
+// once ctx is done, uploads the files of a canceled task to
+// Config.Artifacts, saves the sessions of Config.Logins, and then closes
+// like Close. Tasks started or queued meanwhile fail with ErrBusy or
+// ErrNotStarted.
+func (a *Agent) CloseGracefully(ctx context.Context) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil
+	}
+
+	// Take the run slot, so no task starts after the running one
+	var canceled *agent.BrowserAgent
+	select {
+	case a.runSlot <- struct{}{}:
+	case <-ctx.Done():
+		a.mu.RLock()
+		cancel, runAgent := a.cancelRun, a.runAgent
+		a.mu.RUnlock()
+		if cancel != nil {
+			a.log("Agent").Warn("Canceling the running task to close")
+			cancel()
+			canceled = runAgent
+		}
+		a.runSlot <- struct{}{}
+	}
+	defer func() { <-a.runSlot }()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.started {
+		return nil
+	}
+
+	var errs []error
+	// The canceled task's agent, not a.agent: with IncognitoPerRun they differ
+	if canceled != nil && a.config.Artifacts != nil {
+		uploadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), artifactFlushTimeout)
+		a.uploadArtifacts(uploadCtx, &Result{
+			ScreenshotPaths: canceled.GetScreenshotPaths(),
+			DownloadPaths:   canceled.GetDownloadPaths(),
+		})
+		cancel()
+	}
+	if a.browser != nil {
+		if err := agent.SaveSessions(a.browser, a.sessionDir(), a.config.Logins); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save sessions: %w", err))
+		}
+	}
+	if err := a.close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors during close: %v", errs)
+	}
+	return nil
+}
+
 // GetURL returns the current page URL.
 func (a *Agent) GetURL() string {
 	a.mu.RLock()
@@ -203,6 +752,17 @@ func (a *Agent) GetTitle() string {
 	return a.browser.GetTitle()
 }
 
+// LiveViewURL returns the URL of the live view page, or empty if disabled.
+func (a *Agent) LiveViewURL() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.liveView == nil {
+		return ""
+	}
+	return a.liveView.URL()
+}
+
 // IsStarted returns whether the agent has been started.
 func (a *Agent) IsStarted() bool {
 	a.mu.RLock()
@@ -223,6 +783,21 @@ func (a *Agent) NewTab(ctx context.Context, url string) (string, error) {
 	return a.browser.NewTab(ctx, url)
 }
 
+// NewIsolatedTab opens a tab in its own browser context with separate cookies
+// and storage, e.g. to be logged into two accounts of the same site at once.
+// Returns the tab ID.
+func (a *Agent) NewIsolatedTab(ctx context.Context, url string) (string, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return "", ErrNotStarted
+	}
+
+	return a.browser.NewIsolatedTab(ctx, url)
+}
+
 // SwitchTab switches to a different tab by ID.
 func (a *Agent) SwitchTab(tabID string) error {
 	a.mu.RLock()
@@ -236,6 +811,21 @@ func (a *Agent) SwitchTab(tabID string) error {
 	return a.browser.SwitchTab(tabID)
 }
 
+// LabelTab names what a tab is for, e.g. "GitHub stats". The agent sees the
+// label in list_tabs and can switch to the tab by it. An empty label
+// removes it.
+func (a *Agent) LabelTab(tabID, label string) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.LabelTab(tabID, label)
+}
+
 // CloseTab closes a tab by ID.
 func (a *Agent) CloseTab(tabID string) error {
 	a.mu.RLock()
@@ -262,21 +852,223 @@ func (a *Agent) ListTabs() []TabInfo {
 	result := make([]TabInfo, len(tabs))
 	for i, t := range tabs {
 		result[i] = TabInfo{
-			ID:     t.ID,
-			URL:    t.URL,
-			Title:  t.Title,
-			Active: t.Active,
+			ID:       t.ID,
+			URL:      t.URL,
+			Title:    t.Title,
+			Active:   t.Active,
+			Isolated: t.Isolated,
+			Label:    t.Label,
 		}
 	}
 	return result
 }
 
+// TabSnapshot is the screenshot and element map of one tab.
+type TabSnapshot = browser.TabSnapshot
+
+// CaptureAllTabs takes a screenshot and the element map of every open tab
+// concurrently, sorted by tab ID.
+func (a *Agent) CaptureAllTabs(ctx context.Context) ([]TabSnapshot, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.CaptureAllTabs(ctx)
+}
+
+// TabMetrics is the memory and CPU use of one tab.
+type TabMetrics = browser.TabMetrics
+
+// TabMetrics returns the memory and CPU use of every open tab, and how often
+// each was recycled for Config.MaxTabMemoryBytes.
+func (a *Agent) TabMetrics(ctx context.Context) ([]TabMetrics, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.TabMetrics(ctx)
+}
+
+// Screenshot captures the current page as a JPEG.
+func (a *Agent) Screenshot(ctx context.Context, fullPage bool) ([]byte, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.Screenshot(ctx, fullPage)
+}
+
+// Download saves a URL (relative URLs resolve against the current page) to
+// Config.DownloadDir using the browser's cookies, resuming an earlier partial
+// download of the same file.
+func (a *Agent) Download(ctx context.Context, url string) (*DownloadResult, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.DownloadResource(ctx, url, a.downloadOptions())
+}
+
+// Cookie is a browser cookie.
+type Cookie = browser.Cookie
+
+// Cookies returns all cookies in the browser.
+func (a *Agent) Cookies(ctx context.Context) ([]Cookie, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.GetCookies(ctx)
+}
+
+// SetCookies adds or replaces cookies in the browser.
+func (a *Agent) SetCookies(ctx context.Context, cookies []Cookie) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.SetCookies(ctx, cookies)
+}
+
+// ClearCookies removes all cookies from the browser.
+func (a *Agent) ClearCookies(ctx context.Context) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.ClearCookies(ctx)
+}
+
+// GrantPermissions allows permissions for an origin ("" for all origins) without a prompt.
+func (a *Agent) GrantPermissions(ctx context.Context, origin string, perms ...Permission) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.GrantPermissions(ctx, origin, perms...)
+}
+
+// DenyPermissions rejects permissions for an origin ("" for all origins) without a prompt.
+func (a *Agent) DenyPermissions(ctx context.Context, origin string, perms ...Permission) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.DenyPermissions(ctx, origin, perms...)
+}
+
+// ResetPermissions restores the default prompt behavior for all permissions.
+func (a *Agent) ResetPermissions(ctx context.Context) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.ResetPermissions(ctx)
+}
+
+// SetGeolocation overrides the position reported to the current page. Pass nil to clear it.
+func (a *Agent) SetGeolocation(ctx context.Context, geo *Geolocation) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.SetGeolocation(ctx, geo)
+}
+
+// SetColorScheme switches the emulated prefers-color-scheme for all tabs.
+func (a *Agent) SetColorScheme(ctx context.Context, scheme ColorScheme) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.SetColorScheme(ctx, scheme)
+}
+
+// SetReducedMotion toggles the emulated prefers-reduced-motion for all tabs.
+func (a *Agent) SetReducedMotion(ctx context.Context, reduce bool) error {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return ErrNotStarted
+	}
+
+	return a.browser.SetReducedMotion(ctx, reduce)
+}
+
+// CallCDP sends a raw Chrome DevTools protocol command, e.g.
+// "Page.printToPDF", and returns its raw JSON result. Commands of the
+// Browser, Target, SystemInfo and Storage domains go to the browser, others
+// to the current tab. params is marshaled to JSON; nil sends none.
+func (a *Agent) CallCDP(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	return a.browser.CallCDP(ctx, method, params)
+}
+
 // TabInfo contains information about a browser tab.
 type TabInfo struct {
-	ID     string
-	URL    string
-	Title  string
-	Active bool
+	ID       string
+	URL      string
+	Title    string
+	Active   bool
+	Isolated bool   // Tab has its own cookies and storage
+	Label    string // What the tab is for, set with LabelTab or by the agent
 }
 
 // WithContext returns a helper for chaining operations with context.
@@ -0,0 +1,112 @@
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AntiCaptcha solves challenges with the Anti-Captcha service (anti-captcha.com).
+type AntiCaptcha struct {
+	// APIKey is the Anti-Captcha client key (required).
+	APIKey string
+
+	// BaseURL overrides the API host. Default: "https://api.anti-captcha.com".
+	BaseURL string
+
+	// PollInterval is how often to check for the answer. Default: 5s.
+	PollInterval time.Duration
+
+	// HTTPClient is used for API requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// antiCaptchaResponse covers the fields of createTask and getTaskResult responses.
+type antiCaptchaResponse struct {
+	ErrorID          int    `json:"errorId"`
+	ErrorDescription string `json:"errorDescription"`
+	TaskID           int64  `json:"taskId"`
+	Status           string `json:"status"`
+	Solution         struct {
+		GRecaptchaResponse string `json:"gRecaptchaResponse"`
+		Token              string `json:"token"`
+	} `json:"solution"`
+}
+
+// Solve creates a task and polls until a token is ready or ctx ends.
+func (s *AntiCaptcha) Solve(ctx context.Context, c Challenge) (string, error) {
+	if s.APIKey == "" {
+		return "", errors.New("anti-captcha: API key required")
+	}
+
+	var taskType string
+	switch c.Kind {
+	case KindReCaptcha:
+		taskType = "RecaptchaV2TaskProxyless"
+	case KindHCaptcha:
+		taskType = "HCaptchaTaskProxyless"
+	case KindTurnstile:
+		taskType = "TurnstileTaskProxyless"
+	default:
+		return "", fmt.Errorf("anti-captcha: unsupported captcha kind %q", c.Kind)
+	}
+
+	var created antiCaptchaResponse
+	if err := s.call(ctx, "/createTask", map[string]any{
+		"clientKey": s.APIKey,
+		"task": map[string]any{
+			"type":       taskType,
+			"websiteURL": c.PageURL,
+			"websiteKey": c.SiteKey,
+		},
+	}, &created); err != nil {
+		return "", err
+	}
+
+	return poll(ctx, s.PollInterval, func() (string, bool, error) {
+		var res antiCaptchaResponse
+		if err := s.call(ctx, "/getTaskResult", map[string]any{
+			"clientKey": s.APIKey,
+			"taskId":    created.TaskID,
+		}, &res); err != nil {
+			return "", false, err
+		}
+		if res.Status != "ready" {
+			return "", false, nil
+		}
+		if res.Solution.Token != "" {
+			return res.Solution.Token, true, nil
+		}
+		return res.Solution.GRecaptchaResponse, true, nil
+	})
+}
+
+// call posts a JSON request and decodes the response, surfacing API errors.
+func (s *AntiCaptcha) call(ctx context.Context, path string, body any, out *antiCaptchaResponse) error {
+	base := s.BaseURL
+	if base == "" {
+		base = "https://api.anti-captcha.com"
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("anti-captcha: failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("anti-captcha: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := doJSON(s.HTTPClient, req, out); err != nil {
+		return err
+	}
+	if out.ErrorID != 0 {
+		return fmt.Errorf("anti-captcha: %s", out.ErrorDescription)
+	}
+	return nil
+}
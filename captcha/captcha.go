@@ -0,0 +1,166 @@
+// Package captcha detects reCAPTCHA, hCaptcha and Cloudflare Turnstile
+// challenges on a page and injects tokens obtained from a solving service.
+//
+// A Solver turns a Challenge into a response token. TwoCaptcha and AntiCaptcha
+// call the respective services; SolverFunc adapts any other implementation.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// Kind identifies a CAPTCHA provider.
+type Kind string
+
+const (
+	// KindReCaptcha is Google reCAPTCHA v2 (checkbox or invisible).
+	KindReCaptcha Kind = "recaptcha"
+
+	// KindHCaptcha is hCaptcha.
+	KindHCaptcha Kind = "hcaptcha"
+
+	// KindTurnstile is Cloudflare Turnstile.
+	KindTurnstile Kind = "turnstile"
+)
+
+// Challenge describes a CAPTCHA found on a page.
+type Challenge struct {
+	Kind    Kind   `json:"kind"`
+	SiteKey string `json:"siteKey"`
+	PageURL string `json:"pageURL"`
+
+	// Callback is the widget's data-callback function name, if any.
+	Callback string `json:"callback,omitempty"`
+}
+
+// Solver obtains a response token for a challenge.
+type Solver interface {
+	Solve(ctx context.Context, c Challenge) (string, error)
+}
+
+// SolverFunc adapts a function to the Solver interface.
+type SolverFunc func(ctx context.Context, c Challenge) (string, error)
+
+// Solve calls f.
+func (f SolverFunc) Solve(ctx context.Context, c Challenge) (string, error) {
+	return f(ctx, c)
+}
+
+// detectJS finds the first CAPTCHA widget on the page, looking at widget
+// containers first and falling back to the provider's iframe URL.
+const detectJS = `() => {
+	const found = (kind, siteKey, el) => JSON.stringify({
+		kind: kind,
+		siteKey: siteKey || '',
+		pageURL: location.href,
+		callback: (el && el.getAttribute('data-callback')) || ''
+	});
+
+	const widgets = [
+		['recaptcha', '.g-recaptcha[data-sitekey]'],
+		['hcaptcha', '.h-captcha[data-sitekey]'],
+		['turnstile', '.cf-turnstile[data-sitekey]']
+	];
+	for (const [kind, selector] of widgets) {
+		const el = document.querySelector(selector);
+		if (el) return found(kind, el.getAttribute('data-sitekey'), el);
+	}
+
+	for (const frame of document.querySelectorAll('iframe[src]')) {
+		let url;
+		try { url = new URL(frame.src); } catch (e) { continue; }
+		if (/(google\.com|recaptcha\.net)$/.test(url.hostname) && url.pathname.includes('/recaptcha/') && url.searchParams.get('k')) {
+			return found('recaptcha', url.searchParams.get('k'), null);
+		}
+		if (url.hostname.endsWith('hcaptcha.com')) {
+			const key = url.searchParams.get('sitekey') || new URLSearchParams(url.hash.slice(1)).get('sitekey');
+			if (key) return found('hcaptcha', key, null);
+		}
+		if (url.hostname === 'challenges.cloudflare.com') {
+			const m = url.pathname.match(/(0x[0-9A-Za-z_-]{16,})/);
+			if (m) return found('turnstile', m[1], null);
+		}
+	}
+	return '';
+}`
+
+// Detect returns the CAPTCHA challenge on the page, or nil if there is none.
+func Detect(page *rod.Page) (*Challenge, error) {
+	res, err := page.Eval(detectJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect captcha: %w", err)
+	}
+
+	raw := res.Value.Str()
+	if raw == "" {
+		return nil, nil
+	}
+
+	var c Challenge
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return nil, fmt.Errorf("failed to parse captcha: %w", err)
+	}
+	return &c, nil
+}
+
+// injectJS writes the token into the widget's response fields and invokes
+// the site's callback so forms that submit on solve continue on their own.
+const injectJS = `(kind, token, callback) => {
+	const fields = {
+		recaptcha: ['g-recaptcha-response'],
+		hcaptcha: ['h-captcha-response', 'g-recaptcha-response'],
+		turnstile: ['cf-turnstile-response']
+	}[kind] || [];
+
+	for (const name of fields) {
+		let inputs = document.querySelectorAll('[name="' + name + '"]');
+		if (inputs.length === 0) {
+			const form = document.querySelector('form');
+			if (!form) continue;
+			const input = document.createElement('textarea');
+			input.name = name;
+			input.style.display = 'none';
+			form.appendChild(input);
+			inputs = [input];
+		}
+		for (const input of inputs) {
+			input.value = token;
+			input.innerHTML = token;
+		}
+	}
+
+	const call = (fn) => { try { fn(token); return true; } catch (e) { return false; } };
+	if (callback && typeof window[callback] === 'function') return call(window[callback]);
+
+	// reCAPTCHA widgets rendered from JS keep their callback in ___grecaptcha_cfg
+	if (kind === 'recaptcha' && window.___grecaptcha_cfg) {
+		const seen = new Set();
+		const walk = (obj, depth) => {
+			if (!obj || typeof obj !== 'object' || depth > 4 || seen.has(obj)) return false;
+			seen.add(obj);
+			for (const key of Object.keys(obj)) {
+				const v = obj[key];
+				if (key === 'callback') {
+					if (typeof v === 'function') return call(v);
+					if (typeof v === 'string' && typeof window[v] === 'function') return call(window[v]);
+				}
+				if (walk(v, depth + 1)) return true;
+			}
+			return false;
+		};
+		return walk(window.___grecaptcha_cfg.clients, 0);
+	}
+	return false;
+}`
+
+// Inject fills in the solved token for c and triggers the widget callback.
+func Inject(page *rod.Page, c Challenge, token string) error {
+	if _, err := page.Eval(injectJS, string(c.Kind), token, c.Callback); err != nil {
+		return fmt.Errorf("failed to inject captcha token: %w", err)
+	}
+	return nil
+}
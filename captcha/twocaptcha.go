@@ -0,0 +1,160 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TwoCaptcha solves challenges with the 2Captcha service (2captcha.com).
+type TwoCaptcha struct {
+	// APIKey is the 2Captcha account key (required).
+	APIKey string
+
+	// BaseURL overrides the API host, e.g. for compatible services.
+	// Default: "https://2captcha.com".
+	BaseURL string
+
+	// PollInterval is how often to check for the answer. Default: 5s.
+	PollInterval time.Duration
+
+	// HTTPClient is used for API requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// twoCaptchaResponse is the JSON envelope returned by in.php and res.php.
+type twoCaptchaResponse struct {
+	Status  int    `json:"status"`
+	Request string `json:"request"`
+}
+
+// Solve submits the challenge and polls until a token is ready or ctx ends.
+func (s *TwoCaptcha) Solve(ctx context.Context, c Challenge) (string, error) {
+	if s.APIKey == "" {
+		return "", errors.New("2captcha: API key required")
+	}
+
+	form := url.Values{
+		"key":     {s.APIKey},
+		"pageurl": {c.PageURL},
+		"json":    {"1"},
+	}
+	switch c.Kind {
+	case KindReCaptcha:
+		form.Set("method", "userrecaptcha")
+		form.Set("googlekey", c.SiteKey)
+	case KindHCaptcha:
+		form.Set("method", "hcaptcha")
+		form.Set("sitekey", c.SiteKey)
+	case KindTurnstile:
+		form.Set("method", "turnstile")
+		form.Set("sitekey", c.SiteKey)
+	default:
+		return "", fmt.Errorf("2captcha: unsupported captcha kind %q", c.Kind)
+	}
+
+	var submitted twoCaptchaResponse
+	if err := s.call(ctx, http.MethodPost, "/in.php", form, &submitted); err != nil {
+		return "", err
+	}
+	if submitted.Status != 1 {
+		return "", fmt.Errorf("2captcha: submit failed: %s", submitted.Request)
+	}
+
+	query := url.Values{
+		"key":    {s.APIKey},
+		"action": {"get"},
+		"id":     {submitted.Request},
+		"json":   {"1"},
+	}
+	return poll(ctx, s.PollInterval, func() (string, bool, error) {
+		var res twoCaptchaResponse
+		if err := s.call(ctx, http.MethodGet, "/res.php", query, &res); err != nil {
+			return "", false, err
+		}
+		if res.Status == 1 {
+			return res.Request, true, nil
+		}
+		if res.Request != "CAPCHA_NOT_READY" {
+			return "", false, fmt.Errorf("2captcha: %s", res.Request)
+		}
+		return "", false, nil
+	})
+}
+
+// call performs an API request and decodes the JSON response into out.
+func (s *TwoCaptcha) call(ctx context.Context, method, path string, params url.Values, out any) error {
+	base := s.BaseURL
+	if base == "" {
+		base = "https://2captcha.com"
+	}
+
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, base+path, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, base+path+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("2captcha: failed to build request: %w", err)
+	}
+
+	return doJSON(s.HTTPClient, req, out)
+}
+
+// doJSON sends req and decodes the JSON response body into out.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha service returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode captcha service response: %w", err)
+	}
+	return nil
+}
+
+// poll calls check every interval until it reports done, fails, or ctx ends.
+// Solving services need at least a few seconds, so the first check waits too.
+func poll(ctx context.Context, interval time.Duration, check func() (string, bool, error)) (string, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+
+		token, done, err := check()
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return token, nil
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package bua
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// RetentionPolicy bounds what is kept in a directory. Entries past MaxAge
+// are removed, then the oldest until the rest fit in MaxBytes. Zero fields
+// do not limit.
+type RetentionPolicy struct {
+	MaxAge   time.Duration
+	MaxBytes int64
+}
+
+// Retention configures which files Cleanup removes, per directory.
+type Retention struct {
+	// Screenshots applies to the step and failure screenshots in
+	// Config.ScreenshotDir.
+	Screenshots RetentionPolicy
+
+	// Recordings applies to the run recordings in Config.RecordingDir.
+	Recordings RetentionPolicy
+
+	// Downloads applies to every file in Config.DownloadDir, so leave it
+	// zero if that is a directory you use yourself.
+	Downloads RetentionPolicy
+
+	// TempProfileMaxAge removes the temporary browser profiles of agents
+	// that crashed or were never closed, once nothing in them changed for
+	// this long. Profiles of running browsers change constantly, so they are
+	// kept. Zero keeps them all.
+	TempProfileMaxAge time.Duration
+}
+
+// DefaultRetention keeps screenshots and recordings for a week and
+// abandoned temporary profiles for a day. Downloads are kept.
+func DefaultRetention() Retention {
+	return Retention{
+		Screenshots:       RetentionPolicy{MaxAge: 7 * 24 * time.Hour},
+		Recordings:        RetentionPolicy{MaxAge: 7 * 24 * time.Hour},
+		TempProfileMaxAge: 24 * time.Hour,
+	}
+}
+
+// CleanupResult lists what Cleanup removed.
+type CleanupResult struct {
+	Removed    []string `json:"removed"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// retainedEntry is a file or directory a retention policy applies to.
+type retainedEntry struct {
+	path    string
+	size    int64
+	modTime time.Time // newest in a directory
+}
+
+// Cleanup removes old screenshots, recordings, downloads and abandoned
+// temporary profiles as cfg.Retention says, or DefaultRetention if it is
+// nil. Agents with Config.Retention set run it at Start. Named profiles are
+// never removed; use ProfileManager.Delete for those.
+func Cleanup(cfg Config) (*CleanupResult, error) {
+	cfg.applyDefaults()
+	r := DefaultRetention()
+	if cfg.Retention != nil {
+		r = *cfg.Retention
+	}
+
+	res := &CleanupResult{}
+	var errs []error
+	vacuum := func(dir string, patterns []string, p RetentionPolicy) {
+		if err := res.vacuum(dir, patterns, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	vacuum(cfg.ScreenshotDir, []string{"step_*", "failure_*", "parallel_*"}, r.Screenshots)
+	vacuum(cfg.RecordingDir, []string{"run_*.gif"}, r.Recordings)
+	vacuum(cfg.DownloadDir, []string{"*"}, r.Downloads)
+	vacuum(os.TempDir(), []string{browser.TempProfilePattern}, RetentionPolicy{MaxAge: r.TempProfileMaxAge})
+
+	if len(errs) > 0 {
+		return res, fmt.Errorf("errors during cleanup: %v", errs)
+	}
+	return res, nil
+}
+
+// vacuum removes the entries of dir that match one of patterns and that p
+// does not keep.
+func (res *CleanupResult) vacuum(dir string, patterns []string, p RetentionPolicy) error {
+	if dir == "" || (p.MaxAge <= 0 && p.MaxBytes <= 0) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var matched []retainedEntry
+	for _, e := range entries {
+		if !matchAny(patterns, e.Name()) {
+			continue
+		}
+		matched = append(matched, statEntry(filepath.Join(dir, e.Name())))
+	}
+	// Newest first, so the oldest are beyond MaxBytes
+	sort.Slice(matched, func(i, j int) bool { return matched[i].modTime.After(matched[j].modTime) })
+
+	var (
+		kept int64
+		errs []error
+	)
+	for _, e := range matched {
+		expired := p.MaxAge > 0 && time.Since(e.modTime) > p.MaxAge
+		if !expired && (p.MaxBytes <= 0 || kept+e.size <= p.MaxBytes) {
+			kept += e.size
+			continue
+		}
+		if err := os.RemoveAll(e.path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res.Removed = append(res.Removed, e.path)
+		res.FreedBytes += e.size
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to clean %s: %v", dir, errs)
+	}
+	return nil
+}
+
+// statEntry returns the size of the file or directory at path and the time
+// anything in it last changed.
+func statEntry(path string) retainedEntry {
+	e := retainedEntry{path: path}
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // files come and go while Chrome runs
+		}
+		if info, err := d.Info(); err == nil {
+			if !d.IsDir() {
+				e.size += info.Size()
+			}
+			if info.ModTime().After(e.modTime) {
+				e.modTime = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return e
+}
+
+// matchAny reports whether name matches one of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Command bua-grpc serves a bua agent over gRPC.
+//
+// Usage:
+//
+//	bua-grpc -addr :50051 -preset balanced
+//
+// The service is defined in grpcapi/proto/bua.proto; generate a client for
+// your language from it, or use grpcapi.NewClient from Go.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/grpcapi"
+)
+
+func main() {
+	addr := flag.String("addr", ":50051", "Listen address")
+	preset := flag.String("preset", string(bua.PresetBalanced), "Token/quality preset: fast, efficient, balanced, quality, max")
+	model := flag.String("model", "", "Gemini model name (default: gemini-2.5-flash)")
+	headless := flag.Bool("headless", true, "Run the browser without a visible window")
+	profile := flag.String("profile", "", "Named browser profile for session persistence")
+	debug := flag.Bool("debug", false, "Enable verbose agent logging")
+	flag.Parse()
+
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	if apiKey == "" {
+		log.Fatal("GEMINI_API_KEY environment variable is required")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	svc := grpcapi.New(bua.Config{
+		APIKey:      apiKey,
+		Model:       *model,
+		Preset:      bua.Preset(*preset),
+		Headless:    *headless,
+		ProfileName: *profile,
+		Debug:       *debug,
+	})
+	if err := svc.Start(ctx); err != nil {
+		log.Fatalf("Failed to start agent: %v", err)
+	}
+	defer svc.Close()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+
+	gs := grpc.NewServer()
+	svc.Register(gs)
+
+	go func() {
+		<-ctx.Done()
+		fmt.Println("Shutting down...")
+		gs.GracefulStop()
+	}()
+
+	fmt.Printf("bua-grpc listening on %s\n", lis.Addr())
+	if err := gs.Serve(lis); err != nil {
+		log.Printf("Server error: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Command bua-mcp serves bua's browser tools over the Model Context Protocol (stdio).
+//
+// Register it with an MCP client, e.g. in Claude Desktop's config:
+//
+//	{
+//	  "mcpServers": {
+//	    "bua": { "command": "bua-mcp", "args": ["-headless=false"] }
+//	  }
+//	}
+//
+// The client's model then drives the browser directly with navigate, click,
+// type_text, get_page_state, and the rest of the toolset.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/export"
+)
+
+func main() {
+	headless := flag.Bool("headless", true, "Run the browser without a visible window")
+	profile := flag.String("profile", "", "Named browser profile for session persistence")
+	width := flag.Int("width", 1280, "Viewport width")
+	height := flag.Int("height", 720, "Viewport height")
+	flag.Parse()
+
+	// stdout carries the protocol; all logging goes to stderr
+	log.SetOutput(os.Stderr)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := browser.DefaultConfig()
+	cfg.Headless = *headless
+	cfg.ViewportWidth = *width
+	cfg.ViewportHeight = *height
+	if *profile != "" {
+		home, _ := os.UserHomeDir()
+		cfg.ProfileDir = filepath.Join(home, ".bua", "profiles")
+		cfg.ProfileName = *profile
+	}
+
+	b, err := browser.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create browser: %v", err)
+	}
+	if err := b.Start(ctx); err != nil {
+		log.Fatalf("Failed to start browser: %v", err)
+	}
+	defer b.Close()
+
+	tools, err := export.NewTools(b)
+	if err != nil {
+		log.Fatalf("Failed to create tools: %v", err)
+	}
+
+	if err := export.NewMCPServer(tools).Serve(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+		log.Printf("MCP server error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Command bua-server runs bua as a REST microservice.
+//
+// Usage:
+//
+//	bua-server -addr :8080 -preset balanced -max-concurrent 2
+//
+// Submit a task:
+//
+//	curl -X POST localhost:8080/tasks -d '{"task": "Find the top story on Hacker News", "url": "https://news.ycombinator.com"}'
+//
+// See package github.com/anxuanzi/bua/server for the full API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "Listen address")
+	preset := flag.String("preset", string(bua.PresetBalanced), "Token/quality preset: fast, efficient, balanced, quality, max")
+	model := flag.String("model", "", "Gemini model name (default: gemini-2.5-flash)")
+	headless := flag.Bool("headless", true, "Run browsers without a visible window")
+	maxConcurrent := flag.Int("max-concurrent", 2, "Maximum tasks running at once")
+	maxSteps := flag.Int("max-steps", 0, "Default maximum agent steps per task (default: 100)")
+	timeout := flag.Duration("timeout", 10*time.Minute, "Default task timeout")
+	dataDir := flag.String("data-dir", "bua-data", "Directory for per-task screenshots")
+	maxTasks := flag.Int("max-tasks", 1000, "Maximum tasks remembered; the oldest finished ones are forgotten first")
+	taskTTL := flag.Duration("task-ttl", 24*time.Hour, "How long finished tasks stay listed")
+	debug := flag.Bool("debug", false, "Enable verbose agent logging")
+	flag.Parse()
+
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	if apiKey == "" {
+		log.Fatal("GEMINI_API_KEY environment variable is required")
+	}
+
+	srv := server.New(server.Config{
+		Agent: bua.Config{
+			APIKey:   apiKey,
+			Model:    *model,
+			Preset:   bua.Preset(*preset),
+			Headless: *headless,
+			MaxSteps: *maxSteps,
+			Debug:    *debug,
+		},
+		MaxConcurrent:  *maxConcurrent,
+		DefaultTimeout: *timeout,
+		DataDir:        *dataDir,
+		MaxTasks:       *maxTasks,
+		TaskTTL:        *taskTTL,
+		APIToken:       os.Getenv("BUA_SERVER_TOKEN"),
+	})
+
+	httpServer := &http.Server{Addr: *addr, Handler: srv}
+
+	go func() {
+		fmt.Printf("bua-server listening on %s\n", *addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server error: %v", err)
+		}
+	}()
+
+	// Wait for interrupt, then cancel running tasks and drain connections
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+
+	fmt.Println("Shutting down...")
+	srv.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
+}
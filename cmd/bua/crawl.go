@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anxuanzi/bua"
+)
+
+// crawlPrompt instructs the agent to walk a site and extract per-page data.
+const crawlPrompt = `Crawl the website starting at the current page (%s).
+Visit at most %d pages, following links that stay on the same site and are relevant to the goal.
+Do not revisit pages you have already seen.
+Goal for each page: %s
+When finished, call done with data set to a JSON array with one object per visited page:
+{"url": "...", "title": "...", "data": <what you extracted for the goal>}.`
+
+func crawlCmd(args []string) int {
+	fs := flag.NewFlagSet("crawl", flag.ExitOnError)
+	var af agentFlags
+	af.register(fs)
+	maxPages := fs.Int("max-pages", 10, "Maximum number of pages to visit")
+	goal := fs.String("goal", "Summarize the main content of the page", "What to extract from each page")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: bua crawl [flags] <url>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	url := fs.Arg(0)
+
+	// Crawls take several actions per page; budget steps accordingly
+	if af.maxSteps == 0 {
+		af.maxSteps = *maxPages*5 + 10
+	}
+
+	task := fmt.Sprintf(crawlPrompt, url, *maxPages, *goal)
+	result, err := runTask(af.config(), url, task, af.timeout, bua.RunOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := writeResult(af.output, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// geminiModelsURL is used to verify the API key and network connectivity.
+const geminiModelsURL = "https://generativelanguage.googleapis.com/v1beta/models?pageSize=1"
+
+// checkStatus is the outcome of a single doctor check.
+type checkStatus string
+
+const (
+	statusOK   checkStatus = "OK"
+	statusWarn checkStatus = "WARN"
+	statusFail checkStatus = "FAIL"
+)
+
+func doctorCmd(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	apiKey := fs.String("api-key", "", "Gemini API key (default: $GEMINI_API_KEY or $GOOGLE_API_KEY)")
+	skipLaunch := fs.Bool("skip-launch", false, "Skip launching a headless browser")
+	fs.Parse(args)
+
+	failed := false
+	report := func(name string, status checkStatus, detail string) {
+		fmt.Printf("[%-4s] %-18s %s\n", status, name, detail)
+		if status == statusFail {
+			failed = true
+		}
+	}
+
+	// Chrome install
+	if path, found := launcher.LookPath(); found {
+		report("Chrome", statusOK, path)
+	} else {
+		report("Chrome", statusWarn, "no local Chrome found; Chromium will be downloaded on first run")
+	}
+
+	// Chrome launch
+	if *skipLaunch {
+		report("Chrome launch", statusWarn, "skipped")
+	} else if err := checkLaunch(); err != nil {
+		report("Chrome launch", statusFail, err.Error())
+	} else {
+		report("Chrome launch", statusOK, "headless browser started and closed")
+	}
+
+	// API key and connectivity
+	key := resolveAPIKey(*apiKey)
+	if key == "" {
+		report("API key", statusFail, "set GEMINI_API_KEY or pass --api-key")
+		report("Gemini API", statusFail, "skipped (no API key)")
+	} else {
+		report("API key", statusOK, fmt.Sprintf("found (%d chars)", len(key)))
+		status, detail := checkGemini(key)
+		report("Gemini API", status, detail)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// checkLaunch starts and stops a headless browser.
+func checkLaunch() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	b, err := browser.New(browser.Config{Headless: true})
+	if err != nil {
+		return err
+	}
+	if err := b.Start(ctx); err != nil {
+		return err
+	}
+	return b.Close()
+}
+
+// checkGemini verifies that the Gemini API is reachable and accepts the key.
+func checkGemini(key string) (checkStatus, string) {
+	req, err := http.NewRequest(http.MethodGet, geminiModelsURL, nil)
+	if err != nil {
+		return statusFail, err.Error()
+	}
+	req.Header.Set("x-goog-api-key", key)
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return statusFail, fmt.Sprintf("unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	latency := time.Since(start).Round(time.Millisecond)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return statusOK, fmt.Sprintf("reachable, key accepted (%v)", latency)
+	case resp.StatusCode == http.StatusBadRequest, resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return statusFail, fmt.Sprintf("key rejected (HTTP %d)", resp.StatusCode)
+	default:
+		return statusWarn, fmt.Sprintf("unexpected response (HTTP %d)", resp.StatusCode)
+	}
+}
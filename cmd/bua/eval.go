@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/eval"
+)
+
+func evalCmd(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	var af agentFlags
+	af.register(fs)
+	models := fs.String("models", "", "Comma-separated models to compare (default: -model)")
+	presets := fs.String("presets", "", "Comma-separated presets to compare (default: -preset)")
+	cases := fs.String("cases", "", "Comma-separated case names to run (default: all)")
+	repeat := fs.Int("repeat", 1, "Runs per case and variant")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: bua eval [flags] [-models a,b] [-presets x,y]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	base := af.config()
+	var variants []eval.Variant
+	for _, model := range splitList(*models, base.Model) {
+		for _, preset := range splitList(*presets, string(base.Preset)) {
+			cfg := base
+			cfg.Model = model
+			cfg.Preset = bua.Preset(preset)
+			name := preset
+			if model != "" {
+				name = model + "/" + preset
+			}
+			variants = append(variants, eval.Variant{Name: name, Config: cfg})
+		}
+	}
+
+	var selected []eval.Case
+	wanted := splitList(*cases, "")
+	for _, c := range eval.DefaultCases() {
+		for _, name := range wanted {
+			if name == "" || name == c.Name {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no cases match %q\n", *cases)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := &eval.Runner{
+		Variants: variants,
+		Cases:    selected,
+		Repeat:   *repeat,
+		Timeout:  af.timeout,
+		OnOutcome: func(o eval.Outcome) {
+			status := "ok"
+			if !o.Success {
+				status = "failed: " + o.Error
+			}
+			fmt.Fprintf(os.Stderr, "%s %s #%d: %d steps, %d tokens, %s %s\n",
+				o.Variant, o.Case, o.Attempt, o.Steps, o.Tokens, o.Duration.Round(100*time.Millisecond), status)
+		},
+	}
+	report, err := runner.Run(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprint(os.Stderr, "\n"+report.Table())
+	if err := writeResult(af.output, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// splitList splits a comma-separated flag, or returns def alone if it's empty.
+func splitList(s, def string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{def}
+	}
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/browser"
+)
+
+func installCmd(args []string) int {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	revision := fs.Int("revision", browser.DefaultRevision, "Chromium revision to download")
+	dir := fs.String("dir", "", "Directory for downloaded browsers (default: rod's cache directory)")
+	quiet := fs.Bool("q", false, "Don't print download progress")
+	fs.Parse(args)
+
+	cfg := bua.Config{
+		BrowserRevision: *revision,
+		BrowserDir:      *dir,
+	}
+	if !*quiet {
+		cfg.OnBrowserInstall = func(p bua.BrowserInstallProgress) {
+			if p.Total > 0 {
+				fmt.Fprintf(os.Stderr, "\rDownloading Chromium r%d: %3d%%", p.Revision, p.Downloaded*100/p.Total)
+			} else {
+				fmt.Fprintf(os.Stderr, "\rDownloading Chromium r%d: %d MB", p.Revision, p.Downloaded>>20)
+			}
+			if p.Done {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	path, err := bua.InstallBrowser(context.Background(), cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(path)
+	return 0
+}
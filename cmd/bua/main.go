@@ -0,0 +1,167 @@
+// Command bua runs browser automation tasks from the command line.
+//
+// Usage:
+//
+//	bua run [flags] "task prompt"
+//	bua run [flags] -f task.yaml
+//	bua crawl [flags] <url>
+//	bua pipeline [flags] -f pipeline.yaml
+//	bua schedule [flags] -f schedule.yaml
+//	bua eval [flags] [-models a,b] [-presets x,y]
+//	bua doctor
+//	bua install [-revision N]
+//
+// The Gemini API key is read from --api-key, GEMINI_API_KEY, or GOOGLE_API_KEY.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/anxuanzi/bua"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var code int
+	switch os.Args[1] {
+	case "run":
+		code = runCmd(os.Args[2:])
+	case "crawl":
+		code = crawlCmd(os.Args[2:])
+	case "pipeline":
+		code = pipelineCmd(os.Args[2:])
+	case "schedule":
+		code = scheduleCmd(os.Args[2:])
+	case "eval":
+		code = evalCmd(os.Args[2:])
+	case "doctor":
+		code = doctorCmd(os.Args[2:])
+	case "install":
+		code = installCmd(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		usage()
+		code = 2
+	}
+	os.Exit(code)
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `bua - browser automation agent
+
+Usage:
+  bua run [flags] "task prompt"     Run a task and print the JSON result
+  bua run [flags] -f task.yaml      Run a task defined in a YAML file
+  bua crawl [flags] <url>           Crawl a site and extract data from each page
+  bua pipeline [flags] -f FILE      Run chained tasks that pass data between steps
+  bua schedule [flags] -f FILE      Run tasks from a YAML file on cron schedules
+  bua eval [flags]                  Compare models and presets on built-in test pages
+  bua doctor                        Check Chrome, API key, and connectivity
+  bua install [-revision N]         Download a pinned Chromium and print its path
+
+Run "bua <command> -h" for command flags.
+`)
+}
+
+// agentFlags are the flags shared by commands that start an agent.
+type agentFlags struct {
+	apiKey   string
+	model    string
+	preset   string
+	arch     string
+	headless bool
+	profile  string
+	connect  string
+	chrome   string
+	maxSteps int
+	language string
+	timeout  time.Duration
+	debug    bool
+	logJSON  bool
+	output   string
+}
+
+// register adds the shared agent flags to fs.
+func (f *agentFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.apiKey, "api-key", "", "Gemini API key (default: $GEMINI_API_KEY or $GOOGLE_API_KEY)")
+	fs.StringVar(&f.model, "model", "", "Gemini model name (default: gemini-2.5-flash)")
+	fs.StringVar(&f.preset, "preset", string(bua.PresetBalanced), "Token/quality preset: fast, efficient, balanced, quality, max")
+	fs.StringVar(&f.arch, "architecture", string(bua.ArchitectureSingle), "Agent architecture: single, plan-execute")
+	fs.BoolVar(&f.headless, "headless", false, "Run the browser without a visible window")
+	fs.StringVar(&f.profile, "profile", "", "Named browser profile for session persistence")
+	fs.StringVar(&f.chrome, "chrome", "", "Path to the Chrome/Chromium executable to launch")
+	fs.StringVar(&f.connect, "connect", "", "Attach to a running Chrome (e.g. http://localhost:9222 or a ws:// endpoint) instead of launching one")
+	fs.IntVar(&f.maxSteps, "max-steps", 0, "Maximum agent steps (default: 100)")
+	fs.StringVar(&f.language, "language", "", "Locale for the agent's output and the browser, e.g. de-DE")
+	fs.DurationVar(&f.timeout, "timeout", 5*time.Minute, "Overall timeout for the task")
+	fs.BoolVar(&f.debug, "debug", false, "Enable verbose agent logging")
+	fs.BoolVar(&f.logJSON, "log-json", false, "Write logs to stderr as JSON lines")
+	fs.StringVar(&f.output, "o", "", "Write the JSON result to this file instead of stdout")
+}
+
+// config builds a bua.Config from the flags.
+func (f *agentFlags) config() bua.Config {
+	var logger *slog.Logger
+	if f.logJSON {
+		level := slog.LevelWarn
+		if f.debug {
+			level = slog.LevelDebug
+		}
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	}
+
+	return bua.Config{
+		APIKey:       resolveAPIKey(f.apiKey),
+		Model:        f.model,
+		Preset:       bua.Preset(f.preset),
+		Architecture: bua.Architecture(f.arch),
+		Headless:     f.headless,
+		ProfileName:  f.profile,
+		BrowserPath:  f.chrome,
+		ControlURL:   f.connect,
+		MaxSteps:     f.maxSteps,
+		Language:     f.language,
+		Debug:        f.debug,
+		Logger:       logger,
+	}
+}
+
+// resolveAPIKey returns the explicit key or falls back to the environment.
+func resolveAPIKey(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+		return key
+	}
+	return os.Getenv("GOOGLE_API_KEY")
+}
+
+// writeResult prints v as indented JSON to path, or stdout if path is empty.
+func writeResult(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
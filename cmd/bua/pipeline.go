@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/anxuanzi/bua/pipeline"
+	"github.com/anxuanzi/bua/taskspec"
+)
+
+func pipelineCmd(args []string) int {
+	fs := flag.NewFlagSet("pipeline", flag.ExitOnError)
+	var af agentFlags
+	af.register(fs)
+	file := fs.String("f", "", "YAML or JSON pipeline with a top-level \"steps\" list (required)")
+	var vars varFlags
+	fs.Var(&vars, "var", "Set a pipeline variable (key=value, repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: bua pipeline [flags] -f pipeline.yaml [-var key=value ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *file == "" {
+		fs.Usage()
+		return 2
+	}
+
+	p, err := pipeline.Load(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	values, err := taskspec.ParseVars(vars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	p.Config = af.config()
+
+	ctx, cancel := context.WithTimeout(context.Background(), af.timeout)
+	defer cancel()
+
+	result, err := p.Run(ctx, nil, values)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := writeResult(af.output, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
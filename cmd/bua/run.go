@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/taskspec"
+	"github.com/anxuanzi/bua/vcr"
+)
+
+// varFlags collects repeated -var key=value flags.
+type varFlags []string
+
+func (v *varFlags) String() string     { return strings.Join(*v, ",") }
+func (v *varFlags) Set(s string) error { *v = append(*v, s); return nil }
+
+func runCmd(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	var af agentFlags
+	af.register(fs)
+	file := fs.String("f", "", "YAML or JSON task spec")
+	startURL := fs.String("url", "", "Navigate to this URL before running the task")
+	cassette := fs.String("cassette", "", "Record the run to this file, or replay it if the file exists (BUA_VCR=record re-records)")
+	var vars varFlags
+	fs.Var(&vars, "var", "Set a task spec variable (key=value, repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: bua run [flags] \"task prompt\" | -f task.yaml [-var key=value ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	task := strings.Join(fs.Args(), " ")
+	url := *startURL
+	timeout := af.timeout
+	cfg := af.config()
+	var opts bua.RunOptions
+
+	if *file != "" {
+		spec, err := taskspec.Load(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		values, err := taskspec.ParseVars(vars)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		rendered, err := spec.Render(values)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", *file, err)
+			return 1
+		}
+
+		task = rendered.Prompt
+		opts.Schema = rendered.Schema
+		if url == "" {
+			url = rendered.URL
+		}
+		if rendered.Timeout > 0 {
+			timeout = rendered.Timeout
+		}
+		rendered.Apply(&cfg)
+		// Explicit flags win over the spec
+		if af.maxSteps > 0 {
+			cfg.MaxSteps = af.maxSteps
+		}
+		if af.profile != "" {
+			cfg.ProfileName = af.profile
+		}
+	}
+
+	if strings.TrimSpace(task) == "" {
+		fs.Usage()
+		return 2
+	}
+
+	if *cassette != "" {
+		c, err := vcr.Open(*cassette)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		cfg.Cassette = c
+	}
+
+	result, err := runTask(cfg, url, task, timeout, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := writeResult(af.output, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
+
+// runTask starts an agent, optionally navigates to url, and runs task with opts.
+func runTask(cfg bua.Config, url, task string, timeout time.Duration, opts bua.RunOptions) (*bua.Result, error) {
+	agent, err := bua.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer agent.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := agent.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	if url != "" {
+		if err := agent.Navigate(ctx, url); err != nil {
+			return nil, fmt.Errorf("failed to navigate to %s: %w", url, err)
+		}
+	}
+
+	return agent.RunWithOptions(ctx, task, opts)
+}
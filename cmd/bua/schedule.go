@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/anxuanzi/bua/schedule"
+)
+
+func scheduleCmd(args []string) int {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	var af agentFlags
+	af.register(fs)
+	file := fs.String("f", "", "YAML file with a top-level \"tasks\" list (required)")
+	resultsDir := fs.String("results", "", "Directory for run results (default: ~/.bua/schedule)")
+	once := fs.String("once", "", "Run this task immediately, print its result and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: bua schedule [flags] -f schedule.yaml")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *file == "" {
+		fs.Usage()
+		return 2
+	}
+
+	tasks, err := schedule.Load(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	// -timeout applies to tasks that don't set their own or use a spec's
+	for i := range tasks {
+		if tasks[i].Timeout == 0 && tasks[i].Spec == "" {
+			tasks[i].Timeout = af.timeout
+		}
+	}
+
+	cfg := schedule.Config{
+		Agent: af.config(),
+		Tasks: tasks,
+		OnRecord: func(rec schedule.Record) {
+			status := "ok"
+			if !rec.Success {
+				status = "failed: " + rec.Error
+			}
+			fmt.Fprintf(os.Stderr, "%s %s (attempt %d, %s) %s\n", rec.FinishedAt.Format(time.RFC3339),
+				rec.Task, rec.Attempt, rec.FinishedAt.Sub(rec.StartedAt).Round(time.Second), status)
+		},
+	}
+	if *resultsDir != "" {
+		cfg.Store = &schedule.DirStore{Dir: *resultsDir}
+	}
+
+	s, err := schedule.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once != "" {
+		rec, err := s.RunNow(ctx, *once)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := writeResult(af.output, rec); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if !rec.Success {
+			return 1
+		}
+		return 0
+	}
+
+	for _, t := range s.Tasks() {
+		fmt.Fprintf(os.Stderr, "Scheduled %s (%s), next run %s\n", t.Name, t.Cron, s.Next(t.Name).Format(time.RFC3339))
+	}
+	s.Run(ctx)
+	return 0
+}
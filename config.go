@@ -1,8 +1,25 @@
 package bua
 
 import (
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
+
+	"github.com/anxuanzi/bua/agent"
+	"github.com/anxuanzi/bua/artifact"
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/captcha"
+	"github.com/anxuanzi/bua/consent"
+	"github.com/anxuanzi/bua/dom"
+	"github.com/anxuanzi/bua/inbox"
+	"github.com/anxuanzi/bua/ocr"
+	"github.com/anxuanzi/bua/ratelimit"
+	"github.com/anxuanzi/bua/robots"
+	"github.com/anxuanzi/bua/screenshot"
+	"github.com/anxuanzi/bua/stealth"
+	"github.com/anxuanzi/bua/vcr"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Preset defines token/quality tradeoffs for different use cases.
@@ -36,14 +53,283 @@ func DefaultViewport() Viewport {
 	return Viewport{Width: 1280, Height: 720}
 }
 
+// Device describes a phone or tablet to emulate. See the Device* presets.
+type Device = browser.Device
+
+// Device presets for Config.Device.
+var (
+	DeviceIPhone15       = browser.DeviceIPhone15
+	DeviceIPhone15ProMax = browser.DeviceIPhone15ProMax
+	DevicePixel8         = browser.DevicePixel8
+	DeviceGalaxyS24      = browser.DeviceGalaxyS24
+	DeviceIPadPro        = browser.DeviceIPadPro
+)
+
+// Permission is a browser permission name, e.g. PermissionGeolocation.
+type Permission = browser.Permission
+
+// Common permissions for PermissionRule.
+const (
+	PermissionGeolocation    = browser.PermissionGeolocation
+	PermissionNotifications  = browser.PermissionNotifications
+	PermissionCamera         = browser.PermissionCamera
+	PermissionMicrophone     = browser.PermissionMicrophone
+	PermissionClipboardRead  = browser.PermissionClipboardRead
+	PermissionClipboardWrite = browser.PermissionClipboardWrite
+)
+
+// PermissionRule grants or denies permissions for an origin ("" for all origins).
+type PermissionRule = browser.PermissionRule
+
+// Geolocation is a fixed position reported to pages.
+type Geolocation = browser.Geolocation
+
+// ColorScheme is the emulated prefers-color-scheme value.
+type ColorScheme = browser.ColorScheme
+
+// Color schemes for Config.ColorScheme.
+const (
+	ColorSchemeLight = browser.ColorSchemeLight
+	ColorSchemeDark  = browser.ColorSchemeDark
+)
+
+// Fingerprint is a consistent set of browser traits presented to websites.
+type Fingerprint = stealth.Fingerprint
+
+// FingerprintRotation assigns distinct fingerprint bundles to profiles.
+type FingerprintRotation = stealth.Rotation
+
+// Locale is a timezone and language pair for FingerprintRotation.
+type Locale = stealth.Locale
+
+// HumanizeConfig configures human-like mouse movement and typing.
+type HumanizeConfig = browser.HumanizeConfig
+
+// DefaultHumanizeConfig returns enabled humanized input with sensible defaults.
+func DefaultHumanizeConfig() HumanizeConfig {
+	return browser.DefaultHumanizeConfig()
+}
+
+// DownloadProgress reports the state of a download.
+type DownloadProgress = browser.DownloadProgress
+
+// DownloadResult describes a completed download, including its SHA-256.
+type DownloadResult = browser.DownloadResult
+
+// Login describes how to sign in to a site; see Config.Logins.
+type Login = agent.Login
+
+// Inbox is a source of emailed one-time codes, e.g. &inbox.IMAP{...}.
+type Inbox = inbox.Inbox
+
+// CaptchaSolver obtains tokens for CAPTCHAs, e.g. &captcha.TwoCaptcha{APIKey: "..."}.
+type CaptchaSolver = captcha.Solver
+
+// OCREngine reads text from screenshots, e.g. &ocr.Tesseract{} or &ocr.Gemini{APIKey: "..."}.
+type OCREngine = ocr.Engine
+
+// ConsentConfig configures cookie-consent dismissal; see Config.Consent.
+type ConsentConfig = consent.Config
+
+// ConsentRule recognizes the banner of one consent platform by its selectors.
+type ConsentRule = consent.Rule
+
+const (
+	// ConsentReject rejects optional cookies, accepting only banners that
+	// offer no way to reject.
+	ConsentReject = consent.ChoiceReject
+
+	// ConsentAccept accepts all cookies.
+	ConsentAccept = consent.ChoiceAccept
+)
+
+// AnnotationConfig configures annotated screenshots; see Config.Annotations.
+type AnnotationConfig = screenshot.AnnotationConfig
+
+const (
+	// AnnotationLabelIndex labels elements with their index alone.
+	AnnotationLabelIndex = screenshot.LabelIndex
+
+	// AnnotationLabelRole labels elements with their index and role.
+	AnnotationLabelRole = screenshot.LabelRole
+
+	// AnnotationLabelName labels elements with their index and accessible name.
+	AnnotationLabelName = screenshot.LabelName
+)
+
+// ElementScope is the part of the page the agent is shown elements from;
+// see Config.ElementScope.
+type ElementScope = dom.Scope
+
+const (
+	// ElementScopeViewport shows the elements in the viewport, matching the
+	// screenshot, with a count of those above and below it.
+	ElementScopeViewport = dom.ScopeViewport
+
+	// ElementScopeDocument shows the elements of the whole page, marking
+	// those outside the viewport.
+	ElementScopeDocument = dom.ScopeDocument
+)
+
+// NewTabPolicy is what happens when a page opens a new tab; see
+// Config.NewTabPolicy.
+type NewTabPolicy = browser.NewTabPolicy
+
+const (
+	// NewTabFollow makes tabs that pages open the active tab, so the agent
+	// continues there.
+	NewTabFollow = browser.NewTabFollow
+
+	// NewTabSameTab opens target=_blank links and window.open in the
+	// current tab.
+	NewTabSameTab = browser.NewTabSameTab
+
+	// NewTabBlock keeps pages from opening tabs.
+	NewTabBlock = browser.NewTabBlock
+)
+
+// RobotsPolicy follows robots.txt and paces page loads per host, e.g.
+// robots.New(robots.Config{UserAgent: "mybot", MinDelay: time.Second}).
+type RobotsPolicy = robots.Policy
+
+// RateLimiter paces navigations per host, e.g.
+// ratelimit.New(ratelimit.Config{Default: ratelimit.Limit{PerMinute: 20}}).
+type RateLimiter = ratelimit.Limiter
+
+// ArtifactSink stores run artifacts, e.g. &artifact.S3{Bucket: "..."}.
+type ArtifactSink = artifact.Sink
+
+// Artifact is an uploaded screenshot, recording or download.
+type Artifact = artifact.Artifact
+
+// SiteRule adds instructions for one site; see Config.SiteRules.
+type SiteRule = agent.SiteRule
+
+// Example is a worked example of a recurring flow; see Config.Examples.
+type Example = agent.Example
+
+// ExampleStep is one page state of an Example and the right tool call for it.
+type ExampleStep = agent.ExampleStep
+
+// Assertion is a pass/fail check recorded by the agent's assert tool.
+type Assertion = agent.Assertion
+
+// Section is the result of one objective of a task, filed by the agent's
+// report_section tool.
+type Section = agent.Section
+
+// Finding is an entity the agent saved with its save_finding tool, in
+// Result.Findings.
+type Finding = agent.Finding
+
+// MergeStrategy says how saves of the same entity are merged; see
+// RunOptions.FindingMerge.
+type MergeStrategy = agent.MergeStrategy
+
+// Merge strategies.
+const (
+	MergeFields = agent.MergeFields
+	MergeFirst  = agent.MergeFirst
+	MergeLast   = agent.MergeLast
+)
+
+// SummaryFormat is the format of Result.Summary and Agent.Summarize; see
+// RunOptions.SummaryFormat.
+type SummaryFormat = agent.SummaryFormat
+
+// Summary formats.
+const (
+	SummaryMarkdown = agent.SummaryMarkdown
+	SummaryPlain    = agent.SummaryPlain
+	SummaryJSON     = agent.SummaryJSON
+)
+
+// Section statuses.
+const (
+	SectionComplete = agent.SectionComplete
+	SectionPartial  = agent.SectionPartial
+	SectionFailed   = agent.SectionFailed
+)
+
+// Cassette records a run's model and browser traffic for replay; see package vcr.
+type Cassette = vcr.Cassette
+
+// Architecture selects how the agent carries out a task.
+type Architecture string
+
+const (
+	// ArchitectureSingle runs the whole task in one agent loop.
+	ArchitectureSingle Architecture = agent.ArchitectureSingle
+
+	// ArchitecturePlanExecute has a cheap planner model split the task into
+	// subtasks, runs each in its own agent loop (replanning after failures),
+	// and verifies the combined result at the end.
+	ArchitecturePlanExecute Architecture = agent.ArchitecturePlanExecute
+)
+
+// GenerationConfig tunes how the model generates its answers; see
+// Config.Generation.
+type GenerationConfig = agent.GenerationConfig
+
+// ThinkingLevel is how much a Gemini 3 model thinks before answering.
+type ThinkingLevel = agent.ThinkingLevel
+
+const (
+	ThinkingLevelMinimal = agent.ThinkingLevelMinimal
+	ThinkingLevelLow     = agent.ThinkingLevelLow
+	ThinkingLevelMedium  = agent.ThinkingLevelMedium
+	ThinkingLevelHigh    = agent.ThinkingLevelHigh
+)
+
+// Ptr returns a pointer to v, for the optional fields of GenerationConfig:
+//
+//	Generation: bua.GenerationConfig{Temperature: bua.Ptr[float32](0)}
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 // Config holds agent configuration.
 type Config struct {
-	// APIKey is the Gemini API key (required).
+	// APIKey is the Gemini API key (required unless APIKeys is set).
 	APIKey string
 
+	// APIKeys spreads model requests over several Gemini keys. Keys take
+	// turns; one that hits a rate limit or quota (429) rests until the API says
+	// to retry, and the request goes out again with the next key. APIKey, if
+	// set, joins the rotation first. See Agent.APIKeyUsage. Default: nil.
+	APIKeys []string
+
 	// Model is the Gemini model to use. Default: "gemini-2.5-flash".
 	Model string
 
+	// Generation sets the model's temperature, top-p, answer length and
+	// thinking budget or level. RunOptions.Generation overrides it per run.
+	// Default: the model's own defaults.
+	Generation GenerationConfig
+
+	// SystemPromptExtra is appended to the built-in system prompt, for company
+	// policies, output conventions or language requirements, e.g. "Answer in
+	// German" or "Never submit payment forms". It takes precedence where it
+	// conflicts with the built-in guidance. Default: "".
+	SystemPromptExtra string
+
+	// SystemPromptOverride replaces the built-in system prompt entirely.
+	// SystemPromptExtra is still appended. Start from agent.SystemPrompt() to
+	// keep the tool guidance the agent relies on. Default: "" (built-in).
+	SystemPromptOverride string
+
+	// SiteRules add instructions while the agent is on particular sites,
+	// e.g. {Domain: "shop.example.com", Rules: "Always pick standard
+	// shipping"}. They are sent with the page state of matching pages, so
+	// they cost nothing elsewhere. Default: nil.
+	SiteRules []SiteRule
+
+	// Examples are worked examples of recurring flows: the page states met
+	// and the tool calls that handled them. A run shows the agent those of
+	// RunOptions.ExampleCategory in its first message, so it follows a
+	// known-good approach. Default: nil.
+	Examples []Example
+
 	// Headless runs the browser without a visible window. Default: false.
 	Headless bool
 
@@ -51,21 +337,146 @@ type Config struct {
 	Debug bool
 
 	// ProfileName specifies a named browser profile for session persistence.
-	// Empty string uses a temporary profile that is deleted on close.
+	// It names a directory in ProfileDir, so it cannot contain path
+	// separators. Empty string uses a temporary profile that is deleted on close.
 	ProfileName string
 
 	// ProfileDir is the directory to store browser profiles.
 	// Default: ~/.bua/profiles
 	ProfileDir string
 
+	// ProfileKey encrypts the session data of a named profile at rest: cookies,
+	// local storage and saved sign-ins are sealed with AES-256-GCM (key derived
+	// from this passphrase) whenever the agent is closed, and unsealed on Start.
+	// Default: "" (plaintext, protected only by file permissions).
+	ProfileKey string
+
+	// BrowserPath is the Chrome/Chromium executable to launch, e.g. a preinstalled
+	// binary on an air-gapped host. Default: "" (find a local install or download one).
+	BrowserPath string
+
+	// BrowserRevision pins the Chromium revision to download and launch, so
+	// deployments don't depend on whatever is installed. Ignored when BrowserPath
+	// is set. Default: 0 (no pinning).
+	BrowserRevision int
+
+	// BrowserDir is where pinned Chromium revisions are stored.
+	// Default: rod's cache directory (~/.cache/rod/browser on Linux).
+	BrowserDir string
+
+	// OnBrowserInstall is called with download progress when a pinned revision
+	// has to be downloaded (optional).
+	OnBrowserInstall func(BrowserInstallProgress)
+
+	// Stealth enables anti-detection hardening: evasions injected at document
+	// start (navigator.webdriver, plugins, WebGL, canvas/audio noise) and a
+	// fingerprint that stays the same for a named profile across sessions.
+	// Default: false.
+	Stealth bool
+
+	// Fingerprint overrides the fingerprint used in stealth mode.
+	// Default: nil (stored per profile, or random for temporary profiles).
+	Fingerprint *Fingerprint
+
+	// FingerprintRotation gives each new profile the next user agent, viewport,
+	// timezone and fingerprint bundle in turn, then keeps it for that profile.
+	// Share one rotation across a fleet (a Pool does this automatically) so
+	// profiles don't look alike. Overrides Viewport unless Device is set.
+	// Requires Stealth. Default: nil (one random fingerprint per profile).
+	FingerprintRotation *FingerprintRotation
+
+	// Humanize makes input look like a person: curved mouse paths at variable
+	// speed, per-character typing with occasional corrected typos, and short
+	// hesitations. Slower, but harder to flag on strict sites.
+	// Default: nil (direct input).
+	Humanize *HumanizeConfig
+
+	// Device emulates a phone or tablet: viewport, pixel density, touch events,
+	// mobile flag and user agent, e.g. &bua.DeviceIPhone15. Overrides Viewport.
+	// Default: nil (desktop).
+	Device *Device
+
+	// ColorScheme emulates prefers-color-scheme ("light" or "dark") so visual checks
+	// can capture both themes. Default: "" (system setting).
+	ColorScheme ColorScheme
+
+	// ReducedMotion emulates prefers-reduced-motion: reduce, so animated pages
+	// stabilize faster. Default: false.
+	ReducedMotion bool
+
+	// Language is a locale such as "de-DE". The agent writes its reasoning,
+	// summaries and answers in it and expects localized pages (e.g. German
+	// checkout buttons), and the browser presents it: navigator.language,
+	// Accept-Language, Intl formatting and, with Stealth, the fingerprint's
+	// languages. Default: "" (English, system locale).
+	Language string
+
+	// Permissions grants or denies browser permissions per origin, so permission
+	// prompts (notifications, geolocation, camera) never block the agent.
+	// Default: nil (sites prompt as usual).
+	Permissions []PermissionRule
+
+	// Geolocation is the position reported to pages when geolocation is granted.
+	// Default: nil.
+	Geolocation *Geolocation
+
+	// ControlURL attaches to an already-running Chrome instead of launching one,
+	// e.g. "http://localhost:9222" for a remote debugging port, or a DevTools
+	// WebSocket URL. Headless and profile settings are ignored. Default: "".
+	ControlURL string
+
+	// WSEndpoint connects to a hosted browser WebSocket endpoint as-is,
+	// e.g. "wss://chrome.browserless.io?token=..." or Chrome in Docker.
+	// Takes precedence over ControlURL. Default: "".
+	WSEndpoint string
+
 	// Viewport sets the browser viewport dimensions.
 	// Default: 1280x720
 	Viewport *Viewport
 
 	// MaxSteps is the maximum number of agent steps before giving up.
+	// With ArchitecturePlanExecute the limit applies to each subtask.
 	// Default: 100
 	MaxSteps int
 
+	// MaxCost aborts a run once its estimated cost (Result.EstimatedCost)
+	// exceeds this many US dollars, with ErrorCodeCostBudget. With
+	// ArchitecturePlanExecute the limit applies to the whole task. The model
+	// must have a price in Pricing. Default: 0 (no limit).
+	MaxCost float64
+
+	// DisableFinalTurn ends a run as soon as it reaches MaxSteps or
+	// RunOptions.MaxTokens. Otherwise the agent gets one last turn in which
+	// it may only call done, so it hands in what it has gathered as Data
+	// rather than nothing. Default: false (a final turn).
+	DisableFinalTurn bool
+
+	// Pricing adds or replaces entries of DefaultPricing, e.g. for a model
+	// it lacks or a negotiated rate. Default: nil (DefaultPricing).
+	Pricing Pricing
+
+	// Architecture selects single-loop or plan-execute mode. Plan-execute
+	// improves success on long, multi-site tasks at the cost of a few extra
+	// planner calls. Default: ArchitectureSingle.
+	Architecture Architecture
+
+	// PlannerModel plans and verifies tasks in plan-execute mode, and
+	// checks answers for VerifyResults. Default: "gemini-2.5-flash".
+	PlannerModel string
+
+	// VerifyResults has a critic check the data of every successful answer
+	// against the final page before it is returned. Missing fields or values
+	// that contradict the page send the agent back to fix them (at most
+	// twice per run). Default: false.
+	VerifyResults bool
+
+	// StrictSuccess makes a result succeed only if the agent called done
+	// with success=true and, if RunOptions.Schema is set, data matching the
+	// schema. Data that does not match is sent back to the agent either way
+	// (at most twice per run); in strict mode it fails the result if it
+	// still does not. Default: false.
+	StrictSuccess bool
+
 	// Preset configures token/quality tradeoffs.
 	// Default: PresetBalanced
 	Preset Preset
@@ -78,6 +489,39 @@ type Config struct {
 	// Set automatically based on Preset if not specified.
 	MaxElements int
 
+	// FullElementLists sends the full element list with every page state.
+	// By default, while the agent stays on one page, page states list only
+	// the elements added, changed or removed since the last one, when that
+	// is shorter. Default: false.
+	FullElementLists bool
+
+	// ElementScope is the part of the page page states list elements from:
+	// ElementScopeViewport, which is cheap and matches the screenshot, or
+	// ElementScopeDocument, which lets the agent plan scrolls. The agent can
+	// also ask for the whole page once with get_page_state. Default:
+	// ElementScopeViewport.
+	ElementScope ElementScope
+
+	// NewTabPolicy is what happens when a page opens a new tab, as
+	// target=_blank links do: NewTabFollow switches the agent to it,
+	// NewTabSameTab opens the link in the current tab instead, and
+	// NewTabBlock ignores it. Default: NewTabFollow.
+	NewTabPolicy NewTabPolicy
+
+	// MaxTabMemoryBytes recycles tabs whose JavaScript heap grows past it, so
+	// long crawls don't run the host out of memory: between actions, such a
+	// tab is replaced by a fresh one at the same URL, keeping its cookies.
+	// sessionStorage and page state, such as scroll position and form
+	// input, are lost. Agent.TabMetrics reports heap sizes. Default: 0 (no
+	// limit).
+	MaxTabMemoryBytes int64
+
+	// MaxTabs caps the open tabs, so runaway tab creation during long crawls
+	// can't exhaust memory: opening one more closes the background tab that
+	// was least recently active, and the agent is told which. Default: 0
+	// (no limit).
+	MaxTabs int
+
 	// ScreenshotMaxWidth is the maximum width for screenshots.
 	// Set automatically based on Preset if not specified.
 	ScreenshotMaxWidth int
@@ -94,6 +538,12 @@ type Config struct {
 	// Useful for debugging. Default: false.
 	ShowAnnotations bool
 
+	// Annotations customizes annotated screenshots: colors per role, labels
+	// with role or name, a minimum element size and interactive-only
+	// filtering. Start from screenshot.DefaultAnnotationConfig(). Default:
+	// nil (boxes and index labels for every element).
+	Annotations *AnnotationConfig
+
 	// ShowHighlight highlights elements before actions.
 	// Default: true.
 	ShowHighlight bool
@@ -105,6 +555,203 @@ type Config struct {
 	// ScreenshotDir is the directory to save screenshots.
 	// Default: system temp directory.
 	ScreenshotDir string
+
+	// RecordVideo records each run as an animated GIF using the browser screencast.
+	// The file path is returned in Result.RecordingPath. Default: false.
+	RecordVideo bool
+
+	// RecordingDir is the directory to save recordings.
+	// Default: ~/.bua/recordings
+	RecordingDir string
+
+	// DownloadDir is where the agent saves downloaded files.
+	// Default: ~/.bua/downloads
+	DownloadDir string
+
+	// Retention removes old screenshots, recordings, downloads and
+	// abandoned temporary profiles at Start, as Cleanup does. Default: nil
+	// (files are kept).
+	Retention *Retention
+
+	// MaxDownloadBytes aborts downloads larger than this. Default: 0 (no limit).
+	MaxDownloadBytes int64
+
+	// OnDownloadProgress is called as downloads make progress (optional).
+	OnDownloadProgress func(DownloadProgress)
+
+	// Artifacts uploads each run's screenshots, recording and downloads when
+	// it finishes, e.g. &artifact.S3{Bucket: "..."} or &artifact.GCS{Bucket: "..."}.
+	// Uploads are listed in Result.Artifacts. Default: nil (files stay local).
+	Artifacts ArtifactSink
+
+	// ArtifactPrefix is prepended to uploaded object keys, which look like
+	// <prefix>/<run id>/<kind>/<file>. Default: "bua".
+	ArtifactPrefix string
+
+	// CompletionWebhook is POSTed a JSON summary of every finished run — result,
+	// duration, token usage and artifact links — optionally HMAC-signed.
+	// Default: nil.
+	CompletionWebhook *CompletionWebhook
+
+	// LiveViewAddr starts a local live view server on this address (e.g., "127.0.0.1:8765").
+	// Open it in a browser to watch the screencast and step log of a headless run.
+	// Empty disables the server. Default: "".
+	LiveViewAddr string
+
+	// IncognitoPerRun executes each Run in a fresh incognito browser context that
+	// shares the browser process but not cookies, storage or cache. The context
+	// is discarded when the run ends; the page the browser is on is reopened in
+	// it, so Navigate before Run still works. Default: false.
+	IncognitoPerRun bool
+
+	// MaxParallelTabs limits how many RunParallel sub-tasks run at once,
+	// each in its own tab of the shared browser. Default: 4.
+	MaxParallelTabs int
+
+	// OnStep is called after each agent step completes.
+	// It runs on the agent's goroutine, so it should return quickly.
+	OnStep func(Step)
+
+	// Hooks are callbacks at each stage of a run: step start, tool call (which
+	// can be blocked), tool result, screenshot, and the run's outcome.
+	// Default: none.
+	Hooks Hooks
+
+	// SelectorTools gives the agent the query_selector and click_selector
+	// tools, which find and click elements by CSS selector or XPath
+	// expression without an element map, for prompts and replayed traces
+	// that know the exact selector. Default: false.
+	SelectorTools bool
+
+	// AllowJSEval gives the agent the eval_js tool, which runs JavaScript on
+	// the page and returns the result as JSON (up to 10,000 bytes): the
+	// escape hatch for what no other tool can do. Scripts run with the
+	// page's privileges, so enable it only for trusted tasks. Default: false.
+	AllowJSEval bool
+
+	// JSEvalOrigins limits eval_js to pages on these domains and their
+	// subdomains, e.g. []string{"example.com"}. With a frame selected, the
+	// frame's URL must match. Default: nil (any page).
+	JSEvalOrigins []string
+
+	// DangerouslyAllowCDP gives the agent the cdp_call tool, which sends raw
+	// Chrome DevTools protocol commands, for automations that need protocol
+	// features no tool covers. The model gets full control of the browser,
+	// including its other tabs, files it can read and downloads, so enable
+	// it only for trusted tasks on trusted pages. Default: false.
+	DangerouslyAllowCDP bool
+
+	// ToolMiddleware wraps every tool call the agent makes; the first
+	// middleware is the outermost. A middleware can rewrite the call, answer
+	// it without calling next, or rewrite the result. Default: none.
+	ToolMiddleware []ToolMiddleware
+
+	// Retry retries model calls that fail with rate limits or server errors, and
+	// tool calls that fail with a retryable ErrorCode (navigation timeouts by
+	// default), with exponential backoff. Only the failed call is repeated, not
+	// the task. Set MaxAttempts to 1 to disable. Default: 3 attempts, backoff
+	// from 1s doubling up to 30s.
+	Retry RetryPolicy
+
+	// ContextCaching stores the system prompt and tool schemas, which are the
+	// same on every model call, in Gemini cached content for an hour, so runs
+	// pay cached-token prices for them. The cache is deleted on Close. Content
+	// below the model's minimum cache size is sent uncached. Not available when
+	// APIKeys rotates several keys, as caches belong to one project. Result.CachedTokens shows the
+	// savings. Default: false.
+	ContextCaching bool
+
+	// CaptchaSolver solves reCAPTCHA, hCaptcha and Turnstile challenges as soon
+	// as they appear and injects the token. Use captcha.TwoCaptcha,
+	// captcha.AntiCaptcha or a captcha.SolverFunc. Default: nil (the agent
+	// asks for a human takeover instead).
+	CaptchaSolver CaptchaSolver
+
+	// OCR reads text that pages draw instead of writing into the DOM, as in
+	// charts, canvas-based apps and image buttons. It adds the read_text
+	// tool, which reads a screenshot of one element or of the viewport, and
+	// extract_content appends the viewport's text when the page has little
+	// text of its own. Use ocr.Tesseract, ocr.Gemini or an ocr.EngineFunc.
+	// Default: nil (no OCR).
+	OCR OCREngine
+
+	// Consent answers cookie-consent banners as pages load, before the agent
+	// sees them, so it spends no steps on them. Banners of common consent
+	// platforms (OneTrust, Cookiebot, Didomi, ...) are recognized by their
+	// selectors; with AskModel, the planner model picks the button of others.
+	// Default: nil (the agent deals with banners itself).
+	Consent *ConsentConfig
+
+	// IgnoreBlocks keeps the agent going on bot challenges (Cloudflare,
+	// Akamai, DataDome, PerimeterX), access-denied and rate-limit pages, and
+	// login walls. By default such pages end the task with ErrorCodeBlocked
+	// and a screenshot, after giving challenges that clear by themselves a
+	// few seconds; login walls of sites in Logins are signed into instead,
+	// and other login walls end the task only if the agent fails on them.
+	// Default: false.
+	IgnoreBlocks bool
+
+	// Robots makes the agent a polite crawler: pages that robots.txt
+	// disallows fail to load, and page loads on each host wait out its
+	// Crawl-delay (or the policy's MinDelay). Share one policy between
+	// agents, e.g. in a Pool, to pace them together. Default: nil
+	// (robots.txt is ignored).
+	Robots *RobotsPolicy
+
+	// RateLimit caps how often the agent's navigate and new_tab actions load
+	// pages from one host, with a per-host token bucket and minimum delay.
+	// Share one limiter between agents, e.g. in a Pool, so bulk jobs don't
+	// hammer a site together. Default: nil (no limit).
+	RateLimit *RateLimiter
+
+	// OnHumanTakeover is called when the agent asks a person to take over, e.g.
+	// for a CAPTCHA it cannot solve. It should return once the person is done in
+	// the (visible) browser; the wait is capped at 10 minutes.
+	// Default: nil (the agent gives up on the blocked step).
+	OnHumanTakeover func(ctx context.Context, reason string) error
+
+	// TOTPSecrets maps a name (site or account) to an authenticator secret,
+	// either the base32 key or the full otpauth:// URI from the QR code. The
+	// agent gets a get_totp tool that returns current codes, so logins with
+	// authenticator-app 2FA complete unattended. Secrets are never sent to the
+	// model. Default: nil (no get_totp tool).
+	TOTPSecrets map[string]string
+
+	// Inbox gives the agent a get_email_code tool that waits for emailed
+	// one-time codes, e.g. &inbox.IMAP{...} or an inbox.Webhook fed by an
+	// inbound-mail service. Default: nil (no get_email_code tool).
+	Inbox Inbox
+
+	// Logins are sites the agent can sign in to. Credentials are typed into
+	// the page but never shown to the model. When any task lands on a signed
+	// out page of one of these sites, the agent signs in again and carries on.
+	// With a named profile, cookies are saved after each sign-in and restored
+	// on Start. Default: nil.
+	Logins []Login
+
+	// Secrets are values for <secret>name</secret> placeholders in task
+	// prompts. The agent types the placeholder and the real value is
+	// substituted as it reaches the page, so the model never sees it.
+	// Default: nil.
+	Secrets map[string]string
+
+	// Cassette records the run's model requests and DevTools traffic, or
+	// replays a recording without an API key, browser or network, for
+	// deterministic tests; see vcr.Open. A recording cassette is saved on
+	// Close. APIKey isn't required when replaying. Default: nil.
+	Cassette *Cassette
+
+	// TracerProvider receives OpenTelemetry spans for each Run, model call,
+	// tool call, browser operation and DevTools call. Default: nil (the
+	// global provider set with otel.SetTracerProvider).
+	TracerProvider trace.TracerProvider
+
+	// Logger receives logs from the agent and browser, each record tagged
+	// with a "component" attribute. Inject one with your own handler for
+	// structured output, e.g. slog.NewJSONHandler. Default: nil (console
+	// output of "[Component] message" lines: everything with Debug, only
+	// errors without).
+	Logger *slog.Logger
 }
 
 // presetConfig defines the configuration for each preset.
@@ -165,6 +812,9 @@ func (c *Config) applyDefaults() {
 		c.ProfileDir = filepath.Join(home, ".bua", "profiles")
 	}
 
+	if c.Device != nil {
+		c.Viewport = &Viewport{Width: c.Device.Width, Height: c.Device.Height}
+	}
 	if c.Viewport == nil {
 		v := DefaultViewport()
 		c.Viewport = &v
@@ -209,12 +859,34 @@ func (c *Config) applyDefaults() {
 	if c.ScreenshotDir == "" {
 		c.ScreenshotDir = os.TempDir()
 	}
+
+	if c.ArtifactPrefix == "" {
+		c.ArtifactPrefix = "bua"
+	}
+	if c.RecordingDir == "" {
+		home, _ := os.UserHomeDir()
+		c.RecordingDir = filepath.Join(home, ".bua", "recordings")
+	}
+
+	if c.DownloadDir == "" {
+		home, _ := os.UserHomeDir()
+		c.DownloadDir = filepath.Join(home, ".bua", "downloads")
+	}
+
+	if c.MaxParallelTabs == 0 {
+		c.MaxParallelTabs = 4
+	}
 }
 
 // validate checks that required configuration is provided.
 func (c *Config) validate() error {
-	if c.APIKey == "" {
+	if c.APIKey == "" && len(c.APIKeys) == 0 && (c.Cassette == nil || !c.Cassette.Replaying()) {
 		return ErrMissingAPIKey
 	}
+	if c.ProfileName != "" {
+		if err := ValidateProfileName(c.ProfileName); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,220 @@
+// Package consent dismisses cookie-consent banners, so an agent does not
+// spend steps and tokens on them.
+//
+// Dismiss recognizes the banners of common consent management platforms
+// (OneTrust, Cookiebot, Didomi, Quantcast and others) by their selectors and
+// clicks the reject or accept button. For banners no Rule knows, Find
+// returns the text and buttons of what looks like a consent banner, so a
+// model can pick the button for Click.
+package consent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// Choice is the answer given to consent banners.
+type Choice string
+
+const (
+	// ChoiceReject rejects optional cookies. Banners without a reject button
+	// are accepted instead, as they block the page until answered.
+	ChoiceReject Choice = "reject"
+
+	// ChoiceAccept accepts all cookies.
+	ChoiceAccept Choice = "accept"
+)
+
+// Config configures consent-banner dismissal.
+type Config struct {
+	// Choice is the answer given to banners. Default: ChoiceReject.
+	Choice Choice
+
+	// Rules recognize banners in addition to DefaultRules, and are tried
+	// first. Default: nil.
+	Rules []Rule
+
+	// AskModel has the planner model pick the button of a banner no rule
+	// recognizes, in one small request per page. Default: false (such
+	// banners are left to the agent).
+	AskModel bool
+}
+
+// Rule recognizes the banner of one consent management platform.
+type Rule struct {
+	// Name identifies the platform, e.g. "OneTrust".
+	Name string `json:"name"`
+
+	// Banner is a CSS selector matching the banner while it is shown.
+	Banner string `json:"banner"`
+
+	// Accept and Reject are CSS selectors of the banner's buttons. Reject
+	// may be empty if the platform has none.
+	Accept string `json:"accept"`
+	Reject string `json:"reject,omitempty"`
+}
+
+// DefaultRules recognize the banners of common consent management platforms.
+var DefaultRules = []Rule{
+	{Name: "OneTrust", Banner: "#onetrust-banner-sdk", Accept: "#onetrust-accept-btn-handler", Reject: "#onetrust-reject-all-handler"},
+	{Name: "Cookiebot", Banner: "#CybotCookiebotDialog", Accept: "#CybotCookiebotDialogBodyLevelButtonLevelOptinAllowAll, #CybotCookiebotDialogBodyButtonAccept", Reject: "#CybotCookiebotDialogBodyButtonDecline"},
+	{Name: "Didomi", Banner: "#didomi-notice", Accept: "#didomi-notice-agree-button", Reject: "#didomi-notice-disagree-button, .didomi-continue-without-agreeing"},
+	{Name: "Quantcast", Banner: "#qc-cmp2-ui", Accept: "#qc-cmp2-ui button[mode=\"primary\"]", Reject: "#qc-cmp2-ui button[mode=\"secondary\"]"},
+	{Name: "TrustArc", Banner: "#truste-consent-track", Accept: "#truste-consent-button", Reject: "#truste-consent-required"},
+	{Name: "Osano", Banner: ".osano-cm-dialog", Accept: ".osano-cm-accept-all", Reject: ".osano-cm-denyAll, .osano-cm-deny"},
+	{Name: "CookieYes", Banner: ".cky-consent-container", Accept: ".cky-btn-accept", Reject: ".cky-btn-reject"},
+	{Name: "Complianz", Banner: ".cmplz-cookiebanner", Accept: ".cmplz-accept", Reject: ".cmplz-deny"},
+	{Name: "iubenda", Banner: "#iubenda-cs-banner", Accept: ".iubenda-cs-accept-btn", Reject: ".iubenda-cs-reject-btn"},
+	{Name: "Klaro", Banner: ".klaro .cookie-notice, .klaro .cookie-modal", Accept: ".klaro .cm-btn-success", Reject: ".klaro .cn-decline, .klaro .cm-btn-danger"},
+	{Name: "Borlabs", Banner: "#BorlabsCookieBox", Accept: "#BorlabsCookieBox ._brlbs-btn-accept-all", Reject: "#BorlabsCookieBox ._brlbs-refuse-btn, #BorlabsCookieBox ._brlbs-refuse"},
+	{Name: "Axeptio", Banner: "#axeptio_overlay", Accept: "#axeptio_btn_acceptAll", Reject: "#axeptio_btn_dismiss"},
+	{Name: "Funding Choices", Banner: ".fc-consent-root", Accept: ".fc-cta-consent", Reject: ".fc-cta-do-not-consent"},
+	{Name: "Cookie Consent", Banner: ".cc-window:not(.cc-invisible)", Accept: ".cc-allow, .cc-dismiss", Reject: ".cc-deny"},
+}
+
+// Dismissal describes a banner that was answered.
+type Dismissal struct {
+	// Rule is the name of the rule that recognized the banner, or "" if the
+	// button was picked for a banner found with Find.
+	Rule string `json:"rule,omitempty"`
+
+	// Choice is the answer given.
+	Choice Choice `json:"choice"`
+
+	// Button is the text of the button that was clicked.
+	Button string `json:"button"`
+}
+
+// visibleJS is shared by the scripts below.
+const visibleJS = `const visible = (el) => {
+		if (!el) return false;
+		const r = el.getBoundingClientRect();
+		const s = getComputedStyle(el);
+		return r.width > 0 && r.height > 0 && s.visibility !== 'hidden' && s.display !== 'none' && s.opacity !== '0';
+	};
+	const first = (selector) => {
+		if (!selector) return null;
+		try {
+			for (const el of document.querySelectorAll(selector)) if (visible(el)) return el;
+		} catch (e) {}
+		return null;
+	};
+	const label = (el) => (el.innerText || el.value || el.getAttribute('aria-label') || '').trim().slice(0, 80);`
+
+// dismissJS clicks the button of the first shown banner a rule recognizes.
+const dismissJS = `(rules, choice) => {
+	` + visibleJS + `
+	for (const rule of rules) {
+		if (!first(rule.banner)) continue;
+		let button = choice === 'reject' ? first(rule.reject) : null;
+		let answer = choice;
+		if (!button) {
+			button = first(rule.accept);
+			answer = 'accept';
+		}
+		if (!button) continue;
+		const text = label(button);
+		button.click();
+		return JSON.stringify({rule: rule.name, choice: answer, button: text});
+	}
+	return '';
+}`
+
+// Dismiss answers the first shown banner that one of rules recognizes, and
+// returns what it did, or nil if no banner was shown.
+func Dismiss(page *rod.Page, rules []Rule, choice Choice) (*Dismissal, error) {
+	if choice == "" {
+		choice = ChoiceReject
+	}
+	res, err := page.Eval(dismissJS, rules, choice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dismiss consent banner: %w", err)
+	}
+
+	raw := res.Value.Str()
+	if raw == "" {
+		return nil, nil
+	}
+	var d Dismissal
+	if err := json.Unmarshal([]byte(raw), &d); err != nil {
+		return nil, fmt.Errorf("failed to parse consent dismissal: %w", err)
+	}
+	return &d, nil
+}
+
+// Banner is what looks like a consent banner that no rule recognized.
+type Banner struct {
+	// Text is the start of the banner's text.
+	Text string `json:"text"`
+
+	// Buttons are the texts of its buttons, in page order. Click takes an
+	// index into them.
+	Buttons []string `json:"buttons"`
+}
+
+// findJS finds the smallest visible overlay or dialog that talks about
+// cookies and has buttons, and remembers its buttons for clickJS.
+const findJS = `() => {
+	` + visibleJS + `
+	const words = /cookie|consent|gdpr|privacy|datenschutz|einwilligung|confidentialit|privacidad|galletas/i;
+	const overlay = (el) => {
+		const s = getComputedStyle(el);
+		return s.position === 'fixed' || s.position === 'sticky' || el.getAttribute('role') === 'dialog' ||
+			el.getAttribute('role') === 'alertdialog' || el.getAttribute('aria-modal') === 'true';
+	};
+	let best = null;
+	for (const el of document.querySelectorAll('body *')) {
+		if (!overlay(el) || !visible(el) || !words.test(el.innerText || '')) continue;
+		const buttons = [...el.querySelectorAll('button, [role="button"], a[href="#"], input[type="button"], input[type="submit"]')]
+			.filter((b) => visible(b) && label(b));
+		if (buttons.length === 0 || buttons.length > 12) continue;
+		if (!best || best.el.contains(el)) best = {el, buttons};
+	}
+	if (!best) return '';
+	window.__buaConsentButtons = best.buttons;
+	return JSON.stringify({
+		text: (best.el.innerText || '').replace(/\s+/g, ' ').trim().slice(0, 600),
+		buttons: best.buttons.map(label)
+	});
+}`
+
+// Find returns what looks like a consent banner on the page, or nil if
+// there is none. Use it for banners Dismiss does not recognize.
+func Find(page *rod.Page) (*Banner, error) {
+	res, err := page.Eval(findJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consent banner: %w", err)
+	}
+
+	raw := res.Value.Str()
+	if raw == "" {
+		return nil, nil
+	}
+	var b Banner
+	if err := json.Unmarshal([]byte(raw), &b); err != nil {
+		return nil, fmt.Errorf("failed to parse consent banner: %w", err)
+	}
+	return &b, nil
+}
+
+// clickJS clicks a button remembered by findJS.
+const clickJS = `(i) => {
+	const button = (window.__buaConsentButtons || [])[i];
+	if (!button || !button.isConnected) return false;
+	button.click();
+	return true;
+}`
+
+// Click clicks the button at index i of the banner last returned by Find.
+func Click(page *rod.Page, i int) error {
+	res, err := page.Eval(clickJS, i)
+	if err != nil {
+		return fmt.Errorf("failed to click consent button: %w", err)
+	}
+	if !res.Value.Bool() {
+		return fmt.Errorf("consent button %d is gone", i)
+	}
+	return nil
+}
@@ -45,6 +45,11 @@ type Element struct {
 	// Index is the element's index for LLM reference (0-based).
 	Index int `json:"index"`
 
+	// StableID identifies the element across extractions, while Index may
+	// change with any DOM update. It is kept on the node in the
+	// data-bua-id attribute.
+	StableID string `json:"stableId,omitempty"`
+
 	// TagName is the HTML tag name (lowercase).
 	TagName string `json:"tagName"`
 
@@ -138,6 +143,12 @@ func (e *Element) GetBoundingBox() BoundingBoxInfoInterface {
 // GetIsVisible implements ElementInfo interface for screenshot annotations.
 func (e *Element) GetIsVisible() bool { return e.IsVisible }
 
+// GetName implements ElementInfo interface for screenshot annotations.
+func (e *Element) GetName() string { return e.Description() }
+
+// GetIsInteractive implements ElementInfo interface for screenshot annotations.
+func (e *Element) GetIsInteractive() bool { return e.IsInteractive }
+
 // ElementMap holds all interactive elements on a page.
 type ElementMap struct {
 	// Elements is the list of interactive elements.
@@ -149,17 +160,30 @@ type ElementMap struct {
 	// PageTitle is the current page title.
 	PageTitle string
 
+	// ViewportHeight is the height of the viewport the elements' bounding
+	// boxes are relative to (0 if unknown).
+	ViewportHeight float64
+
+	// OffscreenAbove and OffscreenBelow count the interactive elements
+	// above and below the viewport that were left out (ScopeViewport).
+	OffscreenAbove int
+	OffscreenBelow int
+
 	// indexMap provides O(1) lookup by index.
 	indexMap map[int]*Element
 
+	// stableMap provides O(1) lookup by stable ID.
+	stableMap map[string]*Element
+
 	mu sync.RWMutex
 }
 
 // NewElementMap creates a new empty element map.
 func NewElementMap() *ElementMap {
 	return &ElementMap{
-		Elements: make([]*Element, 0),
-		indexMap: make(map[int]*Element),
+		Elements:  make([]*Element, 0),
+		indexMap:  make(map[int]*Element),
+		stableMap: make(map[string]*Element),
 	}
 }
 
@@ -170,6 +194,9 @@ func (m *ElementMap) Add(el *Element) {
 
 	m.Elements = append(m.Elements, el)
 	m.indexMap[el.Index] = el
+	if el.StableID != "" {
+		m.stableMap[el.StableID] = el
+	}
 }
 
 // Get returns an element by index.
@@ -189,6 +216,27 @@ func (m *ElementMap) Len() int {
 	return len(m.Elements)
 }
 
+// Translated returns a copy of the map with every bounding box moved by dx
+// and dy, e.g. from the viewport of an iframe to that of the page.
+func (m *ElementMap) Translated(dx, dy float64) *ElementMap {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := NewElementMap()
+	out.PageURL = m.PageURL
+	out.PageTitle = m.PageTitle
+	out.ViewportHeight = m.ViewportHeight
+	out.OffscreenAbove = m.OffscreenAbove
+	out.OffscreenBelow = m.OffscreenBelow
+	for _, el := range m.Elements {
+		c := *el
+		c.BoundingBox.X += dx
+		c.BoundingBox.Y += dy
+		out.Add(&c)
+	}
+	return out
+}
+
 // ElementInfoInterface defines the interface for element info used in screenshot annotations.
 // This matches the screenshot.ElementInfo interface.
 type ElementInfoInterface interface {
@@ -198,6 +246,8 @@ type ElementInfoInterface interface {
 	GetText() string
 	GetBoundingBox() BoundingBoxInfoInterface
 	GetIsVisible() bool
+	GetName() string
+	GetIsInteractive() bool
 }
 
 // BoundingBoxInfoInterface defines the interface for bounding box info.
@@ -230,6 +280,33 @@ func (m *ElementMap) Clear() {
 
 	m.Elements = make([]*Element, 0)
 	m.indexMap = make(map[int]*Element)
+	m.stableMap = make(map[string]*Element)
+}
+
+// FindByStableID returns the element with the given stable ID.
+func (m *ElementMap) FindByStableID(id string) (*Element, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	el, ok := m.stableMap[id]
+	return el, ok
+}
+
+// Remap maps the indices of prev, an earlier extraction of the page, to the
+// indices the same elements have in m. Elements that are gone are left out.
+func (m *ElementMap) Remap(prev *ElementMap) map[int]int {
+	prev.mu.RLock()
+	defer prev.mu.RUnlock()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	indices := make(map[int]int, len(prev.Elements))
+	for _, old := range prev.Elements {
+		if el, ok := m.stableMap[old.StableID]; ok && old.StableID != "" {
+			indices[old.Index] = el.Index
+		}
+	}
+	return indices
 }
 
 // FindBySelector returns the first element matching the selector.
@@ -4,16 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
+	"sync"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
 // extractionJS is the JavaScript code injected to extract interactive elements.
+// It takes the Scope to extract.
 // IMPORTANT: Must use arrow function syntax for rod.Eval()
-const extractionJS = `() => {
+const extractionJS = `(scope) => {
     const elements = [];
     let index = 0;
+    let offscreenAbove = 0;
+    let offscreenBelow = 0;
+
+    // Watch the document so the extractor can tell whether this result is
+    // still current. Scrolling, resizing and typing change what is extracted
+    // without a mutation, so they count as changes too; the data-bua-id
+    // attributes set below do not.
+    if (!window.__buaDom) {
+        const state = { id: Math.random().toString(36).slice(2), version: 0 };
+        const bump = () => { state.version++; };
+        new MutationObserver((records) => {
+            if (records.some(r => r.attributeName !== 'data-bua-id')) bump();
+        }).observe(document, { childList: true, subtree: true, attributes: true, characterData: true });
+        for (const type of ['scroll', 'resize', 'input', 'change']) {
+            window.addEventListener(type, bump, true);
+        }
+        window.__buaDom = state;
+    }
 
     // Selectors for interactive elements
     const interactiveSelectors = [
@@ -39,6 +59,16 @@ const extractionJS = `() => {
     ];
 
     const allElements = document.querySelectorAll(interactiveSelectors.join(','));
+    const seenIds = new Set();
+
+    // djb2 hash of a string, as a short base-36 ID
+    const hash = (s) => {
+        let h = 5381;
+        for (let i = 0; i < s.length; i++) {
+            h = ((h << 5) + h + s.charCodeAt(i)) | 0;
+        }
+        return (h >>> 0).toString(36);
+    };
     const viewportHeight = window.innerHeight;
     const viewportWidth = window.innerWidth;
 
@@ -48,10 +78,15 @@ const extractionJS = `() => {
         // Skip elements with no size
         if (rect.width <= 0 || rect.height <= 0) continue;
 
-        // Skip elements completely outside viewport (with buffer)
-        const buffer = 100;
-        if (rect.bottom < -buffer || rect.top > viewportHeight + buffer) continue;
-        if (rect.right < -buffer || rect.left > viewportWidth + buffer) continue;
+        // Elements outside the viewport are only counted, unless the whole
+        // document is extracted
+        const inViewport = rect.bottom > 0 && rect.top < viewportHeight &&
+            rect.right > 0 && rect.left < viewportWidth;
+        if (!inViewport && scope !== 'document') {
+            if (rect.bottom <= 0) offscreenAbove++;
+            else if (rect.top >= viewportHeight) offscreenBelow++;
+            continue;
+        }
 
         // Check computed styles
         const style = window.getComputedStyle(node);
@@ -108,8 +143,32 @@ const extractionJS = `() => {
             role = tagRoles[node.tagName] || '';
         }
 
+        // Stable ID: kept on the node in data-bua-id so it survives re-extraction.
+        // A node seen for the first time gets a hash of what identifies it, so
+        // an element re-rendered in place gets its old ID back.
+        let stableId = node.getAttribute('data-bua-id') || '';
+        if (!stableId || seenIds.has(stableId)) {
+            const isField = node.tagName === 'INPUT' || node.tagName === 'TEXTAREA';
+            const base = hash([
+                node.tagName, node.id, role,
+                node.getAttribute('name'), node.getAttribute('type'),
+                node.getAttribute('href'), node.getAttribute('aria-label'),
+                node.getAttribute('placeholder'),
+                isField ? '' : text,
+                Math.round((rect.x + window.scrollX) / 10),
+                Math.round((rect.y + window.scrollY) / 10)
+            ].join('|'));
+            stableId = base;
+            for (let n = 2; seenIds.has(stableId); n++) {
+                stableId = base + '-' + n;
+            }
+            node.setAttribute('data-bua-id', stableId);
+        }
+        seenIds.add(stableId);
+
         elements.push({
             index: index,
+            stableId: stableId,
             tagName: node.tagName.toLowerCase(),
             role: role,
             name: node.getAttribute('aria-label') || node.getAttribute('name') || '',
@@ -125,7 +184,7 @@ const extractionJS = `() => {
                 width: rect.width,
                 height: rect.height
             },
-            isVisible: true,
+            isVisible: inViewport,
             isEnabled: !node.disabled,
             isFocusable: node.tabIndex >= 0,
             isInteractive: true,
@@ -138,20 +197,72 @@ const extractionJS = `() => {
     return {
         elements: elements,
         pageUrl: window.location.href,
-        pageTitle: document.title
+        pageTitle: document.title,
+        viewportHeight: viewportHeight,
+        offscreenAbove: offscreenAbove,
+        offscreenBelow: offscreenBelow,
+        stamp: window.__buaDom.id + ':' + window.__buaDom.version + ':' + location.href
     };
 }`
 
+// stampJS returns the change stamp of the page's document: its watcher's ID,
+// how many changes it has seen and its URL, or "" if extractionJS never ran
+// on it.
+const stampJS = `() => window.__buaDom ? window.__buaDom.id + ':' + window.__buaDom.version + ':' + location.href : ''`
+
 // extractionResult is the structure returned by the extraction JavaScript.
 type extractionResult struct {
 	Elements  []*Element `json:"elements"`
 	PageURL   string     `json:"pageUrl"`
 	PageTitle string     `json:"pageTitle"`
+	Stamp     string     `json:"stamp"`
+
+	ViewportHeight float64 `json:"viewportHeight"`
+	OffscreenAbove int     `json:"offscreenAbove"`
+	OffscreenBelow int     `json:"offscreenBelow"`
 }
 
+// Scope is the part of a page elements are extracted from.
+type Scope string
+
+const (
+	// ScopeViewport extracts the elements intersecting the viewport, which
+	// is cheap and matches what a screenshot shows. Elements above and below
+	// it are counted, so the model knows to scroll.
+	ScopeViewport Scope = "viewport"
+
+	// ScopeDocument extracts the elements of the whole document, to plan
+	// scrolls. Elements outside the viewport are not visible.
+	ScopeDocument Scope = "document"
+)
+
 // Extractor handles DOM element extraction from a page.
+//
+// It caches the last element map of each page and returns it again until
+// the page's document changes or the page navigates, so actions that
+// refresh the map after every step don't re-run the extraction on pages
+// that didn't react.
 type Extractor struct {
 	maxElements int
+
+	mu    sync.Mutex
+	scope Scope
+	cache map[cacheKey]cachedMap
+}
+
+// cacheKey identifies a cached element map: the page, frame and scope it
+// was extracted from.
+type cacheKey struct {
+	target proto.TargetTargetID
+	frame  proto.PageFrameID
+	scope  Scope
+}
+
+// cachedMap is the last element map extracted from a page, with the change
+// stamp of the document it was extracted from.
+type cachedMap struct {
+	stamp string
+	em    *ElementMap
 }
 
 // NewExtractor creates a new DOM extractor.
@@ -159,19 +270,44 @@ func NewExtractor(maxElements int) *Extractor {
 	if maxElements <= 0 {
 		maxElements = 100
 	}
-	return &Extractor{maxElements: maxElements}
+	return &Extractor{maxElements: maxElements, scope: ScopeViewport, cache: make(map[cacheKey]cachedMap)}
 }
 
-// Extract extracts interactive elements from the page.
+// SetScope sets the scope Extract extracts. Default: ScopeViewport.
+func (e *Extractor) SetScope(scope Scope) {
+	if scope == "" {
+		scope = ScopeViewport
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scope = scope
+}
+
+// Scope returns the scope Extract extracts.
+func (e *Extractor) Scope() Scope {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.scope
+}
+
+// Extract extracts interactive elements from the page, in the extractor's
+// scope.
 func (e *Extractor) Extract(ctx context.Context, page *rod.Page) (*ElementMap, error) {
-	// Wait for page to be ready (500ms stability window)
+	return e.ExtractScope(ctx, page, e.Scope())
+}
+
+// ExtractScope extracts interactive elements from the given scope of the
+// page. It does not wait for the page to settle; callers do.
+func (e *Extractor) ExtractScope(ctx context.Context, page *rod.Page, scope Scope) (*ElementMap, error) {
+	key := cacheKey{target: page.TargetID, frame: page.FrameID, scope: scope}
+
 	_ = ctx // Context available for future use
-	if err := page.WaitStable(500 * time.Millisecond); err != nil {
-		// Continue even if wait fails - page might be dynamic
+	if em, ok := e.cached(page, key); ok {
+		return em, nil
 	}
 
 	// Execute extraction JavaScript
-	result, err := page.Eval(extractionJS)
+	result, err := page.Eval(extractionJS, string(scope))
 	if err != nil {
 		return nil, fmt.Errorf("dom extraction failed: %w", err)
 	}
@@ -191,17 +327,53 @@ func (e *Extractor) Extract(ctx context.Context, page *rod.Page) (*ElementMap, e
 	elementMap := NewElementMap()
 	elementMap.PageURL = data.PageURL
 	elementMap.PageTitle = data.PageTitle
+	elementMap.ViewportHeight = data.ViewportHeight
+	elementMap.OffscreenAbove = data.OffscreenAbove
+	elementMap.OffscreenBelow = data.OffscreenBelow
 
-	for i, el := range data.Elements {
-		if i >= e.maxElements {
-			break
-		}
+	// Over the limit, keep the elements most likely to be useful rather than
+	// the first ones on the page
+	for _, el := range prioritize(data.Elements, e.maxElements, "", data.ViewportHeight) {
 		elementMap.Add(el)
 	}
 
+	e.mu.Lock()
+	e.cache[key] = cachedMap{stamp: data.Stamp, em: elementMap}
+	e.mu.Unlock()
+
 	return elementMap, nil
 }
 
+// cached returns the element map cached under key if page's document has
+// not changed since it was extracted.
+func (e *Extractor) cached(page *rod.Page, key cacheKey) (*ElementMap, bool) {
+	e.mu.Lock()
+	c, ok := e.cache[key]
+	e.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	result, err := page.Eval(stampJS)
+	if err != nil {
+		return nil, false
+	}
+	stamp := result.Value.Str()
+	return c.em, stamp != "" && stamp == c.stamp
+}
+
+// Invalidate drops the cached element maps of page, so the next Extract
+// reads the page again.
+func (e *Extractor) Invalidate(page *rod.Page) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key := range e.cache {
+		if key.target == page.TargetID {
+			delete(e.cache, key)
+		}
+	}
+}
+
 // ExtractElementMap is a convenience function for extracting elements.
 func ExtractElementMap(ctx context.Context, page *rod.Page, maxElements int) (*ElementMap, error) {
 	extractor := NewExtractor(maxElements)
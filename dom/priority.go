@@ -0,0 +1,122 @@
+package dom
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stopWords are goal words too common to say anything about an element.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"that": true, "this": true, "then": true, "than": true, "page": true, "site": true,
+	"click": true, "find": true, "what": true, "which": true, "your": true, "you": true,
+	"are": true, "was": true, "all": true, "any": true, "its": true, "out": true,
+}
+
+// nativeRoles are the roles of elements made to be used, as opposed to
+// elements that are only clickable through an onclick handler or tabindex.
+var nativeRoles = map[string]bool{
+	"button": true, "link": true, "textbox": true, "checkbox": true, "radio": true,
+	"combobox": true, "menuitem": true, "tab": true, "switch": true,
+}
+
+// goalTerms splits goal into the lowercase words worth matching against
+// element labels.
+func goalTerms(goal string) []string {
+	words := strings.FieldsFunc(strings.ToLower(goal), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	var terms []string
+	for _, w := range words {
+		if len([]rune(w)) < 3 || stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// score rates how useful el is likely to be: elements in the viewport, made
+// to be used, enabled, labeled, and whose label mentions goal terms rate
+// higher.
+func score(el *Element, terms []string, viewportHeight float64) float64 {
+	var s float64
+
+	// Viewport proximity
+	box := el.BoundingBox
+	switch {
+	case viewportHeight <= 0:
+	case box.Y+box.Height > 0 && box.Y < viewportHeight:
+		s += 3
+	case box.Y >= viewportHeight:
+		s -= min((box.Y-viewportHeight)/viewportHeight, 3)
+	default:
+		s -= min(-(box.Y+box.Height)/viewportHeight, 3)
+	}
+
+	// Interactivity
+	switch el.TagName {
+	case "input", "select", "textarea", "button", "a":
+		s += 2
+	default:
+		if nativeRoles[el.Role] {
+			s += 2
+		} else {
+			s++
+		}
+	}
+	if !el.IsEnabled {
+		s -= 2
+	}
+
+	// Label text match
+	label := strings.ToLower(strings.Join([]string{el.Description(), el.Text, el.Href}, " "))
+	if strings.TrimSpace(label) != "" {
+		s += 0.5
+	}
+	var matches float64
+	for _, t := range terms {
+		if strings.Contains(label, t) {
+			matches += 2
+		}
+	}
+	return s + min(matches, 6)
+}
+
+// prioritize returns the max elements most likely to be useful for goal, in
+// page order. It returns elements itself if there are no more than max.
+func prioritize(elements []*Element, max int, goal string, viewportHeight float64) []*Element {
+	if max <= 0 || len(elements) <= max {
+		return elements
+	}
+	terms := goalTerms(goal)
+	scores := make(map[*Element]float64, len(elements))
+	for _, el := range elements {
+		scores[el] = score(el, terms, viewportHeight)
+	}
+
+	ranked := make([]*Element, len(elements))
+	copy(ranked, elements)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+	ranked = ranked[:max]
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Index < ranked[j].Index
+	})
+	return ranked
+}
+
+// Prioritized returns the max elements most likely to be useful for goal,
+// e.g. the task, in page order: those in or near the viewport, made to be
+// used rather than merely clickable, and labeled with words from goal. It
+// returns all elements if there are no more than max.
+func (m *ElementMap) Prioritized(max int, goal string) []*Element {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return prioritize(m.Elements, max, goal, m.ViewportHeight)
+}
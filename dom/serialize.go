@@ -19,6 +19,11 @@ type SerializeOptions struct {
 
 	// Compact uses minimal whitespace.
 	Compact bool
+
+	// Goal is what the elements are listed for, e.g. the task. When
+	// MaxElements cuts the list, elements whose labels mention it are kept
+	// first (see ElementMap.Prioritized).
+	Goal string
 }
 
 // DefaultSerializeOptions returns sensible defaults.
@@ -43,28 +48,38 @@ func (m *ElementMap) ToTokenString(opts SerializeOptions) string {
 	sb.WriteString(fmt.Sprintf("Page: %s\n", m.PageTitle))
 	sb.WriteString(fmt.Sprintf("URL: %s\n\n", m.PageURL))
 
-	// Count elements
-	count := len(m.Elements)
-	if opts.MaxElements > 0 && count > opts.MaxElements {
-		count = opts.MaxElements
-	}
-
-	sb.WriteString(fmt.Sprintf("Interactive Elements (%d):\n", count))
+	// Pick the elements to list
+	elements := prioritize(m.Elements, opts.MaxElements, opts.Goal, m.ViewportHeight)
 
-	for i, el := range m.Elements {
-		if opts.MaxElements > 0 && i >= opts.MaxElements {
-			sb.WriteString(fmt.Sprintf("... and %d more elements\n", len(m.Elements)-opts.MaxElements))
-			break
-		}
+	sb.WriteString(fmt.Sprintf("Interactive Elements (%d):\n", len(elements)))
 
+	for _, el := range elements {
 		line := formatElement(el, opts)
 		sb.WriteString(line)
 		sb.WriteString("\n")
 	}
+	if n := len(m.Elements) - len(elements); n > 0 {
+		sb.WriteString(fmt.Sprintf("... and %d more elements\n", n))
+	}
+	sb.WriteString(m.offscreenNote())
 
 	return sb.String()
 }
 
+// offscreenNote tells how many elements were left out above and below the
+// viewport, or is empty if none were.
+func (m *ElementMap) offscreenNote() string {
+	switch {
+	case m.OffscreenAbove > 0 && m.OffscreenBelow > 0:
+		return fmt.Sprintf("(%d more elements above and %d below the viewport; scroll to see them)\n", m.OffscreenAbove, m.OffscreenBelow)
+	case m.OffscreenAbove > 0:
+		return fmt.Sprintf("(%d more elements above the viewport; scroll up to see them)\n", m.OffscreenAbove)
+	case m.OffscreenBelow > 0:
+		return fmt.Sprintf("(%d more elements below the viewport; scroll down to see them)\n", m.OffscreenBelow)
+	}
+	return ""
+}
+
 // ToTokenStringLimited is a convenience method with a max elements limit.
 func (m *ElementMap) ToTokenStringLimited(maxElements int) string {
 	opts := DefaultSerializeOptions()
@@ -72,6 +87,86 @@ func (m *ElementMap) ToTokenStringLimited(maxElements int) string {
 	return m.ToTokenString(opts)
 }
 
+// ToTokenStringFor is ToTokenStringLimited, keeping the elements most useful
+// for goal when the list is cut.
+func (m *ElementMap) ToTokenStringFor(maxElements int, goal string) string {
+	opts := DefaultSerializeOptions()
+	opts.MaxElements = maxElements
+	opts.Goal = goal
+	return m.ToTokenString(opts)
+}
+
+// ToDiffString serializes the changes from prev, an earlier extraction of
+// the same page, to m: the elements whose index now holds a new element
+// ("+"), those that changed in place ("~"), and the indices that are gone
+// ("-"). Only the maxElements of each map listed for goal are compared, as
+// those are what ToTokenStringFor shows. It returns false if the pages
+// differ or the diff would not be shorter than the full list.
+func (m *ElementMap) ToDiffString(prev *ElementMap, maxElements int, goal string) (string, bool) {
+	if prev == nil {
+		return "", false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if prev != m {
+		prev.mu.RLock()
+		defer prev.mu.RUnlock()
+	}
+
+	if prev.PageURL != m.PageURL {
+		return "", false
+	}
+
+	opts := DefaultSerializeOptions()
+	before := prioritize(prev.Elements, maxElements, goal, prev.ViewportHeight)
+	after := prioritize(m.Elements, maxElements, goal, m.ViewportHeight)
+
+	old := make(map[int]*Element, len(before))
+	for _, el := range before {
+		old[el.Index] = el
+	}
+
+	var lines []string
+	for _, el := range after {
+		line := formatElement(el, opts)
+		was, ok := old[el.Index]
+		delete(old, el.Index)
+		switch {
+		case !ok || was.StableID != el.StableID:
+			lines = append(lines, "+ "+line)
+		case formatElement(was, opts) != line:
+			lines = append(lines, "~ "+line)
+		}
+	}
+	for _, el := range before {
+		if _, gone := old[el.Index]; gone {
+			lines = append(lines, fmt.Sprintf("- [%d]", el.Index))
+		}
+	}
+	if len(lines) >= len(after) {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Page: %s\n", m.PageTitle))
+	sb.WriteString(fmt.Sprintf("URL: %s\n\n", m.PageURL))
+	if len(lines) == 0 {
+		sb.WriteString(fmt.Sprintf("Interactive Elements (%d): unchanged since the last page state\n", len(after)))
+		sb.WriteString(m.offscreenNote())
+		return sb.String(), true
+	}
+	sb.WriteString(fmt.Sprintf("Interactive Elements (%d), changes since the last page state (unlisted indices are unchanged; + new, ~ changed, - removed):\n", len(after)))
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	if n := len(m.Elements) - len(after); n > 0 {
+		sb.WriteString(fmt.Sprintf("... and %d more elements\n", n))
+	}
+	sb.WriteString(m.offscreenNote())
+	return sb.String(), true
+}
+
 // formatElement formats a single element as a compact string.
 func formatElement(el *Element, opts SerializeOptions) string {
 	var parts []string
@@ -129,6 +224,11 @@ func formatElement(el *Element, opts SerializeOptions) string {
 		parts = append(parts, "[disabled]")
 	}
 
+	// Outside the viewport (ScopeDocument)
+	if !el.IsVisible {
+		parts = append(parts, "[offscreen]")
+	}
+
 	// Selector
 	if opts.IncludeSelector && el.Selector != "" {
 		parts = append(parts, fmt.Sprintf("sel=%q", el.Selector))
@@ -1,10 +1,72 @@
 package bua
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/anxuanzi/bua/agent"
+)
+
+// ErrorCode classifies why a task or step failed. See Result.ErrorCode.
+type ErrorCode = agent.ErrorCode
+
+// Error codes reported in Result.ErrorCode and Step.ErrorCode.
+const (
+	// ErrorCodeRateLimited means the model API refused requests for exceeding its rate limit or quota.
+	ErrorCodeRateLimited = agent.ErrorCodeRateLimited
+
+	// ErrorCodeModelUnavailable means the model API failed with a server or network error.
+	ErrorCodeModelUnavailable = agent.ErrorCodeModelUnavailable
+
+	// ErrorCodeElementNotFound means an element index was not on the page.
+	ErrorCodeElementNotFound = agent.ErrorCodeElementNotFound
+
+	// ErrorCodeNavigationTimeout means a page did not load in time.
+	ErrorCodeNavigationTimeout = agent.ErrorCodeNavigationTimeout
+
+	// ErrorCodeStepBudget means the task ran out of steps (Config.MaxSteps).
+	ErrorCodeStepBudget = agent.ErrorCodeStepBudget
+
+	// ErrorCodeCostBudget means the task's estimated cost exceeded Config.MaxCost.
+	ErrorCodeCostBudget = agent.ErrorCodeCostBudget
+
+	// ErrorCodeTokenBudget means the task used up RunOptions.MaxTokens.
+	ErrorCodeTokenBudget = agent.ErrorCodeTokenBudget
+
+	// ErrorCodeTimeout means the task's context deadline passed before it finished.
+	ErrorCodeTimeout = agent.ErrorCodeTimeout
+
+	// ErrorCodeRepeatedFailures means the task was aborted after too many failed actions in a row.
+	ErrorCodeRepeatedFailures = agent.ErrorCodeRepeatedFailures
+
+	// ErrorCodeLoopDetected means the task was aborted because the agent kept repeating the same actions.
+	ErrorCodeLoopDetected = agent.ErrorCodeLoopDetected
+
+	// ErrorCodeHumanTakeover means a person was needed but unavailable, or did not finish in time.
+	ErrorCodeHumanTakeover = agent.ErrorCodeHumanTakeover
+
+	// ErrorCodeModelRefusal means the model declined to continue, e.g. for safety reasons.
+	ErrorCodeModelRefusal = agent.ErrorCodeModelRefusal
+
+	// ErrorCodeBlocked means a site turned the agent away with a bot challenge,
+	// block page or login wall. Result.Blocked says which.
+	ErrorCodeBlocked = agent.ErrorCodeBlocked
+
+	// ErrorCodeTaskFailed means the agent finished but reported that the task failed.
+	ErrorCodeTaskFailed = agent.ErrorCodeTaskFailed
+)
+
+// BlockedError describes the block page that ended a task: its Kind (e.g.
+// block.KindCloudflare), URL, HTTP status and a screenshot. Result.Err
+// returns it for blocked tasks; it matches ErrBlocked.
+type BlockedError = agent.BlockedError
+
+// Error is an error with an ErrorCode. Errors with equal codes match with
+// errors.Is, so failures can be checked against the sentinels below.
+type Error = agent.Error
 
 // Common errors returned by the bua package.
 var (
-	// ErrMissingAPIKey is returned when Config.APIKey is not set.
+	// ErrMissingAPIKey is returned when neither Config.APIKey nor Config.APIKeys is set.
 	ErrMissingAPIKey = errors.New("bua: API key is required")
 
 	// ErrNotStarted is returned when Run is called before Start.
@@ -13,14 +75,57 @@ var (
 	// ErrAlreadyStarted is returned when Start is called twice.
 	ErrAlreadyStarted = errors.New("bua: agent already started")
 
-	// ErrMaxStepsReached is returned when the agent exceeds MaxSteps.
-	ErrMaxStepsReached = errors.New("bua: maximum steps reached without completing task")
+	// ErrBusy is returned when a task is started while another one is
+	// running on the same Agent. See Agent.RunQueued.
+	ErrBusy = errors.New("bua: agent is busy with another task")
+
+	// ErrRateLimited matches runs that failed because the model API rate limited them.
+	ErrRateLimited = agent.ErrRateLimited
+
+	// ErrModelUnavailable matches runs that failed on model API server or network errors.
+	ErrModelUnavailable = agent.ErrModelUnavailable
+
+	// ErrStepBudget matches results that ran out of steps.
+	ErrStepBudget = agent.ErrStepBudget
+
+	// ErrMaxStepsReached is the former name of ErrStepBudget.
+	//
+	// Deprecated: Use ErrStepBudget.
+	ErrMaxStepsReached = ErrStepBudget
+
+	// ErrCostBudget matches results aborted for exceeding Config.MaxCost.
+	ErrCostBudget = agent.ErrCostBudget
+
+	// ErrTokenBudget matches results that used up RunOptions.MaxTokens.
+	ErrTokenBudget = agent.ErrTokenBudget
+
+	// ErrRepeatedFailures matches results aborted after too many failed actions.
+	ErrRepeatedFailures = agent.ErrRepeatedFailures
+
+	// ErrLoopDetected matches results aborted because the agent was stuck in a loop.
+	ErrLoopDetected = agent.ErrLoopDetected
+
+	// ErrNavigationTimeout matches steps and results where a page did not load in time.
+	ErrNavigationTimeout = agent.ErrNavigationTimeout
+
+	// ErrHumanTakeover matches results that needed a person who was not available.
+	ErrHumanTakeover = agent.ErrHumanTakeover
+
+	// ErrModelRefusal matches results where the model declined to continue.
+	ErrModelRefusal = agent.ErrModelRefusal
+
+	// ErrBlocked matches results that a site blocked. Use errors.As with a
+	// *BlockedError for the details.
+	ErrBlocked = agent.ErrBlocked
+
+	// ErrTaskFailed matches results the agent itself reported as failed.
+	ErrTaskFailed = agent.ErrTaskFailed
 
 	// ErrBrowserClosed is returned when the browser is unexpectedly closed.
 	ErrBrowserClosed = errors.New("bua: browser was closed")
 
-	// ErrElementNotFound is returned when an element index is invalid.
-	ErrElementNotFound = errors.New("bua: element not found")
+	// ErrElementNotFound matches steps and results where an element index was invalid.
+	ErrElementNotFound = agent.ErrElementNotFound
 
 	// ErrElementNotVisible is returned when an element is not visible.
 	ErrElementNotVisible = errors.New("bua: element is not visible")
@@ -28,9 +133,25 @@ var (
 	// ErrNavigationFailed is returned when page navigation fails.
 	ErrNavigationFailed = errors.New("bua: navigation failed")
 
-	// ErrTimeout is returned when an operation times out.
-	ErrTimeout = errors.New("bua: operation timed out")
+	// ErrTimeout matches results whose context deadline passed before the
+	// task finished.
+	ErrTimeout = agent.ErrTimeout
 
 	// ErrHumanTakeoverTimeout is returned when human intervention times out.
 	ErrHumanTakeoverTimeout = errors.New("bua: human takeover timed out")
+
+	// ErrPoolClosed is returned when submitting to a closed Pool.
+	ErrPoolClosed = errors.New("bua: pool is closed")
+
+	// ErrPoolQueueFull is returned when a Pool's task queue is full.
+	ErrPoolQueueFull = errors.New("bua: pool queue is full")
+
+	// ErrTokenBudgetExceeded is returned when a Pool's token budget is exhausted.
+	ErrTokenBudgetExceeded = errors.New("bua: token budget exceeded")
+
+	// ErrProfileNotFound is returned when a named profile does not exist.
+	ErrProfileNotFound = errors.New("bua: profile not found")
+
+	// ErrInvalidPassphrase is returned when a profile archive can't be decrypted.
+	ErrInvalidPassphrase = errors.New("bua: wrong passphrase or corrupted archive")
 )
@@ -0,0 +1,147 @@
+// Package eval measures how well models and presets carry out browser
+// tasks. It ships a set of task fixtures on the pages of package testsite,
+// each with a success criterion, and a runner that reports success rate, steps,
+// tokens and latency per configuration:
+//
+//	report, err := (&eval.Runner{
+//		Variants: []eval.Variant{
+//			{Name: "flash", Config: bua.Config{APIKey: key, Model: "gemini-2.5-flash", Headless: true}},
+//			{Name: "pro", Config: bua.Config{APIKey: key, Model: "gemini-2.5-pro", Headless: true}},
+//		},
+//		Repeat: 3,
+//	}).Run(ctx)
+//	fmt.Print(report.Table())
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/testsite"
+)
+
+// Check decides whether a run accomplished its case. It returns nil on
+// success and otherwise describes what was wrong.
+type Check func(result *bua.Result, site *testsite.Server) error
+
+// Case is one task fixture.
+type Case struct {
+	// Name identifies the case in reports.
+	Name string `json:"name"`
+
+	// Path is the fixture page the agent starts on, e.g. "/forms.html".
+	Path string `json:"path"`
+
+	// Task is the prompt given to the agent.
+	Task string `json:"task"`
+
+	// Check verifies the outcome. The run must also report success.
+	Check Check `json:"-"`
+}
+
+// DataIncludes checks that the result data mentions every value,
+// ignoring case.
+func DataIncludes(values ...string) Check {
+	return func(result *bua.Result, site *testsite.Server) error {
+		data, _ := json.Marshal(result.Data)
+		text := strings.ToLower(string(data))
+		for _, v := range values {
+			if !strings.Contains(text, strings.ToLower(v)) {
+				return fmt.Errorf("result data does not mention %q", v)
+			}
+		}
+		return nil
+	}
+}
+
+// DataExcludes checks that the result data mentions none of the values,
+// ignoring case.
+func DataExcludes(values ...string) Check {
+	return func(result *bua.Result, site *testsite.Server) error {
+		data, _ := json.Marshal(result.Data)
+		text := strings.ToLower(string(data))
+		for _, v := range values {
+			if strings.Contains(text, strings.ToLower(v)) {
+				return fmt.Errorf("result data mentions %q", v)
+			}
+		}
+		return nil
+	}
+}
+
+// Submitted checks that a form was posted to /submit/<name> with the given
+// field values (compared ignoring case and surrounding space).
+func Submitted(name string, fields map[string]string) Check {
+	return func(result *bua.Result, site *testsite.Server) error {
+		subs := site.Submissions(name)
+		if len(subs) == 0 {
+			return fmt.Errorf("form %q was not submitted", name)
+		}
+		form := subs[len(subs)-1]
+		for field, want := range fields {
+			got := form.Get(field)
+			if !strings.EqualFold(strings.TrimSpace(got), want) {
+				return fmt.Errorf("form %q field %q is %q, want %q", name, field, got, want)
+			}
+		}
+		return nil
+	}
+}
+
+// All combines checks; the first failure wins.
+func All(checks ...Check) Check {
+	return func(result *bua.Result, site *testsite.Server) error {
+		for _, check := range checks {
+			if err := check(result, site); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// DefaultCases returns the built-in fixtures: extraction, form filling,
+// multi-page navigation, JavaScript-driven search and pagination.
+func DefaultCases() []Case {
+	return []Case{
+		{
+			Name:  "extract-table",
+			Path:  "/products.html",
+			Task:  "What is the price of the Blue Widget, and how many are in stock? Report price and stock.",
+			Check: DataIncludes("24.99", "14"),
+		},
+		{
+			Name: "fill-form",
+			Path: "/forms.html",
+			Task: "Send a support request as Jane Doe (jane@example.com) with the message " +
+				"\"My widget arrived broken.\" Don't subscribe to the newsletter.",
+			Check: Submitted("contact", map[string]string{
+				"name":       "Jane Doe",
+				"email":      "jane@example.com",
+				"topic":      "support",
+				"message":    "My widget arrived broken.",
+				"newsletter": "",
+			}),
+		},
+		{
+			Name:  "navigate-docs",
+			Path:  "/docs.html",
+			Task:  "Find the latest release version of the Acme SDK and its release date.",
+			Check: DataIncludes("4.2.1", "2025-03-02"),
+		},
+		{
+			Name:  "js-search",
+			Path:  "/search.html",
+			Task:  "Use the search box to look up kiwi and report its color.",
+			Check: DataIncludes("brown", "green"),
+		},
+		{
+			Name:  "paginate",
+			Path:  "/pages.html",
+			Task:  "List everyone in the Engineering team. The directory has several pages.",
+			Check: All(DataIncludes("Ada Park", "Eve Nakamura"), DataExcludes("Ben Ortiz", "Iris Cole")),
+		},
+	}
+}
@@ -0,0 +1,205 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/testsite"
+)
+
+// Variant is a configuration under test, e.g. a model and preset.
+type Variant struct {
+	Name   string     `json:"name"`
+	Config bua.Config `json:"-"`
+}
+
+// Runner runs every case against every variant.
+type Runner struct {
+	// Variants are the configurations to compare (required).
+	Variants []Variant
+
+	// Cases are the tasks to run. Default: DefaultCases().
+	Cases []Case
+
+	// Repeat runs each case this many times per variant, to average out
+	// nondeterminism. Default: 1.
+	Repeat int
+
+	// Timeout bounds each run. Default: 3 minutes.
+	Timeout time.Duration
+
+	// OnOutcome is called after each run (optional).
+	OnOutcome func(Outcome)
+}
+
+// Outcome is the result of one run of one case.
+type Outcome struct {
+	Variant  string        `json:"variant"`
+	Case     string        `json:"case"`
+	Attempt  int           `json:"attempt"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Steps    int           `json:"steps"`
+	Tokens   int           `json:"tokens"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Summary aggregates the outcomes of a variant.
+type Summary struct {
+	Variant     string        `json:"variant"`
+	Runs        int           `json:"runs"`
+	Successes   int           `json:"successes"`
+	SuccessRate float64       `json:"success_rate"`
+	AvgSteps    float64       `json:"avg_steps"`
+	AvgTokens   float64       `json:"avg_tokens"`
+	AvgDuration time.Duration `json:"avg_duration"`
+}
+
+// Report is the result of an evaluation.
+type Report struct {
+	Summaries []Summary `json:"summaries"`
+	Outcomes  []Outcome `json:"outcomes"`
+}
+
+// Run evaluates each variant in turn on a shared local fixture site.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if len(r.Variants) == 0 {
+		return nil, fmt.Errorf("no variants to evaluate")
+	}
+	cases := r.Cases
+	if len(cases) == 0 {
+		cases = DefaultCases()
+	}
+	repeat := r.Repeat
+	if repeat <= 0 {
+		repeat = 1
+	}
+
+	site, err := testsite.Start()
+	if err != nil {
+		return nil, err
+	}
+	defer site.Close()
+
+	report := &Report{}
+	for _, v := range r.Variants {
+		outcomes, err := r.runVariant(ctx, site, v, cases, repeat)
+		report.Outcomes = append(report.Outcomes, outcomes...)
+		if err != nil {
+			return report, fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+		report.Summaries = append(report.Summaries, summarize(v.Name, outcomes))
+	}
+	return report, nil
+}
+
+// runVariant runs all cases on one agent started from the variant's config.
+func (r *Runner) runVariant(ctx context.Context, site *testsite.Server, v Variant, cases []Case, repeat int) ([]Outcome, error) {
+	agent, err := bua.New(v.Config)
+	if err != nil {
+		return nil, err
+	}
+	defer agent.Close()
+	if err := agent.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	var outcomes []Outcome
+	for _, c := range cases {
+		for attempt := 1; attempt <= repeat; attempt++ {
+			if err := ctx.Err(); err != nil {
+				return outcomes, err
+			}
+			o := r.runCase(ctx, agent, site, c)
+			o.Variant, o.Attempt = v.Name, attempt
+			outcomes = append(outcomes, o)
+			if r.OnOutcome != nil {
+				r.OnOutcome(o)
+			}
+		}
+	}
+	return outcomes, nil
+}
+
+// runCase runs one case from its start page and checks the outcome.
+func (r *Runner) runCase(ctx context.Context, agent *bua.Agent, site *testsite.Server, c Case) Outcome {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	o := Outcome{Case: c.Name}
+	site.Reset()
+	if err := agent.Navigate(ctx, site.URL(c.Path)); err != nil {
+		o.Error = fmt.Sprintf("failed to open %s: %v", c.Path, err)
+		return o
+	}
+
+	start := time.Now()
+	result, err := agent.Run(ctx, c.Task)
+	o.Duration = time.Since(start)
+	if err != nil {
+		o.Error = err.Error()
+		return o
+	}
+
+	o.Steps = len(result.Steps)
+	o.Tokens = result.TokensUsed
+	switch {
+	case !result.Success:
+		o.Error = "agent reported failure: " + result.Error
+	case c.Check != nil:
+		if err := c.Check(result, site); err != nil {
+			o.Error = err.Error()
+		} else {
+			o.Success = true
+		}
+	default:
+		o.Success = true
+	}
+	return o
+}
+
+// summarize aggregates a variant's outcomes.
+func summarize(variant string, outcomes []Outcome) Summary {
+	s := Summary{Variant: variant, Runs: len(outcomes)}
+	if s.Runs == 0 {
+		return s
+	}
+
+	var steps, tokens int
+	var duration time.Duration
+	for _, o := range outcomes {
+		if o.Success {
+			s.Successes++
+		}
+		steps += o.Steps
+		tokens += o.Tokens
+		duration += o.Duration
+	}
+	n := float64(s.Runs)
+	s.SuccessRate = float64(s.Successes) / n
+	s.AvgSteps = float64(steps) / n
+	s.AvgTokens = float64(tokens) / n
+	s.AvgDuration = duration / time.Duration(s.Runs)
+	return s
+}
+
+// Table formats the summaries as an aligned text table.
+func (rep *Report) Table() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VARIANT\tSUCCESS\tRATE\tAVG STEPS\tAVG TOKENS\tAVG LATENCY")
+	for _, s := range rep.Summaries {
+		fmt.Fprintf(w, "%s\t%d/%d\t%.0f%%\t%.1f\t%.0f\t%s\n", s.Variant, s.Successes, s.Runs,
+			s.SuccessRate*100, s.AvgSteps, s.AvgTokens, s.AvgDuration.Round(100*time.Millisecond))
+	}
+	w.Flush()
+	return sb.String()
+}
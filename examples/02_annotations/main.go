@@ -10,9 +10,17 @@ import (
 
 	"github.com/anxuanzi/bua/browser"
 	"github.com/anxuanzi/bua/screenshot"
+	"github.com/anxuanzi/bua/testsite"
 )
 
 func main() {
+	// Serve the local fixture pages
+	site, err := testsite.Start()
+	if err != nil {
+		log.Fatalf("Failed to start test site: %v", err)
+	}
+	defer site.Close()
+
 	// Create browser configuration
 	cfg := browser.Config{
 		Headless:        false,
@@ -41,8 +49,8 @@ func main() {
 	}
 
 	// Navigate to a page with interactive elements
-	fmt.Println("Navigating to example.com...")
-	if err := b.Navigate(ctx, "https://example.com"); err != nil {
+	fmt.Println("Navigating to the test site index...")
+	if err := b.Navigate(ctx, site.URL("/index.html")); err != nil {
 		log.Fatalf("Failed to navigate: %v", err)
 	}
 
@@ -95,19 +103,19 @@ func main() {
 	fmt.Printf("Regular screenshot saved to: %s\n", regularPath)
 
 	// Try a more complex page
-	fmt.Println("\nNavigating to DuckDuckGo for more elements...")
-	if err := b.Navigate(ctx, "https://duckduckgo.com"); err != nil {
-		log.Fatalf("Failed to navigate to DuckDuckGo: %v", err)
+	fmt.Println("\nNavigating to the contact form for more elements...")
+	if err := b.Navigate(ctx, site.URL("/forms.html")); err != nil {
+		log.Fatalf("Failed to navigate to the contact form: %v", err)
 	}
 
 	time.Sleep(2 * time.Second)
 
-	// Get element map for DuckDuckGo
+	// Get element map for the contact form
 	elementMap2, err := b.GetElementMap(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get element map: %v", err)
 	}
-	fmt.Printf("Found %d interactive elements on DuckDuckGo\n", elementMap2.Len())
+	fmt.Printf("Found %d interactive elements on the contact form\n", elementMap2.Len())
 
 	// Take annotated screenshot
 	data2, err := b.ScreenshotSafeWithAnnotations(ctx, elementMap2)
@@ -115,11 +123,11 @@ func main() {
 		log.Fatalf("Failed to take screenshot: %v", err)
 	}
 
-	outputPath2 := filepath.Join(outputDir, "annotated_duckduckgo.jpg")
+	outputPath2 := filepath.Join(outputDir, "annotated_form.jpg")
 	if err := os.WriteFile(outputPath2, data2, 0644); err != nil {
 		log.Fatalf("Failed to save screenshot: %v", err)
 	}
-	fmt.Printf("DuckDuckGo annotated screenshot saved to: %s\n", outputPath2)
+	fmt.Printf("Contact form annotated screenshot saved to: %s\n", outputPath2)
 
 	// Print element details
 	fmt.Println("\n--- Interactive Elements Found ---")
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anxuanzi/bua"
+)
+
+// LangChainTool wraps a bua agent as a langchaingo tool.
+//
+// It satisfies github.com/tmc/langchaingo/tools.Tool structurally
+// (Name, Description, Call), so it can be passed to langchaingo agents
+// without this module depending on langchaingo:
+//
+//	agent, _ := bua.New(cfg)
+//	agent.Start(ctx)
+//	executor := agents.NewExecutor(agents.NewOneShotAgent(llm, []tools.Tool{export.NewLangChainTool(agent)}))
+type LangChainTool struct {
+	agent       *bua.Agent
+	name        string
+	description string
+}
+
+// LangChainOption customizes a LangChainTool.
+type LangChainOption func(*LangChainTool)
+
+// WithToolName overrides the tool name shown to the LLM.
+func WithToolName(name string) LangChainOption {
+	return func(t *LangChainTool) { t.name = name }
+}
+
+// WithToolDescription overrides the tool description shown to the LLM.
+func WithToolDescription(description string) LangChainOption {
+	return func(t *LangChainTool) { t.description = description }
+}
+
+// NewLangChainTool creates a langchaingo-compatible tool for a started agent.
+func NewLangChainTool(agent *bua.Agent, opts ...LangChainOption) *LangChainTool {
+	t := &LangChainTool{
+		agent: agent,
+		name:  "browser",
+		description: "Controls a real web browser to complete a task described in natural language, " +
+			"such as finding information on a website, filling a form, or extracting data. " +
+			`Input is the task as plain text, or JSON {"task": "...", "url": "optional start URL"}. ` +
+			"Returns the extracted data as JSON.",
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name returns the tool name.
+func (t *LangChainTool) Name() string {
+	return t.name
+}
+
+// Description returns the tool description.
+func (t *LangChainTool) Description() string {
+	return t.description
+}
+
+// Call runs the task described by input.
+// Task failures are returned as text so the calling agent can react to them;
+// only infrastructure failures (browser, API) are returned as errors.
+func (t *LangChainTool) Call(ctx context.Context, input string) (string, error) {
+	task, url := parseToolInput(input)
+	if task == "" {
+		return "", fmt.Errorf("empty task")
+	}
+
+	if url != "" {
+		if err := t.agent.Navigate(ctx, url); err != nil {
+			return "", fmt.Errorf("failed to navigate to %s: %w", url, err)
+		}
+	}
+
+	result, err := t.agent.Run(ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if !result.Success {
+		return "Task failed: " + result.Error, nil
+	}
+
+	if result.Data == nil {
+		return "Task completed.", nil
+	}
+	if s, ok := result.Data.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		return fmt.Sprintf("%v", result.Data), nil
+	}
+	return string(data), nil
+}
+
+// parseToolInput accepts plain text or a JSON object with task and url fields.
+func parseToolInput(input string) (task, url string) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") {
+		var in struct {
+			Task string `json:"task"`
+			URL  string `json:"url"`
+		}
+		if err := json.Unmarshal([]byte(input), &in); err == nil && in.Task != "" {
+			return in.Task, in.URL
+		}
+	}
+	return input, ""
+}
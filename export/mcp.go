@@ -0,0 +1,226 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// mcpProtocolVersion is the MCP revision this server implements.
+const mcpProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes used by the MCP server.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// MCPServer serves the browser tools over the Model Context Protocol.
+// It speaks newline-delimited JSON-RPC 2.0 (the MCP stdio transport), so
+// Claude Desktop, IDE agents, and other MCP clients can drive the browser.
+type MCPServer struct {
+	tools   *Tools
+	name    string
+	version string
+
+	writeMu sync.Mutex
+}
+
+// NewMCPServer creates an MCP server for the given toolset.
+func NewMCPServer(tools *Tools) *MCPServer {
+	return &MCPServer{
+		tools:   tools,
+		name:    "bua",
+		version: "1.0.0",
+	}
+}
+
+// rpcRequest is an incoming JSON-RPC request or notification.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is an outgoing JSON-RPC response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is a tool entry in a tools/list response.
+type mcpTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema any    `json:"inputSchema"`
+}
+
+// mcpContent is a content block in a tools/call response.
+type mcpContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// Serve reads requests from r and writes responses to w until r is exhausted
+// or ctx is canceled. Requests are handled sequentially, matching how a single
+// browser can only do one thing at a time.
+func (s *MCPServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(w, rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rpcErr := s.handle(ctx, req)
+
+		// Notifications have no ID and get no response
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+		if rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+		s.write(w, resp)
+	}
+
+	return scanner.Err()
+}
+
+// handle dispatches a single request.
+func (s *MCPServer) handle(ctx context.Context, req rpcRequest) (any, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: rpcInvalidRequest, Message: "jsonrpc must be \"2.0\""}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities": map[string]any{
+				"tools": map[string]any{},
+			},
+			"serverInfo": map[string]any{
+				"name":    s.name,
+				"version": s.version,
+			},
+		}, nil
+
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		return map[string]any{"tools": s.listTools()}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.callTool(ctx, params.Name, params.Arguments), nil
+
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// listTools converts the tool declarations to MCP tool entries.
+func (s *MCPServer) listTools() []mcpTool {
+	decls := s.tools.Declarations()
+	tools := make([]mcpTool, 0, len(decls))
+	for _, d := range decls {
+		var schema any = map[string]any{"type": "object"}
+		if d.ParametersJsonSchema != nil {
+			schema = d.ParametersJsonSchema
+		}
+		tools = append(tools, mcpTool{
+			Name:        d.Name,
+			Description: d.Description,
+			InputSchema: schema,
+		})
+	}
+	return tools
+}
+
+// callTool runs a tool and wraps its output as MCP content.
+// The screenshot tool additionally returns the image itself.
+func (s *MCPServer) callTool(ctx context.Context, name string, args map[string]any) map[string]any {
+	result, err := s.tools.Call(ctx, name, args)
+	if err != nil {
+		return map[string]any{
+			"content": []mcpContent{{Type: "text", Text: err.Error()}},
+			"isError": true,
+		}
+	}
+
+	text, _ := json.Marshal(result)
+	content := []mcpContent{{Type: "text", Text: string(text)}}
+
+	if name == "screenshot" {
+		fullPage, _ := args["full_page"].(bool)
+		if data, err := s.tools.Browser().Screenshot(ctx, fullPage); err == nil && len(data) > 0 {
+			content = append(content, mcpContent{
+				Type:     "image",
+				Data:     base64.StdEncoding.EncodeToString(data),
+				MimeType: "image/jpeg",
+			})
+		}
+	}
+
+	success, _ := result["success"].(bool)
+	return map[string]any{
+		"content": content,
+		"isError": !success,
+	}
+}
+
+// write encodes one response line.
+func (s *MCPServer) write(w io.Writer, resp rpcResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolSpec is an OpenAI-compatible tool definition, as sent in the "tools"
+// field of a chat completions request.
+type ToolSpec struct {
+	Type     string       `json:"type"` // Always "function"
+	Function FunctionSpec `json:"function"`
+}
+
+// FunctionSpec describes a callable function and its JSON Schema parameters.
+type FunctionSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Parameters  any    `json:"parameters"`
+}
+
+// ToolCall is a tool call returned by an OpenAI-compatible model.
+type ToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded object
+	} `json:"function"`
+}
+
+// ToolMessage is the "tool" role message that answers a ToolCall.
+type ToolMessage struct {
+	Role       string `json:"role"` // Always "tool"
+	ToolCallID string `json:"tool_call_id"`
+	Content    string `json:"content"`
+}
+
+// ToolSpecs returns the browser toolset as OpenAI-compatible tool definitions.
+// The specs don't depend on a browser, so they can be built before one starts.
+func ToolSpecs() []ToolSpec {
+	tools, err := NewTools(nil)
+	if err != nil {
+		return nil
+	}
+	return tools.ToolSpecs()
+}
+
+// ToolSpecs returns this toolset as OpenAI-compatible tool definitions.
+func (t *Tools) ToolSpecs() []ToolSpec {
+	decls := t.Declarations()
+	specs := make([]ToolSpec, 0, len(decls))
+	for _, d := range decls {
+		var params any = map[string]any{"type": "object", "properties": map[string]any{}}
+		if d.ParametersJsonSchema != nil {
+			params = d.ParametersJsonSchema
+		}
+		specs = append(specs, ToolSpec{
+			Type: "function",
+			Function: FunctionSpec{
+				Name:        d.Name,
+				Description: d.Description,
+				Parameters:  params,
+			},
+		})
+	}
+	return specs
+}
+
+// Dispatcher executes tool calls returned by a non-ADK LLM client.
+type Dispatcher struct {
+	tools *Tools
+}
+
+// NewDispatcher creates a dispatcher for a started browser's toolset.
+func NewDispatcher(tools *Tools) *Dispatcher {
+	return &Dispatcher{tools: tools}
+}
+
+// Dispatch executes calls in order and returns one tool message per call,
+// ready to append to the conversation. Calls run sequentially because they
+// share a single browser.
+func (d *Dispatcher) Dispatch(ctx context.Context, calls []ToolCall) []ToolMessage {
+	msgs := make([]ToolMessage, len(calls))
+	for i, call := range calls {
+		msgs[i] = d.Execute(ctx, call)
+	}
+	return msgs
+}
+
+// Execute runs a single tool call. Failures are reported in the message
+// content as {"success": false, "message": "..."} so the model can recover.
+func (d *Dispatcher) Execute(ctx context.Context, call ToolCall) ToolMessage {
+	msg := ToolMessage{Role: "tool", ToolCallID: call.ID}
+
+	args := map[string]any{}
+	if call.Function.Arguments != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			msg.Content = errorContent(fmt.Sprintf("invalid arguments: %v", err))
+			return msg
+		}
+	}
+
+	result, err := d.tools.Call(ctx, call.Function.Name, args)
+	if err != nil {
+		msg.Content = errorContent(err.Error())
+		return msg
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		msg.Content = errorContent(fmt.Sprintf("failed to encode result: %v", err))
+		return msg
+	}
+	msg.Content = string(data)
+	return msg
+}
+
+// errorContent formats a failure in the same shape as tool results.
+func errorContent(message string) string {
+	data, _ := json.Marshal(map[string]any{"success": false, "message": message})
+	return string(data)
+}
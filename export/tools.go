@@ -0,0 +1,127 @@
+// Package export exposes bua's browser tools to other agent ecosystems.
+//
+// The tools are the same ones the built-in agent uses (navigate, click,
+// type_text, ...), driven directly against a browser.Browser without the
+// ADK runner. Adapters in this package publish them over MCP and other
+// tool-calling protocols.
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+
+	"github.com/anxuanzi/bua/agent"
+	"github.com/anxuanzi/bua/browser"
+)
+
+// excludedTools are agent-loop tools that make no sense to external callers.
+var excludedTools = map[string]bool{
+	"done": true,
+}
+
+// runnableTool is implemented by ADK function tools.
+type runnableTool interface {
+	tool.Tool
+	Declaration() *genai.FunctionDeclaration
+	Run(ctx tool.Context, args any) (map[string]any, error)
+}
+
+// Tools is the browser toolset, callable outside the ADK agent loop.
+type Tools struct {
+	browser *browser.Browser
+	toolkit *agent.BrowserToolkit
+	tools   []runnableTool
+	byName  map[string]runnableTool
+}
+
+// NewTools creates the exported toolset for a started browser.
+func NewTools(b *browser.Browser) (*Tools, error) {
+	toolkit := agent.NewBrowserToolkit(b, 1280)
+	all, err := toolkit.CreateAllTools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser tools: %w", err)
+	}
+
+	t := &Tools{
+		browser: b,
+		toolkit: toolkit,
+		byName:  make(map[string]runnableTool),
+	}
+	for _, bt := range all {
+		rt, ok := bt.(runnableTool)
+		if !ok || excludedTools[bt.Name()] {
+			continue
+		}
+		t.tools = append(t.tools, rt)
+		t.byName[bt.Name()] = rt
+	}
+
+	return t, nil
+}
+
+// Browser returns the browser the tools operate on.
+func (t *Tools) Browser() *browser.Browser {
+	return t.browser
+}
+
+// Declarations returns the function declarations of all exported tools.
+func (t *Tools) Declarations() []*genai.FunctionDeclaration {
+	decls := make([]*genai.FunctionDeclaration, len(t.tools))
+	for i, rt := range t.tools {
+		decls[i] = rt.Declaration()
+	}
+	return decls
+}
+
+// Call executes a tool by name with JSON-style arguments.
+// Tool-level failures are reported in the result ("success": false), not as errors.
+func (t *Tools) Call(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
+	rt, ok := t.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	// Element indices refer to the last extracted element map; make sure one exists
+	if t.toolkit.GetElementMap() == nil {
+		_ = t.toolkit.RefreshElementMap()
+	}
+
+	return rt.Run(newStandaloneContext(ctx, name), args)
+}
+
+// standaloneContext satisfies tool.Context for calls made outside an ADK invocation.
+// Only the context and per-call methods are backed; the browser tools use nothing else.
+type standaloneContext struct {
+	tool.Context // nil; unused methods panic if called
+
+	ctx     context.Context
+	callID  string
+	actions *session.EventActions
+}
+
+func newStandaloneContext(ctx context.Context, name string) *standaloneContext {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &standaloneContext{
+		ctx:     ctx,
+		callID:  name,
+		actions: &session.EventActions{},
+	}
+}
+
+func (c *standaloneContext) Deadline() (deadline time.Time, ok bool) { return c.ctx.Deadline() }
+func (c *standaloneContext) Done() <-chan struct{}                   { return c.ctx.Done() }
+func (c *standaloneContext) Err() error                              { return c.ctx.Err() }
+func (c *standaloneContext) Value(key any) any                       { return c.ctx.Value(key) }
+func (c *standaloneContext) FunctionCallID() string                  { return c.callID }
+func (c *standaloneContext) Actions() *session.EventActions          { return c.actions }
+func (c *standaloneContext) AgentName() string                       { return "bua_export" }
@@ -0,0 +1,1631 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: grpcapi/proto/bua.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TabAction is what a Tabs call does before listing the tabs.
+type TabAction int32
+
+const (
+	TabAction_TAB_ACTION_LIST TabAction = 0
+	// Open a tab at url and make it active.
+	TabAction_TAB_ACTION_NEW TabAction = 1
+	// Make tab_id the active tab.
+	TabAction_TAB_ACTION_SWITCH TabAction = 2
+	// Close tab_id.
+	TabAction_TAB_ACTION_CLOSE TabAction = 3
+)
+
+// Enum value maps for TabAction.
+var (
+	TabAction_name = map[int32]string{
+		0: "TAB_ACTION_LIST",
+		1: "TAB_ACTION_NEW",
+		2: "TAB_ACTION_SWITCH",
+		3: "TAB_ACTION_CLOSE",
+	}
+	TabAction_value = map[string]int32{
+		"TAB_ACTION_LIST":   0,
+		"TAB_ACTION_NEW":    1,
+		"TAB_ACTION_SWITCH": 2,
+		"TAB_ACTION_CLOSE":  3,
+	}
+)
+
+func (x TabAction) Enum() *TabAction {
+	p := new(TabAction)
+	*p = x
+	return p
+}
+
+func (x TabAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TabAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_grpcapi_proto_bua_proto_enumTypes[0].Descriptor()
+}
+
+func (TabAction) Type() protoreflect.EnumType {
+	return &file_grpcapi_proto_bua_proto_enumTypes[0]
+}
+
+func (x TabAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TabAction.Descriptor instead.
+func (TabAction) EnumDescriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{0}
+}
+
+// RunRequest starts a task.
+type RunRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What to do, in natural language.
+	Task string `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	// Page to open before the task starts, if any.
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Time limit for the task; unset for none.
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunRequest) Reset() {
+	*x = RunRequest{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunRequest) ProtoMessage() {}
+
+func (x *RunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunRequest.ProtoReflect.Descriptor instead.
+func (*RunRequest) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RunRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *RunRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *RunRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+// Result is the outcome of a task.
+type Result struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Extracted data or task output.
+	Data  *structpb.Value `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Error string          `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Failure class, e.g. "step_budget".
+	ErrorCode string               `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Summary   string               `protobuf:"bytes,5,opt,name=summary,proto3" json:"summary,omitempty"`
+	Steps     []*Step              `protobuf:"bytes,6,rep,name=steps,proto3" json:"steps,omitempty"`
+	Duration  *durationpb.Duration `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	Usage     *TokenUsage          `protobuf:"bytes,8,opt,name=usage,proto3" json:"usage,omitempty"`
+	// Cost of usage in US dollars; 0 for models without a price.
+	EstimatedCost float64 `protobuf:"fixed64,9,opt,name=estimated_cost,json=estimatedCost,proto3" json:"estimated_cost,omitempty"`
+	PartialResult bool    `protobuf:"varint,10,opt,name=partial_result,json=partialResult,proto3" json:"partial_result,omitempty"`
+	// How the task ended: "done", "budget", "timeout", "error" or "takeover".
+	CompletionReason string       `protobuf:"bytes,11,opt,name=completion_reason,json=completionReason,proto3" json:"completion_reason,omitempty"`
+	ScreenshotPaths  []string     `protobuf:"bytes,12,rep,name=screenshot_paths,json=screenshotPaths,proto3" json:"screenshot_paths,omitempty"`
+	RecordingPath    string       `protobuf:"bytes,13,opt,name=recording_path,json=recordingPath,proto3" json:"recording_path,omitempty"`
+	DownloadPaths    []string     `protobuf:"bytes,14,rep,name=download_paths,json=downloadPaths,proto3" json:"download_paths,omitempty"`
+	Assertions       []*Assertion `protobuf:"bytes,15,rep,name=assertions,proto3" json:"assertions,omitempty"`
+	Sections         []*Section   `protobuf:"bytes,16,rep,name=sections,proto3" json:"sections,omitempty"`
+	Findings         []*Finding   `protobuf:"bytes,17,rep,name=findings,proto3" json:"findings,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Result) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *Result) GetData() *structpb.Value {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *Result) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Result) GetSteps() []*Step {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Result) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Result) GetUsage() *TokenUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *Result) GetEstimatedCost() float64 {
+	if x != nil {
+		return x.EstimatedCost
+	}
+	return 0
+}
+
+func (x *Result) GetPartialResult() bool {
+	if x != nil {
+		return x.PartialResult
+	}
+	return false
+}
+
+func (x *Result) GetCompletionReason() string {
+	if x != nil {
+		return x.CompletionReason
+	}
+	return ""
+}
+
+func (x *Result) GetScreenshotPaths() []string {
+	if x != nil {
+		return x.ScreenshotPaths
+	}
+	return nil
+}
+
+func (x *Result) GetRecordingPath() string {
+	if x != nil {
+		return x.RecordingPath
+	}
+	return ""
+}
+
+func (x *Result) GetDownloadPaths() []string {
+	if x != nil {
+		return x.DownloadPaths
+	}
+	return nil
+}
+
+func (x *Result) GetAssertions() []*Assertion {
+	if x != nil {
+		return x.Assertions
+	}
+	return nil
+}
+
+func (x *Result) GetSections() []*Section {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+func (x *Result) GetFindings() []*Finding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+// Step is one action the agent took.
+type Step struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 1-based step index.
+	Number int32 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// Tool that was called, e.g. "click".
+	Action              string               `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Target              string               `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Thinking            string               `protobuf:"bytes,4,opt,name=thinking,proto3" json:"thinking,omitempty"`
+	Evaluation          string               `protobuf:"bytes,5,opt,name=evaluation,proto3" json:"evaluation,omitempty"`
+	NextGoal            string               `protobuf:"bytes,6,opt,name=next_goal,json=nextGoal,proto3" json:"next_goal,omitempty"`
+	Memory              string               `protobuf:"bytes,7,opt,name=memory,proto3" json:"memory,omitempty"`
+	Url                 string               `protobuf:"bytes,8,opt,name=url,proto3" json:"url,omitempty"`
+	Title               string               `protobuf:"bytes,9,opt,name=title,proto3" json:"title,omitempty"`
+	ScreenshotPath      string               `protobuf:"bytes,10,opt,name=screenshot_path,json=screenshotPath,proto3" json:"screenshot_path,omitempty"`
+	AfterScreenshotPath string               `protobuf:"bytes,11,opt,name=after_screenshot_path,json=afterScreenshotPath,proto3" json:"after_screenshot_path,omitempty"`
+	Success             bool                 `protobuf:"varint,12,opt,name=success,proto3" json:"success,omitempty"`
+	Duration            *durationpb.Duration `protobuf:"bytes,13,opt,name=duration,proto3" json:"duration,omitempty"`
+	Error               string               `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode           string               `protobuf:"bytes,15,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// Tokens of the model call that chose this action, if counted here.
+	Usage         *TokenUsage `protobuf:"bytes,16,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Step) Reset() {
+	*x = Step{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Step) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Step) ProtoMessage() {}
+
+func (x *Step) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Step.ProtoReflect.Descriptor instead.
+func (*Step) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Step) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Step) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Step) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Step) GetThinking() string {
+	if x != nil {
+		return x.Thinking
+	}
+	return ""
+}
+
+func (x *Step) GetEvaluation() string {
+	if x != nil {
+		return x.Evaluation
+	}
+	return ""
+}
+
+func (x *Step) GetNextGoal() string {
+	if x != nil {
+		return x.NextGoal
+	}
+	return ""
+}
+
+func (x *Step) GetMemory() string {
+	if x != nil {
+		return x.Memory
+	}
+	return ""
+}
+
+func (x *Step) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Step) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Step) GetScreenshotPath() string {
+	if x != nil {
+		return x.ScreenshotPath
+	}
+	return ""
+}
+
+func (x *Step) GetAfterScreenshotPath() string {
+	if x != nil {
+		return x.AfterScreenshotPath
+	}
+	return ""
+}
+
+func (x *Step) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *Step) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Step) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Step) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *Step) GetUsage() *TokenUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// TokenUsage counts the tokens of model calls.
+type TokenUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	InputTokens    int64                  `protobuf:"varint,1,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
+	OutputTokens   int64                  `protobuf:"varint,2,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	ThinkingTokens int64                  `protobuf:"varint,3,opt,name=thinking_tokens,json=thinkingTokens,proto3" json:"thinking_tokens,omitempty"`
+	CachedTokens   int64                  `protobuf:"varint,4,opt,name=cached_tokens,json=cachedTokens,proto3" json:"cached_tokens,omitempty"`
+	TotalTokens    int64                  `protobuf:"varint,5,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TokenUsage) Reset() {
+	*x = TokenUsage{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenUsage) ProtoMessage() {}
+
+func (x *TokenUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenUsage.ProtoReflect.Descriptor instead.
+func (*TokenUsage) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TokenUsage) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetThinkingTokens() int64 {
+	if x != nil {
+		return x.ThinkingTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetCachedTokens() int64 {
+	if x != nil {
+		return x.CachedTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+// Assertion is a check the agent recorded with its assert tool.
+type Assertion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Expected      string                 `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`
+	Actual        string                 `protobuf:"bytes,4,opt,name=actual,proto3" json:"actual,omitempty"`
+	Passed        bool                   `protobuf:"varint,5,opt,name=passed,proto3" json:"passed,omitempty"`
+	Url           string                 `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Assertion) Reset() {
+	*x = Assertion{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Assertion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assertion) ProtoMessage() {}
+
+func (x *Assertion) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assertion.ProtoReflect.Descriptor instead.
+func (*Assertion) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Assertion) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Assertion) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Assertion) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *Assertion) GetActual() string {
+	if x != nil {
+		return x.Actual
+	}
+	return ""
+}
+
+func (x *Assertion) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *Assertion) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Assertion) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Section is the result of one objective, filed with report_section.
+type Section struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Objective string                 `protobuf:"bytes,1,opt,name=objective,proto3" json:"objective,omitempty"`
+	// "complete", "partial" or "failed".
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	Data          *structpb.Value        `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Url           string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Section) Reset() {
+	*x = Section{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Section) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Section) ProtoMessage() {}
+
+func (x *Section) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Section.ProtoReflect.Descriptor instead.
+func (*Section) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Section) GetObjective() string {
+	if x != nil {
+		return x.Objective
+	}
+	return ""
+}
+
+func (x *Section) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Section) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Section) GetData() *structpb.Value {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Section) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Section) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Finding is an entity the agent saved with save_finding.
+type Finding struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Data      *structpb.Struct       `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Url       string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// How often the entity was saved; past 1, the saves were merged.
+	Saves         int32 `protobuf:"varint,4,opt,name=saves,proto3" json:"saves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Finding) Reset() {
+	*x = Finding{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Finding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Finding) ProtoMessage() {}
+
+func (x *Finding) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Finding.ProtoReflect.Descriptor instead.
+func (*Finding) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Finding) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Finding) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Finding) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Finding) GetSaves() int32 {
+	if x != nil {
+		return x.Saves
+	}
+	return 0
+}
+
+// StreamEvent is one message of a Stream call: a step, then the result last.
+type StreamEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*StreamEvent_Step
+	//	*StreamEvent_Result
+	Event         isStreamEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEvent) Reset() {
+	*x = StreamEvent{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEvent) ProtoMessage() {}
+
+func (x *StreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEvent.ProtoReflect.Descriptor instead.
+func (*StreamEvent) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamEvent) GetEvent() isStreamEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *StreamEvent) GetStep() *Step {
+	if x != nil {
+		if x, ok := x.Event.(*StreamEvent_Step); ok {
+			return x.Step
+		}
+	}
+	return nil
+}
+
+func (x *StreamEvent) GetResult() *Result {
+	if x != nil {
+		if x, ok := x.Event.(*StreamEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isStreamEvent_Event interface {
+	isStreamEvent_Event()
+}
+
+type StreamEvent_Step struct {
+	Step *Step `protobuf:"bytes,1,opt,name=step,proto3,oneof"`
+}
+
+type StreamEvent_Result struct {
+	Result *Result `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*StreamEvent_Step) isStreamEvent_Event() {}
+
+func (*StreamEvent_Result) isStreamEvent_Event() {}
+
+// ScreenshotRequest selects what to capture.
+type ScreenshotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Capture the whole page rather than the viewport.
+	FullPage      bool `protobuf:"varint,1,opt,name=full_page,json=fullPage,proto3" json:"full_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenshotRequest) Reset() {
+	*x = ScreenshotRequest{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotRequest) ProtoMessage() {}
+
+func (x *ScreenshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotRequest.ProtoReflect.Descriptor instead.
+func (*ScreenshotRequest) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScreenshotRequest) GetFullPage() bool {
+	if x != nil {
+		return x.FullPage
+	}
+	return false
+}
+
+// ScreenshotResponse holds the captured image.
+type ScreenshotResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// JPEG image data.
+	Image         []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenshotResponse) Reset() {
+	*x = ScreenshotResponse{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotResponse) ProtoMessage() {}
+
+func (x *ScreenshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotResponse.ProtoReflect.Descriptor instead.
+func (*ScreenshotResponse) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ScreenshotResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+// Cookie is a browser cookie.
+type Cookie struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value  string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Domain string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	Path   string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	// Unset for session cookies.
+	Expires  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires,proto3" json:"expires,omitempty"`
+	HttpOnly bool                   `protobuf:"varint,6,opt,name=http_only,json=httpOnly,proto3" json:"http_only,omitempty"`
+	Secure   bool                   `protobuf:"varint,7,opt,name=secure,proto3" json:"secure,omitempty"`
+	// "Strict", "Lax" or "None".
+	SameSite      string `protobuf:"bytes,8,opt,name=same_site,json=sameSite,proto3" json:"same_site,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cookie) Reset() {
+	*x = Cookie{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cookie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cookie) ProtoMessage() {}
+
+func (x *Cookie) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cookie.ProtoReflect.Descriptor instead.
+func (*Cookie) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Cookie) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Cookie) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Cookie) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Cookie) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Cookie) GetExpires() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Expires
+	}
+	return nil
+}
+
+func (x *Cookie) GetHttpOnly() bool {
+	if x != nil {
+		return x.HttpOnly
+	}
+	return false
+}
+
+func (x *Cookie) GetSecure() bool {
+	if x != nil {
+		return x.Secure
+	}
+	return false
+}
+
+func (x *Cookie) GetSameSite() string {
+	if x != nil {
+		return x.SameSite
+	}
+	return ""
+}
+
+// CookiesRequest optionally changes cookies before listing them.
+type CookiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cookies to add or replace, after clearing if clear is set.
+	Set []*Cookie `protobuf:"bytes,1,rep,name=set,proto3" json:"set,omitempty"`
+	// Delete all cookies first.
+	Clear         bool `protobuf:"varint,2,opt,name=clear,proto3" json:"clear,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CookiesRequest) Reset() {
+	*x = CookiesRequest{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CookiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CookiesRequest) ProtoMessage() {}
+
+func (x *CookiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CookiesRequest.ProtoReflect.Descriptor instead.
+func (*CookiesRequest) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CookiesRequest) GetSet() []*Cookie {
+	if x != nil {
+		return x.Set
+	}
+	return nil
+}
+
+func (x *CookiesRequest) GetClear() bool {
+	if x != nil {
+		return x.Clear
+	}
+	return false
+}
+
+// CookiesResponse lists the browser's cookies.
+type CookiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cookies       []*Cookie              `protobuf:"bytes,1,rep,name=cookies,proto3" json:"cookies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CookiesResponse) Reset() {
+	*x = CookiesResponse{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CookiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CookiesResponse) ProtoMessage() {}
+
+func (x *CookiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CookiesResponse.ProtoReflect.Descriptor instead.
+func (*CookiesResponse) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CookiesResponse) GetCookies() []*Cookie {
+	if x != nil {
+		return x.Cookies
+	}
+	return nil
+}
+
+// TabsRequest lists or manipulates tabs.
+type TabsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        TabAction              `protobuf:"varint,1,opt,name=action,proto3,enum=bua.v1.TabAction" json:"action,omitempty"`
+	TabId         string                 `protobuf:"bytes,2,opt,name=tab_id,json=tabId,proto3" json:"tab_id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TabsRequest) Reset() {
+	*x = TabsRequest{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TabsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TabsRequest) ProtoMessage() {}
+
+func (x *TabsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TabsRequest.ProtoReflect.Descriptor instead.
+func (*TabsRequest) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TabsRequest) GetAction() TabAction {
+	if x != nil {
+		return x.Action
+	}
+	return TabAction_TAB_ACTION_LIST
+}
+
+func (x *TabsRequest) GetTabId() string {
+	if x != nil {
+		return x.TabId
+	}
+	return ""
+}
+
+func (x *TabsRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// TabsResponse lists the open tabs.
+type TabsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tabs  []*TabInfo             `protobuf:"bytes,1,rep,name=tabs,proto3" json:"tabs,omitempty"`
+	// The tab opened or switched to, if any.
+	TabId         string `protobuf:"bytes,2,opt,name=tab_id,json=tabId,proto3" json:"tab_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TabsResponse) Reset() {
+	*x = TabsResponse{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TabsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TabsResponse) ProtoMessage() {}
+
+func (x *TabsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TabsResponse.ProtoReflect.Descriptor instead.
+func (*TabsResponse) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TabsResponse) GetTabs() []*TabInfo {
+	if x != nil {
+		return x.Tabs
+	}
+	return nil
+}
+
+func (x *TabsResponse) GetTabId() string {
+	if x != nil {
+		return x.TabId
+	}
+	return ""
+}
+
+// TabInfo describes an open tab.
+type TabInfo struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url    string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Title  string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Active bool                   `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	// The tab has its own cookies and storage.
+	Isolated bool `protobuf:"varint,5,opt,name=isolated,proto3" json:"isolated,omitempty"`
+	// What the tab is for.
+	Label         string `protobuf:"bytes,6,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TabInfo) Reset() {
+	*x = TabInfo{}
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TabInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TabInfo) ProtoMessage() {}
+
+func (x *TabInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_proto_bua_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TabInfo.ProtoReflect.Descriptor instead.
+func (*TabInfo) Descriptor() ([]byte, []int) {
+	return file_grpcapi_proto_bua_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TabInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TabInfo) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *TabInfo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TabInfo) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *TabInfo) GetIsolated() bool {
+	if x != nil {
+		return x.Isolated
+	}
+	return false
+}
+
+func (x *TabInfo) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+var File_grpcapi_proto_bua_proto protoreflect.FileDescriptor
+
+const file_grpcapi_proto_bua_proto_rawDesc = "" +
+	"\n" +
+	"\x17grpcapi/proto/bua.proto\x12\x06bua.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"g\n" +
+	"\n" +
+	"RunRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xa3\x05\n" +
+	"\x06Result\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12*\n" +
+	"\x04data\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x04data\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode\x12\x18\n" +
+	"\asummary\x18\x05 \x01(\tR\asummary\x12\"\n" +
+	"\x05steps\x18\x06 \x03(\v2\f.bua.v1.StepR\x05steps\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x12(\n" +
+	"\x05usage\x18\b \x01(\v2\x12.bua.v1.TokenUsageR\x05usage\x12%\n" +
+	"\x0eestimated_cost\x18\t \x01(\x01R\restimatedCost\x12%\n" +
+	"\x0epartial_result\x18\n" +
+	" \x01(\bR\rpartialResult\x12+\n" +
+	"\x11completion_reason\x18\v \x01(\tR\x10completionReason\x12)\n" +
+	"\x10screenshot_paths\x18\f \x03(\tR\x0fscreenshotPaths\x12%\n" +
+	"\x0erecording_path\x18\r \x01(\tR\rrecordingPath\x12%\n" +
+	"\x0edownload_paths\x18\x0e \x03(\tR\rdownloadPaths\x121\n" +
+	"\n" +
+	"assertions\x18\x0f \x03(\v2\x11.bua.v1.AssertionR\n" +
+	"assertions\x12+\n" +
+	"\bsections\x18\x10 \x03(\v2\x0f.bua.v1.SectionR\bsections\x12+\n" +
+	"\bfindings\x18\x11 \x03(\v2\x0f.bua.v1.FindingR\bfindings\"\xf4\x03\n" +
+	"\x04Step\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x1a\n" +
+	"\bthinking\x18\x04 \x01(\tR\bthinking\x12\x1e\n" +
+	"\n" +
+	"evaluation\x18\x05 \x01(\tR\n" +
+	"evaluation\x12\x1b\n" +
+	"\tnext_goal\x18\x06 \x01(\tR\bnextGoal\x12\x16\n" +
+	"\x06memory\x18\a \x01(\tR\x06memory\x12\x10\n" +
+	"\x03url\x18\b \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\t \x01(\tR\x05title\x12'\n" +
+	"\x0fscreenshot_path\x18\n" +
+	" \x01(\tR\x0escreenshotPath\x122\n" +
+	"\x15after_screenshot_path\x18\v \x01(\tR\x13afterScreenshotPath\x12\x18\n" +
+	"\asuccess\x18\f \x01(\bR\asuccess\x125\n" +
+	"\bduration\x18\r \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x0f \x01(\tR\terrorCode\x12(\n" +
+	"\x05usage\x18\x10 \x01(\v2\x12.bua.v1.TokenUsageR\x05usage\"\xc5\x01\n" +
+	"\n" +
+	"TokenUsage\x12!\n" +
+	"\finput_tokens\x18\x01 \x01(\x03R\vinputTokens\x12#\n" +
+	"\routput_tokens\x18\x02 \x01(\x03R\foutputTokens\x12'\n" +
+	"\x0fthinking_tokens\x18\x03 \x01(\x03R\x0ethinkingTokens\x12#\n" +
+	"\rcached_tokens\x18\x04 \x01(\x03R\fcachedTokens\x12!\n" +
+	"\ftotal_tokens\x18\x05 \x01(\x03R\vtotalTokens\"\xd9\x01\n" +
+	"\tAssertion\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bexpected\x18\x03 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\x04 \x01(\tR\x06actual\x12\x16\n" +
+	"\x06passed\x18\x05 \x01(\bR\x06passed\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xd1\x01\n" +
+	"\aSection\x12\x1c\n" +
+	"\tobjective\x18\x01 \x01(\tR\tobjective\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12*\n" +
+	"\x04data\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x04data\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03url\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x98\x01\n" +
+	"\aFinding\x12+\n" +
+	"\x04data\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x04data\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05saves\x18\x04 \x01(\x05R\x05saves\"d\n" +
+	"\vStreamEvent\x12\"\n" +
+	"\x04step\x18\x01 \x01(\v2\f.bua.v1.StepH\x00R\x04step\x12(\n" +
+	"\x06result\x18\x02 \x01(\v2\x0e.bua.v1.ResultH\x00R\x06resultB\a\n" +
+	"\x05event\"0\n" +
+	"\x11ScreenshotRequest\x12\x1b\n" +
+	"\tfull_page\x18\x01 \x01(\bR\bfullPage\"*\n" +
+	"\x12ScreenshotResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\"\xe6\x01\n" +
+	"\x06Cookie\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x124\n" +
+	"\aexpires\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aexpires\x12\x1b\n" +
+	"\thttp_only\x18\x06 \x01(\bR\bhttpOnly\x12\x16\n" +
+	"\x06secure\x18\a \x01(\bR\x06secure\x12\x1b\n" +
+	"\tsame_site\x18\b \x01(\tR\bsameSite\"H\n" +
+	"\x0eCookiesRequest\x12 \n" +
+	"\x03set\x18\x01 \x03(\v2\x0e.bua.v1.CookieR\x03set\x12\x14\n" +
+	"\x05clear\x18\x02 \x01(\bR\x05clear\";\n" +
+	"\x0fCookiesResponse\x12(\n" +
+	"\acookies\x18\x01 \x03(\v2\x0e.bua.v1.CookieR\acookies\"a\n" +
+	"\vTabsRequest\x12)\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x11.bua.v1.TabActionR\x06action\x12\x15\n" +
+	"\x06tab_id\x18\x02 \x01(\tR\x05tabId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"J\n" +
+	"\fTabsResponse\x12#\n" +
+	"\x04tabs\x18\x01 \x03(\v2\x0f.bua.v1.TabInfoR\x04tabs\x12\x15\n" +
+	"\x06tab_id\x18\x02 \x01(\tR\x05tabId\"\x8b\x01\n" +
+	"\aTabInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x16\n" +
+	"\x06active\x18\x04 \x01(\bR\x06active\x12\x1a\n" +
+	"\bisolated\x18\x05 \x01(\bR\bisolated\x12\x14\n" +
+	"\x05label\x18\x06 \x01(\tR\x05label*a\n" +
+	"\tTabAction\x12\x13\n" +
+	"\x0fTAB_ACTION_LIST\x10\x00\x12\x12\n" +
+	"\x0eTAB_ACTION_NEW\x10\x01\x12\x15\n" +
+	"\x11TAB_ACTION_SWITCH\x10\x02\x12\x14\n" +
+	"\x10TAB_ACTION_CLOSE\x10\x032\xa7\x02\n" +
+	"\x11BrowserAutomation\x12)\n" +
+	"\x03Run\x12\x12.bua.v1.RunRequest\x1a\x0e.bua.v1.Result\x123\n" +
+	"\x06Stream\x12\x12.bua.v1.RunRequest\x1a\x13.bua.v1.StreamEvent0\x01\x12C\n" +
+	"\n" +
+	"Screenshot\x12\x19.bua.v1.ScreenshotRequest\x1a\x1a.bua.v1.ScreenshotResponse\x12:\n" +
+	"\aCookies\x12\x16.bua.v1.CookiesRequest\x1a\x17.bua.v1.CookiesResponse\x121\n" +
+	"\x04Tabs\x12\x13.bua.v1.TabsRequest\x1a\x14.bua.v1.TabsResponseB!Z\x1fgithub.com/anxuanzi/bua/grpcapib\x06proto3"
+
+var (
+	file_grpcapi_proto_bua_proto_rawDescOnce sync.Once
+	file_grpcapi_proto_bua_proto_rawDescData []byte
+)
+
+func file_grpcapi_proto_bua_proto_rawDescGZIP() []byte {
+	file_grpcapi_proto_bua_proto_rawDescOnce.Do(func() {
+		file_grpcapi_proto_bua_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grpcapi_proto_bua_proto_rawDesc), len(file_grpcapi_proto_bua_proto_rawDesc)))
+	})
+	return file_grpcapi_proto_bua_proto_rawDescData
+}
+
+var file_grpcapi_proto_bua_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_grpcapi_proto_bua_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_grpcapi_proto_bua_proto_goTypes = []any{
+	(TabAction)(0),                // 0: bua.v1.TabAction
+	(*RunRequest)(nil),            // 1: bua.v1.RunRequest
+	(*Result)(nil),                // 2: bua.v1.Result
+	(*Step)(nil),                  // 3: bua.v1.Step
+	(*TokenUsage)(nil),            // 4: bua.v1.TokenUsage
+	(*Assertion)(nil),             // 5: bua.v1.Assertion
+	(*Section)(nil),               // 6: bua.v1.Section
+	(*Finding)(nil),               // 7: bua.v1.Finding
+	(*StreamEvent)(nil),           // 8: bua.v1.StreamEvent
+	(*ScreenshotRequest)(nil),     // 9: bua.v1.ScreenshotRequest
+	(*ScreenshotResponse)(nil),    // 10: bua.v1.ScreenshotResponse
+	(*Cookie)(nil),                // 11: bua.v1.Cookie
+	(*CookiesRequest)(nil),        // 12: bua.v1.CookiesRequest
+	(*CookiesResponse)(nil),       // 13: bua.v1.CookiesResponse
+	(*TabsRequest)(nil),           // 14: bua.v1.TabsRequest
+	(*TabsResponse)(nil),          // 15: bua.v1.TabsResponse
+	(*TabInfo)(nil),               // 16: bua.v1.TabInfo
+	(*durationpb.Duration)(nil),   // 17: google.protobuf.Duration
+	(*structpb.Value)(nil),        // 18: google.protobuf.Value
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 20: google.protobuf.Struct
+}
+var file_grpcapi_proto_bua_proto_depIdxs = []int32{
+	17, // 0: bua.v1.RunRequest.timeout:type_name -> google.protobuf.Duration
+	18, // 1: bua.v1.Result.data:type_name -> google.protobuf.Value
+	3,  // 2: bua.v1.Result.steps:type_name -> bua.v1.Step
+	17, // 3: bua.v1.Result.duration:type_name -> google.protobuf.Duration
+	4,  // 4: bua.v1.Result.usage:type_name -> bua.v1.TokenUsage
+	5,  // 5: bua.v1.Result.assertions:type_name -> bua.v1.Assertion
+	6,  // 6: bua.v1.Result.sections:type_name -> bua.v1.Section
+	7,  // 7: bua.v1.Result.findings:type_name -> bua.v1.Finding
+	17, // 8: bua.v1.Step.duration:type_name -> google.protobuf.Duration
+	4,  // 9: bua.v1.Step.usage:type_name -> bua.v1.TokenUsage
+	19, // 10: bua.v1.Assertion.timestamp:type_name -> google.protobuf.Timestamp
+	18, // 11: bua.v1.Section.data:type_name -> google.protobuf.Value
+	19, // 12: bua.v1.Section.timestamp:type_name -> google.protobuf.Timestamp
+	20, // 13: bua.v1.Finding.data:type_name -> google.protobuf.Struct
+	19, // 14: bua.v1.Finding.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 15: bua.v1.StreamEvent.step:type_name -> bua.v1.Step
+	2,  // 16: bua.v1.StreamEvent.result:type_name -> bua.v1.Result
+	19, // 17: bua.v1.Cookie.expires:type_name -> google.protobuf.Timestamp
+	11, // 18: bua.v1.CookiesRequest.set:type_name -> bua.v1.Cookie
+	11, // 19: bua.v1.CookiesResponse.cookies:type_name -> bua.v1.Cookie
+	0,  // 20: bua.v1.TabsRequest.action:type_name -> bua.v1.TabAction
+	16, // 21: bua.v1.TabsResponse.tabs:type_name -> bua.v1.TabInfo
+	1,  // 22: bua.v1.BrowserAutomation.Run:input_type -> bua.v1.RunRequest
+	1,  // 23: bua.v1.BrowserAutomation.Stream:input_type -> bua.v1.RunRequest
+	9,  // 24: bua.v1.BrowserAutomation.Screenshot:input_type -> bua.v1.ScreenshotRequest
+	12, // 25: bua.v1.BrowserAutomation.Cookies:input_type -> bua.v1.CookiesRequest
+	14, // 26: bua.v1.BrowserAutomation.Tabs:input_type -> bua.v1.TabsRequest
+	2,  // 27: bua.v1.BrowserAutomation.Run:output_type -> bua.v1.Result
+	8,  // 28: bua.v1.BrowserAutomation.Stream:output_type -> bua.v1.StreamEvent
+	10, // 29: bua.v1.BrowserAutomation.Screenshot:output_type -> bua.v1.ScreenshotResponse
+	13, // 30: bua.v1.BrowserAutomation.Cookies:output_type -> bua.v1.CookiesResponse
+	15, // 31: bua.v1.BrowserAutomation.Tabs:output_type -> bua.v1.TabsResponse
+	27, // [27:32] is the sub-list for method output_type
+	22, // [22:27] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_grpcapi_proto_bua_proto_init() }
+func file_grpcapi_proto_bua_proto_init() {
+	if File_grpcapi_proto_bua_proto != nil {
+		return
+	}
+	file_grpcapi_proto_bua_proto_msgTypes[7].OneofWrappers = []any{
+		(*StreamEvent_Step)(nil),
+		(*StreamEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grpcapi_proto_bua_proto_rawDesc), len(file_grpcapi_proto_bua_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grpcapi_proto_bua_proto_goTypes,
+		DependencyIndexes: file_grpcapi_proto_bua_proto_depIdxs,
+		EnumInfos:         file_grpcapi_proto_bua_proto_enumTypes,
+		MessageInfos:      file_grpcapi_proto_bua_proto_msgTypes,
+	}.Build()
+	File_grpcapi_proto_bua_proto = out.File
+	file_grpcapi_proto_bua_proto_goTypes = nil
+	file_grpcapi_proto_bua_proto_depIdxs = nil
+}
@@ -0,0 +1,167 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/anxuanzi/bua"
+)
+
+// toResult converts a bua.Result to its message.
+func toResult(r *bua.Result) (*Result, error) {
+	data, err := toValue(r.Data)
+	if err != nil {
+		return nil, err
+	}
+	out := &Result{
+		Success:          r.Success,
+		Data:             data,
+		Error:            r.Error,
+		ErrorCode:        string(r.ErrorCode),
+		Summary:          r.Summary,
+		Duration:         durationpb.New(r.Duration),
+		Usage:            toUsage(&r.Usage),
+		EstimatedCost:    r.EstimatedCost,
+		PartialResult:    r.PartialResult,
+		CompletionReason: string(r.CompletionReason),
+		ScreenshotPaths:  r.ScreenshotPaths,
+		RecordingPath:    r.RecordingPath,
+		DownloadPaths:    r.DownloadPaths,
+	}
+	for _, step := range r.Steps {
+		out.Steps = append(out.Steps, toStep(step))
+	}
+	for _, a := range r.Assertions {
+		out.Assertions = append(out.Assertions, &Assertion{
+			Type:        a.Type,
+			Description: a.Description,
+			Expected:    a.Expected,
+			Actual:      a.Actual,
+			Passed:      a.Passed,
+			Url:         a.URL,
+			Timestamp:   timestamppb.New(a.Timestamp),
+		})
+	}
+	for _, sec := range r.Sections {
+		data, err := toValue(sec.Data)
+		if err != nil {
+			return nil, err
+		}
+		out.Sections = append(out.Sections, &Section{
+			Objective: sec.Objective,
+			Status:    sec.Status,
+			Summary:   sec.Summary,
+			Data:      data,
+			Url:       sec.URL,
+			Timestamp: timestamppb.New(sec.Timestamp),
+		})
+	}
+	for _, f := range r.Findings {
+		data, err := toValue(f.Data)
+		if err != nil {
+			return nil, err
+		}
+		out.Findings = append(out.Findings, &Finding{
+			Data:      data.GetStructValue(),
+			Url:       f.URL,
+			Timestamp: timestamppb.New(f.Timestamp),
+			Saves:     int32(f.Saves),
+		})
+	}
+	return out, nil
+}
+
+// toStep converts a bua.Step to its message.
+func toStep(s bua.Step) *Step {
+	return &Step{
+		Number:              int32(s.Number),
+		Action:              s.Action,
+		Target:              s.Target,
+		Thinking:            s.Thinking,
+		Evaluation:          s.Evaluation,
+		NextGoal:            s.NextGoal,
+		Memory:              s.Memory,
+		Url:                 s.URL,
+		Title:               s.Title,
+		ScreenshotPath:      s.ScreenshotPath,
+		AfterScreenshotPath: s.AfterScreenshotPath,
+		Success:             s.Success,
+		Duration:            durationpb.New(s.Duration),
+		Error:               s.Error,
+		ErrorCode:           string(s.ErrorCode),
+		Usage:               toUsage(s.Usage),
+	}
+}
+
+// toUsage converts token counts to their message; nil stays nil.
+func toUsage(u *bua.TokenUsage) *TokenUsage {
+	if u == nil {
+		return nil
+	}
+	return &TokenUsage{
+		InputTokens:    int64(u.InputTokens),
+		OutputTokens:   int64(u.OutputTokens),
+		ThinkingTokens: int64(u.ThinkingTokens),
+		CachedTokens:   int64(u.CachedTokens),
+		TotalTokens:    int64(u.TotalTokens),
+	}
+}
+
+// toCookie converts a bua.Cookie to its message.
+func toCookie(c bua.Cookie) *Cookie {
+	out := &Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	}
+	if !c.Expires.IsZero() {
+		out.Expires = timestamppb.New(c.Expires)
+	}
+	return out
+}
+
+// fromCookie converts a Cookie message to a bua.Cookie.
+func fromCookie(c *Cookie) bua.Cookie {
+	out := bua.Cookie{
+		Name:     c.GetName(),
+		Value:    c.GetValue(),
+		Domain:   c.GetDomain(),
+		Path:     c.GetPath(),
+		HTTPOnly: c.GetHttpOnly(),
+		Secure:   c.GetSecure(),
+		SameSite: c.GetSameSite(),
+	}
+	if c.Expires != nil {
+		out.Expires = c.Expires.AsTime()
+	}
+	return out
+}
+
+// toValue converts any JSON-serializable value to a Value, or nil for nil.
+func toValue(v any) (*structpb.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode response: %v", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode response: %v", err)
+	}
+	out, err := structpb.NewValue(decoded)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode response: %v", err)
+	}
+	return out, nil
+}
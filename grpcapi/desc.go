@@ -0,0 +1,138 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+//go:generate protoc -I.. --go_out=.. --go_opt=module=github.com/anxuanzi/bua ../grpcapi/proto/bua.proto
+
+// ServiceDesc describes the BrowserAutomation service defined in proto/bua.proto.
+// It is written by hand so the module needs no gRPC code generator; the
+// messages in bua.pb.go are generated.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Run", Handler: unaryHandler("Run", (*Service).Run)},
+		{MethodName: "Screenshot", Handler: unaryHandler("Screenshot", (*Service).Screenshot)},
+		{MethodName: "Cookies", Handler: unaryHandler("Cookies", (*Service).Cookies)},
+		{MethodName: "Tabs", Handler: unaryHandler("Tabs", (*Service).Tabs)},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       streamHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpcapi/proto/bua.proto",
+}
+
+// unaryHandler adapts a Service method to a grpc.MethodHandler.
+func unaryHandler[In, Out any](name string, fn func(*Service, context.Context, *In) (*Out, error)) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(In)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		s := srv.(*Service)
+		if interceptor == nil {
+			return fn(s, ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: "/" + ServiceName + "/" + name,
+		}
+		return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+			return fn(s, ctx, req.(*In))
+		})
+	}
+}
+
+// streamHandler dispatches the server-streaming Stream RPC.
+func streamHandler(srv any, stream grpc.ServerStream) error {
+	in := new(RunRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(*Service).Stream(in, stream)
+}
+
+// ---- Client ----
+
+// Client is a Go client for the BrowserAutomation service.
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient creates a client on an existing connection.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// Run executes a task and returns the result.
+func (c *Client) Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Run", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Stream executes a task; call Recv on the returned stream until io.EOF.
+func (c *Client) Stream(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDesc.Streams[0], "/"+ServiceName+"/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &StreamClient{stream: stream}, nil
+}
+
+// StreamClient receives messages from a Stream call.
+type StreamClient struct {
+	stream grpc.ClientStream
+}
+
+// Recv returns the next step or result event.
+func (s *StreamClient) Recv() (*StreamEvent, error) {
+	out := new(StreamEvent)
+	if err := s.stream.RecvMsg(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Screenshot captures the current page as JPEG.
+func (c *Client) Screenshot(ctx context.Context, in *ScreenshotRequest, opts ...grpc.CallOption) (*ScreenshotResponse, error) {
+	out := new(ScreenshotResponse)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Screenshot", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Cookies returns browser cookies, optionally setting or clearing first.
+func (c *Client) Cookies(ctx context.Context, in *CookiesRequest, opts ...grpc.CallOption) (*CookiesResponse, error) {
+	out := new(CookiesResponse)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Cookies", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Tabs lists or manipulates browser tabs.
+func (c *Client) Tabs(ctx context.Context, in *TabsRequest, opts ...grpc.CallOption) (*TabsResponse, error) {
+	out := new(TabsResponse)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Tabs", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
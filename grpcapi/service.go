@@ -0,0 +1,259 @@
+// Package grpcapi exposes a bua agent over gRPC.
+//
+// The service definition lives in proto/bua.proto, and bua.pb.go holds its
+// generated messages. Clients in other languages generate stubs from the proto.
+//
+//	svc := grpcapi.New(bua.Config{APIKey: key, Headless: true})
+//	if err := svc.Start(ctx); err != nil { ... }
+//	defer svc.Close()
+//
+//	gs := grpc.NewServer()
+//	svc.Register(gs)
+//	gs.Serve(listener)
+package grpcapi
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/anxuanzi/bua"
+)
+
+// ServiceName is the fully-qualified gRPC service name.
+const ServiceName = "bua.v1.BrowserAutomation"
+
+// Service implements the BrowserAutomation gRPC service around a single agent.
+// Only one call runs at a time: while a task runs, every other call fails
+// with Unavailable, so Tabs or Cookies can't change the browser under it.
+type Service struct {
+	config bua.Config
+	agent  *bua.Agent
+
+	runMu sync.Mutex // Held for the duration of every call
+
+	stepMu   sync.Mutex
+	stepSink func(bua.Step) // Receives steps for the current Stream call
+}
+
+// New creates a service. The agent is created by Start.
+func New(cfg bua.Config) *Service {
+	return &Service{config: cfg}
+}
+
+// Start creates and starts the underlying agent.
+func (s *Service) Start(ctx context.Context) error {
+	cfg := s.config
+	userOnStep := cfg.OnStep
+	cfg.OnStep = func(step bua.Step) {
+		if userOnStep != nil {
+			userOnStep(step)
+		}
+		s.stepMu.Lock()
+		sink := s.stepSink
+		s.stepMu.Unlock()
+		if sink != nil {
+			sink(step)
+		}
+	}
+
+	agent, err := bua.New(cfg)
+	if err != nil {
+		return err
+	}
+	if err := agent.Start(ctx); err != nil {
+		return err
+	}
+	s.agent = agent
+	return nil
+}
+
+// Close shuts down the agent.
+func (s *Service) Close() error {
+	if s.agent == nil {
+		return nil
+	}
+	return s.agent.Close()
+}
+
+// Register adds the service to a gRPC server.
+func (s *Service) Register(gs grpc.ServiceRegistrar) {
+	gs.RegisterService(&ServiceDesc, s)
+}
+
+// Run executes a task and returns the result.
+func (s *Service) Run(ctx context.Context, in *RunRequest) (*Result, error) {
+	result, err := s.run(ctx, in, nil)
+	if err != nil {
+		return nil, err
+	}
+	return toResult(result)
+}
+
+// Stream executes a task, sending each completed step and then the result.
+func (s *Service) Stream(in *RunRequest, stream grpc.ServerStream) error {
+	var sendMu sync.Mutex
+	send := func(ev *StreamEvent) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.SendMsg(ev)
+	}
+
+	result, err := s.run(stream.Context(), in, func(step bua.Step) {
+		_ = send(&StreamEvent{Event: &StreamEvent_Step{Step: toStep(step)}})
+	})
+	if err != nil {
+		return err
+	}
+	out, err := toResult(result)
+	if err != nil {
+		return err
+	}
+	return send(&StreamEvent{Event: &StreamEvent_Result{Result: out}})
+}
+
+// run validates the request and executes it, holding the run lock.
+func (s *Service) run(ctx context.Context, in *RunRequest, onStep func(bua.Step)) (*bua.Result, error) {
+	if in.GetTask() == "" {
+		return nil, status.Error(codes.InvalidArgument, "task is required")
+	}
+	if in.Timeout != nil {
+		if err := in.Timeout.CheckValid(); err != nil || in.Timeout.AsDuration() <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timeout %v", in.Timeout.AsDuration())
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, in.Timeout.AsDuration())
+		defer cancel()
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	s.stepMu.Lock()
+	s.stepSink = onStep
+	s.stepMu.Unlock()
+	defer func() {
+		s.stepMu.Lock()
+		s.stepSink = nil
+		s.stepMu.Unlock()
+	}()
+
+	if in.GetUrl() != "" {
+		if err := s.agent.Navigate(ctx, in.GetUrl()); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to navigate: %v", err)
+		}
+	}
+
+	result, err := s.agent.Run(ctx, in.GetTask())
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return result, nil
+}
+
+// lock takes the run lock, failing with Unavailable while a task runs.
+// Every RPC holds it, so none can switch tabs or change cookies under a run.
+func (s *Service) lock() (unlock func(), err error) {
+	if !s.runMu.TryLock() {
+		return nil, status.Error(codes.Unavailable, "agent is busy with another task")
+	}
+	return s.runMu.Unlock, nil
+}
+
+// Screenshot captures the current page.
+func (s *Service) Screenshot(ctx context.Context, in *ScreenshotRequest) (*ScreenshotResponse, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := s.agent.Screenshot(ctx, in.GetFullPage())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "screenshot failed: %v", err)
+	}
+	return &ScreenshotResponse{Image: data}, nil
+}
+
+// Cookies returns all cookies, optionally clearing or setting some first.
+func (s *Service) Cookies(ctx context.Context, in *CookiesRequest) (*CookiesResponse, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if in.GetClear() {
+		if err := s.agent.ClearCookies(ctx); err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+	}
+	if len(in.GetSet()) > 0 {
+		set := make([]bua.Cookie, 0, len(in.GetSet()))
+		for _, c := range in.GetSet() {
+			set = append(set, fromCookie(c))
+		}
+		if err := s.agent.SetCookies(ctx, set); err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+	}
+
+	cookies, err := s.agent.Cookies(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	resp := &CookiesResponse{}
+	for _, c := range cookies {
+		resp.Cookies = append(resp.Cookies, toCookie(c))
+	}
+	return resp, nil
+}
+
+// Tabs lists, opens, switches, or closes tabs.
+func (s *Service) Tabs(ctx context.Context, in *TabsRequest) (*TabsResponse, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	resp := &TabsResponse{}
+	switch in.GetAction() {
+	case TabAction_TAB_ACTION_LIST:
+	case TabAction_TAB_ACTION_NEW:
+		id, err := s.agent.NewTab(ctx, in.GetUrl())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		resp.TabId = id
+	case TabAction_TAB_ACTION_SWITCH:
+		if err := s.agent.SwitchTab(in.GetTabId()); err != nil {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		resp.TabId = in.GetTabId()
+	case TabAction_TAB_ACTION_CLOSE:
+		if err := s.agent.CloseTab(in.GetTabId()); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown action %v", in.GetAction())
+	}
+
+	for _, t := range s.agent.ListTabs() {
+		resp.Tabs = append(resp.Tabs, &TabInfo{
+			Id:       t.ID,
+			Url:      t.URL,
+			Title:    t.Title,
+			Active:   t.Active,
+			Isolated: t.Isolated,
+			Label:    t.Label,
+		})
+	}
+	return resp, nil
+}
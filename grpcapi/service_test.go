@@ -0,0 +1,96 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/anxuanzi/bua"
+)
+
+func TestCallsRejectedDuringRun(t *testing.T) {
+	s := New(bua.Config{})
+	s.runMu.Lock() // A task is running
+	defer s.runMu.Unlock()
+
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"Run": func() error {
+			_, err := s.Run(ctx, &RunRequest{Task: "x"})
+			return err
+		},
+		"Screenshot": func() error {
+			_, err := s.Screenshot(ctx, &ScreenshotRequest{})
+			return err
+		},
+		"Cookies": func() error {
+			_, err := s.Cookies(ctx, &CookiesRequest{Clear: true})
+			return err
+		},
+		"Tabs": func() error {
+			_, err := s.Tabs(ctx, &TabsRequest{Action: TabAction_TAB_ACTION_CLOSE, TabId: "t1"})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if code := status.Code(call()); code != codes.Unavailable {
+			t.Errorf("%s: code = %v, want Unavailable", name, code)
+		}
+	}
+}
+
+func TestRunValidatesRequest(t *testing.T) {
+	s := New(bua.Config{})
+	if _, err := s.Run(context.Background(), &RunRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty task: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestToResult(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &bua.Result{
+		Success:          true,
+		Data:             map[string]any{"price": 9.5, "tags": []string{"a"}},
+		Steps:            []bua.Step{{Number: 1, Action: "click", Duration: time.Second, Usage: &bua.TokenUsage{InputTokens: 10}}},
+		Duration:         2 * time.Second,
+		CompletionReason: bua.CompletionDone,
+	}
+	out, err := toResult(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.GetData().GetStructValue().GetFields()["price"].GetNumberValue(); got != 9.5 {
+		t.Errorf("data.price = %v, want 9.5", got)
+	}
+	if len(out.GetSteps()) != 1 || out.GetSteps()[0].GetAction() != "click" || out.GetSteps()[0].GetUsage().GetInputTokens() != 10 {
+		t.Errorf("steps = %v", out.GetSteps())
+	}
+	if out.GetDuration().AsDuration() != 2*time.Second || out.GetCompletionReason() != "done" {
+		t.Errorf("duration/reason = %v/%q", out.GetDuration().AsDuration(), out.GetCompletionReason())
+	}
+
+	// Results survive the wire.
+	data, err := proto.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Result
+	if err := proto.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(out, &decoded) {
+		t.Error("result changed in a marshal round trip")
+	}
+
+	c := bua.Cookie{Name: "sid", Value: "1", Expires: expires, HTTPOnly: true}
+	if got := fromCookie(toCookie(c)); !got.Expires.Equal(expires) || got.Name != "sid" || !got.HTTPOnly {
+		t.Errorf("cookie round trip = %+v", got)
+	}
+	if toCookie(bua.Cookie{Name: "session"}).Expires != nil {
+		t.Error("session cookie got an expiry")
+	}
+}
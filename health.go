@@ -0,0 +1,145 @@
+package bua
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// geminiModelsURL is requested to check that the model API is reachable and
+// accepts the key.
+const geminiModelsURL = "https://generativelanguage.googleapis.com/v1beta/models?pageSize=1"
+
+// healthCheckTimeout bounds each check of Agent.Health.
+const healthCheckTimeout = 10 * time.Second
+
+// Health is the state of an agent, as reported by Agent.Health.
+type Health struct {
+	// Healthy is true if every check passed.
+	Healthy bool `json:"healthy"`
+
+	// Browser checks that the browser answers DevTools commands.
+	Browser HealthCheck `json:"browser"`
+
+	// ActiveTab checks that the active tab runs JavaScript, i.e. its page
+	// is not hung.
+	ActiveTab HealthCheck `json:"active_tab"`
+
+	// Model checks that the model API is reachable and accepts the key. It
+	// passes without a request when Config.Cassette replays.
+	Model HealthCheck `json:"model"`
+
+	// ProfileBytes is the disk space the browser profile takes, or 0 for
+	// remote browsers.
+	ProfileBytes int64 `json:"profile_bytes"`
+
+	// Busy is set while a task is running.
+	Busy bool `json:"busy"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthCheck is the outcome of one check of Agent.Health.
+type HealthCheck struct {
+	OK      bool          `json:"ok"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Health checks that the agent can still work: that its browser and active
+// tab respond and the model API is reachable. Orchestration layers can
+// poll it, e.g. from a liveness probe, and replace agents that are not
+// Healthy. It works while a task is running.
+func (a *Agent) Health(ctx context.Context) (*Health, error) {
+	a.mu.RLock()
+	started := a.started
+	b := a.browser
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	h := &Health{CheckedAt: time.Now(), Busy: len(a.runSlot) > 0}
+	h.Browser = healthCheck(ctx, func(ctx context.Context) error {
+		_, err := b.CallCDP(ctx, "Browser.getVersion", nil)
+		return err
+	})
+	h.ActiveTab = healthCheck(ctx, func(ctx context.Context) error {
+		page := b.ActivePage()
+		if page == nil {
+			return fmt.Errorf("no active page")
+		}
+		_, err := page.Context(ctx).Eval(`() => document.readyState`)
+		return err
+	})
+	h.Model = healthCheck(ctx, a.checkModel)
+	h.ProfileBytes = dirSize(b.ProfilePath())
+	h.Healthy = h.Browser.OK && h.ActiveTab.OK && h.Model.OK
+	return h, nil
+}
+
+// healthCheck runs check with a timeout, timing it.
+func healthCheck(ctx context.Context, check func(ctx context.Context) error) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	hc := HealthCheck{OK: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		hc.Error = err.Error()
+	}
+	return hc
+}
+
+// checkModel lists one model, to see that the API is reachable and accepts
+// the key.
+func (a *Agent) checkModel(ctx context.Context) error {
+	if c := a.config.Cassette; c != nil && c.Replaying() {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, geminiModelsURL, nil)
+	if err != nil {
+		return err
+	}
+	client := http.DefaultClient
+	if a.keys != nil {
+		client = a.keys.Client(nil)
+	} else {
+		req.Header.Set("x-goog-api-key", a.config.APIKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("model API unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("model API returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dirSize returns the total size of the files under dir, or 0 if dir is "".
+func dirSize(dir string) int64 {
+	if dir == "" {
+		return 0
+	}
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // files come and go while Chrome runs
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
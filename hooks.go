@@ -0,0 +1,86 @@
+package bua
+
+import (
+	"context"
+
+	"github.com/anxuanzi/bua/agent"
+)
+
+// Hooks are callbacks into each run, for hosts that persist progress, update
+// a UI or enforce their own policies. All are optional. They run on the
+// agent's goroutine, so they should return quickly; RunParallel sub-tasks
+// call the step hooks concurrently.
+type Hooks struct {
+	// OnStepStart is called before the model picks its next action, with the
+	// number the resulting Step will have.
+	OnStepStart func(step int)
+
+	// OnToolCall is called before the agent runs a tool with the arguments the
+	// model chose. Returning an error blocks the call: the step fails with the
+	// error's message and the model has to find another way.
+	OnToolCall func(ctx context.Context, name string, args map[string]any) error
+
+	// OnToolResult is called with each finished step, like Config.OnStep.
+	OnToolResult func(Step)
+
+	// OnScreenshot is called with every JPEG screenshot shown to the model,
+	// and the number of the step the model is choosing with it.
+	OnScreenshot func(step int, data []byte)
+
+	// OnProgress is called with an estimate of how far the run is, after each
+	// step and when a subtask of a plan starts, e.g. to show "Step 7/~15:
+	// extracting star count" instead of a spinner.
+	OnProgress func(Progress)
+
+	// OnDone is called with the result of every Run that finished, successful
+	// or not.
+	OnDone func(*Result)
+
+	// OnError is called when a Run fails with an error instead of a Result.
+	OnError func(error)
+}
+
+// Progress is a rough account of how far a run is: steps taken and
+// expected, percent done, the current goal and the latest actions.
+type Progress = agent.Progress
+
+// ToolCall is a tool invocation as chosen by the model.
+type ToolCall = agent.ToolCall
+
+// ToolHandler runs a tool call and returns the result the model sees.
+type ToolHandler = agent.ToolHandler
+
+// ToolMiddleware wraps the handler of every tool, like HTTP middleware, for
+// auditing, rate limiting, argument redaction or policy enforcement.
+type ToolMiddleware = agent.ToolMiddleware
+
+// RetryPolicy retries transient failures of a single model or tool call, with
+// exponential backoff, so they don't fail the task.
+type RetryPolicy = agent.RetryPolicy
+
+// agentHooks returns the hooks the internal agent calls during a run. The
+// run's outcome is reported by Run itself, once its Result is complete.
+func (h Hooks) agentHooks() agent.Hooks {
+	hooks := agent.Hooks{
+		OnStepStart:  h.OnStepStart,
+		OnToolCall:   h.OnToolCall,
+		OnScreenshot: h.OnScreenshot,
+		OnProgress:   h.OnProgress,
+	}
+	if onToolResult := h.OnToolResult; onToolResult != nil {
+		hooks.OnToolResult = func(s agent.Step) {
+			onToolResult(convertStep(s))
+		}
+	}
+	return hooks
+}
+
+// finish reports the outcome of a Run.
+func (h Hooks) finish(result *Result, err error) {
+	switch {
+	case err != nil && h.OnError != nil:
+		h.OnError(err)
+	case err == nil && h.OnDone != nil:
+		h.OnDone(result)
+	}
+}
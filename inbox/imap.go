@@ -0,0 +1,305 @@
+package inbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IMAP is an Inbox that reads a mailbox over IMAP with TLS. Messages are
+// fetched with BODY.PEEK, so they are not marked as read.
+type IMAP struct {
+	// Addr is the server host:port, e.g. "imap.gmail.com:993".
+	Addr string
+
+	// Username and Password log in to the mailbox. Use an app password where
+	// the provider requires one.
+	Username string
+	Password string
+
+	// Mailbox to read. Default: "INBOX".
+	Mailbox string
+
+	// MaxMessages bounds how many recent messages are fetched per check. Default: 10.
+	MaxMessages int
+}
+
+// Messages returns messages dated at or after since, oldest first.
+func (c *IMAP) Messages(ctx context.Context, since time.Time) ([]Message, error) {
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 15 * time.Second}}
+	conn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	s := &imapSession{r: bufio.NewReader(conn), w: conn}
+	if _, err := s.r.ReadString('\n'); err != nil {
+		return nil, fmt.Errorf("failed to read IMAP greeting: %w", err)
+	}
+
+	if _, err := s.command("LOGIN %s %s", imapQuote(c.Username), imapQuote(c.Password)); err != nil {
+		return nil, fmt.Errorf("IMAP login failed: %w", err)
+	}
+	defer s.command("LOGOUT")
+
+	mailbox := c.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	if _, err := s.command("EXAMINE %s", imapQuote(mailbox)); err != nil {
+		return nil, fmt.Errorf("failed to open mailbox %s: %w", mailbox, err)
+	}
+
+	// SEARCH SINCE has day granularity; exact times are filtered below
+	resp, err := s.command("UID SEARCH SINCE %s", since.Add(-24*time.Hour).Format("2-Jan-2006"))
+	if err != nil {
+		return nil, fmt.Errorf("IMAP search failed: %w", err)
+	}
+	uids := parseSearch(resp)
+
+	limit := c.MaxMessages
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(uids) > limit {
+		uids = uids[len(uids)-limit:]
+	}
+
+	var messages []Message
+	for _, uid := range uids {
+		resp, err := s.command("UID FETCH %d BODY.PEEK[]", uid)
+		if err != nil {
+			return nil, fmt.Errorf("IMAP fetch failed: %w", err)
+		}
+		for _, r := range resp {
+			if r.literal == nil {
+				continue
+			}
+			msg, err := parseMessage(r.literal)
+			if err != nil || msg.Received.Before(since) {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// imapResponse is one untagged response line and its literal, if any.
+type imapResponse struct {
+	line    string
+	literal []byte
+}
+
+// imapSession runs tagged commands on an open connection.
+type imapSession struct {
+	r   *bufio.Reader
+	w   io.Writer
+	tag int
+}
+
+// literalSuffix matches the "{123}" that announces a literal of 123 bytes.
+var literalSuffix = regexp.MustCompile(`\{(\d+)\}$`)
+
+// command sends a command and collects untagged responses until its tagged
+// completion, returning an error unless the status is OK.
+func (s *imapSession) command(format string, args ...any) ([]imapResponse, error) {
+	s.tag++
+	tag := fmt.Sprintf("a%d", s.tag)
+	if _, err := fmt.Fprintf(s.w, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var responses []imapResponse
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if rest, ok := strings.CutPrefix(line, tag+" "); ok {
+			if !strings.HasPrefix(rest, "OK") {
+				return nil, errors.New(rest)
+			}
+			return responses, nil
+		}
+
+		resp := imapResponse{line: line}
+		if m := literalSuffix.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			resp.literal = make([]byte, n)
+			if _, err := io.ReadFull(s.r, resp.literal); err != nil {
+				return nil, err
+			}
+			// The rest of the response line (usually ")") follows the literal
+			if _, err := s.r.ReadString('\n'); err != nil {
+				return nil, err
+			}
+		}
+		responses = append(responses, resp)
+	}
+}
+
+// imapQuote returns s as an IMAP quoted string.
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseSearch extracts the UIDs from "* SEARCH 1 2 3" responses.
+func parseSearch(responses []imapResponse) []int {
+	var uids []int
+	for _, r := range responses {
+		rest, ok := strings.CutPrefix(r.line, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Fields(rest) {
+			if uid, err := strconv.Atoi(field); err == nil {
+				uids = append(uids, uid)
+			}
+		}
+	}
+	return uids
+}
+
+// parseMessage decodes a raw RFC 5322 message.
+func parseMessage(raw []byte) (Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Message{}, err
+	}
+
+	dec := new(mime.WordDecoder)
+	header := func(name string) string {
+		v := m.Header.Get(name)
+		if decoded, err := dec.DecodeHeader(v); err == nil {
+			return decoded
+		}
+		return v
+	}
+
+	received, err := m.Header.Date()
+	if err != nil {
+		received = time.Now()
+	}
+
+	text, err := bodyText(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		From:     header("From"),
+		To:       header("To"),
+		Subject:  header("Subject"),
+		Text:     text,
+		Received: received,
+	}, nil
+}
+
+// bodyText returns the readable text of a body, preferring text/plain parts
+// of multipart messages and stripping tags from HTML.
+func bodyText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	switch strings.ToLower(encoding) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var plain, htmlText string
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			text, err := bodyText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil || text == "" {
+				continue
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if partType == "text/html" {
+				if htmlText == "" {
+					htmlText = text
+				}
+			} else if plain == "" {
+				plain = text
+			}
+		}
+		if plain != "" {
+			return plain, nil
+		}
+		return htmlText, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxWebhookBody))
+	if err != nil {
+		return "", err
+	}
+	if mediaType == "text/html" {
+		return stripHTML(string(data)), nil
+	}
+	return string(data), nil
+}
+
+// newlineStripper drops line breaks so wrapped base64 decodes.
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+var (
+	htmlHidden = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlTag    = regexp.MustCompile(`(?s)<[^>]+>`)
+	whitespace = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// stripHTML reduces an HTML body to its visible text.
+func stripHTML(s string) string {
+	s = htmlHidden.ReplaceAllString(s, " ")
+	s = htmlTag.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(whitespace.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,103 @@
+// Package inbox reads one-time codes sent by email, so signup and login flows
+// that mail a verification code can be completed without a person.
+//
+// Two sources are provided: IMAP polls a mailbox, and Webhook receives mail
+// forwarded by an inbound-mail service (Mailgun, SendGrid, Postmark, ...).
+package inbox
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Message is an email received by an inbox.
+type Message struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Text     string    `json:"text"`
+	Received time.Time `json:"received"`
+}
+
+// Inbox is a source of received email.
+type Inbox interface {
+	// Messages returns messages received at or after since, oldest first.
+	Messages(ctx context.Context, since time.Time) ([]Message, error)
+}
+
+// Filter selects messages by case-insensitive substring matches.
+// Empty fields match everything.
+type Filter struct {
+	From    string
+	To      string
+	Subject string
+}
+
+// Match reports whether m passes the filter.
+func (f Filter) Match(m Message) bool {
+	return containsFold(m.From, f.From) && containsFold(m.To, f.To) && containsFold(m.Subject, f.Subject)
+}
+
+// containsFold reports whether substr is in s, ignoring case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+var (
+	// codeNearKeyword finds a code right after words like "code" or "OTP".
+	codeNearKeyword = regexp.MustCompile(`(?i)(?:code|otp|pin|passcode|verification|verify|token)[^0-9A-Za-z]{0,40}?\b([0-9]{4,8}|[A-Z0-9]{6,8})\b`)
+
+	// digitCode finds a standalone 4-8 digit number.
+	digitCode = regexp.MustCompile(`\b[0-9]{4,8}\b`)
+
+	// yearLike excludes numbers that are probably years in footers.
+	yearLike = regexp.MustCompile(`^(19|20)[0-9]{2}$`)
+)
+
+// ExtractCode returns the most likely one-time code in text, or "" if none.
+// Codes next to words like "code" or "verification" win over other numbers.
+func ExtractCode(text string) string {
+	for _, m := range codeNearKeyword.FindAllStringSubmatch(text, -1) {
+		if code := m[1]; strings.ContainsAny(code, "0123456789") && !yearLike.MatchString(code) {
+			return code
+		}
+	}
+	for _, code := range digitCode.FindAllString(text, -1) {
+		if !yearLike.MatchString(code) {
+			return code
+		}
+	}
+	return ""
+}
+
+// WaitForCode polls in until a message matching f with a code arrives after
+// since, returning the newest such message and its code.
+func WaitForCode(ctx context.Context, in Inbox, since time.Time, f Filter, interval time.Duration) (Message, string, error) {
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+
+	for {
+		messages, err := in.Messages(ctx, since)
+		if err != nil {
+			return Message{}, "", err
+		}
+		for i := len(messages) - 1; i >= 0; i-- {
+			m := messages[i]
+			if !f.Match(m) {
+				continue
+			}
+			if code := ExtractCode(m.Subject + "\n" + m.Text); code != "" {
+				return m, code, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return Message{}, "", ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package inbox
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxWebhookBody caps the size of a forwarded email.
+const maxWebhookBody = 10 << 20
+
+// Webhook is an Inbox fed by HTTP POSTs from an inbound-mail service. Mount it
+// on a reachable URL and point the service's forwarding rule at it.
+//
+// It accepts JSON ({"from", "to", "subject", "text", "html"}) and the form
+// fields used by Mailgun ("sender", "recipient", "body-plain") and SendGrid
+// ("from", "to", "text", "html"). Messages are kept in memory.
+type Webhook struct {
+	// Token, if set, must be sent as a Bearer token or a "token" query parameter.
+	Token string
+
+	// MaxMessages bounds how many messages are kept. Default: 100.
+	MaxMessages int
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewWebhook returns an empty webhook inbox.
+func NewWebhook(token string) *Webhook {
+	return &Webhook{Token: token}
+}
+
+// ServeHTTP stores a forwarded email.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if w.Token != "" && !w.authorized(r) {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, maxWebhookBody)
+	msg, err := parseWebhook(r)
+	if err != nil {
+		http.Error(rw, "invalid message: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	msg.Received = time.Now()
+	w.Add(msg)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// Add stores a message, e.g. one received through another channel.
+func (w *Webhook) Add(msg Message) {
+	limit := w.MaxMessages
+	if limit <= 0 {
+		limit = 100
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msg)
+	if len(w.messages) > limit {
+		w.messages = w.messages[len(w.messages)-limit:]
+	}
+}
+
+// Messages returns stored messages received at or after since.
+func (w *Webhook) Messages(ctx context.Context, since time.Time) ([]Message, error) {
+	_ = ctx // Context available for future use
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []Message
+	for _, m := range w.messages {
+		if !m.Received.Before(since) {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// authorized checks the request's token in constant time.
+func (w *Webhook) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		token = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(w.Token)) == 1
+}
+
+// parseWebhook reads a message from a JSON or form-encoded request.
+func parseWebhook(r *http.Request) (Message, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var body struct {
+			From    string `json:"from"`
+			To      string `json:"to"`
+			Subject string `json:"subject"`
+			Text    string `json:"text"`
+			HTML    string `json:"html"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return Message{}, err
+		}
+		return Message{From: body.From, To: body.To, Subject: body.Subject, Text: textOrHTML(body.Text, body.HTML)}, nil
+	}
+
+	if err := r.ParseMultipartForm(maxWebhookBody); err != nil && err != http.ErrNotMultipart {
+		return Message{}, err
+	}
+	field := func(names ...string) string {
+		for _, name := range names {
+			if v := r.FormValue(name); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	return Message{
+		From:    field("from", "sender"),
+		To:      field("to", "recipient"),
+		Subject: field("subject"),
+		Text:    textOrHTML(field("text", "body-plain", "stripped-text"), field("html", "body-html")),
+	}, nil
+}
+
+// textOrHTML prefers the plain text body and falls back to stripped HTML.
+func textOrHTML(text, html string) string {
+	if text != "" {
+		return text
+	}
+	return stripHTML(html)
+}
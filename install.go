@@ -0,0 +1,23 @@
+package bua
+
+import (
+	"context"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// BrowserInstallProgress reports the state of a Chromium download.
+type BrowserInstallProgress = browser.InstallProgress
+
+// InstallBrowser downloads the Chromium revision pinned by cfg.BrowserRevision
+// (or the default revision) into cfg.BrowserDir and returns the executable path.
+// Run it as an explicit deployment step, e.g. while building an image, so the
+// first Start doesn't download anything. Already-installed revisions are reused.
+func InstallBrowser(ctx context.Context, cfg Config) (string, error) {
+	return browser.Install(ctx, browser.InstallOptions{
+		Revision:   cfg.BrowserRevision,
+		Dir:        cfg.BrowserDir,
+		OnProgress: cfg.OnBrowserInstall,
+		Debug:      cfg.Debug,
+	})
+}
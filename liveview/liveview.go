@@ -0,0 +1,335 @@
+// Package liveview serves a local web page for watching a browser agent in real time.
+//
+// The server streams the browser screencast as MJPEG and pushes the agent's
+// step log over Server-Sent Events, so headless runs can be observed from any
+// browser tab:
+//
+//	GET /         viewer page (video + step log)
+//	GET /stream   multipart/x-mixed-replace MJPEG stream
+//	GET /events   text/event-stream of published events
+//	GET /log      JSON array of all events published so far
+package liveview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua/browser"
+	"github.com/anxuanzi/bua/logging"
+)
+
+// maxLogEvents caps the number of events kept for late-joining viewers.
+const maxLogEvents = 500
+
+// Event is a single entry in the live step log.
+type Event struct {
+	// Type identifies the event (e.g., "run_started", "step", "run_finished").
+	Type string `json:"type"`
+
+	// Data is the JSON-serializable event payload.
+	Data any `json:"data,omitempty"`
+
+	// Timestamp is when the event was published.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Server streams screencast frames and agent events over HTTP.
+type Server struct {
+	// Logger receives server errors (nil = console output). Set it before
+	// Start.
+	Logger *slog.Logger
+
+	addr     string
+	server   *http.Server
+	listener net.Listener
+
+	mu       sync.Mutex
+	browser  *browser.Browser // Source of the screencast; see SetBrowser
+	switched chan struct{}    // Closed when browser changes
+	log      []Event
+	subs     map[int]chan Event
+	nextID   int
+}
+
+// New creates a live view server for the given browser.
+// addr is a TCP listen address such as "127.0.0.1:8765".
+func New(b *browser.Browser, addr string) *Server {
+	s := &Server{
+		browser:  b,
+		switched: make(chan struct{}),
+		addr:     addr,
+		subs:     make(map[int]chan Event),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /stream", s.handleStream)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("GET /log", s.handleLog)
+	s.server = &http.Server{Handler: mux}
+
+	return s
+}
+
+// Start begins listening in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.listener = ln
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Component(logging.Or(s.Logger, false), "LiveView").Error("Server failed", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// URL returns the viewer URL. Empty if the server is not started.
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String() + "/"
+}
+
+// Close stops the server and disconnects all viewers.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for id, ch := range s.subs {
+		close(ch)
+		delete(s.subs, id)
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// SetBrowser switches the screencast to b, e.g. a run's incognito view, and
+// moves connected viewers over to it.
+func (s *Server) SetBrowser(b *browser.Browser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b == s.browser {
+		return
+	}
+	s.browser = b
+	close(s.switched)
+	s.switched = make(chan struct{})
+}
+
+// Publish appends an event to the step log and pushes it to connected viewers.
+func (s *Server) Publish(eventType string, data any) {
+	ev := Event{Type: eventType, Data: data, Timestamp: time.Now()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log = append(s.log, ev)
+	if len(s.log) > maxLogEvents {
+		s.log = s.log[len(s.log)-maxLogEvents:]
+	}
+
+	for _, ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Viewer is behind; it can recover from /log
+		}
+	}
+}
+
+// subscribe registers a viewer and returns the backlog plus a live channel.
+func (s *Server) subscribe() ([]Event, <-chan Event, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	ch := make(chan Event, 64)
+	s.subs[id] = ch
+
+	backlog := make([]Event, len(s.log))
+	copy(backlog, s.log)
+
+	return backlog, ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[id]; ok {
+			delete(s.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// ---- Handlers ----
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(indexHTML))
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	const boundary = "buaframe"
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+boundary)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// Stream from the current browser until SetBrowser replaces it
+	for {
+		s.mu.Lock()
+		b, switched := s.browser, s.switched
+		s.mu.Unlock()
+
+		if !s.streamFrames(w, r, flusher, boundary, b, switched) {
+			return
+		}
+	}
+}
+
+// streamFrames writes b's frames to w until the viewer leaves, b's
+// screencast ends, or switched is closed. It reports whether to continue
+// with the next browser.
+func (s *Server) streamFrames(w http.ResponseWriter, r *http.Request, flusher http.Flusher, boundary string, b *browser.Browser, switched <-chan struct{}) bool {
+	frames, unsubscribe := b.SubscribeFrames()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return false
+		case <-switched:
+			return true
+		case frame, ok := <-frames:
+			if !ok {
+				return false
+			}
+			if _, err := fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", boundary, len(frame.Data)); err != nil {
+				return false
+			}
+			if _, err := w.Write(frame.Data); err != nil {
+				return false
+			}
+			if _, err := w.Write([]byte("\r\n")); err != nil {
+				return false
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	backlog, events, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	write := func(ev Event) error {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return nil // Skip unserializable payloads
+		}
+		_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		return err
+	}
+
+	for _, ev := range backlog {
+		if err := write(ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := write(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	events := make([]Event, len(s.log))
+	copy(events, s.log)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(events)
+}
+
+// indexHTML is the viewer page. It renders the MJPEG stream and appends
+// every SSE event to the step log.
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>bua live view</title>
+<style>
+  body { margin: 0; display: flex; height: 100vh; font-family: -apple-system, sans-serif; background: #111; color: #eee; }
+  #view { flex: 3; display: flex; align-items: center; justify-content: center; background: #000; }
+  #view img { max-width: 100%; max-height: 100%; }
+  #log { flex: 1; min-width: 320px; overflow-y: auto; padding: 12px; font-size: 13px; border-left: 1px solid #333; }
+  .ev { padding: 6px 8px; margin-bottom: 6px; border-radius: 4px; background: #1c1c1c; }
+  .ev .type { font-weight: 600; color: #8ab4f8; }
+  .ev.fail .type { color: #f28b82; }
+  .ev pre { margin: 4px 0 0; white-space: pre-wrap; word-break: break-all; color: #aaa; }
+</style>
+</head>
+<body>
+<div id="view"><img src="/stream" alt="waiting for frames..."></div>
+<div id="log"></div>
+<script>
+  const log = document.getElementById('log');
+  function add(ev) {
+    const div = document.createElement('div');
+    div.className = 'ev' + (ev.data && ev.data.success === false ? ' fail' : '');
+    let title = ev.type;
+    if (ev.type === 'step' && ev.data) title = '#' + ev.data.number + ' ' + ev.data.action;
+    div.innerHTML = '<span class="type"></span><pre></pre>';
+    div.querySelector('.type').textContent = title;
+    const d = ev.data || {};
+    div.querySelector('pre').textContent = ev.type === 'step' ? (d.target || '') + (d.error ? '\n' + d.error : '') : JSON.stringify(d);
+    log.appendChild(div);
+    log.scrollTop = log.scrollHeight;
+  }
+  new EventSource('/events').onmessage = e => add(JSON.parse(e.data));
+</script>
+</body>
+</html>
+`
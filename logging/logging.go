@@ -0,0 +1,158 @@
+// Package logging provides bua's log/slog setup: a console handler that
+// prints the familiar "[Component] message" lines, and the defaults the
+// other packages fall back to when no logger is injected.
+//
+// Every bua logger tags its records with a component attribute ("Browser",
+// "Agent", "Planner", ...). Services that want structured logs inject their
+// own logger instead:
+//
+//	cfg := bua.Config{
+//		Logger: slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})),
+//	}
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ComponentKey is the attribute naming the part of bua that logged a record.
+const ComponentKey = "component"
+
+// Default returns the logger used when none is injected: console output of
+// everything when debug is set, and of errors only otherwise.
+func Default(debug bool) *slog.Logger {
+	level := slog.LevelError
+	if debug {
+		level = slog.LevelDebug
+	}
+	return slog.New(NewConsoleHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+}
+
+// Or returns logger, or Default(debug) if logger is nil.
+func Or(logger *slog.Logger, debug bool) *slog.Logger {
+	if logger != nil {
+		return logger
+	}
+	return Default(debug)
+}
+
+// Component returns logger tagged with a component.
+func Component(logger *slog.Logger, name string) *slog.Logger {
+	return logger.With(ComponentKey, name)
+}
+
+// ConsoleHandler writes records as human-readable lines:
+//
+//	[Browser] Attaching to ws://127.0.0.1:9222/devtools/browser/...
+//	[Download] Finished report.pdf err="connection reset"
+//
+// The component, if any, becomes the bracketed prefix; other attributes
+// follow the message as key=value pairs. Warnings and errors are marked.
+type ConsoleHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Leveler
+
+	component string
+	attrs     string // preformatted attributes added with WithAttrs
+	groups    []string
+}
+
+// NewConsoleHandler returns a ConsoleHandler writing to w. Only
+// opts.Level is used; nil opts log at Info and above.
+func NewConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *ConsoleHandler {
+	h := &ConsoleHandler{w: w, mu: &sync.Mutex{}, level: slog.LevelInfo}
+	if opts != nil && opts.Level != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	if h.component != "" {
+		sb.WriteString("[" + h.component + "] ")
+	}
+	switch {
+	case r.Level >= slog.LevelError:
+		sb.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		sb.WriteString("Warning: ")
+	}
+	sb.WriteString(r.Message)
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&sb, h.groups, a)
+		return true
+	})
+	sb.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	var sb strings.Builder
+	for _, a := range attrs {
+		if a.Key == ComponentKey && len(h.groups) == 0 {
+			h2.component = a.Value.String()
+			continue
+		}
+		h.appendAttr(&sb, h.groups, a)
+	}
+	h2.attrs += sb.String()
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
+
+// appendAttr writes " key=value", qualifying the key with its groups.
+func (h *ConsoleHandler) appendAttr(sb *strings.Builder, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(sb, groups, ga)
+		}
+		return
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	value := a.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") || value == "" {
+		value = fmt.Sprintf("%q", value)
+	}
+	sb.WriteString(" " + key + "=" + value)
+}
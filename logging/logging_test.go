@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewConsoleHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	Component(logger, "Download").Warn("Finished report.pdf", "err", "connection reset", "bytes", 0)
+	logger.WithGroup("req").With("id", 7).Info("Sent", slog.Group("opts", "retry", true), "path", "")
+	Component(logger, "Browser").Debug("Attaching")
+
+	want := `[Download] Warning: Finished report.pdf err="connection reset" bytes=0
+Sent req.id=7 req.opts.retry=true req.path=""
+[Browser] Attaching
+`
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConsoleHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewConsoleHandler(&buf, nil))
+
+	logger.Debug("hidden")
+	logger.Error("Launch failed")
+
+	if got, want := buf.String(), "Error: Launch failed\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestOr(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	if Or(logger, true) != logger {
+		t.Error("Or() replaced an injected logger")
+	}
+	if Or(nil, false).Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Or(nil, false) logs warnings; want errors only")
+	}
+	if !Or(nil, true).Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Or(nil, true) drops debug logs")
+	}
+}
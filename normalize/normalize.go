@@ -0,0 +1,299 @@
+// Package normalize cleans values scraped from web pages into what
+// downstream systems expect: "45.2k" becomes 45200, "3 hours ago" a
+// timestamp, and "$1,299.00" an amount and a currency code.
+//
+// Data applies them to the fields of extracted data that a JSON Schema types
+// as numbers, dates or amounts, which is what RunOptions.Normalize does with
+// the data of a run.
+package normalize
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Money is an amount in a currency.
+type Money struct {
+	Amount float64 `json:"amount"`
+
+	// Currency is the ISO 4217 code, or "" if the string did not say.
+	Currency string `json:"currency,omitempty"`
+}
+
+// numberPattern finds the first number in a string, with its thousands and
+// decimal separators and an optional magnitude suffix.
+var numberPattern = regexp.MustCompile(`(?i)([-+]?)(\d[\d,.' ]*\d|\d)\s*(thousand|million|billion|trillion|bn|k|m|b|t)?\b`)
+
+// magnitudes are the values of number suffixes.
+var magnitudes = map[string]float64{
+	"k": 1e3, "thousand": 1e3,
+	"m": 1e6, "million": 1e6,
+	"b": 1e9, "bn": 1e9, "billion": 1e9,
+	"t": 1e12, "trillion": 1e12,
+}
+
+// Number parses the first number in s, as people write them: "1,234",
+// "1.234,5", "45.2k", "3.1M views", "12%". It reports false if s holds none.
+func Number(s string) (float64, bool) {
+	m := numberPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	digits := separators(m[2])
+	v, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return 0, false
+	}
+	if mult, ok := magnitudes[strings.ToLower(m[3])]; ok {
+		v *= mult
+	}
+	if m[1] == "-" {
+		v = -v
+	}
+	// Drop the float noise of scaling, e.g. 45.2*1000 = 45200.000000000004
+	return math.Round(v*1e6) / 1e6, true
+}
+
+// separators rewrites digits with thousands and decimal separators as a Go
+// float: the last of '.' and ',' is the decimal separator, unless it is
+// followed by exactly three digits and is the only one of its kind.
+func separators(digits string) string {
+	digits = strings.NewReplacer(" ", "", "'", "").Replace(digits)
+	dot, comma := strings.LastIndex(digits, "."), strings.LastIndex(digits, ",")
+	decimal := byte(0)
+	switch {
+	case dot >= 0 && comma >= 0:
+		decimal = digits[max(dot, comma)]
+	case dot >= 0:
+		if strings.Count(digits, ".") == 1 {
+			decimal = '.'
+		}
+	case comma >= 0:
+		if strings.Count(digits, ",") == 1 && len(digits)-comma-1 != 3 {
+			decimal = ','
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(digits); i++ {
+		switch c := digits[i]; {
+		case c == decimal && i == strings.LastIndexByte(digits, decimal):
+			sb.WriteByte('.')
+		case c >= '0' && c <= '9':
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// currencySymbols maps currency symbols and prefixes to ISO 4217 codes;
+// longer prefixes come first so "US$" is not read as "$".
+var currencySymbols = []struct{ symbol, code string }{
+	{"US$", "USD"}, {"C$", "CAD"}, {"CA$", "CAD"}, {"A$", "AUD"}, {"AU$", "AUD"},
+	{"NZ$", "NZD"}, {"HK$", "HKD"}, {"S$", "SGD"}, {"R$", "BRL"}, {"MX$", "MXN"},
+	{"$", "USD"}, {"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₹", "INR"},
+	{"₩", "KRW"}, {"₽", "RUB"}, {"₺", "TRY"}, {"₫", "VND"}, {"₪", "ILS"},
+	{"₱", "PHP"}, {"฿", "THB"}, {"zł", "PLN"}, {"kr", "SEK"}, {"CHF", "CHF"},
+}
+
+// currencyCode finds an ISO 4217 code written out, e.g. "1,299 EUR".
+var currencyCode = regexp.MustCompile(`\b(USD|EUR|GBP|JPY|CNY|RMB|INR|CAD|AUD|NZD|CHF|SEK|NOK|DKK|PLN|CZK|HUF|BRL|MXN|KRW|SGD|HKD|TRY|ZAR|RUB|ILS|THB|VND|PHP|IDR|MYR|TWD|AED|SAR)\b`)
+
+// ParseMoney parses a price such as "$1,299.00", "1.299,00 €" or "EUR 12".
+// A written-out code wins over a symbol, since "$" alone is ambiguous. It
+// reports false if s holds no amount.
+func ParseMoney(s string) (Money, bool) {
+	amount, ok := Number(s)
+	if !ok {
+		return Money{}, false
+	}
+	m := Money{Amount: amount}
+	if code := currencyCode.FindString(strings.ToUpper(s)); code != "" {
+		if code == "RMB" {
+			code = "CNY"
+		}
+		m.Currency = code
+		return m, true
+	}
+	for _, c := range currencySymbols {
+		if strings.Contains(s, c.symbol) {
+			m.Currency = c.code
+			break
+		}
+	}
+	return m, true
+}
+
+// timeLayouts are the absolute date formats Time understands.
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123,
+	time.RFC1123Z,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Mon, Jan 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2 2006",
+	"January 2006",
+}
+
+// relativeTime matches "3 hours ago", "in 2 days", "a minute ago" and their
+// short forms, e.g. "5m ago" or "2d".
+var relativeTime = regexp.MustCompile(`(?i)^(in\s+)?(an?|\d+)\s*(seconds?|secs?|s|minutes?|mins?|m|hours?|hrs?|h|days?|d|weeks?|wks?|w|months?|mos?|years?|yrs?|y)\b\s*(ago)?$`)
+
+// Time parses an absolute or relative date, e.g. "2024-03-01", "Mar 1,
+// 2024", "3 hours ago", "yesterday" or "2d", relative to now. Dates without
+// a zone are in now's. It reports false if it cannot read s.
+func Time(s string, now time.Time) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true
+		}
+	}
+
+	lower := strings.ToLower(strings.TrimSuffix(s, "."))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch lower {
+	case "now", "just now", "moments ago", "a moment ago":
+		return now, true
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	m := relativeTime.FindStringSubmatch(lower)
+	if m == nil {
+		return time.Time{}, false
+	}
+	n := 1
+	if m[2] != "a" && m[2] != "an" {
+		n, _ = strconv.Atoi(m[2])
+	}
+	if m[1] == "" {
+		n = -n // "3 hours ago", or "3h" as feeds write it
+	}
+	switch unit := m[3]; {
+	case unit == "m" || strings.HasPrefix(unit, "min"):
+		return now.Add(time.Duration(n) * time.Minute), true
+	case strings.HasPrefix(unit, "s"):
+		return now.Add(time.Duration(n) * time.Second), true
+	case strings.HasPrefix(unit, "h"):
+		return now.Add(time.Duration(n) * time.Hour), true
+	case strings.HasPrefix(unit, "d"):
+		return now.AddDate(0, 0, n), true
+	case strings.HasPrefix(unit, "w"):
+		return now.AddDate(0, 0, 7*n), true
+	case strings.HasPrefix(unit, "mo"):
+		return now.AddDate(0, n, 0), true
+	default: // years
+		return now.AddDate(n, 0, 0), true
+	}
+}
+
+// Data normalizes data, as decoded from JSON, where schema types it:
+//
+//   - strings in "number" or "integer" fields become numbers, via Number
+//   - strings in "string" fields of format "date-time" or "date" become
+//     RFC 3339 timestamps or dates, via Time
+//   - strings in "object" fields with "amount" and "currency" properties
+//     become {"amount": ..., "currency": ...}, via ParseMoney
+//
+// Values that cannot be read are left alone, for validation to report.
+// data itself is not modified.
+func Data(data any, schema map[string]any, now time.Time) any {
+	switch v := data.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		out := make(map[string]any, len(v))
+		for key, val := range v {
+			if prop, ok := props[key].(map[string]any); ok {
+				val = Data(val, prop, now)
+			}
+			out[key] = val
+		}
+		return out
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = Data(val, items, now)
+		}
+		return out
+	case string:
+		return normalizeString(v, schema, now)
+	}
+	return data
+}
+
+// normalizeString normalizes a string value of a field with schema.
+func normalizeString(s string, schema map[string]any, now time.Time) any {
+	types := schemaTypes(schema)
+	switch {
+	case types["string"]:
+		format, _ := schema["format"].(string)
+		if format != "date-time" && format != "date" {
+			return s
+		}
+		t, ok := Time(s, now)
+		if !ok {
+			return s
+		}
+		if format == "date" {
+			return t.Format(time.DateOnly)
+		}
+		return t.Format(time.RFC3339)
+	case types["integer"]:
+		if v, ok := Number(s); ok && v == math.Trunc(v) {
+			return v
+		}
+	case types["number"]:
+		if v, ok := Number(s); ok {
+			return v
+		}
+	case types["object"]:
+		props, _ := schema["properties"].(map[string]any)
+		if props["amount"] == nil || props["currency"] == nil {
+			return s
+		}
+		if m, ok := ParseMoney(s); ok {
+			out := map[string]any{"amount": m.Amount}
+			if m.Currency != "" {
+				out["currency"] = m.Currency
+			}
+			return out
+		}
+	}
+	return s
+}
+
+// schemaTypes returns the types a schema allows, from a "type" that is a
+// string or a list.
+func schemaTypes(schema map[string]any) map[string]bool {
+	types := make(map[string]bool)
+	switch t := schema["type"].(type) {
+	case string:
+		types[t] = true
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types[s] = true
+			}
+		}
+	}
+	return types
+}
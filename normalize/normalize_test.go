@@ -0,0 +1,147 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"1,234", 1234, true},
+		{"1.234,5", 1234.5, true},
+		{"1,234.56", 1234.56, true},
+		{"1.234.567", 1234567, true},
+		{"3,5", 3.5, true},
+		{"45.2k", 45200, true},
+		{"3.1M views", 3.1e6, true},
+		{"2 billion", 2e9, true},
+		{"12%", 12, true},
+		{"-7 points", -7, true},
+		{"1'000'000", 1e6, true},
+		{"no comments", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Number(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Number(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+		ok   bool
+	}{
+		{"$1,299.00", Money{1299, "USD"}, true},
+		{"1.299,00 €", Money{1299, "EUR"}, true},
+		{"EUR 12", Money{12, "EUR"}, true},
+		{"US$ 5", Money{5, "USD"}, true},
+		{"CA$20", Money{20, "CAD"}, true},
+		{"$30 CAD", Money{30, "CAD"}, true},
+		{"¥ 100 RMB", Money{100, "CNY"}, true},
+		{"42", Money{42, ""}, true},
+		{"Free", Money{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseMoney(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseMoney(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, loc)
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, loc), true},
+		{"2024-03-01T08:00:00Z", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), true},
+		{"Mar 1, 2024", time.Date(2024, 3, 1, 0, 0, 0, 0, loc), true},
+		{"1 March 2024", time.Date(2024, 3, 1, 0, 0, 0, 0, loc), true},
+		{"just now", now, true},
+		{"Yesterday", today.AddDate(0, 0, -1), true},
+		{"3 hours ago", now.Add(-3 * time.Hour), true},
+		{"a minute ago", now.Add(-time.Minute), true},
+		{"in 2 days", now.AddDate(0, 0, 2), true},
+		{"5m", now.Add(-5 * time.Minute), true},
+		{"2 mos ago", now.AddDate(0, -2, 0), true},
+		{"1y", now.AddDate(-1, 0, 0), true},
+		{"sometime", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Time(tt.in, now)
+		if !got.Equal(tt.want) || ok != tt.ok {
+			t.Errorf("Time(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestData(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"posts": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"likes":  map[string]any{"type": []any{"integer", "null"}},
+						"ratio":  map[string]any{"type": "integer"},
+						"posted": map[string]any{"type": "string", "format": "date-time"},
+						"day":    map[string]any{"type": "string", "format": "date"},
+						"title":  map[string]any{"type": "string"},
+						"price": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"amount":   map[string]any{"type": "number"},
+								"currency": map[string]any{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	post := map[string]any{
+		"likes":  "45.2k",
+		"ratio":  "1.5",
+		"posted": "2 hours ago",
+		"day":    "yesterday",
+		"title":  "12 tips",
+		"price":  "€9,99",
+		"extra":  "3k",
+	}
+	data := map[string]any{"posts": []any{post}}
+
+	got := Data(data, schema, now)
+
+	want := map[string]any{"posts": []any{map[string]any{
+		"likes":  45200.0,
+		"ratio":  "1.5", // not an integer; left for validation to report
+		"posted": "2024-03-10T13:30:00Z",
+		"day":    "2024-03-09",
+		"title":  "12 tips",
+		"price":  map[string]any{"amount": 9.99, "currency": "EUR"},
+		"extra":  "3k",
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Data() = %v, want %v", got, want)
+	}
+	if post["likes"] != "45.2k" {
+		t.Error("Data() modified its input")
+	}
+}
@@ -0,0 +1,115 @@
+// Package ocr reads text from screenshots, for content a page draws rather
+// than writes: charts, canvas-based apps, and buttons that are images.
+//
+// An Engine turns an image into text. Tesseract runs the tesseract command
+// locally; Gemini asks a vision model; EngineFunc adapts any other
+// implementation.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Engine reads the text in a PNG or JPEG image.
+type Engine interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// EngineFunc adapts a function to the Engine interface.
+type EngineFunc func(ctx context.Context, image []byte) (string, error)
+
+// Recognize calls f.
+func (f EngineFunc) Recognize(ctx context.Context, image []byte) (string, error) {
+	return f(ctx, image)
+}
+
+// Tesseract reads text with the Tesseract OCR command
+// (github.com/tesseract-ocr/tesseract), which must be installed.
+type Tesseract struct {
+	// Path is the tesseract binary. Default: "tesseract" on the PATH.
+	Path string
+
+	// Languages are the trained languages to use, joined with "+", e.g.
+	// "eng+deu". Default: "eng".
+	Languages string
+}
+
+// Recognize runs tesseract on image.
+func (t *Tesseract) Recognize(ctx context.Context, image []byte) (string, error) {
+	path := t.Path
+	if path == "" {
+		path = "tesseract"
+	}
+	languages := t.Languages
+	if languages == "" {
+		languages = "eng"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "stdin", "stdout", "-l", languages)
+	cmd.Stdin = bytes.NewReader(image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("tesseract failed: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// DefaultGeminiModel is the model Gemini uses when Model is empty.
+const DefaultGeminiModel = "gemini-2.5-flash"
+
+// geminiPrompt asks the model for a transcription and nothing else.
+const geminiPrompt = "Transcribe all text visible in this image, including text in charts, buttons and icons, top to bottom and left to right. Keep each line or label on its own line. Reply with the text only; reply with nothing if there is none."
+
+// Gemini reads text by sending the image to a Gemini vision model.
+type Gemini struct {
+	// APIKey is the Gemini API key (required).
+	APIKey string
+
+	// Model is the model to use. Default: DefaultGeminiModel.
+	Model string
+
+	// HTTPClient is used for API requests. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Recognize asks the model to transcribe image.
+func (g *Gemini) Recognize(ctx context.Context, image []byte) (string, error) {
+	if g.APIKey == "" {
+		return "", errors.New("gemini ocr: API key required")
+	}
+	model := g.Model
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     g.APIKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: g.HTTPClient,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create gemini client: %w", err)
+	}
+	contents := []*genai.Content{genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromBytes(image, http.DetectContentType(image)),
+		genai.NewPartFromText(geminiPrompt),
+	}, genai.RoleUser)}
+	resp, err := client.Models.GenerateContent(ctx, model, contents, nil)
+	if err != nil {
+		return "", fmt.Errorf("gemini ocr request failed: %w", err)
+	}
+	return strings.TrimSpace(resp.Text()), nil
+}
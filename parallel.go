@@ -0,0 +1,117 @@
+package bua
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua/agent"
+)
+
+// ParallelTask is an independent sub-task for RunParallel.
+type ParallelTask struct {
+	// Task is the natural-language instruction.
+	Task string
+
+	// URL is opened in the sub-task's tab before it runs (optional).
+	URL string
+}
+
+// RunParallel runs independent sub-tasks concurrently, each in its own tab of
+// this agent's browser. Tabs share cookies and login state, but every sub-task
+// has its own element map and conversation, so they don't interfere.
+// This uses far less memory than one browser per task; for fully isolated
+// sessions use a Pool instead.
+//
+// At most Config.MaxParallelTabs sub-tasks run at once. Results are returned in
+// the same order as tasks. A sub-task that fails to start is reported as an
+// unsuccessful Result rather than failing the whole call. Like Run, it
+// returns ErrBusy if a task is already running on the agent.
+func (a *Agent) RunParallel(ctx context.Context, tasks []ParallelTask) ([]*Result, error) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+
+	if !started {
+		return nil, ErrNotStarted
+	}
+
+	select {
+	case a.runSlot <- struct{}{}:
+	default:
+		return nil, ErrBusy
+	}
+	defer func() { <-a.runSlot }()
+
+	// Register the batch as the running task, so CloseGracefully can cancel it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.mu.Lock()
+	if !a.started {
+		a.mu.Unlock()
+		return nil, ErrNotStarted
+	}
+	a.cancelRun = cancel
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.cancelRun = nil
+		a.mu.Unlock()
+	}()
+
+	results := make([]*Result, len(tasks))
+	sem := make(chan struct{}, a.config.MaxParallelTabs)
+	runID := time.Now().UnixMilli()
+
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task ParallelTask) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = &Result{Success: false, Error: ctx.Err().Error()}
+				return
+			}
+
+			start := time.Now()
+			result, err := a.runInTab(ctx, task, filepath.Join(a.config.ScreenshotDir, fmt.Sprintf("parallel_%d_%d", runID, i+1)))
+			if err != nil {
+				result = &Result{Success: false, Error: err.Error(), Duration: time.Since(start)}
+			}
+			results[i] = result
+		}(i, task)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// runInTab runs one sub-task with a dedicated agent in a new tab view.
+func (a *Agent) runInTab(ctx context.Context, task ParallelTask, screenshotDir string) (*Result, error) {
+	view, err := a.browser.NewTabView(ctx, task.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tab: %w", err)
+	}
+	defer view.Close()
+
+	browserAgent, err := agent.NewBrowserAgent(ctx, a.agentConfig(screenshotDir), view)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	defer browserAgent.Close()
+
+	a.log("Parallel").Debug("Running task in a new tab", "task", task.Task)
+
+	agentResult, err := browserAgent.Run(ctx, task.Task)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertResult(agentResult), nil
+}
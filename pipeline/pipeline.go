@@ -0,0 +1,347 @@
+// Package pipeline chains browser tasks, feeding each step's extracted data
+// into the prompts of the steps after it.
+//
+// Each step is a task spec (see package taskspec) whose templates can use
+// the results of earlier steps:
+//
+//	{{search.data}}          the whole result data of step "search" (JSON)
+//	{{search.data.title}}    one field of it; list items by index, e.g. {{search.data.0}}
+//	{{search.success}}       "true" or "false"
+//	{{search.error}}         the failure message, if any
+//
+// Steps run in order unless Next or OnFailure send the run elsewhere, which
+// makes retries, fallbacks and cleanup steps possible. Steps with their own
+// profile, preset, step limit or secrets run on a separate agent, so a
+// pipeline can read from one signed-in site and act on another.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/taskspec"
+	"gopkg.in/yaml.v3"
+)
+
+// End is a Next/OnFailure target that stops the pipeline.
+const End = "end"
+
+// Step is one task in a pipeline.
+type Step struct {
+	// Spec is the task template. Its Name identifies the step in templates
+	// and results (required, unique).
+	taskspec.Spec `yaml:",inline"`
+
+	// Next is the step to run after success. Default: the following step.
+	Next string `yaml:"next" json:"next,omitempty"`
+
+	// OnFailure is the step to run after failure. Default: stop, and the
+	// pipeline fails.
+	OnFailure string `yaml:"on_failure" json:"on_failure,omitempty"`
+}
+
+// Pipeline is a sequence of steps.
+type Pipeline struct {
+	Steps []Step `yaml:"steps" json:"steps"`
+
+	// Config is the base configuration for steps that run on their own
+	// agent, and for the shared agent if Run isn't given one.
+	Config bua.Config `yaml:"-" json:"-"`
+
+	// MaxTransitions bounds how many steps run in total, guarding against
+	// branches that loop forever. Default: 4 × len(Steps).
+	MaxTransitions int `yaml:"max_transitions" json:"max_transitions,omitempty"`
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Result   *bua.Result   `json:"result,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Result is the outcome of a pipeline run.
+type Result struct {
+	// Success is true if the pipeline ended without an unhandled failure.
+	Success bool `json:"success"`
+
+	// Data is the result data of the last successful step.
+	Data any `json:"data,omitempty"`
+
+	// Error describes the failure that stopped the pipeline.
+	Error string `json:"error,omitempty"`
+
+	// Steps lists the steps that ran, in order.
+	Steps []StepResult `json:"steps"`
+
+	Duration time.Duration `json:"duration"`
+}
+
+// Load reads a pipeline from a YAML or JSON file with a top-level "steps" list.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var p Pipeline
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// validate checks step names and branch targets.
+func (p *Pipeline) validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("pipeline has no steps")
+	}
+
+	names := make(map[string]bool, len(p.Steps))
+	for _, s := range p.Steps {
+		switch {
+		case s.Name == "":
+			return fmt.Errorf("step name is required")
+		case s.Name == End:
+			return fmt.Errorf("step name %q is reserved", End)
+		case names[s.Name]:
+			return fmt.Errorf("duplicate step name %q", s.Name)
+		case s.Task == "":
+			return fmt.Errorf("step %q: task is required", s.Name)
+		}
+		names[s.Name] = true
+	}
+	for _, s := range p.Steps {
+		for _, target := range []string{s.Next, s.OnFailure} {
+			if target != "" && target != End && !names[target] {
+				return fmt.Errorf("step %q: unknown step %q", s.Name, target)
+			}
+		}
+	}
+	return nil
+}
+
+// Run executes the pipeline. vars fill {{variables}} in every step. Steps
+// that don't need their own agent share agent, keeping its page and
+// cookies from step to step; if agent is nil, one is started from Config
+// and closed at the end.
+func (p *Pipeline) Run(ctx context.Context, agent *bua.Agent, vars map[string]string) (*Result, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := &Result{Success: true}
+	values := make(map[string]string, len(vars))
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	maxTransitions := p.MaxTransitions
+	if maxTransitions <= 0 {
+		maxTransitions = 4 * len(p.Steps)
+	}
+
+	index := make(map[string]int, len(p.Steps))
+	for i, s := range p.Steps {
+		index[s.Name] = i
+	}
+
+	// Start a shared agent on first use if the caller didn't provide one
+	ownsShared := false
+	shared := func(ctx context.Context) (*bua.Agent, error) {
+		if agent != nil {
+			return agent, nil
+		}
+		a, err := startAgent(ctx, p.Config)
+		if err != nil {
+			return nil, err
+		}
+		agent, ownsShared = a, true
+		return agent, nil
+	}
+	defer func() {
+		if ownsShared {
+			agent.Close()
+		}
+	}()
+
+	current := 0
+	for transitions := 0; current < len(p.Steps); transitions++ {
+		if transitions >= maxTransitions {
+			result.Success = false
+			result.Error = fmt.Sprintf("stopped after %d steps; check for a Next/OnFailure loop", maxTransitions)
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+			break
+		}
+
+		step := p.Steps[current]
+		sr := p.runStep(ctx, step, values, shared)
+		result.Steps = append(result.Steps, sr)
+		setStepValues(values, sr)
+
+		target := step.Next
+		if sr.Success {
+			result.Data = sr.Result.Data
+		} else {
+			target = step.OnFailure
+			if target == "" {
+				result.Success = false
+				result.Error = fmt.Sprintf("step %q failed: %s", step.Name, sr.Error)
+				break
+			}
+		}
+
+		switch target {
+		case "":
+			current++
+		case End:
+			current = len(p.Steps)
+		default:
+			current = index[target]
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// runStep renders and runs one step, on its own agent if it sets a profile,
+// preset, step limit or secrets and on the shared agent otherwise.
+func (p *Pipeline) runStep(ctx context.Context, step Step, values map[string]string, shared func(context.Context) (*bua.Agent, error)) StepResult {
+	start := time.Now()
+	sr := StepResult{Name: step.Name}
+	fail := func(err error) StepResult {
+		sr.Error = err.Error()
+		sr.Duration = time.Since(start)
+		return sr
+	}
+
+	task, err := step.Render(values)
+	if err != nil {
+		return fail(err)
+	}
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	var agent *bua.Agent
+	if step.Profile != "" || step.Preset != "" || step.MaxSteps > 0 || len(task.Secrets) > 0 {
+		cfg := p.Config
+		task.Apply(&cfg)
+		agent, err = startAgent(ctx, cfg)
+		if err == nil {
+			defer agent.Close()
+		}
+	} else {
+		agent, err = shared(ctx)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	if task.URL != "" {
+		if err := agent.Navigate(ctx, task.URL); err != nil {
+			return fail(fmt.Errorf("failed to navigate to %s: %w", task.URL, err))
+		}
+	}
+
+	res, err := agent.RunWithOptions(ctx, task.Prompt, bua.RunOptions{Schema: task.Schema})
+	if err != nil {
+		return fail(err)
+	}
+
+	sr.Result = res
+	sr.Success = res.Success
+	sr.Error = res.Error
+	sr.Duration = time.Since(start)
+	return sr
+}
+
+// startAgent creates and starts an agent.
+func startAgent(ctx context.Context, cfg bua.Config) (*bua.Agent, error) {
+	agent, err := bua.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := agent.Start(ctx); err != nil {
+		agent.Close()
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+	return agent, nil
+}
+
+// setStepValues exposes a step's outcome as template variables, replacing
+// those of an earlier run of the step.
+func setStepValues(values map[string]string, sr StepResult) {
+	prefix := sr.Name
+	for k := range values {
+		if strings.HasPrefix(k, prefix+".") {
+			delete(values, k)
+		}
+	}
+	values[prefix+".success"] = strconv.FormatBool(sr.Success)
+	values[prefix+".error"] = sr.Error
+	if sr.Result == nil {
+		return
+	}
+
+	// Normalize structs and typed maps to generic JSON values
+	data := sr.Result.Data
+	if encoded, err := json.Marshal(data); err == nil {
+		var generic any
+		if json.Unmarshal(encoded, &generic) == nil {
+			data = generic
+		}
+	}
+	flatten(values, prefix+".data", data)
+}
+
+// flatten stores v under key, and each nested field under key.field.
+// Strings are stored as-is; everything else as JSON.
+func flatten(values map[string]string, key string, v any) {
+	switch val := v.(type) {
+	case nil:
+		values[key] = ""
+	case string:
+		values[key] = val
+	case map[string]any:
+		for k, child := range val {
+			flatten(values, key+"."+k, child)
+		}
+		values[key] = toJSON(val)
+	case []any:
+		for i, child := range val {
+			flatten(values, key+"."+strconv.Itoa(i), child)
+		}
+		values[key] = toJSON(val)
+	default:
+		values[key] = toJSON(val)
+	}
+}
+
+// toJSON encodes v compactly, or returns "" if it can't be encoded.
+func toJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
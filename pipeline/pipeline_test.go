@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/anxuanzi/bua"
+)
+
+func TestSetStepValuesReplacesEarlierRun(t *testing.T) {
+	values := map[string]string{"query": "shoes", "searchable": "kept"}
+
+	setStepValues(values, StepResult{Name: "search", Success: true, Result: &bua.Result{
+		Data: map[string]any{"items": []any{"a", "b"}, "next": "/page/2"},
+	}})
+	if got := values["search.data.items.1"]; got != "b" {
+		t.Fatalf("search.data.items.1 = %q, want %q", got, "b")
+	}
+
+	// A re-run with fewer fields must not leave the first run's behind
+	setStepValues(values, StepResult{Name: "search", Success: true, Result: &bua.Result{
+		Data: map[string]any{"items": []any{"c"}},
+	}})
+	for _, stale := range []string{"search.data.items.1", "search.data.next"} {
+		if v, ok := values[stale]; ok {
+			t.Errorf("%s = %q after re-run, want unset", stale, v)
+		}
+	}
+	if got := values["search.data.items.0"]; got != "c" {
+		t.Errorf("search.data.items.0 = %q, want %q", got, "c")
+	}
+	if values["query"] != "shoes" || values["searchable"] != "kept" {
+		t.Errorf("other values changed: %v", values)
+	}
+}
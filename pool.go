@@ -0,0 +1,313 @@
+package bua
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua/agent"
+)
+
+// PoolConfig configures a Pool.
+type PoolConfig struct {
+	// Agent is the configuration for every agent in the pool.
+	// If ProfileName is set, each agent gets its own "<name>-<n>" profile
+	// so browsers never share a profile directory.
+	Agent Config
+
+	// Size is the number of warm agents (browsers). Default: 2.
+	Size int
+
+	// QueueSize is the maximum number of tasks waiting for an agent. Default: 100.
+	QueueSize int
+
+	// TaskTimeout bounds each task. Default: 0 (no timeout).
+	TaskTimeout time.Duration
+
+	// TokenBudget is the total number of tokens the pool may spend across all tasks.
+	// Once exhausted, queued and new tasks fail with ErrTokenBudgetExceeded. Default: 0 (unlimited).
+	TokenBudget int
+}
+
+// PoolJob describes a task submitted to a Pool.
+type PoolJob struct {
+	// Task is the natural-language instruction.
+	Task string
+
+	// URL is navigated to before the task runs (optional).
+	URL string
+}
+
+// PoolTask is a submitted job. Wait for completion with Wait or Done.
+type PoolTask struct {
+	// ID is the submission sequence number (1-based).
+	ID int
+
+	// Job is the submitted job.
+	Job PoolJob
+
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+// Done returns a channel that is closed when the task finishes.
+func (t *PoolTask) Done() <-chan struct{} {
+	return t.done
+}
+
+// Wait blocks until the task finishes or ctx is done.
+func (t *PoolTask) Wait(ctx context.Context) (*Result, error) {
+	select {
+	case <-t.done:
+		return t.result, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Pool maintains a set of warm agents that execute submitted tasks concurrently.
+type Pool struct {
+	config PoolConfig
+	agents []*Agent
+	keys   *agent.KeyPool // shared by the agents; nil without Config.APIKeys
+	queue  chan *PoolTask
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu         sync.Mutex
+	closed     bool
+	nextID     int
+	tasks      []*PoolTask
+	tokensUsed int
+}
+
+// NewPool launches Size agents and starts processing tasks.
+func NewPool(ctx context.Context, cfg PoolConfig) (*Pool, error) {
+	if cfg.Size <= 0 {
+		cfg.Size = 2
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	poolCtx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		config: cfg,
+		queue:  make(chan *PoolTask, cfg.QueueSize),
+		ctx:    poolCtx,
+		cancel: cancel,
+	}
+
+	for i := 0; i < cfg.Size; i++ {
+		agentCfg := cfg.Agent
+		if agentCfg.ProfileName != "" {
+			agentCfg.ProfileName = fmt.Sprintf("%s-%d", cfg.Agent.ProfileName, i+1)
+		}
+
+		agent, err := New(agentCfg)
+		if err != nil {
+			p.closeAgents()
+			cancel()
+			return nil, err
+		}
+		// Share key rotation, so a rate-limited key rests for every agent
+		if len(p.agents) == 0 {
+			p.keys = agent.keys
+		} else {
+			agent.keys = p.keys
+		}
+		if err := agent.Start(ctx); err != nil {
+			p.closeAgents()
+			cancel()
+			return nil, fmt.Errorf("failed to start pool agent %d: %w", i+1, err)
+		}
+		p.agents = append(p.agents, agent)
+	}
+
+	for _, agent := range p.agents {
+		p.wg.Add(1)
+		go p.worker(agent)
+	}
+
+	return p, nil
+}
+
+// Submit queues a task and returns immediately.
+func (p *Pool) Submit(task string) (*PoolTask, error) {
+	return p.SubmitJob(PoolJob{Task: task})
+}
+
+// SubmitJob queues a job and returns immediately.
+// Returns ErrPoolQueueFull if the queue is full.
+func (p *Pool) SubmitJob(job PoolJob) (*PoolTask, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if p.budgetExhaustedLocked() {
+		return nil, ErrTokenBudgetExceeded
+	}
+
+	p.nextID++
+	t := &PoolTask{ID: p.nextID, Job: job, done: make(chan struct{})}
+
+	select {
+	case p.queue <- t:
+	default:
+		p.nextID--
+		return nil, ErrPoolQueueFull
+	}
+
+	p.tasks = append(p.tasks, t)
+	return t, nil
+}
+
+// Wait blocks until every task submitted so far has finished and returns
+// them in submission order.
+func (p *Pool) Wait(ctx context.Context) ([]*PoolTask, error) {
+	p.mu.Lock()
+	tasks := append([]*PoolTask(nil), p.tasks...)
+	p.mu.Unlock()
+
+	for _, t := range tasks {
+		select {
+		case <-t.done:
+		case <-ctx.Done():
+			return tasks, ctx.Err()
+		}
+	}
+	return tasks, nil
+}
+
+// Results returns the result of each finished task submitted so far, in submission order.
+// Unfinished tasks are skipped.
+func (p *Pool) Results() []*Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var results []*Result
+	for _, t := range p.tasks {
+		select {
+		case <-t.done:
+			if t.result != nil {
+				results = append(results, t.result)
+			}
+		default:
+		}
+	}
+	return results
+}
+
+// TokensUsed returns the total tokens consumed by finished tasks.
+func (p *Pool) TokensUsed() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tokensUsed
+}
+
+// APIKeyUsage returns the usage of each of the agents' Config.APIKeys, which
+// they share, or nil without them. It stays readable after Close.
+func (p *Pool) APIKeyUsage() []KeyUsage {
+	if p.keys == nil {
+		return nil
+	}
+	return p.keys.Usage()
+}
+
+// Size returns the number of agents in the pool.
+func (p *Pool) Size() int {
+	return len(p.agents)
+}
+
+// Close stops accepting tasks, cancels running ones, and shuts down all browsers.
+// Queued tasks that never started fail with ErrPoolClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+
+	return p.closeAgents()
+}
+
+// worker runs queued tasks on one agent until the queue is closed.
+func (p *Pool) worker(agent *Agent) {
+	defer p.wg.Done()
+
+	for t := range p.queue {
+		p.run(agent, t)
+	}
+}
+
+// run executes one task and records its outcome.
+func (p *Pool) run(agent *Agent, t *PoolTask) {
+	defer close(t.done)
+
+	if err := p.ctx.Err(); err != nil {
+		t.err = ErrPoolClosed
+		return
+	}
+
+	p.mu.Lock()
+	exhausted := p.budgetExhaustedLocked()
+	p.mu.Unlock()
+	if exhausted {
+		t.err = ErrTokenBudgetExceeded
+		return
+	}
+
+	ctx := p.ctx
+	if p.config.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.TaskTimeout)
+		defer cancel()
+	}
+
+	if t.Job.URL != "" {
+		if err := agent.Navigate(ctx, t.Job.URL); err != nil {
+			t.err = fmt.Errorf("failed to navigate to %s: %w", t.Job.URL, err)
+			return
+		}
+	}
+
+	t.result, t.err = agent.Run(ctx, t.Job.Task)
+
+	if t.result != nil {
+		p.mu.Lock()
+		p.tokensUsed += t.result.TokensUsed
+		p.mu.Unlock()
+	}
+}
+
+// budgetExhaustedLocked reports whether the token budget is spent. Must be called with p.mu held.
+func (p *Pool) budgetExhaustedLocked() bool {
+	return p.config.TokenBudget > 0 && p.tokensUsed >= p.config.TokenBudget
+}
+
+// closeAgents shuts down every started agent.
+func (p *Pool) closeAgents() error {
+	var errs []error
+	for _, agent := range p.agents {
+		if err := agent.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.agents = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors during pool close: %v", errs)
+	}
+	return nil
+}
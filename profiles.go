@@ -0,0 +1,415 @@
+package bua
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anxuanzi/bua/browser"
+)
+
+// ProfileInfo describes a stored browser profile.
+type ProfileInfo struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`     // Bytes on disk
+	ModTime time.Time `json:"mod_time"` // Last time any file in the profile changed
+}
+
+// ProfileManager lists, deletes, exports and imports the profiles under a
+// profile directory. Profiles must not be in use by a running agent.
+type ProfileManager struct {
+	config Config
+}
+
+// Profiles returns a manager for the profiles under cfg.ProfileDir
+// (default ~/.bua/profiles). Export and Import launch a headless browser
+// using cfg's browser settings to read and write cookies; set cfg.ProfileKey
+// to work with profiles encrypted at rest.
+func Profiles(cfg Config) *ProfileManager {
+	cfg.applyDefaults()
+	return &ProfileManager{config: cfg}
+}
+
+// List returns the stored profiles sorted by name.
+func (m *ProfileManager) List() ([]ProfileInfo, error) {
+	entries, err := os.ReadDir(m.config.ProfileDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read profile directory: %w", err)
+	}
+
+	var profiles []ProfileInfo
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info := ProfileInfo{Name: e.Name(), Path: filepath.Join(m.config.ProfileDir, e.Name())}
+		filepath.WalkDir(info.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if fi, err := d.Info(); err == nil {
+				info.Size += fi.Size()
+				if fi.ModTime().After(info.ModTime) {
+					info.ModTime = fi.ModTime()
+				}
+			}
+			return nil
+		})
+		profiles = append(profiles, info)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// Delete removes a profile and everything stored in it.
+func (m *ProfileManager) Delete(name string) error {
+	path, err := m.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ErrProfileNotFound
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	return nil
+}
+
+// profileArchiveMagic starts every exported profile archive.
+const profileArchiveMagic = "BUAPROF1"
+
+// pbkdf2Iterations is the key derivation cost for archive passphrases.
+const pbkdf2Iterations = 600000
+
+// cookiesEntry is the archive entry holding the profile's cookies.
+const cookiesEntry = "cookies.json"
+
+// portablePaths are the parts of a profile copied as files. Chrome encrypts
+// its cookie database with a machine-bound key, so cookies travel as JSON
+// instead; local storage and bua's own files are portable as-is.
+var portablePaths = []string{
+	filepath.Join("Default", "Local Storage"),
+	"bua-fingerprint.json",
+	"bua-sessions",
+}
+
+// Export writes a profile's cookies, local storage, fingerprint and saved
+// sessions to w as an archive encrypted with passphrase (AES-256-GCM).
+func (m *ProfileManager) Export(ctx context.Context, name string, w io.Writer, passphrase string) (err error) {
+	path, err := m.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ErrProfileNotFound
+	}
+	if passphrase == "" {
+		return fmt.Errorf("bua: passphrase is required")
+	}
+
+	relock, err := m.unlock(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if lockErr := relock(); lockErr != nil && err == nil {
+			err = lockErr
+		}
+	}()
+
+	cookies, err := m.withProfile(ctx, name, func(b *browser.Browser) ([]browser.Cookie, error) {
+		return b.GetCookies(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+	if err := writeTarFile(tw, cookiesEntry, data, 0600); err != nil {
+		return err
+	}
+
+	if err := archiveProfileFiles(tw, path, portablePaths); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to archive profile: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to archive profile: %w", err)
+	}
+
+	sealed, err := sealArchive(buf.Bytes(), passphrase)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(sealed); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Import creates profile name from an archive written by Export. It fails if
+// the profile already exists; Delete it first to replace it.
+func (m *ProfileManager) Import(ctx context.Context, name string, r io.Reader, passphrase string) error {
+	path, err := m.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("bua: profile %q already exists", name)
+	}
+
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	archive, err := openArchive(sealed, passphrase)
+	if err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var cookies []browser.Cookie
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if hdr.Name == cookiesEntry {
+			if err := json.Unmarshal(data, &cookies); err != nil {
+				return fmt.Errorf("failed to parse cookies: %w", err)
+			}
+			continue
+		}
+
+		if err := extractProfileFile(path, hdr.Name, data); err != nil {
+			return err
+		}
+	}
+
+	if len(cookies) > 0 {
+		_, err = m.withProfile(ctx, name, func(b *browser.Browser) ([]browser.Cookie, error) {
+			return nil, b.SetCookies(ctx, cookies)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.config.ProfileKey != "" {
+		return sealProfile(path, m.config.ProfileKey)
+	}
+	return nil
+}
+
+// unlock decrypts a profile sealed with cfg.ProfileKey, returning a function
+// that seals it again and reports whether that failed.
+func (m *ProfileManager) unlock(path string) (func() error, error) {
+	if m.config.ProfileKey == "" {
+		return func() error { return nil }, nil
+	}
+	if err := unsealProfile(path, m.config.ProfileKey); err != nil {
+		return nil, fmt.Errorf("failed to unlock profile: %w", err)
+	}
+	return func() error {
+		if err := sealProfile(path, m.config.ProfileKey); err != nil {
+			return fmt.Errorf("failed to lock profile: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// ValidateProfileName returns an error unless name is a plain directory
+// name, so that the profile stays inside ProfileDir.
+func ValidateProfileName(name string) error {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return fmt.Errorf("bua: invalid profile name %q", name)
+	}
+	return nil
+}
+
+// path returns the directory of a profile, rejecting names that escape ProfileDir.
+func (m *ProfileManager) path(name string) (string, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(m.config.ProfileDir, name), nil
+}
+
+// withProfile runs fn against a headless browser using the named profile.
+func (m *ProfileManager) withProfile(ctx context.Context, name string, fn func(*browser.Browser) ([]browser.Cookie, error)) ([]browser.Cookie, error) {
+	b, err := browser.New(browser.Config{
+		Headless:        true,
+		ProfileDir:      m.config.ProfileDir,
+		ProfileName:     name,
+		BrowserPath:     m.config.BrowserPath,
+		BrowserRevision: m.config.BrowserRevision,
+		BrowserDir:      m.config.BrowserDir,
+		Debug:           m.config.Debug,
+		Logger:          m.config.Logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser: %w", err)
+	}
+	if err := b.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+	defer b.Close()
+
+	return fn(b)
+}
+
+// archiveProfileFiles adds the files under the given profile-relative paths
+// to an archive as "profile/<path>" entries. Missing paths are skipped.
+func archiveProfileFiles(tw *tar.Writer, profilePath string, rels []string) error {
+	for _, rel := range rels {
+		root := filepath.Join(profilePath, rel)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			// Chrome's lock file is meaningless elsewhere
+			if d.IsDir() || d.Name() == "LOCK" {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			relPath, _ := filepath.Rel(profilePath, p)
+			return writeTarFile(tw, "profile/"+filepath.ToSlash(relPath), data, 0600)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to archive profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// extractProfileFile writes a "profile/<path>" archive entry into a profile.
+func extractProfileFile(profilePath, name string, data []byte) error {
+	rel, ok := strings.CutPrefix(name, "profile/")
+	target := filepath.Join(profilePath, filepath.FromSlash(rel))
+	if !ok || !strings.HasPrefix(target, profilePath+string(filepath.Separator)) {
+		return fmt.Errorf("bua: invalid archive entry %q", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	return nil
+}
+
+// writeTarFile adds a regular file to an archive.
+func writeTarFile(tw *tar.Writer, name string, data []byte, mode int64) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(data))}); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// archiveKey derives the encryption key for an archive from a passphrase.
+func archiveKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealArchive encrypts data as magic || salt || nonce || ciphertext.
+func sealArchive(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := archiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(profileArchiveMagic), salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, []byte(profileArchiveMagic)), nil
+}
+
+// openArchive decrypts an archive written by sealArchive.
+func openArchive(sealed []byte, passphrase string) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(sealed, []byte(profileArchiveMagic))
+	if !ok || len(rest) < 16 {
+		return nil, fmt.Errorf("bua: not a profile archive")
+	}
+	aead, err := archiveKey(passphrase, rest[:16])
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[16:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("bua: not a profile archive")
+	}
+
+	data, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(profileArchiveMagic))
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	return data, nil
+}
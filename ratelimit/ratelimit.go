@@ -0,0 +1,141 @@
+// Package ratelimit paces navigations per host with a token bucket, so bulk
+// jobs don't hammer a single site.
+//
+// Each host gets its own bucket: Burst navigations may happen back to back,
+// then PerMinute refill it. MinDelay additionally spaces any two navigations
+// to the host. A Limiter can be shared by several agents to pace them
+// together.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is the pace allowed on one host.
+type Limit struct {
+	// PerMinute is how many navigations the host gets per minute (0 =
+	// unlimited).
+	PerMinute float64
+
+	// Burst is how many navigations may happen back to back before PerMinute
+	// applies. Default: 1.
+	Burst int
+
+	// MinDelay is the least time between two navigations to the host.
+	// Default: 0.
+	MinDelay time.Duration
+}
+
+// Config configures a Limiter.
+type Config struct {
+	// Default is the limit of hosts Domains has no entry for.
+	Default Limit
+
+	// Domains sets the limits of single sites, keyed by domain, e.g.
+	// "example.com" (which covers its subdomains). The most specific domain
+	// applies. Default: nil.
+	Domains map[string]Limit
+}
+
+// Limiter paces navigations per host. It is safe for concurrent use.
+type Limiter struct {
+	cfg Config
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the token bucket of one host.
+type bucket struct {
+	tokens float64   // may go negative: navigations waiting for a token
+	filled time.Time // when tokens was last brought up to date
+	last   time.Time // start of the last navigation reserved
+}
+
+// New returns a Limiter for cfg.
+func New(cfg Config) *Limiter {
+	return &Limiter{cfg: cfg, buckets: make(map[string]*bucket)}
+}
+
+// Wait blocks until a navigation to rawURL's host is allowed, and reserves
+// it. URLs that are not http or https pass at once. It returns early with
+// ctx's error if ctx ends first; the reservation is kept, so the host stays
+// paced.
+func (l *Limiter) Wait(ctx context.Context, rawURL string) error {
+	d, err := l.Reserve(rawURL)
+	if err != nil || d <= 0 {
+		return err
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Reserve reserves a navigation to rawURL's host and returns how long to
+// wait before starting it.
+func (l *Limiter) Reserve(rawURL string) (time.Duration, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return 0, nil
+	}
+	host := strings.ToLower(u.Hostname())
+	limit := l.limitFor(host)
+	if limit.PerMinute <= 0 && limit.MinDelay <= 0 {
+		return 0, nil
+	}
+	burst := float64(max(limit.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: burst, filled: now}
+		l.buckets[host] = b
+	}
+
+	start := now
+	if limit.PerMinute > 0 {
+		rate := limit.PerMinute / 60 // tokens per second
+		b.tokens = min(b.tokens+now.Sub(b.filled).Seconds()*rate, burst)
+		b.filled = now
+		b.tokens--
+		if b.tokens < 0 {
+			start = now.Add(time.Duration(-b.tokens / rate * float64(time.Second)))
+		}
+	}
+	if !b.last.IsZero() {
+		if next := b.last.Add(limit.MinDelay); next.After(start) {
+			start = next
+		}
+	}
+	b.last = start
+	return start.Sub(now), nil
+}
+
+// limitFor returns the limit of host: that of the most specific domain in
+// Domains it belongs to, or Default.
+func (l *Limiter) limitFor(host string) Limit {
+	limit, best := l.cfg.Default, ""
+	for domain, dl := range l.cfg.Domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(best) {
+			limit, best = dl, domain
+		}
+	}
+	return limit
+}
@@ -1,67 +1,228 @@
 package bua
 
-import "time"
+import (
+	"time"
+
+	"github.com/anxuanzi/bua/agent"
+)
 
 // Result represents the outcome of a task execution.
 type Result struct {
 	// Success indicates whether the task completed successfully.
-	Success bool
+	Success bool `json:"success"`
 
 	// Data contains the extracted data or task output.
 	// The type depends on what the agent was asked to do.
-	Data any
+	Data any `json:"data,omitempty"`
 
 	// Error contains the error message if Success is false.
-	Error string
+	Error string `json:"error,omitempty"`
+
+	// ErrorCode classifies the failure if Success is false, e.g.
+	// ErrorCodeStepBudget. Use Err to check it with errors.Is.
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+
+	// Summary is the agent's account of the run, from its done call, in
+	// RunOptions.SummaryFormat. For a full report, see Agent.Summarize.
+	Summary string `json:"summary,omitempty"`
 
 	// Steps contains the sequence of actions taken during execution.
-	Steps []Step
+	Steps []Step `json:"steps"`
 
 	// Duration is the total execution time.
-	Duration time.Duration
+	Duration time.Duration `json:"duration"`
+
+	// TokensUsed is the number of tokens consumed, as reported by the model API.
+	TokensUsed int `json:"tokens_used,omitempty"`
+
+	// CachedTokens is how many of the prompt tokens were served from a cache
+	// and billed at the cached rate (see Config.ContextCaching).
+	CachedTokens int `json:"cached_tokens,omitempty"`
 
-	// TokensUsed is the approximate number of tokens consumed.
-	TokensUsed int
+	// Usage breaks TokensUsed down into input, output and thinking tokens.
+	Usage TokenUsage `json:"usage"`
+
+	// EstimatedCost is what Usage costs in US dollars, per Config.Pricing.
+	// It is 0 for models without a price.
+	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+
+	// Blocked describes the bot challenge, block page or login wall that
+	// stopped the task, if ErrorCode is ErrorCodeBlocked.
+	Blocked *BlockedError `json:"blocked,omitempty"`
+
+	// PartialResult is set if the agent stopped without finishing, e.g. at
+	// MaxSteps, but had found something: Data is then a *PartialData with
+	// the model's last output and the page content it read.
+	PartialResult bool `json:"partial_result,omitempty"`
+
+	// CompletionReason says how the task ended: the agent called done, or
+	// it ran out of budget or time, was aborted, or needed a person.
+	CompletionReason CompletionReason `json:"completion_reason"`
 
 	// ScreenshotPaths contains paths to saved screenshots.
-	ScreenshotPaths []string
+	ScreenshotPaths []string `json:"screenshot_paths,omitempty"`
+
+	// RecordingPath is the path to the session recording, if Config.RecordVideo is set.
+	RecordingPath string `json:"recording_path,omitempty"`
+
+	// DownloadPaths contains paths to files the agent downloaded.
+	DownloadPaths []string `json:"download_paths,omitempty"`
+
+	// Artifacts lists uploaded files, if Config.Artifacts is set.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// Assertions lists the checks the agent recorded with its assert tool,
+	// in order. Ask for them in the task, e.g. "verify the cart shows 2 items".
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// Sections holds the result of each objective of a task with several,
+	// as the agent filed them with its report_section tool, in the order
+	// first filed. They survive a run that fails before done, so partial
+	// completion is visible per objective.
+	Sections []Section `json:"sections,omitempty"`
+
+	// Findings holds the entities the agent saved with its save_finding
+	// tool, one per entity as RunOptions.FindingKeys identify them, in the
+	// order first saved. Like Sections, they survive a run that fails.
+	Findings []Finding `json:"findings,omitempty"`
+
+	// Tabs is the state of every open tab when the task ended, sorted by
+	// tab ID, if RunOptions.CaptureTabs is set.
+	Tabs []TabState `json:"tabs,omitempty"`
+}
+
+// Err returns the failure as an error that matches the sentinel for its
+// ErrorCode, or nil if the task succeeded:
+//
+//	if errors.Is(result.Err(), bua.ErrStepBudget) {
+//		// retry with a higher MaxSteps
+//	}
+//
+// A blocked task's error is its *BlockedError.
+func (r *Result) Err() error {
+	if r.Success {
+		return nil
+	}
+	if r.Blocked != nil {
+		return r.Blocked
+	}
+	return &Error{Code: r.ErrorCode, Message: r.Error}
+}
+
+// AssertionsPassed reports whether every recorded assertion passed. It is
+// false if none were recorded.
+func (r *Result) AssertionsPassed() bool {
+	if len(r.Assertions) == 0 {
+		return false
+	}
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			return false
+		}
+	}
+	return true
 }
 
+// CompletionReason says how a task ended. See Result.CompletionReason.
+type CompletionReason = agent.CompletionReason
+
+// Completion reasons reported in Result.CompletionReason.
+const (
+	// CompletionDone means the agent called done, successfully or not.
+	CompletionDone = agent.CompletionDone
+
+	// CompletionBudget means the task ran out of steps, tokens or cost.
+	CompletionBudget = agent.CompletionBudget
+
+	// CompletionTimeout means the context deadline passed.
+	CompletionTimeout = agent.CompletionTimeout
+
+	// CompletionError means the task was aborted: by repeated failures, a
+	// model refusal or a site blocking it.
+	CompletionError = agent.CompletionError
+
+	// CompletionTakeover means the agent gave up because a person was needed
+	// but unavailable.
+	CompletionTakeover = agent.CompletionTakeover
+)
+
+// PartialData is the Data of a Result with PartialResult set.
+type PartialData = agent.PartialData
+
+// PageRead is page content a partial run read, in PartialData.
+type PageRead = agent.PageRead
+
+// TabState is the URL, title, elements and screenshot of one tab, in
+// Result.Tabs.
+type TabState = agent.TabState
+
 // Step represents a single action in the execution sequence.
 type Step struct {
 	// Number is the step index (1-based).
-	Number int
+	Number int `json:"number"`
 
 	// Action is the tool that was called (e.g., "click", "type_text").
-	Action string
+	Action string `json:"action"`
 
 	// Target describes what the action was performed on.
-	Target string
+	Target string `json:"target,omitempty"`
 
 	// Thinking contains the agent's reasoning for this step.
-	Thinking string
+	Thinking string `json:"thinking,omitempty"`
 
 	// Evaluation is the agent's assessment of the previous action.
-	Evaluation string
+	Evaluation string `json:"evaluation,omitempty"`
 
 	// NextGoal describes what the agent planned to do.
-	NextGoal string
+	NextGoal string `json:"next_goal,omitempty"`
 
 	// Memory contains what the agent chose to remember.
-	Memory string
+	Memory string `json:"memory,omitempty"`
 
 	// URL is the page URL at this step.
-	URL string
+	URL string `json:"url,omitempty"`
 
 	// Title is the page title at this step.
-	Title string
+	Title string `json:"title,omitempty"`
 
-	// ScreenshotPath is the path to the screenshot for this step.
-	ScreenshotPath string
+	// ScreenshotPath is the path to the screenshot the agent saw before this step.
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+
+	// AfterScreenshotPath is the path to the screenshot taken after the action ran.
+	AfterScreenshotPath string `json:"after_screenshot_path,omitempty"`
+
+	// Result is the action's result as JSON, cut after 1000 bytes.
+	Result string `json:"result,omitempty"`
+
+	// Success indicates whether the action succeeded.
+	Success bool `json:"success"`
 
 	// Duration is how long this step took.
-	Duration time.Duration
+	Duration time.Duration `json:"duration"`
 
 	// Error contains any error that occurred during this step.
-	Error string
+	Error string `json:"error,omitempty"`
+
+	// ErrorCode classifies the error, when it is one bua recognizes
+	// (e.g., ErrorCodeElementNotFound).
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+
+	// Usage is the tokens of the model call that chose this action. A call
+	// that chose several actions is counted on the first of them only, so
+	// the steps add up to the run's Usage (less calls that chose none).
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
+
+// TokenUsage counts the tokens of model calls, as reported by the API.
+type TokenUsage = agent.TokenUsage
+
+// ModelPrice is what a model costs, in US dollars per million tokens.
+type ModelPrice = agent.ModelPrice
+
+// Pricing maps model names to their prices. A model without an entry of its
+// own uses the entry with the longest name it starts with.
+type Pricing = agent.Pricing
+
+// DefaultPricing are the Gemini API's standard paid-tier prices. Update it
+// when prices change, or set Config.Pricing to override single models.
+var DefaultPricing = agent.DefaultPricing
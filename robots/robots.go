@@ -0,0 +1,340 @@
+// Package robots makes a browser a polite crawler: it fetches each site's
+// robots.txt, refuses the paths it disallows, and spaces page loads on each
+// host by the site's Crawl-delay or a minimum delay.
+//
+// Rules are matched as RFC 9309 describes: the group for the most specific
+// user agent applies, the longest matching rule wins, and Allow wins ties.
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent is the product token matched against robots.txt groups
+// when Config.UserAgent is empty.
+const DefaultUserAgent = "bua"
+
+// cacheTTL is how long a fetched robots.txt is used before it is fetched
+// again; errorTTL is how long an unreachable one disallows everything.
+const (
+	cacheTTL = 24 * time.Hour
+	errorTTL = time.Minute
+)
+
+// maxSize caps the robots.txt read from a site, as RFC 9309 allows.
+const maxSize = 500 << 10
+
+// Config configures robots.txt compliance.
+type Config struct {
+	// UserAgent is the product token that selects robots.txt groups, e.g.
+	// "mybot". Groups for "*" apply when none names it. Default:
+	// DefaultUserAgent.
+	UserAgent string
+
+	// MinDelay is the least time between page loads on one host, for sites
+	// whose robots.txt sets no longer Crawl-delay. Default: 0.
+	MinDelay time.Duration
+
+	// MaxDelay caps the Crawl-delay honored, so a site cannot stall a run.
+	// Default: 30s.
+	MaxDelay time.Duration
+
+	// HTTPClient fetches robots.txt files. Default: a client with a 10s
+	// timeout.
+	HTTPClient *http.Client
+}
+
+// Policy answers whether URLs may be loaded and paces loads per host. It is
+// safe for concurrent use.
+type Policy struct {
+	cfg Config
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is what a Policy knows about one scheme and host.
+type host struct {
+	// ready is closed once rules are fetched; rules is nil until then, and
+	// used until expires.
+	ready   chan struct{}
+	rules   *Rules
+	expires time.Time
+
+	// next is the earliest time the next page load may start.
+	next time.Time
+}
+
+// New returns a Policy for cfg.
+func New(cfg Config) *Policy {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = 30 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Policy{cfg: cfg, hosts: make(map[string]*host)}
+}
+
+// Allowed reports whether robots.txt allows loading rawURL. URLs that are
+// not http or https are always allowed.
+func (p *Policy) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return true, nil
+	}
+	rules, err := p.rules(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	return rules.Allowed(u.RequestURI()), nil
+}
+
+// Wait blocks until rawURL's host may be loaded again, and reserves the
+// slot. It returns early with ctx's error if ctx ends first.
+func (p *Policy) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	rules, err := p.rules(ctx, u)
+	if err != nil {
+		return err
+	}
+	delay := min(max(rules.CrawlDelay, p.cfg.MinDelay), p.cfg.MaxDelay)
+	if delay <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	h := p.hosts[origin(u)]
+	now := time.Now()
+	start := now
+	if h.next.After(now) {
+		start = h.next
+	}
+	h.next = start.Add(delay)
+	p.mu.Unlock()
+
+	if start.Equal(now) {
+		return nil
+	}
+	t := time.NewTimer(start.Sub(now))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rules returns the rules of u's host, fetching its robots.txt if needed.
+// Concurrent callers for one host share a single fetch.
+func (p *Policy) rules(ctx context.Context, u *url.URL) (*Rules, error) {
+	key := origin(u)
+
+	p.mu.Lock()
+	h, ok := p.hosts[key]
+	if ok && h.rules != nil && time.Now().After(h.expires) {
+		h.ready, h.rules = make(chan struct{}), nil
+		ok = false
+	}
+	if !ok {
+		if h == nil {
+			h = &host{ready: make(chan struct{})}
+			p.hosts[key] = h
+		}
+		ready := h.ready
+		p.mu.Unlock()
+
+		// Other callers wait on this fetch, so it must not end with ctx
+		rules, ttl := p.fetch(context.WithoutCancel(ctx), key)
+		p.mu.Lock()
+		h.rules, h.expires = rules, time.Now().Add(ttl)
+		p.mu.Unlock()
+		close(ready)
+		return rules, nil
+	}
+	ready := h.ready
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-ready:
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return h.rules, nil
+}
+
+// fetch gets the robots.txt of origin and how long to use it. As RFC 9309
+// asks, a missing file (4xx) allows everything, and an unreachable one (5xx
+// or network error) disallows everything until it is fetched again.
+func (p *Policy) fetch(ctx context.Context, origin string) (*Rules, time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return disallowAll, errorTTL
+	}
+	req.Header.Set("User-Agent", p.cfg.UserAgent)
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return disallowAll, errorTTL
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll, errorTTL
+	case resp.StatusCode >= 300:
+		// The client follows redirects; a longer chain counts as missing
+		return &Rules{}, cacheTTL
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+	if err != nil {
+		return disallowAll, errorTTL
+	}
+	return Parse(data, p.cfg.UserAgent), cacheTTL
+}
+
+// origin returns u's scheme and host, e.g. "https://example.com".
+func origin(u *url.URL) string {
+	return u.Scheme + "://" + strings.ToLower(u.Host)
+}
+
+// Rules are the robots.txt rules that apply to one user agent.
+type Rules struct {
+	rules []rule
+
+	// CrawlDelay is the time to leave between page loads (0 if unset).
+	CrawlDelay time.Duration
+}
+
+// rule is one Allow or Disallow line.
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// disallowAll are the rules of an unreachable robots.txt.
+var disallowAll = &Rules{rules: []rule{{allow: false, pattern: "/"}}}
+
+// Parse returns the rules of a robots.txt file for userAgent: those of the
+// groups naming its product token, or else those of the "*" groups.
+func Parse(data []byte, userAgent string) *Rules {
+	userAgent = strings.ToLower(userAgent)
+
+	var (
+		named, wildcard Rules
+		foundNamed      bool
+		current         []*Rules // groups the lines being read belong to
+		inAgents        bool     // reading a group's user-agent lines
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+				inAgents = true
+			}
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				current = append(current, &wildcard)
+			case agent == userAgent:
+				current = append(current, &named)
+				foundNamed = true
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, r := range current {
+			switch key {
+			case "allow", "disallow":
+				if value != "" {
+					r.rules = append(r.rules, rule{allow: key == "allow", pattern: value})
+				}
+			case "crawl-delay":
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					r.CrawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if foundNamed {
+		return &named
+	}
+	return &wildcard
+}
+
+// Allowed reports whether the rules allow path, which may include a query.
+func (r *Rules) Allowed(path string) bool {
+	if path == "/robots.txt" {
+		return true
+	}
+	best, allow := -1, true
+	for _, rl := range r.rules {
+		if !match(rl.pattern, path) {
+			continue
+		}
+		if n := len(rl.pattern); n > best || (n == best && rl.allow) {
+			best, allow = n, rl.allow
+		}
+	}
+	return allow
+}
+
+// match reports whether a robots.txt pattern matches the start of path. "*"
+// matches any sequence of characters, and a trailing "$" anchors the end.
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		zone string
+		from string // in zone, "2006-01-02 15:04"
+		want string // RFC 3339, or "" for no activation
+	}{
+		{"every minute", "* * * * *", "UTC", "2026-01-01 10:00", "2026-01-01T10:01:00Z"},
+		{"step", "*/15 * * * *", "UTC", "2026-01-01 10:07", "2026-01-01T10:15:00Z"},
+		{"daily", "@daily", "UTC", "2026-01-01 10:00", "2026-01-02T00:00:00Z"},
+		{"month names", "0 9 1 jan,jul *", "UTC", "2026-02-01 00:00", "2026-07-01T09:00:00Z"},
+		{"dom or dow", "0 0 13 * fri", "UTC", "2026-02-01 00:00", "2026-02-06T00:00:00Z"},
+		{"sunday as 7", "0 0 * * 7", "UTC", "2026-03-02 00:00", "2026-03-08T00:00:00Z"},
+		{"never", "0 0 30 2 *", "UTC", "2026-01-01 00:00", ""},
+
+		// New York springs forward from 02:00 to 03:00 on 2026-03-08
+		{"skipped time", "30 2 * * *", "America/New_York", "2026-03-07 12:00", "2026-03-09T02:30:00-04:00"},
+		{"sunday before gap", "0 0 * * 7", "America/New_York", "2026-03-07 00:00", "2026-03-08T00:00:00-05:00"},
+		{"hour after gap", "0 3 * * *", "America/New_York", "2026-03-08 00:30", "2026-03-08T03:00:00-04:00"},
+		{"hourly across gap", "0 * * * *", "America/New_York", "2026-03-08 01:30", "2026-03-08T03:00:00-04:00"},
+		// and falls back from 02:00 to 01:00 on 2026-11-01
+		{"repeated time", "30 1 * * *", "America/New_York", "2026-10-31 12:00", "2026-11-01T01:30:00-04:00"},
+
+		// Santiago skips midnight on 2026-09-06
+		{"skipped midnight", "0 0 * * *", "America/Santiago", "2026-09-05 12:00", "2026-09-07T00:00:00-03:00"},
+		{"day after skipped midnight", "0 12 * * *", "America/Santiago", "2026-09-05 13:00", "2026-09-06T12:00:00-03:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.zone)
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			from, err := time.ParseInLocation("2006-01-02 15:04", tt.from, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.Next(from)
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want none", from, got)
+				}
+				return
+			}
+			want, err := time.Parse(time.RFC3339, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, want)
+			}
+		})
+	}
+}
+
+func TestCronNextAdvances(t *testing.T) {
+	// Every activation across a year of DST changes must come after the last
+	for _, zone := range []string{"America/New_York", "America/Santiago", "Europe/London", "Australia/Lord_Howe"} {
+		loc := mustLoad(t, zone)
+		for _, expr := range []string{"30 2 * * *", "0 0 * * *", "0 * * * *", "0 0 * * 7"} {
+			s, err := Parse(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at := time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+			end := at.AddDate(1, 0, 0)
+			for at.Before(end) {
+				next := s.Next(at)
+				if !next.After(at) {
+					t.Fatalf("%s in %s: Next(%s) = %s", expr, zone, at, next)
+				}
+				at = next
+			}
+		}
+	}
+}
+
+func TestEveryNext(t *testing.T) {
+	s, err := Parse("@every 90m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := s.Next(from), from.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+		"@every 10ms",
+		"@every soon",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
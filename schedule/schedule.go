@@ -0,0 +1,408 @@
+// Package schedule runs named browser tasks on cron schedules, turning bua
+// into a small monitoring or scraping daemon.
+//
+// Each run gets a fresh agent built from a shared base configuration plus
+// the task's own profile and preset. Failed runs are retried, and every
+// attempt is written to a Store.
+//
+// Basic usage:
+//
+//	s, err := schedule.New(schedule.Config{
+//		Agent: bua.Config{APIKey: key, Headless: true},
+//		Tasks: []schedule.Task{{
+//			Name: "price-check",
+//			Cron: "*/30 * * * *",
+//			URL:  "https://example.com/product",
+//			Task: "Extract the current price",
+//		}},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	s.Run(ctx) // blocks until ctx is canceled
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/logging"
+	"github.com/anxuanzi/bua/taskspec"
+	"gopkg.in/yaml.v3"
+)
+
+// Task is a named task definition.
+type Task struct {
+	// Name identifies the task in logs and stored results (required, unique).
+	Name string `yaml:"name" json:"name"`
+
+	// Cron is when to run, e.g. "*/15 * * * *", "@daily" or "@every 10m" (required).
+	Cron string `yaml:"cron" json:"cron"`
+
+	// Task is the natural-language instruction (required unless Spec is set).
+	Task string `yaml:"task" json:"task"`
+
+	// Spec is the path of a task spec file (see package taskspec) to run
+	// instead of Task. Fields set here override the spec's.
+	Spec string `yaml:"spec" json:"spec,omitempty"`
+
+	// Vars fill the spec's {{variables}}.
+	Vars map[string]string `yaml:"vars" json:"vars,omitempty"`
+
+	// URL is navigated to before the task runs (optional).
+	URL string `yaml:"url" json:"url,omitempty"`
+
+	// ProfileName overrides the base config's browser profile, so a task
+	// can reuse its own signed-in sessions. Default: the base profile.
+	ProfileName string `yaml:"profile" json:"profile,omitempty"`
+
+	// Preset overrides the base config's preset. Default: the base preset.
+	Preset bua.Preset `yaml:"preset" json:"preset,omitempty"`
+
+	// MaxSteps overrides the base config's step limit. Default: the base limit.
+	MaxSteps int `yaml:"max_steps" json:"max_steps,omitempty"`
+
+	// Timeout bounds each attempt. Default: 10m.
+	Timeout time.Duration `yaml:"timeout" json:"timeout,omitempty"`
+
+	// Retries is how many times a failed attempt is retried. Default: 0.
+	Retries int `yaml:"retries" json:"retries,omitempty"`
+
+	// RetryDelay is the wait before the first retry; it doubles after each
+	// further failure. Default: 30s.
+	RetryDelay time.Duration `yaml:"retry_delay" json:"retry_delay,omitempty"`
+}
+
+// Record is the outcome of one attempt of a scheduled task.
+type Record struct {
+	Task       string      `json:"task"`
+	Attempt    int         `json:"attempt"`
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt time.Time   `json:"finished_at"`
+	Success    bool        `json:"success"`
+	Error      string      `json:"error,omitempty"`
+	Result     *bua.Result `json:"result,omitempty"`
+}
+
+// Config configures a Scheduler.
+type Config struct {
+	// Agent is the base configuration for every run.
+	Agent bua.Config
+
+	// Tasks are the task definitions.
+	Tasks []Task
+
+	// Store receives every attempt. Default: a DirStore in ~/.bua/schedule.
+	Store Store
+
+	// Location is the time zone cron expressions are evaluated in.
+	// Default: time.Local.
+	Location *time.Location
+
+	// OnRecord is called after every attempt (optional).
+	OnRecord func(Record)
+}
+
+// File is the YAML format read by Load.
+type File struct {
+	Tasks []Task `yaml:"tasks"`
+}
+
+// Load reads task definitions from a YAML file with a top-level "tasks" list.
+// Relative spec paths are resolved against the file's directory.
+func Load(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i, t := range f.Tasks {
+		if t.Spec != "" && !filepath.IsAbs(t.Spec) {
+			f.Tasks[i].Spec = filepath.Join(filepath.Dir(path), t.Spec)
+		}
+	}
+	return f.Tasks, nil
+}
+
+// Scheduler runs tasks on their schedules.
+type Scheduler struct {
+	config    Config
+	tasks     []Task
+	schedules map[string]Schedule
+	specs     map[string]*taskspec.Task
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// New validates the task definitions and returns a Scheduler.
+func New(cfg Config) (*Scheduler, error) {
+	if len(cfg.Tasks) == 0 {
+		return nil, errors.New("schedule: no tasks")
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	if cfg.Store == nil {
+		store, err := DefaultStore()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Store = store
+	}
+
+	s := &Scheduler{
+		config:    cfg,
+		schedules: make(map[string]Schedule),
+		specs:     make(map[string]*taskspec.Task),
+		running:   make(map[string]bool),
+	}
+
+	for _, t := range cfg.Tasks {
+		if t.Spec != "" {
+			if err := s.loadSpec(&t); err != nil {
+				return nil, fmt.Errorf("schedule: task %q: %w", t.Name, err)
+			}
+		}
+
+		switch {
+		case t.Name == "":
+			return nil, errors.New("schedule: task name is required")
+		case strings.TrimSpace(t.Task) == "":
+			return nil, fmt.Errorf("schedule: task %q: task is required", t.Name)
+		case s.schedules[t.Name] != nil:
+			return nil, fmt.Errorf("schedule: duplicate task name %q", t.Name)
+		}
+
+		sched, err := Parse(t.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule: task %q: %w", t.Name, err)
+		}
+		if t.Timeout <= 0 {
+			t.Timeout = 10 * time.Minute
+		}
+		if t.RetryDelay <= 0 {
+			t.RetryDelay = 30 * time.Second
+		}
+
+		s.schedules[t.Name] = sched
+		s.tasks = append(s.tasks, t)
+	}
+
+	return s, nil
+}
+
+// loadSpec renders t's spec file and fills in the fields t leaves empty.
+func (s *Scheduler) loadSpec(t *Task) error {
+	spec, err := taskspec.Load(t.Spec)
+	if err != nil {
+		return err
+	}
+	rendered, err := spec.Render(t.Vars)
+	if err != nil {
+		return err
+	}
+
+	if t.Name == "" {
+		t.Name = rendered.Name
+	}
+	if t.Task == "" {
+		t.Task = rendered.Prompt
+	}
+	if t.URL == "" {
+		t.URL = rendered.URL
+	}
+	if t.Timeout == 0 {
+		t.Timeout = rendered.Timeout
+	}
+	s.specs[t.Name] = rendered
+	return nil
+}
+
+// Tasks returns the task definitions with defaults applied.
+func (s *Scheduler) Tasks() []Task {
+	return append([]Task(nil), s.tasks...)
+}
+
+// Next returns when the named task runs next, or the zero time if never.
+func (s *Scheduler) Next(name string) time.Time {
+	sched := s.schedules[name]
+	if sched == nil {
+		return time.Time{}
+	}
+	return sched.Next(time.Now().In(s.config.Location))
+}
+
+// Run starts every task on its schedule and blocks until ctx is canceled
+// and running tasks have finished. A run that is still going when its next
+// activation comes up is not started twice; that activation is skipped.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, t := range s.tasks {
+		wg.Add(1)
+		go func(t Task) {
+			defer wg.Done()
+			s.loop(ctx, t)
+		}(t)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// RunNow runs the named task immediately, with retries, and returns its last attempt.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Record, error) {
+	for _, t := range s.tasks {
+		if t.Name == name {
+			return s.execute(ctx, t), nil
+		}
+	}
+	return Record{}, fmt.Errorf("schedule: unknown task %q", name)
+}
+
+// loop waits for each activation of t and runs it in the background.
+func (s *Scheduler) loop(ctx context.Context, t Task) {
+	sched := s.schedules[t.Name]
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		next := sched.Next(time.Now().In(s.config.Location))
+		if next.IsZero() {
+			s.log().Debug("No further activations", "task", t.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		busy := s.running[t.Name]
+		s.running[t.Name] = true
+		s.mu.Unlock()
+		if busy {
+			s.log().Debug("Still running, skipping activation", "task", t.Name, "activation", next.Format(time.RFC3339))
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				s.mu.Lock()
+				s.running[t.Name] = false
+				s.mu.Unlock()
+			}()
+			s.execute(ctx, t)
+		}()
+	}
+}
+
+// execute runs t, retrying failures with exponential backoff, and returns the last attempt.
+func (s *Scheduler) execute(ctx context.Context, t Task) Record {
+	delay := t.RetryDelay
+	var rec Record
+	for attempt := 1; attempt <= t.Retries+1; attempt++ {
+		rec = s.attempt(ctx, t, attempt)
+
+		if err := s.config.Store.Save(rec); err != nil {
+			s.log().Warn("Failed to store result", "task", t.Name, "err", err)
+		}
+		if s.config.OnRecord != nil {
+			s.config.OnRecord(rec)
+		}
+
+		if rec.Success || attempt > t.Retries {
+			break
+		}
+		s.log().Debug("Attempt failed, retrying", "task", t.Name, "attempt", attempt, "err", rec.Error, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return rec
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return rec
+}
+
+// attempt runs t once on a fresh agent.
+func (s *Scheduler) attempt(ctx context.Context, t Task, attempt int) Record {
+	rec := Record{Task: t.Name, Attempt: attempt, StartedAt: time.Now()}
+
+	result, err := s.runTask(ctx, t)
+	rec.FinishedAt = time.Now()
+	if err != nil {
+		rec.Error = err.Error()
+		return rec
+	}
+
+	rec.Result = result
+	rec.Success = result.Success
+	rec.Error = result.Error
+	return rec
+}
+
+// runTask starts an agent for t, optionally navigates to its URL, and runs it,
+// checking the data against its spec's schema if it has one.
+func (s *Scheduler) runTask(ctx context.Context, t Task) (*bua.Result, error) {
+	cfg := s.config.Agent
+	var opts bua.RunOptions
+	if spec := s.specs[t.Name]; spec != nil {
+		spec.Apply(&cfg)
+		opts.Schema = spec.Schema
+	}
+	if t.ProfileName != "" {
+		cfg.ProfileName = t.ProfileName
+	}
+	if t.Preset != "" {
+		cfg.Preset = t.Preset
+	}
+	if t.MaxSteps > 0 {
+		cfg.MaxSteps = t.MaxSteps
+	}
+
+	agent, err := bua.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer agent.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	if err := agent.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	if t.URL != "" {
+		if err := agent.Navigate(ctx, t.URL); err != nil {
+			return nil, fmt.Errorf("failed to navigate to %s: %w", t.URL, err)
+		}
+	}
+
+	return agent.RunWithOptions(ctx, t.Task, opts)
+}
+
+// log returns the scheduler's logger: the base config's Logger, or console
+// output when it has Debug set.
+func (s *Scheduler) log() *slog.Logger {
+	return logging.Component(logging.Or(s.config.Agent.Logger, s.config.Agent.Debug), "Schedule")
+}
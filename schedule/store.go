@@ -0,0 +1,98 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Store persists the outcome of scheduled runs.
+type Store interface {
+	Save(rec Record) error
+}
+
+// DirStore writes each record as JSON to <Dir>/<task>/<started>-<attempt>.json.
+type DirStore struct {
+	Dir string
+}
+
+// DefaultStore returns a DirStore in ~/.bua/schedule.
+func DefaultStore() (*DirStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &DirStore{Dir: filepath.Join(home, ".bua", "schedule")}, nil
+}
+
+// Save writes rec to disk.
+func (d *DirStore) Save(rec Record) error {
+	dir := filepath.Join(d.Dir, safeName(rec.Task))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create result directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%d.json", rec.StartedAt.UTC().Format("20060102T150405.000Z"), rec.Attempt)
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// List returns the most recent records of a task, newest first. A limit of
+// 0 returns all of them.
+func (d *DirStore) List(task string, limit int) ([]Record, error) {
+	dir := filepath.Join(d.Dir, safeName(task))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read result directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	// Names start with a UTC timestamp, so they sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+
+	records := make([]Record, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// safeName makes a task name usable as a directory name.
+func safeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
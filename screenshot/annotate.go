@@ -9,8 +9,30 @@ import (
 	"image/jpeg"
 	"image/png"
 	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// LabelContent selects what annotation labels show.
+type LabelContent string
+
+const (
+	// LabelIndex shows the element index alone, e.g. "12".
+	LabelIndex LabelContent = ""
+
+	// LabelRole adds the element's role, or its tag name, e.g. "12 BUTTON".
+	LabelRole LabelContent = "role"
+
+	// LabelName adds the element's accessible name, e.g. "12 SIGN IN".
+	LabelName LabelContent = "name"
+)
+
+// defaultMaxLabelLength truncates role and name labels when
+// AnnotationConfig.MaxLabelLength is 0.
+const defaultMaxLabelLength = 24
+
 // AnnotationConfig configures how annotations are drawn.
 type AnnotationConfig struct {
 	// BorderWidth is the width of bounding box borders in pixels.
@@ -25,6 +47,26 @@ type AnnotationConfig struct {
 	// ShowLabelsOnlyForUnlabeled shows labels only for elements without text.
 	ShowLabelsOnlyForUnlabeled bool
 
+	// Labels selects what labels show: the index alone (LabelIndex), or
+	// with the element's role (LabelRole) or accessible name (LabelName).
+	Labels LabelContent
+
+	// MaxLabelLength truncates role and name labels to this many characters
+	// (0 = 24). Labels are drawn in a built-in ASCII font; other characters
+	// show as "?".
+	MaxLabelLength int
+
+	// MinElementSize skips elements narrower or shorter than this many
+	// pixels, which mostly clutter dense pages (0 = draw all).
+	MinElementSize float64
+
+	// InteractiveOnly skips elements that are not interactive.
+	InteractiveOnly bool
+
+	// RoleColors sets box colors by ARIA role or tag name, e.g. "checkbox"
+	// or "select". They take precedence over the colors below.
+	RoleColors map[string]color.RGBA
+
 	// Colors for different element types.
 	LinkColor      color.RGBA
 	ButtonColor    color.RGBA
@@ -65,7 +107,8 @@ func Annotate(imgData []byte, elementMap ElementMapInterface, cfg AnnotationConf
 
 	// Convert to RGBA for drawing
 	bounds := img.Bounds()
-	rgba := image.NewRGBA(bounds)
+	rgba := getRGBA(bounds)
+	defer putRGBA(rgba)
 	draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
 
 	// Draw annotations for each element
@@ -74,6 +117,12 @@ func Annotate(imgData []byte, elementMap ElementMapInterface, cfg AnnotationConf
 		if !el.GetIsVisible() || bbox.GetIsEmpty() {
 			continue
 		}
+		if bbox.GetWidth() < cfg.MinElementSize || bbox.GetHeight() < cfg.MinElementSize {
+			continue
+		}
+		if cfg.InteractiveOnly && !el.GetIsInteractive() {
+			continue
+		}
 
 		// Get color based on element type
 		boxColor := getElementColorFromInfo(el, cfg)
@@ -87,27 +136,62 @@ func Annotate(imgData []byte, elementMap ElementMapInterface, cfg AnnotationConf
 			if cfg.ShowLabelsOnlyForUnlabeled && el.GetText() != "" {
 				continue
 			}
-			drawIndexLabelFromInfo(rgba, el.GetIndex(), bbox, cfg)
+			drawLabelFromInfo(rgba, labelText(el, cfg), bbox, cfg)
 		}
 	}
 
 	// Encode back to original format
-	var buf bytes.Buffer
+	buf := getBuffer()
 	switch format {
 	case "png":
-		err = png.Encode(&buf, rgba)
+		err = png.Encode(buf, rgba)
 	default:
-		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: 85})
+		err = jpeg.Encode(buf, rgba, &jpeg.Options{Quality: 85})
 	}
+	data := putBuffer(buf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode annotated image: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	return data, nil
+}
+
+// labelText returns the label of an element, per cfg.Labels.
+func labelText(el ElementInfo, cfg AnnotationConfig) string {
+	label := strconv.Itoa(el.GetIndex())
+
+	var extra string
+	switch cfg.Labels {
+	case LabelRole:
+		extra = el.GetRole()
+		if extra == "" {
+			extra = el.GetTagName()
+		}
+	case LabelName:
+		extra = el.GetName()
+	}
+	if extra == "" {
+		return label
+	}
+
+	limit := cfg.MaxLabelLength
+	if limit == 0 {
+		limit = defaultMaxLabelLength
+	}
+	if runes := []rune(extra); len(runes) > limit {
+		extra = string(runes[:limit-1]) + "."
+	}
+	return label + " " + extra
 }
 
 // getElementColorFromInfo returns the appropriate color for an element type using the interface.
 func getElementColorFromInfo(el ElementInfo, cfg AnnotationConfig) color.RGBA {
+	if c, ok := cfg.RoleColors[el.GetRole()]; ok && el.GetRole() != "" {
+		return c
+	}
+	if c, ok := cfg.RoleColors[el.GetTagName()]; ok {
+		return c
+	}
 	switch el.GetTagName() {
 	case "a":
 		return cfg.LinkColor
@@ -172,17 +256,17 @@ func drawBoundingBoxFromInfo(img *image.RGBA, bbox BoundingBoxInfo, c color.RGBA
 	}
 }
 
-// drawIndexLabelFromInfo draws the element index at the top center of the bounding box using the interface.
-// Uses a simple built-in digit renderer for minimal dependencies.
-func drawIndexLabelFromInfo(img *image.RGBA, index int, bbox BoundingBoxInfo, cfg AnnotationConfig) {
-	label := fmt.Sprintf("%d", index)
+// drawLabelFromInfo draws a label at the top center of the bounding box using the interface.
+// Uses a simple built-in glyph renderer for minimal dependencies.
+func drawLabelFromInfo(img *image.RGBA, label string, bbox BoundingBoxInfo, cfg AnnotationConfig) {
+	label = strings.Join(strings.Fields(label), " ")
 	bounds := img.Bounds()
 
 	// Calculate label position (top center of bounding box)
 	charWidth := cfg.FontSize * 7 / 12 // Approximate char width
 	charHeight := cfg.FontSize
 	padding := 2
-	labelWidth := len(label)*charWidth + padding*2
+	labelWidth := utf8.RuneCountInString(label)*charWidth + padding*2
 	labelHeight := charHeight + padding*2
 
 	// Position at top center of bounding box
@@ -215,34 +299,66 @@ func drawIndexLabelFromInfo(img *image.RGBA, index int, bbox BoundingBoxInfo, cf
 	textX := labelX + padding
 	textY := labelY + padding
 	for _, char := range label {
-		if char >= '0' && char <= '9' {
-			drawDigit(img, int(char-'0'), textX, textY, charWidth, charHeight, cfg.LabelTextColor)
-		}
+		drawGlyph(img, char, textX, textY, charWidth, charHeight, cfg.LabelTextColor)
 		textX += charWidth
 	}
 }
 
-// drawDigit draws a single digit using a simple 5x7 pixel pattern.
-func drawDigit(img *image.RGBA, digit int, x, y, width, height int, c color.RGBA) {
-	// 5x7 bitmap patterns for digits 0-9
-	patterns := [][]string{
-		{"01110", "10001", "10001", "10001", "10001", "10001", "01110"}, // 0
-		{"00100", "01100", "00100", "00100", "00100", "00100", "01110"}, // 1
-		{"01110", "10001", "00001", "00110", "01000", "10000", "11111"}, // 2
-		{"01110", "10001", "00001", "00110", "00001", "10001", "01110"}, // 3
-		{"00010", "00110", "01010", "10010", "11111", "00010", "00010"}, // 4
-		{"11111", "10000", "11110", "00001", "00001", "10001", "01110"}, // 5
-		{"01110", "10000", "10000", "11110", "10001", "10001", "01110"}, // 6
-		{"11111", "00001", "00010", "00100", "01000", "01000", "01000"}, // 7
-		{"01110", "10001", "10001", "01110", "10001", "10001", "01110"}, // 8
-		{"01110", "10001", "10001", "01111", "00001", "00001", "01110"}, // 9
-	}
+// glyphs are 5x7 pixel patterns of the characters labels can show.
+// Lowercase letters are drawn as uppercase.
+var glyphs = map[rune][7]string{
+	'0': {"01110", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'1': {"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	'2': {"01110", "10001", "00001", "00110", "01000", "10000", "11111"},
+	'3': {"01110", "10001", "00001", "00110", "00001", "10001", "01110"},
+	'4': {"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	'5': {"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	'6': {"01110", "10000", "10000", "11110", "10001", "10001", "01110"},
+	'7': {"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	'8': {"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	'9': {"01110", "10001", "10001", "01111", "00001", "00001", "01110"},
+	'A': {"01110", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'B': {"11110", "10001", "10001", "11110", "10001", "10001", "11110"},
+	'C': {"01110", "10001", "10000", "10000", "10000", "10001", "01110"},
+	'D': {"11110", "10001", "10001", "10001", "10001", "10001", "11110"},
+	'E': {"11111", "10000", "10000", "11110", "10000", "10000", "11111"},
+	'F': {"11111", "10000", "10000", "11110", "10000", "10000", "10000"},
+	'G': {"01110", "10001", "10000", "10111", "10001", "10001", "01111"},
+	'H': {"10001", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'I': {"01110", "00100", "00100", "00100", "00100", "00100", "01110"},
+	'J': {"00111", "00010", "00010", "00010", "00010", "10010", "01100"},
+	'K': {"10001", "10010", "10100", "11000", "10100", "10010", "10001"},
+	'L': {"10000", "10000", "10000", "10000", "10000", "10000", "11111"},
+	'M': {"10001", "11011", "10101", "10101", "10001", "10001", "10001"},
+	'N': {"10001", "10001", "11001", "10101", "10011", "10001", "10001"},
+	'O': {"01110", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'P': {"11110", "10001", "10001", "11110", "10000", "10000", "10000"},
+	'Q': {"01110", "10001", "10001", "10001", "10101", "10010", "01101"},
+	'R': {"11110", "10001", "10001", "11110", "10100", "10010", "10001"},
+	'S': {"01111", "10000", "10000", "01110", "00001", "00001", "11110"},
+	'T': {"11111", "00100", "00100", "00100", "00100", "00100", "00100"},
+	'U': {"10001", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'V': {"10001", "10001", "10001", "10001", "10001", "01010", "00100"},
+	'W': {"10001", "10001", "10001", "10101", "10101", "10101", "01010"},
+	'X': {"10001", "10001", "01010", "00100", "01010", "10001", "10001"},
+	'Y': {"10001", "10001", "01010", "00100", "00100", "00100", "00100"},
+	'Z': {"11111", "00001", "00010", "00100", "01000", "10000", "11111"},
+	' ': {"00000", "00000", "00000", "00000", "00000", "00000", "00000"},
+	':': {"00000", "01100", "01100", "00000", "01100", "01100", "00000"},
+	'-': {"00000", "00000", "00000", "11111", "00000", "00000", "00000"},
+	'.': {"00000", "00000", "00000", "00000", "00000", "01100", "01100"},
+	'_': {"00000", "00000", "00000", "00000", "00000", "00000", "11111"},
+	'/': {"00001", "00010", "00010", "00100", "01000", "01000", "10000"},
+	'?': {"01110", "10001", "00001", "00010", "00100", "00000", "00100"},
+}
 
-	if digit < 0 || digit > 9 {
-		return
+// drawGlyph draws a single character, scaled from its 5x7 pixel pattern.
+// Characters without a glyph are drawn as "?".
+func drawGlyph(img *image.RGBA, char rune, x, y, width, height int, c color.RGBA) {
+	pattern, ok := glyphs[unicode.ToUpper(char)]
+	if !ok {
+		pattern = glyphs['?']
 	}
-
-	pattern := patterns[digit]
 	scaleX := float64(width) / 5.0
 	scaleY := float64(height) / 7.0
 
@@ -0,0 +1,56 @@
+package screenshot
+
+import (
+	"bytes"
+	"image"
+	"sync"
+)
+
+// maxPooledBytes is the largest buffer or image kept for reuse, so one
+// full-page screenshot does not pin its memory for good.
+const maxPooledBytes = 32 << 20
+
+var (
+	// bufferPool holds the buffers screenshots are encoded into.
+	bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+	// rgbaPool holds the images screenshots are annotated on.
+	rgbaPool sync.Pool
+)
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool and a copy of its contents, which stays
+// valid after buf is reused.
+func putBuffer(buf *bytes.Buffer) []byte {
+	data := bytes.Clone(buf.Bytes())
+	if buf.Cap() <= maxPooledBytes {
+		bufferPool.Put(buf)
+	}
+	return data
+}
+
+// getRGBA returns an image of the given bounds, reusing a pooled one whose
+// pixels fit. Its pixels are not cleared; draw over all of them.
+func getRGBA(bounds image.Rectangle) *image.RGBA {
+	n := 4 * bounds.Dx() * bounds.Dy()
+	if img, ok := rgbaPool.Get().(*image.RGBA); ok && cap(img.Pix) >= n {
+		img.Pix = img.Pix[:n]
+		img.Stride = 4 * bounds.Dx()
+		img.Rect = bounds
+		return img
+	}
+	return image.NewRGBA(bounds)
+}
+
+// putRGBA returns img to the pool.
+func putRGBA(img *image.RGBA) {
+	if cap(img.Pix) <= maxPooledBytes {
+		rgbaPool.Put(img)
+	}
+}
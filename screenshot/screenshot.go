@@ -8,6 +8,7 @@ import (
 	"image"
 	"image/jpeg"
 	"image/png"
+	"math"
 	"strings"
 	"time"
 
@@ -33,6 +34,8 @@ type ElementInfo interface {
 	GetText() string
 	GetBoundingBox() BoundingBoxInfo
 	GetIsVisible() bool
+	GetName() string
+	GetIsInteractive() bool
 }
 
 // ElementMapInterface defines the interface for element maps used in annotations.
@@ -112,6 +115,22 @@ func LLMOptions() Options {
 	}
 }
 
+// DebugOptions returns options for full-quality diagnostic screenshots.
+// Captures lossless PNG at native resolution and never skips blank pages,
+// so the image reflects exactly what the browser showed at the time.
+func DebugOptions() Options {
+	return Options{
+		MaxWidth:         3840,
+		Format:           "png",
+		FullPage:         false,
+		WaitForLoad:      false,
+		WaitForIdle:      false,
+		StabilityTimeout: 200 * time.Millisecond,
+		SkipBlankPages:   false,
+		ValidateContent:  false,
+	}
+}
+
 // Capture takes a screenshot of the page and returns compressed bytes.
 // It implements proper page readiness checks following browser-use best practices.
 func Capture(ctx context.Context, page *rod.Page, opts Options) ([]byte, error) {
@@ -148,15 +167,42 @@ func Capture(ctx context.Context, page *rod.Page, opts Options) ([]byte, error)
 		format = proto.PageCaptureScreenshotFormatPng
 	}
 
-	// Capture screenshot
-	data, err := page.Screenshot(opts.FullPage, &proto.PageCaptureScreenshot{
+	// Capture screenshot, scaled and encoded by the browser
+	req := &proto.PageCaptureScreenshot{
 		Format:  format,
 		Quality: &opts.Quality,
-	})
+	}
+	var data []byte
+	var err error
+	if clip := captureClip(page, opts); clip != nil {
+		req.Clip = clip
+		req.CaptureBeyondViewport = opts.FullPage
+		var shot *proto.PageCaptureScreenshotResult
+		if shot, err = req.Call(page); err == nil {
+			data = shot.Data
+		}
+	} else {
+		data, err = page.Screenshot(opts.FullPage, req)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("screenshot capture failed: %w", err)
 	}
 
+	// The browser scaled the image already; decode it only to validate it
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && cfg.Width <= opts.MaxWidth {
+		if !opts.ValidateContent {
+			return data, nil
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+		}
+		if isEmptyImage(img) {
+			return nil, ErrEmptyScreenshot
+		}
+		return data, nil
+	}
+
 	// Decode image for processing
 	img, imgFormat, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
@@ -179,25 +225,57 @@ func Capture(ctx context.Context, page *rod.Page, opts Options) ([]byte, error)
 	}
 
 	// Encode to output format
-	var buf bytes.Buffer
+	buf := getBuffer()
 	switch opts.Format {
 	case "jpeg":
-		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: opts.Quality})
 	case "png":
-		err = png.Encode(&buf, img)
+		err = png.Encode(buf, img)
 	default:
 		if imgFormat == "png" {
-			err = png.Encode(&buf, img)
+			err = png.Encode(buf, img)
 		} else {
-			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+			err = jpeg.Encode(buf, img, &jpeg.Options{Quality: opts.Quality})
 		}
 	}
+	data = putBuffer(buf)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode screenshot: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	return data, nil
+}
+
+// captureClip returns the clip that has the browser capture the viewport,
+// or the whole page for opts.FullPage, scaled down to opts.MaxWidth, or nil
+// if the page's layout is unknown.
+func captureClip(page *rod.Page, opts Options) *proto.PageViewport {
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil || metrics.CSSVisualViewport == nil || metrics.CSSVisualViewport.ClientWidth <= 0 {
+		return nil
+	}
+	css := metrics.CSSVisualViewport
+
+	clip := &proto.PageViewport{X: css.PageX, Y: css.PageY, Width: css.ClientWidth, Height: css.ClientHeight, Scale: 1}
+	if opts.FullPage {
+		if metrics.CSSContentSize == nil {
+			return nil
+		}
+		clip.X, clip.Y = 0, 0
+		clip.Width, clip.Height = metrics.CSSContentSize.Width, metrics.CSSContentSize.Height
+	}
+
+	// Screenshots are in device pixels
+	dpr := 1.0
+	if vv := metrics.VisualViewport; vv != nil && vv.ClientWidth > 0 {
+		dpr = vv.ClientWidth / css.ClientWidth
+	}
+	if width := clip.Width * dpr; width > float64(opts.MaxWidth) {
+		// Round down, so rounding the image size does not exceed MaxWidth
+		clip.Scale = math.Floor(float64(opts.MaxWidth)/width*1e4) / 1e4
+	}
+	return clip
 }
 
 // isBlankPage checks if the page is a blank page (about:blank or empty).
@@ -401,13 +479,13 @@ func CaptureElement(ctx context.Context, page *rod.Page, selector string, opts O
 			newHeight := uint(float64(bounds.Dy()) * ratio)
 			img = resize.Resize(uint(opts.MaxWidth), newHeight, img, resize.Lanczos3)
 
-			var buf bytes.Buffer
+			buf := getBuffer()
 			if imgFormat == "png" || opts.Format == "png" {
-				png.Encode(&buf, img)
+				png.Encode(buf, img)
 			} else {
-				jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+				jpeg.Encode(buf, img, &jpeg.Options{Quality: opts.Quality})
 			}
-			return buf.Bytes(), nil
+			return putBuffer(buf), nil
 		}
 	}
 
@@ -532,7 +610,11 @@ func ForLLMSafeWithAnnotations(ctx context.Context, page *rod.Page, elementMap E
 	if maxWidth > 0 {
 		opts.MaxWidth = maxWidth
 	}
+	return CaptureSafeWithAnnotations(ctx, page, elementMap, opts)
+}
 
+// CaptureSafeWithAnnotations is CaptureWithAnnotations, returning nil for blank pages.
+func CaptureSafeWithAnnotations(ctx context.Context, page *rod.Page, elementMap ElementMapInterface, opts AnnotatedOptions) ([]byte, error) {
 	data, err := CaptureWithAnnotations(ctx, page, elementMap, opts)
 	if err != nil {
 		// Return nil data for expected blank/empty conditions
@@ -0,0 +1,457 @@
+// Package server exposes bua as a REST microservice.
+//
+// Endpoints:
+//
+//	POST   /tasks                          submit a task (returns 202 with the task)
+//	GET    /tasks                          list tasks
+//	GET    /tasks/{id}                     task status, steps, and result
+//	DELETE /tasks/{id}                     cancel a queued or running task
+//	GET    /tasks/{id}/events              SSE stream of steps and status changes
+//	GET    /tasks/{id}/screenshots         list screenshot indices
+//	GET    /tasks/{id}/screenshots/{n}     download a screenshot
+//
+// Each task runs in its own browser; Config.MaxConcurrent bounds how many run at once.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/taskspec"
+)
+
+// Config configures the server.
+type Config struct {
+	// Agent is the base agent configuration for every task.
+	// ScreenshotDir is replaced with a per-task directory under DataDir.
+	Agent bua.Config
+
+	// MaxConcurrent is the maximum number of tasks running at once. Default: 2.
+	MaxConcurrent int
+
+	// DefaultTimeout bounds tasks that don't specify a timeout. Default: 10m.
+	DefaultTimeout time.Duration
+
+	// DataDir is where per-task screenshots are stored. Default: ./bua-data.
+	DataDir string
+
+	// MaxTasks bounds how many tasks the server remembers; past it, the
+	// oldest finished tasks are forgotten as new ones arrive. Default: 1000.
+	MaxTasks int
+
+	// TaskTTL is how long a finished task stays listed. Default: 24h.
+	// Forgotten tasks' screenshots stay in DataDir.
+	TaskTTL time.Duration
+
+	// APIToken, if set, is required as "Authorization: Bearer <token>".
+	APIToken string
+}
+
+// Server runs browser tasks submitted over HTTP.
+type Server struct {
+	config Config
+	sem    chan struct{}
+	mux    *http.ServeMux
+
+	mu    sync.RWMutex
+	tasks map[string]*task
+	order []string
+}
+
+// New creates a server.
+func New(cfg Config) *Server {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 2
+	}
+	if cfg.DefaultTimeout <= 0 {
+		cfg.DefaultTimeout = 10 * time.Minute
+	}
+	if cfg.DataDir == "" {
+		cfg.DataDir = "bua-data"
+	}
+	if cfg.MaxTasks <= 0 {
+		cfg.MaxTasks = 1000
+	}
+	if cfg.TaskTTL <= 0 {
+		cfg.TaskTTL = 24 * time.Hour
+	}
+
+	s := &Server{
+		config: cfg,
+		sem:    make(chan struct{}, cfg.MaxConcurrent),
+		mux:    http.NewServeMux(),
+		tasks:  make(map[string]*task),
+	}
+
+	s.mux.HandleFunc("POST /tasks", s.handleCreate)
+	s.mux.HandleFunc("GET /tasks", s.handleList)
+	s.mux.HandleFunc("GET /tasks/{id}", s.handleGet)
+	s.mux.HandleFunc("DELETE /tasks/{id}", s.handleCancel)
+	s.mux.HandleFunc("GET /tasks/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("GET /tasks/{id}/screenshots", s.handleScreenshots)
+	s.mux.HandleFunc("GET /tasks/{id}/screenshots/{n}", s.handleScreenshot)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.config.APIToken != "" {
+		auth := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(auth, "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.APIToken)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Submit queues a task and returns its ID. Used by POST /tasks.
+func (s *Server) Submit(req TaskRequest) (string, error) {
+	var spec *taskspec.Task
+	if req.Spec != nil {
+		// Secret references read the server's environment and files, which
+		// API callers must not be able to reach
+		if len(req.Spec.Secrets) > 0 {
+			return "", fmt.Errorf("spec secrets are not accepted over the API; configure Agent.Secrets on the server instead")
+		}
+		// Profiles are directories on the server and presets choose its
+		// model and cost, so only the server configures them. The step
+		// limit may be set, as the request's max_steps can set it anyway
+		if req.Spec.Profile != "" {
+			return "", fmt.Errorf("spec profile is not accepted over the API; configure Agent.ProfileName on the server instead")
+		}
+		if req.Spec.Preset != "" {
+			return "", fmt.Errorf("spec preset is not accepted over the API; configure Agent.Preset on the server instead")
+		}
+		rendered, err := req.Spec.Render(req.Vars)
+		if err != nil {
+			return "", fmt.Errorf("invalid spec: %w", err)
+		}
+		spec = rendered
+		if req.Task == "" {
+			req.Task = rendered.Prompt
+		}
+		if req.URL == "" {
+			req.URL = rendered.URL
+		}
+		if req.Timeout == "" {
+			req.Timeout = req.Spec.Timeout
+		}
+		if req.MaxSteps == 0 {
+			req.MaxSteps = rendered.MaxSteps
+		}
+		// The rendered task carries everything needed; don't keep secret references around
+		req.Spec, req.Vars = nil, nil
+	}
+
+	if strings.TrimSpace(req.Task) == "" {
+		return "", fmt.Errorf("task is required")
+	}
+	timeout := s.config.DefaultTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("invalid timeout %q: %w", req.Timeout, err)
+		}
+		timeout = d
+	}
+
+	id := newTaskID()
+	t := newTask(id, req)
+	t.spec = spec
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.cancel = cancel
+
+	s.mu.Lock()
+	s.tasks[id] = t
+	s.order = append(s.order, id)
+	s.pruneLocked(time.Now())
+	s.mu.Unlock()
+
+	go s.execute(ctx, t)
+
+	return id, nil
+}
+
+// pruneLocked forgets finished tasks older than Config.TaskTTL, and the
+// oldest finished ones while more than Config.MaxTasks are kept. Queued and
+// running tasks are always kept. Must be called with s.mu held.
+func (s *Server) pruneLocked(now time.Time) {
+	excess := len(s.order) - s.config.MaxTasks
+	kept := s.order[:0]
+	for _, id := range s.order {
+		finished := s.tasks[id].finished()
+		if !finished.IsZero() && (excess > 0 || now.Sub(finished) > s.config.TaskTTL) {
+			delete(s.tasks, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	clear(s.order[len(kept):])
+	s.order = kept
+}
+
+// execute waits for a free slot and runs the task.
+func (s *Server) execute(ctx context.Context, t *task) {
+	defer t.cancel()
+
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	case <-ctx.Done():
+		t.mu.Lock()
+		t.err = ctx.Err().Error()
+		t.mu.Unlock()
+		t.setStatus(StatusCanceled)
+		return
+	}
+
+	t.setStatus(StatusRunning)
+
+	cfg := s.config.Agent
+	cfg.ScreenshotDir = filepath.Join(s.config.DataDir, t.id)
+	if t.spec != nil {
+		t.spec.Apply(&cfg)
+	}
+	if t.request.MaxSteps > 0 {
+		cfg.MaxSteps = t.request.MaxSteps
+	}
+	cfg.OnStep = t.addStep
+	cfg.Hooks.OnProgress = t.setProgress
+
+	var opts bua.RunOptions
+	if t.spec != nil {
+		opts.Schema = t.spec.Schema
+	}
+	result, err := runAgent(ctx, cfg, t.request, opts)
+
+	t.mu.Lock()
+	t.result = result
+	if err != nil {
+		t.err = err.Error()
+	} else if !result.Success {
+		t.err = result.Error
+	}
+	t.mu.Unlock()
+
+	switch {
+	case ctx.Err() == context.Canceled:
+		t.setStatus(StatusCanceled)
+	case err != nil || !result.Success:
+		t.setStatus(StatusFailed)
+	default:
+		t.setStatus(StatusSucceeded)
+	}
+}
+
+// runAgent starts a fresh agent for one task.
+func runAgent(ctx context.Context, cfg bua.Config, req TaskRequest, opts bua.RunOptions) (*bua.Result, error) {
+	agent, err := bua.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer agent.Close()
+
+	if err := agent.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+	if req.URL != "" {
+		if err := agent.Navigate(ctx, req.URL); err != nil {
+			return nil, fmt.Errorf("failed to navigate to %s: %w", req.URL, err)
+		}
+	}
+	return agent.RunWithOptions(ctx, req.Task, opts)
+}
+
+// Shutdown cancels all unfinished tasks.
+func (s *Server) Shutdown() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.tasks {
+		t.cancel()
+	}
+}
+
+func (s *Server) lookup(r *http.Request) (*task, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tasks[r.PathValue("id")]
+	return t, ok
+}
+
+// ---- Handlers ----
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req TaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+
+	id, err := s.Submit(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.RLock()
+	t := s.tasks[id]
+	s.mu.RUnlock()
+
+	w.Header().Set("Location", "/tasks/"+id)
+	writeJSON(w, http.StatusAccepted, t.view())
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	views := make([]TaskView, 0, len(s.order))
+	for _, id := range s.order {
+		v := s.tasks[id].view()
+		v.Steps = nil // Keep the listing small
+		views = append(views, v)
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t.view())
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	t.cancel()
+	writeJSON(w, http.StatusAccepted, t.view())
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	steps, status, events, unsubscribe := t.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Replay what happened before the client connected
+	for _, step := range steps {
+		writeEvent(w, taskEvent{Type: "step", Data: step})
+	}
+	writeEvent(w, taskEvent{Type: "status", Data: status})
+	flusher.Flush()
+
+	if events == nil {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			writeEvent(w, ev)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleScreenshots(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	type entry struct {
+		Index int    `json:"index"`
+		Name  string `json:"name"`
+		URL   string `json:"url"`
+	}
+	paths := t.screenshots()
+	entries := make([]entry, len(paths))
+	for i, p := range paths {
+		entries[i] = entry{Index: i, Name: filepath.Base(p), URL: fmt.Sprintf("/tasks/%s/screenshots/%d", t.id, i)}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.lookup(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	n, err := strconv.Atoi(r.PathValue("n"))
+	paths := t.screenshots()
+	if err != nil || n < 0 || n >= len(paths) {
+		writeError(w, http.StatusNotFound, "screenshot not found")
+		return
+	}
+
+	http.ServeFile(w, r, paths[n])
+}
+
+// ---- Helpers ----
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeEvent(w http.ResponseWriter, ev taskEvent) {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+}
+
+// newTaskID returns a random 16-character hex ID.
+func newTaskID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
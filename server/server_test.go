@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/taskspec"
+)
+
+func TestPruneForgetsOldFinishedTasks(t *testing.T) {
+	s := New(Config{MaxTasks: 3, TaskTTL: time.Hour})
+	now := time.Now()
+
+	add := func(id string, finished time.Time) {
+		tk := newTask(id, TaskRequest{Task: id})
+		tk.finishedAt = finished
+		if !finished.IsZero() {
+			tk.status = StatusSucceeded
+		}
+		s.tasks[id] = tk
+		s.order = append(s.order, id)
+	}
+	add("expired", now.Add(-2*time.Hour))
+	add("running", time.Time{})
+	add("old", now.Add(-time.Minute))
+	add("newer", now.Add(-time.Second))
+	add("queued", time.Time{})
+
+	s.pruneLocked(now)
+
+	// "expired" is past the TTL, "old" is the oldest finished task past MaxTasks
+	if got, want := fmt.Sprint(s.order), "[running newer queued]"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+	if len(s.tasks) != len(s.order) {
+		t.Errorf("%d tasks for %d ids", len(s.tasks), len(s.order))
+	}
+}
+
+func TestPruneKeepsUnfinishedTasks(t *testing.T) {
+	s := New(Config{MaxTasks: 1})
+	for i := range 3 {
+		id := fmt.Sprint(i)
+		s.tasks[id] = newTask(id, TaskRequest{Task: id})
+		s.order = append(s.order, id)
+	}
+
+	s.pruneLocked(time.Now())
+	if len(s.order) != 3 {
+		t.Errorf("order = %v, want all unfinished tasks kept", s.order)
+	}
+}
+
+func TestSubmitRejectsServerSettings(t *testing.T) {
+	s := New(Config{})
+
+	tests := []struct {
+		name string
+		spec taskspec.Spec
+	}{
+		{"secrets", taskspec.Spec{Task: "Look", Secrets: map[string]string{"key": "env:HOME"}}},
+		{"profile", taskspec.Spec{Task: "Look", Profile: "../.."}},
+		{"preset", taskspec.Spec{Task: "Look", Preset: bua.PresetMax}},
+	}
+	for _, tt := range tests {
+		if _, err := s.Submit(TaskRequest{Spec: &tt.spec}); err == nil || !strings.Contains(err.Error(), tt.name) {
+			t.Errorf("Submit() with a spec %s: error = %v", tt.name, err)
+		}
+	}
+	if len(s.tasks) != 0 {
+		t.Errorf("%d tasks queued, want none", len(s.tasks))
+	}
+}
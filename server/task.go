@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/taskspec"
+)
+
+// TaskStatus is the lifecycle state of a task.
+type TaskStatus string
+
+const (
+	StatusQueued    TaskStatus = "queued"
+	StatusRunning   TaskStatus = "running"
+	StatusSucceeded TaskStatus = "succeeded"
+	StatusFailed    TaskStatus = "failed"
+	StatusCanceled  TaskStatus = "canceled"
+)
+
+// done reports whether the status is terminal.
+func (s TaskStatus) done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// TaskRequest is the body of POST /tasks.
+type TaskRequest struct {
+	// Task is the natural-language instruction (required).
+	Task string `json:"task"`
+
+	// URL is navigated to before the task starts (optional).
+	URL string `json:"url,omitempty"`
+
+	// Timeout bounds the run, as a Go duration string (e.g., "5m").
+	Timeout string `json:"timeout,omitempty"`
+
+	// MaxSteps overrides the server's default step limit.
+	MaxSteps int `json:"max_steps,omitempty"`
+
+	// Spec is a task spec to render instead of Task; fields set above
+	// override the spec's. See package taskspec.
+	Spec *taskspec.Spec `json:"spec,omitempty"`
+
+	// Vars fill the spec's {{variables}}.
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+// TaskView is the JSON representation of a task.
+type TaskView struct {
+	ID         string        `json:"id"`
+	Status     TaskStatus    `json:"status"`
+	Task       string        `json:"task"`
+	URL        string        `json:"url,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	StartedAt  *time.Time    `json:"started_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Steps      []bua.Step    `json:"steps"`
+	Progress   *bua.Progress `json:"progress,omitempty"`
+	Result     *bua.Result   `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// taskEvent is pushed to SSE subscribers.
+type taskEvent struct {
+	Type string `json:"type"` // "step", "progress" or "status"
+	Data any    `json:"data"`
+}
+
+// task tracks one submitted run.
+type task struct {
+	id      string
+	request TaskRequest
+	spec    *taskspec.Task
+	cancel  context.CancelFunc
+
+	mu         sync.Mutex
+	status     TaskStatus
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	steps      []bua.Step
+	progress   *bua.Progress
+	result     *bua.Result
+	err        string
+	subs       map[int]chan taskEvent
+	nextSub    int
+}
+
+func newTask(id string, req TaskRequest) *task {
+	return &task{
+		id:        id,
+		request:   req,
+		status:    StatusQueued,
+		createdAt: time.Now(),
+		subs:      make(map[int]chan taskEvent),
+	}
+}
+
+// view returns a snapshot of the task for JSON encoding.
+func (t *task) view() TaskView {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	v := TaskView{
+		ID:        t.id,
+		Status:    t.status,
+		Task:      t.request.Task,
+		URL:       t.request.URL,
+		CreatedAt: t.createdAt,
+		Steps:     append([]bua.Step(nil), t.steps...),
+		Progress:  t.progress,
+		Result:    t.result,
+		Error:     t.err,
+	}
+	if !t.startedAt.IsZero() {
+		started := t.startedAt
+		v.StartedAt = &started
+	}
+	if !t.finishedAt.IsZero() {
+		finished := t.finishedAt
+		v.FinishedAt = &finished
+	}
+	return v
+}
+
+// finished returns when the task finished, or the zero time if it hasn't.
+func (t *task) finished() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.finishedAt
+}
+
+// screenshots returns the screenshot paths recorded so far.
+func (t *task) screenshots() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.result != nil {
+		return t.result.ScreenshotPaths
+	}
+	var paths []string
+	for _, s := range t.steps {
+		if s.ScreenshotPath != "" {
+			paths = append(paths, s.ScreenshotPath)
+		}
+		if s.AfterScreenshotPath != "" {
+			paths = append(paths, s.AfterScreenshotPath)
+		}
+	}
+	return paths
+}
+
+// setStatus updates the status and notifies subscribers.
+// Subscriber channels are closed once the task reaches a terminal state.
+func (t *task) setStatus(status TaskStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status = status
+	switch {
+	case status == StatusRunning:
+		t.startedAt = time.Now()
+	case status.done():
+		t.finishedAt = time.Now()
+	}
+
+	t.broadcastLocked(taskEvent{Type: "status", Data: status})
+	if status.done() {
+		for id, ch := range t.subs {
+			close(ch)
+			delete(t.subs, id)
+		}
+	}
+}
+
+// addStep records a completed step and notifies subscribers.
+func (t *task) addStep(step bua.Step) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.steps = append(t.steps, step)
+	t.broadcastLocked(taskEvent{Type: "step", Data: step})
+}
+
+// setProgress records the task's progress and notifies subscribers.
+func (t *task) setProgress(p bua.Progress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress = &p
+	t.broadcastLocked(taskEvent{Type: "progress", Data: p})
+}
+
+// subscribe returns past steps and a channel of future events.
+// The channel is nil if the task has already finished.
+func (t *task) subscribe() ([]bua.Step, TaskStatus, <-chan taskEvent, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	steps := append([]bua.Step(nil), t.steps...)
+	if t.status.done() {
+		return steps, t.status, nil, func() {}
+	}
+
+	id := t.nextSub
+	t.nextSub++
+	ch := make(chan taskEvent, 32)
+	t.subs[id] = ch
+
+	return steps, t.status, ch, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.subs[id]; ok {
+			delete(t.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// broadcastLocked sends an event without blocking. Must be called with t.mu held.
+func (t *task) broadcastLocked(ev taskEvent) {
+	for _, ch := range t.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Subscriber is behind; it can poll GET /tasks/{id}
+		}
+	}
+}
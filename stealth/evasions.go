@@ -0,0 +1,278 @@
+package stealth
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// evasionsJS hides automation traits and presents the fingerprint passed in
+// as fp. Every patched function reports itself as native code, and canvas and
+// audio reads get small deterministic noise seeded by the fingerprint, so
+// hashes are stable for a profile but differ between profiles.
+const evasionsJS = `
+(() => {
+    const fp = %s;
+
+    // Keep patched functions looking native
+    const nativeNames = new WeakMap();
+    const nativeToString = Function.prototype.toString;
+    const markNative = (fn, name) => {
+        nativeNames.set(fn, 'function ' + name + '() { [native code] }');
+        return fn;
+    };
+    const toString = function toString() {
+        if (nativeNames.has(this)) return nativeNames.get(this);
+        return nativeToString.call(this);
+    };
+    Function.prototype.toString = markNative(toString, 'toString');
+
+    const defineGetter = (obj, prop, value) => {
+        const getter = markNative(function () { return value; }, 'get ' + prop);
+        Object.defineProperty(obj, prop, { get: getter, configurable: true, enumerable: true });
+    };
+    const patchMethod = (proto, name, make) => {
+        const original = proto[name];
+        if (typeof original !== 'function') return;
+        proto[name] = markNative(make(original), name);
+    };
+
+    // Deterministic PRNG for noise (mulberry32)
+    const rng = (seed) => () => {
+        seed |= 0; seed = seed + 0x6D2B79F5 | 0;
+        let t = Math.imul(seed ^ seed >>> 15, 1 | seed);
+        t = t + Math.imul(t ^ t >>> 7, 61 | t) ^ t;
+        return ((t ^ t >>> 14) >>> 0) / 4294967296;
+    };
+
+    // 1. navigator.webdriver is false in a regular Chrome
+    defineGetter(Navigator.prototype, 'webdriver', false);
+
+    // 2. Navigator traits
+    defineGetter(Navigator.prototype, 'languages', Object.freeze(fp.languages.slice()));
+    defineGetter(Navigator.prototype, 'language', fp.languages[0]);
+    defineGetter(Navigator.prototype, 'platform', fp.platform);
+    defineGetter(Navigator.prototype, 'hardwareConcurrency', fp.hardwareConcurrency);
+    defineGetter(Navigator.prototype, 'deviceMemory', fp.deviceMemory);
+    defineGetter(Navigator.prototype, 'vendor', 'Google Inc.');
+
+    // 3. Plugins and MIME types of Chrome's built-in PDF viewer
+    try {
+        const pluginNames = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF'];
+        const mime = Object.create(MimeType.prototype);
+        const plugins = Object.create(PluginArray.prototype);
+        pluginNames.forEach((name, i) => {
+            const plugin = Object.create(Plugin.prototype);
+            defineGetter(plugin, 'name', name);
+            defineGetter(plugin, 'filename', 'internal-pdf-viewer');
+            defineGetter(plugin, 'description', 'Portable Document Format');
+            defineGetter(plugin, 'length', 1);
+            plugin[0] = mime;
+            plugins[i] = plugin;
+        });
+        defineGetter(plugins, 'length', pluginNames.length);
+        plugins.item = markNative(function item(i) { return this[i] || null; }, 'item');
+        plugins.namedItem = markNative(function namedItem(n) { return pluginNames.includes(n) ? this[pluginNames.indexOf(n)] : null; }, 'namedItem');
+        plugins.refresh = markNative(function refresh() {}, 'refresh');
+        defineGetter(mime, 'type', 'application/pdf');
+        defineGetter(mime, 'suffixes', 'pdf');
+        defineGetter(mime, 'description', 'Portable Document Format');
+        defineGetter(mime, 'enabledPlugin', plugins[0]);
+        const mimes = Object.create(MimeTypeArray.prototype);
+        mimes[0] = mime;
+        defineGetter(mimes, 'length', 1);
+        mimes.item = markNative(function item(i) { return this[i] || null; }, 'item');
+        mimes.namedItem = markNative(function namedItem(n) { return n === 'application/pdf' ? mime : null; }, 'namedItem');
+        defineGetter(Navigator.prototype, 'plugins', plugins);
+        defineGetter(Navigator.prototype, 'mimeTypes', mimes);
+        defineGetter(Navigator.prototype, 'pdfViewerEnabled', true);
+    } catch (e) {}
+
+    // 4. Notification permission query agrees with Notification.permission
+    if (navigator.permissions && navigator.permissions.query) {
+        patchMethod(Permissions.prototype, 'query', (original) => function query(parameters) {
+            if (parameters && parameters.name === 'notifications') {
+                return Promise.resolve({ state: Notification.permission === 'default' ? 'prompt' : Notification.permission, onchange: null });
+            }
+            return original.call(this, parameters);
+        });
+    }
+
+    // 5. window.chrome as present in a regular Chrome
+    if (!window.chrome) {
+        Object.defineProperty(window, 'chrome', { value: {}, writable: true, configurable: true, enumerable: true });
+    }
+    if (!window.chrome.runtime) {
+        window.chrome.runtime = {
+            OnInstalledReason: { CHROME_UPDATE: 'chrome_update', INSTALL: 'install', SHARED_MODULE_UPDATE: 'shared_module_update', UPDATE: 'update' },
+            PlatformOs: { ANDROID: 'android', CROS: 'cros', LINUX: 'linux', MAC: 'mac', OPENBSD: 'openbsd', WIN: 'win' },
+            connect: markNative(function connect() {}, 'connect'),
+            sendMessage: markNative(function sendMessage() {}, 'sendMessage'),
+        };
+    }
+    if (!window.chrome.app) {
+        window.chrome.app = {
+            isInstalled: false,
+            InstallState: { DISABLED: 'disabled', INSTALLED: 'installed', NOT_INSTALLED: 'not_installed' },
+            RunningState: { CANNOT_RUN: 'cannot_run', READY_TO_RUN: 'ready_to_run', RUNNING: 'running' },
+            getDetails: markNative(function getDetails() { return null; }, 'getDetails'),
+            getIsInstalled: markNative(function getIsInstalled() { return false; }, 'getIsInstalled'),
+            runningState: markNative(function runningState() { return 'cannot_run'; }, 'runningState'),
+        };
+    }
+    if (!window.chrome.csi) {
+        const start = Date.now();
+        window.chrome.csi = markNative(function csi() {
+            return { startE: start, onloadT: start + 300, pageT: performance.now(), tran: 15 };
+        }, 'csi');
+    }
+    if (!window.chrome.loadTimes) {
+        const t = Date.now() / 1000;
+        window.chrome.loadTimes = markNative(function loadTimes() {
+            return {
+                commitLoadTime: t, connectionInfo: 'h2', finishDocumentLoadTime: t + 0.4, finishLoadTime: t + 0.6,
+                firstPaintAfterLoadTime: 0, firstPaintTime: t + 0.3, navigationType: 'Other', npnNegotiatedProtocol: 'h2',
+                requestTime: t - 0.2, startLoadTime: t - 0.2, wasAlternateProtocolAvailable: false,
+                wasFetchedViaSpdy: true, wasNpnNegotiated: true,
+            };
+        }, 'loadTimes');
+    }
+
+    // 6. Screen and window metrics
+    defineGetter(Screen.prototype, 'width', fp.screenWidth);
+    defineGetter(Screen.prototype, 'height', fp.screenHeight);
+    defineGetter(Screen.prototype, 'availWidth', fp.screenWidth);
+    defineGetter(Screen.prototype, 'availHeight', fp.screenHeight - 40);
+    defineGetter(Screen.prototype, 'colorDepth', 24);
+    defineGetter(Screen.prototype, 'pixelDepth', 24);
+    if (window.outerWidth === 0 || window.outerHeight === 0) {
+        defineGetter(window, 'outerWidth', window.innerWidth);
+        defineGetter(window, 'outerHeight', window.innerHeight + 85);
+    }
+
+    // 7. WebGL vendor and renderer
+    const UNMASKED_VENDOR = 37445, UNMASKED_RENDERER = 37446;
+    [window.WebGLRenderingContext, window.WebGL2RenderingContext].forEach((ctx) => {
+        if (!ctx) return;
+        patchMethod(ctx.prototype, 'getParameter', (original) => function getParameter(p) {
+            if (p === UNMASKED_VENDOR) return fp.webglVendor;
+            if (p === UNMASKED_RENDERER) return fp.webglRenderer;
+            return original.call(this, p);
+        });
+    });
+
+    // 8. Canvas noise: flip low bits of a sparse, seeded set of pixels
+    const noisePixels = (data) => {
+        const next = rng(fp.seed);
+        for (let i = 0; i < data.length; i += 4) {
+            if (next() < 0.02) {
+                const channel = i + Math.floor(next() * 3);
+                data[channel] = data[channel] ^ 1;
+            }
+        }
+    };
+    const origGetImageData = CanvasRenderingContext2D.prototype.getImageData;
+    patchMethod(CanvasRenderingContext2D.prototype, 'getImageData', (original) => function getImageData(...args) {
+        const image = original.apply(this, args);
+        if (image.data.length <= 16777216) noisePixels(image.data);
+        return image;
+    });
+    const noisyCopy = (canvas) => {
+        const w = canvas.width, h = canvas.height;
+        if (!w || !h || w * h > 4194304) return canvas;
+        const copy = document.createElement('canvas');
+        copy.width = w;
+        copy.height = h;
+        const ctx = copy.getContext('2d');
+        ctx.drawImage(canvas, 0, 0);
+        const image = origGetImageData.call(ctx, 0, 0, w, h);
+        noisePixels(image.data);
+        ctx.putImageData(image, 0, 0);
+        return copy;
+    };
+    patchMethod(HTMLCanvasElement.prototype, 'toDataURL', (original) => function toDataURL(...args) {
+        return original.apply(noisyCopy(this), args);
+    });
+    patchMethod(HTMLCanvasElement.prototype, 'toBlob', (original) => function toBlob(...args) {
+        return original.apply(noisyCopy(this), args);
+    });
+
+    // 9. Audio noise
+    if (window.AudioBuffer) {
+        patchMethod(AudioBuffer.prototype, 'getChannelData', (original) => function getChannelData(...args) {
+            const data = original.apply(this, args);
+            if (!data.__buaNoised) {
+                const next = rng(fp.seed + args[0]);
+                for (let i = 0; i < data.length; i += 100) data[i] += (next() - 0.5) * 1e-7;
+                Object.defineProperty(data, '__buaNoised', { value: true });
+            }
+            return data;
+        });
+    }
+    if (window.AnalyserNode) {
+        patchMethod(AnalyserNode.prototype, 'getFloatFrequencyData', (original) => function getFloatFrequencyData(array) {
+            original.call(this, array);
+            const next = rng(fp.seed);
+            for (let i = 0; i < array.length; i++) array[i] += (next() - 0.5) * 1e-4;
+        });
+    }
+})();
+`
+
+// scriptFingerprint is the subset of a Fingerprint exposed to the evasion script.
+type scriptFingerprint struct {
+	Seed                int32    `json:"seed"`
+	Languages           []string `json:"languages"`
+	Platform            string   `json:"platform"`
+	HardwareConcurrency int      `json:"hardwareConcurrency"`
+	DeviceMemory        int      `json:"deviceMemory"`
+	ScreenWidth         int      `json:"screenWidth"`
+	ScreenHeight        int      `json:"screenHeight"`
+	WebGLVendor         string   `json:"webglVendor"`
+	WebGLRenderer       string   `json:"webglRenderer"`
+}
+
+// Script returns the evasion JavaScript for a fingerprint. It must run before
+// any page script, e.g. via Page.addScriptToEvaluateOnNewDocument.
+func Script(fp Fingerprint) string {
+	fp = fp.withDefaults()
+	data, _ := json.Marshal(scriptFingerprint{
+		Seed:                int32(fp.Seed),
+		Languages:           fp.Languages,
+		Platform:            fp.Platform,
+		HardwareConcurrency: fp.HardwareConcurrency,
+		DeviceMemory:        fp.DeviceMemory,
+		ScreenWidth:         fp.ScreenWidth,
+		ScreenHeight:        fp.ScreenHeight,
+		WebGLVendor:         fp.WebGLVendor,
+		WebGLRenderer:       fp.WebGLRenderer,
+	})
+	return fmt.Sprintf(evasionsJS, data)
+}
+
+// withDefaults fills fields left empty in a hand-written fingerprint from the
+// fingerprint generated for its seed.
+func (fp Fingerprint) withDefaults() Fingerprint {
+	base := GenerateFor(fp.OS, fp.Seed)
+	if fp.OS == "" {
+		fp.OS = base.OS
+	}
+	if fp.Platform == "" {
+		fp.Platform = base.Platform
+	}
+	if len(fp.Languages) == 0 {
+		fp.Languages = base.Languages
+	}
+	if fp.HardwareConcurrency == 0 {
+		fp.HardwareConcurrency = base.HardwareConcurrency
+	}
+	if fp.DeviceMemory == 0 {
+		fp.DeviceMemory = base.DeviceMemory
+	}
+	if fp.ScreenWidth == 0 || fp.ScreenHeight == 0 {
+		fp.ScreenWidth, fp.ScreenHeight = base.ScreenWidth, base.ScreenHeight
+	}
+	if fp.WebGLVendor == "" || fp.WebGLRenderer == "" {
+		fp.WebGLVendor, fp.WebGLRenderer = base.WebGLVendor, base.WebGLRenderer
+	}
+	return fp
+}
@@ -0,0 +1,258 @@
+package stealth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// FingerprintFile is the file name used to persist a profile's fingerprint.
+const FingerprintFile = "bua-fingerprint.json"
+
+// Fingerprint is a consistent set of browser traits presented to websites.
+// Keeping the same fingerprint for a profile across sessions matters as much
+// as the individual values: a returning visitor whose GPU or screen changes
+// between visits is easy to flag.
+type Fingerprint struct {
+	// Seed drives the canvas and audio noise so it is stable for this fingerprint.
+	Seed int64 `json:"seed"`
+
+	// OS is the operating system persona: "windows", "mac" or "linux".
+	OS string `json:"os"`
+
+	// UserAgent overrides the user agent. Empty builds one from OS and the
+	// real browser version, so the engine and the claimed version agree.
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Platform is reported as navigator.platform.
+	Platform string `json:"platform"`
+
+	// Languages are reported as navigator.languages and Accept-Language.
+	Languages []string `json:"languages"`
+
+	// Timezone is the IANA timezone to emulate. Empty keeps the host timezone,
+	// which is usually what matches the exit IP.
+	Timezone string `json:"timezone,omitempty"`
+
+	HardwareConcurrency int `json:"hardware_concurrency"`
+	DeviceMemory        int `json:"device_memory"` // GB
+
+	ScreenWidth  int `json:"screen_width"`
+	ScreenHeight int `json:"screen_height"`
+
+	// ViewportWidth and ViewportHeight are the window's inner size: a maximized
+	// window on the screen above, less the browser toolbar and taskbar.
+	// Zero leaves the configured viewport in place.
+	ViewportWidth  int `json:"viewport_width,omitempty"`
+	ViewportHeight int `json:"viewport_height,omitempty"`
+
+	WebGLVendor   string `json:"webgl_vendor"`
+	WebGLRenderer string `json:"webgl_renderer"`
+
+	// device and mobile are set by ForDevice; they describe the session,
+	// not the profile, so they are not saved.
+	device bool
+	mobile bool
+}
+
+// ForDevice returns fp presenting an emulated device instead of its desktop
+// persona: userAgent and platform are reported as given, the screen is
+// width x height, and client hints report mobile and the OS and model that
+// userAgent names.
+func (fp Fingerprint) ForDevice(userAgent, platform string, width, height int, mobile bool) Fingerprint {
+	fp.UserAgent = userAgent
+	if platform != "" {
+		fp.Platform = platform
+	}
+	fp.ScreenWidth, fp.ScreenHeight = width, height
+	fp.ViewportWidth, fp.ViewportHeight = 0, 0
+	fp.device, fp.mobile = true, mobile
+	return fp
+}
+
+// persona groups traits that must agree with each other for one OS.
+type persona struct {
+	platform  string
+	uaFormat  string // %s is the Chrome version
+	hintsName string // Sec-CH-UA-Platform
+	gpus      [][2]string
+	screens   [][2]int
+	cores     []int
+	memory    []int
+}
+
+var personas = map[string]persona{
+	"windows": {
+		platform:  "Win32",
+		uaFormat:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+		hintsName: "Windows",
+		gpus: [][2]string{
+			{"Google Inc. (NVIDIA)", "ANGLE (NVIDIA, NVIDIA GeForce RTX 3060 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (NVIDIA)", "ANGLE (NVIDIA, NVIDIA GeForce GTX 1650 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (Intel)", "ANGLE (Intel, Intel(R) UHD Graphics 630 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (Intel)", "ANGLE (Intel, Intel(R) Iris(R) Xe Graphics Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon RX 6600 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+		},
+		screens: [][2]int{{1920, 1080}, {2560, 1440}, {1536, 864}, {1366, 768}, {1600, 900}},
+		cores:   []int{4, 8, 12, 16},
+		memory:  []int{8, 16},
+	},
+	"mac": {
+		platform:  "MacIntel",
+		uaFormat:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+		hintsName: "macOS",
+		gpus: [][2]string{
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M1, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M2 Pro, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M3, Unspecified Version)"},
+		},
+		screens: [][2]int{{1440, 900}, {1512, 982}, {1728, 1117}, {1680, 1050}, {2560, 1440}},
+		cores:   []int{8, 10, 12},
+		memory:  []int{8, 16},
+	},
+	"linux": {
+		platform:  "Linux x86_64",
+		uaFormat:  "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+		hintsName: "Linux",
+		gpus: [][2]string{
+			{"Google Inc. (Intel)", "ANGLE (Intel, Mesa Intel(R) UHD Graphics 620 (KBL GT2), OpenGL 4.6)"},
+			{"Google Inc. (Intel)", "ANGLE (Intel, Mesa Intel(R) Xe Graphics (TGL GT2), OpenGL 4.6)"},
+			{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon Graphics (radeonsi, renoir, LLVM 15.0.7), OpenGL 4.6)"},
+		},
+		screens: [][2]int{{1920, 1080}, {2560, 1440}, {1366, 768}},
+		cores:   []int{4, 8, 16},
+		memory:  []int{8, 16},
+	},
+}
+
+// HostOS returns the persona matching the machine the browser runs on.
+// Claiming a different OS than the host is detectable through fonts and
+// rendering, so it is the safest default.
+func HostOS() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "windows"
+	case "darwin":
+		return "mac"
+	default:
+		return "linux"
+	}
+}
+
+// Generate returns a fingerprint for the host OS derived from seed.
+// The same seed always produces the same fingerprint.
+func Generate(seed int64) Fingerprint {
+	return GenerateFor(HostOS(), seed)
+}
+
+// GenerateFor returns a fingerprint for the given OS persona derived from seed.
+// Unknown OS names fall back to the host OS.
+func GenerateFor(osName string, seed int64) Fingerprint {
+	p, ok := personas[osName]
+	if !ok {
+		osName = HostOS()
+		p = personas[osName]
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	gpu := p.gpus[rng.Intn(len(p.gpus))]
+	screen := p.screens[rng.Intn(len(p.screens))]
+	cores := p.cores[rng.Intn(len(p.cores))]
+	memory := p.memory[rng.Intn(len(p.memory))]
+	chrome := 110 + rng.Intn(40)
+
+	return Fingerprint{
+		Seed:                seed,
+		OS:                  osName,
+		Platform:            p.platform,
+		Languages:           []string{"en-US", "en"},
+		HardwareConcurrency: cores,
+		DeviceMemory:        memory,
+		ScreenWidth:         screen[0],
+		ScreenHeight:        screen[1],
+		ViewportWidth:       screen[0],
+		ViewportHeight:      screen[1] - chrome,
+		WebGLVendor:         gpu[0],
+		WebGLRenderer:       gpu[1],
+	}
+}
+
+// Random returns a fingerprint for the host OS with a random seed.
+func Random() Fingerprint {
+	return Generate(time.Now().UnixNano())
+}
+
+// ForProfile loads the fingerprint stored in a profile directory, creating and
+// saving a new one on first use, so a profile always presents the same traits.
+func ForProfile(profileDir string) (Fingerprint, error) {
+	fp, err := Load(profileDir)
+	if err == nil {
+		return fp, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return Fingerprint{}, err
+	}
+
+	fp = Random()
+	if err := fp.Save(profileDir); err != nil {
+		return Fingerprint{}, err
+	}
+	return fp, nil
+}
+
+// Load reads the fingerprint stored in a profile directory.
+func Load(profileDir string) (Fingerprint, error) {
+	data, err := os.ReadFile(filepath.Join(profileDir, FingerprintFile))
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	var fp Fingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to parse fingerprint: %w", err)
+	}
+	return fp, nil
+}
+
+// Save stores the fingerprint in a profile directory.
+func (fp Fingerprint) Save(profileDir string) error {
+	if err := os.MkdirAll(profileDir, 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fingerprint: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(profileDir, FingerprintFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to save fingerprint: %w", err)
+	}
+	return nil
+}
+
+// userAgentFor returns the user agent for this fingerprint and Chrome version.
+func (fp Fingerprint) userAgentFor(chromeVersion string) string {
+	if fp.UserAgent != "" {
+		return fp.UserAgent
+	}
+	p, ok := personas[fp.OS]
+	if !ok {
+		p = personas[HostOS()]
+	}
+	return fmt.Sprintf(p.uaFormat, chromeVersion)
+}
+
+// platformHint returns the Sec-CH-UA-Platform value for this fingerprint.
+func (fp Fingerprint) platformHint() string {
+	p, ok := personas[fp.OS]
+	if !ok {
+		p = personas[HostOS()]
+	}
+	return p.hintsName
+}
@@ -0,0 +1,87 @@
+package stealth
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Locale is a timezone and the languages a person in it would plausibly use.
+// Pick locales that match the exit IPs of the fleet; a Berlin timezone behind a
+// US proxy is a stronger signal than no override at all.
+type Locale struct {
+	Timezone  string   `json:"timezone"`
+	Languages []string `json:"languages"`
+}
+
+// USLocales spreads profiles across the four main US timezones.
+var USLocales = []Locale{
+	{Timezone: "America/New_York", Languages: []string{"en-US", "en"}},
+	{Timezone: "America/Chicago", Languages: []string{"en-US", "en"}},
+	{Timezone: "America/Denver", Languages: []string{"en-US", "en"}},
+	{Timezone: "America/Los_Angeles", Languages: []string{"en-US", "en"}},
+}
+
+// Rotation assigns fingerprint bundles (user agent, screen and viewport,
+// timezone, GPU and noise seed) to profiles. Each new profile gets the next
+// OS persona and locale in turn plus a fresh seed, so a fleet of profiles does
+// not share one trivially correlated fingerprint. Once assigned, a bundle is
+// saved with the profile and reused on every later session.
+//
+// A Rotation is safe for concurrent use and is meant to be shared by all
+// agents in a fleet.
+type Rotation struct {
+	// OS lists the personas to rotate through ("windows", "mac", "linux").
+	// Default: the host OS only, since fonts and rendering give away the real one.
+	OS []string
+
+	// Locales lists the timezone/language pairs to rotate through.
+	// Default: none (host timezone, en-US).
+	Locales []Locale
+
+	mu   sync.Mutex
+	next int
+}
+
+// Next returns the next bundle in the rotation without persisting it.
+func (r *Rotation) Next() Fingerprint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	r.next++
+
+	osName := HostOS()
+	if len(r.OS) > 0 {
+		osName = r.OS[n%len(r.OS)]
+	}
+
+	fp := GenerateFor(osName, time.Now().UnixNano()+int64(n))
+	if len(r.Locales) > 0 {
+		locale := r.Locales[n%len(r.Locales)]
+		fp.Timezone = locale.Timezone
+		if len(locale.Languages) > 0 {
+			fp.Languages = append([]string(nil), locale.Languages...)
+		}
+	}
+	return fp
+}
+
+// ForProfile loads the bundle stored in a profile directory, assigning and
+// saving the next one in the rotation on first use.
+func (r *Rotation) ForProfile(profileDir string) (Fingerprint, error) {
+	fp, err := Load(profileDir)
+	if err == nil {
+		return fp, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return Fingerprint{}, err
+	}
+
+	fp = r.Next()
+	if err := fp.Save(profileDir); err != nil {
+		return Fingerprint{}, err
+	}
+	return fp, nil
+}
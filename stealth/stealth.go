@@ -0,0 +1,132 @@
+// Package stealth hardens a browser page against bot detection.
+//
+// It injects evasions at document start (navigator.webdriver, plugins,
+// window.chrome, WebGL vendor, canvas and audio noise) and presents a
+// Fingerprint whose traits agree with each other and with the user agent and
+// client hints sent over the network. Persist a fingerprint per profile with
+// ForProfile so a profile looks like the same machine on every visit.
+package stealth
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Apply configures a page to present fp. Call it before the page navigates,
+// since the evasion script only runs on documents loaded afterwards.
+func Apply(page *rod.Page, fp Fingerprint) error {
+	fp = fp.withDefaults()
+
+	version, major := chromeVersion(page)
+	ua := fp.userAgentFor(major + ".0.0.0")
+
+	// User agent, Accept-Language and client hints must agree with the script
+	brands := []*proto.EmulationUserAgentBrandVersion{
+		{Brand: "Chromium", Version: major},
+		{Brand: "Google Chrome", Version: major},
+		{Brand: "Not_A Brand", Version: "24"},
+	}
+	fullVersions := []*proto.EmulationUserAgentBrandVersion{
+		{Brand: "Chromium", Version: version},
+		{Brand: "Google Chrome", Version: version},
+		{Brand: "Not_A Brand", Version: "24.0.0.0"},
+	}
+	if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:         ua,
+		AcceptLanguage:    strings.Join(fp.Languages, ","),
+		Platform:          fp.Platform,
+		UserAgentMetadata: fp.userAgentMetadata(ua, brands, fullVersions),
+	}); err != nil {
+		return fmt.Errorf("failed to set user agent: %w", err)
+	}
+
+	if fp.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: fp.Timezone}).Call(page); err != nil {
+			return fmt.Errorf("failed to set timezone: %w", err)
+		}
+	}
+
+	// Best-effort: fails if another override is already active on the page
+	_ = proto.EmulationSetLocaleOverride{Locale: fp.Languages[0]}.Call(page)
+
+	if _, err := page.EvalOnNewDocument(Script(fp)); err != nil {
+		return fmt.Errorf("failed to inject evasions: %w", err)
+	}
+	return nil
+}
+
+// Device user agents name their OS version and, on Android, the model.
+var (
+	androidUA = regexp.MustCompile(`Android (\d+(?:\.\d+)*); ([^;)]+)`)
+	iosUA     = regexp.MustCompile(`(?:iPhone|iPad).*? OS (\d+(?:_\d+)*) like Mac OS X`)
+)
+
+// userAgentMetadata returns the client hints that go with fp and user agent ua.
+func (fp Fingerprint) userAgentMetadata(ua string, brands, fullVersions []*proto.EmulationUserAgentBrandVersion) *proto.EmulationUserAgentMetadata {
+	meta := &proto.EmulationUserAgentMetadata{
+		Brands:          brands,
+		FullVersionList: fullVersions,
+		Platform:        fp.platformHint(),
+		PlatformVersion: platformVersion(fp.OS),
+		Architecture:    "x86",
+		Bitness:         "64",
+	}
+	if !fp.device {
+		return meta
+	}
+
+	// An emulated device: describe what its user agent claims, like the
+	// device's own browser would
+	meta.Mobile = fp.mobile
+	if m := androidUA.FindStringSubmatch(ua); m != nil {
+		meta.Platform, meta.PlatformVersion, meta.Model = "Android", fullVersion(m[1]), strings.TrimSpace(m[2])
+		meta.Architecture, meta.Bitness = "", ""
+	} else if m := iosUA.FindStringSubmatch(ua); m != nil {
+		meta.Platform, meta.PlatformVersion = "iOS", fullVersion(strings.ReplaceAll(m[1], "_", "."))
+		meta.Architecture, meta.Bitness = "", ""
+	}
+	return meta
+}
+
+// fullVersion pads a dotted version to three components, e.g. "14" to "14.0.0".
+func fullVersion(v string) string {
+	for strings.Count(v, ".") < 2 {
+		v += ".0"
+	}
+	return v
+}
+
+// chromeVersion returns the real browser version and its major component,
+// e.g. "131.0.6778.85" and "131".
+func chromeVersion(page *rod.Page) (string, string) {
+	const fallback = "131.0.0.0"
+
+	info, err := proto.BrowserGetVersion{}.Call(page)
+	if err != nil {
+		return fallback, "131"
+	}
+
+	// Product looks like "HeadlessChrome/131.0.6778.85" or "Chrome/131.0.6778.85"
+	_, version, ok := strings.Cut(info.Product, "/")
+	if !ok || version == "" {
+		return fallback, "131"
+	}
+	major, _, _ := strings.Cut(version, ".")
+	return version, major
+}
+
+// platformVersion returns a plausible Sec-CH-UA-Platform-Version for an OS persona.
+func platformVersion(os string) string {
+	switch os {
+	case "windows":
+		return "15.0.0"
+	case "mac":
+		return "14.5.0"
+	default:
+		return "6.5.0"
+	}
+}
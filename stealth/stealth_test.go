@@ -0,0 +1,59 @@
+package stealth
+
+import "testing"
+
+func TestUserAgentMetadataForDevice(t *testing.T) {
+	base := GenerateFor("windows", 1)
+
+	tests := []struct {
+		name                     string
+		fp                       Fingerprint
+		ua                       string
+		mobile                   bool
+		platform, version, model string
+	}{
+		{
+			name:     "desktop persona",
+			fp:       base,
+			ua:       base.userAgentFor("131.0.0.0"),
+			platform: "Windows", version: "15.0.0",
+		},
+		{
+			name:     "android phone",
+			fp:       base.ForDevice("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36", "Linux armv81", 412, 915, true),
+			mobile:   true,
+			platform: "Android", version: "14.0.0", model: "Pixel 8",
+		},
+		{
+			name:     "iphone",
+			fp:       base.ForDevice("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", "iPhone", 393, 852, true),
+			mobile:   true,
+			platform: "iOS", version: "17.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ua := tt.ua
+			if ua == "" {
+				ua = tt.fp.userAgentFor("131.0.0.0")
+			}
+			meta := tt.fp.userAgentMetadata(ua, nil, nil)
+			if meta.Mobile != tt.mobile || meta.Platform != tt.platform || meta.PlatformVersion != tt.version || meta.Model != tt.model {
+				t.Errorf("got mobile=%v platform=%q version=%q model=%q, want %v %q %q %q",
+					meta.Mobile, meta.Platform, meta.PlatformVersion, meta.Model, tt.mobile, tt.platform, tt.version, tt.model)
+			}
+		})
+	}
+}
+
+func TestForDeviceKeepsProfileTraits(t *testing.T) {
+	base := GenerateFor("mac", 7)
+	fp := base.ForDevice("ua", "iPhone", 393, 852, true)
+
+	if fp.UserAgent != "ua" || fp.Platform != "iPhone" || fp.ScreenWidth != 393 || fp.ScreenHeight != 852 {
+		t.Errorf("device traits not applied: %+v", fp)
+	}
+	if fp.Seed != base.Seed || fp.WebGLRenderer != base.WebGLRenderer {
+		t.Error("profile traits changed")
+	}
+}
@@ -0,0 +1,175 @@
+package bua
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anxuanzi/bua/agent"
+)
+
+// runReport is the JSON form of Agent.Summarize.
+type runReport struct {
+	Success    bool          `json:"success"`
+	Summary    string        `json:"summary,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	ErrorCode  ErrorCode     `json:"error_code,omitempty"`
+	Data       any           `json:"data,omitempty"`
+	Sections   []Section     `json:"sections,omitempty"`
+	Findings   []Finding     `json:"findings,omitempty"`
+	Assertions []Assertion   `json:"assertions,omitempty"`
+	Steps      []reportStep  `json:"steps"`
+	Downloads  []string      `json:"downloads,omitempty"`
+	Recording  string        `json:"recording,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Tokens     int           `json:"tokens,omitempty"`
+	Cost       float64       `json:"estimated_cost,omitempty"`
+}
+
+// reportStep is a step of a runReport.
+type reportStep struct {
+	Number int    `json:"number"`
+	Action string `json:"action"`
+	What   string `json:"what"`
+	URL    string `json:"url,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Summarize renders a human-readable report of result: its outcome and the
+// agent's summary, the data, per-objective sections and findings it saved, its
+// assertions, the steps taken and the files it produced. format is
+// SummaryMarkdown (the default for ""), SummaryPlain or SummaryJSON.
+func (a *Agent) Summarize(result *Result, format SummaryFormat) string {
+	if result == nil {
+		return ""
+	}
+	report := runReport{
+		Success:    result.Success,
+		Summary:    result.Summary,
+		Error:      result.Error,
+		ErrorCode:  result.ErrorCode,
+		Data:       result.Data,
+		Sections:   result.Sections,
+		Findings:   result.Findings,
+		Assertions: result.Assertions,
+		Steps:      make([]reportStep, len(result.Steps)),
+		Downloads:  result.DownloadPaths,
+		Recording:  result.RecordingPath,
+		Duration:   result.Duration,
+		Tokens:     result.Usage.TotalTokens,
+		Cost:       result.EstimatedCost,
+	}
+	for i, s := range result.Steps {
+		report.Steps[i] = reportStep{Number: s.Number, Action: s.Action, What: agent.Narrate(s.Action, s.Target), URL: s.URL}
+		if !s.Success {
+			report.Steps[i].Error = s.Error
+			if report.Steps[i].Error == "" {
+				report.Steps[i].Error = "failed"
+			}
+		}
+	}
+
+	if format == SummaryJSON {
+		b, _ := json.MarshalIndent(report, "", "  ")
+		return string(b)
+	}
+	md := report.markdown()
+	if format == SummaryPlain {
+		return agent.FormatSummary(md, SummaryPlain)
+	}
+	return md
+}
+
+// markdown renders the report as Markdown.
+func (r runReport) markdown() string {
+	var sb strings.Builder
+	if r.Success {
+		sb.WriteString("# Task succeeded\n\n")
+	} else {
+		sb.WriteString("# Task failed\n\n")
+	}
+	if r.Summary != "" {
+		sb.WriteString(r.Summary + "\n\n")
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&sb, "**Error:** %s", r.Error)
+		if r.ErrorCode != "" {
+			fmt.Fprintf(&sb, " (`%s`)", r.ErrorCode)
+		}
+		sb.WriteString("\n\n")
+	}
+
+	if r.Data != nil {
+		data, err := json.MarshalIndent(r.Data, "", "  ")
+		if err == nil {
+			fmt.Fprintf(&sb, "## Data\n\n```json\n%s\n```\n\n", data)
+		}
+	}
+
+	if len(r.Sections) > 0 {
+		sb.WriteString("## Objectives\n\n")
+		for _, s := range r.Sections {
+			fmt.Fprintf(&sb, "- **%s** (%s)", s.Objective, s.Status)
+			if s.Summary != "" {
+				sb.WriteString(": " + s.Summary)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Findings) > 0 {
+		fmt.Fprintf(&sb, "## Findings\n\n%d saved\n\n", len(r.Findings))
+		for _, f := range r.Findings {
+			data, _ := json.Marshal(f.Data)
+			fmt.Fprintf(&sb, "- `%s`\n", data)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Assertions) > 0 {
+		sb.WriteString("## Assertions\n\n")
+		for _, as := range r.Assertions {
+			status := "passed"
+			if !as.Passed {
+				status = "FAILED"
+			}
+			fmt.Fprintf(&sb, "- %s %s: expected %s, got %s\n", status, as.Description, as.Expected, as.Actual)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Steps) > 0 {
+		sb.WriteString("## Steps\n\n")
+		for _, s := range r.Steps {
+			fmt.Fprintf(&sb, "%d. %s (`%s`)", s.Number, s.What, s.Action)
+			if s.Error != "" {
+				sb.WriteString(" - failed: " + s.Error)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Downloads) > 0 || r.Recording != "" {
+		sb.WriteString("## Files\n\n")
+		for _, d := range r.Downloads {
+			fmt.Fprintf(&sb, "- %s\n", d)
+		}
+		if r.Recording != "" {
+			fmt.Fprintf(&sb, "- %s (recording)\n", r.Recording)
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "%d steps in %s", len(r.Steps), r.Duration.Round(time.Second))
+	if r.Tokens > 0 {
+		fmt.Fprintf(&sb, ", %d tokens", r.Tokens)
+	}
+	if r.Cost > 0 {
+		fmt.Fprintf(&sb, ", about $%.4f", r.Cost)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
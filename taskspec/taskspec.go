@@ -0,0 +1,286 @@
+// Package taskspec loads declarative task definitions from YAML or JSON, so
+// automations can be written without Go and shared by the CLI, the REST
+// server and the scheduler.
+//
+// A spec's prompt, URL and constraints may reference {{variables}}, filled
+// from the spec's defaults and the caller's values. Secrets are referenced
+// as {{secrets.name}}: the model only ever sees a <secret>name</secret>
+// placeholder, and the real value, read from the environment or a file, is
+// substituted when the agent types it.
+//
+// Example spec:
+//
+//	name: order-status
+//	url: https://shop.example.com/orders/{{order_id}}
+//	task: Sign in with {{secrets.email}} and report the status of order {{order_id}}
+//	vars:
+//	  order_id: ""
+//	secrets:
+//	  email: env:SHOP_EMAIL
+//	constraints:
+//	  - Do not cancel or modify the order
+//	schema:
+//	  type: object
+//	  properties:
+//	    status: {type: string}
+//	preset: fast
+//	timeout: 3m
+package taskspec
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anxuanzi/bua"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a task definition as written in a file.
+type Spec struct {
+	// Name identifies the task (optional).
+	Name string `yaml:"name" json:"name,omitempty"`
+
+	// Description documents the task for people; it is not sent to the model.
+	Description string `yaml:"description" json:"description,omitempty"`
+
+	// URL is navigated to before the task runs (optional, may use variables).
+	URL string `yaml:"url" json:"url,omitempty"`
+
+	// Task is the prompt template (required).
+	Task string `yaml:"task" json:"task"`
+
+	// Vars are variable defaults. A variable used in the template must have
+	// a default here or be supplied when rendering.
+	Vars map[string]string `yaml:"vars" json:"vars,omitempty"`
+
+	// Schema is a JSON Schema the agent's result data should match.
+	Schema map[string]any `yaml:"schema" json:"schema,omitempty"`
+
+	// Constraints are rules the agent must follow, e.g. "Do not submit the form".
+	Constraints []string `yaml:"constraints" json:"constraints,omitempty"`
+
+	// Secrets maps placeholder names to references: "env:NAME" reads an
+	// environment variable, "file:/path" reads a file.
+	Secrets map[string]string `yaml:"secrets" json:"secrets,omitempty"`
+
+	// Preset overrides the agent's token/quality preset.
+	Preset bua.Preset `yaml:"preset" json:"preset,omitempty"`
+
+	// Profile overrides the agent's browser profile.
+	Profile string `yaml:"profile" json:"profile,omitempty"`
+
+	// Timeout bounds the run, as a Go duration string (e.g., "5m").
+	Timeout string `yaml:"timeout" json:"timeout,omitempty"`
+
+	// MaxSteps overrides the agent's step limit.
+	MaxSteps int `yaml:"max_steps" json:"max_steps,omitempty"`
+}
+
+// Task is a rendered spec, ready to run.
+type Task struct {
+	Name     string
+	URL      string
+	Prompt   string
+	Preset   bua.Preset
+	Profile  string
+	Timeout  time.Duration
+	MaxSteps int
+
+	// Schema is the spec's JSON Schema, for RunOptions.Schema. It is not
+	// part of Prompt: the agent adds it to the prompt and checks done
+	// against it.
+	Schema map[string]any
+
+	// Secrets holds the resolved secret values, keyed by placeholder name.
+	Secrets map[string]string
+}
+
+// Load reads a spec from a YAML or JSON file.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse decodes a YAML or JSON spec and checks that it has a task.
+func Parse(data []byte) (*Spec, error) {
+	// JSON is valid YAML, so one decoder handles both
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse task spec: %w", err)
+	}
+	if strings.TrimSpace(spec.Task) == "" {
+		return nil, fmt.Errorf("task is required")
+	}
+	if spec.Profile != "" {
+		if err := bua.ValidateProfileName(spec.Profile); err != nil {
+			return nil, err
+		}
+	}
+	return &spec, nil
+}
+
+// variable matches {{name}} and {{secrets.name}}, with optional spaces.
+var variable = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// Render fills in variables from the spec's defaults overridden by vars,
+// resolves secrets, and builds the final prompt. Run the task with its
+// Schema in RunOptions.Schema.
+func (s *Spec) Render(vars map[string]string) (*Task, error) {
+	values := make(map[string]string, len(s.Vars)+len(vars))
+	for k, v := range s.Vars {
+		values[k] = v
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	var missing []string
+	usedSecret := false
+	expand := func(text string) string {
+		return variable.ReplaceAllStringFunc(text, func(m string) string {
+			name := variable.FindStringSubmatch(m)[1]
+			if secret, ok := strings.CutPrefix(name, "secrets."); ok {
+				if _, defined := s.Secrets[secret]; !defined {
+					missing = append(missing, name)
+					return m
+				}
+				usedSecret = true
+				return "<secret>" + secret + "</secret>"
+			}
+			v, ok := values[name]
+			if !ok {
+				missing = append(missing, name)
+				return m
+			}
+			return v
+		})
+	}
+
+	task := &Task{
+		Name:     s.Name,
+		URL:      expand(s.URL),
+		Preset:   s.Preset,
+		Profile:  s.Profile,
+		MaxSteps: s.MaxSteps,
+		Schema:   s.Schema,
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(strings.TrimSpace(expand(s.Task)))
+	if len(s.Constraints) > 0 {
+		prompt.WriteString("\n\nConstraints:")
+		for _, c := range s.Constraints {
+			prompt.WriteString("\n- " + expand(c))
+		}
+	}
+	if usedSecret {
+		prompt.WriteString("\n\nText in <secret> tags is a placeholder for a secret value. Type it exactly as written; it is replaced with the real value as it is typed.")
+	}
+	task.Prompt = prompt.String()
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing values for variables: %s", strings.Join(dedupe(missing), ", "))
+	}
+
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", s.Timeout, err)
+		}
+		task.Timeout = d
+	}
+
+	if len(s.Secrets) > 0 {
+		task.Secrets = make(map[string]string, len(s.Secrets))
+		for name, ref := range s.Secrets {
+			v, err := resolveSecret(ref)
+			if err != nil {
+				return nil, fmt.Errorf("secret %q: %w", name, err)
+			}
+			task.Secrets[name] = v
+		}
+	}
+
+	return task, nil
+}
+
+// Apply sets the task's preset, profile, step limit and secrets on cfg.
+// Fields the spec leaves empty keep cfg's values.
+func (t *Task) Apply(cfg *bua.Config) {
+	if t.Preset != "" {
+		cfg.Preset = t.Preset
+	}
+	if t.Profile != "" {
+		cfg.ProfileName = t.Profile
+	}
+	if t.MaxSteps > 0 {
+		cfg.MaxSteps = t.MaxSteps
+	}
+	if len(t.Secrets) > 0 {
+		secrets := make(map[string]string, len(cfg.Secrets)+len(t.Secrets))
+		for k, v := range cfg.Secrets {
+			secrets[k] = v
+		}
+		for k, v := range t.Secrets {
+			secrets[k] = v
+		}
+		cfg.Secrets = secrets
+	}
+}
+
+// ParseVars parses "key=value" pairs, e.g. from repeated command-line flags.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid variable %q, want key=value", p)
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// resolveSecret reads a secret from an "env:" or "file:" reference.
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(ref, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported reference %q, want env:NAME or file:PATH", ref)
+	}
+}
+
+// dedupe removes adjacent duplicates from a sorted slice.
+func dedupe(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package taskspec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/anxuanzi/bua"
+)
+
+const orderSpec = `
+name: order-status
+url: https://shop.example.com/orders/{{order_id}}
+task: Sign in with {{ secrets.email }} and report the status of order {{order_id}} in {{lang}}
+vars:
+  order_id: ""
+  lang: English
+secrets:
+  email: env:TASKSPEC_TEST_EMAIL
+constraints:
+  - Do not cancel order {{order_id}}
+schema:
+  type: object
+  properties:
+    status: {type: string}
+preset: fast
+timeout: 3m
+max_steps: 40
+`
+
+func TestRender(t *testing.T) {
+	t.Setenv("TASKSPEC_TEST_EMAIL", "ada@example.com")
+	spec, err := Parse([]byte(orderSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := spec.Render(map[string]string{"order_id": "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if task.URL != "https://shop.example.com/orders/42" {
+		t.Errorf("URL = %s", task.URL)
+	}
+	// The secret stays a placeholder, and the schema goes to RunOptions
+	// rather than into the prompt
+	wantPrompt := "Sign in with <secret>email</secret> and report the status of order 42 in English" +
+		"\n\nConstraints:\n- Do not cancel order 42" +
+		"\n\nText in <secret> tags is a placeholder for a secret value. Type it exactly as written; it is replaced with the real value as it is typed."
+	if task.Prompt != wantPrompt {
+		t.Errorf("Prompt = %q, want %q", task.Prompt, wantPrompt)
+	}
+	wantSchema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"status": map[string]any{"type": "string"}},
+	}
+	if !reflect.DeepEqual(task.Schema, wantSchema) {
+		t.Errorf("Schema = %v, want %v", task.Schema, wantSchema)
+	}
+	if task.Timeout != 3*time.Minute || task.MaxSteps != 40 || task.Preset != bua.PresetFast {
+		t.Errorf("Timeout, MaxSteps, Preset = %v, %d, %q", task.Timeout, task.MaxSteps, task.Preset)
+	}
+	if task.Secrets["email"] != "ada@example.com" {
+		t.Errorf("Secrets = %v", task.Secrets)
+	}
+}
+
+func TestRenderMissing(t *testing.T) {
+	spec := &Spec{
+		URL:  "https://{{host}}/",
+		Task: "Find {{item}} with {{secrets.token}}, then {{item}} again",
+	}
+
+	_, err := spec.Render(nil)
+	if err == nil {
+		t.Fatal("Render() succeeded with undefined variables")
+	}
+	want := "missing values for variables: host, item, secrets.token"
+	if err.Error() != want {
+		t.Errorf("Render() error = %q, want %q", err, want)
+	}
+}
+
+func TestRenderSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := &Spec{Task: "Use {{secrets.token}}", Secrets: map[string]string{"token": "file:" + path}}
+
+	task, err := spec.Render(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Secrets["token"] != "s3cret" {
+		t.Errorf("token = %q, want s3cret", task.Secrets["token"])
+	}
+
+	spec.Secrets["token"] = "vault:token"
+	if _, err := spec.Render(nil); err == nil {
+		t.Error("Render() accepted an unsupported secret reference")
+	}
+}
+
+func TestRenderInvalidTimeout(t *testing.T) {
+	spec := &Spec{Task: "Look", Timeout: "soon"}
+	if _, err := spec.Render(nil); err == nil {
+		t.Error("Render() accepted timeout \"soon\"")
+	}
+}
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(`{"task": "Look", "vars": {"q": "x"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Task != "Look" || spec.Vars["q"] != "x" {
+		t.Errorf("Parse() = %+v", spec)
+	}
+
+	if _, err := Parse([]byte("name: empty\ntask: '  '\n")); err == nil {
+		t.Error("Parse() accepted a spec without a task")
+	}
+
+	for _, profile := range []string{"..", "../other", "a/b", "/tmp"} {
+		if _, err := Parse([]byte("task: Look\nprofile: " + profile + "\n")); err == nil {
+			t.Errorf("Parse() accepted profile %q", profile)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg := bua.Config{Preset: bua.PresetQuality, ProfileName: "work", MaxSteps: 10, Secrets: map[string]string{"a": "1"}}
+	task := &Task{MaxSteps: 40, Secrets: map[string]string{"b": "2"}}
+
+	task.Apply(&cfg)
+
+	if cfg.Preset != bua.PresetQuality || cfg.ProfileName != "work" || cfg.MaxSteps != 40 {
+		t.Errorf("Apply() set Preset, ProfileName, MaxSteps = %q, %q, %d", cfg.Preset, cfg.ProfileName, cfg.MaxSteps)
+	}
+	if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(cfg.Secrets, want) {
+		t.Errorf("Secrets = %v, want %v", cfg.Secrets, want)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"a=1", "b=x=y", "c="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"a": "1", "b": "x=y", "c": ""}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("ParseVars() = %v, want %v", vars, want)
+	}
+	if _, err := ParseVars([]string{"novalue"}); err == nil {
+		t.Error("ParseVars() accepted a pair without =")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/anxuanzi/bua"
+	"github.com/anxuanzi/bua/testsite"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,7 +23,7 @@ type TestSuite struct {
 type TestCase struct {
 	Name        string       `yaml:"name"`
 	Description string       `yaml:"description"`
-	URL         string       `yaml:"url"`
+	URL         string       `yaml:"url"` // paths starting with "/" are served by the local test site
 	Task        string       `yaml:"task"`
 	Timeout     string       `yaml:"timeout"`
 	Expected    Expectations `yaml:"expected"`
@@ -90,10 +91,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Serve the fixture pages the tests start from
+	site, err := testsite.Start()
+	if err != nil {
+		fmt.Printf("Error starting test site: %v\n", err)
+		os.Exit(1)
+	}
+	defer site.Close()
+
 	fmt.Printf("Running %d test(s)...\n\n", len(tests))
 
 	// Run tests
-	results := runTests(tests, cfg)
+	results := runTests(tests, cfg, site)
 
 	// Print results
 	printResults(results, cfg.Verbose)
@@ -106,6 +115,7 @@ func main() {
 		}
 	}
 	if failed > 0 {
+		site.Close()
 		os.Exit(1)
 	}
 }
@@ -187,13 +197,13 @@ func loadTests(files []string, testName string) ([]TestCase, error) {
 	return allTests, nil
 }
 
-func runTests(tests []TestCase, cfg Config) []TestResult {
+func runTests(tests []TestCase, cfg Config, site *testsite.Server) []TestResult {
 	var results []TestResult
 
 	for i, test := range tests {
 		fmt.Printf("[%d/%d] Running: %s\n", i+1, len(tests), test.Name)
 
-		result := runSingleTest(test, cfg)
+		result := runSingleTest(test, cfg, site)
 		results = append(results, result)
 
 		if result.Passed {
@@ -207,7 +217,7 @@ func runTests(tests []TestCase, cfg Config) []TestResult {
 	return results
 }
 
-func runSingleTest(test TestCase, cfg Config) TestResult {
+func runSingleTest(test TestCase, cfg Config, site *testsite.Server) TestResult {
 	result := TestResult{
 		Name: test.Name,
 	}
@@ -254,7 +264,11 @@ func runSingleTest(test TestCase, cfg Config) TestResult {
 
 	// Navigate to URL if specified
 	if test.URL != "" {
-		if err := agent.Navigate(ctx, test.URL); err != nil {
+		url := test.URL
+		if strings.HasPrefix(url, "/") {
+			url = site.URL(url)
+		}
+		if err := agent.Navigate(ctx, url); err != nil {
 			result.Error = fmt.Sprintf("failed to navigate: %v", err)
 			result.Duration = time.Since(startTime)
 			return result
@@ -0,0 +1,105 @@
+// Package testsite serves a small set of fixture pages from memory, so
+// examples, integration tests and evaluations don't depend on live sites.
+//
+// The pages cover what agents commonly trip over: forms, a modal dialog,
+// infinite scroll, an iframe, shadow DOM, a data table, linked docs pages,
+// JavaScript search and pagination. Forms post to /submit/<name>, and the
+// server records what was posted:
+//
+//	site, err := testsite.Start()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer site.Close()
+//
+//	agent.Navigate(ctx, site.URL("/forms.html"))
+//	result, err := agent.Run(ctx, "Send a support request as Jane Doe")
+//	posted := site.Submissions("contact")
+package testsite
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+//go:embed pages
+var pages embed.FS
+
+// Server serves the fixture pages on a local port and records the forms
+// posted to it.
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+
+	mu          sync.Mutex
+	submissions map[string][]url.Values
+}
+
+// Start serves the fixture pages on 127.0.0.1 at a random port.
+func Start() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	root, _ := fs.Sub(pages, "pages")
+	s := &Server{listener: ln, submissions: make(map[string][]url.Values)}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(root)))
+	mux.HandleFunc("POST /submit/", s.handleSubmit)
+	s.server = &http.Server{Handler: mux}
+
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// URL returns the absolute URL of a fixture path, e.g. "/forms.html".
+func (s *Server) URL(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return "http://" + s.listener.Addr().String() + path
+}
+
+// Submissions returns the forms posted to /submit/<name>, in order.
+func (s *Server) Submissions(name string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]url.Values(nil), s.submissions[name]...)
+}
+
+// Reset forgets all submissions.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.submissions = make(map[string][]url.Values)
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// handleSubmit records a posted form and shows a confirmation page.
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/submit/")
+	s.mu.Lock()
+	s.submissions[name] = append(s.submissions[name], r.PostForm)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<!DOCTYPE html><html><head><title>Thank you</title></head><body><h1>Thank you!</h1><p>Your submission was received.</p></body></html>")
+}
@@ -0,0 +1,121 @@
+package bua
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// profileVaultFile holds a profile's sensitive files while no browser uses it.
+const profileVaultFile = "bua-vault.enc"
+
+// sealedPaths are the profile files that hold session tokens: Chrome's cookie
+// database (only obfuscated on Linux hosts without a keyring), local storage,
+// and the cookies bua saves after sign-ins.
+var sealedPaths = []string{
+	filepath.Join("Default", "Cookies"),
+	filepath.Join("Default", "Cookies-journal"),
+	filepath.Join("Default", "Network", "Cookies"),
+	filepath.Join("Default", "Network", "Cookies-journal"),
+	filepath.Join("Default", "Local Storage"),
+	"bua-sessions",
+}
+
+// sealProfile encrypts a profile's sensitive files into its vault and removes
+// the plaintext. Call it only while no browser is using the profile.
+func sealProfile(profilePath, key string) error {
+	if !hasPlaintext(profilePath) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := archiveProfileFiles(tw, profilePath, sealedPaths); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to seal profile: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to seal profile: %w", err)
+	}
+
+	sealed, err := sealArchive(buf.Bytes(), key)
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so a crash never leaves a truncated vault behind
+	vault := filepath.Join(profilePath, profileVaultFile)
+	if err := os.WriteFile(vault+".tmp", sealed, 0600); err != nil {
+		return fmt.Errorf("failed to seal profile: %w", err)
+	}
+	if err := os.Rename(vault+".tmp", vault); err != nil {
+		return fmt.Errorf("failed to seal profile: %w", err)
+	}
+
+	for _, rel := range sealedPaths {
+		if err := os.RemoveAll(filepath.Join(profilePath, rel)); err != nil {
+			return fmt.Errorf("failed to remove plaintext profile data: %w", err)
+		}
+	}
+	return nil
+}
+
+// unsealProfile restores a profile's sensitive files from its vault. If
+// plaintext files are already present (the last session didn't close
+// cleanly), they are newer than the vault and are kept.
+func unsealProfile(profilePath, key string) error {
+	sealed, err := os.ReadFile(filepath.Join(profilePath, profileVaultFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read profile vault: %w", err)
+	}
+	if hasPlaintext(profilePath) {
+		return nil
+	}
+
+	archive, err := openArchive(sealed, key)
+	if err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return fmt.Errorf("failed to read profile vault: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read profile vault: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("failed to read profile vault: %w", err)
+		}
+		if err := extractProfileFile(profilePath, hdr.Name, data); err != nil {
+			return err
+		}
+	}
+}
+
+// hasPlaintext reports whether any sensitive file exists unencrypted.
+func hasPlaintext(profilePath string) bool {
+	for _, rel := range sealedPaths {
+		if _, err := os.Stat(filepath.Join(profilePath, rel)); err == nil {
+			return true
+		}
+	}
+	return false
+}
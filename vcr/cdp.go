@@ -0,0 +1,194 @@
+package vcr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+// CDPMessage is a recorded DevTools call or event, in the order the
+// client saw them.
+type CDPMessage struct {
+	// Event is true for events sent by the browser, false for calls.
+	Event     bool            `json:"event,omitempty"`
+	SessionID string          `json:"session_id,omitempty"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params,omitempty"`
+
+	// Result and Error are the answer to a call.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *cdp.Error      `json:"error,omitempty"`
+
+	// Failure is a call error that didn't come from the browser, e.g. a
+	// closed connection.
+	Failure string `json:"failure,omitempty"`
+}
+
+// cdpRecorder passes DevTools traffic through and records it.
+type cdpRecorder struct {
+	cassette *Cassette
+	inner    rod.CDPClient
+	events   chan *cdp.Event
+}
+
+func newCDPRecorder(c *Cassette, inner rod.CDPClient) *cdpRecorder {
+	r := &cdpRecorder{cassette: c, inner: inner, events: make(chan *cdp.Event)}
+	go func() {
+		defer close(r.events)
+		for e := range inner.Event() {
+			r.record(CDPMessage{Event: true, SessionID: e.SessionID, Method: e.Method, Params: e.Params})
+			r.events <- e
+		}
+	}()
+	return r
+}
+
+func (r *cdpRecorder) Event() <-chan *cdp.Event {
+	return r.events
+}
+
+func (r *cdpRecorder) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	res, err := r.inner.Call(ctx, sessionID, method, params)
+
+	msg := CDPMessage{SessionID: sessionID, Method: method, Params: encodeParams(params), Result: res}
+	var cdpErr *cdp.Error
+	switch {
+	case errors.As(err, &cdpErr):
+		msg.Error = cdpErr
+	case err != nil:
+		msg.Failure = err.Error()
+	}
+	r.record(msg)
+	return res, err
+}
+
+func (r *cdpRecorder) record(msg CDPMessage) {
+	r.cassette.mu.Lock()
+	r.cassette.CDP = append(r.cassette.CDP, msg)
+	r.cassette.mu.Unlock()
+}
+
+// cdpReplayer answers DevTools calls from a recording.
+//
+// Calls are matched to recorded calls with the same session, method and
+// params, falling back to the next recorded call with the same session and
+// method, since params such as stealth fingerprints and humanized mouse
+// paths vary between runs. A call with nothing left to match gets the last
+// answer to the same method again, which keeps polling loops going.
+//
+// Events are delivered as the calls that preceded them in the recording
+// are replayed.
+type cdpReplayer struct {
+	cassette *Cassette
+	events   chan *cdp.Event
+
+	mu   sync.Mutex
+	used []bool
+	last map[string]int
+
+	// next is the index of the first message whose events haven't been sent
+	next int
+}
+
+func newCDPReplayer(c *Cassette) *cdpReplayer {
+	events := 0
+	for _, m := range c.CDP {
+		if m.Event {
+			events++
+		}
+	}
+	return &cdpReplayer{
+		cassette: c,
+		events:   make(chan *cdp.Event, events),
+		used:     make([]bool, len(c.CDP)),
+		last:     make(map[string]int),
+	}
+}
+
+func (r *cdpReplayer) Event() <-chan *cdp.Event {
+	return r.events
+}
+
+func (r *cdpReplayer) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := sessionID + "\x00" + method
+	i := r.match(sessionID, method, encodeParams(params))
+	if i < 0 {
+		last, ok := r.last[key]
+		if !ok {
+			return nil, fmt.Errorf("vcr: no recorded call to %s", method)
+		}
+		i = last
+	} else {
+		r.used[i] = true
+		r.last[key] = i
+		r.deliverThrough(i)
+	}
+
+	msg := r.cassette.CDP[i]
+	switch {
+	case msg.Error != nil:
+		e := *msg.Error
+		return nil, &e
+	case msg.Failure != "":
+		return nil, errors.New(msg.Failure)
+	}
+	return msg.Result, nil
+}
+
+// match returns the index of the unused recorded call best matching a call,
+// or -1.
+func (r *cdpReplayer) match(sessionID, method string, params json.RawMessage) int {
+	fallback := -1
+	for i, m := range r.cassette.CDP {
+		if m.Event || r.used[i] || m.SessionID != sessionID || m.Method != method {
+			continue
+		}
+		if bytes.Equal(m.Params, params) {
+			return i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+// deliverThrough sends the events recorded before call i and between it
+// and the next call.
+func (r *cdpReplayer) deliverThrough(i int) {
+	msgs := r.cassette.CDP
+	end := i + 1
+	for end < len(msgs) && msgs[end].Event {
+		end++
+	}
+	for ; r.next < end; r.next++ {
+		if m := msgs[r.next]; m.Event {
+			r.events <- &cdp.Event{SessionID: m.SessionID, Method: m.Method, Params: m.Params}
+		}
+	}
+}
+
+// encodeParams encodes call params the way the recording stores them.
+func encodeParams(params interface{}) json.RawMessage {
+	if params == nil {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,98 @@
+package vcr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// HTTPInteraction is a recorded request and its response. Request bodies
+// and headers aren't kept, so prompts and API keys stay out of the cassette.
+type HTTPInteraction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// transport records or replays HTTP round trips.
+type transport struct {
+	cassette *Cassette
+	inner    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cassette.Replaying() {
+		return t.replay(req)
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c := t.cassette
+	c.mu.Lock()
+	c.HTTP = append(c.HTTP, HTTPInteraction{
+		Method:      req.Method,
+		URL:         redactURL(req.URL),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers req with the next unused interaction for the same method
+// and URL. Bodies aren't compared: prompts carry timestamps and screenshots
+// that differ between runs.
+func (t *transport) replay(req *http.Request) (*http.Response, error) {
+	c := t.cassette
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	u := redactURL(req.URL)
+	for i, in := range c.HTTP {
+		if c.httpUsed[i] || in.Method != req.Method || in.URL != u {
+			continue
+		}
+		c.httpUsed[i] = true
+
+		header := make(http.Header)
+		if in.ContentType != "" {
+			header.Set("Content-Type", in.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+			ContentLength: int64(len(in.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("vcr: no recorded response left for %s %s", req.Method, u)
+}
+
+// redactURL drops credentials from a request URL.
+func redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	q := clean.Query()
+	q.Del("key")
+	clean.RawQuery = q.Encode()
+	return clean.String()
+}
@@ -0,0 +1,144 @@
+// Package vcr records the model requests and DevTools traffic of an agent
+// run into a cassette file and replays them later, without an API key, a
+// browser or a network. Replayed runs take the same steps and produce the
+// same result as the recording, which makes integration tests of agent
+// behavior deterministic and free to run in CI.
+//
+//	cassette, err := vcr.Open("testdata/checkout.json")
+//	if err != nil { ... }
+//	agent, _ := bua.New(bua.Config{Cassette: cassette, ...})
+//
+// Open replays the cassette if the file exists and records it otherwise;
+// set BUA_VCR=record to re-record an existing cassette. Recordings are saved
+// when the agent is closed.
+//
+// Cassettes contain the pages the agent visited and everything it typed,
+// including secret values. Record against test accounts.
+package vcr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-rod/rod"
+)
+
+// Mode is whether a cassette records or replays.
+type Mode string
+
+const (
+	// ModeRecord passes traffic through and records it.
+	ModeRecord Mode = "record"
+
+	// ModeReplay answers from the recording without touching the network.
+	ModeReplay Mode = "replay"
+)
+
+// cassetteVersion is the file format version.
+const cassetteVersion = 1
+
+// Cassette holds the recorded traffic of a run.
+type Cassette struct {
+	Version int               `json:"version"`
+	HTTP    []HTTPInteraction `json:"http"`
+	CDP     []CDPMessage      `json:"cdp"`
+
+	path string
+	mode Mode
+	mu   sync.Mutex
+
+	// httpUsed marks interactions already replayed
+	httpUsed []bool
+}
+
+// Open returns a cassette for path: replaying it if the file exists and
+// recording it otherwise. BUA_VCR=record or BUA_VCR=replay forces a mode.
+func Open(path string) (*Cassette, error) {
+	switch Mode(os.Getenv("BUA_VCR")) {
+	case ModeRecord:
+		return Record(path), nil
+	case ModeReplay:
+		return Load(path)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return Record(path), nil
+	}
+	return Load(path)
+}
+
+// Record returns an empty cassette that records into path on Save.
+func Record(path string) *Cassette {
+	return &Cassette{Version: cassetteVersion, path: path, mode: ModeRecord}
+}
+
+// Load reads a cassette for replay.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	c := &Cassette{path: path, mode: ModeReplay}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s has unsupported version %d", path, c.Version)
+	}
+	c.httpUsed = make([]bool, len(c.HTTP))
+	return c, nil
+}
+
+// Mode reports whether the cassette records or replays.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Replaying reports whether the cassette replays a recording.
+func (c *Cassette) Replaying() bool {
+	return c.mode == ModeReplay
+}
+
+// Save writes a recording cassette to its file. It does nothing when replaying.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.Marshal(c)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// HTTPClient returns a client for model requests that records them, or
+// answers them from the recording.
+func (c *Cassette) HTTPClient() *http.Client {
+	return &http.Client{Transport: &transport{cassette: c, inner: http.DefaultTransport}}
+}
+
+// WrapCDP wraps a DevTools connection so its calls and events are recorded.
+// When replaying, inner is ignored (and may be nil): calls are answered and
+// events delivered from the recording.
+func (c *Cassette) WrapCDP(inner rod.CDPClient) rod.CDPClient {
+	if c.mode == ModeReplay {
+		return newCDPReplayer(c)
+	}
+	return newCDPRecorder(c, inner)
+}
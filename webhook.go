@@ -0,0 +1,126 @@
+package bua
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CompletionWebhook posts a summary of every finished run to a URL.
+//
+// The body is a JSON CompletionEvent. With a Secret, the request carries
+// X-BUA-Signature: sha256=<hex HMAC-SHA256 of the body>, so the receiver
+// can verify it came from this agent.
+type CompletionWebhook struct {
+	// URL receives the POST (required).
+	URL string
+
+	// Secret signs the body with HMAC-SHA256 (optional).
+	Secret string
+
+	// Timeout bounds the request. Default: 10s.
+	Timeout time.Duration
+
+	// HTTPClient sends the request. Default: http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// CompletionEvent is the payload posted to a CompletionWebhook.
+type CompletionEvent struct {
+	Task          string     `json:"task"`
+	Success       bool       `json:"success"`
+	Error         string     `json:"error,omitempty"`
+	ErrorCode     ErrorCode  `json:"error_code,omitempty"`
+	Data          any        `json:"data,omitempty"`
+	Steps         int        `json:"steps"`
+	DurationMs    int64      `json:"duration_ms"`
+	TokensUsed    int        `json:"tokens_used,omitempty"`
+	RecordingPath string     `json:"recording_path,omitempty"`
+	Artifacts     []Artifact `json:"artifacts,omitempty"`
+	FinishedAt    time.Time  `json:"finished_at"`
+}
+
+// newCompletionEvent summarizes a run. result is nil if the run errored.
+func newCompletionEvent(task string, result *Result, err error, duration time.Duration) CompletionEvent {
+	event := CompletionEvent{
+		Task:       task,
+		DurationMs: duration.Milliseconds(),
+		FinishedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+		var coded *Error
+		if errors.As(err, &coded) {
+			event.ErrorCode = coded.Code
+		}
+		return event
+	}
+
+	event.Success = result.Success
+	event.Error = result.Error
+	event.ErrorCode = result.ErrorCode
+	event.Data = result.Data
+	event.Steps = len(result.Steps)
+	event.TokensUsed = result.TokensUsed
+	event.RecordingPath = result.RecordingPath
+	event.Artifacts = result.Artifacts
+	return event
+}
+
+// send posts the event, signing it if a secret is set.
+func (w *CompletionWebhook) send(ctx context.Context, event CompletionEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode completion event: %w", err)
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bua-webhook")
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set("X-BUA-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := w.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// notifyCompletion posts the run summary to Config.CompletionWebhook. It
+// still fires when ctx was canceled, since a canceled run is worth reporting.
+func (a *Agent) notifyCompletion(ctx context.Context, task string, result *Result, err error, duration time.Duration) {
+	event := newCompletionEvent(task, result, err, duration)
+	if sendErr := a.config.CompletionWebhook.send(context.WithoutCancel(ctx), event); sendErr != nil {
+		a.log("Webhook").Warn("Failed to notify", "url", a.config.CompletionWebhook.URL, "err", sendErr)
+	}
+}